@@ -0,0 +1,185 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// AjvKeywords are implementations of popular keywords of ajv-keywords and
+// ajv-formats, the extensions of the ajv validator. They aren't standard,
+// so they're opt-in
+var AjvKeywords = map[string]ValMaker{
+	"patternRequired":        NewPatternRequired,
+	"prohibited":             NewProhibited,
+	"uniqueItemProperties":   NewUniqueItemProperties,
+	"allRequired":            NewAllRequired,
+	"transform":              NewTransform,
+	"formatMinimum":          NewFormatMinimum,
+	"formatMaximum":          NewFormatMaximum,
+	"formatExclusiveMinimum": NewFormatExclusiveMinimum,
+	"formatExclusiveMaximum": NewFormatExclusiveMaximum,
+}
+
+// RegisterAjvKeywords adds AjvKeywords to DefaultValidators, so schemas
+// written for ajv validate the same way. Schemas decoded before the call
+// ignore the keywords
+func RegisterAjvKeywords() {
+	for name, mk := range AjvKeywords {
+		RegisterValidator(name, mk)
+	}
+}
+
+// PatternRequired is a list of regular expressions. An object instance is
+// valid if, for each of them, it has a property whose name matches it
+type PatternRequired []*regexp.Regexp
+
+// NewPatternRequired allocates a new PatternRequired validator
+func NewPatternRequired() Validator {
+	return &PatternRequired{}
+}
+
+// Validate implements the Validator interface for PatternRequired
+func (p PatternRequired) Validate(propPath string, data interface{}, errs *[]ValError) {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, re := range p {
+		found := false
+		for key := range obj {
+			if re.MatchString(key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			AddError(errs, propPath, data, fmt.Sprintf("a property matching pattern %s is required", re.String()))
+		}
+	}
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for PatternRequired
+func (p *PatternRequired) UnmarshalJSON(data []byte) error {
+	var ptns []string
+	if err := json.Unmarshal(data, &ptns); err != nil {
+		return err
+	}
+	*p = make(PatternRequired, len(ptns))
+	for i, ptn := range ptns {
+		re, err := regexp.Compile(ptn)
+		if err != nil {
+			return err
+		}
+		(*p)[i] = re
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for PatternRequired
+func (p PatternRequired) MarshalJSON() ([]byte, error) {
+	ptns := make([]string, len(p))
+	for i, re := range p {
+		ptns[i] = re.String()
+	}
+	return json.Marshal(ptns)
+}
+
+// Prohibited is a list of property names. An object instance is valid if it
+// has none of them
+type Prohibited []string
+
+// NewProhibited allocates a new Prohibited validator
+func NewProhibited() Validator {
+	return &Prohibited{}
+}
+
+// Validate implements the Validator interface for Prohibited
+func (p Prohibited) Validate(propPath string, data interface{}, errs *[]ValError) {
+	if obj, ok := data.(map[string]interface{}); ok {
+		for _, key := range p {
+			if _, ok := obj[key]; ok {
+				AddError(errs, propPath, data, fmt.Sprintf(`"%s" property is prohibited`, key))
+			}
+		}
+	}
+}
+
+// UniqueItemProperties is a list of property names. An array instance is
+// valid if no two of its object elements have equal values for any of
+// them. Elements without the property aren't compared
+type UniqueItemProperties []string
+
+// NewUniqueItemProperties allocates a new UniqueItemProperties validator
+func NewUniqueItemProperties() Validator {
+	return &UniqueItemProperties{}
+}
+
+// Validate implements the Validator interface for UniqueItemProperties
+func (u UniqueItemProperties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	arr, ok := data.([]interface{})
+	if !ok {
+		return
+	}
+	for _, key := range u {
+	items:
+		for i := range arr {
+			a, ok := arr[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := a[key]; !ok {
+				continue
+			}
+			for j := i + 1; j < len(arr); j++ {
+				b, ok := arr[j].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if val, ok := b[key]; ok && reflect.DeepEqual(a[key], val) {
+					AddError(errs, propPath, data, fmt.Sprintf(`items %d and %d have the same "%s"`, i, j, key))
+					break items
+				}
+			}
+		}
+	}
+}
+
+// AllRequired, when true, makes every property of the sibling "properties"
+// keyword required
+type AllRequired struct {
+	required bool
+	props    *Properties
+}
+
+// NewAllRequired allocates a new AllRequired validator
+func NewAllRequired() Validator {
+	return &AllRequired{}
+}
+
+// Validate implements the Validator interface for AllRequired. Every
+// missing property is listed in a single error, as for Required
+func (a AllRequired) Validate(propPath string, data interface{}, errs *[]ValError) {
+	obj, ok := data.(map[string]interface{})
+	if !ok || !a.required || a.props == nil {
+		return
+	}
+	missing := []string{}
+	for _, key := range sortedPropertyKeys(*a.props) {
+		if _, ok := obj[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	addRequiredError(errs, propPath, data, missing)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for AllRequired
+func (a *AllRequired) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &a.required)
+}
+
+// MarshalJSON implements the json.Marshaler interface for AllRequired
+func (a AllRequired) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.required)
+}
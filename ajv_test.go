@@ -0,0 +1,73 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAjvKeywords(t *testing.T) {
+	RegisterAjvKeywords()
+	defer func() {
+		for name := range AjvKeywords {
+			delete(DefaultValidators, name)
+		}
+	}()
+
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"meta": {"patternRequired": ["^x-", "id$"], "prohibited": ["secret", "token"]},
+			"users": {"uniqueItemProperties": ["id", "email"]},
+			"point": {"allRequired": true, "properties": {"x": {}, "y": {}}}
+		}
+	}`)
+
+	cases := []struct {
+		doc    string
+		expect []string
+	}{
+		{`{"meta": {"x-a": 1, "userid": 2}}`, nil},
+		{`{"meta": {"x-a": 1}}`, []string{"/meta: a property matching pattern id$ is required"}},
+		{`{"meta": {"x-a": 1, "id": 2, "token": "t"}}`, []string{`/meta: "token" property is prohibited`}},
+		{`{"users": [{"id": 1}, {"id": 2, "email": "a"}, {"email": "b"}, 3]}`, nil},
+		{`{"users": [{"id": 1}, {"id": 2, "email": "a"}, {"id": 1}, {"email": "a"}]}`, []string{`/users: items 0 and 2 have the same "id"`, `/users: items 1 and 3 have the same "email"`}},
+		{`{"point": {"x": 1, "y": 2}}`, nil},
+		{`{"point": {"y": 2}}`, []string{`/point: "x" value is required`}},
+		{`{"point": {}}`, []string{`/point: "x", "y" values are required`}},
+	}
+	for _, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.PropertyPath+": "+e.Message)
+		}
+		sort.Strings(got)
+		sort.Strings(c.expect)
+		if strings.Join(got, "\n") != strings.Join(c.expect, "\n") {
+			t.Errorf("%s: expected errors %q, got %q", c.doc, c.expect, got)
+		}
+	}
+
+	data, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kw := range []string{`"patternRequired":["^x-","id$"]`, `"allRequired":true`, `"uniqueItemProperties":["id","email"]`} {
+		if !strings.Contains(string(data), kw) {
+			t.Errorf("expected %s to be encoded, got %s", kw, data)
+		}
+	}
+
+	// the clone's allRequired is linked to its own properties
+	cp := rs.Clone()
+	errs, _ := cp.ValidateBytes([]byte(`{"point": {}}`))
+	if len(errs) != 1 || !reflect.DeepEqual(errs[0].MissingProperties, []string{"x", "y"}) {
+		t.Errorf("expected the clone to require x and y, got %v", errs)
+	}
+}
@@ -0,0 +1,204 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Annotations are the annotation keywords of the schemas that apply to a
+// location within instances, for inline help in user interfaces
+type Annotations struct {
+	Title       string
+	Description string
+	// Examples is the flattened examples of every applicable schema, without
+	// duplicates
+	Examples []interface{}
+	// Default is nil when no applicable schema has a default
+	Default interface{}
+}
+
+// AnnotationsAt gathers the annotations of the schemas that apply to the
+// value at the instance JSON pointer ptr. Properties of ptr are located
+// through "properties", "patternProperties" and "additionalProperties", and
+// array elements through "items" and "additionalItems". References are
+// followed, and every branch of "allOf", "anyOf" and "oneOf" and both
+// "then" and "else" are considered, since which of them apply depends on
+// the instance. Where schemas disagree on a title, description or default
+// the one closest to s wins, with a schema's own keywords taking precedence
+// over those of its branches. A location no schema describes gives empty
+// annotations
+func (s *Schema) AnnotationsAt(ptr string) (*Annotations, error) {
+	schemas, err := s.SchemasAt(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Annotations{}
+	for _, sch := range schemas {
+		if a.Title == "" {
+			a.Title = sch.Title
+		}
+		if a.Description == "" {
+			a.Description = sch.Description
+		}
+		if a.Default == nil {
+			a.Default = sch.Default
+		}
+		for _, ex := range sch.Examples {
+			if !containsValue(a.Examples, ex) {
+				a.Examples = append(a.Examples, ex)
+			}
+		}
+	}
+	return a, nil
+}
+
+// SchemasAt gives the schemas that may apply to the value at the instance
+// JSON pointer ptr, found as described for AnnotationsAt. Schemas closer to
+// s come first, and references are replaced by their targets
+func (s *Schema) SchemasAt(ptr string) ([]*Schema, error) {
+	if ptr != "" && ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid instance pointer %q: must be empty or start with /", ptr)
+	}
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance pointer %q: %s", ptr, err.Error())
+	}
+
+	schemas := applicableSchemas(s, nil, map[*Schema]bool{})
+	for _, tok := range tokens {
+		schemas = nextSchemas(schemas, tok)
+	}
+	return schemas, nil
+}
+
+// nextSchemas gives the schemas that apply to the property or array element
+// tok of a value schemas apply to
+func nextSchemas(schemas []*Schema, tok string) []*Schema {
+	next := []*Schema{}
+	seen := map[*Schema]bool{}
+	for _, sch := range schemas {
+		for _, child := range childSchemas(sch, tok) {
+			next = applicableSchemas(child, next, seen)
+		}
+	}
+	return next
+}
+
+// applicableSchemas appends s and the schemas that apply alongside it to
+// list, skipping schemas in seen
+func applicableSchemas(s *Schema, list []*Schema, seen map[*Schema]bool) []*Schema {
+	s, ok := resolveSchema(s)
+	if !ok || seen[s] || s.schemaType != schemaTypeObject {
+		return list
+	}
+	seen[s] = true
+	list = append(list, s)
+
+	branches := []*Schema{}
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		branches = append(branches, *allOf...)
+	}
+	if anyOf, ok := s.Validators["anyOf"].(*AnyOf); ok {
+		branches = append(branches, *anyOf...)
+	}
+	if oneOf, ok := s.Validators["oneOf"].(*OneOf); ok {
+		branches = append(branches, *oneOf...)
+	}
+	if cond, ok := s.Validators["if"].(*If); ok {
+		if cond.Then != nil {
+			branches = append(branches, (*Schema)(cond.Then))
+		}
+		if cond.Else != nil {
+			branches = append(branches, (*Schema)(cond.Else))
+		}
+	}
+	for _, branch := range branches {
+		list = applicableSchemas(branch, list, seen)
+	}
+	return list
+}
+
+// childSchemas gives the schemas of s that apply to the property or array
+// element tok of an instance
+func childSchemas(s *Schema, tok string) []*Schema {
+	children := []*Schema{}
+	if props, ok := s.Validators["properties"].(*Properties); ok && (*props)[tok] != nil {
+		children = append(children, (*props)[tok])
+	}
+	if patterns, ok := s.Validators["patternProperties"].(*PatternProperties); ok {
+		for _, ptn := range *patterns {
+			if ptn.re.MatchString(tok) {
+				children = append(children, ptn.schema)
+			}
+		}
+	}
+	if add, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && add.Schema != nil && len(children) == 0 {
+		children = append(children, add.Schema)
+	}
+	if i, err := strconv.Atoi(tok); err == nil && i >= 0 {
+		if item := itemSchema(s, i); item != trueSchema {
+			children = append(children, item)
+		}
+	}
+	return children
+}
+
+// Defaults lists the defaults declared for locations within instances,
+// keyed by instance JSON pointer, without applying them. Locations are
+// reached through "properties" and tuple "items", so defaults of pattern
+// properties, additional properties and items in general are left out as
+// they don't belong to a single location. Schemas apply as they do for
+// AnnotationsAt, and the closest default to s wins. Recursive schemas are
+// expanded until they recur
+func (s *Schema) Defaults() map[string]interface{} {
+	defaults := map[string]interface{}{}
+	collectDefaults(s, "", defaults, map[*Schema]bool{})
+	return defaults
+}
+
+// collectDefaults adds the defaults of s and the locations beneath it to
+// defaults. active holds the schemas applying to the locations above ptr
+func collectDefaults(s *Schema, ptr string, defaults map[string]interface{}, active map[*Schema]bool) {
+	schemas := applicableSchemas(s, nil, map[*Schema]bool{})
+	tokens := []string{}
+	children := map[string][]*Schema{}
+	addChild := func(tok string, child *Schema) {
+		if _, ok := children[tok]; !ok {
+			tokens = append(tokens, tok)
+		}
+		children[tok] = append(children[tok], child)
+	}
+	recurse := []*Schema{}
+	for _, sch := range schemas {
+		if active[sch] {
+			continue
+		}
+		recurse = append(recurse, sch)
+		if _, ok := defaults[ptr]; !ok && sch.Default != nil {
+			defaults[ptr] = sch.Default
+		}
+		if props, ok := sch.Validators["properties"].(*Properties); ok {
+			for _, name := range sortedPropertyKeys(*props) {
+				addChild(EscapePointerToken(name), (*props)[name])
+			}
+		}
+		if items, ok := sch.Validators["items"].(*Items); ok && !items.single {
+			for i, item := range items.Schemas {
+				addChild(strconv.Itoa(i), item)
+			}
+		}
+	}
+
+	for _, sch := range recurse {
+		active[sch] = true
+	}
+	for _, tok := range tokens {
+		for _, child := range children[tok] {
+			collectDefaults(child, ptr+"/"+tok, defaults, active)
+		}
+	}
+	for _, sch := range recurse {
+		delete(active, sch)
+	}
+}
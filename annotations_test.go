@@ -0,0 +1,121 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnnotationsAt(t *testing.T) {
+	rs := Must(`{
+		"title": "Config",
+		"properties": {
+			"port": {
+				"$ref": "#/definitions/port",
+				"description": "ignored beside a reference"
+			},
+			"servers": {
+				"type": "array",
+				"items": {
+					"title": "Server",
+					"allOf": [{"properties": {"host": {"description": "Host name", "examples": ["a.example.com"]}}}],
+					"anyOf": [
+						{"properties": {"host": {"title": "Host", "examples": ["b.example.com", "a.example.com"]}}},
+						{"properties": {"host": {"title": "Other host", "default": "localhost"}}}
+					]
+				}
+			}
+		},
+		"patternProperties": {"^x-": {"description": "Extension"}},
+		"additionalProperties": {"title": "Anything else"},
+		"definitions": {
+			"port": {"title": "Port", "default": 8080, "examples": [80, 443]}
+		}
+	}`)
+
+	cases := []struct {
+		ptr    string
+		expect Annotations
+	}{
+		{"", Annotations{Title: "Config"}},
+		{"/port", Annotations{Title: "Port", Default: float64(8080), Examples: []interface{}{float64(80), float64(443)}}},
+		{"/servers/3", Annotations{Title: "Server"}},
+		{"/servers/0/host", Annotations{
+			Title:       "Host",
+			Description: "Host name",
+			Default:     "localhost",
+			Examples:    []interface{}{"a.example.com", "b.example.com"},
+		}},
+		{"/x-custom", Annotations{Description: "Extension"}},
+		{"/other", Annotations{Title: "Anything else"}},
+		{"/port/missing", Annotations{}},
+	}
+	for _, c := range cases {
+		got, err := rs.AnnotationsAt(c.ptr)
+		if err != nil {
+			t.Errorf("%q: %s", c.ptr, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, c.expect) {
+			t.Errorf("%q: expected %#v, got %#v", c.ptr, c.expect, *got)
+		}
+	}
+
+	if _, err := rs.AnnotationsAt("port"); err == nil {
+		t.Error("expected an error for a pointer without a leading slash")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	rs := Must(`{
+		"default": {},
+		"properties": {
+			"port": {"$ref": "#/definitions/port"},
+			"log": {
+				"properties": {
+					"level": {"enum": ["debug", "info"], "default": "info"},
+					"a/b": {"default": true}
+				},
+				"allOf": [{"properties": {"level": {"default": "debug"}, "file": {"default": "out.log"}}}]
+			},
+			"pair": {"items": [{"default": 1}, {"default": 2}]},
+			"list": {"items": {"default": "ignored"}},
+			"child": {"$ref": "#"}
+		},
+		"patternProperties": {"^x-": {"default": "ignored"}},
+		"definitions": {
+			"port": {"default": 8080}
+		}
+	}`)
+
+	expect := map[string]interface{}{
+		"":           map[string]interface{}{},
+		"/port":      float64(8080),
+		"/log/level": "info",
+		"/log/a~1b":  true,
+		"/log/file":  "out.log",
+		"/pair/0":    float64(1),
+		"/pair/1":    float64(2),
+	}
+	if got := rs.Defaults(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
+
+func TestSchemasAt(t *testing.T) {
+	rs := Must(`{
+		"properties": {"a": {"$ref": "#/definitions/a", "title": "ignored"}},
+		"allOf": [{"properties": {"a": {"title": "branch"}}}],
+		"definitions": {"a": {"title": "target"}}
+	}`)
+	schemas, err := rs.SchemasAt("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for _, s := range schemas {
+		titles = append(titles, s.Title)
+	}
+	if expect := []string{"target", "branch"}; !reflect.DeepEqual(titles, expect) {
+		t.Errorf("expected schemas %v, got %v", expect, titles)
+	}
+}
@@ -0,0 +1,372 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaBuilder assembles a schema in Go code, an alternative to writing
+// the schema as JSON. Each method sets a keyword and returns the builder so
+// calls can be chained:
+//
+//	sch, err := NewObject().
+//		Property("name", String().MinLength(1)).
+//		Property("age", Integer().Minimum(0)).
+//		Required("name").
+//		Build()
+//
+// Builders are decoded with the same rules as JSON schemas, so invalid
+// values like a malformed pattern are reported by Build
+type SchemaBuilder struct {
+	boolean  *bool
+	keywords map[string]interface{}
+}
+
+// NewSchema starts a schema with no keywords, which any instance is valid against
+func NewSchema() *SchemaBuilder {
+	return &SchemaBuilder{keywords: map[string]interface{}{}}
+}
+
+// NewObject starts a schema for object instances
+func NewObject() *SchemaBuilder { return NewSchema().Type("object") }
+
+// String starts a schema for string instances
+func String() *SchemaBuilder { return NewSchema().Type("string") }
+
+// Integer starts a schema for integer instances
+func Integer() *SchemaBuilder { return NewSchema().Type("integer") }
+
+// Number starts a schema for number instances
+func Number() *SchemaBuilder { return NewSchema().Type("number") }
+
+// Boolean starts a schema for boolean instances
+func Boolean() *SchemaBuilder { return NewSchema().Type("boolean") }
+
+// Null starts a schema for null instances
+func Null() *SchemaBuilder { return NewSchema().Type("null") }
+
+// Array starts a schema for arrays whose items are valid against items,
+// which may be nil to allow any items
+func Array(items *SchemaBuilder) *SchemaBuilder {
+	b := NewSchema().Type("array")
+	if items != nil {
+		b.Items(items)
+	}
+	return b
+}
+
+// Ref starts a schema that refers to another schema by URI or JSON pointer
+func Ref(uri string) *SchemaBuilder { return NewSchema().Ref(uri) }
+
+// AllOfSchema starts a schema instances must satisfy every one of schemas for
+func AllOfSchema(schemas ...*SchemaBuilder) *SchemaBuilder { return NewSchema().AllOf(schemas...) }
+
+// AnyOfSchema starts a schema instances must satisfy at least one of schemas for
+func AnyOfSchema(schemas ...*SchemaBuilder) *SchemaBuilder { return NewSchema().AnyOf(schemas...) }
+
+// OneOfSchema starts a schema instances must satisfy exactly one of schemas for
+func OneOfSchema(schemas ...*SchemaBuilder) *SchemaBuilder { return NewSchema().OneOf(schemas...) }
+
+// NotSchema starts a schema instances must not satisfy sch for
+func NotSchema(sch *SchemaBuilder) *SchemaBuilder { return NewSchema().Not(sch) }
+
+// True gives the boolean schema any instance is valid against
+func True() *SchemaBuilder {
+	v := true
+	return &SchemaBuilder{boolean: &v}
+}
+
+// False gives the boolean schema no instance is valid against
+func False() *SchemaBuilder {
+	v := false
+	return &SchemaBuilder{boolean: &v}
+}
+
+// FromSchema starts a builder from the keywords of an existing schema. The
+// schema is encoded when the builder is built, and its references are
+// resolved again against the built document
+func FromSchema(s *Schema) *SchemaBuilder {
+	switch s.schemaType {
+	case schemaTypeTrue:
+		return True()
+	case schemaTypeFalse:
+		return False()
+	}
+	b := NewSchema()
+	for key, val := range s.jsonObject() {
+		b.keywords[key] = val
+	}
+	return b
+}
+
+// Keyword sets any keyword to a value that encodes to JSON, for keywords
+// the builder has no method for, like custom validators. A boolean schema
+// becomes an object schema when given keywords
+func (b *SchemaBuilder) Keyword(name string, value interface{}) *SchemaBuilder {
+	if b.boolean != nil {
+		if !*b.boolean {
+			b.keywords = map[string]interface{}{"not": map[string]interface{}{}}
+		} else {
+			b.keywords = map[string]interface{}{}
+		}
+		b.boolean = nil
+	}
+	b.keywords[name] = value
+	return b
+}
+
+// ID sets "$id"
+func (b *SchemaBuilder) ID(id string) *SchemaBuilder { return b.Keyword("$id", id) }
+
+// Title sets "title"
+func (b *SchemaBuilder) Title(title string) *SchemaBuilder { return b.Keyword("title", title) }
+
+// Description sets "description"
+func (b *SchemaBuilder) Description(desc string) *SchemaBuilder {
+	return b.Keyword("description", desc)
+}
+
+// Comment sets "$comment"
+func (b *SchemaBuilder) Comment(comment string) *SchemaBuilder {
+	return b.Keyword("$comment", comment)
+}
+
+// Default sets "default"
+func (b *SchemaBuilder) Default(value interface{}) *SchemaBuilder {
+	return b.Keyword("default", value)
+}
+
+// Examples sets "examples"
+func (b *SchemaBuilder) Examples(values ...interface{}) *SchemaBuilder {
+	return b.Keyword("examples", values)
+}
+
+// ReadOnly sets "readOnly" to true
+func (b *SchemaBuilder) ReadOnly() *SchemaBuilder { return b.Keyword("readOnly", true) }
+
+// WriteOnly sets "writeOnly" to true
+func (b *SchemaBuilder) WriteOnly() *SchemaBuilder { return b.Keyword("writeOnly", true) }
+
+// Definition adds an entry to "definitions"
+func (b *SchemaBuilder) Definition(name string, def *SchemaBuilder) *SchemaBuilder {
+	return b.addTo("definitions", name, def)
+}
+
+// Type sets "type" to one or more type names
+func (b *SchemaBuilder) Type(types ...string) *SchemaBuilder {
+	if len(types) == 1 {
+		return b.Keyword("type", types[0])
+	}
+	return b.Keyword("type", types)
+}
+
+// Enum sets "enum"
+func (b *SchemaBuilder) Enum(values ...interface{}) *SchemaBuilder { return b.Keyword("enum", values) }
+
+// Const sets "const"
+func (b *SchemaBuilder) Const(value interface{}) *SchemaBuilder { return b.Keyword("const", value) }
+
+// Format sets "format"
+func (b *SchemaBuilder) Format(format string) *SchemaBuilder { return b.Keyword("format", format) }
+
+// MultipleOf sets "multipleOf"
+func (b *SchemaBuilder) MultipleOf(n float64) *SchemaBuilder { return b.Keyword("multipleOf", n) }
+
+// Minimum sets "minimum"
+func (b *SchemaBuilder) Minimum(n float64) *SchemaBuilder { return b.Keyword("minimum", n) }
+
+// Maximum sets "maximum"
+func (b *SchemaBuilder) Maximum(n float64) *SchemaBuilder { return b.Keyword("maximum", n) }
+
+// ExclusiveMinimum sets "exclusiveMinimum"
+func (b *SchemaBuilder) ExclusiveMinimum(n float64) *SchemaBuilder {
+	return b.Keyword("exclusiveMinimum", n)
+}
+
+// ExclusiveMaximum sets "exclusiveMaximum"
+func (b *SchemaBuilder) ExclusiveMaximum(n float64) *SchemaBuilder {
+	return b.Keyword("exclusiveMaximum", n)
+}
+
+// MinLength sets "minLength"
+func (b *SchemaBuilder) MinLength(n int) *SchemaBuilder { return b.Keyword("minLength", n) }
+
+// MaxLength sets "maxLength"
+func (b *SchemaBuilder) MaxLength(n int) *SchemaBuilder { return b.Keyword("maxLength", n) }
+
+// Pattern sets "pattern"
+func (b *SchemaBuilder) Pattern(pattern string) *SchemaBuilder { return b.Keyword("pattern", pattern) }
+
+// Items sets "items" to a single schema every item must be valid against
+func (b *SchemaBuilder) Items(items *SchemaBuilder) *SchemaBuilder { return b.Keyword("items", items) }
+
+// TupleItems sets "items" to a schema for each position in the array
+func (b *SchemaBuilder) TupleItems(items ...*SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("items", items)
+}
+
+// AdditionalItems sets "additionalItems"
+func (b *SchemaBuilder) AdditionalItems(items *SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("additionalItems", items)
+}
+
+// MinItems sets "minItems"
+func (b *SchemaBuilder) MinItems(n int) *SchemaBuilder { return b.Keyword("minItems", n) }
+
+// MaxItems sets "maxItems"
+func (b *SchemaBuilder) MaxItems(n int) *SchemaBuilder { return b.Keyword("maxItems", n) }
+
+// UniqueItems sets "uniqueItems" to true
+func (b *SchemaBuilder) UniqueItems() *SchemaBuilder { return b.Keyword("uniqueItems", true) }
+
+// Contains sets "contains"
+func (b *SchemaBuilder) Contains(item *SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("contains", item)
+}
+
+// Property adds an entry to "properties"
+func (b *SchemaBuilder) Property(name string, prop *SchemaBuilder) *SchemaBuilder {
+	return b.addTo("properties", name, prop)
+}
+
+// PatternProperty adds an entry to "patternProperties"
+func (b *SchemaBuilder) PatternProperty(pattern string, prop *SchemaBuilder) *SchemaBuilder {
+	return b.addTo("patternProperties", pattern, prop)
+}
+
+// AdditionalProperties sets "additionalProperties"
+func (b *SchemaBuilder) AdditionalProperties(prop *SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("additionalProperties", prop)
+}
+
+// Required adds names to "required"
+func (b *SchemaBuilder) Required(names ...string) *SchemaBuilder {
+	req, _ := b.keywords["required"].([]string)
+	return b.Keyword("required", append(req, names...))
+}
+
+// MinProperties sets "minProperties"
+func (b *SchemaBuilder) MinProperties(n int) *SchemaBuilder { return b.Keyword("minProperties", n) }
+
+// MaxProperties sets "maxProperties"
+func (b *SchemaBuilder) MaxProperties(n int) *SchemaBuilder { return b.Keyword("maxProperties", n) }
+
+// PropertyNames sets "propertyNames"
+func (b *SchemaBuilder) PropertyNames(names *SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("propertyNames", names)
+}
+
+// DependentRequired adds a "dependencies" entry requiring props whenever
+// the named property is present
+func (b *SchemaBuilder) DependentRequired(name string, props ...string) *SchemaBuilder {
+	return b.addTo("dependencies", name, props)
+}
+
+// DependentSchema adds a "dependencies" entry the object must be valid
+// against whenever the named property is present
+func (b *SchemaBuilder) DependentSchema(name string, dep *SchemaBuilder) *SchemaBuilder {
+	return b.addTo("dependencies", name, dep)
+}
+
+// Ref sets "$ref". Validation ignores the other keywords of a schema with
+// a reference
+func (b *SchemaBuilder) Ref(uri string) *SchemaBuilder { return b.Keyword("$ref", uri) }
+
+// AllOf sets "allOf", requiring instances to be valid against every schema
+func (b *SchemaBuilder) AllOf(schemas ...*SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("allOf", schemas)
+}
+
+// AnyOf sets "anyOf", requiring instances to be valid against at least one schema
+func (b *SchemaBuilder) AnyOf(schemas ...*SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("anyOf", schemas)
+}
+
+// OneOf sets "oneOf", requiring instances to be valid against exactly one schema
+func (b *SchemaBuilder) OneOf(schemas ...*SchemaBuilder) *SchemaBuilder {
+	return b.Keyword("oneOf", schemas)
+}
+
+// Not sets "not", requiring instances to be invalid against the schema
+func (b *SchemaBuilder) Not(sch *SchemaBuilder) *SchemaBuilder { return b.Keyword("not", sch) }
+
+// If sets "if". Use it with Then and Else
+func (b *SchemaBuilder) If(cond *SchemaBuilder) *SchemaBuilder { return b.Keyword("if", cond) }
+
+// Then sets "then", applied to instances valid against "if"
+func (b *SchemaBuilder) Then(sch *SchemaBuilder) *SchemaBuilder { return b.Keyword("then", sch) }
+
+// Else sets "else", applied to instances invalid against "if"
+func (b *SchemaBuilder) Else(sch *SchemaBuilder) *SchemaBuilder { return b.Keyword("else", sch) }
+
+// addTo adds an entry to a keyword whose value is an object
+func (b *SchemaBuilder) addTo(keyword, name string, value interface{}) *SchemaBuilder {
+	obj, ok := b.keywords[keyword].(map[string]interface{})
+	if !ok {
+		obj = map[string]interface{}{}
+	}
+	obj[name] = value
+	return b.Keyword(keyword, obj)
+}
+
+// MarshalJSON implements the json.Marshaler interface for SchemaBuilder
+func (b *SchemaBuilder) MarshalJSON() ([]byte, error) {
+	if b.boolean != nil {
+		return json.Marshal(*b.boolean)
+	}
+	return json.Marshal(b.keywords)
+}
+
+// BuildRoot decodes the builder into a root schema, resolving references
+// against it
+func (b *SchemaBuilder) BuildRoot() (*RootSchema, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding schema: %s", err.Error())
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// Build decodes the builder into a schema. References in the schema are
+// resolved with the schema as their root document
+func (b *SchemaBuilder) Build() (*Schema, error) {
+	rs, err := b.BuildRoot()
+	if err != nil {
+		return nil, err
+	}
+	return &rs.Schema, nil
+}
+
+// MustBuild is like Build but panics if the schema is invalid, for schemas
+// built from constant values
+func (b *SchemaBuilder) MustBuild() *Schema {
+	sch, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return sch
+}
+
+// And combines the schema with others in an "allOf". The new schema shares
+// its subschemas with the originals rather than copying them
+func (s *Schema) And(others ...*Schema) *Schema {
+	all := AllOf(append([]*Schema{s}, others...))
+	return &Schema{Validators: map[string]Validator{"allOf": &all}}
+}
+
+// Or combines the schema with others in an "anyOf". The new schema shares
+// its subschemas with the originals rather than copying them
+func (s *Schema) Or(others ...*Schema) *Schema {
+	anyOf := AnyOf(append([]*Schema{s}, others...))
+	return &Schema{Validators: map[string]Validator{"anyOf": &anyOf}}
+}
+
+// Negate wraps the schema in a "not", giving a schema that accepts exactly
+// the instances s rejects
+func (s *Schema) Negate() *Schema {
+	return &Schema{Validators: map[string]Validator{"not": (*Not)(s)}}
+}
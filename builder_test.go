@@ -0,0 +1,115 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	sch, err := NewObject().
+		Title("person").
+		Property("name", String().MinLength(1)).
+		Property("age", Integer().Minimum(0)).
+		Property("tags", Array(String()).UniqueItems()).
+		Property("friend", NewSchema().Keyword("$ref", "#")).
+		PatternProperty("^x-", True()).
+		AdditionalProperties(False()).
+		Required("name").
+		Required("age").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.Marshal(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"additionalProperties":false,"patternProperties":{"^x-":true},"properties":{"age":{"minimum":0,"type":"integer"},"friend":{"$ref":"#"},"name":{"minLength":1,"type":"string"},"tags":{"items":{"type":"string"},"type":"array","uniqueItems":true}},"required":["name","age"],"title":"person","type":"object"}`
+	if string(got) != expect {
+		t.Errorf("encoding mismatch.\nexpected: %s\ngot:      %s", expect, got)
+	}
+
+	cases := []struct {
+		doc    interface{}
+		errors int
+	}{
+		{map[string]interface{}{"name": "a", "age": float64(1)}, 0},
+		{map[string]interface{}{"name": "", "age": float64(-1)}, 2},
+		{map[string]interface{}{"name": "a", "age": float64(1), "x-id": 1, "other": 1}, 1},
+		{map[string]interface{}{"name": "a", "age": float64(1), "friend": map[string]interface{}{"name": "b"}}, 1},
+	}
+	for i, c := range cases {
+		errs := []ValError{}
+		sch.Validate("/", c.doc, &errs)
+		if len(errs) != c.errors {
+			t.Errorf("case %d: expected %d errors, got: %v", i, c.errors, errs)
+		}
+	}
+
+	if _, err := String().Pattern("(").Build(); err == nil {
+		t.Errorf("expected an invalid pattern to fail to build")
+	}
+
+	base := Must(`{ "type": "string", "maxLength": 3 }`)
+	sch = FromSchema(&base.Schema).MinLength(1).MustBuild()
+	errs := []ValError{}
+	sch.Validate("/", "abcd", &errs)
+	if len(errs) != 1 {
+		t.Errorf("expected FromSchema to keep existing keywords, got errors: %v", errs)
+	}
+}
+
+func TestSchemaComposition(t *testing.T) {
+	sch := NewObject().
+		Definition("id", OneOfSchema(String().MinLength(1), Integer().Minimum(1))).
+		Property("id", Ref("#/definitions/id")).
+		Property("kind", NotSchema(NewSchema().Const("legacy"))).
+		If(NewSchema().Property("kind", NewSchema().Const("user")).Required("kind")).
+		Then(NewSchema().Required("email")).
+		MustBuild()
+
+	cases := []struct {
+		doc    string
+		errors int
+	}{
+		{`{ "id": "a" }`, 0},
+		{`{ "id": 0 }`, 1},
+		{`{ "id": 2, "kind": "legacy" }`, 1},
+		{`{ "kind": "user" }`, 1},
+		{`{ "kind": "user", "email": "a@b.c" }`, 0},
+	}
+	for i, c := range cases {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(c.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		errs := []ValError{}
+		sch.Validate("/", doc, &errs)
+		if len(errs) != c.errors {
+			t.Errorf("case %d: expected %d errors, got: %v", i, c.errors, errs)
+		}
+	}
+
+	str, num := String().MustBuild(), Number().MustBuild()
+	short := String().MaxLength(2).MustBuild()
+	combined := []struct {
+		sch    *Schema
+		data   interface{}
+		errors int
+	}{
+		{str.Or(num), float64(1), 0},
+		{str.Or(num), true, 1},
+		{str.And(short), "abc", 1},
+		{str.And(short), "ab", 0},
+		{str.Negate(), "ab", 1},
+		{str.Negate(), float64(1), 0},
+	}
+	for i, c := range combined {
+		errs := []ValError{}
+		c.sch.Validate("/", c.data, &errs)
+		if len(errs) != c.errors {
+			t.Errorf("combinator case %d: expected %d errors, got: %v", i, c.errors, errs)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// BundleOptions configures Bundle
+type BundleOptions struct {
+	// BaseURI is the location of the root document, which its relative
+	// references are resolved against. It defaults to the document's "$id".
+	// Local files are given as file URLs
+	BaseURI string
+	// Catalog maps the URLs of documents to local files to read them from
+	// in place of fetching them
+	Catalog map[string]string
+	// Documents maps the URLs of documents to their contents, taking
+	// precedence over the catalog
+	Documents map[string][]byte
+	// Allow lists the URL prefixes documents may be loaded from, catalog
+	// entries and documents aside. If empty any document may be loaded
+	Allow []string
+}
+
+// Bundle makes a self-contained copy of rs, loading every document it
+// references directly or through other documents, from local files or over
+// HTTP, and embedding each one under "$defs". References are rewritten to
+// point at the embedded copies, which lose their "$id" and "$schema" so
+// they resolve against the bundle. rs itself isn't modified
+func Bundle(rs *RootSchema, opts BundleOptions) (*RootSchema, error) {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		// boolean schemas can't reference anything
+		return rs.Clone(), nil
+	}
+
+	base := opts.BaseURI
+	if base == "" {
+		base = rs.ID
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URI %q: %s", base, err.Error())
+	}
+	b := &bundler{
+		opts:     opts,
+		root:     root,
+		embedded: map[string]string{bundleDocURL(baseURL): ""},
+	}
+	if err := b.rewrite(root, baseURL); err != nil {
+		return nil, err
+	}
+	if len(b.defs) > 0 {
+		defs, _ := root["$defs"].(map[string]interface{})
+		if defs == nil {
+			defs = map[string]interface{}{}
+			root["$defs"] = defs
+		}
+		for _, name := range b.names {
+			defs[name] = b.defs[name]
+		}
+	}
+
+	if data, err = json.Marshal(root); err != nil {
+		return nil, err
+	}
+	bundled := &RootSchema{}
+	if err := json.Unmarshal(data, bundled); err != nil {
+		return nil, err
+	}
+	return bundled, nil
+}
+
+// bundler collects embedded documents. embedded maps the URL of each
+// document, without a fragment, to the JSON pointer of its copy within the
+// bundle
+type bundler struct {
+	opts     BundleOptions
+	root     map[string]interface{}
+	embedded map[string]string
+	names    []string
+	defs     map[string]interface{}
+}
+
+// rewrite points the references of the schema v, within the document at
+// base, into the bundle
+func (b *bundler) rewrite(v interface{}, base *url.URL) error {
+	return mapRefs(v, func(ref string) (string, error) {
+		return b.target(ref, base)
+	})
+}
+
+// mapRefs replaces the value of each "$ref" within the decoded schema v
+// with the result of calling fn on it
+func mapRefs(v interface{}, fn func(ref string) (string, error)) error {
+	switch t := v.(type) {
+	case []interface{}:
+		for _, el := range t {
+			if err := mapRefs(el, fn); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range sortedMapKeys(t) {
+			val := t[key]
+			switch key {
+			case "$ref":
+				ref, ok := val.(string)
+				if !ok {
+					continue
+				}
+				target, err := fn(ref)
+				if err != nil {
+					return err
+				}
+				t[key] = target
+			case "enum", "const", "default", "examples":
+				// values, not schemas
+			case "properties", "patternProperties", "definitions", "$defs", "dependencies":
+				// keyed by name, so names like "$ref" aren't keywords
+				if named, ok := val.(map[string]interface{}); ok {
+					for _, name := range sortedMapKeys(named) {
+						if err := mapRefs(named[name], fn); err != nil {
+							return err
+						}
+					}
+				}
+			default:
+				if err := mapRefs(val, fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// target gives the reference within the bundle for ref, found in the
+// document at base, embedding the document it refers to
+func (b *bundler) target(ref string, base *url.URL) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference %q: %s", ref, err.Error())
+	}
+	u = base.ResolveReference(u)
+	frag := u.Fragment
+	if frag != "" && frag[0] != '/' {
+		// a plain-name fragment can't be rewritten into a pointer
+		return ref, nil
+	}
+	docURL := bundleDocURL(u)
+	if docPtr, ok := b.embedded[docURL]; ok {
+		return "#" + docPtr + frag, nil
+	}
+
+	doc, err := b.load(docURL)
+	if err != nil {
+		return "", err
+	}
+	name := uniqueBundleName(b.defs, b.root, bundleDocName(u))
+	docPtr := "/$defs/" + EscapePointerToken(name)
+	b.embedded[docURL] = docPtr
+	if b.defs == nil {
+		b.defs = map[string]interface{}{}
+	}
+	b.names = append(b.names, name)
+	b.defs[name] = doc
+	if obj, ok := doc.(map[string]interface{}); ok {
+		delete(obj, "$id")
+		delete(obj, "$schema")
+	}
+	if err := b.rewrite(doc, u); err != nil {
+		return "", err
+	}
+	return "#" + docPtr + frag, nil
+}
+
+// load reads the document at docURL from the given documents, the catalog,
+// a local file or over HTTP
+func (b *bundler) load(docURL string) (interface{}, error) {
+	var data []byte
+	var err error
+	if doc, ok := b.opts.Documents[docURL]; ok {
+		data = doc
+	} else if file, ok := b.opts.Catalog[docURL]; ok {
+		data, err = ioutil.ReadFile(file)
+	} else {
+		if !b.allowed(docURL) {
+			return nil, fmt.Errorf("loading %s isn't allowed", docURL)
+		}
+		data, err = fetchDocument(docURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %s", docURL, err.Error())
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %s", docURL, err.Error())
+	}
+	return doc, nil
+}
+
+func (b *bundler) allowed(docURL string) bool {
+	if len(b.opts.Allow) == 0 {
+		return true
+	}
+	for _, prefix := range b.opts.Allow {
+		if strings.HasPrefix(docURL, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchDocument reads a file URL, or a URL without a scheme as a file
+// path, or fetches an HTTP URL
+func fetchDocument(docURL string) ([]byte, error) {
+	u, err := url.Parse(docURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "", "file":
+		return ioutil.ReadFile(u.Path)
+	case "http", "https":
+		res, err := http.Get(docURL)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", res.Status)
+		}
+		return ioutil.ReadAll(res.Body)
+	}
+	return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+}
+
+// bundleDocURL gives u without its fragment
+func bundleDocURL(u *url.URL) string {
+	doc := *u
+	doc.Fragment = ""
+	return strings.TrimSuffix(doc.String(), "#")
+}
+
+// bundleDocName names the definition embedding the document at u after
+// its file name
+func bundleDocName(u *url.URL) string {
+	name := path.Base(u.Path)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		name = u.Hostname()
+	}
+	if name == "" {
+		name = "schema"
+	}
+	return name
+}
+
+// uniqueBundleName adds a numeric suffix to name if an embedded document
+// or a "$defs" entry of root already has it
+func uniqueBundleName(defs map[string]interface{}, root map[string]interface{}, name string) string {
+	existing, _ := root["$defs"].(map[string]interface{})
+	taken := func(n string) bool {
+		_, a := defs[n]
+		_, b := existing[n]
+		return a || b
+	}
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := fmt.Sprintf("%s%d", name, i); !taken(n) {
+			return n
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"$id": "ignored", "type": "string", "definitions": {"short": {"maxLength": 3}}}`))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"pet.json": `{
+			"type": "object",
+			"properties": {
+				"name": {"$ref": "` + srv.URL + `/name.json"},
+				"owner": {"$ref": "people/person.json#/definitions/person"}
+			}
+		}`,
+		"people/person.json": `{
+			"definitions": {
+				"person": {"properties": {"nick": {"$ref": "` + srv.URL + `/name.json#/definitions/short"}, "pet": {"$ref": "../pet.json"}}}
+			}
+		}`,
+		"catalog.json": `{"type": "integer"}`,
+	}
+	for name, data := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rs := Must(`{
+		"properties": {
+			"pet": {"$ref": "pet.json"},
+			"age": {"$ref": "https://example.com/age.json"},
+			"self": {"$ref": "#/$defs/pet"}
+		},
+		"$defs": {"pet": {"type": "null"}}
+	}`)
+	bundled, err := Bundle(rs, BundleOptions{
+		BaseURI: "file://" + filepath.ToSlash(dir) + "/",
+		Catalog: map[string]string{"https://example.com/age.json": filepath.Join(dir, "catalog.json")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(bundled)
+	for _, want := range []string{
+		`"age":{"$ref":"#/$defs/age"}`,
+		`"pet":{"$ref":"#/$defs/pet2"}`,
+		`"self":{"$ref":"#/$defs/pet"}`,
+		`"name":{"$ref":"#/$defs/name"}`,
+		`"owner":{"$ref":"#/$defs/person/definitions/person"}`,
+		`"nick":{"$ref":"#/$defs/name/definitions/short"}`,
+		`"pet":{"$ref":"#/$defs/pet2"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected bundle to contain %s, got %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "ignored") {
+		t.Errorf("expected embedded documents to lose their $id, got %s", data)
+	}
+
+	errs := []ValError{}
+	doc := map[string]interface{}{"age": 1.0, "pet": map[string]interface{}{"name": "rex", "owner": map[string]interface{}{"nick": "toolong"}}}
+	bundled.Validate("/", doc, &errs)
+	if len(errs) != 1 {
+		t.Errorf("expected the bundle to validate nested references, got errors %v", errs)
+	}
+
+	_, err = Bundle(Must(`{"$ref": "`+srv.URL+`/name.json"}`), BundleOptions{Allow: []string{"https://example.com/"}})
+	if err == nil || !strings.Contains(err.Error(), "isn't allowed") {
+		t.Errorf("expected loading outside the allow list to fail, got %v", err)
+	}
+}
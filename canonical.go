@@ -0,0 +1,134 @@
+package jsonschema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"sort"
+)
+
+// Canonical encodes the root schema in canonical form. Two schemas that
+// differ only in member ordering, number formatting or the ordering of
+// set-like keyword values have identical canonical encodings:
+//   - object members are written in sorted order without insignificant whitespace
+//   - numbers are written in their shortest form
+//   - "type" is a string when it names a single type, and a sorted set of
+//     names otherwise
+//   - "required", "enum", dependency property lists and patternProperties
+//     are sorted, with duplicates removed
+func (rs *RootSchema) Canonical() ([]byte, error) {
+	cp := rs.Clone()
+	if err := canonicalize(&cp.Schema); err != nil {
+		return nil, err
+	}
+	return json.Marshal(cp)
+}
+
+// Canonical encodes the schema in canonical form. See RootSchema.Canonical
+// for details
+func (s *Schema) Canonical() ([]byte, error) {
+	cp := s.Clone()
+	if err := canonicalize(cp); err != nil {
+		return nil, err
+	}
+	return json.Marshal(cp)
+}
+
+// Fingerprint is a SHA-256 hash of the canonical encoding of the root
+// schema, stable across processes and releases for equivalent schemas.
+// Schemas that cannot be encoded to JSON give the zero fingerprint
+func (rs *RootSchema) Fingerprint() [32]byte {
+	data, err := rs.Canonical()
+	if err != nil {
+		return [32]byte{}
+	}
+	return sha256.Sum256(data)
+}
+
+// Fingerprint is a SHA-256 hash of the canonical encoding of the schema.
+// See RootSchema.Fingerprint for details
+func (s *Schema) Fingerprint() [32]byte {
+	data, err := s.Canonical()
+	if err != nil {
+		return [32]byte{}
+	}
+	return sha256.Sum256(data)
+}
+
+// canonicalize rewrites s and every subschema of s into canonical form in place
+func canonicalize(s *Schema) error {
+	return walkSchemas("", s, func(_ string, sch *Schema) error {
+		for key, raw := range sch.extraKeywords {
+			c, err := canonicalJSON(raw)
+			if err != nil {
+				return err
+			}
+			sch.extraKeywords[key] = c
+		}
+
+		for _, v := range sch.Validators {
+			switch t := v.(type) {
+			case *Type:
+				t.vals = uniqueStrings(t.vals)
+				t.strVal = len(t.vals) == 1
+			case *Required:
+				*t = Required(uniqueStrings(*t))
+			case *Const:
+				c, err := canonicalJSON(*t)
+				if err != nil {
+					return err
+				}
+				*t = Const(c)
+			case *Enum:
+				enum := Enum{}
+				for _, con := range *t {
+					c, err := canonicalJSON(con)
+					if err != nil {
+						return err
+					}
+					enum = append(enum, Const(c))
+				}
+				sort.Slice(enum, func(i, j int) bool { return bytes.Compare(enum[i], enum[j]) < 0 })
+				*t = Enum{}
+				for i, con := range enum {
+					if i == 0 || !bytes.Equal(con, enum[i-1]) {
+						*t = append(*t, con)
+					}
+				}
+			case *Dependencies:
+				for key, dep := range *t {
+					if dep.schema == nil {
+						dep.props = uniqueStrings(dep.props)
+						(*t)[key] = dep
+					}
+				}
+			case *PatternProperties:
+				sort.SliceStable(*t, func(i, j int) bool { return (*t)[i].key < (*t)[j].key })
+			}
+		}
+		return nil
+	})
+}
+
+// canonicalJSON re-encodes a JSON value with sorted object members and
+// normalized numbers
+func canonicalJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// uniqueStrings returns a sorted copy of strs with duplicates removed
+func uniqueStrings(strs []string) []string {
+	sorted := append([]string{}, strs...)
+	sort.Strings(sorted)
+	res := []string{}
+	for i, str := range sorted {
+		if i == 0 || str != sorted[i-1] {
+			res = append(res, str)
+		}
+	}
+	return res
+}
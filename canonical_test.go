@@ -0,0 +1,57 @@
+package jsonschema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	cases := []struct {
+		input, expect string
+	}{
+		{`true`, `true`},
+		{`{ "type": ["string"], "maximum" : 1.0 }`, `{"maximum":1,"type":"string"}`},
+		{`{ "type": ["string", "null", "string"] }`, `{"type":["null","string"]}`},
+		{`{ "required": ["b", "a", "b"], "enum": [{"b": 1, "a": 2.50}, "x", "x"] }`,
+			`{"enum":["x",{"a":2.5,"b":1}],"required":["a","b"]}`},
+		{`{ "patternProperties": { "^z": { "type": ["integer"] }, "^a": true } }`,
+			`{"patternProperties":{"^a":true,"^z":{"type":"integer"}}}`},
+		{`{ "dependencies": { "a": ["c", "b"] }, "x-tag": { "z": 1, "a": [1e2] } }`,
+			`{"dependencies":{"a":["b","c"]},"x-tag":{"a":[100],"z":1}}`},
+		{`{ "$schema": "http://json-schema.org/draft-07/schema#", "items": { "const": 1.50 } }`,
+			`{"$schema":"http://json-schema.org/draft-07/schema#","items":{"const":1.5}}`},
+	}
+
+	for i, c := range cases {
+		got, err := Must(c.input).Canonical()
+		if err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+			continue
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d mismatch.\nexpected: %s\ngot:      %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Must(`{ "type": ["string", "null"], "maxLength": 2 }`)
+	b := Must(`{ "maxLength": 2, "type": ["null", "string"] }`)
+	c := Must(`{ "maxLength": 3, "type": ["null", "string"] }`)
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("expected equivalent schemas to have equal fingerprints")
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Errorf("expected different schemas to have different fingerprints")
+	}
+	if a.Fingerprint() == [32]byte{} {
+		t.Errorf("expected non-zero fingerprint")
+	}
+
+	// the encoding of a fingerprint must never change between releases
+	expect := "00404e686415370f1711c4d7acfa2905444d3cf23cef2e10c47d445ebe690f96"
+	if got := fmt.Sprintf("%x", Must(`{"type":"string"}`).Fingerprint()); got != expect {
+		t.Errorf("fingerprint mismatch. expected: %s, got: %s", expect, got)
+	}
+}
@@ -0,0 +1,328 @@
+package jsonschema
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ValidateCBOR performs schema validation against a CBOR-encoded instance,
+// decoded by DecodeCBOR with the decoding options of rs
+func (rs *RootSchema) ValidateCBOR(data []byte) ([]ValError, error) {
+	return rs.ValidateEncoded(data, rs.options().Decoding.DecodeCBOR)
+}
+
+// DecodeCBOR decodes a single CBOR data item (RFC 8949) into the values
+// validation works with, converting it as the RFC's JSON mapping does:
+// integers, including bignums, and floats become numbers, byte strings
+// become base64url strings, or base64 or base16 strings within tags 22
+// and 23, and undefined becomes null. Integer map keys, as COSE uses,
+// become decimal strings, and other tags are dropped in favor of their
+// content. Maps with other keys or duplicate keys, infinities, NaN and
+// simple values other than booleans, null and undefined are errors. It's
+// an InstanceDecoder
+func DecodeCBOR(data []byte) (interface{}, error) {
+	return DecodeOptions{}.DecodeCBOR(data)
+}
+
+// DecodeCBOR decodes a single CBOR data item as the DecodeCBOR function
+// does, with the options o
+func (o DecodeOptions) DecodeCBOR(data []byte) (interface{}, error) {
+	d := &cborDecoder{data: data, enc: base64.RawURLEncoding.EncodeToString, keys: o.keyInterner(), opts: o}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, d.errorf("unexpected data after the top-level item")
+	}
+	return v, nil
+}
+
+// cborMaxDepth bounds the nesting of CBOR arrays, maps and tags
+const cborMaxDepth = 1000
+
+// cborBreak is the "break" stop code ending indefinite-length items
+const cborBreak = 0xff
+
+// cborDecoder reads CBOR data items from data. enc encodes byte strings as
+// the enclosing tags hint
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+	enc   func([]byte) string
+	keys  stringInterner
+	opts  DecodeOptions
+}
+
+func (d *cborDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("cbor: offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+// head reads the initial byte of an item and its argument. indefinite
+// reports additional info 31, which leaves arg 0
+func (d *cborDecoder) head() (major, info byte, arg uint64, indefinite bool, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, false, d.errorf("unexpected end of data")
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info == 31:
+		return major, info, 0, true, nil
+	case info > 27:
+		return 0, 0, 0, false, d.errorf("reserved additional information %d", info)
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.pos < size {
+		return 0, 0, 0, false, d.errorf("unexpected end of data")
+	}
+	for _, c := range d.data[d.pos : d.pos+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.pos += size
+	return major, info, arg, false, nil
+}
+
+// value reads a data item
+func (d *cborDecoder) value() (interface{}, error) {
+	if d.depth++; d.depth > cborMaxDepth {
+		return nil, d.errorf("items nested too deeply")
+	}
+	defer func() { d.depth-- }()
+
+	start := d.pos
+	major, info, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if indefinite && (major < 2 || major == 6) {
+		return nil, d.errorf("indefinite length isn't allowed for major type %d", major)
+	}
+	switch major {
+	case 0:
+		return d.opts.decodedUint(arg), nil
+	case 1:
+		if d.opts.UseNumber {
+			n := new(big.Int).SetUint64(arg)
+			return json.Number(n.Neg(n.Add(n, big.NewInt(1))).String()), nil
+		}
+		return -1 - float64(arg), nil
+	case 2:
+		d.pos = start
+		b, err := d.bytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return d.enc(b), nil
+	case 3:
+		d.pos = start
+		b, err := d.bytes(3)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, d.errorf("text string isn't valid UTF-8")
+		}
+		return string(b), nil
+	case 4:
+		arr := []interface{}{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case 5:
+		obj := map[string]interface{}{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			keyPos := d.pos
+			key, err := d.key()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := obj[key]; ok {
+				d.pos = keyPos
+				return nil, d.errorf("duplicate map key %q", key)
+			}
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+		return obj, nil
+	case 6:
+		return d.tagged(arg)
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return d.float(cborHalf(uint16(arg)))
+	case 26:
+		return d.float(float64(math.Float32frombits(uint32(arg))))
+	case 27:
+		return d.float(math.Float64frombits(arg))
+	case 31:
+		return nil, d.errorf("unexpected break")
+	}
+	return nil, d.errorf("unsupported simple value %d", arg)
+}
+
+// atBreak reports whether the break code ending an indefinite-length item
+// is next, moving past it if so
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// bytes reads a byte or text string of the given major type, joining the
+// chunks of indefinite-length strings
+func (d *cborDecoder) bytes(major byte) ([]byte, error) {
+	m, _, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if m != major {
+		return nil, d.errorf("expected a chunk of major type %d, got %d", major, m)
+	}
+	if !indefinite {
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, d.errorf("unexpected end of data")
+		}
+		b := d.data[d.pos : d.pos+int(arg)]
+		d.pos += int(arg)
+		return b, nil
+	}
+	buf := []byte{}
+	for !d.atBreak() {
+		chunk, err := d.definiteBytes(major)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+	}
+	return buf, nil
+}
+
+// definiteBytes reads a chunk of an indefinite-length string, which must
+// have a definite length
+func (d *cborDecoder) definiteBytes(major byte) ([]byte, error) {
+	if d.pos < len(d.data) && d.data[d.pos]&0x1f == 31 {
+		return nil, d.errorf("chunks of indefinite-length strings can't be indefinite")
+	}
+	return d.bytes(major)
+}
+
+// key reads a map key, which must be a text string or an integer
+func (d *cborDecoder) key() (string, error) {
+	if d.pos >= len(d.data) {
+		return "", d.errorf("unexpected end of data")
+	}
+	switch major := d.data[d.pos] >> 5; major {
+	case 0, 1:
+		_, _, arg, _, err := d.head()
+		if err != nil {
+			return "", err
+		}
+		if major == 0 {
+			return strconv.FormatUint(arg, 10), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return n.Neg(n.Add(n, big.NewInt(1))).String(), nil
+	case 3:
+		v, err := d.value()
+		if err != nil {
+			return "", err
+		}
+		return d.keys.string(v.(string)), nil
+	}
+	return "", d.errorf("map keys must be text strings or integers")
+}
+
+// tagged reads the content of an item with tag number tag
+func (d *cborDecoder) tagged(tag uint64) (interface{}, error) {
+	switch tag {
+	case 2, 3:
+		// bignums
+		b, err := d.bytes(2)
+		if err != nil {
+			return nil, err
+		}
+		n := new(big.Int).SetBytes(b)
+		if tag == 3 {
+			n.Neg(n.Add(n, big.NewInt(1)))
+		}
+		if d.opts.UseNumber {
+			return json.Number(n.String()), nil
+		}
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return d.float(f)
+	case 21, 22, 23:
+		// expected conversions of the byte strings within
+		prev := d.enc
+		d.enc = map[uint64]func([]byte) string{
+			21: base64.RawURLEncoding.EncodeToString,
+			22: base64.StdEncoding.EncodeToString,
+			23: hex.EncodeToString,
+		}[tag]
+		defer func() { d.enc = prev }()
+	}
+	return d.value()
+}
+
+// float gives the number f, which must be finite
+func (d *cborDecoder) float(f float64) (interface{}, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, d.errorf("%v can't be represented in JSON", f)
+	}
+	return d.opts.decodedFloat(f), nil
+}
+
+// cborHalf converts an IEEE 754 half-precision float
+func cborHalf(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
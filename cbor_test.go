@@ -0,0 +1,139 @@
+package jsonschema
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// examples from RFC 8949 appendix A
+	cases := []struct {
+		hex, json string
+	}{
+		{"00", `0`},
+		{"17", `23`},
+		{"1818", `24`},
+		{"1903e8", `1000`},
+		{"1a000f4240", `1000000`},
+		{"1b000000e8d4a51000", `1000000000000`},
+		{"20", `-1`},
+		{"3903e7", `-1000`},
+		{"c249010000000000000000", `18446744073709552000`},
+		{"f90000", `0`},
+		{"f93c00", `1`},
+		{"f93e00", `1.5`},
+		{"f97bff", `65504`},
+		{"f90001", `5.960464477539063e-8`},
+		{"fa47c35000", `100000`},
+		{"fb3ff199999999999a", `1.1`},
+		{"f4", `false`},
+		{"f5", `true`},
+		{"f6", `null`},
+		{"f7", `null`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"c11a514b67b0", `1363896240`},
+		{"4401020304", `"AQIDBA"`},
+		{"d74401020304", `"01020304"`},
+		{"d6830102d74401020304", `[1,2,"01020304"]`},
+		{"6449455446", `"IETF"`},
+		{"62c3bc", `"ü"`},
+		{"83010203", `[1,2,3]`},
+		{"8301820203820405", `[1,[2,3],[4,5]]`},
+		{"a201020304", `{"1":2,"3":4}`},
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+		{"a1200a", `{"-1":10}`},
+		{"5f42010243030405ff", `"AQIDBAU"`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+	}
+	for _, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		v, err := DecodeCBOR(data)
+		if err != nil {
+			t.Errorf("%s: %s", c.hex, err.Error())
+			continue
+		}
+		got, _ := json.Marshal(v)
+		if string(got) != c.json {
+			t.Errorf("%s: expected %s, got %s", c.hex, c.json, got)
+		}
+	}
+}
+
+func TestDecodeCBORErrors(t *testing.T) {
+	cases := []struct {
+		hex, err string
+	}{
+		{"", "cbor: offset 0: unexpected end of data"},
+		{"1903", "cbor: offset 1: unexpected end of data"},
+		{"0000", "cbor: offset 1: unexpected data after the top-level item"},
+		{"1c", "cbor: offset 1: reserved additional information 28"},
+		{"a2616101616102", "cbor: offset 4: duplicate map key \"a\""},
+		{"a1f401", "cbor: offset 1: map keys must be text strings or integers"},
+		{"f97c00", "cbor: offset 3: +Inf can't be represented in JSON"},
+		{"f97e00", "cbor: offset 3: NaN can't be represented in JSON"},
+		{"62c328", "cbor: offset 3: text string isn't valid UTF-8"},
+		{"f0", "cbor: offset 1: unsupported simple value 16"},
+		{"ff", "cbor: offset 1: unexpected break"},
+		{"5f6161ff", "cbor: offset 2: expected a chunk of major type 2, got 3"},
+		{"1f", "cbor: offset 1: indefinite length isn't allowed for major type 0"},
+		{"9bffffffffffffffff", "cbor: offset 9: unexpected end of data"},
+	}
+	for _, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		_, err := DecodeCBOR(data)
+		if err == nil {
+			t.Errorf("%s: expected error %q", c.hex, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%s: expected error %q, got %q", c.hex, c.err, err.Error())
+		}
+	}
+
+	nested, _ := hex.DecodeString(strings.Repeat("81", cborMaxDepth+1) + "00")
+	if _, err := DecodeCBOR(nested); err == nil {
+		t.Error("expected an error for deeply nested arrays")
+	}
+}
+
+func TestValidateCBOR(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["id", "temp"],
+		"properties": {
+			"id": {"type": "string", "minLength": 1},
+			"temp": {"type": "number", "minimum": -40, "maximum": 85},
+			"count": {"type": "integer"}
+		}
+	}`)
+	cases := []struct {
+		hex  string
+		errs int
+	}{
+		// {"id": "s1", "temp": 21.5, "count": 3}
+		{"a36269646273316474656d70f94d6065636f756e7403", 0},
+		// {"id": "", "temp": 100}
+		{"a2626964606474656d701864", 2},
+		// {"id": "s1", "temp": 1.5, "count": 1.5}
+		{"a36269646273316474656d70f93e0065636f756e74f93e00", 1},
+	}
+	for i, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		errs, err := rs.ValidateCBOR(data)
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != c.errs {
+			t.Errorf("case %d: expected %d errors, got %v", i, c.errs, errs)
+		}
+	}
+
+	if _, err := rs.ValidateCBOR([]byte{0xa1}); err == nil {
+		t.Error("expected an error for truncated CBOR")
+	}
+}
@@ -0,0 +1,390 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// Clone returns an independent deep copy of the root schema. Patterns are
+// recompiled, and references that resolve within the schema point into the
+// copy. References fetched from remote documents remain shared. A copy of a
+// schema memoizing references memoizes them too, with results of its own.
+func (rs *RootSchema) Clone() *RootSchema {
+	cp := &RootSchema{SchemaURI: rs.SchemaURI}
+	c := newCloner()
+	c.roots[rs] = cp
+	c.schemas[&rs.Schema] = &cp.Schema
+	c.copyInto(&cp.Schema, &rs.Schema)
+	c.relink()
+	cp.bindData()
+	cp.opts = rs.opts
+	if rs.memo != nil {
+		cp.MemoizeRefs(true)
+	}
+	return cp
+}
+
+// Clone returns an independent deep copy of the schema. Patterns are
+// recompiled, and references that resolve within the schema point into the
+// copy. References that resolve outside of s keep their original targets.
+func (s *Schema) Clone() *Schema {
+	c := newCloner()
+	cp := c.schema(s)
+	c.relink()
+	return cp
+}
+
+// cloner deep-copies schemas, tracking already-copied values so shared
+// pointers (if/then/else, additionalProperties siblings, references) stay
+// shared in the copy
+type cloner struct {
+	roots    map[*RootSchema]*RootSchema
+	schemas  map[*Schema]*Schema
+	props    map[*Properties]*Properties
+	patterns map[*PatternProperties]*PatternProperties
+}
+
+func newCloner() *cloner {
+	return &cloner{
+		roots:    map[*RootSchema]*RootSchema{},
+		schemas:  map[*Schema]*Schema{},
+		props:    map[*Properties]*Properties{},
+		patterns: map[*PatternProperties]*PatternProperties{},
+	}
+}
+
+func (c *cloner) schema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	if cp, ok := c.schemas[s]; ok {
+		return cp
+	}
+	cp := &Schema{}
+	c.schemas[s] = cp
+	c.copyInto(cp, s)
+	return cp
+}
+
+func (c *cloner) copyInto(dst, src *Schema) {
+	*dst = Schema{
+		schemaType:       src.schemaType,
+		ID:               src.ID,
+		Title:            src.Title,
+		Description:      src.Description,
+		Default:          cloneValue(src.Default),
+		ReadOnly:         cloneBool(src.ReadOnly),
+		WriteOnly:        cloneBool(src.WriteOnly),
+		Comment:          src.Comment,
+		Ref:              src.Ref,
+		Format:           src.Format,
+		ref:              src.ref,
+		Definitions:      c.definitions(src.Definitions),
+		Defs:             c.definitions(src.Defs),
+		extraDefinitions: c.definitions(src.extraDefinitions),
+		path:             src.path,
+		opts:             src.opts,
+	}
+	if src.Examples != nil {
+		dst.Examples = make([]interface{}, len(src.Examples))
+		for i, ex := range src.Examples {
+			dst.Examples[i] = cloneValue(ex)
+		}
+	}
+	if src.extraKeywords != nil {
+		dst.extraKeywords = map[string]json.RawMessage{}
+		for key, raw := range src.extraKeywords {
+			dst.extraKeywords[key] = append(json.RawMessage{}, raw...)
+		}
+	}
+	if src.Validators != nil {
+		dst.Validators = map[string]Validator{}
+		for key, v := range src.Validators {
+			dst.Validators[key] = c.validator(key, v)
+		}
+		dst.linkSiblings()
+	}
+}
+
+func (c *cloner) definitions(defs Definitions) Definitions {
+	if defs == nil {
+		return nil
+	}
+	cp := Definitions{}
+	for key, sch := range defs {
+		cp[key] = c.schema(sch)
+	}
+	return cp
+}
+
+func (c *cloner) schemaSlice(ss []*Schema) []*Schema {
+	if ss == nil {
+		return nil
+	}
+	cp := make([]*Schema, len(ss))
+	for i, sch := range ss {
+		cp[i] = c.schema(sch)
+	}
+	return cp
+}
+
+func (c *cloner) properties(p *Properties) *Properties {
+	if p == nil {
+		return nil
+	}
+	if cp, ok := c.props[p]; ok {
+		return cp
+	}
+	cp := Properties{}
+	c.props[p] = &cp
+	for key, sch := range *p {
+		cp[key] = c.schema(sch)
+	}
+	return &cp
+}
+
+func (c *cloner) patternProperties(p *PatternProperties) *PatternProperties {
+	if p == nil {
+		return nil
+	}
+	if cp, ok := c.patterns[p]; ok {
+		return cp
+	}
+	cp := make(PatternProperties, len(*p))
+	c.patterns[p] = &cp
+	for i, ptn := range *p {
+		cp[i] = patternSchema{
+			key:    ptn.key,
+			re:     regexp.MustCompile(ptn.re.String()),
+			schema: c.schema(ptn.schema),
+		}
+	}
+	return &cp
+}
+
+// validator copies a single keyword. Validators this package doesn't define
+// are copied with a JSON round trip through their registered ValMaker, and
+// shared if that isn't possible
+func (c *cloner) validator(key string, v Validator) Validator {
+	switch t := v.(type) {
+	case *Schema:
+		return c.schema(t)
+	case *DataRef:
+		cp := *t
+		return &cp
+	case *Type:
+		return &Type{BaseValidator: t.BaseValidator, strVal: t.strVal, vals: append([]string{}, t.vals...)}
+	case *Enum:
+		cp := make(Enum, len(*t))
+		for i, con := range *t {
+			cp[i] = append(Const{}, con...)
+		}
+		return &cp
+	case *Const:
+		cp := append(Const{}, (*t)...)
+		return &cp
+	case *MultipleOf:
+		cp := *t
+		return &cp
+	case *Maximum:
+		cp := *t
+		return &cp
+	case *ExclusiveMaximum:
+		cp := *t
+		return &cp
+	case *Minimum:
+		cp := *t
+		return &cp
+	case *ExclusiveMinimum:
+		cp := *t
+		return &cp
+	case *MaxLength:
+		cp := *t
+		return &cp
+	case *MinLength:
+		cp := *t
+		return &cp
+	case *Pattern:
+		cp := Pattern(*regexp.MustCompile((*regexp.Regexp)(t).String()))
+		return &cp
+	case *Format:
+		cp := *t
+		return &cp
+	case *AllOf:
+		cp := AllOf(c.schemaSlice(*t))
+		return &cp
+	case *AnyOf:
+		cp := AnyOf(c.schemaSlice(*t))
+		return &cp
+	case *OneOf:
+		cp := OneOf(c.schemaSlice(*t))
+		return &cp
+	case *Not:
+		return (*Not)(c.schema((*Schema)(t)))
+	case *Items:
+		return &Items{single: t.single, Schemas: c.schemaSlice(t.Schemas)}
+	case *AdditionalItems:
+		return &AdditionalItems{startIndex: t.startIndex, Schema: c.schema(t.Schema)}
+	case *MaxItems:
+		cp := *t
+		return &cp
+	case *MinItems:
+		cp := *t
+		return &cp
+	case *UniqueItems:
+		cp := *t
+		return &cp
+	case *Contains:
+		return (*Contains)(c.schema((*Schema)(t)))
+	case *MaxProperties:
+		cp := *t
+		return &cp
+	case *minProperties:
+		cp := *t
+		return &cp
+	case *Required:
+		cp := append(Required{}, (*t)...)
+		return &cp
+	case *Properties:
+		return c.properties(t)
+	case *PatternProperties:
+		return c.patternProperties(t)
+	case *AdditionalProperties:
+		return &AdditionalProperties{
+			Properties: c.properties(t.Properties),
+			patterns:   c.patternProperties(t.patterns),
+			Schema:     c.schema(t.Schema),
+			forbidden:  t.forbidden,
+		}
+	case *Dependencies:
+		cp := Dependencies{}
+		for name, dep := range *t {
+			cp[name] = Dependency{schema: c.schema(dep.schema), props: append([]string(nil), dep.props...)}
+		}
+		return &cp
+	case *PropertyNames:
+		return (*PropertyNames)(c.schema((*Schema)(t)))
+	case *If:
+		cp := &If{}
+		c.schemas[&t.Schema] = &cp.Schema
+		c.copyInto(&cp.Schema, &t.Schema)
+		if t.Then != nil {
+			cp.Then = (*Then)(c.schema((*Schema)(t.Then)))
+		}
+		if t.Else != nil {
+			cp.Else = (*Else)(c.schema((*Schema)(t.Else)))
+		}
+		return cp
+	case *Then:
+		return (*Then)(c.schema((*Schema)(t)))
+	case *Else:
+		return (*Else)(c.schema((*Schema)(t)))
+	case *Links:
+		return c.links(t)
+	case *Discriminator:
+		cp := &Discriminator{PropertyName: t.PropertyName}
+		if t.Mapping != nil {
+			cp.Mapping = make(map[string]string, len(t.Mapping))
+			for val, ref := range t.Mapping {
+				cp.Mapping[val] = ref
+			}
+		}
+		return cp
+	case *Nullable:
+		cp := *t
+		return &cp
+	case *Transform:
+		cp := append(Transform{}, (*t)...)
+		return &cp
+	case *FormatLimit:
+		// format is linked to the copied sibling by linkSiblings
+		cp := *t
+		return &cp
+	case *AllRequired:
+		cp := *t
+		return &cp
+	}
+
+	if mk, ok := DefaultValidators[key]; ok {
+		if data, err := json.Marshal(v); err == nil {
+			cp := mk()
+			if err := json.Unmarshal(data, cp); err == nil {
+				return cp
+			}
+		}
+	}
+	return v
+}
+
+func (c *cloner) links(l *Links) *Links {
+	cp := make(Links, len(*l))
+	for i, link := range *l {
+		if link == nil {
+			continue
+		}
+		lcp := *link
+		lcp.HrefSchema = c.schema(link.HrefSchema)
+		lcp.TargetSchema = c.schema(link.TargetSchema)
+		lcp.HeaderSchema = c.schema(link.HeaderSchema)
+		lcp.SubmissionSchema = c.schema(link.SubmissionSchema)
+		if link.TemplatePointers != nil {
+			lcp.TemplatePointers = make(map[string]string, len(link.TemplatePointers))
+			for name, ptr := range link.TemplatePointers {
+				lcp.TemplatePointers[name] = ptr
+			}
+		}
+		if link.TemplateRequired != nil {
+			lcp.TemplateRequired = append([]string{}, link.TemplateRequired...)
+		}
+		if link.TargetHints != nil {
+			lcp.TargetHints = cloneValue(link.TargetHints).(map[string]interface{})
+		}
+		cp[i] = &lcp
+	}
+	return &cp
+}
+
+// relink points references at the copies of their targets, where the target
+// was part of the cloned tree
+func (c *cloner) relink() {
+	for _, cp := range c.schemas {
+		switch target := cp.ref.(type) {
+		case *Schema:
+			if t, ok := c.schemas[target]; ok {
+				cp.ref = t
+			}
+		case *RootSchema:
+			if t, ok := c.roots[target]; ok {
+				cp.ref = t
+			}
+		}
+	}
+}
+
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	v := *b
+	return &v
+}
+
+// cloneValue deep-copies a standard json-decoded value
+func cloneValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		cp := make(map[string]interface{}, len(t))
+		for key, val := range t {
+			cp[key] = cloneValue(val)
+		}
+		return cp
+	case []interface{}:
+		cp := make([]interface{}, len(t))
+		for i, val := range t {
+			cp[i] = cloneValue(val)
+		}
+		return cp
+	default:
+		return v
+	}
+}
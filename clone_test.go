@@ -0,0 +1,156 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRootSchemaClone(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": { "type": "string", "pattern": "^a" },
+			"friend": { "$ref": "#" },
+			"age": { "$ref": "#/definitions/age" }
+		},
+		"patternProperties": { "^x-": { "type": "string" } },
+		"additionalProperties": false,
+		"if": { "required": ["name"] },
+		"then": { "required": ["age"] },
+		"definitions": {
+			"age": { "type": "integer", "minimum": 0 }
+		}
+	}`)
+
+	cp := rs.Clone()
+
+	before, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := json.Marshal(cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("clone encoding mismatch.\nexpected: %s\ngot:      %s", before, after)
+	}
+
+	// mutating the original must not change the clone
+	rs.Validators["type"] = &Type{vals: []string{"string"}}
+	(*rs.Validators["properties"].(*Properties))["name"].Validators["pattern"] = &Pattern{}
+	rs.Definitions["age"].Validators["minimum"] = NewMinimum()
+
+	cases := []struct {
+		doc    string
+		errors int
+	}{
+		{`{ "name": "alice", "age": 2, "friend": { "name": "ann", "age": 1 } }`, 0},
+		{`{ "name": "bob", "age": 2 }`, 1},
+		{`{ "name": "alice" }`, 1},
+		{`{ "name": "alice", "age": 2, "friend": { "name": "ann", "age": -1 } }`, 1},
+		{`{ "x-tag": "ok", "other": true }`, 1},
+	}
+
+	for i, c := range cases {
+		errs, err := cp.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err)
+			continue
+		}
+		if len(errs) != c.errors {
+			t.Errorf("case %d: expected %d errors, got: %v", i, c.errors, errs)
+		}
+	}
+}
+
+func TestSchemaClone(t *testing.T) {
+	rs := Must(`{ "properties": { "a": { "items": [{ "enum": [1, 2] }], "additionalItems": false } } }`)
+	prop := (*rs.Validators["properties"].(*Properties))["a"]
+
+	cp := prop.Clone()
+	(*prop.Validators["items"].(*Items)).Schemas[0].Validators["enum"] = &Enum{Const(`3`)}
+
+	got := []ValError{}
+	cp.Validate("/", []interface{}{float64(1)}, &got)
+	if len(got) != 0 {
+		t.Errorf("expected clone to be unaffected by changes to the original. got errors: %v", got)
+	}
+
+	got = []ValError{}
+	cp.Validate("/", []interface{}{float64(1), true}, &got)
+	if len(got) != 1 {
+		t.Errorf("expected additionalItems to be enforced on the clone. got errors: %v", got)
+	}
+}
+
+func TestCloneLaterKeywords(t *testing.T) {
+	RegisterAjvKeywords()
+	defer func() {
+		for name := range AjvKeywords {
+			delete(DefaultValidators, name)
+		}
+	}()
+
+	rs := Must(`{
+		"links": [{"rel": "owner", "href": "/users/{id}", "targetSchema": {"$ref": "#/definitions/user"}}],
+		"properties": {
+			"pet": {
+				"oneOf": [{"$ref": "#/definitions/dog"}, {"$ref": "#/definitions/cat"}],
+				"discriminator": {"propertyName": "kind", "mapping": {"puppy": "#/definitions/dog"}}
+			},
+			"code": {"type": "string", "transform": ["trim", "toUpperCase"], "enum": ["AB"]},
+			"day": {"format": "date", "formatMinimum": "2020-01-01"},
+			"owner": {"properties": {"id": {}, "name": {}}, "allRequired": true, "nullable": true}
+		},
+		"definitions": {
+			"user": {"properties": {"id": {"type": "integer"}}},
+			"dog": {"properties": {"kind": {"const": "dog"}, "barks": {"type": "boolean"}}, "required": ["barks"]},
+			"cat": {"properties": {"kind": {"const": "cat"}}}
+		}
+	}`)
+	rs.MemoizeRefs(true)
+	cp := rs.Clone()
+
+	target := (*cp.Validators["links"].(*Links))[0].TargetSchema
+	if target.ref != cp.Definitions["user"] {
+		t.Errorf("expected the link target reference to resolve within the clone")
+	}
+	if target == (*rs.Validators["links"].(*Links))[0].TargetSchema {
+		t.Errorf("expected the link target to be copied")
+	}
+	if cp.memo == nil || cp.memo == rs.memo || target.memo != cp.memo {
+		t.Errorf("expected the clone to memoize references with results of its own")
+	}
+
+	// the original is edited so only the copied keywords give these results
+	rs.Definitions["dog"].Validators["required"] = &Required{}
+	(*rs.Validators["links"].(*Links))[0].Rel = "changed"
+	(*rs.Validators["properties"].(*Properties))["code"].Validators["transform"] = &Transform{}
+
+	cases := []struct {
+		doc    string
+		errors int
+	}{
+		{`{"pet": {"kind": "dog", "barks": true}, "code": " ab ", "day": "2020-06-01", "owner": {"id": 1, "name": "x"}}`, 0},
+		{`{"pet": {"kind": "dog"}}`, 1},
+		{`{"pet": {"kind": "puppy", "barks": true}}`, 1},
+		{`{"pet": {"kind": "bird"}}`, 1},
+		{`{"code": "cd"}`, 1},
+		{`{"day": "2019-06-01"}`, 1},
+		{`{"owner": {"id": 1}}`, 1},
+		{`{"owner": null}`, 0},
+	}
+	for i, c := range cases {
+		errs, err := cp.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != c.errors {
+			t.Errorf("case %d: expected %d errors, got: %v", i, c.errors, errs)
+		}
+	}
+	if rel := (*cp.Validators["links"].(*Links))[0].Rel; rel != "owner" {
+		t.Errorf("expected the copied link to keep its relation, got %q", rel)
+	}
+}
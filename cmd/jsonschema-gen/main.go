@@ -0,0 +1,100 @@
+// Command jsonschema-gen generates Go types from a JSON schema. It's meant
+// to be run by go generate:
+//
+//	//go:generate go run github.com/qri-io/jsonschema/cmd/jsonschema-gen -o pet_gen.go pet.json
+//
+// Flags:
+//
+//	-package name   package of the generated file, defaults to $GOPACKAGE
+//	-o file         file to write, defaults to standard output
+//	-root name      name of the root type, derived from the schema title if unset
+//	-ref ref=type   use an existing Go type for a "$ref" value, may be repeated.
+//	                types from other packages are qualified by import path,
+//	                as in -ref 'common.json#/definitions/id=example.com/common.ID'
+//	-fetch          fetch remote references before generating
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// refFlags collects -ref flags
+type refFlags map[string]string
+
+func (r refFlags) String() string {
+	pairs := make([]string, 0, len(r))
+	for ref, t := range r {
+		pairs = append(pairs, ref+"="+t)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (r refFlags) Set(val string) error {
+	// refs may contain "=", go type names can't
+	idx := strings.LastIndex(val, "=")
+	if idx <= 0 || idx == len(val)-1 {
+		return fmt.Errorf("expected ref=type, got %q", val)
+	}
+	r[val[:idx]] = val[idx+1:]
+	return nil
+}
+
+func main() {
+	refs := refFlags{}
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	out := flag.String("o", "", "file to write, standard output if empty")
+	root := flag.String("root", "", "name of the root type")
+	fetch := flag.Bool("fetch", false, "fetch remote references before generating")
+	flag.Var(refs, "ref", "use an existing Go type for a $ref value, as ref=type")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: jsonschema-gen [flags] schema.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *out, *fetch, jsonschema.GoOptions{
+		Package:  *pkg,
+		RootName: *root,
+		Refs:     refs,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "jsonschema-gen: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(path, out string, fetch bool, opts jsonschema.GoOptions) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rs := &jsonschema.RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return fmt.Errorf("parsing %s: %s", path, err.Error())
+	}
+	if fetch {
+		if err := rs.FetchRemoteReferences(); err != nil {
+			return fmt.Errorf("fetching references: %s", err.Error())
+		}
+	}
+
+	src, err := jsonschema.GenerateGo(rs, opts)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
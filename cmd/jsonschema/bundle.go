@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// catalogFlags collects -catalog flags
+type catalogFlags map[string]string
+
+func (c catalogFlags) String() string {
+	pairs := make([]string, 0, len(c))
+	for u, file := range c {
+		pairs = append(pairs, u+"="+file)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (c catalogFlags) Set(val string) error {
+	// URLs may contain "=", so split at the last one
+	idx := strings.LastIndex(val, "=")
+	if idx <= 0 || idx == len(val)-1 {
+		return fmt.Errorf("expected url=file, got %q", val)
+	}
+	c[val[:idx]] = val[idx+1:]
+	return nil
+}
+
+// listFlags collects repeated flags
+type listFlags []string
+
+func (l *listFlags) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlags) Set(val string) error {
+	*l = append(*l, val)
+	return nil
+}
+
+// runBundle writes a self-contained copy of a schema:
+//
+//	jsonschema bundle -root main.json [-o bundled.json] [-catalog url=file ...] [-allow prefix ...]
+//
+// Referenced documents are read from the catalog, from files relative to
+// the root schema or over HTTP, and embedded under "$defs". With -allow,
+// documents outside the catalog may only be loaded from URLs with one of
+// the given prefixes. Local files have file URLs
+func runBundle(args []string) int {
+	catalog := catalogFlags{}
+	allow := listFlags{}
+	fs := newFlagSet("bundle", "")
+	rootPath := fs.String("root", "", "schema to bundle (required)")
+	out := fs.String("o", "", "file to write, standard output if empty")
+	fs.Var(catalog, "catalog", "read the document at a URL from a file, as url=file. may be repeated")
+	fs.Var(&allow, "allow", "URL prefix documents may be loaded from. may be repeated")
+	fs.Parse(args)
+	if *rootPath == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return exitTrouble
+	}
+
+	rs, err := readSchema(*rootPath, false)
+	if err != nil {
+		return fail("bundle", err)
+	}
+	abs, err := filepath.Abs(*rootPath)
+	if err != nil {
+		return fail("bundle", err)
+	}
+	base := "file://" + filepath.ToSlash(abs)
+	if rs.ID != "" && !strings.HasPrefix(rs.ID, "#") {
+		base = rs.ID
+		// the root document is known by its $id, but may still be served
+		// from the file given
+		catalog[strings.TrimSuffix(rs.ID, "#")] = abs
+	}
+	bundled, err := jsonschema.Bundle(rs, jsonschema.BundleOptions{
+		BaseURI: base,
+		Catalog: catalog,
+		Allow:   allow,
+	})
+	if err != nil {
+		return fail("bundle", err)
+	}
+
+	data, err := json.MarshalIndent(bundled, "", "  ")
+	if err != nil {
+		return fail("bundle", err)
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = ioutil.WriteFile(*out, data, 0644)
+	}
+	if err != nil {
+		return fail("bundle", err)
+	}
+	return exitOK
+}
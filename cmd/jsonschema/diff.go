@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// diffChange is a schema change as written by -json
+type diffChange struct {
+	Pointer  string      `json:"pointer"`
+	Kind     string      `json:"kind"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+	Breaking bool        `json:"breaking"`
+}
+
+// diffResult is the output of -json. Compatibility is the strongest
+// non-transitive level the new schema meets
+type diffResult struct {
+	Changes       []diffChange `json:"changes"`
+	Breaking      bool         `json:"breaking"`
+	Compatibility string       `json:"compatibility"`
+}
+
+// runDiff compares two versions of a schema:
+//
+//	jsonschema diff [-json] [-compat level] old.json new.json
+//
+// Keyword-level changes are printed one per line, breaking ones marked,
+// followed by the strongest compatibility level the new version meets:
+// FULL, BACKWARD, FORWARD or NONE. Exit status is 1 when a change is
+// breaking, or with -compat when the new version violates the given level
+func runDiff(args []string) int {
+	fs := newFlagSet("diff", "old.json new.json")
+	asJSON := fs.Bool("json", false, "write the result as JSON")
+	compat := fs.String("compat", "", "compatibility level the new version must meet, like BACKWARD or FULL")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitTrouble
+	}
+	var level jsonschema.Compatibility
+	if *compat != "" {
+		var err error
+		if level, err = jsonschema.ParseCompatibility(*compat); err != nil {
+			return fail("diff", err)
+		}
+	}
+	old, err := readSchema(fs.Arg(0), false)
+	if err != nil {
+		return fail("diff", err)
+	}
+	new, err := readSchema(fs.Arg(1), false)
+	if err != nil {
+		return fail("diff", err)
+	}
+
+	res := diffResult{Changes: []diffChange{}, Compatibility: compatibilityOf(old, new).String()}
+	for _, c := range jsonschema.Diff(old, new) {
+		res.Changes = append(res.Changes, diffChange{
+			Pointer:  c.Pointer,
+			Kind:     c.Kind.String(),
+			Old:      c.Old,
+			New:      c.New,
+			Breaking: c.Breaking,
+		})
+		res.Breaking = res.Breaking || c.Breaking
+		if !*asJSON {
+			fmt.Println(c.String())
+		}
+	}
+	if *asJSON {
+		if err := writeJSON(res); err != nil {
+			return fail("diff", err)
+		}
+	} else {
+		fmt.Printf("compatibility: %s\n", res.Compatibility)
+	}
+
+	if *compat != "" {
+		if violations := jsonschema.CheckCompatibility(level, new, old); len(violations) > 0 {
+			return exitFailed
+		}
+		return exitOK
+	}
+	if res.Breaking {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// compatibilityOf gives the strongest compatibility level between two
+// versions of a schema
+func compatibilityOf(old, new *jsonschema.RootSchema) jsonschema.Compatibility {
+	backward := len(jsonschema.CheckCompatibility(jsonschema.CompatibilityBackward, new, old)) == 0
+	forward := len(jsonschema.CheckCompatibility(jsonschema.CompatibilityForward, new, old)) == 0
+	switch {
+	case backward && forward:
+		return jsonschema.CompatibilityFull
+	case backward:
+		return jsonschema.CompatibilityBackward
+	case forward:
+		return jsonschema.CompatibilityForward
+	}
+	return jsonschema.CompatibilityNone
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"old.json":   `{"properties": {"a": {"type": "string"}}}`,
+		"tight.json": `{"properties": {"a": {"type": "string", "maxLength": 3}}}`,
+		"loose.json": `{"properties": {"a": {"type": ["string", "null"]}}}`,
+		"bad.json":   `{`,
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	cases := []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{path("old.json"), path("old.json")}, exitOK, "compatibility: FULL\n", ""},
+		{[]string{path("old.json"), path("loose.json")}, exitOK,
+			"changed /properties/a/type: \"string\" -> [\"null\",\"string\"]\ncompatibility: BACKWARD\n", ""},
+		{[]string{path("old.json"), path("tight.json")}, exitFailed,
+			"added /properties/a/maxLength: 3 (breaking)\ncompatibility: FORWARD\n", ""},
+		{[]string{"-compat", "FORWARD", path("old.json"), path("tight.json")}, exitOK,
+			"added /properties/a/maxLength: 3 (breaking)\ncompatibility: FORWARD\n", ""},
+		{[]string{"-compat", "BACKWARD", path("old.json"), path("tight.json")}, exitFailed,
+			"added /properties/a/maxLength: 3 (breaking)\ncompatibility: FORWARD\n", ""},
+		{[]string{"-json", path("old.json"), path("tight.json")}, exitFailed, `{
+  "changes": [
+    {
+      "pointer": "/properties/a/maxLength",
+      "kind": "added",
+      "new": 3,
+      "breaking": true
+    }
+  ],
+  "breaking": true,
+  "compatibility": "FORWARD"
+}
+`, ""},
+		{[]string{path("old.json")}, exitTrouble, "", "usage: jsonschema diff"},
+		{[]string{"-compat", "SIDEWAYS", path("old.json"), path("tight.json")}, exitTrouble, "", "jsonschema diff: unknown compatibility level"},
+		{[]string{path("missing.json"), path("old.json")}, exitTrouble, "", "jsonschema diff: open " + path("missing.json")},
+		{[]string{path("old.json"), path("bad.json")}, exitTrouble, "", "jsonschema diff: parsing " + path("bad.json")},
+	}
+	for i, c := range cases {
+		status, stdout, stderr := runCommand(t, runDiff, c.args...)
+		if status != c.status {
+			t.Errorf("case %d: expected exit status %d, got %d. stderr: %s", i, c.status, status, stderr)
+		}
+		if stdout != c.stdout {
+			t.Errorf("case %d: expected output:\n%s\ngot:\n%s", i, c.stdout, stdout)
+		}
+		if !strings.HasPrefix(stderr, c.stderr) || (c.stderr == "" && stderr != "") {
+			t.Errorf("case %d: expected errors to begin with:\n%s\ngot:\n%s", i, c.stderr, stderr)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// refFlags collects -ref flags
+type refFlags map[string]string
+
+func (r refFlags) String() string {
+	pairs := make([]string, 0, len(r))
+	for ref, t := range r {
+		pairs = append(pairs, ref+"="+t)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (r refFlags) Set(val string) error {
+	// refs may contain "=", go type names can't
+	idx := strings.LastIndex(val, "=")
+	if idx <= 0 || idx == len(val)-1 {
+		return fmt.Errorf("expected ref=type, got %q", val)
+	}
+	r[val[:idx]] = val[idx+1:]
+	return nil
+}
+
+// runGen generates code from a schema:
+//
+//	jsonschema gen [-lang go] [-pkg name] [-o file] [-root name] [-ref ref=type ...] [-fetch] schema.json
+//
+// Go is the only language so far. The flags match those of jsonschema-gen,
+// for repositories that don't run go generate
+func runGen(args []string) int {
+	refs := refFlags{}
+	fs := newFlagSet("gen", "schema.json")
+	lang := fs.String("lang", "go", "language to generate")
+	pkg := fs.String("pkg", "", "package of the generated file, \"schema\" if empty")
+	out := fs.String("o", "", "file to write, standard output if empty")
+	root := fs.String("root", "", "name of the root type, derived from the schema title if empty")
+	fetch := fs.Bool("fetch", false, "fetch remote references before generating")
+	fs.Var(refs, "ref", "use an existing Go type for a $ref value, as ref=type. may be repeated")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitTrouble
+	}
+	if *lang != "go" {
+		return fail("gen", fmt.Errorf("unsupported language %q, only go is supported", *lang))
+	}
+
+	rs, err := readSchema(fs.Arg(0), *fetch)
+	if err != nil {
+		return fail("gen", err)
+	}
+	src, err := jsonschema.GenerateGo(rs, jsonschema.GoOptions{
+		Package:  *pkg,
+		RootName: *root,
+		Refs:     refs,
+	})
+	if err != nil {
+		return fail("gen", err)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = ioutil.WriteFile(*out, src, 0644)
+	}
+	if err != nil {
+		return fail("gen", err)
+	}
+	return exitOK
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGen(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pet.json":      `{"title": "pet", "properties": {"name": {"type": "string"}, "tag": {"$ref": "tags.json#/definitions/tag"}}}`,
+		"external.json": `{"properties": {"tag": {"$ref": "tags.json#/definitions/tag"}}}`,
+		"bad.json":      `{`,
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+	src := "// Code generated by jsonschema. DO NOT EDIT.\n\npackage pets\n\nimport (\n\t\"example.com/tags\"\n)\n\n" +
+		"type Pet struct {\n\tName *string   `json:\"name,omitempty\"`\n\tTag  *tags.Tag `json:\"tag,omitempty\"`\n}\n"
+
+	cases := []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{"-pkg", "pets", "-ref", "tags.json#/definitions/tag=example.com/tags.Tag", path("pet.json")}, exitOK, src, ""},
+		{[]string{"-lang", "go", "-pkg", "pets", "-ref", "tags.json#/definitions/tag=example.com/tags.Tag", "-o", path("pet.go"), path("pet.json")}, exitOK, "", ""},
+		{[]string{}, exitTrouble, "", "usage: jsonschema gen"},
+		{[]string{path("pet.json"), path("external.json")}, exitTrouble, "", "usage: jsonschema gen"},
+		{[]string{"-lang", "rust", path("pet.json")}, exitTrouble, "", `jsonschema gen: unsupported language "rust"`},
+		{[]string{path("missing.json")}, exitTrouble, "", "jsonschema gen: open " + path("missing.json")},
+		{[]string{path("bad.json")}, exitTrouble, "", "jsonschema gen: parsing " + path("bad.json")},
+		{[]string{path("external.json")}, exitTrouble, "", "jsonschema gen: unresolved reference: tags.json#/definitions/tag"},
+		{[]string{"-o", filepath.Join(dir, "missing", "pet.go"), "-ref", "tags.json#/definitions/tag=example.com/tags.Tag", path("pet.json")}, exitTrouble, "", "jsonschema gen: open "},
+	}
+	for i, c := range cases {
+		status, stdout, stderr := runCommand(t, runGen, c.args...)
+		if status != c.status {
+			t.Errorf("case %d: expected exit status %d, got %d. stderr: %s", i, c.status, status, stderr)
+		}
+		if stdout != c.stdout {
+			t.Errorf("case %d: expected output:\n%s\ngot:\n%s", i, c.stdout, stdout)
+		}
+		if !strings.HasPrefix(stderr, c.stderr) || (c.stderr == "" && stderr != "") {
+			t.Errorf("case %d: expected errors to begin with:\n%s\ngot:\n%s", i, c.stderr, stderr)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path("pet.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != src {
+		t.Errorf("expected -o to write:\n%s\ngot:\n%s", src, data)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+)
+
+// junitReport is a JUnit XML report, the format CI systems display test
+// results from
+type junitReport struct {
+	XMLName  xml.Name      `xml:"testsuites"`
+	Name     string        `xml:"name,attr"`
+	Tests    int           `xml:"tests,attr"`
+	Failures int           `xml:"failures,attr"`
+	Suites   []*junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// newJUnitReport starts a report of the results of the command name
+func newJUnitReport(name string) *junitReport {
+	return &junitReport{Name: "jsonschema " + name}
+}
+
+// suite adds a suite of test cases named name to the report
+func (r *junitReport) suite(name string) *junitSuite {
+	s := &junitSuite{Name: name}
+	r.Suites = append(r.Suites, s)
+	return s
+}
+
+// add adds a test case to s, which failed if it has problems. The first
+// problem is the failure message, and all of them its text
+func (s *junitSuite) add(name string, problems []string) {
+	c := junitCase{Name: name, ClassName: s.Name}
+	if len(problems) > 0 {
+		c.Failure = &junitFailure{Message: problems[0], Text: strings.Join(problems, "\n")}
+		s.Failures++
+	}
+	s.Tests++
+	s.Cases = append(s.Cases, c)
+}
+
+// write writes the report to standard output
+func (r *junitReport) write() error {
+	r.Tests, r.Failures = 0, 0
+	for _, s := range r.Suites {
+		r.Tests += s.Tests
+		r.Failures += s.Failures
+	}
+	if _, err := os.Stdout.WriteString(xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(os.Stdout)
+	enc.Indent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	_, err := os.Stdout.WriteString("\n")
+	return err
+}
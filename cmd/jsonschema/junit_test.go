@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestJUnitReport(t *testing.T) {
+	report := newJUnitReport("validate")
+	docs := report.suite("schema.json")
+	docs.add("a.json", nil)
+	docs.add("b.json", []string{"/age: must be greater than or equal to 0", `/: "name" value is required`})
+	fixtures := report.suite("fixtures")
+	fixtures.add("valid & <ok>", nil)
+	fixtures.add("invalid", []string{"expected invalid"})
+	report.suite("empty")
+
+	// writing twice must not count the cases twice
+	captureStdout(t, report.write)
+	got := captureStdout(t, report.write)
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="jsonschema validate" tests="4" failures="2">
+  <testsuite name="schema.json" tests="2" failures="1">
+    <testcase name="a.json" classname="schema.json"></testcase>
+    <testcase name="b.json" classname="schema.json">
+      <failure message="/age: must be greater than or equal to 0">/age: must be greater than or equal to 0&#xA;/: &#34;name&#34; value is required</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="fixtures" tests="2" failures="1">
+    <testcase name="valid &amp; &lt;ok&gt;" classname="fixtures"></testcase>
+    <testcase name="invalid" classname="fixtures">
+      <failure message="expected invalid">expected invalid</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="empty" tests="0" failures="0"></testsuite>
+</testsuites>
+`
+	if got != expect {
+		t.Errorf("JUnit output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// lintMetaSchema is the rule reported for schemas that fail -meta
+const lintMetaSchema = "meta-schema"
+
+// lintRules are the rules -rules and -disable select from
+var lintRules = []string{
+	jsonschema.LintContradictoryBounds,
+	jsonschema.LintUnreachableBranch,
+	jsonschema.LintUnmatchablePattern,
+	jsonschema.LintUndescribedRequired,
+	jsonschema.LintInapplicableKeyword,
+	jsonschema.LintIgnoredKeyword,
+	jsonschema.LintUnreachableEnumValue,
+	jsonschema.LintDuplicateValue,
+	jsonschema.LintInvalidDefault,
+	lintMetaSchema,
+}
+
+// lintResult is the outcome of linting one schema, as written by -json
+type lintResult struct {
+	File   string                 `json:"file"`
+	Issues []jsonschema.LintIssue `json:"issues"`
+}
+
+// runLint reports likely mistakes in schemas:
+//
+//	jsonschema lint [-rules list] [-disable list] [-meta file] [-json | -sarif | -junit | -tap] schema.json ...
+//
+// Rules are named by their codes, comma-separated. With -meta each schema
+// is also validated against a meta-schema, such as the draft-07 one, with
+// failures reported under the "meta-schema" rule. Each issue is printed on
+// a line of its own as "file: pointer: message [rule]", with -json all
+// results are written as a JSON array, and with -sarif as a SARIF log for
+// code scanning tools, where meta-schema failures are errors and other
+// issues warnings. -junit writes a JUnit XML report with a suite per schema
+// and a test case per rule checked, and -tap a Test Anything Protocol
+// stream with a test point per schema and rule
+func runLint(args []string) int {
+	fs := newFlagSet("lint", "schema.json ...")
+	only := fs.String("rules", "", "comma-separated rules to check, all if empty")
+	disable := fs.String("disable", "", "comma-separated rules to skip")
+	metaPath := fs.String("meta", "", "meta-schema to validate schemas against")
+	asJSON := fs.Bool("json", false, "write results as JSON")
+	sarif := fs.Bool("sarif", false, "write results as a SARIF log")
+	junit := fs.Bool("junit", false, "write results as a JUnit XML report")
+	tap := fs.Bool("tap", false, "write results as a TAP stream")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitTrouble
+	}
+
+	enabled, err := selectLintRules(*only, *disable)
+	if err != nil {
+		return fail("lint", err)
+	}
+	var meta *jsonschema.RootSchema
+	if *metaPath != "" && enabled[lintMetaSchema] {
+		if meta, err = readSchema(*metaPath, false); err != nil {
+			return fail("lint", err)
+		}
+	}
+
+	status := exitOK
+	results := []lintResult{}
+	sarifResults := newSARIFReport()
+	junitResults := newJUnitReport("lint")
+	tapResults := &tapReport{}
+	for _, file := range fs.Args() {
+		data, issues, err := lintFile(file, meta, enabled)
+		if err != nil {
+			return fail("lint", err)
+		}
+		if len(issues) > 0 {
+			status = exitFailed
+		}
+		if *asJSON {
+			results = append(results, lintResult{File: file, Issues: issues})
+			continue
+		}
+		if *sarif {
+			for _, issue := range issues {
+				level := "warning"
+				if issue.Rule == lintMetaSchema {
+					level = "error"
+				}
+				sarifResults.add(file, data, issue.Pointer, issue.Rule, level, issue.Message)
+			}
+			continue
+		}
+		if *junit || *tap {
+			suite := junitResults.suite(file)
+			for _, rule := range lintRules {
+				if !enabled[rule] || (rule == lintMetaSchema && meta == nil) {
+					continue
+				}
+				problems := []string{}
+				for _, issue := range issues {
+					if issue.Rule == rule {
+						problems = append(problems, issue.Error())
+					}
+				}
+				suite.add(rule, problems)
+				tapResults.add(file+": "+rule, problems)
+			}
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Printf("%s: %s\n", file, issue.Error())
+		}
+	}
+	if *asJSON {
+		if err := writeJSON(results); err != nil {
+			return fail("lint", err)
+		}
+	} else if *sarif {
+		if err := sarifResults.write(); err != nil {
+			return fail("lint", err)
+		}
+	} else if *junit {
+		if err := junitResults.write(); err != nil {
+			return fail("lint", err)
+		}
+	} else if *tap {
+		if err := tapResults.write(); err != nil {
+			return fail("lint", err)
+		}
+	}
+	return status
+}
+
+// selectLintRules gives the set of rules to check
+func selectLintRules(only, disable string) (map[string]bool, error) {
+	known := map[string]bool{}
+	for _, rule := range lintRules {
+		known[rule] = true
+	}
+	enabled := map[string]bool{}
+	if only == "" {
+		for rule := range known {
+			enabled[rule] = true
+		}
+	}
+	for _, list := range []struct {
+		rules string
+		on    bool
+	}{{only, true}, {disable, false}} {
+		for _, rule := range strings.Split(list.rules, ",") {
+			if rule = strings.TrimSpace(rule); rule == "" {
+				continue
+			}
+			if !known[rule] {
+				return nil, fmt.Errorf("unknown rule %q, expected one of %s", rule, strings.Join(lintRules, ", "))
+			}
+			enabled[rule] = list.on
+		}
+	}
+	return enabled, nil
+}
+
+// lintFile lints the schema in file, validating it against meta if it
+// isn't nil, giving the contents of file and the issues found
+func lintFile(file string, meta *jsonschema.RootSchema, enabled map[string]bool) ([]byte, []jsonschema.LintIssue, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	issues := []jsonschema.LintIssue{}
+	if meta != nil {
+		errs, err := meta.ValidateBytes(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+		for _, e := range errs {
+			issues = append(issues, jsonschema.LintIssue{Rule: lintMetaSchema, Pointer: e.PropertyPath, Message: e.Message})
+		}
+	}
+
+	rs := &jsonschema.RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		if len(issues) > 0 {
+			// the meta-schema has already said what's wrong
+			return data, issues, nil
+		}
+		return nil, nil, fmt.Errorf("parsing %s: %s", file, err.Error())
+	}
+	for _, issue := range rs.Lint() {
+		if enabled[issue.Rule] {
+			issues = append(issues, issue)
+		}
+	}
+	return data, issues, nil
+}
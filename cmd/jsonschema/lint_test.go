@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunLint(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"bounds.json": `{"type": "string", "minLength": 5, "maxLength": 2}`,
+		"clean.json":  `{"type": "string", "description": "a name"}`,
+		"meta.json":   `{"properties": {"type": {"type": "string"}}}`,
+		"typed.json":  `{"type": 5}`,
+		"bad.json":    `{`,
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	cases := []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{path("clean.json")}, exitOK, "", ""},
+		{[]string{path("bounds.json"), path("clean.json")}, exitFailed,
+			path("bounds.json") + ": /minLength: minLength 5 leaves no room below maxLength 2 [contradictory-bounds]\n", ""},
+		{[]string{"-disable", "contradictory-bounds", path("bounds.json")}, exitOK, "", ""},
+		{[]string{"-rules", "ignored-keyword, duplicate-value", path("bounds.json")}, exitOK, "", ""},
+		{[]string{"-meta", path("meta.json"), path("typed.json")}, exitFailed,
+			path("typed.json") + ": /type: type should be string [meta-schema]\n", ""},
+		{[]string{"-json", path("clean.json")}, exitOK,
+			"[\n  {\n    \"file\": \"" + path("clean.json") + "\",\n    \"issues\": []\n  }\n]\n", ""},
+		{[]string{"-tap", "-rules", "contradictory-bounds,duplicate-value", path("bounds.json")}, exitFailed,
+			"TAP version 13\n1..2\nnot ok 1 - " + path("bounds.json") + ": contradictory-bounds\n" +
+				`  ---
+  problems:
+    - "/minLength: minLength 5 leaves no room below maxLength 2 [contradictory-bounds]"
+  ...
+` + "ok 2 - " + path("bounds.json") + ": duplicate-value\n", ""},
+		{[]string{}, exitTrouble, "", "usage: jsonschema lint"},
+		{[]string{"-rules", "nope", path("clean.json")}, exitTrouble, "", `jsonschema lint: unknown rule "nope"`},
+		{[]string{path("missing.json")}, exitTrouble, "", "jsonschema lint: open " + path("missing.json")},
+		{[]string{path("bad.json")}, exitTrouble, "", "jsonschema lint: parsing " + path("bad.json")},
+		{[]string{"-meta", path("missing.json"), path("clean.json")}, exitTrouble, "", "jsonschema lint: open " + path("missing.json")},
+	}
+	for i, c := range cases {
+		status, stdout, stderr := runCommand(t, runLint, c.args...)
+		if status != c.status {
+			t.Errorf("case %d: expected exit status %d, got %d. stderr: %s", i, c.status, status, stderr)
+		}
+		if stdout != c.stdout {
+			t.Errorf("case %d: expected output:\n%s\ngot:\n%s", i, c.stdout, stdout)
+		}
+		if !strings.HasPrefix(stderr, c.stderr) || (c.stderr == "" && stderr != "") {
+			t.Errorf("case %d: expected errors to begin with:\n%s\ngot:\n%s", i, c.stderr, stderr)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Command jsonschema works with JSON schemas from the command line.
+//
+// Usage:
+//
+//	jsonschema <command> [flags] [args]
+//
+// Commands:
+//
+//	validate   validate documents against a schema
+//	lint       report likely mistakes in schemas
+//	bundle     embed the documents a schema references
+//	gen        generate code from a schema
+//	serve      serve validation over HTTP
+//	diff       compare two versions of a schema
+//	test       run instance fixtures against schemas
+//
+// Run "jsonschema <command> -h" for the flags of a command. Exit status is
+// 0 on success, 1 when a check fails and 2 for usage errors or input that
+// can't be read
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// exit statuses
+const (
+	exitOK      = 0
+	exitFailed  = 1
+	exitTrouble = 2
+)
+
+// command is a subcommand. run is given the arguments after the command
+// name and returns the exit status
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{"validate", "validate documents against a schema", runValidate},
+	{"lint", "report likely mistakes in schemas", runLint},
+	{"bundle", "embed the documents a schema references", runBundle},
+	{"gen", "generate code from a schema", runGen},
+	{"serve", "serve validation over HTTP", runServe},
+	{"diff", "compare two versions of a schema", runDiff},
+	{"test", "run instance fixtures against schemas", runTest},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: jsonschema <command> [flags] [args]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitTrouble)
+	}
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+	if os.Args[1] != "-h" && os.Args[1] != "-help" && os.Args[1] != "help" {
+		fmt.Fprintf(os.Stderr, "jsonschema: unknown command %q\n", os.Args[1])
+	}
+	usage()
+	os.Exit(exitTrouble)
+}
+
+// newFlagSet creates the flag set of a command, with a usage message
+// describing its arguments. Invalid flags exit with status 2
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: jsonschema %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// fail reports an error on behalf of a command, returning exitTrouble
+func fail(cmd string, err error) int {
+	fmt.Fprintf(os.Stderr, "jsonschema %s: %s\n", cmd, err.Error())
+	return exitTrouble
+}
+
+// readSchema loads the schema at path, which is read as YAML if it has a
+// .yaml or .yml extension and as JSON5 if it has a .jsonc or .json5 one,
+// fetching its remote references if fetch is set
+func readSchema(path string, fetch bool) (*jsonschema.RootSchema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rs := &jsonschema.RootSchema{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if rs, err = jsonschema.ParseYAML(data); err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", path, err.Error())
+		}
+	case ".jsonc", ".json5":
+		if rs, err = jsonschema.ParseJSON5(data); err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", path, err.Error())
+		}
+	default:
+		if err := json.Unmarshal(data, rs); err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", path, err.Error())
+		}
+	}
+	if fetch {
+		if err := rs.FetchRemoteReferences(); err != nil {
+			return nil, fmt.Errorf("fetching references of %s: %s", path, err.Error())
+		}
+	}
+	return rs, nil
+}
+
+// writeJSON writes v to standard output as indented JSON
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+	"github.com/qri-io/jsonschema"
+)
+
+// ANSI escape codes for -pretty output
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiCyan  = "\x1b[36m"
+	ansiBlue  = "\x1b[34m"
+)
+
+// diagnostics renders validation errors like compiler diagnostics: the
+// location in the document, the line holding the offending value with a
+// caret beneath it, and the schema keywords that rejected it
+type diagnostics struct {
+	w     io.Writer
+	color bool
+}
+
+// newDiagnostics writes diagnostics to standard output, colored when it's a
+// terminal and the NO_COLOR environment variable isn't set
+func newDiagnostics() *diagnostics {
+	color := false
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		_, noColor := os.LookupEnv("NO_COLOR")
+		color = !noColor
+	}
+	return &diagnostics{w: os.Stdout, color: color}
+}
+
+func (d *diagnostics) paint(code, str string) string {
+	if !d.color {
+		return str
+	}
+	return code + str + ansiReset
+}
+
+// write renders the errors found validating data, the contents of file,
+// against rs
+func (d *diagnostics) write(file string, data []byte, rs *jsonschema.RootSchema, errs []jsonschema.ValError) {
+	var doc interface{}
+	json.Unmarshal(data, &doc)
+	for _, e := range errs {
+		ptr := e.PropertyPath
+		if ptr == "/" {
+			ptr = ""
+		}
+		line, col, text := 0, 0, ""
+		if offset, ok := locateValue(data, ptr); ok {
+			line, col, text = lineAt(data, offset)
+		} else if lines := bytes.Split(data, []byte{'\n'}); e.Line > 0 && e.Line <= len(lines) {
+			// documents such as YAML give the line of the error themselves
+			text = strings.TrimRight(string(lines[e.Line-1]), "\r")
+			line, col = e.Line, len(text)-len(strings.TrimLeft(text, " "))+1
+		}
+
+		if line > 0 {
+			fmt.Fprintf(d.w, "%s %s %s\n", d.paint(ansiBold, fmt.Sprintf("%s:%d:%d:", file, line, col)), d.paint(ansiRed+ansiBold, "error:"), e.Message)
+			num := strconv.Itoa(line)
+			gutter := strings.Repeat(" ", len(num))
+			fmt.Fprintf(d.w, "%s %s %s\n", d.paint(ansiBlue, num), d.paint(ansiBlue, "|"), text)
+			fmt.Fprintf(d.w, "%s %s %s%s\n", gutter, d.paint(ansiBlue, "|"), caretPadding(text, col), d.paint(ansiRed+ansiBold, "^"))
+		} else {
+			fmt.Fprintf(d.w, "%s %s %s\n", d.paint(ansiBold, file+":"), d.paint(ansiRed+ansiBold, "error:"), e.Message)
+		}
+		if ptr != "" {
+			fmt.Fprintf(d.w, "  %s at %s\n", d.paint(ansiCyan, "="), ptr)
+		}
+		for _, kw := range failedKeywords(rs, ptr, doc, e.Message) {
+			fmt.Fprintf(d.w, "  %s constraint %q: %s\n", d.paint(ansiCyan, "="), kw.name, kw.value)
+		}
+		fmt.Fprintln(d.w)
+	}
+}
+
+// caretPadding gives whitespace reaching column col of text, keeping tabs
+// so the caret lines up
+func caretPadding(text string, col int) string {
+	pad := &strings.Builder{}
+	for i, r := range []rune(text) {
+		if i >= col-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	return pad.String()
+}
+
+// failedKeyword is a keyword of a schema and its value as JSON
+type failedKeyword struct {
+	name  string
+	value []byte
+}
+
+// failedKeywords gives the keywords of the schemas applying to the value at
+// ptr within doc that report msg on their own
+func failedKeywords(rs *jsonschema.RootSchema, ptr string, doc interface{}, msg string) []failedKeyword {
+	schemas, err := rs.SchemasAt(ptr)
+	if err != nil {
+		return nil
+	}
+	tokens, err := jsonpointer.Parse(ptr)
+	if err != nil {
+		return nil
+	}
+	v, err := tokens.Eval(doc)
+	if err != nil {
+		return nil
+	}
+	path := ptr
+	if path == "" {
+		path = "/"
+	}
+
+	found := []failedKeyword{}
+	for _, s := range schemas {
+		keys := make([]string, 0, len(s.Validators))
+		for key := range s.Validators {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			errs := []jsonschema.ValError{}
+			s.Validators[key].Validate(path, v, &errs)
+			for _, e := range errs {
+				if e.Message != msg {
+					continue
+				}
+				val, err := json.Marshal(s.Validators[key])
+				if err != nil {
+					break
+				}
+				found = append(found, failedKeyword{name: key, value: val})
+				break
+			}
+		}
+	}
+	return found
+}
+
+// lineAt gives the 1-based line and column of offset within data, and the
+// text of that line
+func lineAt(data []byte, offset int) (line, col int, text string) {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := bytes.IndexByte(data[offset:], '\n')
+	if end == -1 {
+		end = len(data)
+	} else {
+		end += offset
+	}
+	line = bytes.Count(data[:offset], []byte{'\n'}) + 1
+	col = len([]rune(string(data[start:offset]))) + 1
+	return line, col, strings.TrimRight(string(data[start:end]), "\r")
+}
+
+// locateValue gives the offset within the JSON document data of the value
+// at the JSON pointer ptr
+func locateValue(data []byte, ptr string) (int, bool) {
+	tokens, err := jsonpointer.Parse(ptr)
+	if err != nil {
+		return 0, false
+	}
+	s := &jsonScanner{data: data}
+	for _, tok := range tokens {
+		s.skipSpace()
+		if s.pos >= len(data) {
+			return 0, false
+		}
+		var found bool
+		switch data[s.pos] {
+		case '{':
+			found = s.findMember(tok)
+		case '[':
+			found = s.findElement(tok)
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	s.skipSpace()
+	return s.pos, s.pos < len(data)
+}
+
+// jsonScanner steps through a JSON document that's known to be valid
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) && strings.IndexByte(" \t\r\n", s.data[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// findMember moves from the start of an object to the value of its member
+// named name
+func (s *jsonScanner) findMember(name string) bool {
+	s.pos++
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+			return false
+		}
+		start := s.pos
+		s.skipValue()
+		var key string
+		if err := json.Unmarshal(s.data[start:s.pos], &key); err != nil {
+			return false
+		}
+		s.skipSpace()
+		s.pos++ // colon
+		s.skipSpace()
+		if key == name {
+			return true
+		}
+		s.skipValue()
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] != ',' {
+			return false
+		}
+		s.pos++
+	}
+}
+
+// findElement moves from the start of an array to its element at index tok
+func (s *jsonScanner) findElement(tok string) bool {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 {
+		return false
+	}
+	s.pos++
+	for ; ; i-- {
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] == ']' {
+			return false
+		}
+		if i == 0 {
+			return true
+		}
+		s.skipValue()
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] != ',' {
+			return false
+		}
+		s.pos++
+	}
+}
+
+// skipValue moves past the value starting at the current position
+func (s *jsonScanner) skipValue() {
+	if s.pos >= len(s.data) {
+		return
+	}
+	switch s.data[s.pos] {
+	case '"':
+		for s.pos++; s.pos < len(s.data) && s.data[s.pos] != '"'; s.pos++ {
+			if s.data[s.pos] == '\\' {
+				s.pos++
+			}
+		}
+		s.pos++
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				s.skipValue()
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					s.pos++
+					return
+				}
+			}
+			s.pos++
+		}
+	default:
+		for s.pos < len(s.data) && strings.IndexByte(",:}] \t\r\n", s.data[s.pos]) < 0 {
+			s.pos++
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// sarifVersion is the version of SARIF -sarif writes
+const sarifVersion = "2.1.0"
+
+// sarifLog is a SARIF log, the format code scanning tools read results
+// from. Only the parts of the format needed to place results in files are
+// covered
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+	// ColumnKind is how columns are counted. Columns are counted in runes
+	ColumnKind string `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	// LogicalLocations holds the JSON pointer of the value at fault
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifReport collects the results of a command into a single run
+type sarifReport struct {
+	run   sarifRun
+	rules map[string]bool
+}
+
+func newSARIFReport() *sarifReport {
+	return &sarifReport{
+		run: sarifRun{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "jsonschema",
+				InformationURI: "https://github.com/qri-io/jsonschema",
+				Rules:          []sarifRule{},
+			}},
+			Results:    []sarifResult{},
+			ColumnKind: "unicodeCodePoints",
+		},
+		rules: map[string]bool{},
+	}
+}
+
+// add reports a result for the value at the JSON pointer ptr within data,
+// the contents of file. level is "error" or "warning"
+func (r *sarifReport) add(file string, data []byte, ptr, rule, level, msg string) {
+	if !r.rules[rule] {
+		r.rules[rule] = true
+		r.run.Tool.Driver.Rules = append(r.run.Tool.Driver.Rules, sarifRule{ID: rule})
+	}
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+	}}
+	if offset, ok := locateValue(data, ptr); ok {
+		line, col, _ := lineAt(data, offset)
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: col}
+	}
+	if ptr != "" {
+		loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: ptr}}
+	}
+	r.run.Results = append(r.run.Results, sarifResult{
+		RuleID:    rule,
+		Level:     level,
+		Message:   sarifMessage{Text: msg},
+		Locations: []sarifLocation{loc},
+	})
+}
+
+// addValErrors reports the errors found validating data, the contents of
+// file, against rs. Each is reported under the keyword that rejected it
+func (r *sarifReport) addValErrors(file string, data []byte, rs *jsonschema.RootSchema, errs []jsonschema.ValError) {
+	var doc interface{}
+	json.Unmarshal(data, &doc)
+	for _, e := range errs {
+		ptr := e.PropertyPath
+		if ptr == "/" {
+			ptr = ""
+		}
+		rule := "invalid"
+		if kws := failedKeywords(rs, ptr, doc, e.Message); len(kws) > 0 {
+			rule = kws[0].name
+		}
+		r.add(file, data, ptr, rule, "error", e.Message)
+	}
+}
+
+// write writes the report to standard output
+func (r *sarifReport) write() error {
+	return writeJSON(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: sarifVersion,
+		Runs:    []sarifRun{r.run},
+	})
+}
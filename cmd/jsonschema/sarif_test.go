@@ -0,0 +1,186 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// captureStdout gives what fn writes to standard output
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	return capture(t, &os.Stdout, fn)
+}
+
+// capture gives what fn writes to the file *f, which is replaced with a
+// pipe while fn runs
+func capture(t *testing.T, f **os.File, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := *f
+	*f = w
+	out := make(chan []byte)
+	go func() {
+		data, _ := ioutil.ReadAll(r)
+		out <- data
+	}()
+	err = fn()
+	*f = orig
+	w.Close()
+	data := <-out
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSARIFReport(t *testing.T) {
+	rs := jsonschema.Must(`{
+		"required": ["name"],
+		"properties": {
+			"label": {"type": "string"},
+			"age": {"minimum": 0}
+		}
+	}`)
+	data := []byte("{\n  \"label\": \"ünï\", \"age\": -1,\n\t\"tags\": {\"label\": 2}\n}")
+	errs, err := rs.ValidateBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := newSARIFReport()
+	report.addValErrors("docs/pet.json", data, rs, errs)
+	report.add("docs/pet.json", data, "/tags/label", "unknown-property", "warning", "tags/label is not declared")
+	report.add("docs/pet.json", data, "/missing", "unknown-property", "warning", "missing is not declared")
+	got := captureStdout(t, report.write)
+
+	expect := `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "jsonschema",
+          "informationUri": "https://github.com/qri-io/jsonschema",
+          "rules": [
+            {
+              "id": "required"
+            },
+            {
+              "id": "minimum"
+            },
+            {
+              "id": "unknown-property"
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "required",
+          "level": "error",
+          "message": {
+            "text": "\"name\" value is required"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "docs/pet.json"
+                },
+                "region": {
+                  "startLine": 1,
+                  "startColumn": 1
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "minimum",
+          "level": "error",
+          "message": {
+            "text": "must be greater than or equal to 0.000000"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "docs/pet.json"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 26
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "/age"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "unknown-property",
+          "level": "warning",
+          "message": {
+            "text": "tags/label is not declared"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "docs/pet.json"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 20
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "/tags/label"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "unknown-property",
+          "level": "warning",
+          "message": {
+            "text": "missing is not declared"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "docs/pet.json"
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "/missing"
+                }
+              ]
+            }
+          ]
+        }
+      ],
+      "columnKind": "unicodeCodePoints"
+    }
+  ]
+}
+`
+	if got != expect {
+		t.Errorf("SARIF output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// maxInstanceSize limits the size of documents the server validates
+const maxInstanceSize = 10 << 20
+
+// outputUnit is an error in the "basic" output format of the JSON Schema
+// specification
+type outputUnit struct {
+	KeywordLocation  string `json:"keywordLocation"`
+	InstanceLocation string `json:"instanceLocation"`
+	Error            string `json:"error"`
+}
+
+// output is a validation result in the "basic" output format of the JSON
+// Schema specification
+type output struct {
+	Valid  bool         `json:"valid"`
+	Errors []outputUnit `json:"errors,omitempty"`
+}
+
+// runServe serves validation over HTTP:
+//
+//	jsonschema serve -schema-dir ./schemas [-addr :8080] [-fetch]
+//
+// Every .json file beneath the schema directory is loaded at startup and
+// identified by its path relative to the directory, without the extension:
+// schemas/v1/pet.json is "v1/pet". POST /validate/{schemaID} validates the
+// request body against the schema, responding with a result in the
+// specification's "basic" output format
+func runServe(args []string) int {
+	fs := newFlagSet("serve", "")
+	dir := fs.String("schema-dir", "", "directory of schemas to serve (required)")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	fetch := fs.Bool("fetch", false, "fetch remote references of the schemas")
+	fs.Parse(args)
+	if *dir == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return exitTrouble
+	}
+
+	schemas, err := loadSchemaDir(*dir, *fetch)
+	if err != nil {
+		return fail("serve", err)
+	}
+	ids := make([]string, 0, len(schemas))
+	for id := range schemas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	log.Printf("serving %d schemas on %s: %s", len(ids), *addr, strings.Join(ids, ", "))
+
+	mux := http.NewServeMux()
+	mux.Handle("/validate/", validateHandler(schemas))
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		return fail("serve", err)
+	}
+	return exitOK
+}
+
+// loadSchemaDir loads the schemas beneath dir, keyed by ID
+func loadSchemaDir(dir string, fetch bool) (map[string]*jsonschema.RootSchema, error) {
+	schemas := map[string]*jsonschema.RootSchema{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rs, err := readSchema(path, fetch)
+		if err != nil {
+			return err
+		}
+		schemas[strings.TrimSuffix(filepath.ToSlash(rel), ".json")] = rs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no schemas found in %s", dir)
+	}
+	return schemas, nil
+}
+
+// validateHandler validates request bodies against the schema named by the
+// rest of the path after /validate/
+func validateHandler(schemas map[string]*jsonschema.RootSchema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/validate/")
+		rs, ok := schemas[id]
+		if !ok {
+			httpError(w, http.StatusNotFound, fmt.Sprintf("schema %q not found", id))
+			return
+		}
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxInstanceSize))
+		if err != nil {
+			httpError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		errs, err := rs.ValidateBytes(data)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		out := output{Valid: len(errs) == 0}
+		for _, e := range errs {
+			loc := e.PropertyPath
+			if loc == "/" {
+				loc = ""
+			}
+			out.Errors = append(out.Errors, outputUnit{
+				KeywordLocation:  e.RulePath,
+				InstanceLocation: loc,
+				Error:            e.Message,
+			})
+		}
+		sort.SliceStable(out.Errors, func(i, j int) bool {
+			return out.Errors[i].InstanceLocation < out.Errors[j].InstanceLocation
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	})
+}
+
+// httpError responds with a JSON error message
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	schema := `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"age": {"$ref": "#/definitions/age"},
+			"tags": {"items": {"type": "string"}}
+		},
+		"definitions": {"age": {"minimum": 0}}
+	}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "v1", "pet.json"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := loadSchemaDir(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := validateHandler(schemas)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate/v1/pet", strings.NewReader(`{"age": -1, "tags": ["a", 2]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	out := output{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Valid {
+		t.Errorf("expected an invalid result")
+	}
+	locations := map[string]string{}
+	for _, e := range out.Errors {
+		if e.KeywordLocation == "" {
+			t.Errorf("expected a keywordLocation for %s at %q", e.Error, e.InstanceLocation)
+		}
+		locations[e.InstanceLocation] = e.KeywordLocation
+	}
+	expect := map[string]string{
+		"":        "/required",
+		"/age":    "/definitions/age/minimum",
+		"/tags/1": "/properties/tags/items/type",
+	}
+	if !reflect.DeepEqual(locations, expect) {
+		t.Errorf("expected keyword locations %v, got %v", expect, locations)
+	}
+
+	for _, c := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/validate/v1/pet", `{"name": "rex"}`, http.StatusOK},
+		{http.MethodGet, "/validate/v1/pet", ``, http.StatusMethodNotAllowed},
+		{http.MethodPost, "/validate/v2/pet", `{}`, http.StatusNotFound},
+		{http.MethodPost, "/validate/v1/pet", `{`, http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+		if rec.Code != c.status {
+			t.Errorf("%s %s: expected status %d, got %d", c.method, c.path, c.status, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tapReport is a Test Anything Protocol report, with a test point per
+// check and the problems of failed checks as YAML diagnostics
+type tapReport struct {
+	points []tapPoint
+}
+
+type tapPoint struct {
+	name     string
+	problems []string
+}
+
+// add adds a test point to r, which failed if it has problems
+func (r *tapReport) add(name string, problems []string) {
+	// an unescaped # would start a directive
+	name = strings.Replace(name, "#", `\#`, -1)
+	r.points = append(r.points, tapPoint{name: name, problems: problems})
+}
+
+// write writes the report to standard output in TAP version 13
+func (r *tapReport) write() error {
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(r.points))
+	for i, p := range r.points {
+		if len(p.problems) == 0 {
+			fmt.Fprintf(w, "ok %d - %s\n", i+1, p.name)
+			continue
+		}
+		fmt.Fprintf(w, "not ok %d - %s\n", i+1, p.name)
+		fmt.Fprintln(w, "  ---")
+		fmt.Fprintln(w, "  problems:")
+		for _, problem := range p.problems {
+			// a double-quoted Go string is a valid YAML scalar
+			fmt.Fprintf(w, "    - %s\n", strconv.Quote(problem))
+		}
+		fmt.Fprintln(w, "  ...")
+	}
+	return w.Flush()
+}
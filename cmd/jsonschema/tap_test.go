@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestTAPReport(t *testing.T) {
+	got := captureStdout(t, (&tapReport{}).write)
+	if expect := "TAP version 13\n1..0\n"; got != expect {
+		t.Errorf("empty TAP output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	report := &tapReport{}
+	report.add("a.json", nil)
+	report.add("fixtures.json # bad", []string{`/: "name" value is required`, "/age: must be\ngreater"})
+	report.add("c.json", []string{})
+	got = captureStdout(t, report.write)
+
+	expect := `TAP version 13
+1..3
+ok 1 - a.json
+not ok 2 - fixtures.json \# bad
+  ---
+  problems:
+    - "/: \"name\" value is required"
+    - "/age: must be\ngreater"
+  ...
+ok 3 - c.json
+`
+	if got != expect {
+		t.Errorf("TAP output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// testResult is the outcome of running one fixture, as written by -json
+type testResult struct {
+	File     string                      `json:"file"`
+	Tests    int                         `json:"tests"`
+	Failures []jsonschema.FixtureFailure `json:"failures"`
+}
+
+// runTest runs instance fixtures, in the format described by
+// jsonschema.Fixture:
+//
+//	jsonschema test [-json | -junit | -tap] fixture.json ...
+//
+// Each fixture gets a line saying whether it passed, followed by a line per
+// failed test, or with -json all results are written as a JSON array. -junit
+// writes a JUnit XML report with a suite per fixture and a test case per
+// test, and -tap a Test Anything Protocol stream with a test point per test
+func runTest(args []string) int {
+	fs := newFlagSet("test", "fixture.json ...")
+	asJSON := fs.Bool("json", false, "write results as JSON")
+	junit := fs.Bool("junit", false, "write results as a JUnit XML report")
+	tap := fs.Bool("tap", false, "write results as a TAP stream")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitTrouble
+	}
+
+	status := exitOK
+	results := []testResult{}
+	junitResults := newJUnitReport("test")
+	tapResults := &tapReport{}
+	for _, file := range fs.Args() {
+		f, err := jsonschema.LoadFixture(file)
+		if err != nil {
+			return fail("test", err)
+		}
+		failures := f.Run()
+		if len(failures) > 0 {
+			status = exitFailed
+		}
+		if *asJSON {
+			results = append(results, testResult{File: file, Tests: len(f.Tests), Failures: failures})
+			continue
+		}
+		if *junit || *tap {
+			suite := junitResults.suite(file)
+			for i, test := range f.Tests {
+				problems := []string{}
+				for _, failure := range failures {
+					if failure.Test == i {
+						problems = append(problems, failure.Message)
+					}
+				}
+				name := test.Description
+				if name == "" {
+					name = fmt.Sprintf("test %d", i)
+				}
+				suite.add(name, problems)
+				tapResults.add(file+": "+name, problems)
+			}
+			continue
+		}
+		if len(failures) == 0 {
+			fmt.Printf("ok   %s (%d tests)\n", file, len(f.Tests))
+			continue
+		}
+		fmt.Printf("FAIL %s (%d of %d tests failed)\n", file, len(failures), len(f.Tests))
+		for _, failure := range failures {
+			fmt.Printf("     %s\n", failure.Error())
+		}
+	}
+	if *asJSON {
+		if err := writeJSON(results); err != nil {
+			return fail("test", err)
+		}
+	} else if *junit {
+		if err := junitResults.write(); err != nil {
+			return fail("test", err)
+		}
+	} else if *tap {
+		if err := tapResults.write(); err != nil {
+			return fail("test", err)
+		}
+	}
+	return status
+}
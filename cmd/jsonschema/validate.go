@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// validateResult is the outcome of validating one document, as written by
+// -json
+type validateResult struct {
+	File   string                `json:"file"`
+	Valid  bool                  `json:"valid"`
+	Errors []jsonschema.ValError `json:"errors"`
+}
+
+// runValidate validates documents against a schema:
+//
+//	jsonschema validate -schema schema.json [-json | -pretty | -sarif | -junit | -tap] [-fetch] [file ...]
+//
+// Documents are read from standard input when no files are given, or for
+// a file named "-". Files with a .yaml or .yml extension are read as YAML,
+// and files with a .toml, .cbor or .msgpack extension as TOML, CBOR or
+// MessagePack. Each error is printed on a line of its own as
+// "file: error", or with -json all results are written as a JSON array.
+// -pretty prints errors like compiler diagnostics instead, quoting the
+// line of the offending value and the keywords it fails, and -sarif writes
+// a SARIF log for code scanning tools, with a rule per failing keyword.
+// -junit writes a JUnit XML report with a test case per document, and -tap
+// a Test Anything Protocol stream with a test point per document
+func runValidate(args []string) int {
+	fs := newFlagSet("validate", "[file ...]")
+	schemaPath := fs.String("schema", "", "schema to validate against (required)")
+	asJSON := fs.Bool("json", false, "write results as JSON")
+	pretty := fs.Bool("pretty", false, "print errors with the offending lines of the documents")
+	sarif := fs.Bool("sarif", false, "write results as a SARIF log")
+	junit := fs.Bool("junit", false, "write results as a JUnit XML report")
+	tap := fs.Bool("tap", false, "write results as a TAP stream")
+	fetch := fs.Bool("fetch", false, "fetch remote references of the schema")
+	fs.Parse(args)
+	if *schemaPath == "" {
+		fs.Usage()
+		return exitTrouble
+	}
+	rs, err := readSchema(*schemaPath, *fetch)
+	if err != nil {
+		return fail("validate", err)
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	status := exitOK
+	results := []validateResult{}
+	diag := newDiagnostics()
+	sarifResults := newSARIFReport()
+	junitResults := newJUnitReport("validate")
+	suite := junitResults.suite(*schemaPath)
+	tapResults := &tapReport{}
+	for _, file := range files {
+		data, errs, err := validateFile(rs, file)
+		if err != nil {
+			return fail("validate", err)
+		}
+		if len(errs) > 0 {
+			status = exitFailed
+		}
+		if *asJSON {
+			results = append(results, validateResult{File: file, Valid: len(errs) == 0, Errors: errs})
+			continue
+		}
+		if *sarif {
+			sarifResults.addValErrors(file, data, rs, errs)
+			continue
+		}
+		if *junit || *tap {
+			problems := make([]string, len(errs))
+			for i, e := range errs {
+				problems[i] = e.Error()
+			}
+			suite.add(file, problems)
+			tapResults.add(file, problems)
+			continue
+		}
+		if *pretty {
+			diag.write(file, data, rs, errs)
+			continue
+		}
+		for _, e := range errs {
+			fmt.Printf("%s: %s\n", file, e.Error())
+		}
+	}
+	if *asJSON {
+		if err := writeJSON(results); err != nil {
+			return fail("validate", err)
+		}
+	} else if *sarif {
+		if err := sarifResults.write(); err != nil {
+			return fail("validate", err)
+		}
+	} else if *junit {
+		if err := junitResults.write(); err != nil {
+			return fail("validate", err)
+		}
+	} else if *tap {
+		if err := tapResults.write(); err != nil {
+			return fail("validate", err)
+		}
+	}
+	return status
+}
+
+// validateFile validates the document in file, "-" for standard input,
+// giving its contents and its errors ordered by location. Files are read
+// as YAML, TOML, CBOR or MessagePack by their extension. JSONC and JSON5
+// files are converted to JSON first, which gives the contents to locate
+// errors in
+func validateFile(rs *jsonschema.RootSchema, file string) ([]byte, []jsonschema.ValError, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	validate := rs.ValidateBytes
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		validate = rs.ValidateYAML
+	case ".cbor":
+		validate = rs.ValidateCBOR
+	case ".msgpack":
+		validate = rs.ValidateMsgPack
+	case ".toml":
+		validate = rs.ValidateTOML
+	case ".jsonc", ".json5":
+		if data, err = jsonschema.JSON5ToJSON(data); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+	}
+	errs, err := validate(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].PropertyPath != errs[j].PropertyPath {
+			return errs[i].PropertyPath < errs[j].PropertyPath
+		}
+		return errs[i].Message < errs[j].Message
+	})
+	return data, errs, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCommand runs a command with args, giving its exit status and what it
+// writes to standard output and standard error
+func runCommand(t *testing.T, run func(args []string) int, args ...string) (status int, stdout, stderr string) {
+	t.Helper()
+	stderr = capture(t, &os.Stderr, func() error {
+		stdout = captureStdout(t, func() error {
+			status = run(args)
+			return nil
+		})
+		return nil
+	})
+	return status, stdout, stderr
+}
+
+// writeFiles writes files, named by their paths relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.json":  `{"required": ["name"], "properties": {"age": {"type": "integer"}}}`,
+		"broken.json":  `{"type": 5}`,
+		"valid.json":   `{"name": "rex", "age": 3}`,
+		"invalid.json": `{"age": "old"}`,
+		"valid.yaml":   "name: rex\nage: 3\n",
+		"bad.json":     `{`,
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	cases := []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{"-schema", path("schema.json"), path("valid.json"), path("valid.yaml")}, exitOK, "", ""},
+		{[]string{"-schema", path("schema.json"), path("valid.json"), path("invalid.json")}, exitFailed,
+			path("invalid.json") + `: /: {"age":"old"} "name" value is required` + "\n" +
+				path("invalid.json") + `: /age: "old" type should be integer` + "\n", ""},
+		{[]string{"-schema", path("schema.json"), "-json", path("valid.json")}, exitOK,
+			"[\n  {\n    \"file\": \"" + path("valid.json") + "\",\n    \"valid\": true,\n    \"errors\": []\n  }\n]\n", ""},
+		{[]string{"-schema", path("schema.json"), "-tap", path("valid.json"), path("invalid.json")}, exitFailed,
+			"TAP version 13\n1..2\nok 1 - " + path("valid.json") + "\nnot ok 2 - " + path("invalid.json") + "\n" +
+				`  ---
+  problems:
+    - "/: {\"age\":\"old\"} \"name\" value is required"
+    - "/age: \"old\" type should be integer"
+  ...
+`, ""},
+		{[]string{path("valid.json")}, exitTrouble, "", "usage: jsonschema validate"},
+		{[]string{"-schema", path("missing.json"), path("valid.json")}, exitTrouble, "", "jsonschema validate: open " + path("missing.json")},
+		{[]string{"-schema", path("broken.json"), path("valid.json")}, exitTrouble, "", "jsonschema validate: parsing " + path("broken.json")},
+		{[]string{"-schema", path("schema.json"), path("bad.json")}, exitTrouble, "", "jsonschema validate: "},
+	}
+	for i, c := range cases {
+		status, stdout, stderr := runCommand(t, runValidate, c.args...)
+		if status != c.status {
+			t.Errorf("case %d: expected exit status %d, got %d. stderr: %s", i, c.status, status, stderr)
+		}
+		if stdout != c.stdout {
+			t.Errorf("case %d: expected output:\n%s\ngot:\n%s", i, c.stdout, stdout)
+		}
+		if !strings.HasPrefix(stderr, c.stderr) || (c.stderr == "" && stderr != "") {
+			t.Errorf("case %d: expected errors to begin with:\n%s\ngot:\n%s", i, c.stderr, stderr)
+		}
+	}
+}
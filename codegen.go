@@ -0,0 +1,653 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoOptions configures Go code generation
+type GoOptions struct {
+	// Package is the package name of the generated file, "schema" if empty
+	Package string
+	// RootName is the name of the type generated for the root schema. If
+	// empty it's derived from the schema title, falling back to "Root"
+	RootName string
+	// Refs maps "$ref" values, as written in the schema, to existing Go
+	// types to use in place of generating one. Types from other packages
+	// are qualified by import path, as in "example.com/pets.Cat". Mapped
+	// references don't need to resolve, and definitions they point at
+	// aren't declared
+	Refs map[string]string
+}
+
+// GenerateGo emits Go source declaring types for the root schema and each
+// of its top-level definitions:
+//
+//   - objects with properties become structs with json tags. Optional
+//     properties are pointers unless their type can already be nil
+//   - other objects become maps, arrays become slices
+//   - string and integer enums become named types with a constant per value
+//   - oneOf and anyOf become wrapper structs with a pointer field per
+//     branch, decoding into the first branch the data fits
+//   - allOf branches referring to structs are embedded, other branches
+//     contribute their properties
+//   - references become the named type of their target
+//
+// Keywords without a Go equivalent, like patterns and bounds, are left for
+// validation. The output is gofmt-formatted
+func GenerateGo(rs *RootSchema, opts GoOptions) ([]byte, error) {
+	g := &goGenerator{
+		typeDecls: newTypeDecls(),
+		imports:   map[string]bool{},
+		refs:      opts.Refs,
+		skip:      map[*Schema]bool{},
+	}
+	for ref := range opts.Refs {
+		if target := rs.localTarget(ref); target != nil {
+			g.skip[target] = true
+		}
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "schema"
+	}
+	root := opts.RootName
+	if root == "" {
+		root = goName(rs.Title)
+	}
+	if root == "" {
+		root = "Root"
+	}
+
+	if _, err := g.declare(&rs.Schema, root); err != nil {
+		return nil, err
+	}
+	for _, defs := range []Definitions{rs.Defs, rs.Definitions} {
+		for _, key := range sortedDefinitionKeys(defs) {
+			if g.skip[defs[key]] {
+				continue
+			}
+			if _, err := g.declare(defs[key], goName(key)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, strconv.Quote(imp))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(buf, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	for _, decl := range g.decls {
+		buf.WriteString(decl)
+		buf.WriteString("\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+// typeDecls holds the types a generator declares for schemas. named maps
+// each schema that has been given a type to its type name, names holds the
+// names taken and decls the declarations, in the order the types are first
+// used
+type typeDecls struct {
+	named map[*Schema]string
+	names map[string]bool
+	decls []string
+}
+
+func newTypeDecls() typeDecls {
+	return typeDecls{named: map[*Schema]string{}, names: map[string]bool{}}
+}
+
+// declare gives s a named type, returning the name: hint, numbered if it's
+// taken. body writes the declaration of the type, which comment prefixes
+// with the description of s if it has one
+func (d *typeDecls) declare(s *Schema, hint string, body func(name string) (string, error), comment func(name, description string) string) (string, error) {
+	if name, ok := d.named[s]; ok {
+		return name, nil
+	}
+	name := hint
+	for i := 2; d.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", hint, i)
+	}
+	d.names[name] = true
+	d.named[s] = name
+
+	// reserve a slot so types are declared in the order they're first used
+	idx := len(d.decls)
+	d.decls = append(d.decls, "")
+	decl, err := body(name)
+	if err != nil {
+		return "", err
+	}
+	if s.Description != "" {
+		decl = comment(name, s.Description) + decl
+	}
+	d.decls[idx] = decl
+	return name, nil
+}
+
+// goGenerator accumulates type declarations
+type goGenerator struct {
+	typeDecls
+	imports map[string]bool
+	refs    map[string]string
+	skip    map[*Schema]bool
+}
+
+// declare gives s a named type, returning the name
+func (g *goGenerator) declare(s *Schema, hint string) (string, error) {
+	return g.typeDecls.declare(s, hint, func(name string) (string, error) {
+		return g.body(s, name)
+	}, func(name, description string) string {
+		return goComment(name+" "+description, "")
+	})
+}
+
+// body gives the full declaration of the named type for s
+func (g *goGenerator) body(s *Schema, name string) (string, error) {
+	switch {
+	case s.Ref != "":
+		t, err := g.typeExpr(s, name)
+		return fmt.Sprintf("type %s %s\n", name, t), err
+	case isUnionSchema(s):
+		return g.unionBody(s, name)
+	case isEnumSchema(s):
+		return g.enumBody(s, name)
+	case isStructSchema(s):
+		fields, err := g.structFields(s, name)
+		return fmt.Sprintf("type %s struct {\n%s}\n", name, fields), err
+	}
+	t, err := g.typeExpr(s, name)
+	return fmt.Sprintf("type %s %s\n", name, t), err
+}
+
+// typeExpr gives a Go type expression for s, declaring named types for
+// structs, enums and unions nested within it
+func (g *goGenerator) typeExpr(s *Schema, hint string) (string, error) {
+	if s == nil || s.schemaType != schemaTypeObject {
+		return "interface{}", nil
+	}
+	if t, ok := g.refs[s.Ref]; ok && s.Ref != "" {
+		return g.qualify(t), nil
+	}
+	if s.Ref != "" {
+		var target *Schema
+		switch t := s.ref.(type) {
+		case *Schema:
+			target = t
+		case *RootSchema:
+			target = &t.Schema
+		}
+		if target == nil {
+			return "", fmt.Errorf("unresolved reference: %s", s.Ref)
+		}
+		return g.declare(target, refTypeName(s.Ref, hint))
+	}
+	types, nullable := nonNullTypes(s)
+	if isUnionSchema(s) || isEnumSchema(s) || isStructSchema(s) {
+		name, err := g.declare(s, hint)
+		if nullable && isStructSchema(s) {
+			name = "*" + name
+		}
+		return name, err
+	}
+	if len(types) != 1 {
+		return "interface{}", nil
+	}
+
+	t := "interface{}"
+	switch types[0] {
+	case "string":
+		t = "string"
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			t = "time.Time"
+		}
+	case "integer":
+		t = "int64"
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		t = "[]interface{}"
+		if items, ok := s.Validators["items"].(*Items); ok && items.single {
+			it, err := g.typeExpr(items.Schemas[0], hint+"Item")
+			if err != nil {
+				return "", err
+			}
+			t = "[]" + it
+		}
+	case "object":
+		t = "map[string]interface{}"
+		if add, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && add.Schema != nil && add.Schema.schemaType != schemaTypeFalse {
+			vt, err := g.typeExpr(add.Schema, hint+"Value")
+			if err != nil {
+				return "", err
+			}
+			t = "map[string]" + vt
+		}
+	}
+	if nullable && !nilable(t) {
+		t = "*" + t
+	}
+	return t, nil
+}
+
+// qualify turns a Go type, which may be qualified by import path, into a
+// type expression, importing its package
+func (g *goGenerator) qualify(t string) string {
+	mods := t[:len(t)-len(strings.TrimLeft(t, "*[]"))]
+	t = t[len(mods):]
+	dot := strings.LastIndex(t, ".")
+	if dot == -1 || dot < strings.LastIndex(t, "/") {
+		return mods + t
+	}
+	path := t[:dot]
+	g.imports[path] = true
+	return mods + path[strings.LastIndex(path, "/")+1:] + t[dot:]
+}
+
+// localTarget finds the schema a reference within rs points at, or nil
+func (rs *RootSchema) localTarget(ref string) *Schema {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	ptr, err := ParsePointer(ref)
+	if err != nil {
+		return nil
+	}
+	sch, _ := ptr.EvalSchema(&rs.Schema)
+	return sch
+}
+
+// structFields declares a field for each property of s and its allOf
+// branches
+func (g *goGenerator) structFields(s *Schema, name string) (string, error) {
+	buf := &bytes.Buffer{}
+	props := Properties{}
+	required := map[string]bool{}
+	collect := func(sch *Schema) {
+		if p, ok := sch.Validators["properties"].(*Properties); ok {
+			for key, prop := range *p {
+				if props[key] == nil {
+					props[key] = prop
+				}
+			}
+		}
+		if req, ok := sch.Validators["required"].(*Required); ok {
+			for _, key := range *req {
+				required[key] = true
+			}
+		}
+	}
+	collect(s)
+
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		for i, branch := range *allOf {
+			if branch.Ref != "" {
+				t, err := g.typeExpr(branch, fmt.Sprintf("%sPart%d", name, i+1))
+				if err != nil {
+					return "", err
+				}
+				fmt.Fprintf(buf, "\t%s\n", t)
+				continue
+			}
+			collect(branch)
+		}
+	}
+
+	fields := map[string]bool{}
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		prop := props[key]
+		field := goName(key)
+		if field == "" {
+			field = "Field"
+		}
+		for i := 2; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", goName(key), i)
+		}
+		fields[field] = true
+
+		t, err := g.typeExpr(prop, name+field)
+		if err != nil {
+			return "", err
+		}
+		tag := key
+		if !required[key] {
+			tag += ",omitempty"
+			if !nilable(t) {
+				t = "*" + t
+			}
+		}
+		if prop.Description != "" {
+			buf.WriteString(goComment(prop.Description, "\t"))
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:%s`\n", field, t, strconv.Quote(tag))
+	}
+	return buf.String(), nil
+}
+
+// enumBody declares a named string or integer type with a constant for
+// each enum value
+func (g *goGenerator) enumBody(s *Schema, name string) (string, error) {
+	vals := enumValues(s)
+	base := "string"
+	if _, ok := vals[0].(float64); ok {
+		base = "int64"
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "type %s %s\n\n", name, base)
+	buf.WriteString("const (\n")
+	used := map[string]bool{}
+	for i, v := range vals {
+		suffix, lit := "", ""
+		switch t := v.(type) {
+		case string:
+			suffix, lit = goName(t), strconv.Quote(t)
+		case float64:
+			lit = strconv.FormatInt(int64(t), 10)
+			suffix = strings.Replace(lit, "-", "Minus", 1)
+		}
+		cname := name + suffix
+		if suffix == "" || used[cname] || g.names[cname] {
+			cname = fmt.Sprintf("%sValue%d", name, i+1)
+		}
+		used[cname] = true
+		g.names[cname] = true
+		fmt.Fprintf(buf, "\t%s %s = %s\n", cname, name, lit)
+	}
+	buf.WriteString(")\n")
+	return buf.String(), nil
+}
+
+// unionBody declares a wrapper struct for a oneOf or anyOf, with a field
+// for each branch and methods to encode and decode whichever is set
+func (g *goGenerator) unionBody(s *Schema, name string) (string, error) {
+	var branches []*Schema
+	switch v := s.Validators["oneOf"].(type) {
+	case *OneOf:
+		branches = *v
+	default:
+		branches = *s.Validators["anyOf"].(*AnyOf)
+	}
+	g.imports["bytes"] = true
+	g.imports["encoding/json"] = true
+	g.imports["fmt"] = true
+
+	type variant struct{ field, typ string }
+	variants := []variant{}
+	fields := map[string]bool{}
+	for i, branch := range branches {
+		t, err := g.typeExpr(branch, fmt.Sprintf("%sOption%d", name, i+1))
+		if err != nil {
+			return "", err
+		}
+		base := variantName(t)
+		field := base
+		for j := 2; fields[field]; j++ {
+			field = fmt.Sprintf("%s%d", base, j)
+		}
+		fields[field] = true
+		variants = append(variants, variant{field, t})
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	for _, v := range variants {
+		fmt.Fprintf(buf, "\t%s *%s\n", v.field, v.typ)
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "// MarshalJSON encodes whichever variant of %s is set\n", name)
+	fmt.Fprintf(buf, "func (v %s) MarshalJSON() ([]byte, error) {\n\tswitch {\n", name)
+	for _, v := range variants {
+		fmt.Fprintf(buf, "\tcase v.%s != nil:\n\t\treturn json.Marshal(v.%s)\n", v.field, v.field)
+	}
+	buf.WriteString("\t}\n\treturn []byte(\"null\"), nil\n}\n\n")
+
+	fmt.Fprintf(buf, "// UnmarshalJSON decodes data into the first variant of %s it fits\n", name)
+	fmt.Fprintf(buf, "func (v *%s) UnmarshalJSON(data []byte) error {\n\t*v = %s{}\n", name, name)
+	// the strict decoder is declared in each method, so that files generated
+	// into the same package don't redeclare a shared helper
+	buf.WriteString("\tstrict := func(x interface{}) error {\n\t\tdec := json.NewDecoder(bytes.NewReader(data))\n\t\tdec.DisallowUnknownFields()\n\t\treturn dec.Decode(x)\n\t}\n")
+	for i, v := range variants {
+		fmt.Fprintf(buf, "\tvar v%d %s\n\tif err := strict(&v%d); err == nil {\n\t\tv.%s = &v%d\n\t\treturn nil\n\t}\n", i, v.typ, i, v.field, i)
+	}
+	fmt.Fprintf(buf, "\treturn fmt.Errorf(\"data doesn't match any variant of %s\")\n}\n", name)
+	return buf.String(), nil
+}
+
+// variantName names a wrapper field after the Go type it holds
+func variantName(t string) string {
+	switch {
+	case strings.HasPrefix(t, "*"):
+		return variantName(t[1:])
+	case strings.HasPrefix(t, "[]"):
+		return variantName(t[2:]) + "Slice"
+	case strings.HasPrefix(t, "map[string]"):
+		return variantName(t[len("map[string]"):]) + "Map"
+	case t == "interface{}":
+		return "Value"
+	case t == "time.Time":
+		return "Time"
+	}
+	return goName(t)
+}
+
+func isUnionSchema(s *Schema) bool {
+	return s.Validators["oneOf"] != nil || s.Validators["anyOf"] != nil
+}
+
+// isEnumSchema reports whether s is an enum of strings or integers only
+func isEnumSchema(s *Schema) bool {
+	vals := enumValues(s)
+	if len(vals) == 0 {
+		return false
+	}
+	_, str := vals[0].(string)
+	for _, v := range vals {
+		switch t := v.(type) {
+		case string:
+			if !str {
+				return false
+			}
+		case float64:
+			if str || t != float64(int64(t)) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isStringEnum reports whether s is an enum of strings only
+func isStringEnum(s *Schema) bool {
+	if !isEnumSchema(s) {
+		return false
+	}
+	_, str := enumValues(s)[0].(string)
+	return str
+}
+
+func enumValues(s *Schema) []interface{} {
+	enum, ok := s.Validators["enum"].(*Enum)
+	if !ok {
+		return nil
+	}
+	vals := []interface{}{}
+	for _, con := range *enum {
+		var v interface{}
+		if err := json.Unmarshal(con, &v); err != nil {
+			return nil
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+// isStructSchema reports whether s describes an object with known properties
+func isStructSchema(s *Schema) bool {
+	types, _ := nonNullTypes(s)
+	if len(types) > 1 || (len(types) == 1 && types[0] != "object") {
+		return false
+	}
+	if s.Validators["properties"] != nil {
+		return true
+	}
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		for _, branch := range *allOf {
+			if branch.Ref != "" || branch.Validators["properties"] != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectProperties gathers the properties and required property names of
+// s, its allOf branches and the schemas they refer to
+func collectProperties(s *Schema) (Properties, map[string]bool) {
+	props := Properties{}
+	required := map[string]bool{}
+	seen := map[*Schema]bool{}
+	var collect func(sch *Schema)
+	collect = func(sch *Schema) {
+		if seen[sch] {
+			return
+		}
+		seen[sch] = true
+		if target := refTarget(sch); target != nil && sch.Ref != "" {
+			collect(target)
+		}
+		if p, ok := sch.Validators["properties"].(*Properties); ok {
+			for key, prop := range *p {
+				if props[key] == nil {
+					props[key] = prop
+				}
+			}
+		}
+		if req, ok := sch.Validators["required"].(*Required); ok {
+			for _, key := range *req {
+				required[key] = true
+			}
+		}
+		if allOf, ok := sch.Validators["allOf"].(*AllOf); ok {
+			for _, branch := range *allOf {
+				collect(branch)
+			}
+		}
+	}
+	collect(s)
+	return props, required
+}
+
+// refTarget gives the schema reference s points at, nil if it's unresolved
+func refTarget(s *Schema) *Schema {
+	switch t := s.ref.(type) {
+	case *Schema:
+		return t
+	case *RootSchema:
+		return &t.Schema
+	}
+	return nil
+}
+
+// schemaTypeNames gives the names in the type keyword of s, nil if it has none
+func schemaTypeNames(s *Schema) []string {
+	if t, ok := s.Validators["type"].(*Type); ok {
+		return t.vals
+	}
+	return nil
+}
+
+// nonNullTypes gives the type names of s other than "null", and whether
+// "null" was among them or "nullable" adds it
+func nonNullTypes(s *Schema) ([]string, bool) {
+	types := []string{}
+	nullable := s.nullable()
+	for _, t := range schemaTypeNames(s) {
+		if t == "null" {
+			nullable = true
+			continue
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 && !nullable {
+		return nil, false
+	}
+	return types, nullable
+}
+
+// nilable reports whether a Go type expression already has a nil value
+func nilable(t string) bool {
+	return strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") ||
+		strings.HasPrefix(t, "*") || t == "interface{}"
+}
+
+// refTypeName picks a type name for the target of a reference
+func refTypeName(ref, hint string) string {
+	idx := strings.LastIndexAny(ref, "/#")
+	if name := goName(ref[idx+1:]); name != "" {
+		return name
+	}
+	return hint
+}
+
+// goInitialisms are written in upper case in Go identifiers
+var goInitialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// goName converts a JSON name into an exported Go identifier
+func goName(str string) string {
+	parts := strings.FieldsFunc(str, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	buf := &strings.Builder{}
+	for _, part := range parts {
+		if goInitialisms[strings.ToLower(part)] {
+			buf.WriteString(strings.ToUpper(part))
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	name := buf.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "N" + name
+	}
+	return name
+}
+
+// goComment formats text as a Go comment at the given indent
+func goComment(text, indent string) string {
+	buf := &strings.Builder{}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+	return buf.String()
+}
@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	rs := Must(`{
+		"title": "pet store",
+		"type": "object",
+		"properties": {
+			"id": { "type": "integer" },
+			"name": { "type": "string", "description": "display name" },
+			"status": { "enum": ["available", "sold"] },
+			"pet": { "$ref": "#/definitions/pet" },
+			"tags": { "type": "array", "items": { "type": "string" } },
+			"updated": { "type": "string", "format": "date-time" },
+			"owner": { "type": ["object", "null"], "properties": { "email": { "type": "string" } }, "required": ["email"] },
+			"extra": { "additionalProperties": { "type": "number" }, "type": "object" }
+		},
+		"required": ["id", "name"],
+		"definitions": {
+			"pet": { "oneOf": [ { "$ref": "#/definitions/cat" }, { "$ref": "#/definitions/dog" } ] },
+			"cat": { "type": "object", "properties": { "lives": { "type": "integer" } }, "additionalProperties": false },
+			"dog": { "allOf": [ { "$ref": "#/definitions/animal" }, { "properties": { "good": { "type": "boolean" } } } ] },
+			"animal": { "description": "anything with legs", "properties": { "legs": { "enum": [2, 4] } } }
+		}
+	}`)
+
+	got, err := GenerateGo(rs, GoOptions{Package: "store"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "// Code generated by jsonschema. DO NOT EDIT.\n\npackage store\n\nimport (\n\t\"bytes\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"time\"\n)\n\ntype PetStore struct {\n\tExtra map[string]float64 `json:\"extra,omitempty\"`\n\tID    int64              `json:\"id\"`\n\t// display name\n\tName    string          `json:\"name\"`\n\tOwner   *PetStoreOwner  `json:\"owner,omitempty\"`\n\tPet     *Pet            `json:\"pet,omitempty\"`\n\tStatus  *PetStoreStatus `json:\"status,omitempty\"`\n\tTags    []string        `json:\"tags,omitempty\"`\n\tUpdated *time.Time      `json:\"updated,omitempty\"`\n}\n\ntype PetStoreOwner struct {\n\tEmail string `json:\"email\"`\n}\n\ntype Pet struct {\n\tCat *Cat\n\tDog *Dog\n}\n\n// MarshalJSON encodes whichever variant of Pet is set\nfunc (v Pet) MarshalJSON() ([]byte, error) {\n\tswitch {\n\tcase v.Cat != nil:\n\t\treturn json.Marshal(v.Cat)\n\tcase v.Dog != nil:\n\t\treturn json.Marshal(v.Dog)\n\t}\n\treturn []byte(\"null\"), nil\n}\n\n// UnmarshalJSON decodes data into the first variant of Pet it fits\nfunc (v *Pet) UnmarshalJSON(data []byte) error {\n\t*v = Pet{}\n\tstrict := func(x interface{}) error {\n\t\tdec := json.NewDecoder(bytes.NewReader(data))\n\t\tdec.DisallowUnknownFields()\n\t\treturn dec.Decode(x)\n\t}\n\tvar v0 Cat\n\tif err := strict(&v0); err == nil {\n\t\tv.Cat = &v0\n\t\treturn nil\n\t}\n\tvar v1 Dog\n\tif err := strict(&v1); err == nil {\n\t\tv.Dog = &v1\n\t\treturn nil\n\t}\n\treturn fmt.Errorf(\"data doesn't match any variant of Pet\")\n}\n\ntype Cat struct {\n\tLives *int64 `json:\"lives,omitempty\"`\n}\n\ntype Dog struct {\n\tAnimal\n\tGood *bool `json:\"good,omitempty\"`\n}\n\n// Animal anything with legs\ntype Animal struct {\n\tLegs *AnimalLegs `json:\"legs,omitempty\"`\n}\n\ntype AnimalLegs int64\n\nconst (\n\tAnimalLegs2 AnimalLegs = 2\n\tAnimalLegs4 AnimalLegs = 4\n)\n\ntype PetStoreStatus string\n\nconst (\n\tPetStoreStatusAvailable PetStoreStatus = \"available\"\n\tPetStoreStatusSold      PetStoreStatus = \"sold\"\n)\n"
+	if string(got) != expect {
+		t.Errorf("output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateGo(Must(`{"type": "array", "items": {"$ref": "#"}}`), GoOptions{RootName: "List"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "// Code generated by jsonschema. DO NOT EDIT.\n\npackage schema\n\ntype List []List\n"
+	if string(got) != expect {
+		t.Errorf("recursive output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	rs = Must(`{
+		"properties": {
+			"id": { "$ref": "common.json#/definitions/id" },
+			"tags": { "type": "array", "items": { "$ref": "#/definitions/tag" } },
+			"at": { "$ref": "#/definitions/time" }
+		},
+		"definitions": {
+			"tag": { "type": "string" },
+			"time": { "type": "string", "format": "date-time" }
+		}
+	}`)
+	got, err = GenerateGo(rs, GoOptions{Refs: map[string]string{
+		"common.json#/definitions/id": "example.com/common.ID",
+		"#/definitions/tag":           "*example.com/labels.Tag",
+		"#/definitions/time":          "time.Time",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "// Code generated by jsonschema. DO NOT EDIT.\n\npackage schema\n\nimport (\n\t\"example.com/common\"\n\t\"example.com/labels\"\n\t\"time\"\n)\n\ntype Root struct {\n\tAt   *time.Time    `json:\"at,omitempty\"`\n\tID   *common.ID    `json:\"id,omitempty\"`\n\tTags []*labels.Tag `json:\"tags,omitempty\"`\n}\n"
+	if string(got) != expect {
+		t.Errorf("mapped refs output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	rs = Must(`{"properties": {"x": {"$ref": "other.json#/definitions/missing"}}}`)
+	if _, err := GenerateGo(rs, GoOptions{}); err == nil {
+		t.Error("expected an error for an unresolved reference")
+	}
+}
+
+func TestGenerateGoSamePackage(t *testing.T) {
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for i, schema := range []string{
+		`{"title": "shape", "oneOf": [{"type": "string"}, {"type": "integer"}]}`,
+		`{"title": "color", "anyOf": [{"type": "boolean"}, {"type": "number"}]}`,
+	} {
+		src, err := GenerateGo(Must(schema), GoOptions{Package: "shared"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(fset, fmt.Sprintf("gen%d.go", i), src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("shared", fset, files, nil); err != nil {
+		t.Errorf("expected generated files to compile together, got %s", err)
+	}
+}
@@ -0,0 +1,148 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compatibility is a schema evolution policy, modelled on the compatibility
+// levels of Avro schema registries
+type Compatibility int
+
+const (
+	// CompatibilityNone accepts any new schema version
+	CompatibilityNone Compatibility = iota
+	// CompatibilityBackward requires the new schema to accept every instance
+	// the latest previous version accepts, so consumers can upgrade first
+	CompatibilityBackward
+	// CompatibilityForward requires the latest previous version to accept
+	// every instance the new schema accepts, so producers can upgrade first
+	CompatibilityForward
+	// CompatibilityFull requires both backward and forward compatibility
+	CompatibilityFull
+	// CompatibilityBackwardTransitive is backward compatibility with every
+	// previous version
+	CompatibilityBackwardTransitive
+	// CompatibilityForwardTransitive is forward compatibility with every
+	// previous version
+	CompatibilityForwardTransitive
+	// CompatibilityFullTransitive is full compatibility with every previous
+	// version
+	CompatibilityFullTransitive
+)
+
+var compatibilityNames = map[Compatibility]string{
+	CompatibilityNone:               "NONE",
+	CompatibilityBackward:           "BACKWARD",
+	CompatibilityForward:            "FORWARD",
+	CompatibilityFull:               "FULL",
+	CompatibilityBackwardTransitive: "BACKWARD_TRANSITIVE",
+	CompatibilityForwardTransitive:  "FORWARD_TRANSITIVE",
+	CompatibilityFullTransitive:     "FULL_TRANSITIVE",
+}
+
+// String implements the stringer interface for Compatibility
+func (c Compatibility) String() string {
+	if name, ok := compatibilityNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Compatibility(%d)", int(c))
+}
+
+// ParseCompatibility reads a compatibility level by name, like "BACKWARD" or
+// "full_transitive"
+func ParseCompatibility(name string) (Compatibility, error) {
+	for c, n := range compatibilityNames {
+		if strings.EqualFold(n, name) {
+			return c, nil
+		}
+	}
+	return CompatibilityNone, fmt.Errorf("unknown compatibility level: %q", name)
+}
+
+func (c Compatibility) backward() bool {
+	return c == CompatibilityBackward || c == CompatibilityFull ||
+		c == CompatibilityBackwardTransitive || c == CompatibilityFullTransitive
+}
+
+func (c Compatibility) forward() bool {
+	return c == CompatibilityForward || c == CompatibilityFull ||
+		c == CompatibilityForwardTransitive || c == CompatibilityFullTransitive
+}
+
+func (c Compatibility) transitive() bool {
+	return c == CompatibilityBackwardTransitive || c == CompatibilityForwardTransitive ||
+		c == CompatibilityFullTransitive
+}
+
+// CompatibilityViolation describes a previous schema version the new
+// version isn't compatible with
+type CompatibilityViolation struct {
+	// Version is the index of the previous version the check failed against
+	Version int
+	// Backward is true when the new schema rejects instances the previous
+	// version accepts, false when it accepts instances the previous version
+	// rejects
+	Backward bool
+	// Changes lists the differences responsible for the violation. Forward
+	// violations list changes from the new version back to the previous one.
+	// Changes may be empty when compatibility couldn't be proven for other
+	// reasons
+	Changes []SchemaChange
+}
+
+// Error implements the error interface for CompatibilityViolation
+func (v CompatibilityViolation) Error() string {
+	dir := "forward"
+	if v.Backward {
+		dir = "backward"
+	}
+	if len(v.Changes) == 0 {
+		return fmt.Sprintf("version %d: %s compatibility can't be proven", v.Version, dir)
+	}
+	strs := make([]string, len(v.Changes))
+	for i, c := range v.Changes {
+		strs[i] = c.String()
+	}
+	return fmt.Sprintf("version %d: not %s compatible: %s", v.Version, dir, strings.Join(strs, "; "))
+}
+
+// CheckCompatibility checks a new schema version against previous versions,
+// ordered oldest first, under a compatibility policy. Non-transitive
+// policies only check the latest previous version. Compatibility is decided
+// with IsSubschemaOf, so a version may be reported as incompatible when the
+// relationship can't be proven; each violation lists the breaking changes
+// found by Diff to explain it
+func CheckCompatibility(level Compatibility, new *RootSchema, previous ...*RootSchema) []CompatibilityViolation {
+	violations := []CompatibilityViolation{}
+	if len(previous) == 0 {
+		return violations
+	}
+	first := len(previous) - 1
+	if level.transitive() {
+		first = 0
+	}
+
+	for i := len(previous) - 1; i >= first; i-- {
+		old := previous[i]
+		if level.backward() && !IsSubschemaOf(&old.Schema, &new.Schema) {
+			violations = append(violations, CompatibilityViolation{Version: i, Backward: true, Changes: breakingChanges(old, new)})
+		}
+		if level.forward() && !IsSubschemaOf(&new.Schema, &old.Schema) {
+			violations = append(violations, CompatibilityViolation{Version: i, Changes: breakingChanges(new, old)})
+		}
+	}
+	return violations
+}
+
+// breakingChanges lists the changes from old to new that can reject
+// instances old accepts
+func breakingChanges(old, new *RootSchema) []SchemaChange {
+	res := []SchemaChange{}
+	for _, c := range Diff(old, new) {
+		if c.Breaking {
+			res = append(res, c)
+		}
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	v1 := Must(`{ "type": "object", "properties": { "name": { "type": "string", "maxLength": 10 } } }`)
+	v2 := Must(`{ "type": "object", "properties": { "name": { "type": "string", "maxLength": 20 } } }`)
+	v3 := Must(`{ "type": "object", "properties": { "name": { "type": "string", "maxLength": 5 } } }`)
+
+	cases := []struct {
+		level    Compatibility
+		new      *RootSchema
+		previous []*RootSchema
+		expect   []string
+	}{
+		{CompatibilityNone, v3, []*RootSchema{v1, v2}, []string{}},
+		{CompatibilityBackward, v2, []*RootSchema{v1}, []string{}},
+		{CompatibilityForward, v2, []*RootSchema{v1},
+			[]string{"version 0: not forward compatible: changed /properties/name/maxLength: 20 -> 10 (breaking)"}},
+		{CompatibilityFull, v1, []*RootSchema{v1}, []string{}},
+		{CompatibilityBackward, v3, []*RootSchema{v1, v2},
+			[]string{"version 1: not backward compatible: changed /properties/name/maxLength: 20 -> 5 (breaking)"}},
+		{CompatibilityForwardTransitive, v3, []*RootSchema{v1, v2}, []string{}},
+		{CompatibilityBackwardTransitive, v3, []*RootSchema{v1, v2}, []string{
+			"version 1: not backward compatible: changed /properties/name/maxLength: 20 -> 5 (breaking)",
+			"version 0: not backward compatible: changed /properties/name/maxLength: 10 -> 5 (breaking)",
+		}},
+	}
+
+	for i, c := range cases {
+		got := CheckCompatibility(c.level, c.new, c.previous...)
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d: expected %d violations, got: %v", i, len(c.expect), got)
+			continue
+		}
+		for j, v := range got {
+			if v.Error() != c.expect[j] {
+				t.Errorf("case %d violation %d: expected %q, got: %q", i, j, c.expect[j], v.Error())
+			}
+		}
+	}
+}
+
+func TestParseCompatibility(t *testing.T) {
+	for c, name := range compatibilityNames {
+		got, err := ParseCompatibility(name)
+		if err != nil {
+			t.Errorf("error parsing %s: %s", name, err)
+		}
+		if got != c {
+			t.Errorf("expected %s to parse as %d, got: %d", name, c, got)
+		}
+	}
+	if c, _ := ParseCompatibility("full_transitive"); c != CompatibilityFullTransitive {
+		t.Errorf("expected names to be case-insensitive")
+	}
+	if _, err := ParseCompatibility("sideways"); err == nil {
+		t.Errorf("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,189 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateConfig validates config, a merged configuration map such as
+// Viper's AllSettings or koanf's Raw give, against the schema. First the
+// defaults the schema declares (see Defaults) are written into config for
+// keys it lacks, creating the tables holding them as needed, so they can
+// be loaded back into the configuration library. Values keep the Go types
+// they were loaded with: integers and other numbers validate as numbers,
+// durations as strings like "1m30s" and times as RFC 3339 strings. The
+// PropertyPath of each error is a dotted configuration key, such as
+// "server.tls.cert" or "upstreams[0].url", and is empty for the
+// configuration as a whole. The error reports values that can't be
+// represented as JSON
+func (rs *RootSchema) ValidateConfig(config map[string]interface{}) ([]ValError, error) {
+	errs := []ValError{}
+	applyConfigDefaults(config, rs.Defaults())
+
+	doc, err := configValue(rs.options().Decoding, config)
+	if err != nil {
+		return errs, err
+	}
+	rs.Validate("/", doc, &errs)
+	for i := range errs {
+		errs[i].PropertyPath = configKey(doc, errs[i].PropertyPath)
+	}
+	return errs, nil
+}
+
+// applyConfigDefaults writes defaults, keyed by instance JSON pointer, into
+// config where it has no value. Defaults within arrays are only written
+// to elements config already has
+func applyConfigDefaults(config map[string]interface{}, defaults map[string]interface{}) {
+	ptrs := make([]string, 0, len(defaults))
+	for ptr := range defaults {
+		ptrs = append(ptrs, ptr)
+	}
+	// parents sort before their children
+	sort.Strings(ptrs)
+
+	for _, ptr := range ptrs {
+		tokens, err := ParsePointer(ptr)
+		if err != nil || len(tokens) == 0 {
+			continue
+		}
+		var parent interface{} = config
+		for _, tok := range tokens[:len(tokens)-1] {
+			switch p := parent.(type) {
+			case map[string]interface{}:
+				if _, ok := p[tok]; !ok {
+					p[tok] = map[string]interface{}{}
+				}
+				parent = p[tok]
+			case []interface{}:
+				i, err := strconv.Atoi(tok)
+				if err != nil || i < 0 || i >= len(p) {
+					parent = nil
+					break
+				}
+				parent = p[i]
+			default:
+				parent = nil
+			}
+		}
+		if obj, ok := parent.(map[string]interface{}); ok {
+			if _, ok := obj[tokens[len(tokens)-1]]; !ok {
+				obj[tokens[len(tokens)-1]] = cloneValue(defaults[ptr])
+			}
+		}
+	}
+}
+
+// configValue converts a configuration value to the values validation
+// works with, decoding numbers as o sets
+func configValue(o DecodeOptions, v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case nil, bool, string, float64, json.Number:
+		return v, nil
+	case time.Duration:
+		return t.String(), nil
+	case time.Time:
+		return t.Format(time.RFC3339Nano), nil
+	case json.Marshaler:
+		return configJSONValue(o, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return o.decodedInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return o.decodedUint(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return o.decodedFloat(rv.Float()), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return configValue(o, rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			// byte slices encode as base64 strings
+			return configJSONValue(o, v)
+		}
+		arr := make([]interface{}, rv.Len())
+		for i := range arr {
+			val, err := configValue(o, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = val
+		}
+		return arr, nil
+	case reflect.Map:
+		obj := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			// YAML decoders give map[interface{}]interface{}
+			key := fmt.Sprint(iter.Key().Interface())
+			val, err := configValue(o, iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = val
+		}
+		return obj, nil
+	}
+	return configJSONValue(o, v)
+}
+
+// configJSONValue converts a configuration value through its JSON encoding
+func configJSONValue(o DecodeOptions, v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration value %v: %s", v, err.Error())
+	}
+	doc, err := o.decodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration value %v: %s", v, err.Error())
+	}
+	return doc, nil
+}
+
+// configKey converts the instance JSON pointer ptr within doc to a dotted
+// configuration key, writing array elements as indexes
+func configKey(doc interface{}, ptr string) string {
+	if ptr == "/" {
+		return ""
+	}
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return ptr
+	}
+	key := &strings.Builder{}
+	for _, tok := range tokens {
+		switch d := doc.(type) {
+		case []interface{}:
+			key.WriteString("[" + tok + "]")
+			if i, err := strconv.Atoi(tok); err == nil && i >= 0 && i < len(d) {
+				doc = d[i]
+			} else {
+				doc = nil
+			}
+			continue
+		case map[string]interface{}:
+			doc = d[tok]
+		default:
+			doc = nil
+		}
+		if key.Len() > 0 {
+			key.WriteByte('.')
+		}
+		key.WriteString(tok)
+	}
+	return key.String()
+}
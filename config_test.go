@@ -0,0 +1,129 @@
+package jsonschema
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateConfig(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["server"],
+		"properties": {
+			"server": {
+				"type": "object",
+				"required": ["host"],
+				"properties": {
+					"host": {"type": "string"},
+					"port": {"type": "integer", "maximum": 65535, "default": 8080},
+					"timeout": {"type": "string", "pattern": "^([0-9]+[a-z]+)+$"}
+				}
+			},
+			"log": {
+				"type": "object",
+				"properties": {
+					"level": {"enum": ["debug", "info", "warn"], "default": "info"}
+				}
+			},
+			"upstreams": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"url": {"type": "string", "format": "uri"},
+						"weight": {"type": "number", "minimum": 0}
+					}
+				}
+			}
+		}
+	}`)
+
+	config := map[string]interface{}{
+		"server": map[string]interface{}{
+			"host":    "localhost",
+			"timeout": 90 * time.Second,
+		},
+		"upstreams": []map[interface{}]interface{}{
+			{"url": "http://a.example", "weight": 1},
+		},
+	}
+	errs, err := rs.ValidateConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	if port := config["server"].(map[string]interface{})["port"]; port != float64(8080) {
+		t.Errorf("expected the default port to be applied, got %v", port)
+	}
+	if log, ok := config["log"].(map[string]interface{}); !ok || log["level"] != "info" {
+		t.Errorf("expected the default log level to be applied, got %v", config["log"])
+	}
+
+	config = map[string]interface{}{
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": uint16(8443),
+		},
+		"log": map[string]interface{}{"level": "trace"},
+		"upstreams": []interface{}{
+			map[string]interface{}{"url": "http://a.example", "weight": int64(-1)},
+		},
+	}
+	errs, err = rs.ValidateConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, e := range errs {
+		got[e.PropertyPath] = true
+	}
+	for _, key := range []string{"log.level", "upstreams[0].weight"} {
+		if !got[key] {
+			t.Errorf("expected an error at %s, got %v", key, errs)
+		}
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+	if port := config["server"].(map[string]interface{})["port"]; port != uint16(8443) {
+		t.Errorf("expected the port to be kept, got %v", port)
+	}
+
+	errs, err = rs.ValidateConfig(map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].PropertyPath != "server" {
+		t.Errorf("expected an error for the missing server host, got %v", errs)
+	}
+
+	if _, err := rs.ValidateConfig(map[string]interface{}{"hook": func() {}}); err == nil {
+		t.Error("expected an error for a value that isn't JSON")
+	}
+}
+
+func TestConfigKey(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{
+			"0":    []interface{}{map[string]interface{}{"b/c": 1.0}},
+			"list": []interface{}{1.0},
+		},
+	}
+	cases := []struct {
+		ptr, key string
+	}{
+		{"/", ""},
+		{"/a", "a"},
+		{"/a/0", "a.0"},
+		{"/a/0/0/b~1c", "a.0[0].b/c"},
+		{"/a/list/3", "a.list[3]"},
+		{"/missing/x", "missing.x"},
+	}
+	for _, c := range cases {
+		if got := configKey(doc, c.ptr); got != c.key {
+			t.Errorf("%s: expected %q, got %q", c.ptr, c.key, got)
+		}
+	}
+}
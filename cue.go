@@ -0,0 +1,496 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CUEOptions configures CUE generation
+type CUEOptions struct {
+	// Package is the package of the generated file, "schema" if empty
+	Package string
+	// RootName is the name of the definition generated for the root schema,
+	// without the leading "#". If empty it's derived from the schema title,
+	// falling back to "Root"
+	RootName string
+}
+
+// cueAnnotations are keywords with no bearing on validation, which are left
+// out of CUE output. Descriptions and titles become comments
+var cueAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "id": true, "$comment": true, "title": true, "description": true,
+	"examples": true, "readOnly": true, "writeOnly": true, "contentMediaType": true, "contentEncoding": true,
+	"definitions": true, "$defs": true, "format": true,
+}
+
+// cueIdentifier matches labels CUE accepts without quotes
+var cueIdentifier = regexp.MustCompile(`^[a-zA-Z$][a-zA-Z0-9_$]*$`)
+
+// cueKeywords can't be used as unquoted labels
+var cueKeywords = map[string]bool{
+	"package": true, "import": true, "for": true, "in": true, "if": true, "let": true,
+	"true": true, "false": true, "null": true,
+}
+
+// GenerateCUE emits CUE definitions for the root schema and each of its
+// top-level definitions, for validating with CUE what's described with JSON
+// Schema. Objects become structs, closed unless they allow additional
+// properties, with required fields marked "!" as in CUE v0.6 and later.
+// Types, bounds, lengths, patterns, item and property counts, enums, consts
+// and defaults become their CUE equivalents, and date-time strings
+// time.Time. allOf becomes a conjunction, and anyOf and oneOf disjunctions,
+// which accept values matching more than one oneOf branch. References
+// become references to definitions.
+//
+// Schemas without a type are taken to be of the types their keywords apply
+// to. Annotations are left out, while assertions CUE can't express, like
+// "not", "if" or tuple "items", and references to anything but top-level
+// definitions are errors, as leaving them out would accept values rs
+// rejects
+func GenerateCUE(rs *RootSchema, opts CUEOptions) ([]byte, error) {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "schema"
+	}
+	root := opts.RootName
+	if root == "" {
+		root = goName(rs.Title)
+	}
+	if root == "" {
+		root = "Root"
+	}
+
+	c := &cueConverter{names: map[string]string{"#": "#" + root}, imports: map[string]bool{}}
+	used := map[string]bool{root: true}
+	type def struct {
+		name, ptr string
+		schema    interface{}
+	}
+	defs := []def{}
+	if m, ok := doc.(map[string]interface{}); ok {
+		for _, keyword := range []string{"$defs", "definitions"} {
+			named, _ := m[keyword].(map[string]interface{})
+			for _, key := range sortedMapKeys(named) {
+				base := goName(key)
+				if base == "" {
+					base = "Def"
+				}
+				name := base
+				for i := 2; used[name]; i++ {
+					name = fmt.Sprintf("%s%d", base, i)
+				}
+				used[name] = true
+				ptr := "#/" + keyword + "/" + EscapePointerToken(key)
+				c.names[ptr] = "#" + name
+				defs = append(defs, def{"#" + name, ptr, named[key]})
+			}
+		}
+	}
+
+	rootExpr, err := c.expr(doc, "", "")
+	if err != nil {
+		return nil, err
+	}
+	decls := []string{}
+	for _, d := range defs {
+		expr, err := c.expr(d.schema, "/"+strings.TrimPrefix(d.ptr, "#/"), "")
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, cueComment(d.schema, "")+d.name+": "+expr+"\n")
+	}
+	// leave out roots that only hold definitions
+	if rootExpr != "_" || len(defs) == 0 || c.rootRef {
+		decls = append([]string{cueComment(doc, "") + "#" + root + ": " + rootExpr + "\n"}, decls...)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	imports := make([]string, 0, len(c.imports))
+	for imp := range c.imports {
+		imports = append(imports, fmt.Sprintf("%q", imp))
+	}
+	sort.Strings(imports)
+	switch len(imports) {
+	case 0:
+	case 1:
+		fmt.Fprintf(buf, "import %s\n\n", imports[0])
+	default:
+		fmt.Fprintf(buf, "import (\n\t%s\n)\n\n", strings.Join(imports, "\n\t"))
+	}
+	buf.WriteString(strings.Join(decls, "\n"))
+	return buf.Bytes(), nil
+}
+
+// cueConverter converts decoded schemas to CUE expressions. names maps the
+// references that can be converted to the definitions they name, and
+// rootRef records whether the root schema is referred to
+type cueConverter struct {
+	names   map[string]string
+	imports map[string]bool
+	rootRef bool
+}
+
+// expr gives a CUE expression for the schema v found at ptr, with nested
+// lines at the given indent
+func (c *cueConverter) expr(v interface{}, ptr, indent string) (string, error) {
+	switch t := v.(type) {
+	case bool:
+		if t {
+			return "_", nil
+		}
+		return "_|_", nil
+	case map[string]interface{}:
+		parts := []string{}
+		if ref, ok := t["$ref"].(string); ok {
+			name, ok := c.names[ref]
+			if !ok {
+				return "", fmt.Errorf("%s/$ref: reference %q isn't to a top-level definition", ptr, ref)
+			}
+			c.rootRef = c.rootRef || ref == "#"
+			parts = append(parts, name)
+		}
+		if val, ok := t["const"]; ok {
+			parts = append(parts, cueLiteral(val))
+		}
+		if enum, ok := t["enum"].([]interface{}); ok {
+			vals := make([]string, len(enum))
+			for i, val := range enum {
+				vals[i] = cueLiteral(val)
+			}
+			parts = append(parts, strings.Join(vals, " | "))
+		}
+		for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+			subs, ok := t[key].([]interface{})
+			if !ok {
+				continue
+			}
+			exprs := make([]string, len(subs))
+			for i, sub := range subs {
+				expr, err := c.expr(sub, fmt.Sprintf("%s/%s/%d", ptr, key, i), indent)
+				if err != nil {
+					return "", err
+				}
+				exprs[i] = expr
+			}
+			if key == "allOf" {
+				for _, expr := range exprs {
+					parts = append(parts, cueParens(expr))
+				}
+				continue
+			}
+			parts = append(parts, strings.Join(exprs, " | "))
+		}
+
+		typed, err := c.typed(t, ptr, indent)
+		if err != nil {
+			return "", err
+		}
+		if typed != "" {
+			parts = append(parts, typed)
+		}
+		for _, key := range sortedMapKeys(t) {
+			switch key {
+			case "$ref", "const", "enum", "allOf", "anyOf", "oneOf", "type", "default":
+			default:
+				if !cueAnnotations[key] && keywordTypes[key] == "" {
+					return "", fmt.Errorf("%s/%s: keyword %q has no CUE equivalent", ptr, EscapePointerToken(key), key)
+				}
+			}
+		}
+
+		expr := "_"
+		if len(parts) == 1 {
+			expr = parts[0]
+		} else if len(parts) > 1 {
+			for i, part := range parts {
+				parts[i] = cueParens(part)
+			}
+			expr = strings.Join(parts, " & ")
+		}
+		if val, ok := t["default"]; ok {
+			expr = "*" + cueLiteral(val) + " | " + expr
+		}
+		return expr, nil
+	}
+	return "", fmt.Errorf("%s: expected a schema", ptr)
+}
+
+// typed gives the disjunction of the types schema t allows, each with the
+// keywords that apply to it, or "" if t doesn't constrain types
+func (c *cueConverter) typed(t map[string]interface{}, ptr, indent string) (string, error) {
+	types := typeNames(t["type"])
+	if _, ok := t["type"]; !ok {
+		for _, key := range sortedMapKeys(t) {
+			if kt := keywordTypes[key]; kt != "" && !containsType(types, kt) {
+				types = append(types, kt)
+			}
+		}
+		if t["format"] == "date-time" && !containsType(types, "string") {
+			types = append(types, "string")
+		}
+	}
+	number := false
+	for _, typ := range types {
+		number = number || typ == "number"
+	}
+
+	alts := []string{}
+	for _, typ := range types {
+		if typ == "integer" && number {
+			// number covers integers
+			continue
+		}
+		conj := []string{}
+		switch typ {
+		case "null":
+			conj = append(conj, "null")
+		case "boolean":
+			conj = append(conj, "bool")
+		case "integer", "number":
+			conj = append(conj, map[string]string{"integer": "int", "number": "number"}[typ])
+			bounds := []struct{ key, op string }{
+				{"minimum", ">="}, {"exclusiveMinimum", ">"}, {"maximum", "<="}, {"exclusiveMaximum", "<"},
+			}
+			for _, b := range bounds {
+				if n, ok := t[b.key]; ok {
+					conj = append(conj, b.op+cueLiteral(n))
+				}
+			}
+			if n, ok := t["multipleOf"]; ok {
+				c.imports["math"] = true
+				conj = append(conj, "math.MultipleOf("+cueLiteral(n)+")")
+			}
+		case "string":
+			conj = append(conj, "string")
+			if t["format"] == "date-time" {
+				c.imports["time"] = true
+				conj = append(conj, "time.Time")
+			}
+			if n, ok := t["minLength"]; ok {
+				c.imports["strings"] = true
+				conj = append(conj, "strings.MinRunes("+cueLiteral(n)+")")
+			}
+			if n, ok := t["maxLength"]; ok {
+				c.imports["strings"] = true
+				conj = append(conj, "strings.MaxRunes("+cueLiteral(n)+")")
+			}
+			if p, ok := t["pattern"]; ok {
+				conj = append(conj, "=~"+cueLiteral(p))
+			}
+		case "array":
+			list, err := c.list(t, ptr, indent)
+			if err != nil {
+				return "", err
+			}
+			conj = append(conj, list...)
+		case "object":
+			st, err := c.structure(t, ptr, indent)
+			if err != nil {
+				return "", err
+			}
+			conj = append(conj, st...)
+		}
+		alts = append(alts, strings.Join(conj, " & "))
+	}
+	return strings.Join(alts, " | "), nil
+}
+
+// list gives the conjuncts of an array schema
+func (c *cueConverter) list(t map[string]interface{}, ptr, indent string) ([]string, error) {
+	for _, key := range []string{"contains", "additionalItems"} {
+		if _, ok := t[key]; ok {
+			return nil, fmt.Errorf("%s/%s: keyword %q has no CUE equivalent", ptr, key, key)
+		}
+	}
+	conj := []string{"[...]"}
+	switch items := t["items"].(type) {
+	case nil:
+	case []interface{}:
+		return nil, fmt.Errorf("%s/items: tuple items have no CUE equivalent", ptr)
+	default:
+		expr, err := c.expr(items, ptr+"/items", indent)
+		if err != nil {
+			return nil, err
+		}
+		if expr != "_" {
+			conj[0] = "[..." + cueParens(expr) + "]"
+		}
+	}
+	if n, ok := t["minItems"]; ok {
+		c.imports["list"] = true
+		conj = append(conj, "list.MinItems("+cueLiteral(n)+")")
+	}
+	if n, ok := t["maxItems"]; ok {
+		c.imports["list"] = true
+		conj = append(conj, "list.MaxItems("+cueLiteral(n)+")")
+	}
+	if unique, _ := t["uniqueItems"].(bool); unique {
+		c.imports["list"] = true
+		conj = append(conj, "list.UniqueItems()")
+	}
+	return conj, nil
+}
+
+// structure gives the conjuncts of an object schema
+func (c *cueConverter) structure(t map[string]interface{}, ptr, indent string) ([]string, error) {
+	for _, key := range []string{"dependencies", "propertyNames"} {
+		if _, ok := t[key]; ok {
+			return nil, fmt.Errorf("%s/%s: keyword %q has no CUE equivalent", ptr, key, key)
+		}
+	}
+	inner := indent + "\t"
+	lines := []string{}
+	props, _ := t["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := t["required"].([]interface{}); ok {
+		for _, name := range req {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	names := sortedMapKeys(props)
+	for name := range required {
+		if _, ok := props[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		marker := "?"
+		if required[name] {
+			marker = "!"
+		}
+		expr := "_"
+		if sub, ok := props[name]; ok {
+			var err error
+			if expr, err = c.expr(sub, ptr+"/properties/"+EscapePointerToken(name), inner); err != nil {
+				return nil, err
+			}
+			lines = append(lines, cueComment(sub, inner)+inner+cueLabel(name)+marker+": "+expr)
+			continue
+		}
+		lines = append(lines, inner+cueLabel(name)+marker+": "+expr)
+	}
+
+	patterns, _ := t["patternProperties"].(map[string]interface{})
+	for _, pattern := range sortedMapKeys(patterns) {
+		expr, err := c.expr(patterns[pattern], ptr+"/patternProperties/"+EscapePointerToken(pattern), inner)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, inner+"[=~"+cueLiteral(pattern)+"]: "+expr)
+	}
+
+	switch add := t["additionalProperties"].(type) {
+	case nil:
+		lines = append(lines, inner+"...")
+	case bool:
+		if add {
+			lines = append(lines, inner+"...")
+		}
+	default:
+		expr, err := c.expr(add, ptr+"/additionalProperties", inner)
+		if err != nil {
+			return nil, err
+		}
+		// match labels other than those of properties and patternProperties
+		others := []string{}
+		if len(props) > 0 {
+			quoted := make([]string, 0, len(props))
+			for _, name := range sortedMapKeys(props) {
+				quoted = append(quoted, regexp.QuoteMeta(name))
+			}
+			others = append(others, "^(?:"+strings.Join(quoted, "|")+")$")
+		}
+		others = append(others, sortedMapKeys(patterns)...)
+		label := "[string]"
+		if len(others) > 0 {
+			label = "[!~" + cueLiteral(strings.Join(others, "|")) + "]"
+		}
+		lines = append(lines, inner+label+": "+expr)
+	}
+
+	st := "{}"
+	if len(lines) > 0 {
+		st = "{\n" + strings.Join(lines, "\n") + "\n" + indent + "}"
+	}
+	conj := []string{st}
+	if n, ok := t["minProperties"]; ok {
+		c.imports["struct"] = true
+		conj = append(conj, "struct.MinFields("+cueLiteral(n)+")")
+	}
+	if n, ok := t["maxProperties"]; ok {
+		c.imports["struct"] = true
+		conj = append(conj, "struct.MaxFields("+cueLiteral(n)+")")
+	}
+	return conj, nil
+}
+
+// cueLiteral writes a JSON value as a CUE literal, which JSON is a subset of
+func cueLiteral(v interface{}) string {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// cueLabel quotes name if CUE wouldn't take it as a regular field label
+func cueLabel(name string) string {
+	if cueIdentifier.MatchString(name) && !cueKeywords[name] {
+		return name
+	}
+	return cueLiteral(name)
+}
+
+// cueParens wraps disjunctions in parentheses, so they can be conjoined
+func cueParens(expr string) string {
+	depth := 0
+	quoted := false
+	for i := 0; i < len(expr); i++ {
+		switch ch := expr[i]; {
+		case quoted && ch == '\\':
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		case depth == 0 && strings.HasPrefix(expr[i:], " | "):
+			return "(" + expr + ")"
+		}
+	}
+	return expr
+}
+
+// cueComment gives the title and description of schema v as a comment
+func cueComment(v interface{}, indent string) string {
+	m, _ := v.(map[string]interface{})
+	text := []string{}
+	for _, key := range []string{"title", "description"} {
+		if s, ok := m[key].(string); ok && strings.TrimSpace(s) != "" {
+			text = append(text, s)
+		}
+	}
+	if len(text) == 0 {
+		return ""
+	}
+	return goComment(strings.Join(text, "\n"), indent)
+}
@@ -0,0 +1,63 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestGenerateCUE(t *testing.T) {
+	rs := Must(`{
+		"title": "pet store",
+		"description": "a store of pets",
+		"type": "object",
+		"properties": {
+			"id": { "type": "integer", "minimum": 1 },
+			"name": { "type": "string", "description": "display name", "minLength": 1, "pattern": "^\\w+$" },
+			"nickname": { "type": ["string", "null"] },
+			"status": { "enum": ["available", "sold"], "default": "available" },
+			"pet": { "$ref": "#/definitions/pet" },
+			"tags": { "type": "array", "items": { "type": "string" }, "uniqueItems": true, "maxItems": 5 },
+			"updated": { "type": "string", "format": "date-time" },
+			"price": { "type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01 },
+			"extra": { "type": "object", "additionalProperties": { "type": "number" }, "minProperties": 1 },
+			"owner-id": { "const": "x" },
+			"parent": { "$ref": "#" }
+		},
+		"required": ["id", "name", "pet", "kind"],
+		"patternProperties": { "^x-": { "type": "string" } },
+		"additionalProperties": false,
+		"definitions": {
+			"pet": { "oneOf": [ { "$ref": "#/definitions/cat" }, { "$ref": "#/definitions/dog" } ] },
+			"cat": { "type": "object", "properties": { "lives": { "type": "integer", "maximum": 9 } } },
+			"dog": { "allOf": [ { "$ref": "#/definitions/cat" }, { "properties": { "good": { "type": "boolean" } }, "additionalProperties": { "type": "string" } } ] }
+		}
+	}`)
+
+	got, err := GenerateCUE(rs, CUEOptions{Package: "store"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "// Code generated by jsonschema. DO NOT EDIT.\n\npackage store\n\nimport (\n\t\"list\"\n\t\"math\"\n\t\"strings\"\n\t\"struct\"\n\t\"time\"\n)\n\n// pet store\n// a store of pets\n#PetStore: {\n\textra?: {\n\t\t[string]: number\n\t} & struct.MinFields(1)\n\tid!: int & >=1\n\tkind!: _\n\t// display name\n\tname!: string & strings.MinRunes(1) & =~\"^\\\\w+$\"\n\tnickname?: string | null\n\t\"owner-id\"?: \"x\"\n\tparent?: #PetStore\n\tpet!: #Pet\n\tprice?: number & >0 & math.MultipleOf(0.01)\n\tstatus?: *\"available\" | \"available\" | \"sold\"\n\ttags?: [...string] & list.MaxItems(5) & list.UniqueItems()\n\tupdated?: string & time.Time\n\t[=~\"^x-\"]: string\n}\n\n#Cat: {\n\tlives?: int & <=9\n\t...\n}\n\n#Dog: #Cat & {\n\tgood?: bool\n\t[!~\"^(?:good)$\"]: string\n}\n\n#Pet: #Cat | #Dog\n"
+	if string(got) != expect {
+		t.Errorf("output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateCUE(Must(`{"anyOf": [{"type": "integer"}, {"minLength": 2}], "allOf": [{"enum": [1, "ab", "abc"]}]}`), CUEOptions{RootName: "Value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "// Code generated by jsonschema. DO NOT EDIT.\n\npackage schema\n\nimport \"strings\"\n\n#Value: (1 | \"ab\" | \"abc\") & (int | string & strings.MinRunes(2))\n"
+	if string(got) != expect {
+		t.Errorf("disjunction output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	errCases := []string{
+		`{"not": {"type": "string"}}`,
+		`{"type": "array", "items": [{"type": "string"}]}`,
+		`{"properties": {"a": {"$ref": "#/properties/b"}, "b": {"type": "string"}}}`,
+	}
+	for i, c := range errCases {
+		if got, err := GenerateCUE(Must(c), CUEOptions{}); err == nil {
+			t.Errorf("case %d: expected an error, got:\n%s", i, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DataKeywords are the keywords whose values may be given as $data
+// references, {"$data": pointer}, as ajv allows
+var DataKeywords = map[string]bool{
+	"const":            true,
+	"enum":             true,
+	"maximum":          true,
+	"minimum":          true,
+	"exclusiveMaximum": true,
+	"exclusiveMinimum": true,
+	"maxLength":        true,
+	"minLength":        true,
+	"maxItems":         true,
+	"minItems":         true,
+	"maxProperties":    true,
+	"minProperties":    true,
+	"multipleOf":       true,
+	"pattern":          true,
+	"required":         true,
+	"uniqueItems":      true,
+}
+
+// DataRef is a keyword whose value is taken from the instance being
+// validated, written as {"$data": pointer}. The pointer is a Relative JSON
+// Pointer from the location the keyword checks, such as "1/budget", or a
+// JSON pointer from the root of the instance. A keyword whose value is
+// missing from the instance is ignored
+type DataRef struct {
+	Keyword string
+	Pointer string
+
+	rel  *RelativePointer
+	abs  Pointer
+	make ValMaker
+}
+
+// newDataRef gives the DataRef rawmsg holds for the value of keyword, if
+// it's a $data reference
+func newDataRef(keyword string, rawmsg json.RawMessage) (*DataRef, bool, error) {
+	mk, ok := DefaultValidators[keyword]
+	if !ok || !DataKeywords[keyword] {
+		return nil, false, nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(rawmsg, &obj); err != nil || len(obj) != 1 {
+		return nil, false, nil
+	}
+	ptr, ok := obj["$data"].(string)
+	if !ok {
+		return nil, false, nil
+	}
+
+	d := &DataRef{Keyword: keyword, Pointer: ptr, make: mk}
+	var err error
+	if ptr == "" || ptr[0] == '/' {
+		d.abs, err = ParsePointer(ptr)
+	} else {
+		d.rel, err = ParseRelativePointer(ptr)
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid $data pointer %q for %s: %s", ptr, keyword, err.Error())
+	}
+	return d, true, nil
+}
+
+// Validate implements the Validator interface for DataRef
+func (d *DataRef) Validate(propPath string, data interface{}, errs *[]ValError) {
+	d.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (d *DataRef) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
+	if !vd.whole {
+		AddError(errs, propPath, data, fmt.Sprintf("$data pointer %q for %s needs the whole instance", d.Pointer, d.Keyword))
+		return
+	}
+	root := vd.root
+
+	var val interface{}
+	var err error
+	if d.rel != nil {
+		val, err = d.rel.Eval(root, propPath)
+	} else {
+		val, err = d.abs.Eval(root)
+	}
+	if err != nil {
+		return
+	}
+
+	v := d.make()
+	raw, err := json.Marshal(val)
+	if err == nil {
+		err = json.Unmarshal(raw, v)
+	}
+	if err != nil {
+		AddError(errs, propPath, data, fmt.Sprintf("$data pointer %q gives an invalid %s: %s", d.Pointer, d.Keyword, InvalidValueString(val)))
+		return
+	}
+	v.Validate(propPath, data, errs)
+}
+
+// MarshalJSON implements the json.Marshaler interface for DataRef
+func (d DataRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"$data": d.Pointer})
+}
+
+// bindData records whether rs uses $data references
+func (rs *RootSchema) bindData() {
+	rs.data = false
+	rs.Schema.Walk(func(_ string, s *Schema) error {
+		for _, v := range s.Validators {
+			if _, ok := v.(*DataRef); ok {
+				rs.data = true
+			}
+		}
+		return nil
+	})
+}
+
+// Validate uses the root schema to check an instance, as Schema.Validate
+// does
+func (rs *RootSchema) Validate(propPath string, data interface{}, errs *[]ValError) {
+	if rs.memo != nil && propPath == "/" {
+		defer rs.memo.reset()
+	}
+	rs.Schema.Validate(propPath, data, errs)
+}
+
+func (rs *RootSchema) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
+	rs.Schema.validateIn(vd, propPath, data, errs)
+}
+
+// validation is the state of a single validation of an instance, which
+// schemas pass on to the subschemas they apply
+type validation struct {
+	// root is the whole instance, which $data pointers are resolved
+	// against, when whole is set
+	root  interface{}
+	whole bool
+	// opts are the options of the schema validation started from
+	opts *Options
+}
+
+// newValidation starts validating data at propPath. The whole instance is
+// known when validation starts from its root
+func newValidation(propPath string, data interface{}) *validation {
+	if propPath == "/" {
+		return &validation{root: data, whole: true}
+	}
+	return &validation{}
+}
+
+// options gives the options of the validation, the defaults if the schema
+// it started from has none
+func (vd *validation) options() *Options {
+	if vd.opts == nil {
+		return defaultOptions
+	}
+	return vd.opts
+}
+
+// validatorIn is implemented by validators that apply subschemas, or need
+// the whole instance, to validate as part of a validation under way
+type validatorIn interface {
+	validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError)
+}
+
+// validate validates data at propPath with v as part of vd
+func (vd *validation) validate(v Validator, propPath string, data interface{}, errs *[]ValError) {
+	if in, ok := v.(validatorIn); ok {
+		in.validateIn(vd, propPath, data, errs)
+		return
+	}
+	v.Validate(propPath, data, errs)
+}
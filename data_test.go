@@ -0,0 +1,151 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDataRef(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"budget": {"type": "number"},
+			"cost": {"type": "number", "maximum": {"$data": "1/budget"}},
+			"confirm": {"const": {"$data": "/password"}},
+			"password": {"type": "string"},
+			"sizes": {
+				"type": "array",
+				"items": {"enum": {"$data": "/allowed"}}
+			},
+			"allowed": {"type": "array"},
+			"limits": {
+				"type": "object",
+				"properties": {"min": {"type": "number"}, "max": {"minimum": {"$data": "1/min"}}}
+			}
+		},
+		"required": {"$data": "/mandatory"}
+	}`)
+
+	cases := []struct {
+		doc    string
+		expect []string
+	}{
+		{`{"budget": 10, "cost": 5}`, nil},
+		{`{"budget": 10, "cost": 15}`, []string{"/cost"}},
+		{`{"cost": 15}`, nil},
+		{`{"password": "a", "confirm": "a"}`, nil},
+		{`{"password": "a", "confirm": "b"}`, []string{"/confirm"}},
+		{`{"allowed": ["s", "m"], "sizes": ["s", "l", "m"]}`, []string{"/sizes/1"}},
+		{`{"limits": {"min": 3, "max": 2}}`, []string{"/limits/max"}},
+		{`{"mandatory": ["id"]}`, []string{"/"}},
+		{`{"budget": 10, "cost": 15, "mandatory": ["budget"]}`, []string{"/cost"}},
+		{`{"budget": "ten", "cost": 15}`, []string{"/budget", "/cost"}},
+	}
+	for _, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.PropertyPath)
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != strings.Join(c.expect, " ") {
+			t.Errorf("%s: expected errors at %v, got %v", c.doc, c.expect, errs)
+		}
+	}
+}
+
+func TestDataRefDecode(t *testing.T) {
+	rs := Must(`{"properties": {"a": {"maximum": {"$data": "1/b"}}, "c": {"enum": [{"$data": "x"}]}}}`)
+	data, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"maximum":{"$data":"1/b"}`) {
+		t.Errorf("expected the $data reference to be encoded, got %s", data)
+	}
+
+	// enum values that merely hold "$data" aren't references
+	errs, _ := rs.ValidateBytes([]byte(`{"c": {"$data": "x"}}`))
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	err = json.Unmarshal([]byte(`{"maximum": {"$data": "01/b"}}`), &RootSchema{})
+	if err == nil || !strings.Contains(err.Error(), `invalid $data pointer "01/b" for maximum`) {
+		t.Errorf("expected an invalid pointer error, got %v", err)
+	}
+}
+
+func TestDataRefClone(t *testing.T) {
+	rs := Must(`{"properties": {"cost": {"maximum": {"$data": "1/budget"}}}}`)
+	cp := rs.Clone()
+	errs, err := cp.ValidateBytes([]byte(`{"budget": 1, "cost": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected the clone to resolve $data references, got %v", errs)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(budget float64) {
+			defer wg.Done()
+			errs := []ValError{}
+			rs.Validate("/", map[string]interface{}{"budget": budget, "cost": 4.0}, &errs)
+			if (budget < 4) != (len(errs) == 1) {
+				t.Errorf("budget %v: unexpected errors %v", budget, errs)
+			}
+		}(float64(i))
+	}
+	wg.Wait()
+}
+
+func TestDataRefInvalidValue(t *testing.T) {
+	rs := Must(`{"properties": {"cost": {"maximum": {"$data": "1/budget"}}}}`)
+	errs, _ := rs.ValidateBytes([]byte(`{"budget": "lots", "cost": 2}`))
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, `$data pointer "1/budget" gives an invalid maximum`) {
+		t.Errorf("expected an invalid value error, got %v", errs)
+	}
+}
+
+func TestDataRefWithoutRootSchema(t *testing.T) {
+	rs := Must(`{"properties": {"cost": {"maximum": {"$data": "1/budget"}}}}`)
+	for _, budget := range []float64{1, 3} {
+		doc := map[string]interface{}{"budget": budget, "cost": 2.0}
+		expect := 0
+		if budget < 2 {
+			expect = 1
+		}
+		errs := []ValError{}
+		rs.Schema.Validate("/", doc, &errs)
+		if len(errs) != expect {
+			t.Errorf("budget %v: expected Schema.Validate to resolve $data references, got %v", budget, errs)
+		}
+		if tr := rs.ValidateTrace(doc); len(tr.Failures()) != expect {
+			t.Errorf("budget %v: expected the trace to resolve $data references, got\n%s", budget, tr)
+		}
+	}
+
+	const uri = "http://example.com/order.json"
+	DefaultSchemaPool[uri] = &Must(`{"properties": {"cost": {"maximum": {"$data": "/limit"}}}}`).Schema
+	defer delete(DefaultSchemaPool, uri)
+	outer := Must(`{"properties": {"order": {"$ref": "http://example.com/order.json"}}}`)
+	if err := outer.FetchRemoteReferences(); err != nil {
+		t.Fatal(err)
+	}
+	for _, limit := range []float64{1, 3} {
+		errs := []ValError{}
+		outer.Validate("/", map[string]interface{}{"limit": limit, "order": map[string]interface{}{"cost": 2.0}}, &errs)
+		if (limit < 2) != (len(errs) == 1) || len(errs) > 1 {
+			t.Errorf("limit %v: expected a referenced root to resolve $data references against the instance, got %v", limit, errs)
+		}
+	}
+}
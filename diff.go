@@ -0,0 +1,560 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ChangeKind says whether a keyword or subschema was added, removed or changed
+type ChangeKind int
+
+const (
+	// ChangeAdded marks a keyword or subschema present only in the new schema
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved marks a keyword or subschema present only in the old schema
+	ChangeRemoved
+	// ChangeModified marks a keyword or subschema with a different value
+	ChangeModified
+)
+
+// String implements the stringer interface for ChangeKind
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// SchemaChange is a single difference between two versions of a schema
+type SchemaChange struct {
+	// Pointer is the JSON pointer to the keyword or subschema that changed
+	Pointer string `json:"pointer"`
+	// Kind is the type of change
+	Kind ChangeKind `json:"kind"`
+	// Old is the json-decoded value in the old schema, nil when added
+	Old interface{} `json:"old,omitempty"`
+	// New is the json-decoded value in the new schema, nil when removed
+	New interface{} `json:"new,omitempty"`
+	// Breaking is true when the change can make instances that were valid
+	// against the old schema invalid against the new one
+	Breaking bool `json:"breaking"`
+}
+
+// String implements the stringer interface for SchemaChange
+func (c SchemaChange) String() string {
+	str := ""
+	switch c.Kind {
+	case ChangeAdded:
+		str = fmt.Sprintf("added %s: %s", c.Pointer, InvalidValueString(c.New))
+	case ChangeRemoved:
+		str = fmt.Sprintf("removed %s: %s", c.Pointer, InvalidValueString(c.Old))
+	default:
+		str = fmt.Sprintf("changed %s: %s -> %s", c.Pointer, InvalidValueString(c.Old), InvalidValueString(c.New))
+	}
+	if c.Breaking {
+		str += " (breaking)"
+	}
+	return str
+}
+
+// annotationKeywords don't affect validation
+var annotationKeywords = map[string]bool{
+	"$schema":     true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"readOnly":    true,
+	"writeOnly":   true,
+	"definitions": true,
+	"$defs":       true,
+}
+
+// Diff lists the keyword-level differences between two versions of a
+// schema, ordered by pointer. Each change is classified as breaking when it
+// can make instances that were valid against old invalid against new.
+// Classification is conservative: changes that can't be proven safe, like
+// an edited pattern, are marked as breaking. Loosening a schema under "not"
+// is breaking, as is any change to an "if" condition or a oneOf branch.
+// Definitions take the polarity of the references reaching them, and
+// changes to definitions reached from several polarities, or from none, are
+// breaking either way, as is removing a definition that's still referenced
+func Diff(old, new *RootSchema) []SchemaChange {
+	changes := []SchemaChange{}
+	if old.SchemaURI != new.SchemaURI {
+		changes = append(changes, keywordChange("/$schema", "$schema", stringValue(old.SchemaURI), stringValue(new.SchemaURI), false))
+	}
+	// compare canonical copies so reordering sets like "required" isn't a change
+	a, b := old.Clone(), new.Clone()
+	canonicalize(&a.Schema)
+	canonicalize(&b.Schema)
+	uses := &definitionUses{reached: map[string]map[polarity]bool{}, refs: map[string]bool{}}
+	uses.reach(a)
+	uses.reach(b)
+	b.Schema.Walk(func(_ string, s *Schema) error {
+		if s.Ref != "" {
+			uses.refs[s.Ref] = true
+		}
+		return nil
+	})
+	diffSchemas("", &a.Schema, &b.Schema, positive, uses, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Pointer < changes[j].Pointer })
+	return changes
+}
+
+// polarity says how a change to a subschema bears on the instances the
+// whole schema accepts
+type polarity int
+
+const (
+	// positive subschemas reject more instances as they tighten
+	positive polarity = iota
+	// negative subschemas, under "not", reject more as they loosen
+	negative
+	// conditional subschemas, like "if" conditions, reject more either way
+	conditional
+)
+
+// breaking picks whether a change is breaking under p, given whether it
+// tightens and whether it loosens the subschema it's made to
+func (p polarity) breaking(tightens, loosens bool) bool {
+	switch p {
+	case negative:
+		return loosens
+	case conditional:
+		return tightens || loosens
+	}
+	return tightens
+}
+
+// within gives the polarity of the subschemas of keyword in a schema of
+// polarity p
+func (p polarity) within(keyword string) polarity {
+	switch {
+	case p == conditional || keyword == "if" || keyword == "oneOf":
+		// loosening a oneOf branch can make instances match a second one
+		return conditional
+	case keyword == "not" && p == negative:
+		return positive
+	case keyword == "not":
+		return negative
+	}
+	return p
+}
+
+// definitionUses are how the definitions of two versions of a schema are
+// used. reached holds the polarities of the references reaching each
+// schema, by pointer, and refs the references of the new version
+type definitionUses struct {
+	reached map[string]map[polarity]bool
+	refs    map[string]bool
+}
+
+// reach records the polarities the references of rs reach schemas with,
+// following references from the root as validation does
+func (u *definitionUses) reach(rs *RootSchema) {
+	ptrs := map[*Schema]string{}
+	rs.Schema.Walk(func(ptr string, s *Schema) error {
+		ptrs[s] = ptr
+		return nil
+	})
+	seen := map[*Schema]map[polarity]bool{}
+	var visit func(s *Schema, pol polarity)
+	visit = func(s *Schema, pol polarity) {
+		if s == nil || seen[s][pol] {
+			return
+		}
+		if seen[s] == nil {
+			seen[s] = map[polarity]bool{}
+		}
+		seen[s][pol] = true
+		if s.Ref != "" {
+			target := refTarget(s)
+			if ptr, ok := ptrs[target]; ok {
+				if u.reached[ptr] == nil {
+					u.reached[ptr] = map[polarity]bool{}
+				}
+				u.reached[ptr][pol] = true
+			}
+			visit(target, pol)
+			return
+		}
+		for key, v := range applicators(s) {
+			for _, sub := range subschemaMap(v) {
+				visit(sub, pol.within(key))
+			}
+		}
+	}
+	visit(&rs.Schema, positive)
+}
+
+// polarity gives the polarity of the definition at ptr: that of the
+// references reaching it, conditional if they reach it with several
+// polarities or there are none
+func (u *definitionUses) polarity(ptr string) polarity {
+	if pols := u.reached[ptr]; len(pols) == 1 {
+		for pol := range pols {
+			return pol
+		}
+	}
+	return conditional
+}
+
+func diffSchemas(ptr string, a, b *Schema, pol polarity, uses *definitionUses, changes *[]SchemaChange) {
+	if a.schemaType != schemaTypeObject || b.schemaType != schemaTypeObject {
+		if a.schemaType != b.schemaType {
+			*changes = append(*changes, SchemaChange{
+				Pointer:  ptr,
+				Kind:     ChangeModified,
+				Old:      schemaValue(a),
+				New:      schemaValue(b),
+				Breaking: pol.breaking(schemaTypeBreaking(a, b), schemaTypeBreaking(b, a)),
+			})
+		}
+		return
+	}
+
+	av, bv := shallowKeywords(a), shallowKeywords(b)
+	for _, key := range unionKeys(av, bv) {
+		kptr := ptr + "/" + EscapePointerToken(key)
+		x, inA := av[key]
+		y, inB := bv[key]
+		ignored := annotationKeywords[key] || a.extraKeywords[key] != nil || b.extraKeywords[key] != nil ||
+			a.extraDefinitions[key] != nil || b.extraDefinitions[key] != nil
+		switch {
+		case !inA:
+			*changes = append(*changes, SchemaChange{Pointer: kptr, Kind: ChangeAdded, New: y, Breaking: pol.breaking(!ignored, false)})
+		case !inB:
+			*changes = append(*changes, SchemaChange{Pointer: kptr, Kind: ChangeRemoved, Old: x, Breaking: pol.breaking(false, !ignored)})
+		case !reflect.DeepEqual(x, y):
+			c := keywordChange(kptr, key, x, y, !ignored)
+			c.Breaking = pol.breaking(c.Breaking, keywordChange(kptr, key, y, x, !ignored).Breaking)
+			*changes = append(*changes, c)
+		}
+	}
+
+	diffDefinitions(ptr+"/$defs", a.Defs, b.Defs, uses, changes)
+	diffDefinitions(ptr+"/definitions", a.Definitions, b.Definitions, uses, changes)
+
+	for _, key := range unionKeys(applicators(a), applicators(b)) {
+		kptr := ptr + "/" + EscapePointerToken(key)
+		x, y := a.Validators[key], b.Validators[key]
+		switch {
+		case x == nil:
+			breaking := pol.breaking(true, removedApplicatorBreaking(key, a))
+			*changes = append(*changes, SchemaChange{Pointer: kptr, Kind: ChangeAdded, New: keywordValue(y), Breaking: breaking})
+			continue
+		case y == nil:
+			breaking := pol.breaking(removedApplicatorBreaking(key, b), true)
+			*changes = append(*changes, SchemaChange{Pointer: kptr, Kind: ChangeRemoved, Old: keywordValue(x), Breaking: breaking})
+			continue
+		}
+
+		xs, ys := subschemaMap(x), subschemaMap(y)
+		if _, single := xs[""]; single != (ys[""] != nil) {
+			// a keyword changed between a single schema and a set of schemas
+			*changes = append(*changes, SchemaChange{Pointer: kptr, Kind: ChangeModified, Old: keywordValue(x), New: keywordValue(y), Breaking: pol.breaking(true, true)})
+			continue
+		}
+		if dx, ok := x.(*Dependencies); ok {
+			diffPropertyDependencies(kptr, dx, y.(*Dependencies), pol, changes)
+		}
+
+		for _, tok := range unionSchemaKeys(xs, ys) {
+			sptr := kptr
+			if tok != "" {
+				sptr += "/" + EscapePointerToken(tok)
+			}
+			sx, sy := xs[tok], ys[tok]
+			switch {
+			case sx == nil:
+				breaking := pol.breaking(addedSubschemaBreaking(key, sy), removedSubschemaBreaking(key, a))
+				*changes = append(*changes, SchemaChange{Pointer: sptr, Kind: ChangeAdded, New: schemaValue(sy), Breaking: breaking})
+			case sy == nil:
+				breaking := pol.breaking(removedSubschemaBreaking(key, b), addedSubschemaBreaking(key, sx))
+				*changes = append(*changes, SchemaChange{Pointer: sptr, Kind: ChangeRemoved, Old: schemaValue(sx), Breaking: breaking})
+			default:
+				diffSchemas(sptr, sx, sy, pol.within(key), uses, changes)
+			}
+		}
+	}
+}
+
+func diffDefinitions(ptr string, a, b Definitions, uses *definitionUses, changes *[]SchemaChange) {
+	for _, key := range unionSchemaKeys(a, b) {
+		dptr := ptr + "/" + EscapePointerToken(key)
+		switch {
+		case a[key] == nil:
+			*changes = append(*changes, SchemaChange{Pointer: dptr, Kind: ChangeAdded, New: schemaValue(b[key])})
+		case b[key] == nil:
+			// references left pointing at the definition resolve to nothing
+			*changes = append(*changes, SchemaChange{Pointer: dptr, Kind: ChangeRemoved, Old: schemaValue(a[key]), Breaking: uses.refs["#"+dptr]})
+		default:
+			diffSchemas(dptr, a[key], b[key], uses.polarity(dptr), uses, changes)
+		}
+	}
+}
+
+// diffPropertyDependencies compares the property-list form of dependencies,
+// which isn't reached by comparing subschemas
+func diffPropertyDependencies(ptr string, a, b *Dependencies, pol polarity, changes *[]SchemaChange) {
+	keys := map[string]bool{}
+	for key, dep := range *a {
+		if dep.schema == nil {
+			keys[key] = true
+		}
+	}
+	for key, dep := range *b {
+		if dep.schema == nil {
+			keys[key] = true
+		}
+	}
+	for key := range keys {
+		dptr := ptr + "/" + EscapePointerToken(key)
+		x, y := (*a)[key], (*b)[key]
+		switch {
+		case x.schema == nil && y.schema == nil && x.props != nil && y.props != nil:
+			if !reflect.DeepEqual(uniqueStrings(x.props), uniqueStrings(y.props)) {
+				c := keywordChange(dptr, "required", stringsValue(x.props), stringsValue(y.props), true)
+				c.Breaking = pol.breaking(c.Breaking, keywordChange(dptr, "required", c.New, c.Old, true).Breaking)
+				*changes = append(*changes, c)
+			}
+		case x.props == nil && x.schema == nil:
+			*changes = append(*changes, SchemaChange{Pointer: dptr, Kind: ChangeAdded, New: keywordValue(y), Breaking: pol.breaking(true, false)})
+		case y.props == nil && y.schema == nil:
+			*changes = append(*changes, SchemaChange{Pointer: dptr, Kind: ChangeRemoved, Old: keywordValue(x), Breaking: pol.breaking(false, true)})
+		default:
+			*changes = append(*changes, SchemaChange{Pointer: dptr, Kind: ChangeModified, Old: keywordValue(x), New: keywordValue(y), Breaking: pol.breaking(true, true)})
+		}
+	}
+}
+
+// schemaTypeBreaking reports whether changing a boolean or empty schema a
+// into b can reject instances that used to be valid
+func schemaTypeBreaking(a, b *Schema) bool {
+	return b.schemaType == schemaTypeFalse || (a.schemaType == schemaTypeTrue && !Equal(b, &Schema{}))
+}
+
+// removedApplicatorBreaking reports whether removing keyword from parent,
+// leaving the rest of parent, can reject instances that used to be valid
+func removedApplicatorBreaking(keyword string, parent *Schema) bool {
+	// dropping the properties a remaining additionalProperties exempts
+	// makes those properties additional
+	return (keyword == "properties" || keyword == "patternProperties") && parent.Validators["additionalProperties"] != nil
+}
+
+// keywordChange builds a modification, deciding for well-known keywords
+// whether the new value is looser or tighter than the old one
+func keywordChange(ptr, key string, old, new interface{}, assertion bool) SchemaChange {
+	c := SchemaChange{Pointer: ptr, Kind: ChangeModified, Old: old, New: new}
+	if !assertion {
+		return c
+	}
+
+	x, xnum := old.(float64)
+	y, ynum := new.(float64)
+	switch key {
+	case "maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties":
+		c.Breaking = !xnum || !ynum || y < x
+	case "minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties":
+		c.Breaking = !xnum || !ynum || y > x
+	case "multipleOf":
+		c.Breaking = !xnum || !ynum || y == 0 || x/y != float64(int64(x/y))
+	case "uniqueItems":
+		c.Breaking = new == true
+	case "required":
+		c.Breaking = !isSubset(new, old)
+	case "enum":
+		c.Breaking = !isSubset(old, new)
+	case "type":
+		c.Breaking = !typesCovered(typeNames(old), typeNames(new))
+	default:
+		c.Breaking = true
+	}
+	return c
+}
+
+// addedSubschemaBreaking reports whether adding a schema within keyword can
+// reject instances that used to be valid
+func addedSubschemaBreaking(keyword string, sch *Schema) bool {
+	switch keyword {
+	case "anyOf":
+		return false
+	case "properties", "patternProperties", "dependencies", "items":
+		return !Equal(sch, &Schema{schemaType: schemaTypeTrue}) && !Equal(sch, &Schema{})
+	default:
+		return true
+	}
+}
+
+// removedSubschemaBreaking reports whether removing a schema within keyword
+// can reject instances that used to be valid
+func removedSubschemaBreaking(keyword string, parent *Schema) bool {
+	switch keyword {
+	case "anyOf", "oneOf":
+		return true
+	case "properties", "patternProperties":
+		return parent.Validators["additionalProperties"] != nil
+	default:
+		return false
+	}
+}
+
+// shallowKeywords gives the json-decoded values of every keyword of s that
+// doesn't hold subschemas
+func shallowKeywords(s *Schema) map[string]interface{} {
+	res := map[string]interface{}{}
+	for key, val := range s.jsonObject() {
+		if key == "definitions" || key == "$defs" {
+			continue
+		}
+		if v, ok := s.Validators[key]; ok && isApplicator(v) {
+			continue
+		}
+		res[key] = decodedValue(val)
+	}
+	return res
+}
+
+// applicators gives the keywords of s that hold subschemas
+func applicators(s *Schema) map[string]Validator {
+	res := map[string]Validator{}
+	for key, v := range s.Validators {
+		if isApplicator(v) {
+			res[key] = v
+		}
+	}
+	return res
+}
+
+func isApplicator(v Validator) bool {
+	switch v.(type) {
+	case *AllOf, *AnyOf, *OneOf, *Not, *Items, *AdditionalItems, *Contains, *Properties,
+		*PatternProperties, *AdditionalProperties, *Dependencies, *PropertyNames, *If, *Then, *Else:
+		return true
+	}
+	return false
+}
+
+func subschemaMap(v Validator) map[string]*Schema {
+	res := map[string]*Schema{}
+	for _, ch := range subschemas(v) {
+		if ch.sub != "" {
+			res[ch.token+"/"+ch.sub] = ch.schema
+			continue
+		}
+		res[ch.token] = ch.schema
+	}
+	return res
+}
+
+func unionKeys(a, b interface{}) []string {
+	keys := map[string]bool{}
+	for _, m := range []interface{}{a, b} {
+		for _, key := range reflect.ValueOf(m).MapKeys() {
+			keys[key.String()] = true
+		}
+	}
+	res := make([]string, 0, len(keys))
+	for key := range keys {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func unionSchemaKeys(a, b map[string]*Schema) []string {
+	return unionKeys(a, b)
+}
+
+func schemaValue(s *Schema) interface{} {
+	return decodedValue(s)
+}
+
+func keywordValue(v Validator) interface{} {
+	return decodedValue(v)
+}
+
+func stringValue(str string) interface{} {
+	if str == "" {
+		return nil
+	}
+	return str
+}
+
+func stringsValue(strs []string) interface{} {
+	return decodedValue(strs)
+}
+
+// decodedValue gives the standard json-decoded form of any encodable value
+func decodedValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var res interface{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil
+	}
+	return res
+}
+
+// isSubset reports whether every element of the json array a is in the json array b
+func isSubset(a, b interface{}) bool {
+	as, _ := a.([]interface{})
+	bs, _ := b.([]interface{})
+	for _, x := range as {
+		found := false
+		for _, y := range bs {
+			if reflect.DeepEqual(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// typeNames gives the type names of a decoded "type" keyword value
+func typeNames(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		res := []string{}
+		for _, name := range t {
+			if str, ok := name.(string); ok {
+				res = append(res, str)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// typesCovered reports whether every type in a is accepted by a type in b
+func typesCovered(a, b []string) bool {
+	for _, x := range a {
+		covered := false
+		for _, y := range b {
+			if x == y || (x == "integer" && y == "number") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		old, new string
+		expect   []string
+	}{
+		{`{ "type": "string" }`, `{ "type": "string", "title": "name" }`,
+			[]string{`added /title: "name"`}},
+		{`{ "required": ["a", "b"] }`, `{ "required": ["b", "a"] }`, []string{}},
+		{`{ "maxLength": 5 }`, `{ "maxLength": 3 }`,
+			[]string{`changed /maxLength: 5 -> 3 (breaking)`}},
+		{`{ "maxLength": 3 }`, `{ "maxLength": 5 }`,
+			[]string{`changed /maxLength: 3 -> 5`}},
+		{`{ "enum": [1, 2] }`, `{ "enum": [1, 2, 3] }`,
+			[]string{`changed /enum: [1,2] -> [1,2,3]`}},
+		{`{ "type": "integer" }`, `{ "type": ["number", "null"] }`,
+			[]string{`changed /type: "integer" -> ["null","number"]`}},
+		{`{ "required": ["a"] }`, `{ "required": ["a", "b"] }`,
+			[]string{`changed /required: ["a"] -> ["a","b"] (breaking)`}},
+		{`{ "properties": { "a": { "type": "string" } } }`,
+			`{ "properties": { "a": { "type": "string", "pattern": "^x" }, "b": {} } }`,
+			[]string{`added /properties/a/pattern: "^x" (breaking)`, `added /properties/b: {}`}},
+		{`{ "properties": { "a": {}, "b": {} }, "additionalProperties": false }`,
+			`{ "properties": { "a": {} }, "additionalProperties": false }`,
+			[]string{`removed /properties/b: {} (breaking)`}},
+		{`{ "anyOf": [{ "type": "string" }] }`, `{ "anyOf": [{ "type": "string" }, { "type": "null" }] }`,
+			[]string{`added /anyOf/1: {"type":"null"}`}},
+		{`{ "definitions": { "a": true } }`, `{ "definitions": { "a": false } }`,
+			[]string{`changed /definitions/a: true -> false (breaking)`}},
+		{`{ "items": { "type": "string" } }`, `{ "items": [{ "type": "string" }] }`,
+			[]string{`changed /items: {"type":"string"} -> [{"type":"string"}] (breaking)`}},
+		{`{ "dependencies": { "a": ["b"] } }`, `{ "dependencies": { "a": ["b", "c"] } }`,
+			[]string{`changed /dependencies/a: ["b"] -> ["b","c"] (breaking)`}},
+		{`{ "x-owner": "a", "minimum": 1 }`, `{ "x-owner": "b" }`,
+			[]string{`removed /minimum: 1`, `changed /x-owner: "a" -> "b"`}},
+		{`{ "not": { "type": "null" } }`, `{}`, []string{`removed /not: {"type":"null"}`}},
+		{`{ "not": { "type": "string" } }`, `{ "not": { "type": ["string", "null"] } }`,
+			[]string{`changed /not/type: "string" -> ["null","string"] (breaking)`}},
+		{`{ "not": { "type": ["string", "null"] } }`, `{ "not": { "type": "string" } }`,
+			[]string{`changed /not/type: ["null","string"] -> "string"`}},
+		{`{ "not": { "type": "string", "maxLength": 3 } }`, `{ "not": { "type": "string" } }`,
+			[]string{`removed /not/maxLength: 3 (breaking)`}},
+		{`{ "not": { "not": { "type": "string" } } }`, `{ "not": { "not": { "type": ["string", "null"] } } }`,
+			[]string{`changed /not/not/type: "string" -> ["null","string"]`}},
+		{`{ "if": { "minimum": 0 }, "then": { "multipleOf": 2 } }`, `{ "if": { "minimum": 1 }, "then": { "multipleOf": 2 } }`,
+			[]string{`changed /if/minimum: 0 -> 1 (breaking)`}},
+		{`{ "if": { "minimum": 1 }, "then": { "multipleOf": 2 } }`, `{ "if": { "minimum": 0, "title": "x" }, "then": { "multipleOf": 2 } }`,
+			[]string{`changed /if/minimum: 1 -> 0 (breaking)`, `added /if/title: "x"`}},
+		{`{ "oneOf": [{ "type": "string", "maxLength": 3 }, { "type": "integer" }] }`, `{ "oneOf": [{ "type": "string" }, { "type": "integer" }] }`,
+			[]string{`removed /oneOf/0/maxLength: 3 (breaking)`}},
+		{`{ "properties": { "x": { "not": { "$ref": "#/definitions/s" } } }, "definitions": { "s": { "type": "string" } } }`,
+			`{ "properties": { "x": { "not": { "$ref": "#/definitions/s" } } }, "definitions": { "s": { "type": "string", "minLength": 1 } } }`,
+			[]string{`added /definitions/s/minLength: 1`}},
+		{`{ "properties": { "x": { "$ref": "#/definitions/s" } }, "definitions": { "s": { "type": "string" } } }`,
+			`{ "properties": { "x": { "$ref": "#/definitions/s" } }, "definitions": { "s": { "type": "string", "minLength": 1 } } }`,
+			[]string{`added /definitions/s/minLength: 1 (breaking)`}},
+		{`{ "properties": { "x": { "$ref": "#/definitions/s" }, "y": { "not": { "$ref": "#/definitions/s" } } }, "definitions": { "s": { "minLength": 1 } } }`,
+			`{ "properties": { "x": { "$ref": "#/definitions/s" }, "y": { "not": { "$ref": "#/definitions/s" } } }, "definitions": { "s": {} } }`,
+			[]string{`removed /definitions/s/minLength: 1 (breaking)`}},
+		{`{ "definitions": { "s": { "minLength": 1 } } }`, `{ "definitions": { "s": {} } }`,
+			[]string{`removed /definitions/s/minLength: 1 (breaking)`}},
+		{`{ "properties": { "x": { "not": { "$ref": "#/definitions/a" } } }, "definitions": { "a": { "$ref": "#/definitions/b" }, "b": { "maxLength": 3 } } }`,
+			`{ "properties": { "x": { "not": { "$ref": "#/definitions/a" } } }, "definitions": { "a": { "$ref": "#/definitions/b" }, "b": {} } }`,
+			[]string{`removed /definitions/b/maxLength: 3 (breaking)`}},
+	}
+
+	for i, c := range cases {
+		got := Diff(Must(c.old), Must(c.new))
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d: expected %d changes, got: %v", i, len(c.expect), got)
+			continue
+		}
+		for j, change := range got {
+			if change.String() != c.expect[j] {
+				t.Errorf("case %d change %d: expected %s, got: %s", i, j, c.expect[j], change.String())
+			}
+		}
+	}
+}
+
+func TestDiffRemovedDefinition(t *testing.T) {
+	old := Must(`{ "properties": { "x": { "$ref": "#/definitions/s" } }, "definitions": { "s": { "type": "string" }, "t": {} } }`)
+	// definitions can't be removed from a parsed schema while references
+	// to them resolve, so drop them from a copy
+	new := old.Clone()
+	delete(new.Definitions, "s")
+	delete(new.Definitions, "t")
+	got := []string{}
+	for _, change := range Diff(old, new) {
+		got = append(got, change.String())
+	}
+	expect := []string{`removed /definitions/s: {"type":"string"} (breaking)`, `removed /definitions/t: {}`}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected changes %q, got %q", expect, got)
+	}
+}
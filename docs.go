@@ -0,0 +1,270 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// docFormat renders the inline parts of schema documentation in an output
+// format
+type docFormat interface {
+	// text escapes plain text
+	text(str string) string
+	// code formats str as inline code
+	code(str string) string
+	// link links already formatted text to href
+	link(text, href string) string
+}
+
+// docSection is a schema documented under its own heading: the root schema
+// or a top-level definition
+type docSection struct {
+	// name is the definition key, empty for the root schema
+	name string
+	// anchor identifies the section within the document
+	anchor string
+	schema *Schema
+}
+
+// docRow is a property of a section's schema, or a property or array item
+// nested within one. name is a path of property names, joined by "."
+// through objects and suffixed with "[]" through array items
+type docRow struct {
+	name     string
+	schema   *Schema
+	required bool
+}
+
+// docLink is where a schema is documented
+type docLink struct {
+	title string
+	href  string
+}
+
+// schemaDocs builds the text of schema documentation that's the same in
+// every output format. links maps documented schemas to their sections,
+// which may be on other pages. refs maps "$ref" values to sections, for
+// references that aren't resolved
+type schemaDocs struct {
+	f        docFormat
+	sections []docSection
+	links    map[*Schema]docLink
+	refs     map[string]docLink
+}
+
+func newSchemaDocs(rs *RootSchema, f docFormat) *schemaDocs {
+	d := &schemaDocs{
+		f:        f,
+		sections: []docSection{{anchor: "root", schema: &rs.Schema}},
+		links:    map[*Schema]docLink{},
+		refs:     map[string]docLink{},
+	}
+	d.links[&rs.Schema] = docLink{d.sections[0].title(), "#root"}
+	for _, defs := range []struct {
+		key  string
+		defs Definitions
+	}{{"$defs", rs.Defs}, {"definitions", rs.Definitions}} {
+		for _, name := range sortedDefinitionKeys(defs.defs) {
+			sch := defs.defs[name]
+			if _, ok := d.links[sch]; ok || sch == nil {
+				continue
+			}
+			sec := docSection{name: name, anchor: docAnchor(defs.key + "-" + name), schema: sch}
+			d.sections = append(d.sections, sec)
+			d.links[sch] = docLink{sec.title(), "#" + sec.anchor}
+			d.refs["#/"+defs.key+"/"+EscapePointerToken(name)] = d.links[sch]
+		}
+	}
+	return d
+}
+
+// title gives the heading of the section
+func (sec docSection) title() string {
+	switch {
+	case sec.name != "":
+		return sec.name
+	case sec.schema.Title != "":
+		return sec.schema.Title
+	}
+	return "Schema"
+}
+
+// rows lists the properties of s, expanding objects nested within them that
+// aren't references
+func (d *schemaDocs) rows(s *Schema) []docRow {
+	rows := []docRow{}
+	d.appendRows(&rows, "", s, 0)
+	return rows
+}
+
+func (d *schemaDocs) appendRows(rows *[]docRow, prefix string, s *Schema, depth int) {
+	props, ok := s.Validators["properties"].(*Properties)
+	if !ok || depth > maxSubsetDepth {
+		return
+	}
+	required := map[string]bool{}
+	if req, ok := s.Validators["required"].(*Required); ok {
+		for _, name := range *req {
+			required[name] = true
+		}
+	}
+	for _, name := range sortedPropertyKeys(*props) {
+		sch := (*props)[name]
+		*rows = append(*rows, docRow{name: prefix + name, schema: sch, required: required[name]})
+		if sch == nil || sch.Ref != "" {
+			continue
+		}
+		d.appendRows(rows, prefix+name+".", sch, depth+1)
+		items, ok := sch.Validators["items"].(*Items)
+		if !ok || !items.single || len(items.Schemas) != 1 || items.Schemas[0].Ref != "" {
+			continue
+		}
+		// items get a row of their own when there's more to say than their type
+		if item := items.Schemas[0]; item.Validators["properties"] != nil {
+			d.appendRows(rows, prefix+name+"[].", item, depth+1)
+		} else if item.Description != "" || len(d.constraints(item)) > 0 {
+			*rows = append(*rows, docRow{name: prefix + name + "[]", schema: item})
+		}
+	}
+}
+
+// typeText describes the type of instances s accepts, linking references
+// to the sections they point at
+func (d *schemaDocs) typeText(s *Schema) string {
+	switch {
+	case s == nil || isTrueSchema(s):
+		return d.f.text("any")
+	case isFalseSchema(s):
+		return d.f.text("nothing")
+	case s.Ref != "":
+		// references naming a section are trusted over how they resolved,
+		// as references to other documents may resolve within this one
+		link, ok := d.refs[strings.TrimPrefix(s.Ref, "./")]
+		if target, resolved := resolveSchema(s); !ok && resolved {
+			link, ok = d.links[target]
+		}
+		if !ok {
+			return d.f.code(s.Ref)
+		}
+		return d.f.link(d.f.text(link.title), link.href)
+	}
+
+	parts := []string{}
+	if types := impliedTypes(s); len(types) < len(allTypes) {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = d.f.text(t)
+			if t == "array" {
+				names[i] = d.arrayText(s)
+			}
+		}
+		parts = append(parts, strings.Join(names, d.f.text(" | ")))
+	}
+	for _, comb := range []struct {
+		key, label string
+	}{{"allOf", "all of"}, {"anyOf", "any of"}, {"oneOf", "one of"}} {
+		var branches []*Schema
+		switch t := s.Validators[comb.key].(type) {
+		case *AllOf:
+			branches = *t
+		case *AnyOf:
+			branches = *t
+		case *OneOf:
+			branches = *t
+		default:
+			continue
+		}
+		texts := make([]string, len(branches))
+		for i, branch := range branches {
+			texts[i] = d.typeText(branch)
+		}
+		parts = append(parts, d.f.text(comb.label+": ")+strings.Join(texts, d.f.text(", ")))
+	}
+	if len(parts) == 0 {
+		return d.f.text("any")
+	}
+	return strings.Join(parts, d.f.text("; "))
+}
+
+// arrayText describes an array type by its items
+func (d *schemaDocs) arrayText(s *Schema) string {
+	items, ok := s.Validators["items"].(*Items)
+	if !ok {
+		return d.f.text("array")
+	}
+	if items.single && len(items.Schemas) == 1 {
+		return d.f.text("array of ") + d.typeText(items.Schemas[0])
+	}
+	texts := make([]string, len(items.Schemas))
+	for i, item := range items.Schemas {
+		texts[i] = d.typeText(item)
+	}
+	return d.f.text("array [") + strings.Join(texts, d.f.text(", ")) + d.f.text("]")
+}
+
+// docStructuralKeywords are described by a schema's type and rows rather
+// than listed as constraints
+var docStructuralKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true,
+	"allOf": true, "anyOf": true, "oneOf": true,
+}
+
+// constraints lists the keywords of s that restrict instances beyond their
+// type, along with its format, default and examples
+func (d *schemaDocs) constraints(s *Schema) []string {
+	if s == nil || s.schemaType != schemaTypeObject || s.Ref != "" {
+		return nil
+	}
+	list := []string{}
+	add := func(key string, v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		list = append(list, d.f.text(key+": ")+d.f.code(string(data)))
+	}
+	if s.Format != "" {
+		list = append(list, d.f.text("format: ")+d.f.code(s.Format))
+	}
+	for _, key := range sortedValidatorKeys(s.Validators) {
+		if docStructuralKeywords[key] {
+			continue
+		}
+		if items, ok := s.Validators[key].(*Items); ok && len(items.Schemas) > 0 {
+			continue
+		}
+		if enum, ok := s.Validators[key].(*Enum); ok {
+			vals := make([]string, len(*enum))
+			for i, con := range *enum {
+				vals[i] = d.f.code(string(con))
+			}
+			list = append(list, d.f.text("allowed values: ")+strings.Join(vals, d.f.text(", ")))
+			continue
+		}
+		add(key, s.Validators[key])
+	}
+	if s.Default != nil {
+		add("default", s.Default)
+	}
+	if len(s.Examples) > 0 {
+		add("examples", s.Examples)
+	}
+	if s.ReadOnly != nil && *s.ReadOnly {
+		list = append(list, d.f.text("read-only"))
+	}
+	if s.WriteOnly != nil && *s.WriteOnly {
+		list = append(list, d.f.text("write-only"))
+	}
+	return list
+}
+
+// docAnchor turns a name into an identifier usable as a link fragment
+func docAnchor(name string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name), "-")
+}
@@ -0,0 +1,209 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path"
+	"sort"
+	"strings"
+)
+
+// GenerateHTML renders a static HTML reference for a set of schemas, keyed
+// by name. It returns the files of the site keyed by file name: a page for
+// each schema, named after it with an ".html" extension in place of any
+// other, and an "index.html" listing them all. Pages are laid out like
+// GenerateMarkdown output, with required properties marked. References link
+// to the sections they point at, on other pages too, whether they've been
+// resolved or name another schema of the set by its key or "$id"
+func GenerateHTML(schemas map[string]*RootSchema) (map[string][]byte, error) {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := map[string]string{}
+	taken := map[string]string{"index.html": "the index"}
+	docs := map[string]*schemaDocs{}
+	links := map[*Schema]docLink{}
+	refs := map[string]docLink{}
+	for _, name := range names {
+		rs := schemas[name]
+		file := htmlFileName(name)
+		if other, ok := taken[file]; ok {
+			return nil, fmt.Errorf("schemas %s and %s would both be written to %s", name, other, file)
+		}
+		taken[file] = name
+		files[name] = file
+
+		d := newSchemaDocs(rs, htmlFormat{})
+		docs[name] = d
+		for sch, link := range d.links {
+			links[sch] = docLink{link.title, file + link.href}
+		}
+		bases := []string{name}
+		if rs.ID != "" {
+			bases = append(bases, strings.TrimSuffix(rs.ID, "#"))
+		}
+		for _, base := range bases {
+			root := docLink{d.sections[0].title(), file}
+			refs[base], refs[base+"#"] = root, root
+			for ref, link := range d.refs {
+				refs[base+ref] = docLink{link.title, file + link.href}
+			}
+		}
+	}
+
+	site := map[string][]byte{}
+	for _, name := range names {
+		d := docs[name]
+		for sch, link := range links {
+			if _, ok := d.links[sch]; !ok {
+				d.links[sch] = link
+			}
+		}
+		for ref, link := range refs {
+			if _, ok := d.refs[ref]; !ok {
+				d.refs[ref] = link
+			}
+		}
+		site[files[name]] = htmlPage(d, name, names, files)
+	}
+	site["index.html"] = htmlIndex(schemas, names, files)
+	return site, nil
+}
+
+// htmlFileName gives the name of the page for a schema
+func htmlFileName(name string) string {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	return strings.Replace(name, "/", "_", -1) + ".html"
+}
+
+// htmlStyle is the stylesheet shared by every page
+const htmlStyle = `body { display: flex; margin: 0; font-family: sans-serif; line-height: 1.5; }
+nav { flex: 0 0 14em; padding: 1em; background: #f4f4f4; }
+nav ul { padding-left: 1em; }
+main { flex: 1; padding: 1em 2em; }
+.description { white-space: pre-line; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+tr.required td:first-child { font-weight: bold; }
+.badge { font-size: 0.75em; padding: 0 0.4em; border-radius: 0.3em; }
+tr.required .badge { background: #c33; color: #fff; }
+tr.optional .badge { background: #ddd; color: #555; }
+`
+
+func writeHTMLHead(buf *bytes.Buffer, title string) {
+	fmt.Fprintf(buf, "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n",
+		html.EscapeString(title), htmlStyle)
+}
+
+// htmlPage renders the page for the schema name, with navigation between
+// its sections and the other pages
+func htmlPage(d *schemaDocs, name string, names []string, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	writeHTMLHead(buf, d.sections[0].title())
+
+	buf.WriteString("<nav>\n<a href=\"index.html\">All schemas</a>\n<ul>\n")
+	for _, other := range names {
+		if other != name {
+			fmt.Fprintf(buf, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(files[other]), html.EscapeString(other))
+			continue
+		}
+		fmt.Fprintf(buf, "<li><strong>%s</strong>\n<ul>\n", html.EscapeString(name))
+		for _, sec := range d.sections {
+			fmt.Fprintf(buf, "<li><a href=\"#%s\">%s</a></li>\n", sec.anchor, html.EscapeString(sec.title()))
+		}
+		buf.WriteString("</ul>\n</li>\n")
+	}
+	buf.WriteString("</ul>\n</nav>\n<main>\n")
+
+	for i, sec := range d.sections {
+		level := 1
+		switch {
+		case i == 1:
+			buf.WriteString("<h2>Definitions</h2>\n")
+			fallthrough
+		case i > 1:
+			level = 3
+		}
+		fmt.Fprintf(buf, "<section id=\"%s\">\n<h%d>%s</h%d>\n", sec.anchor, level, html.EscapeString(sec.title()), level)
+		writeHTMLSection(buf, d, sec)
+		buf.WriteString("</section>\n")
+	}
+	buf.WriteString("</main>\n</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+func writeHTMLSection(buf *bytes.Buffer, d *schemaDocs, sec docSection) {
+	s := sec.schema
+	if sec.name != "" && s.Title != "" && s.Title != sec.name {
+		fmt.Fprintf(buf, "<p><strong>%s</strong></p>\n", html.EscapeString(s.Title))
+	}
+	if s.Description != "" {
+		fmt.Fprintf(buf, "<p class=\"description\">%s</p>\n", html.EscapeString(strings.TrimSpace(s.Description)))
+	}
+	fmt.Fprintf(buf, "<p>Type: %s</p>\n", d.typeText(s))
+	if cons := d.constraints(s); len(cons) > 0 {
+		buf.WriteString("<ul class=\"constraints\">\n")
+		for _, con := range cons {
+			fmt.Fprintf(buf, "<li>%s</li>\n", con)
+		}
+		buf.WriteString("</ul>\n")
+	}
+
+	rows := d.rows(s)
+	if len(rows) == 0 {
+		return
+	}
+	buf.WriteString("<table>\n<thead>\n<tr><th>Property</th><th>Type</th><th>Description</th><th>Constraints</th></tr>\n</thead>\n<tbody>\n")
+	for _, row := range rows {
+		class := "optional"
+		if row.required {
+			class = "required"
+		}
+		desc := ""
+		if row.schema != nil {
+			desc = html.EscapeString(strings.TrimSpace(row.schema.Description))
+		}
+		fmt.Fprintf(buf, "<tr class=\"%s\"><td><code>%s</code> <span class=\"badge\">%s</span></td><td>%s</td><td class=\"description\">%s</td><td>%s</td></tr>\n",
+			class, html.EscapeString(row.name), class, d.typeText(row.schema), desc,
+			strings.Join(d.constraints(row.schema), "<br>"))
+	}
+	buf.WriteString("</tbody>\n</table>\n")
+}
+
+// htmlIndex renders the page listing every schema of the set
+func htmlIndex(schemas map[string]*RootSchema, names []string, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	writeHTMLHead(buf, "Schemas")
+	buf.WriteString("<main>\n<h1>Schemas</h1>\n<dl>\n")
+	for _, name := range names {
+		rs := schemas[name]
+		fmt.Fprintf(buf, "<dt><a href=\"%s\">%s</a></dt>\n", html.EscapeString(files[name]), html.EscapeString(name))
+		if rs.Title != "" {
+			fmt.Fprintf(buf, "<dd><strong>%s</strong></dd>\n", html.EscapeString(rs.Title))
+		}
+		if rs.Description != "" {
+			fmt.Fprintf(buf, "<dd class=\"description\">%s</dd>\n", html.EscapeString(strings.TrimSpace(rs.Description)))
+		}
+	}
+	buf.WriteString("</dl>\n</main>\n</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// htmlFormat renders inline documentation as HTML
+type htmlFormat struct{}
+
+func (htmlFormat) text(str string) string {
+	return html.EscapeString(str)
+}
+
+func (htmlFormat) code(str string) string {
+	return "<code>" + html.EscapeString(str) + "</code>"
+}
+
+func (htmlFormat) link(text, href string) string {
+	return "<a href=\"" + html.EscapeString(href) + "\">" + text + "</a>"
+}
@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// GenerateMarkdown renders reference documentation for rs as Markdown. The
+// root schema and each top-level definition get a section giving their
+// title, description, type and constraints, followed by a table of their
+// properties. Objects and arrays of objects nested within properties are
+// expanded into rows of their own, while references link to the section of
+// the definition they point at
+func GenerateMarkdown(rs *RootSchema) ([]byte, error) {
+	d := newSchemaDocs(rs, markdownFormat{})
+	buf := &bytes.Buffer{}
+	for i, sec := range d.sections {
+		level := "#"
+		switch {
+		case i == 1:
+			buf.WriteString("## Definitions\n\n")
+			fallthrough
+		case i > 1:
+			level = "###"
+		}
+		fmt.Fprintf(buf, "<a id=\"%s\"></a>\n\n%s %s\n\n", sec.anchor, level, sec.title())
+		writeMarkdownSection(buf, d, sec)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMarkdownSection(buf *bytes.Buffer, d *schemaDocs, sec docSection) {
+	s := sec.schema
+	if sec.name != "" && s.Title != "" && s.Title != sec.name {
+		fmt.Fprintf(buf, "**%s**\n\n", s.Title)
+	}
+	if s.Description != "" {
+		fmt.Fprintf(buf, "%s\n\n", strings.TrimSpace(s.Description))
+	}
+	fmt.Fprintf(buf, "Type: %s\n\n", d.typeText(s))
+	if cons := d.constraints(s); len(cons) > 0 {
+		for _, con := range cons {
+			fmt.Fprintf(buf, "- %s\n", con)
+		}
+		buf.WriteString("\n")
+	}
+
+	rows := d.rows(s)
+	if len(rows) == 0 {
+		return
+	}
+	buf.WriteString("| Property | Type | Required | Description | Constraints |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, row := range rows {
+		required := "no"
+		if row.required {
+			required = "yes"
+		}
+		desc := ""
+		if row.schema != nil {
+			desc = markdownCell(row.schema.Description)
+		}
+		fmt.Fprintf(buf, "| %s | %s | %s | %s | %s |\n",
+			markdownFormat{}.code(row.name), d.typeText(row.schema), required, desc,
+			strings.Join(d.constraints(row.schema), "<br>"))
+	}
+	buf.WriteString("\n")
+}
+
+// markdownCell fits text on a single line of a table
+func markdownCell(str string) string {
+	return strings.Replace(markdownFormat{}.text(strings.TrimSpace(str)), "\n", "<br>", -1)
+}
+
+// markdownFormat renders inline documentation as GitHub-flavored Markdown.
+// Pipes are escaped so text can appear in tables
+type markdownFormat struct{}
+
+func (markdownFormat) text(str string) string {
+	return strings.Replace(str, "|", `\|`, -1)
+}
+
+func (markdownFormat) code(str string) string {
+	str = strings.Replace(str, "|", `\|`, -1)
+	if strings.Contains(str, "`") {
+		return "`` " + str + " ``"
+	}
+	return "`" + str + "`"
+}
+
+func (markdownFormat) link(text, href string) string {
+	return "[" + text + "](" + href + ")"
+}
@@ -0,0 +1,117 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+const docsTestSchema = `{
+	"title": "Person",
+	"description": "Someone with a name",
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "description": "Full name | preferred", "minLength": 1},
+		"age": {"type": ["integer", "null"], "minimum": 0, "default": 30},
+		"pet": {"$ref": "#/definitions/pet"},
+		"address": {
+			"type": "object",
+			"properties": {
+				"city": {"type": "string"}
+			},
+			"required": ["city"]
+		},
+		"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}}
+	},
+	"required": ["name"],
+	"definitions": {
+		"pet": {
+			"title": "Pet",
+			"oneOf": [{"$ref": "#/definitions/cat"}, {"type": "null"}]
+		},
+		"cat": {
+			"type": "object",
+			"properties": {"lives": {"type": "integer", "maximum": 9}}
+		}
+	}
+}`
+
+func TestGenerateMarkdown(t *testing.T) {
+	data, err := GenerateMarkdown(Must(docsTestSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"# Person\n\nSomeone with a name\n\nType: object\n",
+		"| `name` | string | yes | Full name \\| preferred | minLength: `1` |",
+		"| `age` | integer \\| null | no |  | minimum: `0`<br>default: `30` |",
+		"| `pet` | [pet](#definitions-pet) | no |",
+		"| `address.city` | string | yes |",
+		"| `tags` | array of string | no |",
+		"| `tags[]` | string | no |  | allowed values: `\"a\"`, `\"b\"` |",
+		"## Definitions\n",
+		"<a id=\"definitions-cat\"></a>\n\n### cat\n",
+		"### pet\n\n**Pet**\n\nType: one of: [cat](#definitions-cat), null\n",
+		"| `lives` | integer | no |  | maximum: `9` |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerateHTML(t *testing.T) {
+	site, err := GenerateHTML(map[string]*RootSchema{
+		"person.json": Must(docsTestSchema),
+		"owner.json": Must(`{
+			"$id": "https://example.com/owner",
+			"type": "object",
+			"properties": {
+				"person": {"$ref": "person.json"},
+				"cat": {"$ref": "person.json#/definitions/cat"},
+				"friend": {"$ref": "#/definitions/friend"},
+				"<b>": {"type": "string"}
+			},
+			"required": ["person"],
+			"definitions": {"friend": {"type": "string"}}
+		}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(site) != 3 || site["index.html"] == nil {
+		t.Fatalf("expected an index and a page per schema, got %d files", len(site))
+	}
+	for file, wants := range map[string][]string{
+		"index.html": {
+			`<dt><a href="owner.html">owner.json</a></dt>`,
+			`<dt><a href="person.html">person.json</a></dt>`,
+		},
+		"owner.html": {
+			`<tr class="required"><td><code>person</code> <span class="badge">required</span></td><td><a href="person.html">Person</a></td>`,
+			`<tr class="optional"><td><code>cat</code> <span class="badge">optional</span></td><td><a href="person.html#definitions-cat">cat</a></td>`,
+			`<td><a href="#definitions-friend">friend</a></td>`,
+			`<code>&lt;b&gt;</code>`,
+			`<section id="definitions-friend">`,
+		},
+		"person.html": {
+			`<h1>Person</h1>`,
+			`<p class="description">Someone with a name</p>`,
+			`<a href="owner.html">owner.json</a>`,
+			`<td>integer | null</td>`,
+			`<td><a href="#definitions-pet">pet</a></td>`,
+			`<p>Type: one of: <a href="#definitions-cat">cat</a>, null</p>`,
+		},
+	} {
+		got := string(site[file])
+		for _, want := range wants {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %s to contain %q, got:\n%s", file, want, got)
+			}
+		}
+	}
+
+	if _, err := GenerateHTML(map[string]*RootSchema{"a.json": Must(`true`), "a.yaml": Must(`true`)}); err == nil {
+		t.Error("expected an error for schemas sharing a page name")
+	}
+}
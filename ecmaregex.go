@@ -0,0 +1,400 @@
+package jsonschema
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+// ecmaRegex checks the syntax of an ECMA 262 regular expression pattern,
+// as a RegExp without flags reads it, including the extensions of its
+// annex B that browsers accept, such as literal "]" and "{" and identity
+// escapes of any character. Unlike Go's regexp, it accepts lookarounds and
+// backreferences, and rejects Go-only syntax such as "(?i)"
+// http://www.ecma-international.org/ecma-262/#sec-patterns
+type ecmaRegex struct {
+	src   []rune
+	pos   int
+	names map[string]bool
+	// refs are the group names of \k escapes, with "" for malformed ones.
+	// They're only checked if the pattern has named groups
+	refs []string
+}
+
+// RegexFormatOptions configure the regex format, which accepts ECMA 262
+// patterns by default
+type RegexFormatOptions struct {
+	// AllowRE2 accepts patterns in the RE2 syntax of Go's regexp package
+	// too, such as inline flags like "(?i)", which ECMA 262 lacks
+	AllowRE2 bool
+}
+
+// check reports the first syntax error of the ECMA 262 pattern, unless o
+// accepts it as RE2
+func (o RegexFormatOptions) check(pattern string) error {
+	err := checkECMARegex(pattern)
+	if err != nil && o.AllowRE2 {
+		if _, reErr := regexp.Compile(pattern); reErr == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// checkECMARegex reports the first syntax error of the ECMA 262 regular
+// expression pattern
+func checkECMARegex(pattern string) error {
+	re := &ecmaRegex{src: []rune(pattern), names: map[string]bool{}}
+	if err := re.disjunction(); err != nil {
+		return err
+	}
+	if re.pos < len(re.src) {
+		return re.errorf("unmatched )")
+	}
+	if len(re.names) > 0 {
+		for _, name := range re.refs {
+			if name == "" {
+				return fmt.Errorf("invalid named reference")
+			}
+			if !re.names[name] {
+				return fmt.Errorf("reference to undefined group %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// errorf gives a syntax error at the current character
+func (re *ecmaRegex) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), re.pos)
+}
+
+// peek gives the character at offset n from the current one, or -1
+func (re *ecmaRegex) peek(n int) rune {
+	if re.pos+n < len(re.src) {
+		return re.src[re.pos+n]
+	}
+	return -1
+}
+
+// disjunction reads alternatives up to a closing parenthesis or the end
+func (re *ecmaRegex) disjunction() error {
+	for {
+		if err := re.alternative(); err != nil {
+			return err
+		}
+		if re.peek(0) != '|' {
+			return nil
+		}
+		re.pos++
+	}
+}
+
+// alternative reads a sequence of terms
+func (re *ecmaRegex) alternative() error {
+	for re.pos < len(re.src) {
+		quantifiable := true
+		switch re.peek(0) {
+		case '|', ')':
+			return nil
+		case '^', '$':
+			re.pos++
+			quantifiable = false
+		case '*', '+', '?':
+			return re.errorf("nothing to repeat")
+		case '{':
+			if _, ok := re.bracedQuantifier(); ok {
+				return re.errorf("nothing to repeat")
+			}
+			re.pos++
+		case '(':
+			var err error
+			if quantifiable, err = re.group(); err != nil {
+				return err
+			}
+		case '[':
+			if err := re.class(); err != nil {
+				return err
+			}
+		case '\\':
+			if c := re.peek(1); c == 'b' || c == 'B' {
+				re.pos += 2
+				quantifiable = false
+			} else if err := re.atomEscape(); err != nil {
+				return err
+			}
+		default:
+			re.pos++
+		}
+		if err := re.quantifier(quantifiable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quantifier reads the quantifier following a term, if any
+func (re *ecmaRegex) quantifier(quantifiable bool) error {
+	switch re.peek(0) {
+	case '*', '+', '?':
+		if !quantifiable {
+			return re.errorf("nothing to repeat")
+		}
+		re.pos++
+	case '{':
+		n, ok := re.bracedQuantifier()
+		if !ok {
+			return nil
+		}
+		if !quantifiable {
+			return re.errorf("nothing to repeat")
+		}
+		if n < 0 {
+			return re.errorf("numbers out of order in {} quantifier")
+		}
+		re.pos += n
+	default:
+		return nil
+	}
+	if re.peek(0) == '?' {
+		re.pos++
+	}
+	return nil
+}
+
+// bracedQuantifier reports whether a {n}, {n,} or {n,m} quantifier starts
+// at the current character, giving its length, or -1 if m is less than n
+func (re *ecmaRegex) bracedQuantifier() (int, bool) {
+	i := re.pos + 1
+	number := func() (int, bool) {
+		start, n := i, 0
+		for i < len(re.src) && re.src[i] >= '0' && re.src[i] <= '9' {
+			if n < 1<<20 {
+				n = n*10 + int(re.src[i]-'0')
+			}
+			i++
+		}
+		return n, i > start
+	}
+	min, ok := number()
+	if !ok {
+		return 0, false
+	}
+	max := min
+	if i < len(re.src) && re.src[i] == ',' {
+		i++
+		if max, ok = number(); !ok {
+			max = min
+		}
+	}
+	if i >= len(re.src) || re.src[i] != '}' {
+		return 0, false
+	}
+	if max < min {
+		return -1, true
+	}
+	return i + 1 - re.pos, true
+}
+
+// group reads a parenthesized group, reporting whether it may be
+// quantified: lookbehinds can't be
+func (re *ecmaRegex) group() (bool, error) {
+	start := re.pos
+	re.pos++
+	quantifiable := true
+	if re.peek(0) == '?' {
+		switch {
+		case re.peek(1) == ':' || re.peek(1) == '=' || re.peek(1) == '!':
+			re.pos += 2
+		case re.peek(1) == '<' && (re.peek(2) == '=' || re.peek(2) == '!'):
+			re.pos += 3
+			quantifiable = false
+		case re.peek(1) == '<':
+			re.pos += 2
+			name, ok := re.groupName()
+			if !ok {
+				return false, re.errorf("invalid capture group name")
+			}
+			if re.names[name] {
+				return false, re.errorf("duplicate capture group name %q", name)
+			}
+			re.names[name] = true
+		default:
+			return false, re.errorf("invalid group")
+		}
+	}
+	if err := re.disjunction(); err != nil {
+		return false, err
+	}
+	if re.peek(0) != ')' {
+		re.pos = start
+		return false, re.errorf("missing )")
+	}
+	re.pos++
+	return quantifiable, nil
+}
+
+// groupName reads an identifier followed by ">"
+func (re *ecmaRegex) groupName() (string, bool) {
+	start := re.pos
+	for ; re.pos < len(re.src) && re.src[re.pos] != '>'; re.pos++ {
+		c := re.src[re.pos]
+		if !(c == '$' || c == '_' || unicode.IsLetter(c) ||
+			(re.pos > start && (unicode.IsDigit(c) || unicode.In(c, unicode.Mn, unicode.Mc, unicode.Pc) || c == '\u200c' || c == '\u200d'))) {
+			return "", false
+		}
+	}
+	if re.pos == start || re.pos == len(re.src) {
+		return "", false
+	}
+	name := string(re.src[start:re.pos])
+	re.pos++
+	return name, true
+}
+
+// atomEscape reads an escape outside a character class
+func (re *ecmaRegex) atomEscape() error {
+	re.pos++
+	if re.pos == len(re.src) {
+		return re.errorf(`\ at end of pattern`)
+	}
+	if re.src[re.pos] == 'k' {
+		re.pos++
+		name := ""
+		if re.peek(0) == '<' {
+			re.pos++
+			name, _ = re.groupName()
+		}
+		re.refs = append(re.refs, name)
+		return nil
+	}
+	re.escapeValue()
+	return nil
+}
+
+// class reads a character class
+func (re *ecmaRegex) class() error {
+	start := re.pos
+	re.pos++
+	if re.peek(0) == '^' {
+		re.pos++
+	}
+	for {
+		if re.pos == len(re.src) {
+			re.pos = start
+			return re.errorf("missing ]")
+		}
+		if re.src[re.pos] == ']' {
+			re.pos++
+			return nil
+		}
+		lo, loSet, err := re.classAtom()
+		if err != nil {
+			return err
+		}
+		if re.peek(0) != '-' || re.peek(1) == ']' || re.peek(1) == -1 {
+			continue
+		}
+		re.pos++
+		hi, hiSet, err := re.classAtom()
+		if err != nil {
+			return err
+		}
+		// ranges with class escapes such as \d are literal hyphens
+		if !loSet && !hiSet && lo > hi {
+			return re.errorf("range out of order in character class")
+		}
+	}
+}
+
+// classAtom reads a character of a class, giving its value, or reporting
+// it's a class escape such as \d
+func (re *ecmaRegex) classAtom() (rune, bool, error) {
+	c := re.src[re.pos]
+	if c != '\\' {
+		re.pos++
+		return c, false, nil
+	}
+	re.pos++
+	if re.pos == len(re.src) {
+		return 0, false, re.errorf(`\ at end of pattern`)
+	}
+	switch re.src[re.pos] {
+	case 'd', 'D', 's', 'S', 'w', 'W':
+		re.pos++
+		return 0, true, nil
+	case 'b':
+		re.pos++
+		return '\b', false, nil
+	case '-':
+		re.pos++
+		return '-', false, nil
+	}
+	v, set := re.escapeValue()
+	return v, set, nil
+}
+
+// escapeValue reads the escape following a backslash, giving the character
+// it stands for, or reporting it's a class escape
+func (re *ecmaRegex) escapeValue() (rune, bool) {
+	c := re.src[re.pos]
+	re.pos++
+	switch c {
+	case 'd', 'D', 's', 'S', 'w', 'W':
+		return 0, true
+	case 'f':
+		return '\f', false
+	case 'n':
+		return '\n', false
+	case 'r':
+		return '\r', false
+	case 't':
+		return '\t', false
+	case 'v':
+		return '\v', false
+	case 'c':
+		if l := re.peek(0); l >= 'a' && l <= 'z' || l >= 'A' && l <= 'Z' {
+			re.pos++
+			return l % 32, false
+		}
+		// annex B reads a backslash not followed by a control letter
+		re.pos--
+		return '\\', false
+	case 'x':
+		if v, ok := re.hex(2); ok {
+			return v, false
+		}
+	case 'u':
+		if v, ok := re.hex(4); ok {
+			return v, false
+		}
+	}
+	if c >= '0' && c <= '7' {
+		// backreferences and annex B's legacy octal escapes
+		v := c - '0'
+		for n := 1; n < 3 && re.peek(0) >= '0' && re.peek(0) <= '7' && v*8+re.peek(0)-'0' <= 0377; n++ {
+			v = v*8 + re.peek(0) - '0'
+			re.pos++
+		}
+		return v, false
+	}
+	return c, false
+}
+
+// hex reads n hex digits, if they follow
+func (re *ecmaRegex) hex(n int) (rune, bool) {
+	var v rune
+	for i := 0; i < n; i++ {
+		c := unicode.ToLower(re.peek(i))
+		switch {
+		case c >= '0' && c <= '9':
+			v = v*16 + c - '0'
+		case c >= 'a' && c <= 'f':
+			v = v*16 + c - 'a' + 10
+		default:
+			return 0, false
+		}
+	}
+	re.pos += n
+	return v, true
+}
@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestCheckECMARegex(t *testing.T) {
+	cases := []struct {
+		pattern, err string
+	}{
+		{`([abc])+\s+$`, ""},
+		{`^(?:a|b)+?c{2,}d{1,3}$`, ""},
+		{`(?<=\$)\d+(?!\.)`, ""},
+		{`(?=a)*`, ""},
+		{`(?<year>\d{4})-\k<year>`, ""},
+		{`(a)\1`, ""},
+		{`\k<a>`, ""},
+		{`[\d-z\x41-\x5a\b]`, ""},
+		{`\cA\c1`, ""},
+		{`{`, ""},
+		{`a{,5}`, ""},
+		{`]}`, ""},
+		{`[^]`, ""},
+		{`^(abc]`, "missing ) at offset 1"},
+		{`(?i)abc`, "invalid group at offset 1"},
+		{`a)`, "unmatched ) at offset 1"},
+		{`[a`, "missing ] at offset 0"},
+		{`a\`, `\ at end of pattern at offset 2`},
+		{`*a`, "nothing to repeat at offset 0"},
+		{`{1}`, "nothing to repeat at offset 0"},
+		{`a**`, "nothing to repeat at offset 2"},
+		{`^*`, "nothing to repeat at offset 1"},
+		{`(?<=a)+`, "nothing to repeat at offset 6"},
+		{`a{2,1}`, "numbers out of order in {} quantifier at offset 1"},
+		{`[z-a]`, "range out of order in character class at offset 4"},
+		{`(?<1a>a)`, "invalid capture group name at offset 3"},
+		{`(?<a>x)(?<a>y)`, `duplicate capture group name "a" at offset 12`},
+		{`(?<a>x)\k<b>`, `reference to undefined group "b"`},
+		{`(?<a>x)\k`, "invalid named reference"},
+	}
+	for _, c := range cases {
+		got := ""
+		if err := checkECMARegex(c.pattern); err != nil {
+			got = err.Error()
+		}
+		if got != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.pattern, c.err, got)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EmailProfile is how strictly the "email" format reads addresses
+type EmailProfile int
+
+const (
+	// EmailStrict accepts the "Mailbox" of RFC 5321, section 4.1.2: a local
+	// part of dot-separated atoms or a quoted string, and a host name or an
+	// IPv4 or IPv6 address literal
+	EmailStrict EmailProfile = iota
+	// EmailSimple accepts any local part of printable characters other than
+	// separators, including leading, trailing and consecutive dots, as some
+	// mail providers hand out, and a host name
+	EmailSimple
+	// EmailHTML5 accepts the addresses of HTML's email input: a local part
+	// of "atext" characters and dots, and a domain of letters, digits and
+	// inner hyphens, without limits on their lengths
+	// https://html.spec.whatwg.org/multipage/input.html#valid-e-mail-address
+	EmailHTML5
+)
+
+// EmailFormatOptions configures the "email" format
+type EmailFormatOptions struct {
+	Profile EmailProfile
+}
+
+// Limits on the lengths of addresses, RFC 5321 section 4.5.3.1 and
+// RFC 3696 errata 1690
+const (
+	emailMaxLocalPart = 64
+	emailMaxAddress   = 254
+)
+
+// check reports whether email is an address according to the options
+func (o EmailFormatOptions) check(email string) error {
+	at := strings.LastIndexByte(email, '@')
+	if at == -1 {
+		return fmt.Errorf("missing @")
+	}
+	local, domain := email[:at], email[at+1:]
+	if local == "" {
+		return fmt.Errorf("missing local part")
+	}
+	if o.Profile == EmailHTML5 {
+		return checkHTML5Email(local, domain)
+	}
+	if len(local) > emailMaxLocalPart {
+		return fmt.Errorf("local part is longer than %d characters", emailMaxLocalPart)
+	}
+	if len(email) > emailMaxAddress {
+		return fmt.Errorf("address is longer than %d characters", emailMaxAddress)
+	}
+
+	if o.Profile == EmailSimple {
+		for i := 0; i < len(local); i++ {
+			if c := local[i]; c <= ' ' || c >= 0x7f || strings.IndexByte(`@"(),:;<>[\]`, c) != -1 {
+				return fmt.Errorf("invalid character %q in local part", c)
+			}
+		}
+		return checkEmailDomain(domain, false)
+	}
+
+	if strings.HasPrefix(local, `"`) {
+		if err := checkEmailQuotedString(local); err != nil {
+			return err
+		}
+	} else {
+		for _, atom := range strings.Split(local, ".") {
+			if atom == "" {
+				return fmt.Errorf("local part has an empty atom")
+			}
+			for i := 0; i < len(atom); i++ {
+				if !isEmailAtext(atom[i]) {
+					return fmt.Errorf("invalid character %q in local part", atom[i])
+				}
+			}
+		}
+	}
+	return checkEmailDomain(domain, true)
+}
+
+// checkEmailQuotedString checks a local part is a "Quoted-string" of
+// RFC 5321
+func checkEmailQuotedString(local string) error {
+	if len(local) < 2 || !strings.HasSuffix(local, `"`) {
+		return fmt.Errorf("unterminated quoted local part")
+	}
+	quoted := local[1 : len(local)-1]
+	for i := 0; i < len(quoted); i++ {
+		switch c := quoted[i]; {
+		case c == '\\':
+			i++
+			if i == len(quoted) || quoted[i] < ' ' || quoted[i] > '~' {
+				return fmt.Errorf("invalid quoted pair in local part")
+			}
+		case c == '"' || c < ' ' || c > '~':
+			return fmt.Errorf("invalid character %q in quoted local part", c)
+		}
+	}
+	return nil
+}
+
+// checkEmailDomain checks the domain of an address is a host name or, if
+// literals are allowed, an IPv4 or IPv6 address literal
+func checkEmailDomain(domain string, literals bool) error {
+	if strings.HasPrefix(domain, "[") && strings.HasSuffix(domain, "]") && literals {
+		addr := domain[1 : len(domain)-1]
+		var err error
+		if strings.HasPrefix(addr, "IPv6:") {
+			err = ValidateIPv6(addr[len("IPv6:"):])
+		} else {
+			err = ValidateIPv4(addr)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid address literal: %s", err.Error())
+		}
+		return nil
+	}
+	if err := ValidateHostname(domain); err != nil {
+		return fmt.Errorf("invalid domain: %s", err.Error())
+	}
+	return nil
+}
+
+// checkHTML5Email checks the parts of an address HTML's email input
+// accepts
+func checkHTML5Email(local, domain string) error {
+	for i := 0; i < len(local); i++ {
+		if !isEmailAtext(local[i]) && local[i] != '.' {
+			return fmt.Errorf("invalid character %q in local part", local[i])
+		}
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid domain label %q", label)
+		}
+		for i := 0; i < len(label); i++ {
+			if !isAlphaNum(label[i]) && label[i] != '-' {
+				return fmt.Errorf("invalid character %q in domain", label[i])
+			}
+		}
+	}
+	return nil
+}
+
+// isEmailAtext reports whether c is an "atext" character of RFC 5322, which
+// make up the atoms of unquoted local parts
+func isEmailAtext(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) != -1
+}
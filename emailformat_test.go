@@ -0,0 +1,49 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmailFormat(t *testing.T) {
+	cases := []struct {
+		email          string
+		strict, simple string
+	}{
+		{"joe.bloggs@example.com", "", ""},
+		{"joe+tag@sub.example.com", "", ""},
+		{`"joe bloggs"@example.com`, "", `invalid character '"' in local part`},
+		{`"joe\"@"@example.com`, "", `invalid character '"' in local part`},
+		{"joe@[192.168.0.1]", "", `invalid domain: label "[192" contains illegal character '['`},
+		{"joe@[IPv6:::1]", "", `invalid domain: label "[IPv6:::1]" contains illegal character '['`},
+		{"joe..bloggs.@docomo.ne.jp", "local part has an empty atom", ""},
+		{".joe@example.com", "local part has an empty atom", ""},
+		{"2962", "missing @", "missing @"},
+		{"@example.com", "missing local part", "missing local part"},
+		{"Joe <joe@example.com>", "invalid character ' ' in local part", "invalid character ' ' in local part"},
+		{"joe@-example.com", `invalid domain: label "-example" can't begin or end with a hyphen`, `invalid domain: label "-example" can't begin or end with a hyphen`},
+		{"joe@[1.2.3]", "invalid address literal: IPv4 addresses have 4 parts, not 3", `invalid domain: label "[1" contains illegal character '['`},
+		{`"joe@example.com`, "unterminated quoted local part", `invalid character '"' in local part`},
+		{strings.Repeat("a", 65) + "@example.com", "local part is longer than 64 characters", "local part is longer than 64 characters"},
+		{"joe@" + strings.Repeat("a.", 125) + "com", "address is longer than 254 characters", "address is longer than 254 characters"},
+	}
+	for _, profile := range []EmailProfile{EmailStrict, EmailSimple} {
+		rs := Must(`{"format": "email"}`)
+		rs.SetOptions(Options{Formats: FormatOptions{Email: EmailFormatOptions{Profile: profile}}})
+		for _, c := range cases {
+			expect := c.strict
+			if profile == EmailSimple {
+				expect = c.simple
+			}
+			errs := []ValError{}
+			rs.Validate("/", c.email, &errs)
+			got := ""
+			if len(errs) > 0 {
+				got = strings.TrimPrefix(errs[0].Message, "invalid email: ")
+			}
+			if got != expect {
+				t.Errorf("profile %d %q: expected error %q, got %q", profile, c.email, expect, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvVar is an environment variable that sets a property of instances
+type EnvVar struct {
+	Name string
+	// Pointer is the instance JSON pointer of the property
+	Pointer string
+	// Types are the types the schema declares for the property, which the
+	// variable's value is coerced to. Values of properties without a type
+	// stay strings
+	Types []string
+}
+
+// EnvVars lists the environment variables setting the properties of
+// instances of the schema, ordered by property. A property's variable is
+// named by its "x-env" keyword, or else by prefix followed by the property
+// name in upper snake case, so "maxConns" is read from prefix+"MAX_CONNS".
+// Properties whose schemas declare properties of their own are objects
+// built from a variable per nested property, named by joining the names
+// with underscores, as with "DB_HOST" for the "host" of "db". Such
+// properties have a variable of their own only when they have "x-env".
+// Properties are found through "properties" as for Defaults
+func (rs *RootSchema) EnvVars(prefix string) []EnvVar {
+	vars := []EnvVar{}
+	collectEnvVars(&rs.Schema, "", prefix, &vars, map[*Schema]bool{})
+	return vars
+}
+
+// collectEnvVars adds the variables of the properties of s, located at ptr
+// and named by prefix, to vars. active holds the schemas of the locations
+// above ptr
+func collectEnvVars(s *Schema, ptr, prefix string, vars *[]EnvVar, active map[*Schema]bool) {
+	schemas := applicableSchemas(s, nil, map[*Schema]bool{})
+	names := []string{}
+	props := map[string][]*Schema{}
+	recurse := []*Schema{}
+	for _, sch := range schemas {
+		if active[sch] {
+			continue
+		}
+		recurse = append(recurse, sch)
+		if p, ok := sch.Validators["properties"].(*Properties); ok {
+			for _, name := range sortedPropertyKeys(*p) {
+				if _, ok := props[name]; !ok {
+					names = append(names, name)
+				}
+				props[name] = append(props[name], (*p)[name])
+			}
+		}
+	}
+
+	for _, sch := range recurse {
+		active[sch] = true
+	}
+	for _, name := range names {
+		propPtr := ptr + "/" + EscapePointerToken(name)
+		nested, explicit := false, ""
+		types := []string{}
+		for _, child := range props[name] {
+			for _, sch := range applicableSchemas(child, nil, map[*Schema]bool{}) {
+				if _, ok := sch.Validators["properties"]; ok && !active[sch] {
+					nested = true
+				}
+				if raw, ok := sch.extraKeywords["x-env"]; ok && explicit == "" {
+					json.Unmarshal(raw, &explicit)
+				}
+				if t, ok := sch.Validators["type"].(*Type); ok && len(types) == 0 {
+					types = t.vals
+				}
+			}
+		}
+		varName := prefix + envName(name)
+		if explicit != "" || !nested {
+			if explicit != "" {
+				varName = explicit
+			}
+			*vars = append(*vars, EnvVar{Name: varName, Pointer: propPtr, Types: types})
+		}
+		if nested {
+			for _, child := range props[name] {
+				collectEnvVars(child, propPtr, prefix+envName(name)+"_", vars, active)
+			}
+		}
+	}
+	for _, sch := range recurse {
+		delete(active, sch)
+	}
+}
+
+// envName converts a property name to upper snake case
+func envName(name string) string {
+	runes := []rune(name)
+	buf := &strings.Builder{}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			buf.WriteByte('_')
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// split "maxConns" and the "Port" of "HTTPPort"
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				buf.WriteByte('_')
+			}
+		}
+		buf.WriteRune(unicode.ToUpper(r))
+	}
+	return buf.String()
+}
+
+// DecodeEnv builds an instance of the schema from environ, a list of
+// "NAME=value" pairs as os.Environ gives, setting the property of each
+// variable of EnvVars that's present. Values are coerced to the declared
+// types of their properties: numbers and integers are parsed as floats,
+// booleans as strconv.ParseBool parses them, and an empty value or "null"
+// gives null. Arrays are JSON arrays or comma-separated lists whose
+// elements are coerced to the types of the items, and objects are JSON
+// objects. Of several declared types the first to parse, in that order,
+// is used, and values that parse as none of them stay strings for
+// validation to report
+func (rs *RootSchema) DecodeEnv(prefix string, environ []string) map[string]interface{} {
+	env := map[string]string{}
+	for _, kv := range environ {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	doc := map[string]interface{}{}
+	for _, v := range rs.EnvVars(prefix) {
+		val, ok := env[v.Name]
+		if !ok {
+			continue
+		}
+		var itemTypes func(i int) []string
+		if schemas, err := rs.SchemasAt(v.Pointer); err == nil {
+			itemTypes = func(i int) []string {
+				for _, sch := range schemas {
+					for _, item := range applicableSchemas(itemSchema(sch, i), nil, map[*Schema]bool{}) {
+						if t, ok := item.Validators["type"].(*Type); ok {
+							return t.vals
+						}
+					}
+				}
+				return nil
+			}
+		}
+		setEnvValue(doc, v.Pointer, coerceEnvValue(rs.options().Decoding, val, v.Types, itemTypes))
+	}
+	return doc
+}
+
+// ValidateEnv performs schema validation against the instance DecodeEnv
+// builds from environ, giving it along with the errors. The PropertyPath
+// of errors within a variable's property, or of a "required" error for a
+// missing one, is the variable's name. "required" errors for several
+// missing variables are split into an error for each
+func (rs *RootSchema) ValidateEnv(prefix string, environ []string) (map[string]interface{}, []ValError) {
+	doc := rs.DecodeEnv(prefix, environ)
+	errs := []ValError{}
+	rs.Validate("/", doc, &errs)
+
+	vars := rs.EnvVars(prefix)
+	mapped := make([]ValError, 0, len(errs))
+	for _, e := range errs {
+		ptr := e.PropertyPath
+		if ptr == "/" {
+			ptr = ""
+		}
+		unmapped := []string{}
+	keys:
+		for _, key := range e.MissingProperties {
+			for _, v := range vars {
+				if v.Pointer == ptr+"/"+EscapePointerToken(key) {
+					// split the missing variables into an error each
+					split := e
+					split.PropertyPath, split.Message, split.MissingProperties = v.Name, requiredMessage([]string{key}), []string{key}
+					mapped = append(mapped, split)
+					continue keys
+				}
+			}
+			unmapped = append(unmapped, key)
+		}
+		if len(e.MissingProperties) > 0 {
+			if len(unmapped) == 0 {
+				continue
+			}
+			e.Message, e.MissingProperties = requiredMessage(unmapped), unmapped
+		}
+		for _, v := range vars {
+			if ptr == v.Pointer || strings.HasPrefix(ptr, v.Pointer+"/") {
+				e.PropertyPath = v.Name
+				break
+			}
+		}
+		mapped = append(mapped, e)
+	}
+	return doc, mapped
+}
+
+// coerceEnvValue converts the value of a variable to the first of types it
+// parses as, decoding numbers as o sets. itemTypes gives the types of array
+// items by index
+func coerceEnvValue(o DecodeOptions, val string, types []string, itemTypes func(i int) []string) interface{} {
+	has := map[string]bool{}
+	for _, t := range types {
+		has[t] = true
+	}
+	trimmed := strings.TrimSpace(val)
+	if has["null"] && (trimmed == "" || trimmed == "null") {
+		return nil
+	}
+	if has["boolean"] {
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			return b
+		}
+	}
+	if has["integer"] || has["number"] {
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return o.decodedNumberText(trimmed, f)
+		}
+	}
+	if has["array"] {
+		if strings.HasPrefix(trimmed, "[") {
+			if arr, err := o.decodeJSON([]byte(trimmed)); err == nil {
+				if arr, ok := arr.([]interface{}); ok {
+					return arr
+				}
+			}
+		} else {
+			arr := []interface{}{}
+			if trimmed != "" {
+				for i, elem := range strings.Split(val, ",") {
+					var types []string
+					if itemTypes != nil {
+						types = itemTypes(i)
+					}
+					arr = append(arr, coerceEnvValue(o, strings.TrimSpace(elem), types, nil))
+				}
+			}
+			return arr
+		}
+	}
+	if has["object"] {
+		if obj, err := o.decodeJSON([]byte(trimmed)); err == nil {
+			if obj, ok := obj.(map[string]interface{}); ok {
+				return obj
+			}
+		}
+	}
+	return val
+}
+
+// setEnvValue sets the property at ptr within doc, creating the objects
+// above it
+func setEnvValue(doc map[string]interface{}, ptr string, val interface{}) {
+	tokens, err := ParsePointer(ptr)
+	if err != nil || len(tokens) == 0 {
+		return
+	}
+	for _, tok := range tokens[:len(tokens)-1] {
+		child, ok := doc[tok].(map[string]interface{})
+		if !ok {
+			// a variable holding the whole object takes precedence
+			if _, set := doc[tok]; set {
+				return
+			}
+			child = map[string]interface{}{}
+			doc[tok] = child
+		}
+		doc = child
+	}
+	if _, set := doc[tokens[len(tokens)-1]]; !set {
+		doc[tokens[len(tokens)-1]] = val
+	}
+}
@@ -0,0 +1,124 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	cases := []struct {
+		name, env string
+	}{
+		{"port", "PORT"},
+		{"maxConns", "MAX_CONNS"},
+		{"HTTPPort", "HTTP_PORT"},
+		{"tls-cert", "TLS_CERT"},
+		{"retry2Delay", "RETRY2_DELAY"},
+	}
+	for _, c := range cases {
+		if got := envName(c.name); got != c.env {
+			t.Errorf("%s: expected %s, got %s", c.name, c.env, got)
+		}
+	}
+}
+
+var envSchema = `{
+	"type": "object",
+	"required": ["port", "db"],
+	"properties": {
+		"port": {"type": "integer", "maximum": 65535},
+		"debug": {"type": "boolean", "default": false},
+		"logLevel": {"enum": ["debug", "info"]},
+		"hosts": {"type": "array", "items": {"type": "integer"}},
+		"secret": {"type": ["string", "null"], "x-env": "API_SECRET"},
+		"labels": {"type": "object"},
+		"db": {
+			"type": "object",
+			"required": ["url"],
+			"properties": {
+				"url": {"type": "string", "minLength": 1},
+				"poolSize": {"type": "number"}
+			}
+		}
+	}
+}`
+
+func TestEnvVars(t *testing.T) {
+	rs := Must(envSchema)
+	expect := []string{
+		"/db/poolSize=APP_DB_POOL_SIZE",
+		"/db/url=APP_DB_URL",
+		"/debug=APP_DEBUG",
+		"/hosts=APP_HOSTS",
+		"/labels=APP_LABELS",
+		"/logLevel=APP_LOG_LEVEL",
+		"/port=APP_PORT",
+		"/secret=API_SECRET",
+	}
+	vars := rs.EnvVars("APP_")
+	if len(vars) != len(expect) {
+		t.Fatalf("expected %d variables, got %v", len(expect), vars)
+	}
+	for i, v := range vars {
+		if got := v.Pointer + "=" + v.Name; got != expect[i] {
+			t.Errorf("variable %d: expected %s, got %s", i, expect[i], got)
+		}
+	}
+}
+
+func TestDecodeEnv(t *testing.T) {
+	rs := Must(envSchema)
+	doc := rs.DecodeEnv("APP_", []string{
+		"APP_PORT=8080",
+		"APP_DEBUG=true",
+		"APP_HOSTS=1, 2,3",
+		"API_SECRET=",
+		"APP_LABELS={\"team\": \"core\"}",
+		"APP_DB_URL=postgres://localhost",
+		"APP_DB_POOL_SIZE=4.5",
+		"APP_UNRELATED=x",
+		"HOME=/root",
+	})
+	assertJSONEqual(t, "DecodeEnv", `{
+		"port": 8080,
+		"debug": true,
+		"hosts": [1, 2, 3],
+		"secret": null,
+		"labels": {"team": "core"},
+		"db": {"url": "postgres://localhost", "poolSize": 4.5}
+	}`, doc)
+
+	doc = rs.DecodeEnv("APP_", []string{"APP_PORT=eighty", "APP_HOSTS=[4]", "APP_DEBUG=maybe"})
+	assertJSONEqual(t, "DecodeEnv", `{"port": "eighty", "hosts": [4], "debug": "maybe"}`, doc)
+}
+
+func TestValidateEnv(t *testing.T) {
+	rs := Must(envSchema)
+	_, errs := rs.ValidateEnv("APP_", []string{"APP_PORT=8080", "APP_DB_URL=postgres://localhost"})
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	_, errs = rs.ValidateEnv("APP_", []string{"APP_PORT=99999", "APP_HOSTS=1,x", "APP_DB_POOL_SIZE=2"})
+	got := map[string]int{}
+	for _, e := range errs {
+		got[e.PropertyPath]++
+	}
+	expect := map[string]int{"APP_PORT": 1, "APP_HOSTS": 1, "APP_DB_URL": 1}
+	if len(got) != len(expect) {
+		t.Errorf("expected errors for %v, got %v", expect, errs)
+	}
+	for name, n := range expect {
+		if got[name] != n {
+			t.Errorf("expected %d errors for %s, got %v", n, name, errs)
+		}
+	}
+
+	// the missing variable is split from the missing object
+	_, errs = rs.ValidateEnv("APP_", nil)
+	if len(errs) != 2 || errs[0].PropertyPath != "APP_PORT" || errs[1].PropertyPath != "/" || errs[1].Message != `"db" value is required` {
+		t.Errorf("expected an error for APP_PORT and one for db, got %v", errs)
+	} else if !reflect.DeepEqual(errs[0].MissingProperties, []string{"port"}) || !reflect.DeepEqual(errs[1].MissingProperties, []string{"db"}) {
+		t.Errorf("expected the missing properties to be split too, got %v and %v", errs[0].MissingProperties, errs[1].MissingProperties)
+	}
+}
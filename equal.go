@@ -0,0 +1,46 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Equal reports whether two schemas are structurally the same. Schemas are
+// compared in canonical form, ignoring the "$comment" and "description"
+// annotations, which don't affect validation
+func Equal(a, b *Schema) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	av, err := equalityValue(a)
+	if err != nil {
+		return false
+	}
+	bv, err := equalityValue(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// equalityValue gives the standard json-decoded canonical form of a schema
+// with ignored annotations stripped
+func equalityValue(s *Schema) (interface{}, error) {
+	cp := s.Clone()
+	walkSchemas("", cp, func(_ string, sch *Schema) error {
+		sch.Comment = ""
+		sch.Description = ""
+		return nil
+	})
+	if err := canonicalize(cp); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
@@ -0,0 +1,38 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect bool
+	}{
+		{`true`, `true`, true},
+		{`true`, `false`, false},
+		{`true`, `{}`, false},
+		{`{ "type": "string", "minLength": 1 }`, `{ "minLength": 1, "type": "string" }`, true},
+		{`{ "type": "string", "$comment": "a" }`, `{ "type": "string", "description": "b" }`, true},
+		{`{ "type": "string" }`, `{ "type": "integer" }`, false},
+		{`{ "properties": { "a": { "description": "one", "type": "string" } } }`,
+			`{ "properties": { "a": { "type": "string" } } }`, true},
+		{`{ "properties": { "description": { "type": "string" } } }`, `{ "properties": {} }`, false},
+		{`{ "maximum": 2 }`, `{ "maximum": 2.0 }`, true},
+		{`{ "title": "a" }`, `{ "title": "b" }`, false},
+		{`{ "type": ["string", "null"] }`, `{ "type": ["null", "string"] }`, true},
+		{`{ "required": ["a", "b"] }`, `{ "required": ["b", "a", "b"] }`, true},
+		{`{ "x-tag": [1, 2] }`, `{ "x-tag": [2, 1] }`, false},
+	}
+
+	for i, c := range cases {
+		a, b := Must(c.a), Must(c.b)
+		if got := Equal(&a.Schema, &b.Schema); got != c.expect {
+			t.Errorf("case %d: expected %t, got: %t", i, c.expect, got)
+		}
+	}
+
+	if Equal(nil, &Schema{}) {
+		t.Errorf("expected nil schema to be unequal to an empty schema")
+	}
+}
@@ -0,0 +1,126 @@
+package jsonschema
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Evaluated holds the properties and array items of an instance that were
+// successfully evaluated, the locations "unevaluatedProperties" and
+// "unevaluatedItems" leave alone. A property is evaluated by "properties",
+// "patternProperties" or "additionalProperties", and an item by "items" or
+// "additionalItems", of a schema that passes, including the schemas
+// "allOf", "anyOf", "oneOf", "if", "then", "else", "dependencies" and
+// references apply in place. Schemas that fail, like every branch of
+// "not", evaluate nothing
+type Evaluated struct {
+	// Properties maps the JSON pointer of each object to the sorted names
+	// of its evaluated properties
+	Properties map[string][]string `json:"properties"`
+	// Items maps the JSON pointer of each array to the sorted indexes of its
+	// evaluated items
+	Items map[string][]int `json:"items"`
+}
+
+// ValidateEvaluated validates data, giving its errors along with the
+// properties and items validation evaluated. Pointers are written as they
+// are in errors, "/" being the whole instance. Evaluation is found from a
+// trace, see ValidateTrace
+func (rs *RootSchema) ValidateEvaluated(data interface{}) ([]ValError, *Evaluated) {
+	errs := []ValError{}
+	rs.Validate("/", data, &errs)
+	return errs, rs.ValidateTrace(data).Evaluated()
+}
+
+// Evaluated gives the properties and items the traced validation evaluated
+func (t *Trace) Evaluated() *Evaluated {
+	props := map[string]map[string]bool{}
+	items := map[string]map[int]bool{}
+	collectEvaluated(t, props, items)
+
+	ev := &Evaluated{Properties: map[string][]string{}, Items: map[string][]int{}}
+	for ptr, names := range props {
+		for name := range names {
+			ev.Properties[ptr] = append(ev.Properties[ptr], name)
+		}
+		sort.Strings(ev.Properties[ptr])
+	}
+	for ptr, idxs := range items {
+		for i := range idxs {
+			ev.Items[ptr] = append(ev.Items[ptr], i)
+		}
+		sort.Ints(ev.Items[ptr])
+	}
+	return ev
+}
+
+// Unevaluated lists the JSON pointers of the properties and items of data
+// that weren't evaluated, in document order with object keys sorted. The
+// members of an unevaluated value aren't listed separately
+func (e *Evaluated) Unevaluated(data interface{}) []string {
+	res := []string{}
+	collectUnevaluated(e, "/", data, &res)
+	return res
+}
+
+// collectEvaluated adds the properties and items the schema trace t
+// evaluated to props and items, when the schema passed
+func collectEvaluated(t *Trace, props map[string]map[string]bool, items map[string]map[int]bool) {
+	if !t.Valid {
+		return
+	}
+	for _, kw := range t.Children {
+		for _, ch := range kw.Children {
+			if !ch.Valid {
+				continue
+			}
+			switch kw.Keyword {
+			case "properties", "patternProperties", "additionalProperties":
+				if props[kw.InstancePath] == nil {
+					props[kw.InstancePath] = map[string]bool{}
+				}
+				props[kw.InstancePath][ch.member] = true
+			case "items", "additionalItems":
+				if items[kw.InstancePath] == nil {
+					items[kw.InstancePath] = map[int]bool{}
+				}
+				i, _ := strconv.Atoi(ch.member)
+				items[kw.InstancePath][i] = true
+			}
+			collectEvaluated(ch, props, items)
+		}
+	}
+}
+
+// collectUnevaluated appends the pointers of the members of data at ptr
+// that e doesn't hold to res, descending into those it does
+func collectUnevaluated(e *Evaluated, ptr string, data interface{}, res *[]string) {
+	switch t := data.(type) {
+	case map[string]interface{}:
+		evaluated := map[string]bool{}
+		for _, name := range e.Properties[ptr] {
+			evaluated[name] = true
+		}
+		for _, key := range sortedMapKeys(t) {
+			child := traceInstancePath(ptr, key)
+			if !evaluated[key] {
+				*res = append(*res, child)
+				continue
+			}
+			collectUnevaluated(e, child, t[key], res)
+		}
+	case []interface{}:
+		evaluated := map[int]bool{}
+		for _, i := range e.Items[ptr] {
+			evaluated[i] = true
+		}
+		for i, elem := range t {
+			child := traceInstancePath(ptr, strconv.Itoa(i))
+			if !evaluated[i] {
+				*res = append(*res, child)
+				continue
+			}
+			collectUnevaluated(e, child, elem, res)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateEvaluated(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"name": {"type": "string"},
+			"owner": {"properties": {"id": {"type": "integer"}}}
+		},
+		"patternProperties": {"^x-": {}},
+		"anyOf": [
+			{"properties": {"kind": {"const": "a"}, "a": {}}},
+			{"properties": {"kind": {"const": "b"}, "b": {}}}
+		],
+		"not": {"properties": {"hidden": {}}, "required": ["hidden"]},
+		"dependencies": {"tags": {"properties": {"tags": {"items": [{}, {}]}}}}
+	}`)
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"name": "n",
+		"owner": {"id": 1, "email": "e"},
+		"x-trace": true,
+		"kind": "b",
+		"a": 1,
+		"b": 2,
+		"tags": ["one", "two", "three"],
+		"extra": {"nested": true}
+	}`), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	errs, ev := rs.ValidateEvaluated(doc)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	expect := &Evaluated{
+		Properties: map[string][]string{
+			"/":      {"b", "kind", "name", "owner", "tags", "x-trace"},
+			"/owner": {"id"},
+		},
+		Items: map[string][]int{
+			"/tags": {0, 1},
+		},
+	}
+	if !reflect.DeepEqual(ev, expect) {
+		t.Errorf("expected %v, got %v", expect, ev)
+	}
+
+	unevaluated := []string{"/a", "/extra", "/owner/email", "/tags/2"}
+	if got := ev.Unevaluated(doc); !reflect.DeepEqual(got, unevaluated) {
+		t.Errorf("expected unevaluated %v, got %v", unevaluated, got)
+	}
+}
+
+func TestEvaluatedFailingSchemas(t *testing.T) {
+	rs := Must(`{
+		"properties": {"a": {"type": "string"}, "b": {}}
+	}`)
+	doc := map[string]interface{}{"a": 1.0, "b": true}
+	errs, ev := rs.ValidateEvaluated(doc)
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+	if len(ev.Properties) != 0 || len(ev.Items) != 0 {
+		t.Errorf("expected a failing schema to evaluate nothing, got %v", ev)
+	}
+	if got := ev.Unevaluated(doc); !reflect.DeepEqual(got, []string{"/a", "/b"}) {
+		t.Errorf("expected every property unevaluated, got %v", got)
+	}
+}
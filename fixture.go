@@ -0,0 +1,151 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Fixture is a schema with instances that should or shouldn't validate
+// against it, letting schema authors keep regression tests next to their
+// schemas. In JSON a fixture looks like:
+//
+//	{
+//	  "schema": "person.json",
+//	  "tests": [
+//	    {"description": "minimal", "instance": {"name": "Ann"}, "valid": true},
+//	    {
+//	      "description": "age must be a number",
+//	      "instance": {"name": "Ann", "age": "old"},
+//	      "valid": false,
+//	      "expectedErrors": [{"propertyPath": "/age", "message": "type should be"}]
+//	    }
+//	  ]
+//	}
+//
+// where "schema" is either a schema or the path to one, relative to the
+// fixture file
+type Fixture struct {
+	Schema *RootSchema
+	Tests  []FixtureTest
+}
+
+// FixtureTest is an instance of a fixture and its expected outcome
+type FixtureTest struct {
+	Description string      `json:"description"`
+	Instance    interface{} `json:"instance"`
+	Valid       bool        `json:"valid"`
+	// ExpectedErrors must each match an error of the instance, which makes
+	// it invalid whatever Valid says. More errors than expected are fine
+	ExpectedErrors []ExpectedError `json:"expectedErrors,omitempty"`
+}
+
+// ExpectedError matches validation errors. Empty fields match any error
+type ExpectedError struct {
+	// PropertyPath must equal the error's property path
+	PropertyPath string `json:"propertyPath,omitempty"`
+	// Message must be part of the error's message
+	Message string `json:"message,omitempty"`
+}
+
+// FixtureFailure is a fixture test with an unexpected outcome
+type FixtureFailure struct {
+	// Test is the index of the test within the fixture
+	Test        int    `json:"test"`
+	Description string `json:"description"`
+	Message     string `json:"message"`
+}
+
+// Error implements the error interface for FixtureFailure
+func (f FixtureFailure) Error() string {
+	return fmt.Sprintf("test %d (%s): %s", f.Test, f.Description, f.Message)
+}
+
+// LoadFixture reads a fixture from a file, loading its schema from the
+// path it names if it isn't given inline
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw := struct {
+		Schema json.RawMessage `json:"schema"`
+		Tests  []FixtureTest   `json:"tests"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %s", path, err.Error())
+	}
+	if len(raw.Schema) == 0 {
+		return nil, fmt.Errorf("%s: fixture has no schema", path)
+	}
+
+	schemaData := []byte(raw.Schema)
+	var schemaPath string
+	if json.Unmarshal(raw.Schema, &schemaPath) == nil {
+		if !filepath.IsAbs(schemaPath) {
+			schemaPath = filepath.Join(filepath.Dir(path), schemaPath)
+		}
+		if schemaData, err = ioutil.ReadFile(schemaPath); err != nil {
+			return nil, err
+		}
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(schemaData, rs); err != nil {
+		return nil, fmt.Errorf("%s: parsing schema: %s", path, err.Error())
+	}
+	return &Fixture{Schema: rs, Tests: raw.Tests}, nil
+}
+
+// Run validates the instances of f, returning the tests that failed
+func (f *Fixture) Run() []FixtureFailure {
+	failures := []FixtureFailure{}
+	for i, test := range f.Tests {
+		errs := []ValError{}
+		f.Schema.Validate("/", test.Instance, &errs)
+		fail := func(msg string) {
+			failures = append(failures, FixtureFailure{Test: i, Description: test.Description, Message: msg})
+		}
+
+		valid := test.Valid && len(test.ExpectedErrors) == 0
+		switch {
+		case valid && len(errs) > 0:
+			msgs := make([]string, len(errs))
+			for j, e := range errs {
+				msgs[j] = e.Error()
+			}
+			fail("expected the instance to be valid, got errors: " + strings.Join(msgs, "; "))
+			continue
+		case !valid && len(errs) == 0:
+			fail("expected the instance to be invalid")
+			continue
+		}
+		for _, expect := range test.ExpectedErrors {
+			if !expect.matchesAny(errs) {
+				fail(fmt.Sprintf("expected an error matching %s", expect))
+			}
+		}
+	}
+	return failures
+}
+
+func (e ExpectedError) matchesAny(errs []ValError) bool {
+	for _, err := range errs {
+		if (e.PropertyPath == "" || e.PropertyPath == err.PropertyPath) && strings.Contains(err.Message, e.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements the stringer interface for ExpectedError
+func (e ExpectedError) String() string {
+	switch {
+	case e.PropertyPath != "" && e.Message != "":
+		return fmt.Sprintf("%s: %q", e.PropertyPath, e.Message)
+	case e.PropertyPath != "":
+		return e.PropertyPath
+	}
+	return fmt.Sprintf("%q", e.Message)
+}
@@ -0,0 +1,62 @@
+package jsonschema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFixture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"person.json": `{
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "age": {"type": "integer"}},
+			"required": ["name"]
+		}`,
+		"person.fixture.json": `{
+			"schema": "person.json",
+			"tests": [
+				{"description": "minimal", "instance": {"name": "Ann"}, "valid": true},
+				{"description": "bad age", "instance": {"name": "Ann", "age": "old"}, "expectedErrors": [{"propertyPath": "/age", "message": "type should be"}]},
+				{"description": "wrongly valid", "instance": {}, "valid": true},
+				{"description": "wrongly invalid", "instance": {"name": "Bob"}, "valid": false},
+				{"description": "other error", "instance": {"age": 1}, "expectedErrors": [{"propertyPath": "/age"}]}
+			]
+		}`,
+		"inline.fixture.json": `{
+			"schema": {"maximum": 3},
+			"tests": [{"description": "too big", "instance": 4, "valid": false}]
+		}`,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := LoadFixture(filepath.Join(dir, "person.fixture.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := []int{}
+	for _, failure := range f.Run() {
+		failed = append(failed, failure.Test)
+	}
+	if expect := []int{2, 3, 4}; !reflect.DeepEqual(failed, expect) {
+		t.Errorf("expected tests %v to fail, got %v", expect, failed)
+	}
+
+	if f, err = LoadFixture(filepath.Join(dir, "inline.fixture.json")); err != nil {
+		t.Fatal(err)
+	}
+	if failures := f.Run(); len(failures) != 0 {
+		t.Errorf("expected inline fixture to pass, got %v", failures)
+	}
+}
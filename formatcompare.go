@@ -0,0 +1,275 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatComparators order the values of formats for formatMinimum and its
+// related keywords, giving -1, 0 or 1 as a is less than, equal to or
+// greater than b, or an error if either isn't a valid value. Values of the
+// "date-time", "date" and "time" formats are ordered as the time options
+// of the schema read them, unless a comparator is registered for them
+var FormatComparators = map[string]func(a, b string) (int, error){
+	"semver": compareSemver,
+}
+
+// FormatLimit is formatMinimum, formatMaximum, formatExclusiveMinimum or
+// formatExclusiveMaximum, as ajv-formats defines them: a bound on string
+// instances compared as values of the sibling "format", such as a date
+// that must be after 2020-01-01. Instances that aren't valid values of the
+// format are left to it
+type FormatLimit struct {
+	Bound     string
+	maximum   bool
+	exclusive bool
+	format    *Format
+}
+
+// NewFormatMinimum allocates a new formatMinimum validator
+func NewFormatMinimum() Validator {
+	return &FormatLimit{}
+}
+
+// NewFormatMaximum allocates a new formatMaximum validator
+func NewFormatMaximum() Validator {
+	return &FormatLimit{maximum: true}
+}
+
+// NewFormatExclusiveMinimum allocates a new formatExclusiveMinimum validator
+func NewFormatExclusiveMinimum() Validator {
+	return &FormatLimit{exclusive: true}
+}
+
+// NewFormatExclusiveMaximum allocates a new formatExclusiveMaximum validator
+func NewFormatExclusiveMaximum() Validator {
+	return &FormatLimit{maximum: true, exclusive: true}
+}
+
+// Validate implements the Validator interface for FormatLimit
+func (f FormatLimit) Validate(propPath string, data interface{}, errs *[]ValError) {
+	f.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (f FormatLimit) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
+	str, ok := data.(string)
+	if !ok || f.format == nil {
+		return
+	}
+	compare, ok := FormatComparators[string(*f.format)]
+	if !ok {
+		switch format := string(*f.format); format {
+		case "date-time", "date", "time":
+			compare = compareFormatTimes(vd.options().Formats.Time, format)
+		default:
+			return
+		}
+	}
+	if _, err := compare(f.Bound, f.Bound); err != nil {
+		AddError(errs, propPath, data, fmt.Sprintf("%s bound %q isn't a valid %s", f.keyword(), f.Bound, *f.format))
+		return
+	}
+	c, err := compare(str, f.Bound)
+	if err != nil {
+		return
+	}
+
+	switch {
+	case f.maximum && f.exclusive && c >= 0:
+		AddError(errs, propPath, data, fmt.Sprintf("must be before %s", f.Bound))
+	case f.maximum && !f.exclusive && c > 0:
+		AddError(errs, propPath, data, fmt.Sprintf("must be %s or before", f.Bound))
+	case !f.maximum && f.exclusive && c <= 0:
+		AddError(errs, propPath, data, fmt.Sprintf("must be after %s", f.Bound))
+	case !f.maximum && !f.exclusive && c < 0:
+		AddError(errs, propPath, data, fmt.Sprintf("must be %s or after", f.Bound))
+	}
+}
+
+// keyword gives the name of the keyword
+func (f FormatLimit) keyword() string {
+	name := "Minimum"
+	if f.maximum {
+		name = "Maximum"
+	}
+	if f.exclusive {
+		return "formatExclusive" + name
+	}
+	return "format" + name
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for FormatLimit
+func (f *FormatLimit) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &f.Bound)
+}
+
+// MarshalJSON implements the json.Marshaler interface for FormatLimit
+func (f FormatLimit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Bound)
+}
+
+// compareFormatTimes gives the comparator of the date and time formats,
+// which reads values as validation with the options o does
+func compareFormatTimes(o TimeFormatOptions, format string) func(a, b string) (int, error) {
+	layout := map[string]string{"date-time": time.RFC3339, "date": "2006-01-02", "time": "15:04:05Z07:00"}[format]
+	parse := func(str string) (time.Time, error) {
+		t, err := time.Parse(layout, str)
+		if err != nil {
+			var ok bool
+			if t, ok = o.parse(format, str); !ok {
+				return t, fmt.Errorf("invalid %s %q", format, str)
+			}
+		}
+		return t, nil
+	}
+	return func(a, b string) (int, error) {
+		ta, err := parse(a)
+		if err != nil {
+			return 0, err
+		}
+		tb, err := parse(b)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case ta.Before(tb):
+			return -1, nil
+		case ta.After(tb):
+			return 1, nil
+		}
+		return 0, nil
+	}
+}
+
+// compareSemver orders semantic versions by their precedence, which
+// ignores build metadata
+// https://semver.org/#spec-item-11
+func compareSemver(a, b string) (int, error) {
+	va, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < 3; i++ {
+		if c := compareSemverNumbers(va[i], vb[i]); c != 0 {
+			return c, nil
+		}
+	}
+
+	// a version with pre-release identifiers precedes the release
+	switch {
+	case len(va) == 3 && len(vb) == 3:
+		return 0, nil
+	case len(va) == 3:
+		return 1, nil
+	case len(vb) == 3:
+		return -1, nil
+	}
+	for i := 3; i < len(va) && i < len(vb); i++ {
+		numA, numB := isSemverNumber(va[i]), isSemverNumber(vb[i])
+		var c int
+		switch {
+		case numA && numB:
+			c = compareSemverNumbers(va[i], vb[i])
+		case numA:
+			c = -1
+		case numB:
+			c = 1
+		default:
+			c = strings.Compare(va[i], vb[i])
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	switch {
+	case len(va) < len(vb):
+		return -1, nil
+	case len(va) > len(vb):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// parseSemver splits a semantic version into its major, minor and patch
+// numbers followed by its pre-release identifiers
+func parseSemver(str string) ([]string, error) {
+	version := str
+	if i := strings.IndexByte(version, '+'); i != -1 {
+		if !validSemverIdentifiers(version[i+1:], false) {
+			return nil, fmt.Errorf("invalid semver %q", str)
+		}
+		version = version[:i]
+	}
+	pre := ""
+	if i := strings.IndexByte(version, '-'); i != -1 {
+		version, pre = version[:i], version[i+1:]
+		if !validSemverIdentifiers(pre, true) {
+			return nil, fmt.Errorf("invalid semver %q", str)
+		}
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid semver %q", str)
+	}
+	for _, part := range parts {
+		if !isSemverNumber(part) {
+			return nil, fmt.Errorf("invalid semver %q", str)
+		}
+	}
+	if pre != "" {
+		parts = append(parts, strings.Split(pre, ".")...)
+	}
+	return parts, nil
+}
+
+// validSemverIdentifiers reports whether str is a dot-separated list of
+// alphanumerics and hyphens. Numeric pre-release identifiers can't have
+// leading zeros
+func validSemverIdentifiers(str string, pre bool) bool {
+	for _, id := range strings.Split(str, ".") {
+		if id == "" {
+			return false
+		}
+		numeric := true
+		for i := 0; i < len(id); i++ {
+			if !isAlphaNum(id[i]) && id[i] != '-' {
+				return false
+			}
+			numeric = numeric && id[i] >= '0' && id[i] <= '9'
+		}
+		if pre && numeric && !isSemverNumber(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSemverNumber reports whether str is a number without leading zeros
+func isSemverNumber(str string) bool {
+	if str == "" || (len(str) > 1 && str[0] == '0') {
+		return false
+	}
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareSemverNumbers orders numbers without leading zeros, however long
+func compareSemverNumbers(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFormatLimits(t *testing.T) {
+	RegisterAjvKeywords()
+	defer func() {
+		for name := range AjvKeywords {
+			delete(DefaultValidators, name)
+		}
+	}()
+
+	rs := Must(`{
+		"properties": {
+			"since": {"format": "date", "formatMinimum": "2020-01-01"},
+			"at": {"format": "date-time", "formatExclusiveMaximum": "2020-01-01T00:00:00Z"},
+			"opens": {"format": "time", "formatExclusiveMinimum": "09:00:00+02:00", "formatMaximum": "17:00:00+02:00"},
+			"version": {"format": "semver", "formatMinimum": "1.2.0-beta.2", "formatExclusiveMaximum": "2.0.0"},
+			"bad": {"format": "date", "formatMinimum": "soon"},
+			"name": {"formatMinimum": "b"}
+		}
+	}`)
+
+	cases := []struct {
+		doc    string
+		expect []string
+	}{
+		{`{"since": "2020-01-01", "at": "2019-12-31T23:59:59Z", "opens": "07:30:00Z", "version": "1.2.0-beta.11", "name": "a"}`, nil},
+		{`{"since": "2019-12-31"}`, []string{"/since: must be 2020-01-01 or after"}},
+		{`{"since": "someday"}`, []string{`/since: invalid date: date-time incorrectly Formatted: parsing time "somedayT08:30:06.283185Z" as "2006-01-02T15:04:05Z07:00": cannot parse "somedayT08:30:06.283185Z" as "2006"`}},
+		{`{"at": "2020-01-01T01:00:00+01:00"}`, []string{"/at: must be before 2020-01-01T00:00:00Z"}},
+		{`{"opens": "07:00:00Z"}`, []string{"/opens: must be after 09:00:00+02:00"}},
+		{`{"opens": "15:00:01Z"}`, []string{"/opens: must be 17:00:00+02:00 or before"}},
+		{`{"version": "1.2.0-beta.1"}`, []string{"/version: must be 1.2.0-beta.2 or after"}},
+		{`{"version": "1.2.0-alpha"}`, []string{"/version: must be 1.2.0-beta.2 or after"}},
+		{`{"version": "2.0.0-rc.1+build.5"}`, nil},
+		{`{"version": "2.0.0+build.5"}`, []string{"/version: must be before 2.0.0"}},
+		{`{"bad": "2020-01-01"}`, []string{`/bad: formatMinimum bound "soon" isn't a valid date`}},
+	}
+	for _, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.PropertyPath+": "+e.Message)
+		}
+		sort.Strings(got)
+		if strings.Join(got, "\n") != strings.Join(c.expect, "\n") {
+			t.Errorf("%s: expected errors %q, got %q", c.doc, c.expect, got)
+		}
+	}
+
+	data, err := json.Marshal(rs.Clone())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"formatExclusiveMaximum":"2.0.0"`) {
+		t.Errorf("expected the bounds to be encoded, got %s", data)
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.10.0", "10.0.0"}
+	for i := range ordered {
+		for j := range ordered {
+			c, err := compareSemver(ordered[i], ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := 0
+			if i < j {
+				expect = -1
+			} else if i > j {
+				expect = 1
+			}
+			if c != expect {
+				t.Errorf("comparing %s and %s: expected %d, got %d", ordered[i], ordered[j], expect, c)
+			}
+		}
+	}
+
+	for _, invalid := range []string{"1.0", "01.0.0", "1.0.0-01", "1.0.0-", "1.0.0+", "1.0.0-a..b", "v1.0.0", "1.0.0-a_b"} {
+		if _, err := compareSemver(invalid, "1.0.0"); err == nil {
+			t.Errorf("expected %q to be an invalid version", invalid)
+		}
+	}
+}
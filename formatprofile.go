@@ -0,0 +1,102 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatOptions are the options of the built-in formats that can be
+// configured, set together by format profiles
+type FormatOptions struct {
+	Time     TimeFormatOptions
+	UUID     UUIDFormatOptions
+	Email    EmailFormatOptions
+	Hostname HostnameFormatOptions
+	IP       IPFormatOptions
+	URI      URIFormatOptions
+	Regex    RegexFormatOptions
+}
+
+// FormatProfiles are named settings of the built-in formats, so they can
+// match a policy with one switch:
+//
+// "spec-strict" reads formats as the RFCs the specification refers to
+// define them, as happens by default.
+//
+// "lenient" accepts the variations people commonly write: date-times
+// without a "T" or a time zone, UUIDs in braces, email addresses with any
+// local part, fully qualified host names and those with underscores, IPv4
+// parts with leading zeros, IPv6 addresses with zones, URIs and IRIs with
+// unencoded spaces and RE2 patterns.
+//
+// "html5" reads formats as the matching inputs of browsers do:
+// date-times without a time zone or seconds, as of datetime-local inputs,
+// email addresses as of email inputs, URIs as of url inputs, which encode
+// spaces themselves, and host names as URL parsing reads them. Patterns
+// are ECMA 262, as of the pattern attribute.
+//
+// Formats the profiles don't mention read the same in all of them
+var FormatProfiles = map[string]FormatOptions{
+	"spec-strict": {},
+	"lenient": {
+		Time:     TimeFormatOptions{Lenient: true},
+		UUID:     UUIDFormatOptions{AllowBraces: true},
+		Email:    EmailFormatOptions{Profile: EmailSimple},
+		Hostname: HostnameFormatOptions{TrailingDot: true, Underscores: true},
+		IP:       IPFormatOptions{LeadingZeros: true, Zones: true},
+		URI:      URIFormatOptions{Lenient: true},
+		Regex:    RegexFormatOptions{AllowRE2: true},
+	},
+	"html5": {
+		Time: TimeFormatOptions{
+			Lenient: true,
+			Layouts: map[string][]string{"date-time": {"2006-01-02T15:04", "2006-01-02 15:04"}},
+		},
+		Email:    EmailFormatOptions{Profile: EmailHTML5},
+		Hostname: HostnameFormatOptions{TrailingDot: true, Underscores: true},
+		URI:      URIFormatOptions{Lenient: true},
+	},
+}
+
+// FormatProfile gives the options of the profile name, one of
+// FormatProfiles
+func FormatProfile(name string) (FormatOptions, error) {
+	opts, ok := FormatProfiles[name]
+	if !ok {
+		names := make([]string, 0, len(FormatProfiles))
+		for n := range FormatProfiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return FormatOptions{}, fmt.Errorf("unknown format profile %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return opts.clone(), nil
+}
+
+// UseFormatProfile sets the options of the built-in formats of rs to those
+// of the profile name, one of FormatProfiles, keeping its other options
+func (rs *RootSchema) UseFormatProfile(name string) error {
+	formats, err := FormatProfile(name)
+	if err != nil {
+		return err
+	}
+	opts := rs.Options()
+	opts.Formats = formats
+	rs.SetOptions(opts)
+	return nil
+}
+
+// clone copies o, including its layouts and versions, so that registering
+// more of them in the copy leaves o as it is
+func (o FormatOptions) clone() FormatOptions {
+	if o.Time.Layouts != nil {
+		layouts := make(map[string][]string, len(o.Time.Layouts))
+		for format, l := range o.Time.Layouts {
+			layouts[format] = append([]string(nil), l...)
+		}
+		o.Time.Layouts = layouts
+	}
+	o.UUID.Versions = append([]int(nil), o.UUID.Versions...)
+	return o
+}
@@ -0,0 +1,70 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestUseFormatProfile(t *testing.T) {
+	cases := []struct {
+		format, value          string
+		strict, lenient, html5 bool
+	}{
+		{"date-time", "2020-01-02T15:04:05Z", true, true, true},
+		{"date-time", "2020-01-02 15:04:05", false, true, true},
+		{"date-time", "2020-01-02T15:04", false, false, true},
+		{"time", "15:04", false, true, true},
+		{"uuid", "{123e4567-e89b-12d3-a456-426614174000}", false, true, false},
+		{"email", "joe@example.com", true, true, true},
+		{"email", `"joe bloggs"@example.com`, true, false, false},
+		{"email", "joe..bloggs@example.com", false, true, true},
+		{"email", "joe@[127.0.0.1]", true, false, false},
+		{"email", "joe@localhost", true, true, true},
+		{"email", "joe@ab--cd.example", false, false, true},
+		{"hostname", "example.com.", false, true, true},
+		{"hostname", "_dmarc.example.com", false, true, true},
+		{"hostname", "-a.example.com", false, false, false},
+		{"ipv4", "192.168.01.1", false, true, false},
+		{"ipv6", "fe80::1%eth0", false, true, false},
+		{"ipv6", "fe80::1%", false, false, false},
+		{"uri", "https://example.com/a b?q={x}", false, true, true},
+		{"uri-reference", "a b", false, true, true},
+		{"iri", "https://例え.jp/a b", false, true, true},
+		{"uri", "https://exa mple.com:port/", false, false, false},
+		{"regex", "(?i)abc", false, true, false},
+		{"regex", "(?<=a)b", true, true, true},
+		{"regex", "(", false, false, false},
+	}
+	for _, profile := range []string{"spec-strict", "lenient", "html5"} {
+		for _, c := range cases {
+			rs := Must(`{"format": "` + c.format + `"}`)
+			if err := rs.UseFormatProfile(profile); err != nil {
+				t.Fatal(err)
+			}
+			expect := map[string]bool{"spec-strict": c.strict, "lenient": c.lenient, "html5": c.html5}[profile]
+			errs := []ValError{}
+			rs.Validate("/", c.value, &errs)
+			if valid := len(errs) == 0; valid != expect {
+				t.Errorf("%s %s %q: expected valid to be %t, got %v", profile, c.format, c.value, expect, errs)
+			}
+		}
+	}
+
+	rs := Must(`{"format": "date-time"}`)
+	rs.SetOptions(Options{Parsing: ParseOptions{Strict: true}})
+	if err := rs.UseFormatProfile("loose"); err == nil || err.Error() != `unknown format profile "loose", expected one of html5, lenient, spec-strict` {
+		t.Errorf("expected unknown profile error, got %v", err)
+	}
+
+	if !rs.Options().Parsing.Strict {
+		t.Errorf("expected a profile to leave the other options as they are")
+	}
+
+	opts, err := FormatProfile("html5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Time.RegisterLayout("date-time", "Jan 2 2006")
+	if layouts := FormatProfiles["html5"].Time.Layouts["date-time"]; len(layouts) != 2 {
+		t.Errorf("expected registering a layout to leave the profile as it is, got %v", layouts)
+	}
+}
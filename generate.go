@@ -0,0 +1,630 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// InstanceGenerator produces synthetic instances that are valid against
+// schemas, for test fixtures and documentation examples. Generation is
+// deterministic for a given seed and schema
+type InstanceGenerator struct {
+	// MaxDepth limits nesting. Past it arrays and objects only get the
+	// items and properties they require
+	MaxDepth int
+	// Attempts is how many candidates are generated for each schema before
+	// giving up. Constraints like "not", "oneOf", "uniqueItems" and patterns
+	// with length bounds are satisfied by trial and error
+	Attempts int
+
+	rand *rand.Rand
+	// budget counts down the candidates left for the current instance, so
+	// schemas that nest attempts within attempts fail in reasonable time
+	budget int
+}
+
+// NewInstanceGenerator creates a generator with a random number generator
+// seeded by seed
+func NewInstanceGenerator(seed int64) *InstanceGenerator {
+	return &InstanceGenerator{
+		MaxDepth: 6,
+		Attempts: 20,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Generate creates an instance that's valid against s, decoded the way
+// encoding/json decodes into an interface{}: numbers are float64s, arrays
+// []interface{} and objects map[string]interface{}. Enums and consts are
+// chosen from, types, formats, patterns, numeric and length bounds are
+// respected, and composition keywords are satisfied by picking branches.
+// It returns an error if no valid instance was found
+func (g *InstanceGenerator) Generate(s *Schema) (interface{}, error) {
+	g.budget = g.Attempts * 500
+	return g.generate(s, 0)
+}
+
+// generate makes candidates for s until one is valid
+func (g *InstanceGenerator) generate(s *Schema, depth int) (interface{}, error) {
+	if s == nil {
+		s = trueSchema
+	}
+	var err error
+	for i := 0; i < g.Attempts; i++ {
+		var v interface{}
+		if v, err = g.candidate(s, depth); err != nil {
+			continue
+		}
+		if isValid(s, v) {
+			return v, nil
+		}
+		err = fmt.Errorf("no valid instance found in %d attempts", g.Attempts)
+	}
+	return nil, err
+}
+
+// candidate makes an instance that satisfies the keywords of s it can
+// account for, which the caller checks against s
+func (g *InstanceGenerator) candidate(s *Schema, depth int) (interface{}, error) {
+	if g.budget--; g.budget < 0 {
+		return nil, fmt.Errorf("gave up generating an instance after too many attempts")
+	}
+	if depth > g.MaxDepth*4 {
+		return nil, fmt.Errorf("schema nests too deeply to generate an instance")
+	}
+	if s.Ref != "" {
+		target, ok := resolveSchema(s)
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference: %s", s.Ref)
+		}
+		return g.candidate(target, depth+1)
+	}
+	switch {
+	case isFalseSchema(s):
+		return nil, fmt.Errorf("schema accepts no instances")
+	case isTrueSchema(s):
+		return g.anyValue(), nil
+	}
+
+	if vals, ok := enumerable(s); ok {
+		valid := []interface{}{}
+		for _, v := range vals {
+			if isValid(s, v) {
+				valid = append(valid, v)
+			}
+		}
+		if len(valid) == 0 {
+			return nil, fmt.Errorf("no enumerated value is valid")
+		}
+		return valid[g.rand.Intn(len(valid))], nil
+	}
+	if flat := g.flatten(s); flat != nil {
+		return g.candidate(flat, depth+1)
+	}
+
+	types := impliedTypes(s)
+	if depth >= g.MaxDepth && len(types) > 1 {
+		// prefer values that don't nest
+		scalar := []string{}
+		for _, t := range types {
+			if t != "array" && t != "object" {
+				scalar = append(scalar, t)
+			}
+		}
+		if len(scalar) > 0 {
+			types = scalar
+		}
+	}
+	switch types[g.rand.Intn(len(types))] {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return g.rand.Intn(2) == 0, nil
+	case "integer":
+		return g.number(s, true)
+	case "number":
+		return g.number(s, false)
+	case "string":
+		return g.string(s)
+	case "array":
+		return g.array(s, depth)
+	case "object":
+		return g.object(s, depth)
+	}
+	return nil, fmt.Errorf("unknown type")
+}
+
+// flatten folds one composition keyword of s into a schema without it,
+// picking a branch of anyOf, oneOf and if. It returns nil if s has no
+// composition keywords. Keywords that can't be merged are dropped, leaving
+// the caller's validation to catch candidates that violate them
+func (g *InstanceGenerator) flatten(s *Schema) *Schema {
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		cur := without(s, "allOf")
+		for _, branch := range *allOf {
+			cur = combineSchemas(cur, branch)
+		}
+		return cur
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		var branches []*Schema
+		switch t := s.Validators[key].(type) {
+		case *AnyOf:
+			branches = *t
+		case *OneOf:
+			branches = *t
+		default:
+			continue
+		}
+		if len(branches) == 0 {
+			return without(s, key)
+		}
+		return combineSchemas(without(s, key), branches[g.rand.Intn(len(branches))])
+	}
+	if cond, ok := s.Validators["if"].(*If); ok {
+		rest := without(s, "if", "then", "else")
+		if g.rand.Intn(2) == 0 {
+			rest = combineSchemas(rest, &cond.Schema)
+			if cond.Then != nil {
+				rest = combineSchemas(rest, (*Schema)(cond.Then))
+			}
+			return rest
+		}
+		if cond.Else != nil {
+			rest = combineSchemas(rest, (*Schema)(cond.Else))
+		}
+		return rest
+	}
+	if _, ok := s.Validators["not"]; ok {
+		return without(s, "not")
+	}
+	return nil
+}
+
+// combineSchemas merges b into a, following a reference in b
+func combineSchemas(a, b *Schema) *Schema {
+	b, ok := resolveSchema(b)
+	if !ok {
+		return a
+	}
+	merged := mergeSubschemas(a.Clone(), b.Clone())
+	if merged.schemaType != schemaTypeObject {
+		return merged
+	}
+	return without(merged, "allOf")
+}
+
+// impliedTypes gives the types s allows, narrowed to those its
+// type-specific keywords apply to when it has no "type" keyword
+func impliedTypes(s *Schema) []string {
+	if t, ok := s.Validators["type"].(*Type); ok && len(t.vals) > 0 {
+		return t.vals
+	}
+	types := []string{}
+	for _, key := range sortedValidatorKeys(s.Validators) {
+		if t, ok := keywordTypes[key]; ok && !containsType(types, t) {
+			types = append(types, t)
+		}
+	}
+	if s.Format != "" && !containsType(types, "string") {
+		types = append(types, "string")
+	}
+	if len(types) == 0 {
+		return allTypes
+	}
+	return types
+}
+
+// anyValue makes a scalar for schemas that accept anything
+func (g *InstanceGenerator) anyValue() interface{} {
+	switch g.rand.Intn(4) {
+	case 0:
+		return nil
+	case 1:
+		return g.rand.Intn(2) == 0
+	case 2:
+		return float64(g.rand.Intn(100))
+	}
+	return g.letters(1 + g.rand.Intn(8))
+}
+
+// number picks a number within the bounds of s that's a multiple of its
+// multipleOf
+func (g *InstanceGenerator) number(s *Schema, integer bool) (interface{}, error) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	loExcl, hiExcl := false, false
+	if m, ok := s.Validators["minimum"].(*Minimum); ok {
+		lo = float64(*m)
+	}
+	if m, ok := s.Validators["exclusiveMinimum"].(*ExclusiveMinimum); ok && float64(*m) >= lo {
+		lo, loExcl = float64(*m), true
+	}
+	if m, ok := s.Validators["maximum"].(*Maximum); ok {
+		hi = float64(*m)
+	}
+	if m, ok := s.Validators["exclusiveMaximum"].(*ExclusiveMaximum); ok && float64(*m) <= hi {
+		hi, hiExcl = float64(*m), true
+	}
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		lo, hi = 0, 100
+	case math.IsInf(lo, -1):
+		lo = hi - 100
+	case math.IsInf(hi, 1):
+		hi = lo + 100
+	}
+
+	step := 0.0
+	if m, ok := s.Validators["multipleOf"].(*MultipleOf); ok && *m > 0 {
+		step = float64(*m)
+	}
+	if integer && step == 0 {
+		step = 1
+	}
+	if step == 0 {
+		v := lo + g.rand.Float64()*(hi-lo)
+		if rounded := math.Round(v*100) / 100; rounded > lo && rounded < hi {
+			v = rounded
+		}
+		if (loExcl && v <= lo) || (hiExcl && v >= hi) {
+			v = lo + (hi-lo)/2
+		}
+		return v, nil
+	}
+
+	kmin, kmax := math.Ceil(lo/step), math.Floor(hi/step)
+	if loExcl && kmin*step <= lo {
+		kmin++
+	}
+	if hiExcl && kmax*step >= hi {
+		kmax--
+	}
+	if kmin > kmax {
+		return nil, fmt.Errorf("no multiple of %v between %v and %v", step, lo, hi)
+	}
+	if kmax-kmin > 1000 {
+		kmax = kmin + 1000
+	}
+	return (kmin + float64(g.rand.Intn(int(kmax-kmin)+1))) * step, nil
+}
+
+// string makes a string matching the pattern of s, or its format, or a run
+// of letters within its length bounds
+func (g *InstanceGenerator) string(s *Schema) (interface{}, error) {
+	if p, ok := s.Validators["pattern"].(*Pattern); ok {
+		return g.matching((*regexp.Regexp)(p).String())
+	}
+	if str, ok := g.formatted(s.Format); ok {
+		return str, nil
+	}
+	min, max := 0, -1
+	if l, ok := s.Validators["minLength"].(*MinLength); ok {
+		min = int(*l)
+	}
+	if l, ok := s.Validators["maxLength"].(*MaxLength); ok {
+		max = int(*l)
+	}
+	upper := min + 8
+	if max >= 0 && upper > max {
+		upper = max
+	}
+	if upper < min {
+		return nil, fmt.Errorf("maxLength is less than minLength")
+	}
+	return g.letters(min + g.rand.Intn(upper-min+1)), nil
+}
+
+func (g *InstanceGenerator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + g.rand.Intn(26))
+	}
+	return string(b)
+}
+
+// formatted makes a string in a format, reporting false for unknown formats
+func (g *InstanceGenerator) formatted(format string) (string, bool) {
+	t := time.Date(2000+g.rand.Intn(30), time.Month(1+g.rand.Intn(12)), 1+g.rand.Intn(28),
+		g.rand.Intn(24), g.rand.Intn(60), g.rand.Intn(60), 0, time.UTC)
+	word := g.letters(3 + g.rand.Intn(6))
+	switch format {
+	case "date-time":
+		return t.Format(time.RFC3339), true
+	case "date":
+		return t.Format("2006-01-02"), true
+	case "time":
+		return t.Format("15:04:05Z07:00"), true
+	case "duration":
+		return fmt.Sprintf("P%dDT%dH", 1+g.rand.Intn(30), g.rand.Intn(24)), true
+	case "email", "idn-email":
+		return word + "@example.com", true
+	case "hostname", "idn-hostname":
+		return word + ".example.com", true
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", 1+g.rand.Intn(254), g.rand.Intn(256), g.rand.Intn(256), 1+g.rand.Intn(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x:%x", g.rand.Intn(0x10000), g.rand.Intn(0x10000)), true
+	case "uri", "iri":
+		return "https://example.com/" + word, true
+	case "uri-reference", "iri-reference":
+		return "/" + word, true
+	case "uri-template":
+		return "https://example.com/" + word + "/{id}", true
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", g.rand.Uint32(), g.rand.Intn(0x10000), g.rand.Intn(0x1000),
+			0x8000|g.rand.Intn(0x4000), g.rand.Int63n(0x1000000000000)), true
+	case "json-pointer":
+		return "/" + word, true
+	case "relative-json-pointer":
+		return fmt.Sprintf("%d/%s", g.rand.Intn(3), word), true
+	case "regex":
+		return "^" + word + "$", true
+	}
+	return "", false
+}
+
+// matching makes a string matching a regular expression
+func (g *InstanceGenerator) matching(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	buf := &strings.Builder{}
+	if err := g.writeMatch(buf, re.Simplify()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (g *InstanceGenerator) writeMatch(buf *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return fmt.Errorf("pattern matches nothing")
+	case syntax.OpLiteral:
+		buf.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		buf.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		buf.WriteByte(byte('a' + g.rand.Intn(26)))
+	case syntax.OpCapture:
+		return g.writeMatch(buf, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.writeMatch(buf, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return g.writeMatch(buf, re.Sub[g.rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max == -1 || max > min+3 {
+			max = min + 3
+		}
+		for n := min + g.rand.Intn(max-min+1); n > 0; n-- {
+			if err := g.writeMatch(buf, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	}
+	// anchors and empty matches add nothing
+	return nil
+}
+
+// classRune picks a rune from a character class, given as pairs of range
+// bounds, preferring printable ASCII
+func (g *InstanceGenerator) classRune(ranges []rune) rune {
+	printable := [][2]rune{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, [2]rune{lo, hi})
+		}
+	}
+	if len(printable) == 0 {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if unicode.IsPrint(ranges[i]) {
+				return ranges[i]
+			}
+		}
+		return ranges[0]
+	}
+	r := printable[g.rand.Intn(len(printable))]
+	return r[0] + rune(g.rand.Intn(int(r[1]-r[0])+1))
+}
+
+// array makes an array within the item bounds of s, with elements valid
+// against the items that apply to them
+func (g *InstanceGenerator) array(s *Schema, depth int) (interface{}, error) {
+	min, max := 0, -1
+	if m, ok := s.Validators["minItems"].(*MinItems); ok {
+		min = int(*m)
+	}
+	if m, ok := s.Validators["maxItems"].(*MaxItems); ok {
+		max = int(*m)
+	}
+	if items, ok := s.Validators["items"].(*Items); ok && !items.single {
+		if add, ok := s.Validators["additionalItems"].(*AdditionalItems); ok && isFalseSchema(add.Schema) {
+			if max == -1 || max > len(items.Schemas) {
+				max = len(items.Schemas)
+			}
+		}
+	}
+	n := min
+	if depth < g.MaxDepth {
+		n += g.rand.Intn(3)
+	}
+	if max >= 0 && n > max {
+		n = max
+	}
+	unique := false
+	if u, ok := s.Validators["uniqueItems"].(*UniqueItems); ok {
+		unique = bool(*u)
+	}
+
+	arr := []interface{}{}
+	for i := 0; i < n; i++ {
+		var v interface{}
+		var err error
+		for attempt := 0; attempt < g.Attempts; attempt++ {
+			if v, err = g.generate(itemSchema(s, i), depth+1); err != nil {
+				return nil, err
+			}
+			if !unique || !containsValue(arr, v) {
+				break
+			}
+		}
+		arr = append(arr, v)
+	}
+
+	if c, ok := s.Validators["contains"].(*Contains); ok {
+		for _, v := range arr {
+			if isValid((*Schema)(c), v) {
+				return arr, nil
+			}
+		}
+		i := len(arr)
+		if max >= 0 && i >= max {
+			if i == 0 {
+				return nil, fmt.Errorf("array can't hold an item for contains")
+			}
+			i = g.rand.Intn(len(arr))
+		} else {
+			arr = append(arr, nil)
+		}
+		v, err := g.generate(combineSchemas(itemSchema(s, i), (*Schema)(c)), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func containsValue(arr []interface{}, v interface{}) bool {
+	for _, el := range arr {
+		if reflect.DeepEqual(el, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// object makes an object with the required properties of s, some of its
+// optional ones and whatever extra properties it needs to reach
+// "minProperties"
+func (g *InstanceGenerator) object(s *Schema, depth int) (interface{}, error) {
+	keys := []string{}
+	has := map[string]bool{}
+	add := func(key string) {
+		if !has[key] {
+			has[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if req, ok := s.Validators["required"].(*Required); ok {
+		for _, key := range *req {
+			add(key)
+		}
+	}
+	min, max := 0, -1
+	if m, ok := s.Validators["minProperties"].(*minProperties); ok {
+		min = int(*m)
+	}
+	if m, ok := s.Validators["maxProperties"].(*MaxProperties); ok {
+		max = int(*m)
+	}
+	if props, ok := s.Validators["properties"].(*Properties); ok {
+		for _, key := range sortedPropertyKeys(*props) {
+			if isFalseSchema((*props)[key]) {
+				continue
+			}
+			if len(keys) < min || (depth < g.MaxDepth && g.rand.Intn(2) == 0) {
+				add(key)
+			}
+		}
+	}
+	for len(keys) < min {
+		key, err := g.propertyName(s, len(keys))
+		if err != nil {
+			return nil, err
+		}
+		add(key)
+	}
+
+	// properties may depend on others
+	if deps, ok := s.Validators["dependencies"].(*Dependencies); ok {
+		for i := 0; i < len(keys); i++ {
+			if dep, ok := (*deps)[keys[i]]; ok {
+				for _, key := range dep.props {
+					add(key)
+				}
+			}
+		}
+	}
+	if max >= 0 && len(keys) > max {
+		keys = keys[:max]
+	}
+
+	obj := map[string]interface{}{}
+	for _, key := range keys {
+		v, err := g.generate(propertySchema(s, key), depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %s", key, err.Error())
+		}
+		obj[key] = v
+	}
+	return obj, nil
+}
+
+// propertyName makes the name of an extra property, valid against
+// "propertyNames" and matching a pattern property if additional properties
+// aren't allowed
+func (g *InstanceGenerator) propertyName(s *Schema, i int) (string, error) {
+	if names, ok := s.Validators["propertyNames"].(*PropertyNames); ok {
+		v, err := g.generate(combineSchemas((*Schema)(names), &Schema{Validators: map[string]Validator{"type": &Type{vals: []string{"string"}}}}), 1)
+		if err != nil {
+			return "", fmt.Errorf("property names: %s", err.Error())
+		}
+		return v.(string), nil
+	}
+	if add, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && isFalseSchema(add.Schema) {
+		patterns, ok := s.Validators["patternProperties"].(*PatternProperties)
+		if !ok || len(*patterns) == 0 {
+			return "", fmt.Errorf("object needs more properties than it allows")
+		}
+		return g.matching((*patterns)[g.rand.Intn(len(*patterns))].key)
+	}
+	return fmt.Sprintf("property%d", i+1), nil
+}
+
+func sortedPropertyKeys(props Properties) []string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInstanceGenerator(t *testing.T) {
+	schemas := []string{
+		`true`,
+		`{"type": "integer", "minimum": 10, "exclusiveMaximum": 13}`,
+		`{"type": "number", "multipleOf": 0.25, "exclusiveMinimum": 1, "maximum": 2}`,
+		`{"type": "string", "minLength": 3, "maxLength": 5}`,
+		`{"type": "string", "pattern": "^[A-Z]{2}-\\d{3,4}(x|y)?$"}`,
+		`{"type": "string", "format": "date-time"}`,
+		`{"type": "string", "format": "email"}`,
+		`{"type": "string", "format": "ipv4"}`,
+		`{"enum": ["red", "green", 3]}`,
+		`{"const": {"a": [1, 2]}}`,
+		`{"type": "array", "items": {"type": "integer"}, "minItems": 2, "maxItems": 4, "uniqueItems": true}`,
+		`{"type": "array", "items": [{"type": "string"}, {"type": "boolean"}], "additionalItems": false, "minItems": 2}`,
+		`{"type": "array", "contains": {"const": "needle"}, "items": {"type": "string"}}`,
+		`{"oneOf": [{"type": "string"}, {"type": "integer"}]}`,
+		`{"anyOf": [{"type": "null"}, {"type": "string", "maxLength": 2}]}`,
+		`{"allOf": [{"type": "integer"}, {"minimum": 5}, {"maximum": 6}]}`,
+		`{"not": {"type": ["string", "null", "boolean", "object", "array"]}}`,
+		`{"if": {"type": "string"}, "then": {"minLength": 4}, "else": {"type": "integer", "minimum": 1000}}`,
+		`{"type": "object", "propertyNames": {"pattern": "^x[0-9]$"}, "minProperties": 2}`,
+		`{"type": "object", "additionalProperties": false, "patternProperties": {"^n_[a-z]+$": {"type": "number"}}, "minProperties": 1}`,
+		`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "minimum": 1},
+				"name": {"type": "string", "minLength": 1},
+				"email": {"type": "string", "format": "email"},
+				"tags": {"type": "array", "items": {"$ref": "#/definitions/tag"}},
+				"child": {"$ref": "#"}
+			},
+			"required": ["id", "name"],
+			"dependencies": {"email": ["name"]},
+			"additionalProperties": false,
+			"definitions": {
+				"tag": {"type": "string", "enum": ["a", "b", "c"]}
+			}
+		}`,
+	}
+
+	for i, str := range schemas {
+		rs := &RootSchema{}
+		if err := json.Unmarshal([]byte(str), rs); err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		g := NewInstanceGenerator(int64(i))
+		for j := 0; j < 10; j++ {
+			v, err := g.Generate(&rs.Schema)
+			if err != nil {
+				t.Errorf("case %d: %s", i, err)
+				break
+			}
+			if !isValid(&rs.Schema, v) {
+				t.Errorf("case %d: generated instance isn't valid: %v", i, v)
+			}
+		}
+	}
+
+	for _, str := range []string{
+		`{"type": "string", "minLength": 3, "maxLength": 2}`,
+		`{"type": "integer", "multipleOf": 5, "minimum": 1, "maximum": 4}`,
+		`{"properties": {"child": {"$ref": "#"}}, "required": ["child"]}`,
+	} {
+		rs := Must(str)
+		if _, err := NewInstanceGenerator(1).Generate(&rs.Schema); err == nil {
+			t.Errorf("expected an error for a schema without valid instances: %s", str)
+		}
+	}
+
+	// the same seed gives the same instances
+	rs := Must(`{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "number"}, "c": {"type": "array"}}}`)
+	a, _ := NewInstanceGenerator(42).Generate(&rs.Schema)
+	b, _ := NewInstanceGenerator(42).Generate(&rs.Schema)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected equal instances for equal seeds, got %v and %v", a, b)
+	}
+}
@@ -1,5 +1,7 @@
 module github.com/qri-io/jsonschema
 
+go 1.23
+
 require (
 	github.com/qri-io/jsonpointer v0.0.0-20190212172158-7f104febd1fd
 	github.com/sergi/go-diff v1.0.0
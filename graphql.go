@@ -0,0 +1,338 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphQLOptions configures GraphQL schema generation
+type GraphQLOptions struct {
+	// RootName is the name of the type generated for the root schema. If
+	// empty it's derived from the schema title, falling back to "Root"
+	RootName string
+}
+
+// GenerateGraphQL emits GraphQL SDL declaring types for the root schema and
+// each of its top-level definitions, for serving a schema-first JSON API
+// through an equivalent GraphQL layer:
+//
+//   - objects with properties become object types with a field per
+//     property. Required properties that can't be null are non-null
+//   - arrays become lists, non-null within unless their items can be null
+//   - string enums become enums. Values that aren't valid GraphQL names
+//     are changed to be, so they no longer match the JSON strings
+//   - oneOf and anyOf of object types become unions
+//   - strings, numbers and booleans become String, Float and Boolean, and
+//     integers Int, which GraphQL limits to 32 bits
+//   - date-time strings become a DateTime scalar, and anything else, such
+//     as maps or values of several types, a JSON scalar
+//
+// Schemas other than objects, enums and unions are written inline where
+// they're used rather than declared. Keywords without a GraphQL equivalent,
+// like patterns and bounds, are left for validation
+func GenerateGraphQL(rs *RootSchema, opts GraphQLOptions) ([]byte, error) {
+	g := &graphQLGenerator{
+		typeDecls: newTypeDecls(),
+		scalars:   map[string]bool{},
+		active:    map[*Schema]bool{},
+	}
+	for _, name := range []string{"String", "Int", "Float", "Boolean", "ID", "DateTime", "JSON"} {
+		g.names[name] = true
+	}
+	root := opts.RootName
+	if root == "" {
+		root = goName(rs.Title)
+	}
+	if root == "" {
+		root = "Root"
+	}
+
+	if g.declared(&rs.Schema) {
+		if _, err := g.declare(&rs.Schema, root); err != nil {
+			return nil, err
+		}
+	}
+	for _, defs := range []Definitions{rs.Defs, rs.Definitions} {
+		for _, key := range sortedDefinitionKeys(defs) {
+			if !g.declared(defs[key]) {
+				continue
+			}
+			if _, err := g.declare(defs[key], goName(key)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(g.decls) == 0 {
+		return nil, fmt.Errorf("schema has no objects, enums or unions to declare types for")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("# Code generated by jsonschema. DO NOT EDIT.\n")
+	scalars := make([]string, 0, len(g.scalars))
+	for name := range g.scalars {
+		scalars = append(scalars, name)
+	}
+	sort.Strings(scalars)
+	for _, name := range scalars {
+		fmt.Fprintf(buf, "\nscalar %s\n", name)
+	}
+	for _, decl := range g.decls {
+		buf.WriteString("\n")
+		buf.WriteString(decl)
+	}
+	return buf.Bytes(), nil
+}
+
+// graphQLGenerator accumulates type declarations. active holds the
+// references being written inline
+type graphQLGenerator struct {
+	typeDecls
+	scalars map[string]bool
+	active  map[*Schema]bool
+}
+
+// declared reports whether s is given a type of its own
+func (g *graphQLGenerator) declared(s *Schema) bool {
+	if isUnionSchema(s) {
+		return g.unionMembers(s) != nil
+	}
+	return isStringEnum(s) || len(structProperties(s)) > 0
+}
+
+// declare gives s a named type, returning the name
+func (g *graphQLGenerator) declare(s *Schema, hint string) (string, error) {
+	return g.typeDecls.declare(s, hint, func(name string) (string, error) {
+		switch {
+		case isUnionSchema(s):
+			return g.union(s, name)
+		case isStringEnum(s):
+			return g.enum(s, name), nil
+		}
+		return g.object(s, name)
+	}, func(_, description string) string {
+		return graphQLDescription(description, "")
+	})
+}
+
+// typeRef gives a GraphQL type reference for values of s, declaring types
+// nested within it. Types are non-null unless s allows null
+func (g *graphQLGenerator) typeRef(s *Schema, hint string) (string, error) {
+	if s == nil || s.schemaType != schemaTypeObject {
+		return g.scalar("JSON"), nil
+	}
+	if s.Ref != "" {
+		target := refTarget(s)
+		if target == nil {
+			return "", fmt.Errorf("unresolved reference: %s", s.Ref)
+		}
+		if g.declared(target) {
+			name, err := g.declare(target, refTypeName(s.Ref, hint))
+			if _, nullable := nonNullTypes(target); !nullable {
+				name += "!"
+			}
+			return name, err
+		}
+		if g.active[target] {
+			return g.scalar("JSON"), nil
+		}
+		g.active[target] = true
+		defer delete(g.active, target)
+		return g.typeRef(target, hint)
+	}
+
+	types, nullable := nonNullTypes(s)
+	bang := "!"
+	if nullable {
+		bang = ""
+	}
+	if g.declared(s) {
+		name, err := g.declare(s, hint)
+		return name + bang, err
+	}
+	if isEnumSchema(s) {
+		// enums of integers
+		types = []string{"integer"}
+	}
+	if len(types) != 1 {
+		return g.scalar("JSON"), nil
+	}
+	switch types[0] {
+	case "string":
+		if s.Format == "date-time" {
+			return g.scalar("DateTime") + bang, nil
+		}
+		return "String" + bang, nil
+	case "integer":
+		return "Int" + bang, nil
+	case "number":
+		return "Float" + bang, nil
+	case "boolean":
+		return "Boolean" + bang, nil
+	case "array":
+		if items, ok := s.Validators["items"].(*Items); ok && items.single {
+			it, err := g.typeRef(items.Schemas[0], hint+"Item")
+			if err != nil {
+				return "", err
+			}
+			return "[" + it + "]" + bang, nil
+		}
+		return "[" + g.scalar("JSON") + "]" + bang, nil
+	}
+	return g.scalar("JSON"), nil
+}
+
+// scalar gives the name of a custom scalar, declaring it
+func (g *graphQLGenerator) scalar(name string) string {
+	g.scalars[name] = true
+	return name
+}
+
+// structProperties gives the properties of s, including those of its allOf
+// branches and the schemas they refer to. It's nil for schemas that aren't
+// objects
+func structProperties(s *Schema) Properties {
+	if !isStructSchema(s) {
+		return nil
+	}
+	props, _ := collectProperties(s)
+	return props
+}
+
+// object declares an object type with a field for each property of s
+func (g *graphQLGenerator) object(s *Schema, name string) (string, error) {
+	props, required := collectProperties(s)
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "type %s {\n", name)
+	fields := map[string]bool{}
+	for _, key := range keys {
+		prop := props[key]
+		t, err := g.typeRef(prop, name+goName(key))
+		if err != nil {
+			return "", err
+		}
+		if !required[key] {
+			t = strings.TrimSuffix(t, "!")
+		}
+		base := graphQLName(key)
+		field := base
+		for i := 2; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", base, i)
+		}
+		fields[field] = true
+		if prop.Description != "" {
+			buf.WriteString(graphQLDescription(prop.Description, "  "))
+		}
+		fmt.Fprintf(buf, "  %s: %s\n", field, t)
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// unionMembers gives the branches of a oneOf or anyOf other than null, or
+// nil unless each is an object, as GraphQL unions hold only object types
+func (g *graphQLGenerator) unionMembers(s *Schema) []*Schema {
+	var branches []*Schema
+	switch v := s.Validators["oneOf"].(type) {
+	case *OneOf:
+		branches = *v
+	default:
+		branches = *s.Validators["anyOf"].(*AnyOf)
+	}
+	members := []*Schema{}
+	for _, branch := range branches {
+		if types := schemaTypeNames(branch); len(types) == 1 && types[0] == "null" {
+			continue
+		}
+		target := branch
+		if branch.Ref != "" {
+			if target = refTarget(branch); target == nil {
+				return nil
+			}
+		}
+		if isUnionSchema(target) || len(structProperties(target)) == 0 {
+			return nil
+		}
+		members = append(members, branch)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return members
+}
+
+// union declares a union of the object types of each branch of s
+func (g *graphQLGenerator) union(s *Schema, name string) (string, error) {
+	members := []string{}
+	for i, branch := range g.unionMembers(s) {
+		t, err := g.typeRef(branch, fmt.Sprintf("%sOption%d", name, i+1))
+		if err != nil {
+			return "", err
+		}
+		members = append(members, strings.TrimSuffix(t, "!"))
+	}
+	return fmt.Sprintf("union %s = %s\n", name, strings.Join(members, " | ")), nil
+}
+
+// enum declares an enum with a value for each string of s
+func (g *graphQLGenerator) enum(s *Schema, name string) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "enum %s {\n", name)
+	used := map[string]bool{}
+	for i, v := range enumValues(s) {
+		val := graphQLName(v.(string))
+		switch val {
+		case "true", "false", "null":
+			val = strings.ToUpper(val)
+		}
+		if used[val] {
+			val = fmt.Sprintf("VALUE_%d", i+1)
+		}
+		used[val] = true
+		fmt.Fprintf(buf, "  %s\n", val)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// graphQLName turns str into a valid GraphQL name, replacing characters
+// other than letters, digits and underscores
+func graphQLName(str string) string {
+	b := &strings.Builder{}
+	for _, r := range str {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteByte('_')
+	}
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// graphQLDescription formats text as a GraphQL description at the given
+// indent
+func graphQLDescription(text, indent string) string {
+	text = strings.TrimSpace(text)
+	if !strings.Contains(text, "\n") {
+		text = strings.Replace(strings.Replace(text, `\`, `\\`, -1), `"`, `\"`, -1)
+		return indent + `"` + text + "\"\n"
+	}
+	buf := &strings.Builder{}
+	buf.WriteString(indent + "\"\"\"\n")
+	for _, line := range strings.Split(strings.Replace(text, `"""`, `\"""`, -1), "\n") {
+		buf.WriteString(indent + strings.TrimSpace(line) + "\n")
+	}
+	buf.WriteString(indent + "\"\"\"\n")
+	return buf.String()
+}
@@ -0,0 +1,54 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestGenerateGraphQL(t *testing.T) {
+	rs := Must(`{
+		"title": "pet store",
+		"type": "object",
+		"properties": {
+			"id": { "type": "integer" },
+			"name": { "type": "string", "description": "display name" },
+			"nickname": { "type": ["string", "null"] },
+			"status": { "enum": ["available", "on-hold", "null"] },
+			"pet": { "$ref": "#/definitions/pet" },
+			"tags": { "type": "array", "items": { "type": "string" } },
+			"scores": { "type": "array", "items": { "type": ["number", "null"] } },
+			"updated": { "type": "string", "format": "date-time" },
+			"extra": { "type": "object", "additionalProperties": { "type": "number" } },
+			"owner-id": { "type": "string" }
+		},
+		"required": ["id", "name", "nickname", "pet", "tags"],
+		"definitions": {
+			"pet": { "oneOf": [ { "$ref": "#/definitions/cat" }, { "$ref": "#/definitions/dog" } ] },
+			"cat": { "type": "object", "properties": { "lives": { "type": "integer" } } },
+			"dog": { "allOf": [ { "$ref": "#/definitions/animal" }, { "properties": { "good": { "type": "boolean" } } } ] },
+			"animal": { "description": "anything with legs", "properties": { "legs": { "enum": [2, 4] } }, "required": ["legs"] },
+			"mixed": { "anyOf": [ { "type": "string" }, { "$ref": "#/definitions/cat" } ] }
+		}
+	}`)
+
+	got, err := GenerateGraphQL(rs, GraphQLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "# Code generated by jsonschema. DO NOT EDIT.\n\nscalar DateTime\n\nscalar JSON\n\ntype PetStore {\n  extra: JSON\n  id: Int!\n  \"display name\"\n  name: String!\n  nickname: String\n  owner_id: String\n  pet: Pet!\n  scores: [Float]\n  status: PetStoreStatus\n  tags: [String!]!\n  updated: DateTime\n}\n\nunion Pet = Cat | Dog\n\ntype Cat {\n  lives: Int\n}\n\ntype Dog {\n  good: Boolean\n  legs: Int!\n}\n\nenum PetStoreStatus {\n  available\n  on_hold\n  NULL\n}\n\n\"anything with legs\"\ntype Animal {\n  legs: Int!\n}\n"
+	if string(got) != expect {
+		t.Errorf("output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateGraphQL(Must(`{"properties": {"children": {"type": "array", "items": {"$ref": "#"}}}}`), GraphQLOptions{RootName: "Node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "# Code generated by jsonschema. DO NOT EDIT.\n\ntype Node {\n  children: [Node!]\n}\n"
+	if string(got) != expect {
+		t.Errorf("recursive output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	if got, err = GenerateGraphQL(Must(`{"type": "string"}`), GraphQLOptions{}); err == nil {
+		t.Errorf("expected an error for a schema without types, got:\n%s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package jsonschema
+
+// KeywordEvaluation describes the evaluation of one keyword to the hooks
+// around it
+type KeywordEvaluation struct {
+	// Keyword is the name of the keyword
+	Keyword string
+	// SchemaPath is the JSON pointer to the keyword within the root schema
+	// it was parsed with
+	SchemaPath string
+	// InstancePath is the JSON pointer to the value being evaluated, as
+	// errors give it
+	InstancePath string
+	// Data is the value being evaluated
+	Data interface{}
+	// Errors are the errors the keyword reported, set once it's evaluated.
+	// After hooks may change them, and the errors left are reported
+	Errors []ValError
+}
+
+// Valid reports whether the keyword found no errors
+func (e *KeywordEvaluation) Valid() bool {
+	return len(e.Errors) == 0
+}
+
+// KeywordHooks are callbacks invoked around the evaluation of every keyword
+// of every schema, for auditing, metrics or skipping keywords conditionally.
+// They're set for a root schema through Options.Hooks. Either of them may
+// be nil
+type KeywordHooks struct {
+	// Before is called before a keyword is evaluated. Returning false skips
+	// the keyword, which then reports no errors, and no After hook is
+	// called for it
+	Before func(e *KeywordEvaluation) bool
+	// After is called once a keyword is evaluated
+	After func(e *KeywordEvaluation)
+}
+
+// validateHooked evaluates the keyword key of s, calling the hooks of the
+// options of vd around it
+func (s *Schema) validateHooked(vd *validation, key string, v Validator, propPath string, data interface{}, errs *[]ValError) {
+	e := &KeywordEvaluation{
+		Keyword:      key,
+		SchemaPath:   s.path + "/" + EscapePointerToken(key),
+		InstancePath: propPath,
+		Data:         data,
+	}
+	hooks := vd.options().Hooks
+	for _, h := range hooks {
+		if h.Before != nil && !h.Before(e) {
+			return
+		}
+	}
+	e.Errors = []ValError{}
+	vd.validate(v, propPath, data, &e.Errors)
+	for _, h := range hooks {
+		if h.After != nil {
+			h.After(e)
+		}
+	}
+	*errs = append(*errs, e.Errors...)
+}
+
+// bindPaths records the location of every schema of rs within it
+func (rs *RootSchema) bindPaths() {
+	rs.Schema.Walk(func(ptr string, s *Schema) error {
+		s.path = ptr
+		return nil
+	})
+}
@@ -0,0 +1,83 @@
+package jsonschema
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestKeywordHooks(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"name": {"type": "string", "minLength": 3},
+			"tag": {"$ref": "#/definitions/tag"}
+		},
+		"definitions": {
+			"tag": {"type": "string", "maxLength": 2}
+		}
+	}`)
+	doc := map[string]interface{}{"name": "ab", "tag": "abc"}
+
+	seen := []string{}
+	failed := []string{}
+	rs.SetOptions(Options{Hooks: []KeywordHooks{{
+		After: func(e *KeywordEvaluation) {
+			seen = append(seen, e.SchemaPath+" at "+e.InstancePath)
+			if !e.Valid() {
+				failed = append(failed, e.SchemaPath)
+			}
+		},
+	}}})
+
+	errs := []ValError{}
+	rs.Validate("/", doc, &errs)
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+	if len(seen) != 5 {
+		t.Errorf("expected 5 evaluations, got %v", seen)
+	}
+	for _, expect := range []string{
+		"/properties at /",
+		"/properties/name/minLength at /name",
+		"/definitions/tag/maxLength at /tag",
+	} {
+		found := false
+		for _, s := range seen {
+			found = found || s == expect
+		}
+		if !found {
+			t.Errorf("expected an evaluation of %s, got %v", expect, seen)
+		}
+	}
+	sort.Strings(failed)
+	expectFailed := []string{"/definitions/tag/maxLength", "/properties", "/properties/name/minLength"}
+	if !reflect.DeepEqual(failed, expectFailed) {
+		t.Errorf("expected failures %v, got %v", expectFailed, failed)
+	}
+
+	// skip minLength and drop errors about the tag
+	rs.SetOptions(Options{Hooks: []KeywordHooks{{
+		Before: func(e *KeywordEvaluation) bool {
+			return e.Keyword != "minLength"
+		},
+	}, {
+		After: func(e *KeywordEvaluation) {
+			if e.InstancePath == "/tag" {
+				e.Errors = nil
+			}
+		},
+	}}})
+	errs = []ValError{}
+	rs.Validate("/", doc, &errs)
+	if len(errs) != 0 {
+		t.Errorf("expected hooks to remove every error, got %v", errs)
+	}
+
+	rs.SetOptions(Options{})
+	errs = []ValError{}
+	rs.Validate("/", doc, &errs)
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors without hooks, got %v", errs)
+	}
+}
@@ -0,0 +1,342 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Bootstring parameters of Punycode, RFC 3492 section 5
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// acePrefix starts the ASCII form of a label holding Unicode characters,
+// an A-label
+const acePrefix = "xn--"
+
+// idnDots are the characters that separate the labels of internationalized
+// host names, RFC 3490 section 3.1
+var idnDots = []string{"。", "．", "｡"}
+
+// isValidIDNHostnameLabels checks host, a host name made of labels that
+// may be A-labels or hold Unicode characters, with the rules of IDNA 2008,
+// RFC 5891 and RFC 5892. Uppercase letters are accepted, as they would be
+// mapped to lowercase before lookup. Normalization isn't checked
+func isValidIDNHostnameLabels(host string) error {
+	for _, dot := range idnDots {
+		host = strings.Replace(host, dot, ".", -1)
+	}
+	if host == "" {
+		return fmt.Errorf("host names can't be empty")
+	}
+
+	size := 0
+	for _, label := range strings.Split(host, ".") {
+		ascii, err := idnLabelToASCII(label)
+		if err != nil {
+			return err
+		}
+		size += len(ascii) + 1
+	}
+	if size-1 > 255 {
+		return fmt.Errorf("host names can't be longer than 255 characters")
+	}
+	return nil
+}
+
+// idnLabelToASCII checks a label of a host name, giving its ASCII form
+func idnLabelToASCII(label string) (string, error) {
+	if label == "" {
+		return "", fmt.Errorf("labels can't be empty")
+	}
+	ascii := label
+	if isASCII(label) {
+		if !strings.HasPrefix(strings.ToLower(label), acePrefix) {
+			if err := checkLDHLabel(label); err != nil {
+				return "", err
+			}
+			return label, nil
+		}
+		decoded, err := punycodeDecode(label[len(acePrefix):])
+		if err != nil {
+			return "", fmt.Errorf("invalid A-label %q: %s", label, err.Error())
+		}
+		if isASCII(decoded) {
+			return "", fmt.Errorf("invalid A-label %q: holds no Unicode characters", label)
+		}
+		label = decoded
+	} else {
+		encoded, err := punycodeEncode(strings.ToLower(label))
+		if err != nil {
+			return "", err
+		}
+		ascii = acePrefix + encoded
+	}
+
+	if err := checkULabel(strings.ToLower(label)); err != nil {
+		return "", err
+	}
+	if len(ascii) > 63 {
+		return "", fmt.Errorf("label %q is longer than 63 characters in ASCII", label)
+	}
+	return ascii, nil
+}
+
+// checkLDHLabel checks an ASCII label is made of letters, digits and
+// hyphens, RFC 1034 section 3.5
+func checkLDHLabel(label string) error {
+	if len(label) > 63 {
+		return fmt.Errorf("label %q is longer than 63 characters", label)
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+			return fmt.Errorf("label %q contains illegal character %q", label, c)
+		}
+	}
+	return checkLabelHyphens(label)
+}
+
+// checkLabelHyphens checks a label doesn't begin or end with a hyphen, or
+// have hyphens at its third and fourth positions, which are reserved for
+// prefixes such as "xn--"
+func checkLabelHyphens(label string) error {
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return fmt.Errorf("label %q can't begin or end with a hyphen", label)
+	}
+	if runes := []rune(label); len(runes) >= 4 && runes[2] == '-' && runes[3] == '-' {
+		return fmt.Errorf("label %q can't have hyphens at its third and fourth positions", label)
+	}
+	return nil
+}
+
+// checkULabel checks the characters of a lowercase label holding Unicode
+// characters are allowed by RFC 5892, approximating its derived property
+// values with Unicode categories, and meet its contextual rules
+func checkULabel(label string) error {
+	if err := checkLabelHyphens(label); err != nil {
+		return err
+	}
+	runes := []rune(label)
+	if unicode.In(runes[0], unicode.Mn, unicode.Mc, unicode.Me) {
+		return fmt.Errorf("label %q can't begin with combining mark %#U", label, runes[0])
+	}
+
+	arabicIndic, extendedArabicIndic := false, false
+	for i, r := range runes {
+		if disallowedIdnChars[string(r)] && r != '-' {
+			return fmt.Errorf("contains illegal character %#U", r)
+		}
+		switch {
+		case r == '·':
+			// MIDDLE DOT, only between two "l", as in Catalan
+			if i == 0 || i == len(runes)-1 || runes[i-1] != 'l' || runes[i+1] != 'l' {
+				return fmt.Errorf("%#U must be between two 'l'", r)
+			}
+		case r == '͵':
+			// GREEK LOWER NUMERAL SIGN, only before Greek
+			if i == len(runes)-1 || !unicode.Is(unicode.Greek, runes[i+1]) {
+				return fmt.Errorf("%#U must be followed by a Greek character", r)
+			}
+		case r == '׳' || r == '״':
+			// HEBREW PUNCTUATION GERESH and GERSHAYIM, only after Hebrew
+			if i == 0 || !unicode.Is(unicode.Hebrew, runes[i-1]) {
+				return fmt.Errorf("%#U must follow a Hebrew character", r)
+			}
+		case r == '・':
+			// KATAKANA MIDDLE DOT, only with Japanese characters
+			if !strings.ContainsAny(label, "々〆〇") && !containsRuneIn(runes, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+				return fmt.Errorf("%#U must be used with Hiragana, Katakana or Han characters", r)
+			}
+		case r >= '٠' && r <= '٩':
+			arabicIndic = true
+		case r >= '۰' && r <= '۹':
+			extendedArabicIndic = true
+		case r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z':
+		case unicode.In(r, unicode.Ll, unicode.Lo, unicode.Lm, unicode.Nd, unicode.Mn, unicode.Mc):
+		default:
+			return fmt.Errorf("contains illegal character %#U", r)
+		}
+	}
+	if arabicIndic && extendedArabicIndic {
+		return fmt.Errorf("label %q mixes Arabic-Indic and Extended Arabic-Indic digits", label)
+	}
+	return nil
+}
+
+// containsRuneIn reports whether any of runes is in any of tables
+func containsRuneIn(runes []rune, tables ...*unicode.RangeTable) bool {
+	for _, r := range runes {
+		if r != '・' && unicode.In(r, tables...) {
+			return true
+		}
+	}
+	return false
+}
+
+// isASCII reports whether str only holds ASCII characters
+func isASCII(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// punycodeAdapt is the bias adaptation function of RFC 3492 section 6.1
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+// punycodeThreshold gives the threshold of the digit at position k
+func punycodeThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punycodeTMin
+	case k >= bias+punycodeTMax:
+		return punycodeTMax
+	}
+	return k - bias
+}
+
+// punycodeDecode decodes the Punycode form of a label, without its "xn--"
+// prefix, RFC 3492 section 6.2
+func punycodeDecode(encoded string) (string, error) {
+	var output []rune
+	pos := 0
+	if i := strings.LastIndexByte(encoded, '-'); i != -1 {
+		output = []rune(encoded[:i])
+		pos = i + 1
+	}
+	n, bias, i := punycodeInitialN, punycodeInitialBias, 0
+	for pos < len(encoded) {
+		oldi, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos == len(encoded) {
+				return "", fmt.Errorf("unexpected end of input")
+			}
+			digit := punycodeDigit(encoded[pos])
+			pos++
+			if digit < 0 {
+				return "", fmt.Errorf("invalid character %q", encoded[pos-1])
+			}
+			if digit > (utf8.MaxRune-i)/w {
+				return "", fmt.Errorf("overflow")
+			}
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punycodeBase - t
+		}
+		bias = punycodeAdapt(i-oldi, len(output)+1, oldi == 0)
+		n += i / (len(output) + 1)
+		i %= len(output) + 1
+		if n > utf8.MaxRune || (n >= 0xd800 && n <= 0xdfff) {
+			return "", fmt.Errorf("invalid code point %#x", n)
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+	return string(output), nil
+}
+
+// punycodeEncode gives the Punycode form of a label, without the "xn--"
+// prefix, RFC 3492 section 6.3
+func punycodeEncode(label string) (string, error) {
+	runes := []rune(label)
+	output := []byte{}
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			output = append(output, byte(r))
+		}
+	}
+	basic := len(output)
+	handled := basic
+	if basic > 0 {
+		output = append(output, '-')
+	}
+
+	n, delta, bias := punycodeInitialN, 0, punycodeInitialBias
+	for handled < len(runes) {
+		m := int(utf8.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		if m-n > (1<<31-1-delta)/(handled+1) {
+			return "", fmt.Errorf("label %q is too long to encode", label)
+		}
+		delta += (m - n) * (handled + 1)
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				output = append(output, punycodeDigitChar(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			output = append(output, punycodeDigitChar(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(output), nil
+}
+
+// punycodeDigit gives the value of a Punycode digit, or -1
+func punycodeDigit(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A')
+	}
+	return -1
+}
+
+// punycodeDigitChar gives the lowercase character of a Punycode digit
+func punycodeDigitChar(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
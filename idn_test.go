@@ -0,0 +1,78 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestPunycode(t *testing.T) {
+	cases := []struct {
+		label, encoded string
+	}{
+		{"bücher", "bcher-kva"},
+		{"münchen-ost", "mnchen-ost-9db"},
+		{"実例", "fsq470a"},
+		{"ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+	}
+	for _, c := range cases {
+		got, err := punycodeEncode(c.label)
+		if err != nil || got != c.encoded {
+			t.Errorf("encoding %q: expected %q, got %q (%v)", c.label, c.encoded, got, err)
+		}
+		got, err = punycodeDecode(c.encoded)
+		if err != nil || got != c.label {
+			t.Errorf("decoding %q: expected %q, got %q (%v)", c.encoded, c.label, got, err)
+		}
+	}
+
+	if _, err := punycodeDecode("bcher-kv"); err == nil {
+		t.Errorf("expected truncated input to be an error")
+	}
+}
+
+func TestInternationalizedFormats(t *testing.T) {
+	cases := []struct {
+		format, value string
+		err           string
+	}{
+		{"idn-hostname", "실례.테스트", ""},
+		{"idn-hostname", "Bücher.example", ""},
+		{"idn-hostname", "my-host.example", ""},
+		{"idn-hostname", "xn--bcher-kva.example", ""},
+		{"idn-hostname", "例え。テスト", ""},
+		{"idn-hostname", "l·l.example", ""},
+		{"idn-hostname", "xn--bcher-kv.example", `invalid idn-hostname: invalid A-label "xn--bcher-kv": unexpected end of input`},
+		{"idn-hostname", "xn--abc-.example", `invalid idn-hostname: invalid A-label "xn--abc-": holds no Unicode characters`},
+		{"idn-hostname", "〮실례.테스트", `invalid idn-hostname: label "〮실례" can't begin with combining mark U+302E '〮'`},
+		{"idn-hostname", "실〮례.테스트", "invalid idn-hostname: contains illegal character U+302E '〮'"},
+		{"idn-hostname", "-bücher.example", `invalid idn-hostname: label "-bücher" can't begin or end with a hyphen`},
+		{"idn-hostname", "bü--cher.example", `invalid idn-hostname: label "bü--cher" can't have hyphens at its third and fourth positions`},
+		{"idn-hostname", "a·b.example", "invalid idn-hostname: U+00B7 '·' must be between two 'l'"},
+		{"idn-hostname", "bü cher.example", "invalid idn-hostname: contains illegal character U+0020 ' '"},
+		{"idn-hostname", "bücher..example", "invalid idn-hostname: labels can't be empty"},
+
+		{"idn-email", "실례@실례.테스트", ""},
+		{"idn-email", "jöe@[127.0.0.1]", ""},
+		{"idn-email", "jöe@-bücher.example", `invalid idn-email: invalid domain: label "-bücher" can't begin or end with a hyphen`},
+
+		{"iri", "http://ƒøø.ßår/?∂éœ=πîx#πîüx", ""},
+		{"iri", "http://example.com/?", ""},
+		{"iri", "http://example.com/", "invalid iri: invalid character U+E000 at offset 19"},
+		{"iri", "http://example.com/#?", "invalid iri: invalid character U+E000 at offset 21"},
+		{"iri", "http://ƒøø.ßår/a b", "invalid iri: invalid character ' ' at offset 21"},
+		{"iri", "âππ", "invalid iri: missing scheme"},
+		{"iri-reference", "âππ", ""},
+		{"iri-reference", "//ƒøø.ßår/?∂éœ=πîx#πîüx", ""},
+		{"iri-reference", `#ƒräg\mênt`, `invalid iri-reference: invalid character '\\' at offset 7`},
+	}
+	for _, c := range cases {
+		errs := []ValError{}
+		Format(c.format).Validate("/", c.value, &errs)
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].Message
+		}
+		if got != c.err {
+			t.Errorf("%s %q: expected error %q, got %q", c.format, c.value, c.err, got)
+		}
+	}
+}
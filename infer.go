@@ -0,0 +1,236 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"sort"
+)
+
+// InferSchema drafts a schema that an example JSON document is valid
+// against, as a starting point for writing one by hand. It records types,
+// the properties of objects, which are all required, and the items of
+// arrays, which are described by a single schema covering every element.
+// Strings are given a format when every string seen at that position is a
+// date-time, date, time, email, ipv4, ipv6 or uri. InferSchema returns nil
+// if data isn't valid JSON
+func InferSchema(data []byte) *RootSchema {
+	rs, err := InferSchemaFromSamples([][]byte{data}, InferOptions{})
+	if err != nil {
+		return nil
+	}
+	return rs
+}
+
+// InferOptions configures schema inference
+type InferOptions struct {
+	// EnumThreshold is the most distinct values a string or integer
+	// position may take to be described by an enum. Positions are only
+	// given an enum when some value repeats, so a handful of unique values
+	// don't become the only valid ones. Zero disables enums
+	EnumThreshold int
+}
+
+// InferSchemaFromSamples drafts a schema that every sample document is valid
+// against, merging what's observed at each position across the corpus:
+// properties missing from any object are optional, positions holding values
+// of several types accept all of them, and formats are only kept when every
+// string matches. See InferSchema for what's inferred from each value
+func InferSchemaFromSamples(samples [][]byte, opts InferOptions) (*RootSchema, error) {
+	in := &inference{}
+	for i, data := range samples {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("sample %d: %s", i, err.Error())
+		}
+		in.observe(v, opts.EnumThreshold)
+	}
+	return in.schema().Keyword("$schema", "http://json-schema.org/draft-07/schema#").BuildRoot()
+}
+
+// inferTypes orders the type names inference can observe
+var inferTypes = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+// inferFormats are the formats inference detects, most specific first
+var inferFormats = []struct {
+	name  string
+	valid func(string) bool
+}{
+	{"date-time", func(str string) bool { return isValidDateTime(TimeFormatOptions{}, str) == nil }},
+	{"date", func(str string) bool { return isValidDate(TimeFormatOptions{}, str) == nil }},
+	{"time", func(str string) bool { return isValidTime(TimeFormatOptions{}, str) == nil }},
+	{"email", func(str string) bool {
+		// ParseAddress also accepts display names and comments
+		addr, err := mail.ParseAddress(str)
+		return err == nil && addr.Address == str
+	}},
+	{"ipv4", func(str string) bool { return ValidateIPv4(str) == nil }},
+	{"ipv6", func(str string) bool { return ValidateIPv6(str) == nil }},
+	{"uri", func(str string) bool {
+		u, err := url.Parse(str)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	}},
+}
+
+// inference accumulates observations of the values found at one position
+// within documents
+type inference struct {
+	// types counts values of each type name
+	types map[string]int
+	// objects counts observed objects, and props the observations of their
+	// properties
+	objects int
+	props   map[string]*inference
+	// items observes the elements of arrays
+	items *inference
+	// formats counts strings matching each of inferFormats
+	strings int
+	formats map[string]int
+	// values holds the distinct scalar values observed in order, until
+	// there are more than the enum threshold
+	values     []interface{}
+	seen       map[interface{}]bool
+	manyValues bool
+}
+
+func (in *inference) observe(v interface{}, enumThreshold int) {
+	if in.types == nil {
+		in.types = map[string]int{}
+	}
+	switch v.(type) {
+	case nil, bool, float64, string:
+		in.observeValue(v, enumThreshold)
+	}
+	switch t := v.(type) {
+	case nil:
+		in.types["null"]++
+	case bool:
+		in.types["boolean"]++
+	case float64:
+		if t == math.Trunc(t) && !math.IsInf(t, 0) {
+			in.types["integer"]++
+		} else {
+			in.types["number"]++
+		}
+	case string:
+		in.types["string"]++
+		in.strings++
+		if in.formats == nil {
+			in.formats = map[string]int{}
+		}
+		for _, f := range inferFormats {
+			if f.valid(t) {
+				in.formats[f.name]++
+				// formats are tried in order, so a date-time isn't also
+				// counted as a uri
+				break
+			}
+		}
+	case []interface{}:
+		in.types["array"]++
+		for _, el := range t {
+			if in.items == nil {
+				in.items = &inference{}
+			}
+			in.items.observe(el, enumThreshold)
+		}
+	case map[string]interface{}:
+		in.types["object"]++
+		in.objects++
+		if in.props == nil {
+			in.props = map[string]*inference{}
+		}
+		for key, val := range t {
+			if in.props[key] == nil {
+				in.props[key] = &inference{}
+			}
+			in.props[key].observe(val, enumThreshold)
+		}
+	}
+}
+
+// observeValue records a distinct scalar value as an enum candidate
+func (in *inference) observeValue(v interface{}, enumThreshold int) {
+	if in.manyValues || enumThreshold == 0 || in.seen[v] {
+		return
+	}
+	if len(in.values) == enumThreshold {
+		// too many to enumerate, stop keeping them
+		in.manyValues = true
+		in.values, in.seen = nil, nil
+		return
+	}
+	if in.seen == nil {
+		in.seen = map[interface{}]bool{}
+	}
+	in.seen[v] = true
+	in.values = append(in.values, v)
+}
+
+// isEnum reports whether the observed values should be described by an
+// enum: they're all strings, integers, booleans or null, at least one is a
+// string or integer, and some value repeats
+func (in *inference) isEnum() bool {
+	if in.manyValues || len(in.values) == 0 || len(in.values) == in.count() {
+		return false
+	}
+	if in.types["number"]+in.types["array"]+in.types["object"] > 0 {
+		return false
+	}
+	return in.types["string"]+in.types["integer"] > 0
+}
+
+// count gives the number of values observed
+func (in *inference) count() int {
+	n := 0
+	for _, c := range in.types {
+		n += c
+	}
+	return n
+}
+
+// schema describes the observed values
+func (in *inference) schema() *SchemaBuilder {
+	types := []string{}
+	for _, t := range inferTypes {
+		if in.types[t] == 0 || (t == "integer" && in.types["number"] > 0) {
+			// every integer is a number
+			continue
+		}
+		types = append(types, t)
+	}
+
+	b := NewSchema()
+	if len(types) > 0 {
+		b.Type(types...)
+	}
+	if in.isEnum() {
+		b.Enum(in.values...)
+	} else if in.strings > 0 {
+		for _, f := range inferFormats {
+			if in.formats[f.name] == in.strings {
+				b.Format(f.name)
+				break
+			}
+		}
+	}
+	if in.items != nil {
+		b.Items(in.items.schema())
+	}
+	if in.objects > 0 {
+		keys := make([]string, 0, len(in.props))
+		for key := range in.props {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			b.Property(key, in.props[key].schema())
+			if in.props[key].count() == in.objects {
+				b.Required(key)
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	cases := []struct {
+		data, expect string
+	}{
+		{`"hello"`, `{"$schema":"http://json-schema.org/draft-07/schema#","type":"string"}`},
+		{`[1, 2.5, null]`, `{"$schema":"http://json-schema.org/draft-07/schema#","items":{"type":["null","number"]},"type":"array"}`},
+		{`[1, 2]`, `{"$schema":"http://json-schema.org/draft-07/schema#","items":{"type":"integer"},"type":"array"}`},
+		{`[]`, `{"$schema":"http://json-schema.org/draft-07/schema#","type":"array"}`},
+		{`{
+			"id": 1,
+			"email": "a@example.com",
+			"created": "2018-11-13T20:20:39Z",
+			"site": "https://example.com/a",
+			"tags": ["a", "b"],
+			"pets": [
+				{ "name": "rex", "born": "2015-06-01" },
+				{ "name": "tom", "legs": 4 }
+			]
+		}`, `{"$schema":"http://json-schema.org/draft-07/schema#","properties":{` +
+			`"created":{"format":"date-time","type":"string"},` +
+			`"email":{"format":"email","type":"string"},` +
+			`"id":{"type":"integer"},` +
+			`"pets":{"items":{"properties":{"born":{"format":"date","type":"string"},"legs":{"type":"integer"},"name":{"type":"string"}},"required":["name"],"type":"object"},"type":"array"},` +
+			`"site":{"format":"uri","type":"string"},` +
+			`"tags":{"items":{"type":"string"},"type":"array"}},` +
+			`"required":["created","email","id","pets","site","tags"],"type":"object"}`},
+		{`["2018-11-13", "not a date"]`, `{"$schema":"http://json-schema.org/draft-07/schema#","items":{"type":"string"},"type":"array"}`},
+	}
+
+	for i, c := range cases {
+		rs := InferSchema([]byte(c.data))
+		if rs == nil {
+			t.Errorf("case %d: expected a schema", i)
+			continue
+		}
+		got, err := json.Marshal(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d: expected:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+		if errs, err := rs.ValidateBytes([]byte(c.data)); err != nil || len(errs) > 0 {
+			t.Errorf("case %d: sample doesn't validate against inferred schema: %v %v", i, err, errs)
+		}
+	}
+
+	if InferSchema([]byte(`{`)) != nil {
+		t.Error("expected invalid JSON to give a nil schema")
+	}
+}
+
+func TestInferSchemaFromSamples(t *testing.T) {
+	samples := [][]byte{
+		[]byte(`{"id": 1, "status": "active", "score": 1, "name": "a", "note": null}`),
+		[]byte(`{"id": 2, "status": "inactive", "score": 2.5, "name": "b"}`),
+		[]byte(`{"id": 3, "status": "active", "score": null, "name": "c", "note": "hi", "at": "2019-01-01"}`),
+		[]byte(`{"id": "4", "status": "active", "name": "d", "note": "hi"}`),
+	}
+	rs, err := InferSchemaFromSamples(samples, InferOptions{EnumThreshold: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"$schema":"http://json-schema.org/draft-07/schema#","properties":{` +
+		`"at":{"format":"date","type":"string"},` +
+		`"id":{"type":["integer","string"]},` +
+		`"name":{"type":"string"},` +
+		`"note":{"enum":[null,"hi"],"type":["null","string"]},` +
+		`"score":{"type":["null","number"]},` +
+		`"status":{"enum":["active","inactive"],"type":"string"}},` +
+		`"required":["id","name","status"],"type":"object"}`
+	if string(got) != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, got)
+	}
+	for i, data := range samples {
+		if errs, err := rs.ValidateBytes(data); err != nil || len(errs) > 0 {
+			t.Errorf("sample %d doesn't validate against inferred schema: %v %v", i, err, errs)
+		}
+	}
+
+	if _, err := InferSchemaFromSamples([][]byte{[]byte(`{}`), []byte(`{`)}, InferOptions{}); err == nil {
+		t.Error("expected an error for an invalid sample")
+	}
+}
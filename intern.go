@@ -0,0 +1,39 @@
+package jsonschema
+
+// stringInterner shares one string between the equal strings decoded from
+// a document. A nil interner allocates every string
+type stringInterner map[string]string
+
+// bytes gives the string b holds, allocating it only the first time
+func (in stringInterner) bytes(b []byte) string {
+	if in == nil {
+		return string(b)
+	}
+	if s, ok := in[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	in[s] = s
+	return s
+}
+
+// string gives the shared string equal to s
+func (in stringInterner) string(s string) string {
+	if in == nil {
+		return s
+	}
+	if shared, ok := in[s]; ok {
+		return shared
+	}
+	in[s] = s
+	return s
+}
+
+// keyInterner gives the interner of the object keys of a document, as o
+// sets
+func (o DecodeOptions) keyInterner() stringInterner {
+	if o.InternKeys {
+		return stringInterner{}
+	}
+	return nil
+}
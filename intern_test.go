@@ -0,0 +1,53 @@
+package jsonschema
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestDecodingInternKeys(t *testing.T) {
+	data := []byte(`[{"name": "a", "name2": 1}, {"name": "b", "name2": 2}]`)
+
+	sharedKeys := func(doc interface{}) bool {
+		ptrs := map[string]*byte{}
+		for _, el := range doc.([]interface{}) {
+			for key := range el.(map[string]interface{}) {
+				if p, ok := ptrs[key]; ok && p != unsafe.StringData(key) {
+					return false
+				}
+				ptrs[key] = unsafe.StringData(key)
+			}
+		}
+		return true
+	}
+
+	opts := DecodeOptions{InternKeys: true}
+	doc, err := opts.decodeJSON(data)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !sharedKeys(doc) {
+		t.Errorf("expected equal keys to share their string")
+	}
+	if _, err := opts.decodeJSON([]byte(`[{"name": 1}] {}`)); err == nil {
+		t.Errorf("expected an error for data after the document")
+	}
+
+	cbor, err := opts.DecodeCBOR([]byte{0x82, 0xa1, 0x61, 0x6b, 0x01, 0xa1, 0x61, 0x6b, 0x02})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !sharedKeys(cbor) {
+		t.Errorf("expected equal CBOR keys to share their string")
+	}
+
+	rs := Must(`{"items": {"required": ["name2"], "properties": {"name": {"type": "string"}}}}`)
+	rs.SetOptions(Options{Decoding: opts})
+	errs, err := rs.ValidateBytes(data)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
@@ -0,0 +1,379 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// InvalidInstance is a near-miss instance of a schema: a valid instance
+// with a single change that breaks one keyword
+type InvalidInstance struct {
+	// KeywordPath is a JSON pointer to the broken keyword within the schema,
+	// passing through "$ref" where references were followed
+	KeywordPath string
+	// PropertyPath is a JSON pointer to the changed value within Instance
+	PropertyPath string
+	Instance     interface{}
+}
+
+// GenerateInvalid generates a valid instance of s, then derives an invalid
+// instance from it for each constraint it can break, one constraint at a
+// time. Values are pushed just past their bounds where there are any:
+// numbers to the nearest invalid value, strings and arrays one element too
+// short or too long. Other changes include using a disallowed type, dropping
+// a required property, adding an unexpected one and duplicating array items.
+// Branches of "anyOf", "oneOf", "not" and "if" are left alone, since
+// breaking one doesn't reliably break the schema, and every returned
+// instance is checked to be invalid against s
+func (g *InstanceGenerator) GenerateInvalid(s *Schema) ([]InvalidInstance, error) {
+	base, err := g.Generate(s)
+	if err != nil {
+		return nil, err
+	}
+	inv := &invalidator{g: g, root: s, base: base}
+	inv.at(s, "", nil, base, 0)
+	return inv.found, nil
+}
+
+// invalidator collects invalid variations of a base instance
+type invalidator struct {
+	g     *InstanceGenerator
+	root  *Schema
+	base  interface{}
+	found []InvalidInstance
+}
+
+// at breaks each keyword of s that applies to value v, found at path within
+// the base instance
+func (inv *invalidator) at(s *Schema, ptr string, path []interface{}, v interface{}, depth int) {
+	if s == nil || depth > inv.g.MaxDepth*4 {
+		return
+	}
+	if s.Ref != "" {
+		switch target := s.ref.(type) {
+		case *Schema:
+			inv.at(target, ptr+"/$ref", path, v, depth+1)
+		case *RootSchema:
+			inv.at(&target.Schema, ptr+"/$ref", path, v, depth+1)
+		}
+		return
+	}
+	if s.schemaType != schemaTypeObject {
+		return
+	}
+
+	for _, key := range sortedValidatorKeys(s.Validators) {
+		kw := s.Validators[key]
+		for _, b := range inv.breakKeyword(s, key, v) {
+			kptr := ptr + "/" + key
+			if b.entry != "" {
+				kptr += "/" + b.entry
+			}
+			if isValid(keywordSchema(kw), b.value) {
+				continue
+			}
+			instance := replaceValue(inv.base, path, b.value)
+			if isValid(inv.root, instance) {
+				continue
+			}
+			inv.found = append(inv.found, InvalidInstance{
+				KeywordPath:  kptr,
+				PropertyPath: pathPointer(path),
+				Instance:     instance,
+			})
+		}
+	}
+
+	// descend into the parts of v that subschemas apply to
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		for i, branch := range *allOf {
+			inv.at(branch, ptr+"/allOf/"+strconv.Itoa(i), path, v, depth+1)
+		}
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedMapKeys(t) {
+			sch, sptr := propertySchemaPointer(s, key)
+			inv.at(sch, ptr+sptr, append(path[:len(path):len(path)], key), t[key], depth+1)
+		}
+	case []interface{}:
+		for i, el := range t {
+			sch := itemSchema(s, i)
+			items, ok := s.Validators["items"].(*Items)
+			if !ok || sch == trueSchema {
+				continue
+			}
+			sptr := "/items"
+			switch {
+			case !items.single && i < len(items.Schemas):
+				sptr += "/" + strconv.Itoa(i)
+			case !items.single:
+				sptr = "/additionalItems"
+			case i > 0:
+				// every element has the same schema, breaking one is enough
+				continue
+			}
+			inv.at(sch, ptr+sptr, append(path[:len(path):len(path)], i), el, depth+1)
+		}
+	}
+}
+
+// breakage is a value that may break a keyword, or one entry of keywords
+// like "required" that list several constraints
+type breakage struct {
+	entry string
+	value interface{}
+}
+
+// breakKeyword gives values derived from v that may break keyword key of s.
+// Callers check that each one actually does
+func (inv *invalidator) breakKeyword(s *Schema, key string, v interface{}) []breakage {
+	switch kw := s.Validators[key].(type) {
+	case *Type:
+		for _, bad := range []interface{}{nil, true, 1.5, 1.0, "invalid", []interface{}{}, map[string]interface{}{}} {
+			if !isValid(keywordSchema(kw), bad) {
+				return values(bad)
+			}
+		}
+	case *Enum, *Const:
+		return values("invalid", 123456789.5, nil, false, map[string]interface{}{})
+	case *Minimum:
+		return values(float64(*kw) - 1)
+	case *ExclusiveMinimum:
+		return values(float64(*kw))
+	case *Maximum:
+		return values(float64(*kw) + 1)
+	case *ExclusiveMaximum:
+		return values(float64(*kw))
+	case *MultipleOf:
+		if n, ok := v.(float64); ok {
+			return values(n + float64(*kw)/2)
+		}
+	case *MinLength:
+		if *kw > 0 {
+			return values(resize(v, int(*kw)-1, inv.g))
+		}
+	case *MaxLength:
+		return values(resize(v, int(*kw)+1, inv.g))
+	case *Pattern:
+		return values("", "!", "0", "a", "A", " ", "-")
+	case *Format:
+		switch *kw {
+		case "regex":
+			return values("[")
+		case "uri-reference", "iri-reference":
+			return values(`\\invalid`)
+		case "uri-template":
+			return values("{invalid")
+		case "relative-json-pointer":
+			return values("-1")
+		}
+		return values("invalid " + string(*kw))
+	case *MinItems:
+		if arr, ok := v.([]interface{}); ok && *kw > 0 && len(arr) >= int(*kw) {
+			return values(append([]interface{}{}, arr[:*kw-1]...))
+		}
+	case *MaxItems:
+		if arr, ok := v.([]interface{}); ok && len(arr) > 0 {
+			long := append([]interface{}{}, arr...)
+			for len(long) <= int(*kw) {
+				long = append(long, arr[len(long)%len(arr)])
+			}
+			return values(long)
+		}
+	case *UniqueItems:
+		if arr, ok := v.([]interface{}); ok && bool(*kw) {
+			if len(arr) == 0 {
+				el, err := inv.g.Generate(itemSchema(s, 0))
+				if err != nil {
+					return nil
+				}
+				arr = []interface{}{el}
+			}
+			return values(append(append([]interface{}{}, arr...), arr[0]))
+		}
+	case *Contains:
+		if arr, ok := v.([]interface{}); ok {
+			rest := []interface{}{}
+			for _, el := range arr {
+				if !isValid((*Schema)(kw), el) {
+					rest = append(rest, el)
+				}
+			}
+			return values(rest)
+		}
+	case *AdditionalItems:
+		if arr, ok := v.([]interface{}); ok && isFalseSchema(kw.Schema) {
+			return values(append(append([]interface{}{}, arr...), nil))
+		}
+	case *Required:
+		if obj, ok := v.(map[string]interface{}); ok {
+			bad := []breakage{}
+			for i, name := range *kw {
+				bad = append(bad, breakage{strconv.Itoa(i), withoutKeys(obj, name)})
+			}
+			return bad
+		}
+	case *minProperties:
+		if obj, ok := v.(map[string]interface{}); ok && *kw > 0 && len(obj) >= int(*kw) {
+			keys := sortedMapKeys(obj)
+			short := map[string]interface{}{}
+			for _, key := range keys[:*kw-1] {
+				short[key] = obj[key]
+			}
+			return values(short)
+		}
+	case *MaxProperties:
+		if obj, ok := v.(map[string]interface{}); ok {
+			long := withoutKeys(obj)
+			for i := 1; len(long) <= int(*kw); i++ {
+				long[fmt.Sprintf("extra%d", i)] = nil
+			}
+			return values(long)
+		}
+	case *AdditionalProperties:
+		if obj, ok := v.(map[string]interface{}); ok && isFalseSchema(kw.Schema) {
+			extra := withoutKeys(obj)
+			extra["unexpectedProperty"] = nil
+			return values(extra)
+		}
+	case *PropertyNames:
+		if obj, ok := v.(map[string]interface{}); ok {
+			for _, name := range []string{"", "!", "0", "a", "A", " ", "-", "invalidName"} {
+				if !isValid((*Schema)(kw), name) {
+					bad := withoutKeys(obj)
+					bad[name] = nil
+					return values(bad)
+				}
+			}
+		}
+	case *Dependencies:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		bad := []breakage{}
+		for _, name := range sortedDependencyKeys(*kw) {
+			dep := (*kw)[name]
+			if len(dep.props) == 0 {
+				continue
+			}
+			broken := withoutKeys(obj, dep.props[0])
+			if _, ok := broken[name]; !ok {
+				val, err := inv.g.Generate(propertySchema(s, name))
+				if err != nil {
+					continue
+				}
+				broken[name] = val
+			}
+			bad = append(bad, breakage{EscapePointerToken(name), broken})
+		}
+		return bad
+	}
+	return nil
+}
+
+// values wraps values that break a keyword as a whole
+func values(vals ...interface{}) []breakage {
+	b := make([]breakage, len(vals))
+	for i, v := range vals {
+		b[i] = breakage{value: v}
+	}
+	return b
+}
+
+// resize gives a string of n characters, keeping what it can of v
+func resize(v interface{}, n int, g *InstanceGenerator) interface{} {
+	str, _ := v.(string)
+	runes := []rune(str)
+	if len(runes) >= n {
+		return string(runes[:n])
+	}
+	return str + g.letters(n-len(runes))
+}
+
+// replaceValue gives a copy of doc with the value at path replaced. Only the
+// objects and arrays along path are copied
+func replaceValue(doc interface{}, path []interface{}, v interface{}) interface{} {
+	if len(path) == 0 {
+		return v
+	}
+	switch t := doc.(type) {
+	case map[string]interface{}:
+		key := path[0].(string)
+		cp := withoutKeys(t)
+		cp[key] = replaceValue(t[key], path[1:], v)
+		return cp
+	case []interface{}:
+		i := path[0].(int)
+		cp := append([]interface{}{}, t...)
+		cp[i] = replaceValue(t[i], path[1:], v)
+		return cp
+	}
+	return doc
+}
+
+// withoutKeys gives a shallow copy of obj lacking keys
+func withoutKeys(obj map[string]interface{}, keys ...string) map[string]interface{} {
+	cp := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		cp[k] = v
+	}
+	for _, key := range keys {
+		delete(cp, key)
+	}
+	return cp
+}
+
+// pathPointer formats a path of object keys and array indexes as a JSON
+// pointer
+func pathPointer(path []interface{}) string {
+	ptr := ""
+	for _, tok := range path {
+		switch t := tok.(type) {
+		case string:
+			ptr += "/" + EscapePointerToken(t)
+		case int:
+			ptr += "/" + strconv.Itoa(t)
+		}
+	}
+	return ptr
+}
+
+// propertySchemaPointer is like propertySchema, also giving the schema's
+// pointer relative to s
+func propertySchemaPointer(s *Schema, name string) (*Schema, string) {
+	if props, ok := s.Validators["properties"].(*Properties); ok && (*props)[name] != nil {
+		return (*props)[name], "/properties/" + EscapePointerToken(name)
+	}
+	if patterns, ok := s.Validators["patternProperties"].(*PatternProperties); ok {
+		for _, ptn := range *patterns {
+			if ptn.re.MatchString(name) {
+				return ptn.schema, "/patternProperties/" + EscapePointerToken(ptn.key)
+			}
+		}
+	}
+	if v, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && v.Schema != nil {
+		return v.Schema, "/additionalProperties"
+	}
+	return nil, ""
+}
+
+func sortedMapKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedDependencyKeys(deps Dependencies) []string {
+	keys := make([]string, 0, len(deps))
+	for key := range deps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateInvalid(t *testing.T) {
+	cases := []struct {
+		schema   string
+		keywords []string
+	}{
+		{`{"type": "integer", "minimum": 10, "exclusiveMaximum": 13}`,
+			[]string{"/exclusiveMaximum", "/minimum", "/type"}},
+		{`{"type": "string", "minLength": 3, "maxLength": 5}`,
+			[]string{"/maxLength", "/minLength", "/type"}},
+		{`{"type": "string", "format": "date-time"}`, []string{"/format", "/type"}},
+		{`{"enum": ["red", "green"]}`, []string{"/enum"}},
+		{`{"type": "array", "items": {"type": "integer", "maximum": 5}, "minItems": 1, "maxItems": 3, "uniqueItems": true}`,
+			[]string{"/items/maximum", "/items/type", "/maxItems", "/minItems", "/type", "/uniqueItems"}},
+		{`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "minimum": 1},
+				"tag": {"$ref": "#/definitions/tag"}
+			},
+			"required": ["id", "tag"],
+			"additionalProperties": false,
+			"definitions": {
+				"tag": {"type": "string", "enum": ["a", "b"]}
+			}
+		}`, []string{
+			"/additionalProperties",
+			"/properties/id/minimum",
+			"/properties/id/type",
+			"/properties/tag/$ref/enum",
+			"/properties/tag/$ref/type",
+			"/required/0",
+			"/required/1",
+			"/type",
+		}},
+	}
+
+	for i, c := range cases {
+		rs := &RootSchema{}
+		if err := json.Unmarshal([]byte(c.schema), rs); err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		found, err := NewInstanceGenerator(int64(i)).GenerateInvalid(&rs.Schema)
+		if err != nil {
+			t.Errorf("case %d: %s", i, err)
+			continue
+		}
+		broken := map[string]bool{}
+		for _, inv := range found {
+			if isValid(&rs.Schema, inv.Instance) {
+				t.Errorf("case %d: instance breaking %s is valid: %v", i, inv.KeywordPath, inv.Instance)
+			}
+			broken[inv.KeywordPath] = true
+		}
+		for _, kw := range c.keywords {
+			if !broken[kw] {
+				t.Errorf("case %d: expected an instance breaking %s", i, kw)
+			}
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseJSON5 decodes a schema written as JSON5, or JSONC, converting it
+// with JSON5ToJSON
+func ParseJSON5(data []byte) (*RootSchema, error) {
+	js, err := JSON5ToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(js, rs); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %s", err.Error())
+	}
+	return rs, nil
+}
+
+// ValidateJSON5 performs schema validation against a JSON5 or JSONC
+// document, converted with JSON5ToJSON
+func (rs *RootSchema) ValidateJSON5(data []byte) ([]ValError, error) {
+	js, err := JSON5ToJSON(data)
+	if err != nil {
+		return []ValError{}, err
+	}
+	return rs.ValidateBytes(js)
+}
+
+// JSON5ToJSON converts a JSON5 document to standard JSON. JSON5 is a
+// superset of JSONC, JSON with comments and trailing commas as used by
+// many hand-written configuration files, and adds unquoted keys,
+// single-quoted strings, hexadecimal numbers and numbers with a leading
+// "+" or a leading or trailing decimal point. Comments and trailing commas
+// are replaced with whitespace, keeping every value on its line so
+// positions in the JSON can be reported as lines of the original. Object
+// keys keep their order. Infinity and NaN, which JSON can't represent, are
+// errors
+func JSON5ToJSON(data []byte) ([]byte, error) {
+	c := &json5Converter{src: data, out: &bytes.Buffer{}}
+	if err := c.value(); err != nil {
+		return nil, err
+	}
+	if err := c.skip(); err != nil {
+		return nil, err
+	}
+	if c.pos < len(c.src) {
+		return nil, c.errorf("unexpected %q after the top-level value", c.peekRune())
+	}
+	return c.out.Bytes(), nil
+}
+
+// json5Converter writes the JSON equivalent of the JSON5 in src to out
+type json5Converter struct {
+	src []byte
+	pos int
+	out *bytes.Buffer
+}
+
+func (c *json5Converter) errorf(format string, args ...interface{}) error {
+	line := bytes.Count(c.src[:c.pos], []byte{'\n'}) + 1
+	return fmt.Errorf("json5: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (c *json5Converter) peek() byte {
+	if c.pos >= len(c.src) {
+		return 0
+	}
+	return c.src[c.pos]
+}
+
+func (c *json5Converter) peekRune() rune {
+	r, _ := utf8.DecodeRune(c.src[c.pos:])
+	return r
+}
+
+// skip copies whitespace to out, replacing comments with spaces and the
+// line breaks within them
+func (c *json5Converter) skip() error {
+	for c.pos < len(c.src) {
+		switch r, size := utf8.DecodeRune(c.src[c.pos:]); {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			c.out.WriteRune(r)
+			c.pos += size
+		case r == '\v' || r == '\f' || r == '\ufeff' || r == '\u2028' || r == '\u2029' || unicode.Is(unicode.Zs, r):
+			c.out.WriteByte(' ')
+			c.pos += size
+		case bytes.HasPrefix(c.src[c.pos:], []byte("//")):
+			for c.pos < len(c.src) && c.src[c.pos] != '\n' {
+				c.out.WriteByte(' ')
+				c.pos++
+			}
+		case bytes.HasPrefix(c.src[c.pos:], []byte("/*")):
+			end := bytes.Index(c.src[c.pos+2:], []byte("*/"))
+			if end == -1 {
+				return c.errorf("unterminated comment")
+			}
+			for _, b := range c.src[c.pos : c.pos+end+4] {
+				if b == '\n' {
+					c.out.WriteByte('\n')
+				} else {
+					c.out.WriteByte(' ')
+				}
+			}
+			c.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// value converts a value
+func (c *json5Converter) value() error {
+	if err := c.skip(); err != nil {
+		return err
+	}
+	switch ch := c.peek(); {
+	case ch == '{':
+		return c.container('{', '}', true)
+	case ch == '[':
+		return c.container('[', ']', false)
+	case ch == '"' || ch == '\'':
+		str, err := c.str()
+		if err != nil {
+			return err
+		}
+		c.writeString(str)
+		return nil
+	case ch == 0:
+		return c.errorf("unexpected end of input")
+	case ch == '-' || ch == '+' || ch == '.' || (ch >= '0' && ch <= '9'):
+		return c.number()
+	}
+	ident := c.identifier()
+	switch ident {
+	case "true", "false", "null":
+		c.out.WriteString(ident)
+		return nil
+	case "Infinity", "NaN":
+		return c.errorf("%s can't be represented in JSON", ident)
+	case "":
+		return c.errorf("unexpected %q", c.peekRune())
+	}
+	return c.errorf("unexpected %q", ident)
+}
+
+// container converts an object or array, replacing a trailing comma with
+// a space
+func (c *json5Converter) container(open, closing byte, object bool) error {
+	c.out.WriteByte(open)
+	c.pos++
+	comma := -1
+	for {
+		if err := c.skip(); err != nil {
+			return err
+		}
+		if c.peek() == closing {
+			if comma != -1 {
+				c.out.Bytes()[comma] = ' '
+			}
+			c.out.WriteByte(closing)
+			c.pos++
+			return nil
+		}
+		if object {
+			if err := c.key(); err != nil {
+				return err
+			}
+		}
+		if err := c.value(); err != nil {
+			return err
+		}
+		if err := c.skip(); err != nil {
+			return err
+		}
+		comma = -1
+		switch c.peek() {
+		case ',':
+			comma = c.out.Len()
+			c.out.WriteByte(',')
+			c.pos++
+		case closing:
+		case 0:
+			return c.errorf("expected %q before the end of input", closing)
+		default:
+			return c.errorf("expected \",\" or %q, got %q", closing, c.peekRune())
+		}
+	}
+}
+
+// key converts an object key and the colon following it
+func (c *json5Converter) key() error {
+	var key string
+	if ch := c.peek(); ch == '"' || ch == '\'' {
+		str, err := c.str()
+		if err != nil {
+			return err
+		}
+		key = str
+	} else if key = c.identifier(); key == "" {
+		return c.errorf("expected an object key, got %q", c.peekRune())
+	}
+	c.writeString(key)
+	if err := c.skip(); err != nil {
+		return err
+	}
+	if c.peek() != ':' {
+		return c.errorf("expected \":\" after object key %q", key)
+	}
+	c.out.WriteByte(':')
+	c.pos++
+	return nil
+}
+
+// identifier reads an ECMAScript identifier name, as unquoted keys are
+func (c *json5Converter) identifier() string {
+	start := c.pos
+	for c.pos < len(c.src) {
+		r, size := utf8.DecodeRune(c.src[c.pos:])
+		if r == '_' || r == '$' || unicode.IsLetter(r) || (c.pos > start && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Pc, r))) {
+			c.pos += size
+			continue
+		}
+		break
+	}
+	return string(c.src[start:c.pos])
+}
+
+// json5Escapes maps the single-character escapes of JSON5 strings. Other
+// characters escape to themselves
+var json5Escapes = map[byte]string{
+	'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v", '0': "\x00",
+}
+
+// str reads a single or double-quoted string
+func (c *json5Converter) str() (string, error) {
+	quote := c.src[c.pos]
+	c.pos++
+	buf := &strings.Builder{}
+	for {
+		if c.pos >= len(c.src) {
+			return "", c.errorf("unterminated string")
+		}
+		ch := c.src[c.pos]
+		switch {
+		case ch == quote:
+			c.pos++
+			return buf.String(), nil
+		case ch == '\n' || ch == '\r':
+			return "", c.errorf("unescaped line break in string")
+		case ch != '\\':
+			buf.WriteByte(ch)
+			c.pos++
+			continue
+		}
+
+		// escapes
+		c.pos++
+		if c.pos >= len(c.src) {
+			return "", c.errorf("unterminated string")
+		}
+		esc := c.src[c.pos]
+		switch {
+		case esc == '\n':
+			// line continuations
+			c.pos++
+		case esc == '\r':
+			c.pos++
+			if c.peek() == '\n' {
+				c.pos++
+			}
+		case esc == '0' && c.pos+1 < len(c.src) && c.src[c.pos+1] >= '0' && c.src[c.pos+1] <= '9':
+			return "", c.errorf("octal escapes aren't allowed")
+		case json5Escapes[esc] != "":
+			buf.WriteString(json5Escapes[esc])
+			c.pos++
+		case esc == 'x' || esc == 'u':
+			digits := 2
+			if esc == 'u' {
+				digits = 4
+			}
+			if c.pos+1+digits > len(c.src) {
+				return "", c.errorf("invalid escape \\%c", esc)
+			}
+			code, err := strconv.ParseUint(string(c.src[c.pos+1:c.pos+1+digits]), 16, 32)
+			if err != nil {
+				return "", c.errorf("invalid escape \\%s", c.src[c.pos:c.pos+1+digits])
+			}
+			c.pos += 1 + digits
+			r := rune(code)
+			if esc == 'u' && r >= 0xd800 && r < 0xdc00 && bytes.HasPrefix(c.src[c.pos:], []byte(`\u`)) && c.pos+6 <= len(c.src) {
+				// surrogate pairs
+				if low, err := strconv.ParseUint(string(c.src[c.pos+2:c.pos+6]), 16, 32); err == nil && low >= 0xdc00 && low < 0xe000 {
+					r = (r-0xd800)<<10 + (rune(low) - 0xdc00) + 0x10000
+					c.pos += 6
+				}
+			}
+			buf.WriteRune(r)
+		default:
+			_, size := utf8.DecodeRune(c.src[c.pos:])
+			buf.Write(c.src[c.pos : c.pos+size])
+			c.pos += size
+		}
+	}
+}
+
+// writeString writes str as a JSON string
+func (c *json5Converter) writeString(str string) {
+	enc := json.NewEncoder(c.out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(str)
+	// Encode ends with a line break
+	c.out.Truncate(c.out.Len() - 1)
+}
+
+// json5Decimal matches the decimal numbers of JSON5
+var json5Decimal = regexp.MustCompile(`^[+-]?(?:(?:0|[1-9]\d*)(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?$`)
+
+// number converts a number
+func (c *json5Converter) number() error {
+	start := c.pos
+	for c.pos < len(c.src) {
+		ch := c.src[c.pos]
+		if ch == '+' || ch == '-' || ch == '.' || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') {
+			c.pos++
+			continue
+		}
+		break
+	}
+	token := string(c.src[start:c.pos])
+	sign, digits := "", token
+	if token[0] == '+' || token[0] == '-' {
+		sign, digits = strings.TrimPrefix(token[:1], "+"), token[1:]
+	}
+	switch {
+	case digits == "Infinity" || digits == "NaN":
+		c.pos = start
+		return c.errorf("%s can't be represented in JSON", token)
+	case strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X"):
+		n, err := strconv.ParseUint(digits[2:], 16, 64)
+		if err != nil {
+			c.pos = start
+			return c.errorf("invalid number %s", token)
+		}
+		c.out.WriteString(sign + strconv.FormatUint(n, 10))
+		return nil
+	case !json5Decimal.MatchString(token):
+		c.pos = start
+		return c.errorf("invalid number %s", token)
+	}
+	if strings.HasPrefix(digits, ".") {
+		digits = "0" + digits
+	}
+	if i := strings.IndexByte(digits, '.'); i != -1 && (i+1 == len(digits) || digits[i+1] < '0' || digits[i+1] > '9') {
+		digits = digits[:i] + digits[i+1:]
+	}
+	c.out.WriteString(sign + digits)
+	return nil
+}
@@ -0,0 +1,101 @@
+package jsonschema
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSON5ToJSON(t *testing.T) {
+	cases := []struct {
+		json5, json string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"{\n  // comment\n  \"a\": 1, /* inline */\n}", "{\n            \n  \"a\": 1              \n}"},
+		{"[1, 2, ]", `[1, 2  ]`},
+		{"{unquoted: 'single', $id_1: \"x\"}", `{"unquoted": "single", "$id_1": "x"}`},
+		{"['it\\'s', 'say \"hi\"', 'line\\\nbreak', '\\x41\\u00e9\\v\\q']", `["it's", "say \"hi\"", "linebreak", "Aé\u000bq"]`},
+		{"[0x1F, -0xa, +1, .5, 5., -.5e1, 1e3]", `[31, -10, 1, 0.5, 5, -0.5e1, 1e3]`},
+		{"[true, false, null]", `[true, false, null]`},
+		{"/* leading */ 'top'", `              "top"`},
+		{"{'<tag>': '\\ud83d\\ude00'}", `{"<tag>": "😀"}`},
+	}
+	for i, c := range cases {
+		got, err := JSON5ToJSON([]byte(c.json5))
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		if string(got) != c.json {
+			t.Errorf("case %d: expected %q, got %q", i, c.json, got)
+		}
+		if bytes.Count(got, []byte{'\n'}) != bytes.Count([]byte(c.json5), []byte{'\n'})-bytes.Count([]byte(c.json5), []byte("\\\n")) {
+			t.Errorf("case %d: expected lines to be kept", i)
+		}
+	}
+}
+
+func TestJSON5ToJSONErrors(t *testing.T) {
+	cases := []struct {
+		json5, err string
+	}{
+		{"", "json5: line 1: unexpected end of input"},
+		{"{a: 1", "json5: line 1: expected '}' before the end of input"},
+		{"[1,,2]", "json5: line 1: unexpected ','"},
+		{"[1 2]", "json5: line 1: expected \",\" or ']', got '2'"},
+		{"{\n\"a\" 1}", "json5: line 2: expected \":\" after object key \"a\""},
+		{"/* open", "json5: line 1: unterminated comment"},
+		{"'open", "json5: line 1: unterminated string"},
+		{"'a\nb'", "json5: line 1: unescaped line break in string"},
+		{"Infinity", "json5: line 1: Infinity can't be represented in JSON"},
+		{"-NaN", "json5: line 1: -NaN can't be represented in JSON"},
+		{"012", "json5: line 1: invalid number 012"},
+		{"{} {}", "json5: line 1: unexpected '{' after the top-level value"},
+		{"undefined", "json5: line 1: unexpected \"undefined\""},
+	}
+	for _, c := range cases {
+		_, err := JSON5ToJSON([]byte(c.json5))
+		if err == nil {
+			t.Errorf("%q: expected error %q", c.json5, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.json5, c.err, err.Error())
+		}
+	}
+}
+
+func TestParseJSON5(t *testing.T) {
+	rs, err := ParseJSON5([]byte(`{
+		// settings for the service
+		type: 'object',
+		properties: {
+			port: {type: 'integer', maximum: 0xFFFF},
+			host: {type: 'string'}, // defaults to localhost
+		},
+		/* no other settings */
+		additionalProperties: false,
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{
+		"type": "object",
+		"properties": {
+			"port": {"type": "integer", "maximum": 65535},
+			"host": {"type": "string"}
+		},
+		"additionalProperties": false
+	}`
+	assertJSONEqual(t, "ParseJSON5", expect, rs)
+
+	errs, err := rs.ValidateJSON5([]byte("{\n  port: 70000, // too high\n  host: 'a',\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].PropertyPath != "/port" {
+		t.Errorf("expected an error for port, got %v", errs)
+	}
+	if _, err := rs.ValidateJSON5([]byte("{port: }")); err == nil {
+		t.Error("expected an error for invalid JSON5")
+	}
+}
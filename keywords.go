@@ -3,7 +3,6 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strconv"
 	"strings"
 )
@@ -32,6 +31,15 @@ func DataType(data interface{}) string {
 			return "integer"
 		}
 		return "number"
+	case json.Number:
+		n, ok := bigNumber(v)
+		if !ok {
+			return "unknown"
+		}
+		if n.IsInt() {
+			return "integer"
+		}
+		return "number"
 	case string:
 		return "string"
 	case []interface{}:
@@ -213,7 +221,7 @@ func (c Const) Validate(propPath string, data interface{}, errs *[]ValError) {
 		return
 	}
 
-	if !reflect.DeepEqual(con, data) {
+	if !valuesEqual(con, data) {
 		AddError(errs, propPath, data, fmt.Sprintf(`must equal %s`, InvalidValueString(con)))
 	}
 }
@@ -3,7 +3,6 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strconv"
 
 	"github.com/qri-io/jsonpointer"
@@ -31,6 +30,10 @@ func NewItems() Validator {
 
 // Validate implements the Validator interface for Items
 func (it Items) Validate(propPath string, data interface{}, errs *[]ValError) {
+	it.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (it Items) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, fmt.Sprintf("invalid property path: %s", err.Error()))
@@ -40,13 +43,13 @@ func (it Items) Validate(propPath string, data interface{}, errs *[]ValError) {
 		if it.single {
 			for i, elem := range arr {
 				d, _ := jp.Descendant(strconv.Itoa(i))
-				it.Schemas[0].Validate(d.String(), elem, errs)
+				it.Schemas[0].validateIn(vd, d.String(), elem, errs)
 			}
 		} else {
 			for i, vs := range it.Schemas {
 				if i < len(arr) {
 					d, _ := jp.Descendant(strconv.Itoa(i))
-					vs.Validate(d.String(), arr[i], errs)
+					vs.validateIn(vd, d.String(), arr[i], errs)
 				}
 			}
 		}
@@ -115,6 +118,10 @@ func NewAdditionalItems() Validator {
 
 // Validate implements the Validator interface for AdditionalItems
 func (a *AdditionalItems) Validate(propPath string, data interface{}, errs *[]ValError) {
+	a.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (a *AdditionalItems) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, fmt.Sprintf("invalid property path: %s", err.Error()))
@@ -127,7 +134,7 @@ func (a *AdditionalItems) Validate(propPath string, data interface{}, errs *[]Va
 					continue
 				}
 				d, _ := jp.Descendant(strconv.Itoa(i))
-				a.Schema.Validate(d.String(), elem, errs)
+				a.Schema.validateIn(vd, d.String(), elem, errs)
 			}
 		}
 	}
@@ -158,6 +165,11 @@ func (a *AdditionalItems) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON implements the json.Marshaler interface for AdditionalItems
+func (a AdditionalItems) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Schema)
+}
+
 // MaxItems MUST be a non-negative integer.
 // An array instance is valid against "MaxItems" if its size is less than, or equal to, the value of this keyword.
 type MaxItems int
@@ -214,7 +226,7 @@ func (u *UniqueItems) Validate(propPath string, data interface{}, errs *[]ValErr
 		found := []interface{}{}
 		for _, elem := range arr {
 			for _, f := range found {
-				if reflect.DeepEqual(f, elem) {
+				if valuesEqual(f, elem) {
 					AddError(errs, propPath, data, fmt.Sprintf("array items must be unique. duplicated entry: %v", elem))
 					return
 				}
@@ -235,11 +247,15 @@ func NewContains() Validator {
 
 // Validate implements the Validator interface for Contains
 func (c *Contains) Validate(propPath string, data interface{}, errs *[]ValError) {
+	c.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (c *Contains) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	v := Schema(*c)
 	if arr, ok := data.([]interface{}); ok {
 		for _, elem := range arr {
 			test := &[]ValError{}
-			v.Validate(propPath, elem, test)
+			v.validateIn(vd, propPath, elem, test)
 			if len(*test) == 0 {
 				return
 			}
@@ -267,3 +283,8 @@ func (c *Contains) UnmarshalJSON(data []byte) error {
 	*c = Contains(sch)
 	return nil
 }
+
+// MarshalJSON implements the json.Marshaler interface for Contains
+func (c Contains) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Schema(c))
+}
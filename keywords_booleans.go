@@ -2,7 +2,9 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 // AllOf MUST be a non-empty array. Each item of the array MUST be a valid JSON Schema.
@@ -16,8 +18,12 @@ func NewAllOf() Validator {
 
 // Validate implements the validator interface for AllOf
 func (a AllOf) Validate(propPath string, data interface{}, errs *[]ValError) {
+	a.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (a AllOf) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	for _, sch := range a {
-		sch.Validate(propPath, data, errs)
+		sch.validateIn(vd, propPath, data, errs)
 	}
 }
 
@@ -54,9 +60,13 @@ func NewAnyOf() Validator {
 
 // Validate implements the validator interface for AnyOf
 func (a AnyOf) Validate(propPath string, data interface{}, errs *[]ValError) {
+	a.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (a AnyOf) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	for _, sch := range a {
 		test := &[]ValError{}
-		sch.Validate(propPath, data, test)
+		sch.validateIn(vd, propPath, data, test)
 		if len(*test) == 0 {
 			return
 		}
@@ -96,10 +106,14 @@ func NewOneOf() Validator {
 
 // Validate implements the validator interface for OneOf
 func (o OneOf) Validate(propPath string, data interface{}, errs *[]ValError) {
+	o.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (o OneOf) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	matched := false
 	for _, sch := range o {
 		test := &[]ValError{}
-		sch.Validate(propPath, data, test)
+		sch.validateIn(vd, propPath, data, test)
 		if len(*test) == 0 {
 			if matched {
 				AddError(errs, propPath, data, "matched more than one specified OneOf schemas")
@@ -134,6 +148,86 @@ func (o OneOf) JSONChildren() (res map[string]JSONPather) {
 	return
 }
 
+// Discriminator is OpenAPI's "discriminator" keyword. Alongside "oneOf" it
+// names the property of object instances whose value picks the branch to
+// validate against, so the other branches are skipped and errors are about
+// the intended variant. Values are looked up in Mapping, which maps them to
+// references or names, and otherwise name the branch whose reference ends
+// with them. Instances other than objects are validated by "oneOf" as usual
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+	oneOf        *OneOf
+}
+
+// NewDiscriminator creates a new Discriminator validator
+func NewDiscriminator() Validator {
+	return &Discriminator{}
+}
+
+// Validate implements the validator interface for Discriminator
+func (d Discriminator) Validate(propPath string, data interface{}, errs *[]ValError) {
+	d.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (d Discriminator) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
+	if d.oneOf == nil {
+		return
+	}
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		d.oneOf.validateIn(vd, propPath, data, errs)
+		return
+	}
+	name, ok := obj[d.PropertyName].(string)
+	if !ok {
+		AddError(errs, propPath, data, fmt.Sprintf("discriminator property %q must be a string naming a OneOf variant", d.PropertyName))
+		return
+	}
+	sch := d.variant(name)
+	if sch == nil {
+		AddError(errs, propPath, data, fmt.Sprintf("discriminator %q value %q doesn't name a OneOf variant", d.PropertyName, name))
+		return
+	}
+	test := &[]ValError{}
+	sch.validateIn(vd, propPath, data, test)
+	for _, err := range *test {
+		err.Message = fmt.Sprintf("%s (as %s variant)", err.Message, name)
+		*errs = append(*errs, err)
+	}
+}
+
+// variant gives the oneOf branch the discriminator value name picks, or nil
+func (d Discriminator) variant(name string) *Schema {
+	ref := name
+	if mapped, ok := d.Mapping[name]; ok {
+		ref = mapped
+	}
+	for _, sch := range *d.oneOf {
+		if sch.Ref == "" {
+			continue
+		}
+		if sch.Ref == ref || sch.Ref[strings.LastIndexAny(sch.Ref, "/#")+1:] == ref {
+			return sch
+		}
+	}
+	return nil
+}
+
+// Nullable is OpenAPI 3.0's "nullable" keyword. When true alongside "type",
+// null instances are valid against "type" and "enum" too, as if "null" were
+// among their values
+type Nullable bool
+
+// NewNullable creates a new Nullable validator
+func NewNullable() Validator {
+	return new(Nullable)
+}
+
+// Validate implements the validator interface for Nullable. Schemas apply
+// it by skipping "type" and "enum" for null instances
+func (n Nullable) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
 // Not MUST be a valid JSON Schema.
 // An instance is valid against this keyword if it fails to validate successfully against the schema defined
 // by this keyword.
@@ -146,9 +240,13 @@ func NewNot() Validator {
 
 // Validate implements the validator interface for Not
 func (n *Not) Validate(propPath string, data interface{}, errs *[]ValError) {
+	n.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (n *Not) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	sch := Schema(*n)
 	test := &[]ValError{}
-	sch.Validate(propPath, data, test)
+	sch.validateIn(vd, propPath, data, test)
 	if len(*test) == 0 {
 		// TODO - make this error actually make sense
 		AddError(errs, propPath, data, "cannot match schema")
@@ -160,13 +258,13 @@ func (n Not) JSONProp(name string) interface{} {
 	return Schema(n).JSONProp(name)
 }
 
-// JSONChildren implements the JSONContainer interface for Not
-func (n Not) JSONChildren() (res map[string]JSONPather) {
+// JSONChildren implements the JSONContainer interface for Not. A reference
+// is given as the schema itself, so that resolving it links n
+func (n *Not) JSONChildren() (res map[string]JSONPather) {
 	if n.Ref != "" {
-		s := Schema(n)
-		return map[string]JSONPather{"$ref": &s}
+		return map[string]JSONPather{"$ref": (*Schema)(n)}
 	}
-	return Schema(n).JSONChildren()
+	return Schema(*n).JSONChildren()
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface for Not
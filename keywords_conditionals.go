@@ -21,20 +21,24 @@ func NewIf() Validator {
 
 // Validate implements the Validator interface for If
 func (i *If) Validate(propPath string, data interface{}, errs *[]ValError) {
+	i.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (i *If) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	test := &[]ValError{}
-	i.Schema.Validate(propPath, data, test)
+	i.Schema.validateIn(vd, propPath, data, test)
 	if len(*test) == 0 {
 		if i.Then != nil {
 			s := Schema(*i.Then)
 			sch := &s
-			sch.Validate(propPath, data, errs)
+			sch.validateIn(vd, propPath, data, errs)
 			return
 		}
 	} else {
 		if i.Else != nil {
 			s := Schema(*i.Else)
 			sch := &s
-			sch.Validate(propPath, data, errs)
+			sch.validateIn(vd, propPath, data, errs)
 			return
 		}
 	}
@@ -3,31 +3,14 @@ package jsonschema
 import (
 	// "encoding/json"
 	"fmt"
-	"net"
 	"net/mail"
-	"net/url"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
-)
-
-const (
-	hostname       string = `^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9]))*$`
-	unescapedTilda        = `\~[^01]`
-	endingTilda           = `\~$`
-	schemePrefix          = `^[^\:]+\:`
-	uriTemplate           = `\{[^\{\}\\]*\}`
+	"unicode/utf8"
 )
 
 var (
 	// emailPattern           = regexp.MustCompile(email)
-	hostnamePattern        = regexp.MustCompile(hostname)
-	unescaptedTildaPattern = regexp.MustCompile(unescapedTilda)
-	endingTildaPattern     = regexp.MustCompile(endingTilda)
-	schemePrefixPattern    = regexp.MustCompile(schemePrefix)
-	uriTemplatePattern     = regexp.MustCompile(uriTemplate)
-
 	disallowedIdnChars = map[string]bool{"\u0020": true, "\u002D": true, "\u00A2": true, "\u00A3": true, "\u00A4": true, "\u00A5": true, "\u034F": true, "\u0640": true, "\u07FA": true, "\u180B": true, "\u180C": true, "\u180D": true, "\u200B": true, "\u2060": true, "\u2104": true, "\u2108": true, "\u2114": true, "\u2117": true, "\u2118": true, "\u211E": true, "\u211F": true, "\u2123": true, "\u2125": true, "\u2282": true, "\u2283": true, "\u2284": true, "\u2285": true, "\u2286": true, "\u2287": true, "\u2288": true, "\u2616": true, "\u2617": true, "\u2619": true, "\u262F": true, "\u2638": true, "\u266C": true, "\u266D": true, "\u266F": true, "\u2752": true, "\u2756": true, "\u2758": true, "\u275E": true, "\u2761": true, "\u2775": true, "\u2794": true, "\u2798": true, "\u27AF": true, "\u27B1": true, "\u27BE": true, "\u3004": true, "\u3012": true, "\u3013": true, "\u3020": true, "\u302E": true, "\u302F": true, "\u3031": true, "\u3032": true, "\u3035": true, "\u303B": true, "\u3164": true, "\uFFA0": true}
 )
 
@@ -59,43 +42,52 @@ func NewFormat() Validator {
 
 // Validate validates input against a keyword
 func (f Format) Validate(propPath string, data interface{}, errs *[]ValError) {
+	f.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (f Format) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	var err error
+	opts := vd.options().Formats
 	if str, ok := data.(string); ok {
 		switch f {
 		case "date-time":
-			err = isValidDateTime(str)
+			err = isValidDateTime(opts.Time, str)
 		case "date":
-			err = isValidDate(str)
+			err = isValidDate(opts.Time, str)
+		case "duration":
+			err = isValidDuration(str)
 		case "email":
-			err = isValidEmail(str)
+			err = isValidEmail(opts.Email, str)
 		case "hostname":
-			err = isValidHostname(str)
+			err = isValidHostname(opts.Hostname, str)
 		case "idn-email":
 			err = isValidIDNEmail(str)
 		case "idn-hostname":
 			err = isValidIDNHostname(str)
 		case "ipv4":
-			err = isValidIPv4(str)
+			err = isValidIPv4(opts.IP, str)
 		case "ipv6":
-			err = isValidIPv6(str)
+			err = isValidIPv6(opts.IP, str)
 		case "iri-reference":
-			err = isValidIriRef(str)
+			err = isValidIriRef(opts.URI, str)
 		case "iri":
-			err = isValidIri(str)
+			err = isValidIri(opts.URI, str)
 		case "json-pointer":
 			err = isValidJSONPointer(str)
 		case "regex":
-			err = isValidRegex(str)
+			err = isValidRegex(opts.Regex, str)
 		case "relative-json-pointer":
 			err = isValidRelJSONPointer(str)
 		case "time":
-			err = isValidTime(str)
+			err = isValidTime(opts.Time, str)
 		case "uri-reference":
-			err = isValidURIRef(str)
+			err = isValidURIRef(opts.URI, str)
 		case "uri-template":
 			err = isValidURITemplate(str)
 		case "uri":
-			err = isValidURI(str)
+			err = isValidURI(opts.URI, str)
+		case "uuid":
+			err = opts.UUID.check(str)
 		default:
 			err = nil
 		}
@@ -109,7 +101,15 @@ func (f Format) Validate(propPath string, data interface{}, errs *[]ValError) {
 // representation according to the "date-time" production derived
 // from RFC 3339, section 5.6 [RFC3339]
 // https://tools.ietf.org/html/rfc3339#section-5.6
-func isValidDateTime(dateTime string) error {
+// The options may accept other layouts
+func isValidDateTime(o TimeFormatOptions, dateTime string) error {
+	if err := parseRFC3339(dateTime); err != nil && !o.accepts("date-time", dateTime) {
+		return err
+	}
+	return nil
+}
+
+func parseRFC3339(dateTime string) error {
 	if _, err := time.Parse(time.RFC3339, dateTime); err != nil {
 		return fmt.Errorf("date-time incorrectly Formatted: %s", err.Error())
 	}
@@ -120,107 +120,222 @@ func isValidDateTime(dateTime string) error {
 // representation according to the "full-date" production derived
 // from RFC 3339, section 5.6 [RFC3339]
 // https://tools.ietf.org/html/rfc3339#section-5.6
-func isValidDate(date string) error {
+func isValidDate(o TimeFormatOptions, date string) error {
 	arbitraryTime := "T08:30:06.283185Z"
 	dateTime := fmt.Sprintf("%s%s", date, arbitraryTime)
-	return isValidDateTime(dateTime)
+	if err := parseRFC3339(dateTime); err != nil && !o.accepts("date", date) {
+		return err
+	}
+	return nil
 }
 
-// A string instance is valid against "email" if it is a valid
-// representation as defined by RFC 5322, section 3.4.1 [RFC5322].
-// https://tools.ietf.org/html/rfc5322#section-3.4.1
-func isValidEmail(email string) error {
-	// if !emailPattern.MatchString(email) {
-	// 	return fmt.Errorf("invalid email Format")
-	// }
-	if _, err := mail.ParseAddress(email); err != nil {
-		return fmt.Errorf("email address incorrectly Formatted: %s", err.Error())
+// A string instance is valid against "duration" if it is a valid
+// representation according to the "duration" production of RFC 3339,
+// appendix A, an ISO 8601 duration. As ISO 8601 allows, components may be
+// left out, and the last may have a fraction, such as "PT1.5S" or "P0,5D".
+// Weeks can't be combined with other components
+// https://tools.ietf.org/html/rfc3339#appendix-A
+func isValidDuration(dur string) error {
+	if !strings.HasPrefix(dur, "P") {
+		return fmt.Errorf("durations must begin with P")
+	}
+	units := "YMDW"
+	last := -1
+	inTime, week, components, fraction := false, false, 0, false
+	for i := 1; i < len(dur); {
+		if dur[i] == 'T' {
+			if inTime || week {
+				return fmt.Errorf("unexpected T at offset %d", i)
+			}
+			if i+1 == len(dur) {
+				return fmt.Errorf("T must be followed by a time component")
+			}
+			inTime, units, last = true, "HMS", -1
+			i++
+			continue
+		}
+		if fraction {
+			return fmt.Errorf("only the last component may have a fraction")
+		}
+		start := i
+		for i < len(dur) && dur[i] >= '0' && dur[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return fmt.Errorf("expected a number at offset %d", start)
+		}
+		if i < len(dur) && (dur[i] == '.' || dur[i] == ',') {
+			i++
+			digits := i
+			for i < len(dur) && dur[i] >= '0' && dur[i] <= '9' {
+				i++
+			}
+			if i == digits {
+				return fmt.Errorf("expected the digits of a fraction at offset %d", digits)
+			}
+			fraction = true
+		}
+		if i == len(dur) {
+			return fmt.Errorf("%s is missing a designator", dur[start:])
+		}
+		if week || (dur[i] == 'W' && !inTime && components > 0) {
+			return fmt.Errorf("weeks can't be combined with other components")
+		}
+		unit := strings.IndexByte(units, dur[i])
+		if unit == -1 || unit <= last {
+			return fmt.Errorf("unexpected designator %c at offset %d", dur[i], i)
+		}
+		week = dur[i] == 'W'
+		last = unit
+		components++
+		i++
+	}
+	if components == 0 {
+		return fmt.Errorf("durations must have at least one component")
 	}
 	return nil
 }
 
+// A string instance is valid against "email" if it is a valid
+// representation as defined by RFC 5322, section 3.4.1 [RFC5322]. The
+// "Mailbox" of RFC 5321, section 4.1.2, is accepted unless the options
+// select another profile
+// https://tools.ietf.org/html/rfc5322#section-3.4.1
+// https://tools.ietf.org/html/rfc5321#section-4.1.2
+func isValidEmail(o EmailFormatOptions, email string) error {
+	return o.check(email)
+}
+
 // A string instance is valid against "hostname" if it is a valid
 // representation as defined by RFC 1034, section 3.1 [RFC1034],
 // including host names produced using the Punycode algorithm
 // specified in RFC 5891, section 4.4 [RFC5891].
 // https://tools.ietf.org/html/rfc1034#section-3.1
 // https://tools.ietf.org/html/rfc5891#section-4.4
-func isValidHostname(hostname string) error {
-	if !hostnamePattern.MatchString(hostname) || len(hostname) > 255 {
-		return fmt.Errorf("invalid hostname string")
-	}
-	return nil
+func isValidHostname(o HostnameFormatOptions, hostname string) error {
+	return o.check(hostname)
 }
 
 // A string instance is valid against "idn-email" if it is a valid
-// representation as defined by RFC 6531 [RFC6531]
+// representation as defined by RFC 6531 [RFC6531]: an address whose local
+// part may hold UTF-8 and whose domain is an internationalized host name
+// or an address literal
 // https://tools.ietf.org/html/rfc6531
 func isValidIDNEmail(idnEmail string) error {
-	if _, err := mail.ParseAddress(idnEmail); err != nil {
+	addr, err := mail.ParseAddress(idnEmail)
+	if err != nil {
 		return fmt.Errorf("email address incorrectly Formatted: %s", err.Error())
 	}
+	domain := addr.Address[strings.LastIndex(addr.Address, "@")+1:]
+	if strings.HasPrefix(domain, "[") && strings.HasSuffix(domain, "]") {
+		return nil
+	}
+	if err := isValidIDNHostnameLabels(domain); err != nil {
+		return fmt.Errorf("invalid domain: %s", err.Error())
+	}
 	return nil
 }
 
-// A string instance is valid against "hostname" if it is a valid
+// A string instance is valid against "idn-hostname" if it is a valid
 // representation as defined by either RFC 1034 as for hostname, or
 // an internationalized hostname as defined by RFC 5890, section
-// 2.3.2.3 [RFC5890].
+// 2.3.2.3 [RFC5890]. Labels are checked as RFC 5891 and RFC 5892 require,
+// decoding A-labels from Punycode
 // https://tools.ietf.org/html/rfc1034
 // https://tools.ietf.org/html/rfc5890#section-2.3.2.3
+// https://tools.ietf.org/html/rfc5891#section-5.4
 // https://pdfs.semanticscholar.org/9275/6bcecb29d3dc407e23a997b256be6ff4149d.pdf
 func isValidIDNHostname(idnHostname string) error {
-	if len(idnHostname) > 255 {
-		return fmt.Errorf("invalid idn hostname string")
-	}
-	for _, r := range idnHostname {
-		s := string(r)
-		if disallowedIdnChars[s] {
-			return fmt.Errorf("invalid hostname: contains illegal character %#U", r)
-		}
-	}
-	return nil
+	return isValidIDNHostnameLabels(idnHostname)
 }
 
 // A string instance is valid against "ipv4" if it is a valid
 // representation of an IPv4 address according to the "dotted-quad"
 // ABNF syntax as defined in RFC 2673, section 3.2 [RFC2673].
 // https://tools.ietf.org/html/rfc2673#section-3.2
-func isValidIPv4(ipv4 string) error {
-	parsedIP := net.ParseIP(ipv4)
-	hasDots := strings.Contains(ipv4, ".")
-	if !hasDots || parsedIP == nil {
-		return fmt.Errorf("invalid IPv4 address")
-	}
-	return nil
+func isValidIPv4(o IPFormatOptions, ipv4 string) error {
+	return o.checkIPv4(ipv4)
 }
 
 // A string instance is valid against "ipv6" if it is a valid
 // representation of an IPv6 address as defined in RFC 4291, section
 // 2.2 [RFC4291].
 // https://tools.ietf.org/html/rfc4291#section-2.2
-func isValidIPv6(ipv6 string) error {
-	parsedIP := net.ParseIP(ipv6)
-	hasColons := strings.Contains(ipv6, ":")
-	if !hasColons || parsedIP == nil {
-		return fmt.Errorf("invalid IPv4 address")
-	}
-	return nil
+func isValidIPv6(o IPFormatOptions, ipv6 string) error {
+	return o.checkIPv6(ipv6)
 }
 
 // A string instance is a valid against "iri-reference" if it is a
 // valid IRI Reference (either an IRI or a relative-reference),
 // according to [RFC3987].
 // https://tools.ietf.org/html/rfc3987
-func isValidIriRef(iriRef string) error {
-	return isValidURIRef(iriRef)
+func isValidIriRef(o URIFormatOptions, iriRef string) error {
+	uriRef, err := iriToURI(o.escape(iriRef))
+	if err != nil {
+		return err
+	}
+	return isValidURIRef(o, uriRef)
 }
 
 // A string instance is a valid against "iri" if it is a valid IRI,
 // according to [RFC3987].
 // https://tools.ietf.org/html/rfc3987
-func isValidIri(iri string) error {
-	return isValidURI(iri)
+func isValidIri(o URIFormatOptions, iri string) error {
+	uri, err := iriToURI(o.escape(iri))
+	if err != nil {
+		return err
+	}
+	return isValidURI(o, uri)
+}
+
+// iriToURI maps an IRI to a URI as RFC 3987, section 3.1 does, percent
+// encoding its Unicode characters, after checking they're allowed:
+// "ucschar" anywhere and "iprivate" in the query
+// https://tools.ietf.org/html/rfc3987#section-3.1
+func iriToURI(iri string) (string, error) {
+	buf := &strings.Builder{}
+	query, fragment := false, false
+	for i, r := range iri {
+		switch r {
+		case '?':
+			query = !fragment
+		case '#':
+			query, fragment = false, true
+		}
+		if r < utf8.RuneSelf {
+			if r <= ' ' || r == 0x7f || strings.ContainsRune(`<>"{}|\^`+"`", r) {
+				return "", fmt.Errorf("invalid character %q at offset %d", r, i)
+			}
+			buf.WriteRune(r)
+			continue
+		}
+		if !isUcschar(r) && !(query && isIprivate(r)) {
+			return "", fmt.Errorf("invalid character %#U at offset %d", r, i)
+		}
+		for _, b := range []byte(string(r)) {
+			fmt.Fprintf(buf, "%%%02X", b)
+		}
+	}
+	return buf.String(), nil
+}
+
+// isUcschar reports whether r is a "ucschar" of RFC 3987: a Unicode
+// character beyond ASCII that isn't a control, surrogate, private use
+// character or noncharacter
+func isUcschar(r rune) bool {
+	switch {
+	case r >= 0xa0 && r <= 0xd7ff, r >= 0xf900 && r <= 0xfdcf, r >= 0xfdf0 && r <= 0xffef:
+		return true
+	case r >= 0x10000 && r <= 0xefffd:
+		return r&0xfffe != 0xfffe && !(r >= 0xe0000 && r < 0xe1000)
+	}
+	return false
+}
+
+// isIprivate reports whether r is a private use character, which RFC 3987
+// only allows in queries
+func isIprivate(r rune) bool {
+	return r >= 0xe000 && r <= 0xf8ff || r >= 0xf0000 && r <= 0xffffd || r >= 0x100000 && r <= 0x10fffd
 }
 
 // A string instance is a valid against "json-pointer" if it is a
@@ -234,12 +349,10 @@ func isValidJSONPointer(jsonPointer string) error {
 	if jsonPointer[0] != '/' {
 		return fmt.Errorf("non-empty references must begin with a '/' character")
 	}
-	str := jsonPointer[1:]
-	if unescaptedTildaPattern.MatchString(str) {
-		return fmt.Errorf("unescaped tilda error")
-	}
-	if endingTildaPattern.MatchString(str) {
-		return fmt.Errorf("unescaped tilda error")
+	for i := 0; i < len(jsonPointer); i++ {
+		if jsonPointer[i] == '~' && (i+1 == len(jsonPointer) || (jsonPointer[i+1] != '0' && jsonPointer[i+1] != '1')) {
+			return fmt.Errorf("unescaped tilda at offset %d: ~ must be followed by 0 or 1", i)
+		}
 	}
 	return nil
 }
@@ -253,40 +366,28 @@ func isValidJSONPointer(jsonPointer string) error {
 // http://www.ecma-international.org/publications/files/ECMA-ST/Ecma-262.pdf
 // http://json-schema.org/latest/jsoxn-schema-validation.html#regexInterop
 // https://tools.ietf.org/html/rfc7159
-func isValidRegex(regex string) error {
-	if _, err := regexp.Compile(regex); err != nil {
-		return fmt.Errorf("invalid regex expression")
-	}
-	return nil
+func isValidRegex(o RegexFormatOptions, regex string) error {
+	return o.check(regex)
 }
 
 // A string instance is a valid against "relative-json-pointer" if it
 // is a valid Relative JSON Pointer [relative-json-pointer].
 // https://tools.ietf.org/html/draft-handrews-relative-json-pointer-00
 func isValidRelJSONPointer(relJSONPointer string) error {
-	parts := strings.Split(relJSONPointer, "/")
-	if len(parts) == 1 {
-		parts = strings.Split(relJSONPointer, "#")
-	}
-	if i, err := strconv.Atoi(parts[0]); err != nil || i < 0 {
-		return fmt.Errorf("RJP must begin with positive integer")
-	}
-	//skip over first part
-	str := relJSONPointer[len(parts[0]):]
-	if len(str) > 0 && str[0] == '#' {
-		return nil
-	}
-	return isValidJSONPointer(str)
+	_, err := ParseRelativePointer(relJSONPointer)
+	return err
 }
 
 // A string instance is valid against "time" if it is a valid
 // representation according to the "full-time" production derived
 // from RFC 3339, section 5.6 [RFC3339]
 // https://tools.ietf.org/html/rfc3339#section-5.6
-func isValidTime(time string) error {
+func isValidTime(o TimeFormatOptions, time string) error {
 	arbitraryDate := "1963-06-19"
 	dateTime := fmt.Sprintf("%sT%s", arbitraryDate, time)
-	return isValidDateTime(dateTime)
+	if err := parseRFC3339(dateTime); err != nil && !o.accepts("time", time) {
+		return err
+	}
 	return nil
 }
 
@@ -294,14 +395,8 @@ func isValidTime(time string) error {
 // valid URI Reference (either a URI or a relative-reference),
 // according to [RFC3986].
 // https://tools.ietf.org/html/rfc3986
-func isValidURIRef(uriRef string) error {
-	if _, err := url.Parse(uriRef); err != nil {
-		return fmt.Errorf("uri incorrectly Formatted: %s", err.Error())
-	}
-	if strings.Contains(uriRef, "\\") {
-		return fmt.Errorf("invalid uri")
-	}
-	return nil
+func isValidURIRef(o URIFormatOptions, uriRef string) error {
+	return o.check(uriRef, false)
 }
 
 // A string instance is a valid against "uri-template" if it is a
@@ -310,23 +405,13 @@ func isValidURIRef(uriRef string) error {
 // Template specification.
 // https://tools.ietf.org/html/rfc6570
 func isValidURITemplate(uriTemplate string) error {
-	arbitraryValue := "aaa"
-	uriRef := uriTemplatePattern.ReplaceAllString(uriTemplate, arbitraryValue)
-	if strings.Contains(uriRef, "{") || strings.Contains(uriRef, "}") {
-		return fmt.Errorf("invalid uri template")
-	}
-	return isValidURIRef(uriRef)
+	_, err := ParseURITemplate(uriTemplate)
+	return err
 }
 
 // A string instance is a valid against "uri" if it is a valid URI,
 // according to [RFC3986].
 // https://tools.ietf.org/html/rfc3986
-func isValidURI(uri string) error {
-	if _, err := url.Parse(uri); err != nil {
-		return fmt.Errorf("uri incorrectly Formatted: %s", err.Error())
-	}
-	if !schemePrefixPattern.MatchString(uri) {
-		return fmt.Errorf("uri missing scheme prefix")
-	}
-	return nil
+func isValidURI(o URIFormatOptions, uri string) error {
+	return o.check(uri, true)
 }
@@ -1,7 +1,9 @@
 package jsonschema
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 )
 
 // MultipleOf MUST be a number, strictly greater than 0.
@@ -16,11 +18,33 @@ func NewMultipleOf() Validator {
 
 // Validate implements the Validator interface for MultipleOf
 func (m MultipleOf) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
+	switch num := data.(type) {
+	case float64:
 		div := num / float64(m)
 		if float64(int(div)) != div {
 			AddError(errs, propPath, data, fmt.Sprintf("must be a multiple of %f", m))
 		}
+	case json.Number:
+		if r, ok := ratNumber(num); ok {
+			if mr, ok := ratNumber(float64(m)); ok && mr.Sign() != 0 {
+				if !new(big.Rat).Quo(r, mr).IsInt() {
+					AddError(errs, propPath, data, fmt.Sprintf("must be a multiple of %f", m))
+				}
+				return
+			}
+		}
+		n, ok := bigNumber(num)
+		if !ok {
+			return
+		}
+		mf, ok := bigNumber(float64(m))
+		if !ok || mf.Sign() == 0 {
+			return
+		}
+		div := new(big.Float).SetPrec(numberPrec).Quo(n, mf)
+		if !div.IsInt() {
+			AddError(errs, propPath, data, fmt.Sprintf("must be a multiple of %f", m))
+		}
 	}
 }
 
@@ -36,8 +60,8 @@ func NewMaximum() Validator {
 
 // Validate implements the Validator interface for Maximum
 func (m Maximum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num > float64(m) {
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c > 0 {
 			AddError(errs, propPath, data, fmt.Sprintf("must be less than or equal to %f", m))
 		}
 	}
@@ -55,8 +79,8 @@ func NewExclusiveMaximum() Validator {
 
 // Validate implements the Validator interface for ExclusiveMaximum
 func (m ExclusiveMaximum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num >= float64(m) {
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c >= 0 {
 			AddError(errs, propPath, data, fmt.Sprintf("must be less than %f", m))
 		}
 	}
@@ -73,8 +97,8 @@ func NewMinimum() Validator {
 
 // Validate implements the Validator interface for Minimum
 func (m Minimum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num < float64(m) {
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c < 0 {
 			AddError(errs, propPath, data, fmt.Sprintf("must be greater than or equal to %f", m))
 		}
 	}
@@ -91,8 +115,8 @@ func NewExclusiveMinimum() Validator {
 
 // Validate implements the Validator interface for ExclusiveMinimum
 func (m ExclusiveMinimum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num <= float64(m) {
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c <= 0 {
 			AddError(errs, propPath, data, fmt.Sprintf("must be greater than %f", m))
 		}
 	}
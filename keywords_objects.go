@@ -1,11 +1,13 @@
 package jsonschema
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/qri-io/jsonpointer"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // MaxProperties MUST be a non-negative integer.
@@ -55,17 +57,43 @@ func NewRequired() Validator {
 	return &Required{}
 }
 
-// Validate implements the validator interface for Required
+// Validate implements the validator interface for Required. Every missing
+// property is listed in a single error
 func (r Required) Validate(propPath string, data interface{}, errs *[]ValError) {
 	if obj, ok := data.(map[string]interface{}); ok {
+		missing := []string{}
 		for _, key := range r {
 			if val, ok := obj[key]; val == nil && !ok {
-				AddError(errs, propPath, data, fmt.Sprintf(`"%s" value is required`, key))
+				missing = append(missing, key)
 			}
 		}
+		addRequiredError(errs, propPath, data, missing)
 	}
 }
 
+// addRequiredError appends an error listing the missing required
+// properties to errs, if any are missing
+func addRequiredError(errs *[]ValError, propPath string, data interface{}, missing []string) {
+	if len(missing) == 0 {
+		return
+	}
+	*errs = append(*errs, ValError{
+		PropertyPath:      propPath,
+		InvalidValue:      data,
+		Message:           requiredMessage(missing),
+		MissingProperties: missing,
+	})
+}
+
+// requiredMessage gives the message of the error for the missing required
+// properties keys
+func requiredMessage(keys []string) string {
+	if len(keys) == 1 {
+		return fmt.Sprintf(`"%s" value is required`, keys[0])
+	}
+	return fmt.Sprintf(`"%s" values are required`, strings.Join(keys, `", "`))
+}
+
 // JSONProp implements JSON property name indexing for Required
 func (r Required) JSONProp(name string) interface{} {
 	idx, err := strconv.Atoi(name)
@@ -93,6 +121,10 @@ func NewProperties() Validator {
 
 // Validate implements the validator interface for Properties
 func (p Properties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	p.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (p Properties) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -103,7 +135,7 @@ func (p Properties) Validate(propPath string, data interface{}, errs *[]ValError
 		for key, val := range obj {
 			if p[key] != nil {
 				d, _ := jp.Descendant(key)
-				p[key].Validate(d.String(), val, errs)
+				p[key].validateIn(vd, d.String(), val, errs)
 			}
 		}
 	}
@@ -145,8 +177,15 @@ type patternSchema struct {
 	schema *Schema
 }
 
-// Validate implements the validator interface for PatternProperties
+// Validate implements the validator interface for PatternProperties.
+// Patterns are evaluated in the order the schema lists them, each against
+// the instance names it matches in sorted order, so errors come out in the
+// same order every time
 func (p PatternProperties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	p.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (p PatternProperties) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -154,11 +193,12 @@ func (p PatternProperties) Validate(propPath string, data interface{}, errs *[]V
 	}
 
 	if obj, ok := data.(map[string]interface{}); ok {
-		for key, val := range obj {
-			for _, ptn := range p {
-				if ptn.re.Match([]byte(key)) {
+		keys := sortedMapKeys(obj)
+		for _, ptn := range p {
+			for _, key := range keys {
+				if ptn.re.MatchString(key) {
 					d, _ := jp.Descendant(key)
-					ptn.schema.Validate(d.String(), val, errs)
+					ptn.schema.validateIn(vd, d.String(), obj[key], errs)
 				}
 			}
 		}
@@ -185,16 +225,20 @@ func (p PatternProperties) JSONChildren() (res map[string]JSONPather) {
 	return
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface for PatternProperties
+// UnmarshalJSON implements the json.Unmarshaler interface for PatternProperties.
+// Patterns are kept in the order they're written in the source document
 func (p *PatternProperties) UnmarshalJSON(data []byte) error {
+	keys, err := objectKeys(data)
+	if err != nil {
+		return err
+	}
 	var props map[string]*Schema
 	if err := json.Unmarshal(data, &props); err != nil {
 		return err
 	}
 
-	ptn := make(PatternProperties, len(props))
-	i := 0
-	for key, sch := range props {
+	ptn := make(PatternProperties, len(keys))
+	for i, key := range keys {
 		re, err := regexp.Compile(key)
 		if err != nil {
 			return fmt.Errorf("invalid pattern: %s: %s", key, err.Error())
@@ -202,22 +246,72 @@ func (p *PatternProperties) UnmarshalJSON(data []byte) error {
 		ptn[i] = patternSchema{
 			key:    key,
 			re:     re,
-			schema: sch,
+			schema: props[key],
 		}
-		i++
 	}
 
 	*p = ptn
 	return nil
 }
 
-// MarshalJSON implements json.Marshaler for PatternProperties
+// MarshalJSON implements json.Marshaler for PatternProperties,
+// writing patterns in their original order
 func (p PatternProperties) MarshalJSON() ([]byte, error) {
-	obj := map[string]interface{}{}
-	for _, prop := range p {
-		obj[prop.key] = prop.schema
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(prop.key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(prop.schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
 	}
-	return json.Marshal(obj)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// objectKeys returns the member names of a JSON object in document order,
+// dropping duplicates
+func objectKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected a json object")
+	}
+
+	keys := []string{}
+	seen := map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a json object key")
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
 }
 
 // AdditionalProperties determines how child instances validate for objects, and does not directly validate the immediate instance itself.
@@ -229,6 +323,9 @@ type AdditionalProperties struct {
 	Properties *Properties
 	patterns   *PatternProperties
 	Schema     *Schema
+	// forbidden is set when Schema is the false schema, so that additional
+	// properties are reported by name without validating them
+	forbidden bool
 }
 
 // NewAdditionalProperties allocates a new AdditionalProperties validator
@@ -238,6 +335,10 @@ func NewAdditionalProperties() Validator {
 
 // Validate implements the validator interface for AdditionalProperties
 func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	ap.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (ap AdditionalProperties) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -245,31 +346,71 @@ func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs
 	}
 
 	if obj, ok := data.(map[string]interface{}); ok {
-	KEYS:
+		if ap.forbidden {
+			ap.validateForbidden(jp, obj, errs)
+			return
+		}
 		for key, val := range obj {
-			if ap.Properties != nil {
-				for propKey := range *ap.Properties {
-					if propKey == key {
-						continue KEYS
-					}
-				}
-			}
-			if ap.patterns != nil {
-				for _, ptn := range *ap.patterns {
-					if ptn.re.Match([]byte(key)) {
-						continue KEYS
-					}
-				}
+			if ap.allowed(key) {
+				continue
 			}
-			// c := len(*errs)
 			d, _ := jp.Descendant(key)
-			ap.Schema.Validate(d.String(), val, errs)
-			// if len(*errs) > c {
-			// 	// fmt.Sprintf("object key %s AdditionalProperties error: %s", key, err.Error())
-			// 	return
-			// }
+			ap.Schema.validateIn(vd, d.String(), val, errs)
+		}
+	}
+}
+
+// allowed reports whether key is matched by properties or patternProperties
+func (ap AdditionalProperties) allowed(key string) bool {
+	if ap.Properties != nil {
+		if _, ok := (*ap.Properties)[key]; ok {
+			return true
+		}
+	}
+	if ap.patterns != nil {
+		for _, ptn := range *ap.patterns {
+			if ptn.re.MatchString(key) {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// validateForbidden adds an error naming each additional property of obj,
+// listing the properties that are allowed
+func (ap AdditionalProperties) validateForbidden(jp jsonpointer.Pointer, obj map[string]interface{}, errs *[]ValError) {
+	allowed := ""
+	for _, key := range sortedMapKeys(obj) {
+		if ap.allowed(key) {
+			continue
+		}
+		if allowed == "" {
+			allowed = ap.allowedNames()
+		}
+		d, _ := jp.Descendant(key)
+		AddError(errs, d.String(), obj[key], fmt.Sprintf("additional property %q is not allowed%s", key, allowed))
+	}
+}
+
+// allowedNames describes the properties that are allowed, for the errors
+// of additional ones
+func (ap AdditionalProperties) allowedNames() string {
+	names := []string{}
+	if ap.Properties != nil {
+		for _, key := range sortedPropertyKeys(*ap.Properties) {
+			names = append(names, strconv.Quote(key))
+		}
+	}
+	if ap.patterns != nil {
+		for _, ptn := range *ap.patterns {
+			names = append(names, "names matching "+strconv.Quote(ptn.key))
+		}
+	}
+	if len(names) == 0 {
+		return ", no properties are allowed"
+	}
+	return ", allowed: " + strings.Join(names, ", ")
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface for AdditionalProperties
@@ -279,7 +420,7 @@ func (ap *AdditionalProperties) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	// fmt.Println("unmarshal:", sch.Ref)
-	*ap = AdditionalProperties{Schema: sch}
+	*ap = AdditionalProperties{Schema: sch, forbidden: sch.schemaType == schemaTypeFalse}
 	return nil
 }
 
@@ -321,6 +462,10 @@ func NewDependencies() Validator {
 
 // Validate implements the validator interface for Dependencies
 func (d Dependencies) Validate(propPath string, data interface{}, errs *[]ValError) {
+	d.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (d Dependencies) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -331,7 +476,7 @@ func (d Dependencies) Validate(propPath string, data interface{}, errs *[]ValErr
 		for key, val := range d {
 			if obj[key] != nil {
 				d, _ := jp.Descendant(key)
-				val.Validate(d.String(), obj, errs)
+				val.validateIn(vd, d.String(), obj, errs)
 			}
 		}
 	}
@@ -362,9 +507,13 @@ type Dependency struct {
 
 // Validate implements the validator interface for Dependency
 func (d Dependency) Validate(propPath string, data interface{}, errs *[]ValError) {
+	d.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (d Dependency) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	if obj, ok := data.(map[string]interface{}); ok {
 		if d.schema != nil {
-			d.schema.Validate(propPath, data, errs)
+			d.schema.validateIn(vd, propPath, data, errs)
 		} else if len(d.props) > 0 {
 			for _, k := range d.props {
 				if obj[k] == nil {
@@ -410,8 +559,14 @@ func NewPropertyNames() Validator {
 	return &PropertyNames{}
 }
 
-// Validate implements the validator interface for PropertyNames
+// Validate implements the validator interface for PropertyNames. Errors
+// are at the path of the property with the invalid name, which they give
+// as their PropertyName
 func (p PropertyNames) Validate(propPath string, data interface{}, errs *[]ValError) {
+	p.validateIn(newValidation(propPath, data), propPath, data, errs)
+}
+
+func (p PropertyNames) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -420,10 +575,15 @@ func (p PropertyNames) Validate(propPath string, data interface{}, errs *[]ValEr
 
 	sch := Schema(p)
 	if obj, ok := data.(map[string]interface{}); ok {
-		for key := range obj {
-			// TODO - adjust error message & prop path
+		for _, key := range sortedMapKeys(obj) {
 			d, _ := jp.Descendant(key)
-			sch.Validate(d.String(), key, errs)
+			start := len(*errs)
+			sch.validateIn(vd, d.String(), key, errs)
+			for i := start; i < len(*errs); i++ {
+				e := &(*errs)[i]
+				e.PropertyName = key
+				e.Message = fmt.Sprintf("invalid property name: %s", e.Message)
+			}
 		}
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -89,3 +91,55 @@ func (p Pattern) MarshalJSON() ([]byte, error) {
 	rep := &re
 	return json.Marshal(rep.String())
 }
+
+// Transform is an ajv-style list of normalizations applied, in order, to a
+// string instance before the other keywords of its schema check it: "trim",
+// "trimStart", "trimEnd", "toLowerCase" and "toUpperCase". It isn't a
+// standard keyword, so it's opt-in, enabled with
+// RegisterValidator("transform", NewTransform) or RegisterAjvKeywords.
+// Transform never fails validation itself. ApplyTransforms gives the
+// normalized instance
+type Transform []string
+
+// NewTransform allocates a new Transform validator
+func NewTransform() Validator {
+	return &Transform{}
+}
+
+var stringTransforms = map[string]func(string) string{
+	"trim":        strings.TrimSpace,
+	"trimStart":   func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) },
+	"trimEnd":     func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) },
+	"toLowerCase": strings.ToLower,
+	"toUpperCase": strings.ToUpper,
+}
+
+// Validate implements the Validator interface for Transform
+func (t Transform) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// apply normalizes data if it's a string
+func (t Transform) apply(data interface{}) interface{} {
+	str, ok := data.(string)
+	if !ok {
+		return data
+	}
+	for _, name := range t {
+		str = stringTransforms[name](str)
+	}
+	return str
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Transform
+func (t *Transform) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if stringTransforms[name] == nil {
+			return fmt.Errorf("unknown transform %q", name)
+		}
+	}
+	*t = Transform(names)
+	return nil
+}
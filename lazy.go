@@ -0,0 +1,366 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// lazyMaxDepth bounds the nesting of documents ValidateRaw reads, as
+// encoding/json does
+const lazyMaxDepth = 10000
+
+// ValidateRaw performs schema validation against a slice of json byte
+// data, as ValidateBytes does, without decoding the parts of the document
+// no schema constrains. Those are checked to be valid JSON and kept as
+// json.RawMessage slices of data, nulls aside, so large documents of
+// which only a few fields are constrained validate without building their
+// maps. Members that schemas only look at as a whole, through keywords like
+// "enum", "const", "not" and "contains", are decoded in full, and so are
+// documents validated by schemas using $data. data mustn't be changed while
+// errors referring to it are in use
+func (rs *RootSchema) ValidateRaw(data []byte) ([]ValError, error) {
+	if rs.data {
+		return rs.ValidateBytes(data)
+	}
+	opts := rs.options().Decoding
+	sc := &lazyScanner{data: data, keys: opts.keyInterner(), opts: opts}
+	doc, err := sc.document(applicableSchemas(&rs.Schema, nil, map[*Schema]bool{}), false)
+	errs := []ValError{}
+	if err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	rs.Validate("/", doc, &errs)
+	return errs, nil
+}
+
+// lazyScanner reads JSON values, decoding only what schemas constrain
+type lazyScanner struct {
+	data []byte
+	pos  int
+	keys stringInterner
+	opts DecodeOptions
+}
+
+func (sc *lazyScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), sc.pos)
+}
+
+// document reads the whole of data as a single value, which schemas apply
+// to. full decodes it entirely
+func (sc *lazyScanner) document(schemas []*Schema, full bool) (interface{}, error) {
+	doc, err := sc.value(schemas, full, 0)
+	if err != nil {
+		return nil, err
+	}
+	sc.skipSpace()
+	if sc.pos < len(sc.data) {
+		return nil, sc.errorf("invalid character %q after top-level value", sc.data[sc.pos])
+	}
+	return doc, nil
+}
+
+// value reads the value at the current position, which schemas apply to.
+// full decodes it entirely
+func (sc *lazyScanner) value(schemas []*Schema, full bool, depth int) (interface{}, error) {
+	if depth > lazyMaxDepth {
+		return nil, sc.errorf("exceeded max depth")
+	}
+	sc.skipSpace()
+	if !full {
+		constrained := false
+		for _, s := range schemas {
+			constrained = constrained || len(s.Validators) > 0
+			full = full || !lazyShallow(s)
+		}
+		if !constrained {
+			start := sc.pos
+			if err := sc.skip(depth); err != nil {
+				return nil, err
+			}
+			if raw := sc.data[start:sc.pos]; string(raw) != "null" {
+				return json.RawMessage(raw), nil
+			}
+			return nil, nil
+		}
+	}
+
+	if sc.pos == len(sc.data) {
+		return nil, sc.errorf("unexpected end of JSON input")
+	}
+	switch sc.data[sc.pos] {
+	case '{':
+		sc.pos++
+		obj := map[string]interface{}{}
+		err := sc.members('}', func() error {
+			key, err := sc.str(sc.keys)
+			if err != nil {
+				return err
+			}
+			sc.skipSpace()
+			if sc.pos == len(sc.data) || sc.data[sc.pos] != ':' {
+				return sc.errorf("expected ':' after object key")
+			}
+			sc.pos++
+			var next []*Schema
+			if !full {
+				next = nextSchemas(schemas, key)
+			}
+			obj[key], err = sc.value(next, full, depth+1)
+			return err
+		})
+		return obj, err
+	case '[':
+		sc.pos++
+		arr := []interface{}{}
+		err := sc.members(']', func() error {
+			var next []*Schema
+			if !full {
+				next = nextSchemas(schemas, strconv.Itoa(len(arr)))
+			}
+			elem, err := sc.value(next, full, depth+1)
+			arr = append(arr, elem)
+			return err
+		})
+		return arr, err
+	case '"':
+		return sc.str(nil)
+	}
+	start := sc.pos
+	if err := sc.skip(depth); err != nil {
+		return nil, err
+	}
+	switch lit := string(sc.data[start:sc.pos]); lit {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		f, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return nil, fmt.Errorf("number %s isn't a float64", lit)
+		}
+		return sc.opts.decodedNumberText(lit, f), nil
+	}
+}
+
+// members reads the members of an object or array up to end, calling
+// member for each of them
+func (sc *lazyScanner) members(end byte, member func() error) error {
+	sc.skipSpace()
+	if sc.pos < len(sc.data) && sc.data[sc.pos] == end {
+		sc.pos++
+		return nil
+	}
+	for {
+		sc.skipSpace()
+		if err := member(); err != nil {
+			return err
+		}
+		sc.skipSpace()
+		if sc.pos == len(sc.data) {
+			return sc.errorf("unexpected end of JSON input")
+		}
+		switch sc.data[sc.pos] {
+		case ',':
+			sc.pos++
+		case end:
+			sc.pos++
+			return nil
+		default:
+			return sc.errorf("invalid character %q after %s element", sc.data[sc.pos], map[byte]string{'}': "object", ']': "array"}[end])
+		}
+	}
+}
+
+// skip checks the syntax of the value at the current position, moving past
+// it without decoding it
+func (sc *lazyScanner) skip(depth int) error {
+	if depth > lazyMaxDepth {
+		return sc.errorf("exceeded max depth")
+	}
+	sc.skipSpace()
+	if sc.pos == len(sc.data) {
+		return sc.errorf("unexpected end of JSON input")
+	}
+	switch c := sc.data[sc.pos]; {
+	case c == '{':
+		sc.pos++
+		return sc.members('}', func() error {
+			if _, err := sc.strEnd(); err != nil {
+				return err
+			}
+			sc.skipSpace()
+			if sc.pos == len(sc.data) || sc.data[sc.pos] != ':' {
+				return sc.errorf("expected ':' after object key")
+			}
+			sc.pos++
+			return sc.skip(depth + 1)
+		})
+	case c == '[':
+		sc.pos++
+		return sc.members(']', func() error {
+			return sc.skip(depth + 1)
+		})
+	case c == '"':
+		_, err := sc.strEnd()
+		return err
+	case c == '-' || (c >= '0' && c <= '9'):
+		return sc.number()
+	}
+	for _, lit := range []string{"null", "true", "false"} {
+		if len(sc.data)-sc.pos >= len(lit) && string(sc.data[sc.pos:sc.pos+len(lit)]) == lit {
+			sc.pos += len(lit)
+			return nil
+		}
+	}
+	return sc.errorf("invalid character %q looking for beginning of value", sc.data[sc.pos])
+}
+
+// number moves past a number, checking its syntax
+func (sc *lazyScanner) number() error {
+	start := sc.pos
+	digits := func() int {
+		n := 0
+		for sc.pos < len(sc.data) && sc.data[sc.pos] >= '0' && sc.data[sc.pos] <= '9' {
+			sc.pos++
+			n++
+		}
+		return n
+	}
+	if sc.data[sc.pos] == '-' {
+		sc.pos++
+	}
+	if sc.pos < len(sc.data) && sc.data[sc.pos] == '0' {
+		sc.pos++
+	} else if digits() == 0 {
+		return sc.errorf("invalid number %q", sc.data[start:sc.pos])
+	}
+	if sc.pos < len(sc.data) && sc.data[sc.pos] == '.' {
+		sc.pos++
+		if digits() == 0 {
+			return sc.errorf("invalid number %q", sc.data[start:sc.pos])
+		}
+	}
+	if sc.pos < len(sc.data) && (sc.data[sc.pos] == 'e' || sc.data[sc.pos] == 'E') {
+		sc.pos++
+		if sc.pos < len(sc.data) && (sc.data[sc.pos] == '+' || sc.data[sc.pos] == '-') {
+			sc.pos++
+		}
+		if digits() == 0 {
+			return sc.errorf("invalid number %q", sc.data[start:sc.pos])
+		}
+	}
+	return nil
+}
+
+// str reads a string, sharing it through in
+func (sc *lazyScanner) str(in stringInterner) (string, error) {
+	start := sc.pos
+	plain, err := sc.strEnd()
+	if err != nil {
+		return "", err
+	}
+	raw := sc.data[start:sc.pos]
+	if plain {
+		return in.bytes(raw[1 : len(raw)-1]), nil
+	}
+	// escapes and invalid UTF-8 are replaced as encoding/json replaces them
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", err
+	}
+	return in.string(s), nil
+}
+
+// strEnd moves past a string, checking its syntax, and reports whether it
+// holds neither escapes nor invalid UTF-8, so it reads as it's written
+func (sc *lazyScanner) strEnd() (bool, error) {
+	if sc.pos == len(sc.data) || sc.data[sc.pos] != '"' {
+		return false, sc.errorf("expected string")
+	}
+	sc.pos++
+	plain := true
+	for sc.pos < len(sc.data) {
+		c := sc.data[sc.pos]
+		switch {
+		case c == '"':
+			sc.pos++
+			return plain, nil
+		case c < ' ':
+			return false, sc.errorf("invalid control character in string")
+		case c == '\\':
+			plain = false
+			sc.pos++
+			if sc.pos == len(sc.data) {
+				break
+			}
+			switch sc.data[sc.pos] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				sc.pos++
+			case 'u':
+				if sc.pos+5 > len(sc.data) {
+					return false, sc.errorf("unexpected end of JSON input")
+				}
+				for _, h := range sc.data[sc.pos+1 : sc.pos+5] {
+					if !isHex(h) {
+						return false, sc.errorf("invalid \\u escape in string")
+					}
+				}
+				sc.pos += 5
+			default:
+				return false, sc.errorf("invalid escape in string")
+			}
+		case c < utf8.RuneSelf:
+			sc.pos++
+		default:
+			r, size := utf8.DecodeRune(sc.data[sc.pos:])
+			if r == utf8.RuneError && size == 1 {
+				plain = false
+			}
+			sc.pos += size
+		}
+	}
+	return false, sc.errorf("unexpected end of JSON input")
+}
+
+func (sc *lazyScanner) skipSpace() {
+	for sc.pos < len(sc.data) {
+		switch sc.data[sc.pos] {
+		case ' ', '\t', '\n', '\r':
+			sc.pos++
+		default:
+			return
+		}
+	}
+}
+
+// lazyShallow reports whether the keywords of s only look at the members
+// of values through the schemas nextSchemas gives for them, or at whether
+// members are present or null, so that members no schema applies to can
+// be left undecoded
+func lazyShallow(s *Schema) bool {
+	for _, v := range s.Validators {
+		switch t := v.(type) {
+		case *Type, *Nullable, *Format, *FormatLimit,
+			*MultipleOf, *Maximum, *ExclusiveMaximum, *Minimum, *ExclusiveMinimum,
+			*MaxLength, *MinLength, *Pattern,
+			*MaxItems, *MinItems, *Items, *AdditionalItems,
+			*MaxProperties, *minProperties, *Required, *Properties, *PatternProperties,
+			*AdditionalProperties, *PropertyNames, *PatternRequired, *Prohibited, *AllRequired,
+			*AllOf, *AnyOf, *OneOf, *Then, *Else:
+		case *Dependencies:
+			for _, dep := range *t {
+				if dep.schema != nil {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestValidateRaw(t *testing.T) {
+	schemas := []string{
+		`{"properties": {"id": {"type": "integer", "minimum": 1}, "tags": {"items": {"maxLength": 3}}}, "required": ["id", "meta"]}`,
+		`{"properties": {"kind": {"enum": ["a", "b"]}, "body": {"not": {"type": "array"}}}, "additionalProperties": {"type": "object"}}`,
+		`{"allOf": [{"$ref": "#/definitions/named"}], "definitions": {"named": {"properties": {"name": {"pattern": "^[a-z]+$"}}}}}`,
+		`{"items": [{"type": "string"}], "additionalItems": {"type": "number"}, "contains": {"const": 2}}`,
+		`{"dependencies": {"a": ["b"]}, "propertyNames": {"maxLength": 2}}`,
+	}
+	docs := []string{
+		`{"id": 3, "meta": {"deep": [1, 2, {"x": null}]}, "tags": ["ab", "abcd"]}`,
+		`{"id": 0.5, "meta": null, "kind": "c", "body": [1], "other": "str"}`,
+		`{"name": "ABC", "extra": {"a": "é\n"}, "body": {}}`,
+		`["x", 1, 2, "y"]`,
+		`{"a": 1, "ccc": [true, false]}`,
+		`"just a string"`,
+	}
+	for i, sch := range schemas {
+		rs := Must(sch)
+		for j, doc := range docs {
+			want, err := rs.ValidateBytes([]byte(doc))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			got, err := rs.ValidateRaw([]byte(doc))
+			if err != nil {
+				t.Errorf("schema %d doc %d: %s", i, j, err.Error())
+				continue
+			}
+			if fmt.Sprint(errorKeys(got)) != fmt.Sprint(errorKeys(want)) {
+				t.Errorf("schema %d doc %d: expected errors %v, got %v", i, j, want, got)
+			}
+		}
+	}
+}
+
+func errorKeys(errs []ValError) map[string]bool {
+	keys := map[string]bool{}
+	for _, e := range errs {
+		keys[e.PropertyPath+" "+e.Message] = true
+	}
+	return keys
+}
+
+func TestValidateRawSkipsUnconstrained(t *testing.T) {
+	var seen map[string]interface{}
+	rs := Must(`{"required": ["a"], "properties": {"id": {"type": "integer"}, "all": {"const": {"x": [1]}}}}`)
+	rs.SetOptions(Options{Hooks: []KeywordHooks{{After: func(ev *KeywordEvaluation) {
+		if ev.Keyword == "required" {
+			seen = ev.Data.(map[string]interface{})
+		}
+	}}}})
+	errs, err := rs.ValidateRaw([]byte(`{"id": 1, "big": {"x": [1, 2, 3]}, "nothing": null, "all": {"x": [1]}}`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+	if raw, ok := seen["big"].(json.RawMessage); !ok || string(raw) != `{"x": [1, 2, 3]}` {
+		t.Errorf("expected the unconstrained member as raw JSON, got %#v", seen["big"])
+	}
+	if v, ok := seen["nothing"]; !ok || v != nil {
+		t.Errorf("expected a nil member for null, got %#v", v)
+	}
+	if _, ok := seen["all"].(map[string]interface{}); !ok {
+		t.Errorf("expected the const member to be decoded, got %#v", seen["all"])
+	}
+}
+
+func TestValidateRawSyntax(t *testing.T) {
+	rs := Must(`{"properties": {"id": {"type": "integer"}}}`)
+	for _, doc := range []string{
+		`{"id": 1, "skip": [1, 2}`,
+		`{"id": 1, "skip": "\x"}`,
+		`{"id": 01}`,
+		`{"skip": tru}`,
+		`{"id": 1} {}`,
+		`{"id": 1`,
+		``,
+	} {
+		if _, err := rs.ValidateRaw([]byte(doc)); err == nil {
+			t.Errorf("expected an error parsing %q", doc)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Links is JSON Hyper-Schema's "links" keyword, the Link Description
+// Objects describing the links of instances. Links are annotations, so
+// they never fail validation, but malformed links are rejected when the
+// schema is decoded. ResolveLinks gives the links of an instance
+type Links []*Link
+
+// Link is a Link Description Object of JSON Hyper-Schema
+type Link struct {
+	// Rel is the relation type of the link, such as "self" or "item"
+	Rel string `json:"rel"`
+	// Href is a URI template for the target of the link, filled in from
+	// the instance
+	Href string `json:"href"`
+	// Anchor is a URI template for the context of the link, which is the
+	// instance by default
+	Anchor string `json:"anchor,omitempty"`
+	// AnchorPointer is a JSON pointer, or a Relative JSON Pointer from the
+	// attachment point, to the part of the instance that's the context of
+	// the link
+	AnchorPointer string `json:"anchorPointer,omitempty"`
+	// TemplatePointers maps the variables of Href to JSON pointers within
+	// the instance, or Relative JSON Pointers from the attachment point.
+	// Variables without one are properties of the instance the link is
+	// attached to
+	TemplatePointers map[string]string `json:"templatePointers,omitempty"`
+	// TemplateRequired lists the variables without which the link can't be
+	// resolved
+	TemplateRequired []string `json:"templateRequired,omitempty"`
+	Title            string   `json:"title,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	Comment          string   `json:"$comment,omitempty"`
+	// HrefSchema describes the variables of Href a client may supply
+	HrefSchema   *Schema `json:"hrefSchema,omitempty"`
+	TargetSchema *Schema `json:"targetSchema,omitempty"`
+	// TargetMediaType is the expected media type of the target
+	TargetMediaType string `json:"targetMediaType,omitempty"`
+	// TargetHints are hints about the target, such as allowed methods
+	TargetHints         map[string]interface{} `json:"targetHints,omitempty"`
+	HeaderSchema        *Schema                `json:"headerSchema,omitempty"`
+	SubmissionSchema    *Schema                `json:"submissionSchema,omitempty"`
+	SubmissionMediaType string                 `json:"submissionMediaType,omitempty"`
+}
+
+// NewLinks creates a new Links validator
+func NewLinks() Validator {
+	return &Links{}
+}
+
+// Validate implements the Validator interface for Links, which never fails
+func (l Links) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// JSONProp implements JSON property name indexing for Links
+func (l Links) JSONProp(name string) interface{} {
+	idx, err := strconv.Atoi(name)
+	if err != nil || idx < 0 || idx >= len(l) {
+		return nil
+	}
+	return l[idx]
+}
+
+// JSONChildren implements the JSONContainer interface for Links
+func (l Links) JSONChildren() (res map[string]JSONPather) {
+	res = map[string]JSONPather{}
+	for i, link := range l {
+		res[strconv.Itoa(i)] = link
+	}
+	return
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Links
+func (l *Links) UnmarshalJSON(data []byte) error {
+	var links []*Link
+	if err := json.Unmarshal(data, &links); err != nil {
+		return err
+	}
+	for i, link := range links {
+		if link == nil {
+			return fmt.Errorf("link %d must be an object", i)
+		}
+		if err := link.check(); err != nil {
+			return fmt.Errorf("link %d: %s", i, err.Error())
+		}
+	}
+	*l = links
+	return nil
+}
+
+// check reports the problems of a decoded link
+func (l *Link) check() error {
+	if l.Rel == "" {
+		return fmt.Errorf(`"rel" is required`)
+	}
+	if l.Href == "" {
+		return fmt.Errorf(`"href" is required`)
+	}
+	if _, err := ParseURITemplate(l.Href); err != nil {
+		return fmt.Errorf("invalid href %q: %s", l.Href, err.Error())
+	}
+	if _, err := ParseURITemplate(l.Anchor); err != nil {
+		return fmt.Errorf("invalid anchor %q: %s", l.Anchor, err.Error())
+	}
+	if err := checkLinkPointer(l.AnchorPointer); err != nil {
+		return fmt.Errorf("invalid anchorPointer %q: %s", l.AnchorPointer, err.Error())
+	}
+	if rel, err := ParseRelativePointer(l.AnchorPointer); err == nil && rel.Key {
+		return fmt.Errorf("invalid anchorPointer %q: must locate a value", l.AnchorPointer)
+	}
+	for name, ptr := range l.TemplatePointers {
+		if err := checkLinkPointer(ptr); err != nil {
+			return fmt.Errorf("invalid template pointer %q for %s: %s", ptr, name, err.Error())
+		}
+	}
+	return nil
+}
+
+// checkLinkPointer reports the problems of ptr, a JSON pointer or a
+// Relative JSON Pointer
+func checkLinkPointer(ptr string) error {
+	if ptr == "" || ptr[0] == '/' {
+		_, err := ParsePointer(ptr)
+		return err
+	}
+	_, err := ParseRelativePointer(ptr)
+	return err
+}
+
+// JSONProp implements JSON property name indexing for Link
+func (l *Link) JSONProp(name string) interface{} {
+	switch name {
+	case "rel":
+		return l.Rel
+	case "href":
+		return l.Href
+	case "anchor":
+		return l.Anchor
+	case "anchorPointer":
+		return l.AnchorPointer
+	case "title":
+		return l.Title
+	case "description":
+		return l.Description
+	case "hrefSchema":
+		return l.HrefSchema
+	case "targetSchema":
+		return l.TargetSchema
+	case "headerSchema":
+		return l.HeaderSchema
+	case "submissionSchema":
+		return l.SubmissionSchema
+	}
+	return nil
+}
+
+// JSONChildren implements the JSONContainer interface for Link
+func (l *Link) JSONChildren() (res map[string]JSONPather) {
+	res = map[string]JSONPather{}
+	for key, sch := range l.schemas() {
+		res[key] = sch
+	}
+	return
+}
+
+// schemas gives the schemas of the link by keyword
+func (l *Link) schemas() map[string]*Schema {
+	res := map[string]*Schema{}
+	for key, sch := range map[string]*Schema{
+		"hrefSchema":       l.HrefSchema,
+		"targetSchema":     l.TargetSchema,
+		"headerSchema":     l.HeaderSchema,
+		"submissionSchema": l.SubmissionSchema,
+	} {
+		if sch != nil {
+			res[key] = sch
+		}
+	}
+	return res
+}
+
+// ResolvedLink is a link of an instance with its templates filled in
+type ResolvedLink struct {
+	*Link
+	// Attachment is the instance JSON pointer of the value whose schema
+	// declares the link
+	Attachment string
+	// Context is the instance JSON pointer of the link's context, the
+	// attachment point unless the link has an anchorPointer
+	Context string
+	// Target is the URI of the link's target, resolved against the base URI
+	Target string
+	// ContextURI is the URI of the link's context, when it has an anchor
+	ContextURI string
+}
+
+// ResolveLinks gives the links of instance declared by the schemas that
+// apply to it and the values within it, in instance order. Schemas apply
+// as they do in validation: references are followed, "anyOf" and "oneOf"
+// branches contribute links only when the value is valid against them,
+// and "if" contributes the links of "then" or "else" as the value decides.
+// Templates are filled in from the instance: a variable is read from the
+// JSON pointer TemplatePointers gives for it, or else from the property of
+// the attachment point of the same name. Links missing a variable of
+// TemplateRequired are left out. Targets are resolved against base, when
+// given, as changed by the "base" keywords of the schemas applying to the
+// attachment point and the values above it. "base" is a template filled
+// in from the value its schema applies to
+func (rs *RootSchema) ResolveLinks(instance interface{}, base string) ([]ResolvedLink, error) {
+	r := &linkResolver{root: instance, seen: map[*Schema]map[string]bool{}}
+	r.collect(&rs.Schema, "", instance, base)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	links := []ResolvedLink{}
+	for _, att := range r.attached {
+		resolved, ok, err := r.resolve(att)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			links = append(links, resolved)
+		}
+	}
+	return links, nil
+}
+
+// attachedLink is a link declared for data, the instance value at ptr,
+// whose URIs are resolved against base
+type attachedLink struct {
+	link *Link
+	ptr  string
+	data interface{}
+	base string
+}
+
+// linkResolver gathers the links of the instance root
+type linkResolver struct {
+	root     interface{}
+	attached []attachedLink
+	// seen holds the instance locations each schema has been applied to
+	seen map[*Schema]map[string]bool
+	err  error
+}
+
+// collect gathers the links of the schemas applying to data, the instance
+// value at ptr, and the values within it. base is the base URI of data
+func (r *linkResolver) collect(s *Schema, ptr string, data interface{}, base string) {
+	s, ok := resolveSchema(s)
+	if !ok || s.schemaType != schemaTypeObject || r.seen[s][ptr] || r.err != nil {
+		return
+	}
+	if r.seen[s] == nil {
+		r.seen[s] = map[string]bool{}
+	}
+	r.seen[s][ptr] = true
+
+	base, err := r.baseURI(s, data, base)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	if links, ok := s.Validators["links"].(*Links); ok {
+		for _, link := range *links {
+			r.attached = append(r.attached, attachedLink{link: link, ptr: ptr, data: data, base: base})
+		}
+	}
+
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		for _, branch := range *allOf {
+			r.collect(branch, ptr, data, base)
+		}
+	}
+	if anyOf, ok := s.Validators["anyOf"].(*AnyOf); ok {
+		for _, branch := range *anyOf {
+			r.collectValid(branch, ptr, data, base)
+		}
+	}
+	if oneOf, ok := s.Validators["oneOf"].(*OneOf); ok {
+		for _, branch := range *oneOf {
+			r.collectValid(branch, ptr, data, base)
+		}
+	}
+	if cond, ok := s.Validators["if"].(*If); ok {
+		errs := []ValError{}
+		cond.Schema.Validate("/", data, &errs)
+		if len(errs) == 0 && cond.Then != nil {
+			r.collect((*Schema)(cond.Then), ptr, data, base)
+		} else if len(errs) > 0 && cond.Else != nil {
+			r.collect((*Schema)(cond.Else), ptr, data, base)
+		}
+	}
+
+	switch d := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, child := range childSchemas(s, key) {
+				r.collect(child, ptr+"/"+EscapePointerToken(key), d[key], base)
+			}
+		}
+	case []interface{}:
+		for i, elem := range d {
+			for _, child := range childSchemas(s, strconv.Itoa(i)) {
+				r.collect(child, ptr+"/"+strconv.Itoa(i), elem, base)
+			}
+		}
+	}
+}
+
+// collectValid gathers the links of s when data is valid against it
+func (r *linkResolver) collectValid(s *Schema, ptr string, data interface{}, base string) {
+	errs := []ValError{}
+	s.Validate("/", data, &errs)
+	if len(errs) == 0 {
+		r.collect(s, ptr, data, base)
+	}
+}
+
+// resolve fills in the templates of an attached link, reporting false if a
+// required variable is missing
+func (r *linkResolver) resolve(att attachedLink) (ResolvedLink, bool, error) {
+	// templates were checked when the link was decoded
+	href, _ := ParseURITemplate(att.link.Href)
+	anchor, _ := ParseURITemplate(att.link.Anchor)
+	vars := map[string]interface{}{}
+	for _, name := range append(href.Variables(), anchor.Variables()...) {
+		if val, ok := r.variable(att.link, att.ptr, att.data, name); ok {
+			vars[name] = val
+		}
+	}
+	for _, name := range att.link.TemplateRequired {
+		if _, ok := vars[name]; !ok {
+			return ResolvedLink{}, false, nil
+		}
+	}
+
+	resolved := ResolvedLink{Link: att.link, Attachment: att.ptr, Context: att.ptr}
+	if ptr := att.link.AnchorPointer; ptr != "" && ptr[0] == '/' {
+		resolved.Context = ptr
+	} else if ptr != "" {
+		rel, _ := ParseRelativePointer(ptr)
+		loc, err := rel.Location(att.ptr)
+		if err != nil {
+			return ResolvedLink{}, false, err
+		}
+		resolved.Context = append(loc, rel.Pointer...).String()
+	}
+	var err error
+	if resolved.Target, err = resolveLinkURI(att.base, href.Expand(vars)); err != nil {
+		return ResolvedLink{}, false, err
+	}
+	if att.link.Anchor != "" {
+		if resolved.ContextURI, err = resolveLinkURI(att.base, anchor.Expand(vars)); err != nil {
+			return ResolvedLink{}, false, err
+		}
+	}
+	return resolved, true, nil
+}
+
+// variable gives the value of the template variable name of link, which is
+// attached to data, the instance value at ptr
+func (r *linkResolver) variable(link *Link, ptr string, data interface{}, name string) (interface{}, bool) {
+	if tp, ok := link.TemplatePointers[name]; ok {
+		var val interface{}
+		var err error
+		if tp == "" || tp[0] == '/' {
+			p, _ := ParsePointer(tp)
+			val, err = p.Eval(r.root)
+		} else {
+			rel, _ := ParseRelativePointer(tp)
+			val, err = rel.Eval(r.root, ptr)
+		}
+		return val, err == nil && val != nil
+	}
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	val, ok := obj[name]
+	return val, ok && val != nil
+}
+
+// baseURI gives the base URI of data within the schema s, which is base
+// with the "base" keyword of s resolved against it
+func (r *linkResolver) baseURI(s *Schema, data interface{}, base string) (string, error) {
+	raw, ok := s.extraKeywords["base"]
+	if !ok {
+		return base, nil
+	}
+	var tmpl string
+	if err := json.Unmarshal(raw, &tmpl); err != nil {
+		return "", fmt.Errorf(`"base" must be a string`)
+	}
+	t, err := ParseURITemplate(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid base %q: %s", tmpl, err.Error())
+	}
+	vars := map[string]interface{}{}
+	for _, name := range t.Variables() {
+		if val, ok := r.variable(&Link{}, "", data, name); ok {
+			vars[name] = val
+		}
+	}
+	return resolveLinkURI(base, t.Expand(vars))
+}
+
+// resolveLinkURI resolves ref against base, leaving it as it is without a
+// base
+func resolveLinkURI(base, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid link URI %q: %s", ref, err.Error())
+	}
+	if base == "" {
+		return u.String(), nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URI %q: %s", base, err.Error())
+	}
+	return b.ResolveReference(u).String(), nil
+}
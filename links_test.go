@@ -0,0 +1,170 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var linksSchema = `{
+	"$id": "https://api.example.com/schemas/thing",
+	"base": "https://api.example.com/",
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer"},
+		"owner": {"$ref": "#/definitions/user"},
+		"tags": {"type": "array", "items": {"$ref": "#/definitions/tag"}}
+	},
+	"links": [
+		{"rel": "self", "href": "things/{id}", "templateRequired": ["id"]},
+		{
+			"rel": "owner",
+			"href": "users/{name}",
+			"templatePointers": {"name": "/owner/name"},
+			"targetSchema": {"$ref": "#/definitions/user"}
+		},
+		{
+			"rel": "search",
+			"href": "things{q}",
+			"hrefSchema": {"type": "object", "properties": {"q": {"type": "string"}}},
+			"submissionSchema": {"type": "object"}
+		}
+	],
+	"anyOf": [
+		{"required": ["archived"], "links": [{"rel": "restore", "href": "things/{id}/restore"}]},
+		{"required": ["id"], "links": [{"rel": "archive", "href": "things/{id}/archive"}]}
+	],
+	"definitions": {
+		"user": {"type": "object", "properties": {"name": {"type": "string"}}},
+		"tag": {
+			"type": "object",
+			"links": [{
+				"rel": "tag",
+				"href": "/tags/{label}{?thing,i}",
+				"anchorPointer": "1",
+				"templatePointers": {"thing": "2/id", "i": "0#"}
+			}]
+		}
+	}
+}`
+
+func TestLinksDecode(t *testing.T) {
+	rs := &RootSchema{}
+	if err := json.Unmarshal([]byte(linksSchema), rs); err != nil {
+		t.Fatal(err)
+	}
+	links, ok := rs.Validators["links"].(*Links)
+	if !ok || len(*links) != 3 {
+		t.Fatalf("expected 3 links, got %v", rs.Validators["links"])
+	}
+	if (*links)[1].TargetSchema == nil || (*links)[1].TargetSchema.ref == nil {
+		t.Error("expected the reference of the target schema to be resolved")
+	}
+
+	data, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"templatePointers":{"name":"/owner/name"}`) {
+		t.Errorf("expected links to be encoded, got %s", data)
+	}
+
+	ptrs := []string{}
+	rs.Walk(func(ptr string, _ *Schema) error {
+		if strings.HasPrefix(ptr, "/links") {
+			ptrs = append(ptrs, ptr)
+		}
+		return nil
+	})
+	expect := "/links/1/targetSchema /links/2/hrefSchema /links/2/hrefSchema/properties/q /links/2/submissionSchema"
+	if got := strings.Join(ptrs, " "); got != expect {
+		t.Errorf("expected Walk to visit %s, got %s", expect, got)
+	}
+
+	errs := []ValError{}
+	rs.Validate("/", map[string]interface{}{"id": 1.0}, &errs)
+	if len(errs) != 0 {
+		t.Errorf("expected links not to affect validation, got %v", errs)
+	}
+
+	invalid := []struct {
+		links, err string
+	}{
+		{`[{"href": "a"}]`, `link 0: "rel" is required`},
+		{`[{"rel": "self"}]`, `link 0: "href" is required`},
+		{`[{"rel": "self", "href": "a/{id"}]`, `link 0: invalid href "a/{id": unterminated expression`},
+		{`[{"rel": "self", "href": "a", "templatePointers": {"id": "id"}}]`, `link 0: invalid template pointer "id" for id`},
+	}
+	for _, c := range invalid {
+		err := json.Unmarshal([]byte(`{"links": `+c.links+`}`), &RootSchema{})
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected error %q, got %v", c.links, c.err, err)
+		}
+	}
+}
+
+func TestResolveLinks(t *testing.T) {
+	rs := Must(linksSchema)
+	var instance interface{}
+	json.Unmarshal([]byte(`{
+		"id": 7,
+		"owner": {"name": "ada lovelace"},
+		"tags": [{"label": "a/b"}, {}]
+	}`), &instance)
+
+	links, err := rs.ResolveLinks(instance, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, l := range links {
+		got = append(got, l.Rel+" "+l.Attachment+" "+l.Context+" "+l.Target)
+	}
+	expect := []string{
+		"self   https://api.example.com/things/7",
+		"owner   https://api.example.com/users/ada%20lovelace",
+		"search   https://api.example.com/things",
+		"archive   https://api.example.com/things/7/archive",
+		"tag /tags/0 /tags https://api.example.com/tags/a%2Fb?thing=7&i=0",
+		"tag /tags/1 /tags https://api.example.com/tags/?thing=7&i=1",
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("expected links %q, got %q", expect, got)
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("link %d: expected %q, got %q", i, expect[i], got[i])
+		}
+	}
+
+	// without an id the self link can't be resolved
+	links, err = rs.ResolveLinks(map[string]interface{}{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range links {
+		if l.Rel == "self" || l.Rel == "archive" {
+			t.Errorf("expected no %s link without an id", l.Rel)
+		}
+	}
+}
+
+func TestResolveLinksBase(t *testing.T) {
+	rs := Must(`{
+		"base": "v1/",
+		"links": [{"rel": "self", "href": "items/{id}", "anchor": "#{id}"}]
+	}`)
+	links, err := rs.ResolveLinks(map[string]interface{}{"id": "x y"}, "https://example.com/api/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link, got %v", links)
+	}
+	if links[0].Target != "https://example.com/api/v1/items/x%20y" {
+		t.Errorf("unexpected target %s", links[0].Target)
+	}
+	if links[0].ContextURI != "https://example.com/api/v1/#x%20y" {
+		t.Errorf("unexpected context URI %s", links[0].ContextURI)
+	}
+}
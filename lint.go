@@ -0,0 +1,327 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+)
+
+// Lint rule codes reported in LintIssue.Rule
+const (
+	// LintContradictoryBounds flags a lower bound greater than its upper bound,
+	// like minLength > maxLength
+	LintContradictoryBounds = "contradictory-bounds"
+	// LintUnreachableBranch flags anyOf and oneOf branches no instance can match
+	LintUnreachableBranch = "unreachable-branch"
+	// LintUnmatchablePattern flags regular expressions that match no string
+	LintUnmatchablePattern = "unmatchable-pattern"
+	// LintUndescribedRequired flags required properties missing from properties
+	LintUndescribedRequired = "undescribed-required"
+	// LintInapplicableKeyword flags keywords that never apply to the types a
+	// schema allows, like maxLength alongside "type": "integer"
+	LintInapplicableKeyword = "inapplicable-keyword"
+	// LintIgnoredKeyword flags keywords with no effect: then or else without
+	// if, additionalItems without an items array, or siblings of $ref
+	LintIgnoredKeyword = "ignored-keyword"
+	// LintUnreachableEnumValue flags enum values that fail sibling keywords
+	LintUnreachableEnumValue = "unreachable-enum-value"
+	// LintDuplicateValue flags repeated enum values and required names
+	LintDuplicateValue = "duplicate-value"
+	// LintInvalidDefault flags default values and examples that don't
+	// validate against their schema
+	LintInvalidDefault = "invalid-default"
+)
+
+// LintIssue is a likely authoring mistake found in a schema
+type LintIssue struct {
+	// Rule is the code of the rule that found the issue
+	Rule string `json:"rule"`
+	// Pointer is the JSON pointer to the schema or keyword at fault
+	Pointer string `json:"pointer"`
+	// Message describes the issue
+	Message string `json:"message"`
+}
+
+// Error implements the error interface for LintIssue
+func (i LintIssue) Error() string {
+	return fmt.Sprintf("%s: %s [%s]", i.Pointer, i.Message, i.Rule)
+}
+
+// boundPairs lists keywords that bound the same quantity from below and above
+var boundPairs = []struct {
+	min, max  string
+	exclusive bool
+}{
+	{"minLength", "maxLength", false},
+	{"minItems", "maxItems", false},
+	{"minProperties", "maxProperties", false},
+	{"minimum", "maximum", false},
+	{"exclusiveMinimum", "maximum", true},
+	{"minimum", "exclusiveMaximum", true},
+	{"exclusiveMinimum", "exclusiveMaximum", true},
+}
+
+// keywordTypes lists the instance type each type-specific keyword applies to
+var keywordTypes = map[string]string{
+	"multipleOf":           "number",
+	"maximum":              "number",
+	"exclusiveMaximum":     "number",
+	"minimum":              "number",
+	"exclusiveMinimum":     "number",
+	"maxLength":            "string",
+	"minLength":            "string",
+	"pattern":              "string",
+	"items":                "array",
+	"additionalItems":      "array",
+	"maxItems":             "array",
+	"minItems":             "array",
+	"uniqueItems":          "array",
+	"contains":             "array",
+	"maxProperties":        "object",
+	"minProperties":        "object",
+	"required":             "object",
+	"properties":           "object",
+	"patternProperties":    "object",
+	"additionalProperties": "object",
+	"dependencies":         "object",
+	"propertyNames":        "object",
+}
+
+// Lint checks the schema and its subschemas for contradictory constraints,
+// unreachable branches and similar authoring mistakes. Issues are ordered by
+// pointer. A schema with no issues may still be wrong, and some issues, like
+// undescribed required properties, can be deliberate
+func (s *Schema) Lint() []LintIssue {
+	issues := []LintIssue{}
+	s.Walk(func(ptr string, sch *Schema) error {
+		if sch.schemaType == schemaTypeObject {
+			issues = append(issues, lintSchema(ptr, sch)...)
+		}
+		return nil
+	})
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Pointer < issues[j].Pointer })
+	return issues
+}
+
+func lintSchema(ptr string, s *Schema) []LintIssue {
+	issues := []LintIssue{}
+	add := func(rule, key, format string, args ...interface{}) {
+		p := ptr
+		if key != "" {
+			p += "/" + key
+		}
+		issues = append(issues, LintIssue{Rule: rule, Pointer: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Ref != "" {
+		for _, key := range sortedValidatorKeys(s.Validators) {
+			add(LintIgnoredKeyword, key, "%s is ignored alongside $ref", key)
+		}
+		return issues
+	}
+
+	vals := shallowKeywords(s)
+	for _, pair := range boundPairs {
+		min, hasMin := vals[pair.min].(float64)
+		max, hasMax := vals[pair.max].(float64)
+		if hasMin && hasMax && (min > max || (pair.exclusive && min == max)) {
+			add(LintContradictoryBounds, pair.min, "%s %v leaves no room below %s %v", pair.min, min, pair.max, max)
+		}
+	}
+
+	types := schemaTypes(s)
+	if _, ok := s.Validators["type"]; ok {
+		for _, key := range sortedValidatorKeys(s.Validators) {
+			if t, ok := keywordTypes[key]; ok && !containsType(types, t) {
+				add(LintInapplicableKeyword, key, "%s only applies to %s instances, which the schema doesn't allow", key, t)
+			}
+		}
+	}
+
+	if _, ok := s.Validators["if"]; !ok {
+		for _, key := range []string{"then", "else"} {
+			if _, ok := s.Validators[key]; ok {
+				add(LintIgnoredKeyword, key, "%s is ignored without if", key)
+			}
+		}
+	}
+	if _, ok := s.Validators["additionalItems"]; ok {
+		if items, ok := s.Validators["items"].(*Items); !ok || items.single {
+			add(LintIgnoredKeyword, "additionalItems", "additionalItems is ignored unless items is an array")
+		}
+	}
+
+	if p, ok := s.Validators["pattern"].(*Pattern); ok {
+		if str := (*regexp.Regexp)(p).String(); unmatchable(str) {
+			add(LintUnmatchablePattern, "pattern", "pattern %q matches no string", str)
+		}
+	}
+	if pp, ok := s.Validators["patternProperties"].(*PatternProperties); ok {
+		for _, ptn := range *pp {
+			if unmatchable(ptn.key) {
+				add(LintUnmatchablePattern, "patternProperties", "pattern %q matches no property name", ptn.key)
+			}
+		}
+	}
+
+	if req, ok := s.Validators["required"].(*Required); ok {
+		seen := map[string]bool{}
+		for _, name := range *req {
+			if seen[name] {
+				add(LintDuplicateValue, "required", "%q is required more than once", name)
+			}
+			seen[name] = true
+		}
+		if _, ok := s.Validators["properties"]; ok {
+			for _, name := range uniqueStrings(*req) {
+				if propertySchema(s, name) == trueSchema {
+					add(LintUndescribedRequired, "required", "required property %q isn't described in properties", name)
+				} else if isFalseSchema(propertySchema(s, name)) {
+					add(LintUndescribedRequired, "required", "required property %q is never allowed", name)
+				}
+			}
+		}
+	}
+
+	if enum, ok := s.Validators["enum"].(*Enum); ok {
+		rest := without(s, "enum")
+		seen := []Const{}
+		for i, con := range *enum {
+			for _, prev := range seen {
+				if jsonEqual(prev, con) {
+					add(LintDuplicateValue, "enum", "enum value %s appears more than once", string(con))
+				}
+			}
+			seen = append(seen, con)
+			var v interface{}
+			if err := json.Unmarshal(con, &v); err == nil && !isValid(rest, v) {
+				add(LintUnreachableEnumValue, "enum/"+strconv.Itoa(i), "enum value %s fails the schema's other keywords", string(con))
+			}
+		}
+	}
+
+	c := &subsetChecker{assumed: map[[2]*Schema]bool{}}
+	siblings := without(s, "allOf", "anyOf", "oneOf", "not", "if", "then", "else")
+	for _, key := range []string{"anyOf", "oneOf"} {
+		var branches []*Schema
+		switch v := s.Validators[key].(type) {
+		case *AnyOf:
+			branches = *v
+		case *OneOf:
+			branches = *v
+		}
+		for i, branch := range branches {
+			if c.disjoint(branch, trueSchema) || c.disjoint(branch, siblings) {
+				add(LintUnreachableBranch, key+"/"+strconv.Itoa(i), "no instance valid against the schema can match this branch")
+			}
+		}
+	}
+
+	if s.Default != nil && !isValid(s, s.Default) {
+		add(LintInvalidDefault, "default", "default value %s is invalid against the schema", InvalidValueString(s.Default))
+	}
+	for i, ex := range s.Examples {
+		if !isValid(s, ex) {
+			add(LintInvalidDefault, "examples/"+strconv.Itoa(i), "example %s is invalid against the schema", InvalidValueString(ex))
+		}
+	}
+	return issues
+}
+
+// unmatchable reports whether a regular expression can match no string at
+// all. Expressions that fail to parse are reported elsewhere
+func unmatchable(pattern string) bool {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return matchesNothing(re.Simplify())
+}
+
+func matchesNothing(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return true
+	case syntax.OpCharClass:
+		return len(re.Rune) == 0
+	case syntax.OpCapture, syntax.OpPlus:
+		return matchesNothing(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min > 0 && matchesNothing(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !matchesNothing(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpConcat:
+		started, ended := false, false
+		for _, sub := range re.Sub {
+			if matchesNothing(sub) {
+				return true
+			}
+			// nothing can precede the start of the text or follow its end
+			if (ended && consumes(sub)) || (started && sub.Op == syntax.OpBeginText) {
+				return true
+			}
+			if sub.Op == syntax.OpEndText {
+				ended = true
+			}
+			if consumes(sub) {
+				started = true
+			}
+		}
+	}
+	return false
+}
+
+// consumes reports whether every match of re is at least one character long
+func consumes(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune) > 0
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpCapture, syntax.OpPlus:
+		return consumes(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min > 0 && consumes(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if consumes(sub) {
+				return true
+			}
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !consumes(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// containsType reports whether types allows instances of type t
+func containsType(types []string, t string) bool {
+	for _, name := range types {
+		if name == t || (t == "number" && name == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedValidatorKeys(vals map[string]Validator) []string {
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		schema string
+		expect []string
+	}{
+		{`{ "type": "string", "minLength": 1, "maxLength": 10 }`, []string{}},
+		{`{ "minLength": 5, "maxLength": 2 }`,
+			[]string{"/minLength: minLength 5 leaves no room below maxLength 2 [contradictory-bounds]"}},
+		{`{ "exclusiveMinimum": 3, "maximum": 3 }`,
+			[]string{"/exclusiveMinimum: exclusiveMinimum 3 leaves no room below maximum 3 [contradictory-bounds]"}},
+		{`{ "type": "integer", "maxLength": 2 }`,
+			[]string{"/maxLength: maxLength only applies to string instances, which the schema doesn't allow [inapplicable-keyword]"}},
+		{`{ "then": { "required": ["a"] } }`, []string{"/then: then is ignored without if [ignored-keyword]"}},
+		{`{ "items": {}, "additionalItems": false }`,
+			[]string{"/additionalItems: additionalItems is ignored unless items is an array [ignored-keyword]"}},
+		{`{ "properties": { "a": { "$ref": "#", "type": "string" } } }`,
+			[]string{"/properties/a/type: type is ignored alongside $ref [ignored-keyword]"}},
+		{`{ "pattern": "a$b" }`, []string{`/pattern: pattern "a$b" matches no string [unmatchable-pattern]`}},
+		{`{ "pattern": "a^" }`, []string{`/pattern: pattern "a^" matches no string [unmatchable-pattern]`}},
+		{`{ "pattern": "^a$|b" }`, []string{}},
+		{`{ "properties": { "a": {} }, "required": ["a", "b", "a"] }`, []string{
+			`/required: "a" is required more than once [duplicate-value]`,
+			`/required: required property "b" isn't described in properties [undescribed-required]`,
+		}},
+		{`{ "type": "string", "enum": ["a", 1, "a"] }`, []string{
+			`/enum: enum value "a" appears more than once [duplicate-value]`,
+			`/enum/1: enum value 1 fails the schema's other keywords [unreachable-enum-value]`,
+		}},
+		{`{ "type": "string", "anyOf": [{ "maxLength": 2 }, { "type": "integer" }, false] }`, []string{
+			"/anyOf/1: no instance valid against the schema can match this branch [unreachable-branch]",
+			"/anyOf/2: no instance valid against the schema can match this branch [unreachable-branch]",
+		}},
+		{`{ "type": "string", "default": 1, "examples": ["a", null] }`, []string{
+			"/default: default value 1 is invalid against the schema [invalid-default]",
+			"/examples/1: example null is invalid against the schema [invalid-default]",
+		}},
+		{`{ "definitions": { "a": { "minItems": 2, "maxItems": 1 } } }`,
+			[]string{"/definitions/a/minItems: minItems 2 leaves no room below maxItems 1 [contradictory-bounds]"}},
+	}
+
+	for i, c := range cases {
+		rs := Must(c.schema)
+		got := rs.Lint()
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d: expected %d issues, got: %v", i, len(c.expect), got)
+			continue
+		}
+		for j, issue := range got {
+			if issue.Error() != c.expect[j] {
+				t.Errorf("case %d issue %d: expected %s, got: %s", i, j, c.expect[j], issue.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package jsonschema
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// MemoizeRefs sets whether validation caches the results of referenced
+// schemas, keyed by the schema and a hash of the value, so that the same
+// subschema validating structurally identical values many times within a
+// document, as in long arrays of repeated records, is evaluated once.
+// Results are kept for one document at a time, and hashing costs more
+// than it saves on payloads that don't repeat. Schemas using $data aren't
+// memoized, since their results depend on the rest of the instance, and
+// neither are validations with keyword hooks
+func (rs *RootSchema) MemoizeRefs(on bool) {
+	rs.memo = nil
+	if on && !rs.data {
+		rs.memo = &refMemo{}
+	}
+	rs.Schema.Walk(func(_ string, s *Schema) error {
+		if s.Ref != "" {
+			s.memo = rs.memo
+		}
+		return nil
+	})
+}
+
+// refMemo holds the results of referenced schemas for the document being
+// validated
+type refMemo struct {
+	mu      sync.Mutex
+	results map[memoKey][]ValError
+}
+
+// memoKey identifies the validation of a value by a referenced schema
+type memoKey struct {
+	target Validator
+	sum    [sha256.Size]byte
+}
+
+// validate validates data with target as part of vd, reusing the result for a value
+// already validated by it. Errors are kept relative to the value, and
+// placed at propPath when reused
+func (m *refMemo) validate(vd *validation, target Validator, propPath string, data interface{}, errs *[]ValError) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		vd.validate(target, propPath, data, errs)
+		return
+	}
+	key := memoKey{target: target, sum: sha256.Sum256(raw)}
+
+	m.mu.Lock()
+	res, ok := m.results[key]
+	m.mu.Unlock()
+	if ok {
+		for _, e := range res {
+			e.PropertyPath = absoluteInstancePath(e.PropertyPath, propPath)
+			*errs = append(*errs, e)
+		}
+		return
+	}
+
+	res = []ValError{}
+	vd.validate(target, propPath, data, &res)
+	*errs = append(*errs, res...)
+
+	rel := make([]ValError, len(res))
+	for i, e := range res {
+		if e.PropertyPath, ok = relativeInstancePath(e.PropertyPath, propPath); !ok {
+			// errors elsewhere in the instance can't be moved
+			return
+		}
+		rel[i] = e
+	}
+	m.mu.Lock()
+	if m.results == nil {
+		m.results = map[memoKey][]ValError{}
+	}
+	m.results[key] = rel
+	m.mu.Unlock()
+}
+
+// reset drops the results kept, once a document is validated
+func (m *refMemo) reset() {
+	m.mu.Lock()
+	m.results = nil
+	m.mu.Unlock()
+}
+
+// relativeInstancePath gives path relative to the instance path base, the
+// empty string for base itself, reporting false if path isn't within base
+func relativeInstancePath(path, base string) (string, bool) {
+	switch {
+	case path == base:
+		return "", true
+	case base == "/" && strings.HasPrefix(path, "/"):
+		return path, true
+	case strings.HasPrefix(path, base+"/"):
+		return path[len(base):], true
+	}
+	return "", false
+}
+
+// absoluteInstancePath places the relative path rel within base
+func absoluteInstancePath(rel, base string) string {
+	switch {
+	case rel == "":
+		return base
+	case base == "/":
+		return rel
+	}
+	return base + rel
+}
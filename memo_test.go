@@ -0,0 +1,109 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// countCalls is a keyword counting its evaluations
+type countCalls int
+
+var countedCalls int
+
+func (c countCalls) Validate(propPath string, data interface{}, errs *[]ValError) {
+	countedCalls++
+}
+
+func TestMemoizeRefs(t *testing.T) {
+	RegisterValidator("countCalls", func() Validator { return new(countCalls) })
+	defer delete(DefaultValidators, "countCalls")
+
+	rs := Must(`{
+		"properties": {
+			"items": {"items": {"$ref": "#/definitions/item"}},
+			"first": {"$ref": "#/definitions/item"}
+		},
+		"definitions": {
+			"item": {
+				"countCalls": 0,
+				"properties": {"qty": {"type": "integer"}, "name": {"type": "string"}}
+			}
+		}
+	}`)
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"first": {"qty": "x", "name": "a"},
+		"items": [
+			{"qty": 1, "name": "a"},
+			{"qty": "x", "name": "a"},
+			{"name": "a", "qty": 1},
+			{"qty": "x", "name": "a"}
+		]
+	}`), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	validate := func() []string {
+		countedCalls = 0
+		errs := []ValError{}
+		rs.Validate("/", doc, &errs)
+		paths := []string{}
+		for _, e := range errs {
+			paths = append(paths, e.PropertyPath)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+	expect := []string{"/first/qty", "/items/1/qty", "/items/3/qty"}
+
+	if got := validate(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected errors at %v, got %v", expect, got)
+	}
+	if countedCalls != 5 {
+		t.Errorf("expected 5 evaluations without memoizing, got %d", countedCalls)
+	}
+
+	rs.MemoizeRefs(true)
+	if got := validate(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected memoized errors at %v, got %v", expect, got)
+	}
+	if countedCalls != 2 {
+		t.Errorf("expected 2 evaluations when memoizing, got %d", countedCalls)
+	}
+	// results are kept for one document at a time
+	if validate(); countedCalls != 2 {
+		t.Errorf("expected 2 evaluations validating again, got %d", countedCalls)
+	}
+
+	rs.MemoizeRefs(false)
+	if validate(); countedCalls != 5 {
+		t.Errorf("expected 5 evaluations once memoizing is off, got %d", countedCalls)
+	}
+}
+
+func TestInstancePathRebasing(t *testing.T) {
+	cases := []struct {
+		path, from, to, expect string
+	}{
+		{"/", "/", "/a/0", "/a/0"},
+		{"/x/y", "/", "/a/0", "/a/0/x/y"},
+		{"/a/0", "/a/0", "/", "/"},
+		{"/a/0/x", "/a/0", "/", "/x"},
+		{"/a/0/x", "/a/0", "/b", "/b/x"},
+	}
+	for _, c := range cases {
+		rel, ok := relativeInstancePath(c.path, c.from)
+		if !ok {
+			t.Errorf("%s isn't within %s", c.path, c.from)
+			continue
+		}
+		if got := absoluteInstancePath(rel, c.to); got != c.expect {
+			t.Errorf("moving %s from %s to %s: expected %s, got %s", c.path, c.from, c.to, c.expect, got)
+		}
+	}
+	if _, ok := relativeInstancePath("/ab", "/a"); ok {
+		t.Errorf("expected /ab to be outside /a")
+	}
+}
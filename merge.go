@@ -0,0 +1,381 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// MergeConflict describes a part of an "allOf" branch that Merge could not
+// fold into its parent schema
+type MergeConflict struct {
+	// Pointer is the JSON pointer to the allOf branch
+	Pointer string
+	// Keyword is the keyword that couldn't be merged, empty when the
+	// whole branch was kept
+	Keyword string
+	// Message is a human-readable description of the conflict
+	Message string
+}
+
+// Error implements the error interface for MergeConflict
+func (c MergeConflict) Error() string {
+	if c.Keyword == "" {
+		return fmt.Sprintf("%s: %s", c.Pointer, c.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Pointer, c.Keyword, c.Message)
+}
+
+// keyword groups whose behaviour depends on each other. Groups are merged
+// as a unit
+var siblingGroups = [][]string{
+	{"properties", "patternProperties", "additionalProperties"},
+	{"items", "additionalItems"},
+	{"if", "then", "else"},
+}
+
+// Merge returns a copy of the schema with "allOf" branches folded into the
+// schemas that contain them, wherever that can be done without changing
+// which instances are valid: numeric and length bounds are tightened, types
+// and enums intersected, required lists and properties combined.
+// Keywords that can't be combined stay behind in a reduced "allOf", and each
+// is described by a MergeConflict. Merge doesn't follow or rewrite
+// references, so references into allOf branches may no longer resolve when
+// the result is encoded and parsed again
+func (s *Schema) Merge() (*Schema, []MergeConflict) {
+	cp := s.Clone()
+
+	// merge innermost schemas first so merged branches are already flat
+	type located struct {
+		ptr string
+		sch *Schema
+	}
+	all := []located{}
+	cp.Walk(func(ptr string, sch *Schema) error {
+		all = append(all, located{ptr, sch})
+		return nil
+	})
+
+	conflicts := []MergeConflict{}
+	for i := len(all) - 1; i >= 0; i-- {
+		conflicts = append(conflicts, mergeAllOf(all[i].ptr, all[i].sch)...)
+	}
+	return cp, conflicts
+}
+
+// mergeAllOf folds the allOf branches of s into s in place
+func mergeAllOf(ptr string, s *Schema) (conflicts []MergeConflict) {
+	allOf, ok := s.Validators["allOf"].(*AllOf)
+	// siblings of $ref are ignored during validation, so there's nothing to gain
+	if !ok || s.Ref != "" || s.schemaType != schemaTypeObject {
+		return nil
+	}
+	delete(s.Validators, "allOf")
+
+	remaining := AllOf{}
+	for i, branch := range *allOf {
+		bptr := ptr + "/allOf/" + strconv.Itoa(i)
+		residual, cs := mergeBranch(bptr, s, branch)
+		conflicts = append(conflicts, cs...)
+		if s.schemaType == schemaTypeFalse {
+			// nothing is valid against the false schema
+			return conflicts
+		}
+		if residual != nil {
+			remaining = append(remaining, residual)
+		}
+	}
+	if len(remaining) > 0 {
+		s.Validators["allOf"] = &remaining
+	}
+	s.linkSiblings()
+	return conflicts
+}
+
+// mergeBranch folds a single branch into s, returning whatever couldn't be
+// merged as a residual schema
+func mergeBranch(ptr string, s, branch *Schema) (*Schema, []MergeConflict) {
+	switch {
+	case branch == nil || branch.schemaType == schemaTypeTrue:
+		return nil, nil
+	case branch.schemaType == schemaTypeFalse:
+		*s = Schema{schemaType: schemaTypeFalse, Validators: map[string]Validator{"not": &Not{}}}
+		return nil, nil
+	case branch.Ref != "":
+		return branch, []MergeConflict{{Pointer: ptr, Message: "references aren't inlined"}}
+	case branch.ID != "" && branch.ID != s.ID:
+		return branch, []MergeConflict{{Pointer: ptr, Message: "branch declares a different $id"}}
+	}
+
+	if s.Title == "" {
+		s.Title = branch.Title
+	}
+	if s.Description == "" {
+		s.Description = branch.Description
+	}
+	if s.Comment == "" {
+		s.Comment = branch.Comment
+	}
+	if s.Default == nil {
+		s.Default = branch.Default
+	}
+	if s.ReadOnly == nil {
+		s.ReadOnly = branch.ReadOnly
+	}
+	if s.WriteOnly == nil {
+		s.WriteOnly = branch.WriteOnly
+	}
+	s.Examples = append(s.Examples, branch.Examples...)
+
+	residual := &Schema{Validators: map[string]Validator{}}
+	conflicts := []MergeConflict{}
+	keep := func(key, msg string) {
+		residual.Validators[key] = branch.Validators[key]
+		conflicts = append(conflicts, MergeConflict{Pointer: ptr, Keyword: key, Message: msg})
+	}
+
+	s.Definitions, residual.Definitions = mergeDefinitions(ptr, "definitions", s.Definitions, branch.Definitions, &conflicts)
+	s.Defs, residual.Defs = mergeDefinitions(ptr, "$defs", s.Defs, branch.Defs, &conflicts)
+
+	grouped := map[string]bool{}
+	for _, group := range siblingGroups {
+		for _, key := range group {
+			grouped[key] = true
+		}
+		mergeGroup(group, s, branch, keep)
+	}
+
+	keys := make([]string, 0, len(branch.Validators))
+	for key := range branch.Validators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if grouped[key] {
+			continue
+		}
+		bv := branch.Validators[key]
+		sv, ok := s.Validators[key]
+		if !ok {
+			s.Validators[key] = bv
+			if key == "format" {
+				s.Format = branch.Format
+			}
+			continue
+		}
+		if msg := mergeKeyword(key, s, sv, bv); msg != "" {
+			keep(key, msg)
+		}
+	}
+
+	if len(residual.Validators) == 0 && residual.Definitions == nil && residual.Defs == nil {
+		return nil, conflicts
+	}
+	residual.linkSiblings()
+	return residual, conflicts
+}
+
+// mergeDefinitions adds the definitions of a branch to those of its parent,
+// returning the combined definitions and any that conflict
+func mergeDefinitions(ptr, keyword string, defs, branch Definitions, conflicts *[]MergeConflict) (merged, residual Definitions) {
+	merged = defs
+	for key, def := range branch {
+		if merged == nil {
+			merged = Definitions{}
+		}
+		if existing := merged[key]; existing == nil {
+			merged[key] = def
+		} else if !Equal(existing, def) {
+			if residual == nil {
+				residual = Definitions{}
+			}
+			residual[key] = def
+			*conflicts = append(*conflicts, MergeConflict{Pointer: ptr, Keyword: keyword, Message: fmt.Sprintf("conflicting definitions of %q", key)})
+		}
+	}
+	return merged, residual
+}
+
+// mergeGroup merges a group of sibling-dependent keywords from branch into s
+func mergeGroup(group []string, s, branch *Schema, keep func(key, msg string)) {
+	inBranch, inSchema := []string{}, []string{}
+	for _, key := range group {
+		if branch.Validators[key] != nil {
+			inBranch = append(inBranch, key)
+		}
+		if s.Validators[key] != nil {
+			inSchema = append(inSchema, key)
+		}
+	}
+
+	switch {
+	case len(inBranch) == 0:
+		return
+	case len(inSchema) == 0:
+		for _, key := range inBranch {
+			s.Validators[key] = branch.Validators[key]
+		}
+		return
+	case len(inBranch) == 1 && len(inSchema) == 1 && inBranch[0] == inSchema[0]:
+		switch a := s.Validators[inSchema[0]].(type) {
+		case *Properties:
+			b := branch.Validators["properties"].(*Properties)
+			for key, sch := range *b {
+				if (*a)[key] == nil {
+					(*a)[key] = sch
+				} else {
+					(*a)[key] = mergeSubschemas((*a)[key], sch)
+				}
+			}
+			return
+		case *Items:
+			b := branch.Validators["items"].(*Items)
+			if a.single && b.single {
+				a.Schemas[0] = mergeSubschemas(a.Schemas[0], b.Schemas[0])
+				return
+			}
+		}
+	}
+
+	same := len(inBranch) == len(inSchema)
+	for _, key := range inBranch {
+		if !Equal(keywordSchema(s.Validators[key]), keywordSchema(branch.Validators[key])) {
+			same = false
+		}
+	}
+	if same {
+		return
+	}
+	// keywords in a group only make sense together, so keep all of them
+	for _, key := range inBranch {
+		keep(key, "depends on sibling keywords in both schemas")
+	}
+}
+
+// mergeSubschemas combines two schemas as if they were branches of an allOf
+func mergeSubschemas(a, b *Schema) *Schema {
+	if Equal(a, b) {
+		return a
+	}
+	sch := &Schema{Validators: map[string]Validator{"allOf": &AllOf{a, b}}}
+	mergeAllOf("", sch)
+	return sch
+}
+
+// keywordSchema wraps a single keyword in a schema for comparison
+func keywordSchema(v Validator) *Schema {
+	return &Schema{Validators: map[string]Validator{"_": v}}
+}
+
+// mergeKeyword intersects two values of the same keyword, storing the result
+// in s. It returns a non-empty message if the values can't be combined
+func mergeKeyword(key string, s *Schema, a, b Validator) string {
+	switch av := a.(type) {
+	case *Type:
+		types := intersectTypes(av.vals, b.(*Type).vals)
+		if len(types) == 0 {
+			return fmt.Sprintf("no type satisfies both %s and %s", av.String(), b.(*Type).String())
+		}
+		s.Validators[key] = &Type{strVal: len(types) == 1, vals: types}
+	case *Maximum:
+		*av = Maximum(math.Min(float64(*av), float64(*b.(*Maximum))))
+	case *ExclusiveMaximum:
+		*av = ExclusiveMaximum(math.Min(float64(*av), float64(*b.(*ExclusiveMaximum))))
+	case *Minimum:
+		*av = Minimum(math.Max(float64(*av), float64(*b.(*Minimum))))
+	case *ExclusiveMinimum:
+		*av = ExclusiveMinimum(math.Max(float64(*av), float64(*b.(*ExclusiveMinimum))))
+	case *MaxLength:
+		if bv := *b.(*MaxLength); bv < *av {
+			*av = bv
+		}
+	case *MinLength:
+		if bv := *b.(*MinLength); bv > *av {
+			*av = bv
+		}
+	case *MaxItems:
+		if bv := *b.(*MaxItems); bv < *av {
+			*av = bv
+		}
+	case *MinItems:
+		if bv := *b.(*MinItems); bv > *av {
+			*av = bv
+		}
+	case *MaxProperties:
+		if bv := *b.(*MaxProperties); bv < *av {
+			*av = bv
+		}
+	case *minProperties:
+		if bv := *b.(*minProperties); bv > *av {
+			*av = bv
+		}
+	case *UniqueItems:
+		*av = *av || *b.(*UniqueItems)
+	case *MultipleOf:
+		x, y := float64(*av), float64(*b.(*MultipleOf))
+		switch {
+		case math.Mod(x, y) == 0:
+		case math.Mod(y, x) == 0:
+			*av = MultipleOf(y)
+		default:
+			return "multiples can't be combined"
+		}
+	case *Required:
+		*av = Required(uniqueStrings(append(*av, *b.(*Required)...)))
+	case *Enum:
+		both := Enum{}
+		for _, x := range *av {
+			for _, y := range *b.(*Enum) {
+				if jsonEqual(x, y) {
+					both = append(both, x)
+					break
+				}
+			}
+		}
+		if len(both) == 0 {
+			return "enums share no values"
+		}
+		*av = both
+	case *Const:
+		if !jsonEqual(*av, *b.(*Const)) {
+			return "conflicting constants"
+		}
+	default:
+		if !Equal(keywordSchema(a), keywordSchema(b)) {
+			return "values can't be combined"
+		}
+	}
+	return ""
+}
+
+// intersectTypes gives the types in both a and b, where integer is a
+// subset of number
+func intersectTypes(a, b []string) []string {
+	res := []string{}
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case x == y:
+				res = append(res, x)
+			case x == "integer" && y == "number", x == "number" && y == "integer":
+				res = append(res, "integer")
+			}
+		}
+	}
+	return uniqueStrings(res)
+}
+
+// jsonEqual compares two encoded JSON values for semantic equality
+func jsonEqual(a, b []byte) bool {
+	ca, err := canonicalJSON(a)
+	if err != nil {
+		return false
+	}
+	cb, err := canonicalJSON(b)
+	if err != nil {
+		return false
+	}
+	return string(ca) == string(cb)
+}
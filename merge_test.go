@@ -0,0 +1,66 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		input, expect string
+		conflicts     []string
+	}{
+		{`{ "allOf": [{ "type": "string" }, { "maxLength": 3 }] }`,
+			`{"maxLength":3,"type":"string"}`, nil},
+		{`{ "type": ["number", "null"], "maximum": 10, "allOf": [{ "type": "integer", "maximum": 5 }, { "minimum": 1 }] }`,
+			`{"maximum":5,"minimum":1,"type":"integer"}`, nil},
+		{`{ "allOf": [
+				{ "required": ["a"], "properties": { "a": { "type": "string" } } },
+				{ "required": ["b"], "properties": { "a": { "minLength": 1 }, "b": true } }
+			] }`,
+			`{"properties":{"a":{"minLength":1,"type":"string"},"b":true},"required":["a","b"]}`, nil},
+		{`{ "allOf": [{ "enum": [1, 2, 3] }, { "enum": [3, 2.0, 4] }] }`,
+			`{"enum":[2,3]}`, nil},
+		{`{ "allOf": [{ "allOf": [{ "title": "inner" }, { "minItems": 2 }] }, { "minItems": 1 }] }`,
+			`{"minItems":2,"title":"inner"}`, nil},
+		{`{ "allOf": [{ "pattern": "^a" }, { "pattern": "b$" }] }`,
+			`{"allOf":[{"pattern":"b$"}],"pattern":"^a"}`, []string{"/allOf/1: pattern: values can't be combined"}},
+		{`{ "allOf": [{ "type": "string" }, { "type": "integer" }] }`,
+			`{"allOf":[{"type":"integer"}],"type":"string"}`, []string{"/allOf/1: type: no type satisfies both string and integer"}},
+		{`{ "properties": { "a": { "allOf": [{ "$ref": "#/definitions/a" }] } }, "definitions": { "a": true } }`,
+			`{"definitions":{"a":true},"properties":{"a":{"allOf":[{"$ref":"#/definitions/a"}]}}}`,
+			[]string{"/properties/a/allOf/0: references aren't inlined"}},
+		{`{ "allOf": [{ "additionalProperties": false }, { "properties": { "a": true } }] }`,
+			`{"additionalProperties":false,"allOf":[{"properties":{"a":true}}]}`,
+			[]string{"/allOf/1: properties: depends on sibling keywords in both schemas"}},
+		{`{ "allOf": [{ "properties": { "a": true } }, { "properties": { "a": true }, "additionalProperties": false }] }`,
+			`{"allOf":[{"additionalProperties":false,"properties":{"a":true}}],"properties":{"a":true}}`,
+			[]string{
+				"/allOf/1: properties: depends on sibling keywords in both schemas",
+				"/allOf/1: additionalProperties: depends on sibling keywords in both schemas",
+			}},
+		{`{ "allOf": [{ "type": "string" }, false] }`, `false`, nil},
+	}
+
+	for i, c := range cases {
+		rs := Must(c.input)
+		merged, conflicts := rs.Schema.Merge()
+		data, err := json.Marshal(merged)
+		if err != nil {
+			t.Errorf("case %d error encoding: %s", i, err)
+			continue
+		}
+		if string(data) != c.expect {
+			t.Errorf("case %d result mismatch.\nexpected: %s\ngot:      %s", i, c.expect, data)
+		}
+		if len(conflicts) != len(c.conflicts) {
+			t.Errorf("case %d: expected %d conflicts, got: %v", i, len(c.conflicts), conflicts)
+			continue
+		}
+		for j, conflict := range conflicts {
+			if conflict.Error() != c.conflicts[j] {
+				t.Errorf("case %d conflict %d mismatch. expected: %s, got: %s", i, j, c.conflicts[j], conflict.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergePatch applies patch, a JSON Merge Patch (RFC 7386), to doc, giving
+// the merged document. Both are as encoding/json decodes them, and doc is
+// left as it is
+func MergePatch(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return cloneValue(patch)
+	}
+	target, ok := doc.(map[string]interface{})
+	if !ok {
+		target = map[string]interface{}{}
+	}
+	merged := make(map[string]interface{}, len(target)+len(p))
+	for key, val := range target {
+		merged[key] = cloneValue(val)
+	}
+	for key, val := range p {
+		if val == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = MergePatch(target[key], val)
+	}
+	return merged
+}
+
+// MergePatchError is a validation error of a merged document, with the
+// members of the merge patch that caused it
+type MergePatchError struct {
+	// Keys are the JSON pointers of the members of the patch that caused the
+	// error, within the patch. They're empty if the document had the error
+	// before patching, and hold "" when the patch replaced the whole
+	// document
+	Keys []string
+	ValError
+}
+
+// Error implements the error interface for MergePatchError
+func (e MergePatchError) Error() string {
+	if len(e.Keys) == 0 {
+		return "before patching: " + e.ValError.Error()
+	}
+	return fmt.Sprintf("patch %s: %s", strings.Join(e.Keys, ", "), e.ValError.Error())
+}
+
+// ValidateMergePatch applies patch, a JSON Merge Patch, to doc and
+// validates the result, giving the merged document and its validation
+// errors. Errors the document didn't have before are mapped to the patch
+// members without which they wouldn't occur or, when no single member is
+// to blame, to the members at or within the invalid location
+func (rs *RootSchema) ValidateMergePatch(doc, patch interface{}) (interface{}, []MergePatchError) {
+	before := map[string]bool{}
+	for _, e := range rs.mergePatchErrors(doc, nil) {
+		before[patchErrorKey(e)] = true
+	}
+	merged := MergePatch(doc, patch)
+	errs := rs.mergePatchErrors(merged, nil)
+
+	res := make([]MergePatchError, len(errs))
+	members := mergePatchMembers(patch, nil, nil)
+	without := make([]map[string]bool, len(members))
+	for i, e := range errs {
+		res[i] = MergePatchError{ValError: e}
+		if before[patchErrorKey(e)] {
+			continue
+		}
+		for j, m := range members {
+			if without[j] == nil {
+				without[j] = map[string]bool{}
+				rs.mergePatchErrors(MergePatch(doc, omitMergePatchMember(patch, m)), without[j])
+			}
+			if !without[j][e.PropertyPath+"\x00"+e.Message] {
+				res[i].Keys = append(res[i].Keys, m.String())
+			}
+		}
+		if len(res[i].Keys) == 0 {
+			res[i].Keys = mergePatchKeys(members, e.PropertyPath)
+		}
+	}
+	return merged, res
+}
+
+// mergePatchErrors validates doc, adding the locations and messages of its
+// errors to keys when it isn't nil. Invalid values are left out of keys,
+// since leaving out members of a patch changes the values of the objects
+// holding them
+func (rs *RootSchema) mergePatchErrors(doc interface{}, keys map[string]bool) []ValError {
+	errs := []ValError{}
+	rs.Validate("/", doc, &errs)
+	if keys != nil {
+		for _, e := range errs {
+			keys[e.PropertyPath+"\x00"+e.Message] = true
+		}
+	}
+	return errs
+}
+
+// mergePatchMembers lists the pointers of the members of patch that
+// change the document: those that aren't objects, or are empty ones. A
+// patch that isn't an object replaces the whole document, and is listed
+// as the empty pointer
+func mergePatchMembers(patch interface{}, ptr Pointer, list []Pointer) []Pointer {
+	obj, ok := patch.(map[string]interface{})
+	if !ok || (len(obj) == 0 && ptr != nil) {
+		return append(list, ptr.Descend())
+	}
+	for _, key := range sortedMapKeys(obj) {
+		list = mergePatchMembers(obj[key], ptr.Descend(key), list)
+	}
+	return list
+}
+
+// omitMergePatchMember gives a copy of patch without the member at ptr
+func omitMergePatchMember(patch interface{}, ptr Pointer) interface{} {
+	if len(ptr) == 0 {
+		return map[string]interface{}{}
+	}
+	omitted, _, _ := patchRemove(cloneValue(patch), ptr)
+	return omitted
+}
+
+// mergePatchKeys gives the patch members at or within the location
+// propPath, or the one holding it
+func mergePatchKeys(members []Pointer, propPath string) []string {
+	loc := Pointer{}
+	if propPath != "/" {
+		var err error
+		if loc, err = ParsePointer(propPath); err != nil {
+			return nil
+		}
+	}
+	keys := []string{}
+	for _, m := range members {
+		if m.hasPrefix(loc) || loc.hasPrefix(m) {
+			keys = append(keys, m.String())
+		}
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// examples from RFC 7386 appendix A
+	cases := []struct {
+		doc, patch, expect string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+	for _, c := range cases {
+		var doc, patch interface{}
+		json.Unmarshal([]byte(c.doc), &doc)
+		json.Unmarshal([]byte(c.patch), &patch)
+		assertJSONEqual(t, c.patch, c.expect, MergePatch(doc, patch))
+		assertJSONEqual(t, c.patch, c.doc, doc)
+	}
+}
+
+func TestValidateMergePatch(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["name", "address"],
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer", "minimum": 0},
+			"address": {
+				"type": "object",
+				"required": ["city"],
+				"properties": {"city": {"type": "string"}, "zip": {"type": "string"}}
+			}
+		}
+	}`)
+	var doc, patch interface{}
+	json.Unmarshal([]byte(`{"name": "ada", "age": -1, "address": {"city": "london"}}`), &doc)
+	json.Unmarshal([]byte(`{"name": null, "address": {"city": null, "zip": 1}, "age": -1}`), &patch)
+
+	merged, errs := rs.ValidateMergePatch(doc, patch)
+	assertJSONEqual(t, "merged", `{"age": -1, "address": {"zip": 1}}`, merged)
+
+	got := map[string]string{}
+	for _, e := range errs {
+		got[e.PropertyPath+" "+e.Message] = strings.Join(e.Keys, ",")
+	}
+	expect := map[string]string{
+		`/ "name" value is required`:                     "/name",
+		`/address "city" value is required`:              "/address/city",
+		"/address/zip type should be string":             "/address/zip",
+		"/age must be greater than or equal to 0.000000": "",
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("expected errors %v, got %v", expect, errs)
+	}
+	for msg, keys := range expect {
+		if k, ok := got[msg]; !ok || k != keys {
+			t.Errorf("%s: expected keys %q, got %q", msg, keys, k)
+		}
+	}
+
+	// no single member causes the error, so those within its location do
+	exclusive := Must(`{"not": {"anyOf": [{"required": ["a"]}, {"required": ["b"]}]}}`)
+	json.Unmarshal([]byte(`{"a": 1, "b": {"c": 2}, "d": {}}`), &patch)
+	_, errs = exclusive.ValidateMergePatch(map[string]interface{}{}, patch)
+	if len(errs) != 1 || strings.Join(errs[0].Keys, ",") != "/a,/b/c,/d" {
+		t.Errorf("expected the error to be mapped to every member, got %v", errs)
+	}
+
+	_, errs = rs.ValidateMergePatch(doc, "replaced")
+	if len(errs) != 1 || len(errs[0].Keys) != 1 || errs[0].Keys[0] != "" {
+		t.Errorf("expected a whole document replacement to be reported at the empty pointer, got %v", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "patch : ") {
+		t.Errorf("unexpected error string %q", errs[0].Error())
+	}
+}
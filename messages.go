@@ -0,0 +1,127 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// TypedMessages validates JSON messages, such as WebSocket frames, against
+// schemas selected by a member of the messages naming their type
+type TypedMessages struct {
+	// TypeField is the member holding the type of a message, "type" if
+	// empty
+	TypeField string
+	// Schemas maps message types to the schemas of their messages
+	Schemas map[string]*RootSchema
+	// Default validates messages of types without a schema. When nil they're
+	// rejected
+	Default *RootSchema
+}
+
+// MessageRejection describes why a message was rejected, for sending back
+// to its sender
+type MessageRejection struct {
+	// Type is always "rejected", so senders can tell rejections from the
+	// other messages they receive
+	Type string `json:"type"`
+	// MessageType is the type of the rejected message, if it has one
+	MessageType string         `json:"messageType,omitempty"`
+	Errors      []MessageError `json:"errors"`
+}
+
+// MessageError is an error of a rejected message
+type MessageError struct {
+	InstanceLocation string `json:"instanceLocation"`
+	Message          string `json:"error"`
+}
+
+// Error implements the error interface for MessageRejection
+func (r *MessageRejection) Error() string {
+	if len(r.Errors) == 0 {
+		return "message rejected"
+	}
+	e := r.Errors[0]
+	msg := e.Message
+	if e.InstanceLocation != "" {
+		msg = e.InstanceLocation + ": " + msg
+	}
+	if len(r.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more errors)", len(r.Errors)-1)
+	}
+	return msg
+}
+
+// Validate gives the type of the message data, and a rejection if the
+// message isn't a valid one of its type
+func (t *TypedMessages) Validate(data []byte) (string, *MessageRejection) {
+	field := t.TypeField
+	if field == "" {
+		field = "type"
+	}
+	reject := func(msgType, loc, msg string) (string, *MessageRejection) {
+		return msgType, &MessageRejection{Type: "rejected", MessageType: msgType, Errors: []MessageError{{InstanceLocation: loc, Message: msg}}}
+	}
+
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return reject("", "", "message must be a JSON object: "+err.Error())
+	}
+	var msgType string
+	if raw, ok := msg[field]; !ok {
+		return reject("", "", fmt.Sprintf("missing %q member naming the message type", field))
+	} else if err := json.Unmarshal(raw, &msgType); err != nil {
+		return reject("", "/"+EscapePointerToken(field), "message type must be a string")
+	}
+
+	rs := t.Schemas[msgType]
+	if rs == nil {
+		rs = t.Default
+	}
+	if rs == nil {
+		return reject(msgType, "/"+EscapePointerToken(field), fmt.Sprintf("unknown message type %q", msgType))
+	}
+	errs, err := rs.ValidateBytes(data)
+	if err != nil {
+		return reject(msgType, "", err.Error())
+	}
+	if len(errs) == 0 {
+		return msgType, nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].PropertyPath < errs[j].PropertyPath
+	})
+	rej := &MessageRejection{Type: "rejected", MessageType: msgType}
+	for _, e := range errs {
+		loc := e.PropertyPath
+		if loc == "/" {
+			loc = ""
+		}
+		rej.Errors = append(rej.Errors, MessageError{InstanceLocation: loc, Message: e.Message})
+	}
+	return msgType, rej
+}
+
+// ReadLoop reads messages with read until it fails, such as with the
+// ReadMessage method of a WebSocket connection wrapped to drop the frame
+// type, passing valid messages and their types to handle and rejections
+// of invalid ones to reject, which typically writes them back to the
+// sender as JSON. The loop ends with the first error of any of the three,
+// which it returns
+func (t *TypedMessages) ReadLoop(read func() ([]byte, error), handle func(msgType string, data []byte) error, reject func(*MessageRejection) error) error {
+	for {
+		data, err := read()
+		if err != nil {
+			return err
+		}
+		msgType, rej := t.Validate(data)
+		if rej != nil {
+			err = reject(rej)
+		} else {
+			err = handle(msgType, data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestTypedMessages(t *testing.T) {
+	msgs := &TypedMessages{Schemas: map[string]*RootSchema{
+		"chat": Must(`{"properties": {"text": {"type": "string", "maxLength": 5}}, "required": ["text"]}`),
+		"ping": Must(`{}`),
+	}}
+
+	cases := []struct {
+		data      string
+		msgType   string
+		rejection *MessageRejection
+	}{
+		{`{"type": "chat", "text": "hi"}`, "chat", nil},
+		{`{"type": "ping"}`, "ping", nil},
+		{`{"type": "chat", "text": "too long"}`, "chat", &MessageRejection{Type: "rejected", MessageType: "chat", Errors: []MessageError{
+			{InstanceLocation: "/text", Message: "max length of 5 characters exceeded: too long"},
+		}}},
+		{`{"type": "move"}`, "move", &MessageRejection{Type: "rejected", MessageType: "move", Errors: []MessageError{
+			{InstanceLocation: "/type", Message: `unknown message type "move"`},
+		}}},
+		{`{"text": "hi"}`, "", &MessageRejection{Type: "rejected", Errors: []MessageError{
+			{Message: `missing "type" member naming the message type`},
+		}}},
+		{`{"type": 1}`, "", &MessageRejection{Type: "rejected", Errors: []MessageError{
+			{InstanceLocation: "/type", Message: "message type must be a string"},
+		}}},
+	}
+	for _, c := range cases {
+		msgType, rej := msgs.Validate([]byte(c.data))
+		if msgType != c.msgType || !reflect.DeepEqual(rej, c.rejection) {
+			t.Errorf("%s: expected %q %+v, got %q %+v", c.data, c.msgType, c.rejection, msgType, rej)
+		}
+	}
+
+	msgs.TypeField = "kind"
+	msgs.Default = Must(`{"required": ["id"]}`)
+	if _, rej := msgs.Validate([]byte(`{"kind": "other", "id": 1}`)); rej != nil {
+		t.Errorf("expected the default schema to accept the message, got %s", rej)
+	}
+	if _, rej := msgs.Validate([]byte(`[]`)); rej == nil {
+		t.Errorf("expected a non-object message to be rejected")
+	}
+}
+
+func TestTypedMessagesReadLoop(t *testing.T) {
+	msgs := &TypedMessages{Schemas: map[string]*RootSchema{
+		"chat": Must(`{"required": ["text"]}`),
+	}}
+	frames := []string{`{"type": "chat", "text": "hi"}`, `{"type": "chat"}`, `not json`}
+	read := func() ([]byte, error) {
+		if len(frames) == 0 {
+			return nil, io.EOF
+		}
+		frame := frames[0]
+		frames = frames[1:]
+		return []byte(frame), nil
+	}
+
+	var handled []string
+	var sent []string
+	err := msgs.ReadLoop(read, func(msgType string, data []byte) error {
+		handled = append(handled, msgType)
+		return nil
+	}, func(rej *MessageRejection) error {
+		data, err := json.Marshal(rej)
+		sent = append(sent, string(data))
+		return err
+	})
+	if err != io.EOF {
+		t.Errorf("expected the read error to end the loop, got %v", err)
+	}
+	if !reflect.DeepEqual(handled, []string{"chat"}) {
+		t.Errorf("unexpected handled messages: %v", handled)
+	}
+	if len(sent) != 2 || sent[0] != `{"type":"rejected","messageType":"chat","errors":[{"instanceLocation":"","error":"\"text\" value is required"}]}` {
+		t.Errorf("unexpected rejections: %v", sent)
+	}
+}
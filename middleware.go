@@ -0,0 +1,379 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxBodyBytes is the request body size limit of Middleware when
+// MiddlewareOptions doesn't set one
+const defaultMaxBodyBytes = 10 << 20
+
+// MiddlewareOptions configures Middleware
+type MiddlewareOptions struct {
+	// Query validates the query parameters of requests when set. Parameters
+	// are validated as an object of strings, with parameters given more than
+	// once, or described by the schema as arrays, given as arrays of
+	// strings. A value is converted to a number or boolean where the schema
+	// of its parameter wants one and not a string
+	Query *RootSchema
+	// Headers validates request headers as Query does parameters, keyed by
+	// their canonical names such as "Content-Type"
+	Headers *RootSchema
+	// MaxBodyBytes limits the size of request bodies, 10MB if zero
+	MaxBodyBytes int64
+	// ErrorHandler responds to requests that fail validation. By default a
+	// RequestErrors is written as JSON
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, errs *RequestErrors)
+}
+
+// RequestErrors is the outcome of validating a request that failed
+type RequestErrors struct {
+	// Status is the HTTP status to respond with: 400 for invalid requests,
+	// or 413 for bodies over the size limit
+	Status int            `json:"-"`
+	Valid  bool           `json:"valid"`
+	Errors []RequestError `json:"errors"`
+}
+
+// RequestError is a validation error of part of a request
+type RequestError struct {
+	// In is the part of the request at fault: "body", "query" or "header"
+	In string `json:"in"`
+	// InstanceLocation is a JSON pointer to the value at fault within
+	// that part
+	InstanceLocation string `json:"instanceLocation"`
+	Message          string `json:"error"`
+}
+
+// Middleware wraps handlers so the requests they're given are validated
+// first, their JSON bodies against schema and their query parameters and
+// headers against the schemas of opts. A nil schema leaves bodies alone,
+// and an empty body fails validation against any other. Invalid requests
+// are answered by opts.ErrorHandler without reaching the wrapped handler,
+// which is given the body to read again otherwise
+func Middleware(schema *RootSchema, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	opts = opts.withDefaults()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if res := validateRequest(w, r, schema, opts.Query, opts.Headers, opts.MaxBodyBytes); len(res.Errors) > 0 {
+				opts.ErrorHandler(w, r, res)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (opts MiddlewareOptions) withDefaults() MiddlewareOptions {
+	if opts.MaxBodyBytes == 0 {
+		opts.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = writeRequestErrors
+	}
+	return opts
+}
+
+// validateRequest validates the body, query parameters and headers of r
+// against the schemas given for them, any of which may be nil. The body is
+// replaced for the next handler to read
+func validateRequest(w http.ResponseWriter, r *http.Request, body, query, headers *RootSchema, limit int64) *RequestErrors {
+	res := &RequestErrors{Status: http.StatusBadRequest, Errors: []RequestError{}}
+	if query != nil {
+		res.add("query", validateParams(query, r.URL.Query()))
+	}
+	if headers != nil {
+		res.add("header", validateParams(headers, r.Header))
+	}
+	if body == nil {
+		return res
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	r.Body.Close()
+	switch {
+	case err != nil:
+		res.Status = http.StatusRequestEntityTooLarge
+		res.Errors = append(res.Errors, RequestError{In: "body", Message: err.Error()})
+	case len(bytes.TrimSpace(data)) == 0:
+		res.Errors = append(res.Errors, RequestError{In: "body", Message: "request body is required"})
+	default:
+		errs, err := body.ValidateBytes(data)
+		if err != nil {
+			res.Errors = append(res.Errors, RequestError{In: "body", Message: err.Error()})
+		}
+		res.add("body", errs)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return res
+}
+
+// add appends the errors of the request part in to res
+func (res *RequestErrors) add(in string, errs []ValError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].PropertyPath < errs[j].PropertyPath
+	})
+	for _, e := range errs {
+		loc := e.PropertyPath
+		if loc == "/" {
+			loc = ""
+		}
+		res.Errors = append(res.Errors, RequestError{In: in, InstanceLocation: loc, Message: e.Message})
+	}
+}
+
+// writeRequestErrors is the default MiddlewareOptions.ErrorHandler
+func writeRequestErrors(w http.ResponseWriter, r *http.Request, errs *RequestErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errs.Status)
+	json.NewEncoder(w).Encode(errs)
+}
+
+// validateParams validates query parameters or headers against rs
+func validateParams(rs *RootSchema, params map[string][]string) []ValError {
+	obj := map[string]interface{}{}
+	for name, vals := range params {
+		obj[name] = paramValue(rs.options().Decoding, propertySchema(&rs.Schema, name), vals)
+	}
+	errs := []ValError{}
+	rs.Validate("/", obj, &errs)
+	return errs
+}
+
+// paramValue converts the values of a parameter described by s to the
+// types s wants
+func paramValue(o DecodeOptions, s *Schema, vals []string) interface{} {
+	s, ok := resolveSchema(s)
+	if !ok || s.schemaType != schemaTypeObject {
+		s = trueSchema
+	}
+	types := impliedTypes(s)
+	if len(vals) == 1 && !(containsType(types, "array") && !containsType(types, "string")) {
+		return paramScalar(o, types, vals[0])
+	}
+	arr := make([]interface{}, len(vals))
+	for i, val := range vals {
+		item, ok := resolveSchema(itemSchema(s, i))
+		if !ok || item.schemaType != schemaTypeObject {
+			item = trueSchema
+		}
+		arr[i] = paramScalar(o, impliedTypes(item), val)
+	}
+	return arr
+}
+
+// paramScalar converts val to a number or boolean if types wants one
+// rather than a string
+func paramScalar(o DecodeOptions, types []string, val string) interface{} {
+	if containsType(types, "string") {
+		return val
+	}
+	if containsType(types, "number") {
+		if n, err := strconv.ParseFloat(val, 64); err == nil {
+			return o.decodedNumberText(val, n)
+		}
+	}
+	if containsType(types, "boolean") && (val == "true" || val == "false") {
+		return val == "true"
+	}
+	return val
+}
+
+// ResponseOptions configures ResponseMiddleware
+type ResponseOptions struct {
+	// Enforce holds responses back until they're validated, replacing those
+	// that fail with a 500 Internal Server Error. By default responses are
+	// validated in shadow mode, reaching clients unchanged as they're
+	// written and reported only once complete
+	Enforce bool
+	// OnViolation is called for responses that fail validation. By default
+	// violations are logged with the log package
+	OnViolation func(v *ResponseViolation)
+	// MaxBodyBytes limits the size of the responses validated, 10MB if
+	// zero. Larger responses are passed on, or in enforce mode replaced, and
+	// reported as violations
+	MaxBodyBytes int64
+}
+
+// ResponseViolation is a response that failed validation
+type ResponseViolation struct {
+	Request *http.Request
+	Status  int
+	Errors  []ValError
+}
+
+// String implements the stringer interface for ResponseViolation
+func (v *ResponseViolation) String() string {
+	msgs := make([]string, len(v.Errors))
+	for i, e := range v.Errors {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%s %s: %d response: %s", v.Request.Method, v.Request.URL.Path, v.Status, strings.Join(msgs, "; "))
+}
+
+// ResponseMiddleware wraps handlers so the JSON bodies of the successful
+// responses they write are validated against schema, catching handlers
+// drifting from the contract clients expect. Responses with a status
+// outside 2xx, without a body or with a Content-Type other than JSON are
+// left alone
+func ResponseMiddleware(schema *RootSchema, opts ResponseOptions) func(http.Handler) http.Handler {
+	opts = opts.withDefaults()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveValidatedResponse(w, r, next, schema, opts)
+		})
+	}
+}
+
+func (opts ResponseOptions) withDefaults() ResponseOptions {
+	if opts.MaxBodyBytes == 0 {
+		opts.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if opts.OnViolation == nil {
+		opts.OnViolation = func(v *ResponseViolation) {
+			log.Printf("jsonschema: invalid response to %s", v)
+		}
+	}
+	return opts
+}
+
+// serveValidatedResponse has next respond to r, validating the response
+// against schema
+func serveValidatedResponse(w http.ResponseWriter, r *http.Request, next http.Handler, schema *RootSchema, opts ResponseOptions) {
+	rec := &responseRecorder{ResponseWriter: w, hold: opts.Enforce, limit: opts.MaxBodyBytes, body: getBuffer()}
+	defer putBuffer(rec.body)
+	next.ServeHTTP(rec, r)
+
+	errs := rec.validate(schema)
+	if len(errs) > 0 {
+		opts.OnViolation(&ResponseViolation{Request: r, Status: rec.status, Errors: errs})
+	}
+	if !opts.Enforce {
+		return
+	}
+	if len(errs) > 0 {
+		w.Header().Del("Content-Length")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	rec.release()
+}
+
+// RouteSchemas are the schemas the requests and responses of a route are
+// validated against. Nil schemas skip their part
+type RouteSchemas struct {
+	Body     *RootSchema
+	Query    *RootSchema
+	Headers  *RootSchema
+	Response *RootSchema
+}
+
+// SchemaSelector picks the schemas of the route r is for, nil if r isn't
+// validated. Selectors running after routing can key schemas on the route
+// pattern matched, such as http.Request.Pattern for http.ServeMux or the
+// pattern in chi's route context
+type SchemaSelector func(r *http.Request) *RouteSchemas
+
+// RouteMiddleware wraps handlers so requests and responses are validated as
+// Middleware and ResponseMiddleware do, against schemas picked per request
+// by sel. The Query and Headers schemas of reqOpts are ignored in favor of
+// the route's. The func(http.Handler) http.Handler it returns plugs into
+// the middleware stacks of most routers, directly for chi and through the
+// adapters echo and gin provide for net/http middleware
+func RouteMiddleware(sel SchemaSelector, reqOpts MiddlewareOptions, resOpts ResponseOptions) func(http.Handler) http.Handler {
+	reqOpts = reqOpts.withDefaults()
+	resOpts = resOpts.withDefaults()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := sel(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if res := validateRequest(w, r, route.Body, route.Query, route.Headers, reqOpts.MaxBodyBytes); len(res.Errors) > 0 {
+				reqOpts.ErrorHandler(w, r, res)
+				return
+			}
+			if route.Response == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			serveValidatedResponse(w, r, next, route.Response, resOpts)
+		})
+	}
+}
+
+// responseRecorder keeps a copy of a response as it's written, passing it
+// on unless hold is set
+type responseRecorder struct {
+	http.ResponseWriter
+	hold     bool
+	limit    int64
+	status   int
+	body     *bytes.Buffer
+	overflow bool
+}
+
+// WriteHeader implements the http.ResponseWriter interface
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status != 0 {
+		return
+	}
+	rec.status = status
+	if !rec.hold {
+		rec.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write implements the http.ResponseWriter interface
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if !rec.overflow && int64(rec.body.Len()+len(p)) > rec.limit {
+		rec.overflow = true
+	}
+	if rec.hold || !rec.overflow {
+		rec.body.Write(p)
+	}
+	if rec.hold {
+		return len(p), nil
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+// validate validates the recorded response against schema if it should be
+func (rec *responseRecorder) validate(schema *RootSchema) []ValError {
+	if rec.status < 200 || rec.status > 299 || rec.body.Len() == 0 {
+		return nil
+	}
+	ct := rec.Header().Get("Content-Type")
+	if ct != "" && !strings.Contains(ct, "json") {
+		return nil
+	}
+	if rec.overflow {
+		return []ValError{{Message: fmt.Sprintf("response exceeds %d bytes and can't be validated", rec.limit)}}
+	}
+	errs, err := schema.ValidateBytes(rec.body.Bytes())
+	if err != nil {
+		return []ValError{{Message: err.Error()}}
+	}
+	return errs
+}
+
+// release writes a held response
+func (rec *responseRecorder) release() {
+	if rec.status == 0 {
+		// the handler wrote nothing
+		return
+	}
+	rec.ResponseWriter.WriteHeader(rec.status)
+	rec.ResponseWriter.Write(rec.body.Bytes())
+}
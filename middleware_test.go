@@ -0,0 +1,222 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	body := Must(`{
+		"type": "object",
+		"properties": {"name": {"type": "string"}, "age": {"type": "integer"}},
+		"required": ["name"]
+	}`)
+	query := Must(`{
+		"properties": {
+			"limit": {"type": "integer", "maximum": 100},
+			"tag": {"type": "array", "items": {"type": "string"}},
+			"verbose": {"type": "boolean"}
+		},
+		"additionalProperties": false
+	}`)
+	headers := Must(`{"properties": {"X-Request-Id": {"type": "string", "minLength": 4}}, "required": ["X-Request-Id"]}`)
+
+	var received string
+	handler := Middleware(body, MiddlewareOptions{Query: query, Headers: headers, MaxBodyBytes: 64})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			received = string(data)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	cases := []struct {
+		description string
+		target      string
+		requestID   string
+		body        string
+		status      int
+		errors      []RequestError
+	}{
+		{"valid", "/?limit=10&tag=a&tag=b&verbose=true", "abcd", `{"name": "Ann"}`, http.StatusNoContent, nil},
+		{"single array parameter", "/?tag=a", "abcd", `{"name": "Ann"}`, http.StatusNoContent, nil},
+		{"invalid body", "/", "abcd", `{"age": "old"}`, http.StatusBadRequest, []RequestError{
+			{In: "body", InstanceLocation: "", Message: `"name" value is required`},
+			{In: "body", InstanceLocation: "/age", Message: "type should be integer"},
+		}},
+		{"invalid query", "/?limit=1000&other=1", "abcd", `{"name": "Ann"}`, http.StatusBadRequest, []RequestError{
+			{In: "query", InstanceLocation: "/limit", Message: "must be less than or equal to 100.000000"},
+			{In: "query", InstanceLocation: "/other", Message: `additional property "other" is not allowed, allowed: "limit", "tag", "verbose"`},
+		}},
+		{"unconvertible query", "/?limit=ten", "abcd", `{"name": "Ann"}`, http.StatusBadRequest, []RequestError{
+			{In: "query", InstanceLocation: "/limit", Message: "type should be integer"},
+		}},
+		{"invalid header", "/", "ab", `{"name": "Ann"}`, http.StatusBadRequest, []RequestError{
+			{In: "header", InstanceLocation: "/X-Request-Id", Message: "min length of 4 characters required: ab"},
+		}},
+		{"empty body", "/", "abcd", "", http.StatusBadRequest, []RequestError{
+			{In: "body", Message: "request body is required"},
+		}},
+		{"body too large", "/", "abcd", `{"name": "` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, []RequestError{
+			{In: "body", Message: "http: request body too large"},
+		}},
+	}
+
+	for _, c := range cases {
+		received = ""
+		req := httptest.NewRequest("POST", c.target, strings.NewReader(c.body))
+		if c.requestID != "" {
+			req.Header.Set("X-Request-Id", c.requestID)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != c.status {
+			t.Errorf("%s: expected status %d, got %d: %s", c.description, c.status, rec.Code, rec.Body.String())
+			continue
+		}
+		if c.errors == nil {
+			if received != c.body {
+				t.Errorf("%s: expected the handler to read the body %q, got %q", c.description, c.body, received)
+			}
+			continue
+		}
+		if received != "" {
+			t.Errorf("%s: handler called for an invalid request", c.description)
+		}
+		res := &RequestErrors{}
+		if err := json.Unmarshal(rec.Body.Bytes(), res); err != nil {
+			t.Errorf("%s: %s", c.description, err)
+			continue
+		}
+		if res.Valid || !reflect.DeepEqual(res.Errors, c.errors) {
+			t.Errorf("%s: expected errors %+v, got valid %t, %+v", c.description, c.errors, res.Valid, res.Errors)
+		}
+	}
+}
+
+func TestMiddlewareErrorHandler(t *testing.T) {
+	var got *RequestErrors
+	handler := Middleware(nil, MiddlewareOptions{
+		Query: Must(`{"required": ["q"]}`),
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, errs *RequestErrors) {
+			got = errs
+			w.WriteHeader(http.StatusTeapot)
+		},
+	})(http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("expected the error handler's status, got %d", rec.Code)
+	}
+	if got == nil || got.Status != http.StatusBadRequest || len(got.Errors) != 1 || got.Errors[0].In != "query" {
+		t.Errorf("unexpected errors: %+v", got)
+	}
+}
+
+func TestResponseMiddleware(t *testing.T) {
+	schema := Must(`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`)
+	cases := []struct {
+		description string
+		enforce     bool
+		contentType string
+		status      int
+		body        string
+		wantStatus  int
+		wantBody    string
+		violation   string
+	}{
+		{"valid", false, "application/json", 200, `{"id": 1}`, 200, `{"id": 1}`, ""},
+		{"shadow", false, "application/json", 200, `{"id": "1"}`, 200, `{"id": "1"}`, "GET /items: 200 response: /id: \"1\" type should be integer"},
+		{"invalid JSON", false, "", 201, `{"id":`, 201, `{"id":`, "GET /items: 201 response: error parsing JSON bytes: unexpected end of JSON input"},
+		{"error status", false, "application/json", 404, `{"error": "not found"}`, 404, `{"error": "not found"}`, ""},
+		{"not JSON", true, "text/plain", 200, "hello", 200, "hello", ""},
+		{"too large", false, "application/json", 200, `{"id": 1, "padding": "` + strings.Repeat("a", 64) + `"}`, 200, `{"id": 1, "padding": "` + strings.Repeat("a", 64) + `"}`, "GET /items: 200 response: response exceeds 64 bytes and can't be validated"},
+		{"enforce valid", true, "application/json", 200, `{"id": 1}`, 200, `{"id": 1}`, ""},
+		{"enforce invalid", true, "application/json", 200, `{}`, 500, "Internal Server Error\n", `GET /items: 200 response: /: {} "id" value is required`},
+	}
+
+	for _, c := range cases {
+		var violation string
+		handler := ResponseMiddleware(schema, ResponseOptions{
+			Enforce:      c.enforce,
+			MaxBodyBytes: 64,
+			OnViolation:  func(v *ResponseViolation) { violation = v.String() },
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.contentType != "" {
+				w.Header().Set("Content-Type", c.contentType)
+			}
+			w.WriteHeader(c.status)
+			// write in pieces, as handlers streaming a response do
+			for i := 0; i < len(c.body); i += 8 {
+				end := i + 8
+				if end > len(c.body) {
+					end = len(c.body)
+				}
+				w.Write([]byte(c.body[i:end]))
+			}
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/items", nil))
+		if rec.Code != c.wantStatus || rec.Body.String() != c.wantBody {
+			t.Errorf("%s: expected %d %q, got %d %q", c.description, c.wantStatus, c.wantBody, rec.Code, rec.Body.String())
+		}
+		if violation != c.violation {
+			t.Errorf("%s: expected violation %q, got %q", c.description, c.violation, violation)
+		}
+	}
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	routes := map[string]*RouteSchemas{
+		"POST /items": {
+			Body:     Must(`{"required": ["name"]}`),
+			Response: Must(`{"required": ["id"]}`),
+		},
+		"GET /items/{id}": {
+			Query: Must(`{"properties": {"fields": {"type": "string"}}, "additionalProperties": false}`),
+		},
+	}
+	var violations []string
+	mw := RouteMiddleware(func(r *http.Request) *RouteSchemas {
+		return routes[r.Pattern]
+	}, MiddlewareOptions{}, ResponseOptions{
+		OnViolation: func(v *ResponseViolation) { violations = append(violations, v.String()) },
+	})
+
+	mux := http.NewServeMux()
+	respond := func(body string) http.Handler {
+		return mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+	}
+	mux.Handle("POST /items", respond(`{"name": "x"}`))
+	mux.Handle("GET /items/{id}", respond(`{}`))
+	mux.Handle("GET /health", respond(`ok`))
+
+	cases := []struct {
+		method, target, body string
+		status               int
+		violations           int
+	}{
+		{"POST", "/items", `{"name": "x"}`, 200, 1},
+		{"POST", "/items", `{}`, 400, 0},
+		{"GET", "/items/1?fields=name", "", 200, 0},
+		{"GET", "/items/1?other=1", "", 400, 0},
+		{"GET", "/health", "", 200, 0},
+	}
+	for _, c := range cases {
+		violations = nil
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(c.method, c.target, strings.NewReader(c.body)))
+		if rec.Code != c.status || len(violations) != c.violations {
+			t.Errorf("%s %s: expected status %d and %d violations, got %d and %v", c.method, c.target, c.status, c.violations, rec.Code, violations)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// mongoBSONTypes maps JSON types to the BSON types MongoDB's "bsonType"
+// keyword matches them with
+var mongoBSONTypes = map[string][]string{
+	"string":  {"string"},
+	"number":  {"number"},
+	"integer": {"int", "long"},
+	"boolean": {"bool"},
+	"object":  {"object"},
+	"array":   {"array"},
+	"null":    {"null"},
+}
+
+// mongoJSONTypes maps BSON types to schemas describing their values as
+// applications usually represent them in JSON
+var mongoJSONTypes = map[string]map[string]interface{}{
+	"double":   {"type": "number"},
+	"decimal":  {"type": "number"},
+	"number":   {"type": "number"},
+	"int":      {"type": "integer"},
+	"long":     {"type": "integer"},
+	"string":   {"type": "string"},
+	"bool":     {"type": "boolean"},
+	"object":   {"type": "object"},
+	"array":    {"type": "array"},
+	"null":     {"type": "null"},
+	"objectId": {"type": "string", "pattern": "^[0-9a-fA-F]{24}$"},
+	"date":     {"type": "string", "format": "date-time"},
+}
+
+// mongoKeywords are the keywords MongoDB's dialect shares with JSON Schema
+var mongoKeywords = map[string]bool{
+	"title": true, "description": true, "enum": true,
+	"minimum": true, "maximum": true, "multipleOf": true,
+	"minLength": true, "maxLength": true, "pattern": true,
+	"items": true, "additionalItems": true, "minItems": true, "maxItems": true, "uniqueItems": true,
+	"properties": true, "patternProperties": true, "additionalProperties": true,
+	"required": true, "minProperties": true, "maxProperties": true, "dependencies": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true,
+}
+
+// mongoDropped are annotations MongoDB's dialect doesn't accept, which are
+// left out of converted schemas. "format" is among them, as it's an
+// annotation to JSON Schema
+var mongoDropped = map[string]bool{
+	"$schema": true, "$id": true, "id": true, "$comment": true,
+	"default": true, "examples": true, "format": true, "readOnly": true, "writeOnly": true,
+	"definitions": true, "$defs": true, "contentMediaType": true, "contentEncoding": true,
+}
+
+// ToMongoSchema converts rs to MongoDB's "$jsonSchema" dialect, for use as
+// a collection validator: {"$jsonSchema": <result>}. Types become BSON
+// types, "const" a single-valued "enum", numeric exclusive bounds boolean
+// flags on "minimum" and "maximum", and references are inlined since the
+// dialect has none. Annotations the dialect lacks are left out, while
+// assertions it lacks, such as "if" or "contains", and recursive
+// references are errors, as leaving them out would accept documents rs
+// rejects
+func ToMongoSchema(rs *RootSchema) (map[string]interface{}, error) {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	c := &mongoConverter{root: doc, active: map[string]bool{}}
+	out, err := c.toMongo(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FromMongoSchema converts a schema in MongoDB's "$jsonSchema" dialect, or
+// a validator document holding one, to a JSON schema. BSON types become
+// JSON types, with object IDs as 24 hexadecimal digits and dates as
+// date-time strings as applications usually represent them. BSON types
+// without a JSON representation, such as "binData", are errors
+func FromMongoSchema(doc map[string]interface{}) (*RootSchema, error) {
+	if inner, ok := doc["$jsonSchema"].(map[string]interface{}); ok {
+		doc = inner
+	}
+	// normalize values such as the int32s of BSON documents
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var norm interface{}
+	if err := json.Unmarshal(data, &norm); err != nil {
+		return nil, err
+	}
+	converted, err := fromMongo(norm, "")
+	if err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(converted); err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// mongoConverter converts decoded schemas to MongoDB's dialect. active
+// holds the references being inlined
+type mongoConverter struct {
+	root   interface{}
+	active map[string]bool
+}
+
+func (c *mongoConverter) toMongo(v interface{}, ptr string) (map[string]interface{}, error) {
+	switch t := v.(type) {
+	case bool:
+		if t {
+			return map[string]interface{}{}, nil
+		}
+		return map[string]interface{}{"not": map[string]interface{}{}}, nil
+	case map[string]interface{}:
+		if ref, ok := t["$ref"].(string); ok {
+			return c.inline(ref, ptr)
+		}
+		out := map[string]interface{}{}
+		for _, key := range sortedMapKeys(t) {
+			val := t[key]
+			kptr := ptr + "/" + EscapePointerToken(key)
+			switch {
+			case key == "type":
+				bsonTypes := []interface{}{}
+				for _, name := range typeNames(val) {
+					for _, bt := range mongoBSONTypes[name] {
+						if !containsValue(bsonTypes, bt) {
+							bsonTypes = append(bsonTypes, bt)
+						}
+					}
+				}
+				if len(bsonTypes) == 1 {
+					out["bsonType"] = bsonTypes[0]
+				} else {
+					out["bsonType"] = bsonTypes
+				}
+			case key == "const":
+				out["enum"] = []interface{}{val}
+			case key == "exclusiveMinimum" || key == "exclusiveMaximum":
+				bound := "minimum"
+				if key == "exclusiveMaximum" {
+					bound = "maximum"
+				}
+				n, ok := val.(float64)
+				if !ok {
+					return nil, fmt.Errorf("%s: expected a number", kptr)
+				}
+				// an inclusive bound that's as strict wins
+				if m, ok := t[bound].(float64); ok && ((bound == "minimum" && m > n) || (bound == "maximum" && m < n)) {
+					continue
+				}
+				out[bound] = n
+				out[key] = true
+			case key == "minimum" || key == "maximum":
+				if _, ok := out[key]; !ok {
+					out[key] = val
+				}
+			case mongoDropped[key]:
+			case !mongoKeywords[key]:
+				return nil, fmt.Errorf("%s: keyword %q has no MongoDB equivalent", kptr, key)
+			default:
+				conv, err := c.subschemas(key, val, kptr)
+				if err != nil {
+					return nil, err
+				}
+				out[key] = conv
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s: expected a schema", ptr)
+}
+
+// inline converts the schema ref points at in place of the reference
+func (c *mongoConverter) inline(ref, ptr string) (map[string]interface{}, error) {
+	if ref == "" || ref[0] != '#' {
+		return nil, fmt.Errorf("%s: can't inline reference %q to another document", ptr, ref)
+	}
+	if c.active[ref] {
+		return nil, fmt.Errorf("%s: can't inline recursive reference %q", ptr, ref)
+	}
+	tokens, err := ParsePointer(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid reference %q: %s", ptr, ref, err.Error())
+	}
+	target, err := tokens.Eval(c.root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: resolving reference %q: %s", ptr, ref, err.Error())
+	}
+	c.active[ref] = true
+	defer delete(c.active, ref)
+	return c.toMongo(target, ptr)
+}
+
+// subschemas converts the schemas within the value of key
+func (c *mongoConverter) subschemas(key string, val interface{}, ptr string) (interface{}, error) {
+	return mapSubschemas(key, val, ptr, func(sub interface{}, ptr string) (interface{}, error) {
+		return c.toMongo(sub, ptr)
+	})
+}
+
+// fromMongo converts a schema in MongoDB's dialect
+func fromMongo(v interface{}, ptr string) (interface{}, error) {
+	t, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a schema", ptr)
+	}
+	out := map[string]interface{}{}
+	extra := []interface{}{}
+	for _, key := range sortedMapKeys(t) {
+		val := t[key]
+		kptr := ptr + "/" + EscapePointerToken(key)
+		switch key {
+		case "bsonType":
+			frags := []map[string]interface{}{}
+			for _, name := range typeNames(val) {
+				frag, ok := mongoJSONTypes[name]
+				if !ok {
+					return nil, fmt.Errorf("%s: BSON type %q has no JSON equivalent", kptr, name)
+				}
+				frags = append(frags, frag)
+			}
+			types := []interface{}{}
+			plain := true
+			for _, frag := range frags {
+				plain = plain && len(frag) == 1
+				if !containsValue(types, frag["type"]) {
+					types = append(types, frag["type"])
+				}
+			}
+			switch {
+			case plain && len(types) == 1:
+				out["type"] = types[0]
+			case plain:
+				out["type"] = types
+			case len(frags) == 1 && !sharesKeys(t, frags[0]):
+				for k, v := range frags[0] {
+					out[k] = v
+				}
+			case len(frags) == 1:
+				extra = append(extra, frags[0])
+			default:
+				alts := make([]interface{}, len(frags))
+				for i, frag := range frags {
+					alts[i] = frag
+				}
+				extra = append(extra, map[string]interface{}{"anyOf": alts})
+			}
+		case "exclusiveMinimum", "exclusiveMaximum":
+			bound := "minimum"
+			if key == "exclusiveMaximum" {
+				bound = "maximum"
+			}
+			if b, _ := val.(bool); b {
+				if n, ok := t[bound]; ok {
+					out[key] = n
+				}
+			}
+		case "minimum", "maximum":
+			exclusive := "exclusiveMinimum"
+			if key == "maximum" {
+				exclusive = "exclusiveMaximum"
+			}
+			if b, _ := t[exclusive].(bool); !b {
+				out[key] = val
+			}
+		default:
+			conv, err := mapSubschemas(key, val, kptr, fromMongo)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = conv
+		}
+	}
+	if len(extra) > 0 {
+		if allOf, ok := out["allOf"].([]interface{}); ok {
+			extra = append(allOf, extra...)
+		}
+		out["allOf"] = extra
+	}
+	return out, nil
+}
+
+// mapSubschemas applies fn to the schemas within the value of keyword key,
+// which is found at ptr, giving the value with the results in their place.
+// Values of other keywords are given back as they are
+func mapSubschemas(key string, val interface{}, ptr string, fn func(sub interface{}, ptr string) (interface{}, error)) (interface{}, error) {
+	switch key {
+	case "items", "allOf", "anyOf", "oneOf":
+		if arr, ok := val.([]interface{}); ok {
+			out := make([]interface{}, len(arr))
+			for i, sub := range arr {
+				conv, err := fn(sub, ptr+"/"+strconv.Itoa(i))
+				if err != nil {
+					return nil, err
+				}
+				out[i] = conv
+			}
+			return out, nil
+		}
+		if key != "items" {
+			return val, nil
+		}
+		return fn(val, ptr)
+	case "additionalItems", "additionalProperties", "not", "contains", "propertyNames", "if", "then", "else":
+		if _, ok := val.(bool); ok && key != "not" {
+			return val, nil
+		}
+		return fn(val, ptr)
+	case "properties", "patternProperties", "dependencies", "definitions", "$defs":
+		named, ok := val.(map[string]interface{})
+		if !ok {
+			return val, nil
+		}
+		out := make(map[string]interface{}, len(named))
+		for _, name := range sortedMapKeys(named) {
+			sub := named[name]
+			if _, isList := sub.([]interface{}); isList && key == "dependencies" {
+				// property dependencies aren't schemas
+				out[name] = sub
+				continue
+			}
+			conv, err := fn(sub, ptr+"/"+EscapePointerToken(name))
+			if err != nil {
+				return nil, err
+			}
+			out[name] = conv
+		}
+		return out, nil
+	}
+	return val, nil
+}
+
+// sharesKeys reports whether a and b have a key in common
+func sharesKeys(a, b map[string]interface{}) bool {
+	for key := range b {
+		if _, ok := a[key]; ok {
+			return true
+		}
+	}
+	return false
+}
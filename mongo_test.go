@@ -0,0 +1,110 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToMongoSchema(t *testing.T) {
+	rs := Must(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "person",
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "format": "email", "default": "x"},
+			"age": {"type": ["integer", "null"], "exclusiveMinimum": 0, "maximum": 150, "exclusiveMaximum": 200},
+			"kind": {"const": "person"},
+			"home": {"$ref": "#/definitions/address"},
+			"tags": {"items": {"type": "string"}, "additionalItems": false}
+		},
+		"additionalProperties": false,
+		"definitions": {"address": {"type": "object", "properties": {"city": {"type": "string"}}}}
+	}`)
+	got, err := ToMongoSchema(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{
+		"title": "person",
+		"bsonType": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"bsonType": "string"},
+			"age": {"bsonType": ["int", "long", "null"], "minimum": 0, "exclusiveMinimum": true, "maximum": 150},
+			"kind": {"enum": ["person"]},
+			"home": {"bsonType": "object", "properties": {"city": {"bsonType": "string"}}},
+			"tags": {"items": {"bsonType": "string"}, "additionalItems": false}
+		},
+		"additionalProperties": false
+	}`
+	assertJSONEqual(t, "ToMongoSchema", expect, got)
+
+	errCases := []struct {
+		schema, err string
+	}{
+		{`{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`, `/if: keyword "if" has no MongoDB equivalent`},
+		{`{"properties": {"child": {"$ref": "#"}}}`, `/properties/child/properties/child: can't inline recursive reference "#"`},
+		{`{"items": {"$ref": "other.json"}}`, `/items: can't inline reference "other.json" to another document`},
+	}
+	for _, c := range errCases {
+		if _, err := ToMongoSchema(Must(c.schema)); err == nil || err.Error() != c.err {
+			t.Errorf("%s: expected error %q, got %v", c.schema, c.err, err)
+		}
+	}
+}
+
+func TestFromMongoSchema(t *testing.T) {
+	validator := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(`{"$jsonSchema": {
+		"bsonType": "object",
+		"required": ["_id"],
+		"properties": {
+			"_id": {"bsonType": "objectId"},
+			"n": {"bsonType": ["int", "double"], "minimum": 0, "exclusiveMinimum": true, "maximum": 10},
+			"at": {"bsonType": ["date", "null"]},
+			"code": {"bsonType": "objectId", "pattern": "^0"}
+		}
+	}}`), &validator); err != nil {
+		t.Fatal(err)
+	}
+	rs, err := FromMongoSchema(validator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{
+		"type": "object",
+		"required": ["_id"],
+		"properties": {
+			"_id": {"type": "string", "pattern": "^[0-9a-fA-F]{24}$"},
+			"n": {"type": ["integer", "number"], "exclusiveMinimum": 0, "maximum": 10},
+			"at": {"allOf": [{"anyOf": [{"type": "string", "format": "date-time"}, {"type": "null"}]}]},
+			"code": {"pattern": "^0", "allOf": [{"type": "string", "pattern": "^[0-9a-fA-F]{24}$"}]}
+		}
+	}`
+	assertJSONEqual(t, "FromMongoSchema", expect, rs)
+
+	if _, err := FromMongoSchema(map[string]interface{}{"bsonType": "binData"}); err == nil || !strings.Contains(err.Error(), `"binData"`) {
+		t.Errorf("expected an error for a BSON type without a JSON equivalent, got %v", err)
+	}
+}
+
+func assertJSONEqual(t *testing.T, name, expect string, got interface{}) {
+	t.Helper()
+	var e, g interface{}
+	if err := json.Unmarshal([]byte(expect), &e); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e, g) {
+		t.Errorf("%s: expected %s, got %s", name, expect, data)
+	}
+}
@@ -0,0 +1,293 @@
+package jsonschema
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidateMsgPack performs schema validation against a MessagePack-encoded
+// instance, decoded by DecodeMsgPack with the decoding options of rs
+func (rs *RootSchema) ValidateMsgPack(data []byte) ([]ValError, error) {
+	return rs.ValidateEncoded(data, rs.options().Decoding.DecodeMsgPack)
+}
+
+// DecodeMsgPack decodes a single MessagePack object into the values
+// validation works with. Integers and floats become numbers, binary data
+// becomes base64 strings, as encoding/json writes byte slices, and
+// timestamps become RFC 3339 date-time strings. Integer map keys become
+// decimal strings, as with DecodeCBOR. Maps with other keys or duplicate
+// keys, strings that aren't UTF-8, infinities, NaN and extension types
+// other than timestamps are errors. It's an InstanceDecoder
+func DecodeMsgPack(data []byte) (interface{}, error) {
+	return DecodeOptions{}.DecodeMsgPack(data)
+}
+
+// DecodeMsgPack decodes a single MessagePack object as the DecodeMsgPack
+// function does, with the options o
+func (o DecodeOptions) DecodeMsgPack(data []byte) (interface{}, error) {
+	d := &msgpackDecoder{data: data, keys: o.keyInterner(), opts: o}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, d.errorf("unexpected data after the top-level object")
+	}
+	return v, nil
+}
+
+// msgpackMaxDepth bounds the nesting of MessagePack arrays and maps
+const msgpackMaxDepth = 1000
+
+// msgpackTimestamp is the extension type of timestamps
+const msgpackTimestamp = -1
+
+// msgpackDecoder reads MessagePack objects from data
+type msgpackDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+	keys  stringInterner
+	opts  DecodeOptions
+}
+
+func (d *msgpackDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("msgpack: offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+// next reads n bytes
+func (d *msgpackDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, d.errorf("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// int reads a big-endian two's complement integer of size bytes
+func (d *msgpackDecoder) int(size int) (int64, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	shift := uint(64 - 8*size)
+	return int64(n<<shift) >> shift, nil
+}
+
+// value reads an object
+func (d *msgpackDecoder) value() (interface{}, error) {
+	if d.depth++; d.depth > msgpackMaxDepth {
+		return nil, d.errorf("objects nested too deeply")
+	}
+	defer func() { d.depth-- }()
+
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	switch c := b[0]; {
+	case c <= 0x7f:
+		return d.opts.decodedInt(int64(c)), nil
+	case c <= 0x8f:
+		return d.mapValue(uint64(c & 0x0f))
+	case c <= 0x9f:
+		return d.array(uint64(c & 0x0f))
+	case c <= 0xbf:
+		return d.str(uint64(c & 0x1f))
+	case c >= 0xe0:
+		return d.opts.decodedInt(int64(int8(c))), nil
+	}
+
+	switch c := b[0]; c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		bin, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(bin), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		n, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return d.float(float64(math.Float32frombits(uint32(n))))
+	case 0xcb:
+		n, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return d.float(math.Float64frombits(n))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		return d.opts.decodedUint(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n, err := d.int(1 << (c - 0xd0))
+		return d.opts.decodedInt(n), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapValue(n)
+	}
+	return nil, d.errorf("invalid format byte 0x%x", b[0])
+}
+
+// str reads a UTF-8 string of n bytes
+func (d *msgpackDecoder) str(n uint64) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, d.errorf("string isn't valid UTF-8")
+	}
+	return string(b), nil
+}
+
+// array reads an array of n objects
+func (d *msgpackDecoder) array(n uint64) (interface{}, error) {
+	arr := []interface{}{}
+	for i := uint64(0); i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+// mapValue reads a map of n entries
+func (d *msgpackDecoder) mapValue(n uint64) (interface{}, error) {
+	obj := map[string]interface{}{}
+	for i := uint64(0); i < n; i++ {
+		keyPos := d.pos
+		key, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := obj[key]; ok {
+			d.pos = keyPos
+			return nil, d.errorf("duplicate map key %q", key)
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+	}
+	return obj, nil
+}
+
+// key reads a map key, which must be a string or an integer
+func (d *msgpackDecoder) key() (string, error) {
+	if d.pos >= len(d.data) {
+		return "", d.errorf("unexpected end of data")
+	}
+	switch c := d.data[d.pos]; {
+	case c <= 0x7f || c >= 0xe0:
+		d.pos++
+		return strconv.Itoa(int(int8(c))), nil
+	case c >= 0xcc && c <= 0xcf:
+		d.pos++
+		n, err := d.uint(1 << (c - 0xcc))
+		return strconv.FormatUint(n, 10), err
+	case c >= 0xd0 && c <= 0xd3:
+		d.pos++
+		n, err := d.int(1 << (c - 0xd0))
+		return strconv.FormatInt(n, 10), err
+	case (c >= 0xa0 && c <= 0xbf) || (c >= 0xd9 && c <= 0xdb):
+		v, err := d.value()
+		if err != nil {
+			return "", err
+		}
+		return d.keys.string(v.(string)), nil
+	}
+	return "", d.errorf("map keys must be strings or integers")
+}
+
+// ext reads an extension of n bytes of data, which must be a timestamp
+func (d *msgpackDecoder) ext(n uint64) (interface{}, error) {
+	typ, err := d.int(1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != msgpackTimestamp {
+		return nil, d.errorf("unsupported extension type %d", typ)
+	}
+	var t time.Time
+	switch len(b) {
+	case 4:
+		t = time.Unix(int64(binary.BigEndian.Uint32(b)), 0)
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		t = time.Unix(int64(v&(1<<34-1)), int64(v>>34))
+	case 12:
+		t = time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b)))
+	default:
+		return nil, d.errorf("invalid timestamp length %d", len(b))
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+// float gives the number f, which must be finite
+func (d *msgpackDecoder) float(f float64) (interface{}, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, d.errorf("%v can't be represented in JSON", f)
+	}
+	return d.opts.decodedFloat(f), nil
+}
@@ -0,0 +1,126 @@
+package jsonschema
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMsgPack(t *testing.T) {
+	cases := []struct {
+		hex, json string
+	}{
+		{"00", `0`},
+		{"7f", `127`},
+		{"ff", `-1`},
+		{"e0", `-32`},
+		{"cc80", `128`},
+		{"cd0100", `256`},
+		{"ce00010000", `65536`},
+		{"cf0000000100000000", `4294967296`},
+		{"d080", `-128`},
+		{"d1ff00", `-256`},
+		{"d2ffff0000", `-65536`},
+		{"d3ffffffff00000000", `-4294967296`},
+		{"ca3fc00000", `1.5`},
+		{"cb3ff199999999999a", `1.1`},
+		{"c0", `null`},
+		{"c2", `false`},
+		{"c3", `true`},
+		{"a3616263", `"abc"`},
+		{"d903616263", `"abc"`},
+		{"da0003616263", `"abc"`},
+		{"c40401020304", `"AQIDBA=="`},
+		{"93010203", `[1,2,3]`},
+		{"dc0002c0c3", `[null,true]`},
+		{"82a16101a1629202a162", `{"a":1,"b":[2,"b"]}`},
+		{"de0001a16101", `{"a":1}`},
+		{"8201a16fd0ffa26d6e", `{"-1":"mn","1":"o"}`},
+		{"d6ff5f5e1000", `"2020-09-13T12:26:40Z"`},
+		{"d7ff000000045f5e1000", `"2020-09-13T12:26:40.000000001Z"`},
+		{"c70cff000003e8000000005f5e1000", `"2020-09-13T12:26:40.000001Z"`},
+	}
+	for _, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		v, err := DecodeMsgPack(data)
+		if err != nil {
+			t.Errorf("%s: %s", c.hex, err.Error())
+			continue
+		}
+		got, _ := json.Marshal(v)
+		if string(got) != c.json {
+			t.Errorf("%s: expected %s, got %s", c.hex, c.json, got)
+		}
+	}
+}
+
+func TestDecodeMsgPackErrors(t *testing.T) {
+	cases := []struct {
+		hex, err string
+	}{
+		{"", "msgpack: offset 0: unexpected end of data"},
+		{"cd01", "msgpack: offset 1: unexpected end of data"},
+		{"0000", "msgpack: offset 1: unexpected data after the top-level object"},
+		{"c1", "msgpack: offset 1: invalid format byte 0xc1"},
+		{"82a16101a16102", "msgpack: offset 4: duplicate map key \"a\""},
+		{"81c301", "msgpack: offset 1: map keys must be strings or integers"},
+		{"cb7ff0000000000000", "msgpack: offset 9: +Inf can't be represented in JSON"},
+		{"a2c328", "msgpack: offset 3: string isn't valid UTF-8"},
+		{"d40101", "msgpack: offset 3: unsupported extension type 1"},
+		{"d5ff0000", "msgpack: offset 4: invalid timestamp length 2"},
+		{"ddffffffff", "msgpack: offset 5: unexpected end of data"},
+	}
+	for _, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		_, err := DecodeMsgPack(data)
+		if err == nil {
+			t.Errorf("%s: expected error %q", c.hex, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%s: expected error %q, got %q", c.hex, c.err, err.Error())
+		}
+	}
+
+	nested, _ := hex.DecodeString(strings.Repeat("91", msgpackMaxDepth+1) + "00")
+	if _, err := DecodeMsgPack(nested); err == nil {
+		t.Error("expected an error for deeply nested arrays")
+	}
+}
+
+func TestValidateMsgPack(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["method", "params"],
+		"properties": {
+			"method": {"enum": ["add", "sub"]},
+			"params": {"type": "array", "items": {"type": "integer"}, "maxItems": 2},
+			"sent": {"type": "string", "format": "date-time"}
+		}
+	}`)
+	cases := []struct {
+		hex  string
+		errs int
+	}{
+		// {"method": "add", "params": [1, 2], "sent": timestamp}
+		{"83a66d6574686f64a3616464a6706172616d73920102a473656e74d6ff5f5e1000", 0},
+		// {"method": "mul", "params": [1, 2.5, 3]}
+		{"82a66d6574686f64a36d756ca6706172616d739301ca4020000003", 3},
+	}
+	for i, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		errs, err := rs.ValidateMsgPack(data)
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != c.errs {
+			t.Errorf("case %d: expected %d errors, got %v", i, c.errs, errs)
+		}
+	}
+
+	if _, err := rs.ValidateMsgPack([]byte{0x81}); err == nil {
+		t.Error("expected an error for truncated MessagePack")
+	}
+}
@@ -0,0 +1,187 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateIPv4 checks str is an IPv4 address in the "dotted-quad" syntax
+// of RFC 2673, section 3.2, which the "ipv4" format requires: four decimal
+// numbers up to 255, without leading zeros, which some parsers read as
+// octal
+// https://tools.ietf.org/html/rfc2673#section-3.2
+func ValidateIPv4(str string) error {
+	return IPFormatOptions{}.checkIPv4(str)
+}
+
+// IPFormatOptions configure the ipv4 and ipv6 formats, which follow their
+// RFCs strictly by default
+type IPFormatOptions struct {
+	// LeadingZeros accepts IPv4 parts with leading zeros, reading them as
+	// decimal numbers
+	LeadingZeros bool
+	// Zones accepts IPv6 addresses followed by a zone ID, such as
+	// "fe80::1%eth0", as RFC 6874 writes them
+	Zones bool
+}
+
+// checkIPv4 checks str is an IPv4 address as ValidateIPv4 does, with the
+// relaxations o sets
+func (o IPFormatOptions) checkIPv4(str string) error {
+	parts := strings.Split(str, ".")
+	if len(parts) != 4 {
+		return fmt.Errorf("IPv4 addresses have 4 parts, not %d", len(parts))
+	}
+	for _, part := range parts {
+		if part == "" || len(part) > 3 {
+			return fmt.Errorf("invalid part %q", part)
+		}
+		n := 0
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return fmt.Errorf("invalid part %q", part)
+			}
+			n = n*10 + int(part[i]-'0')
+		}
+		if len(part) > 1 && part[0] == '0' && !o.LeadingZeros {
+			return fmt.Errorf("part %q has a leading zero", part)
+		}
+		if n > 255 {
+			return fmt.Errorf("part %q is greater than 255", part)
+		}
+	}
+	return nil
+}
+
+// ValidateIPv6 checks str is an IPv6 address as RFC 4291, section 2.2
+// writes it, which the "ipv6" format requires: eight groups of up to four
+// hex digits, the longest run of zero groups of which may be written "::",
+// and the last two of which may be written as an IPv4 address. Zone IDs,
+// such as "%eth0", aren't part of addresses, so they're an error
+// https://tools.ietf.org/html/rfc4291#section-2.2
+func ValidateIPv6(str string) error {
+	return IPFormatOptions{}.checkIPv6(str)
+}
+
+// checkIPv6 checks str is an IPv6 address as ValidateIPv6 does, with the
+// relaxations o sets. Embedded IPv4 addresses are read strictly either way
+func (o IPFormatOptions) checkIPv6(str string) error {
+	if i := strings.IndexByte(str, '%'); i != -1 {
+		if !o.Zones {
+			return fmt.Errorf("zone ID %q isn't part of an IPv6 address", str[i:])
+		}
+		if i == len(str)-1 {
+			return fmt.Errorf("empty zone ID")
+		}
+		str = str[:i]
+	}
+	head, tail, compressed := str, "", false
+	if i := strings.Index(str, "::"); i != -1 {
+		head, tail, compressed = str[:i], str[i+2:], true
+		if strings.Contains(tail, "::") {
+			return fmt.Errorf(`"::" may only appear once`)
+		}
+	}
+
+	groups := 0
+	for n, part := range []string{head, tail} {
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, ":")
+		for i, field := range fields {
+			if strings.Contains(field, ".") {
+				if n == 0 && compressed || i != len(fields)-1 {
+					return fmt.Errorf("an IPv4 address may only end an IPv6 address")
+				}
+				if err := ValidateIPv4(field); err != nil {
+					return fmt.Errorf("invalid IPv4 part: %s", err.Error())
+				}
+				groups += 2
+				continue
+			}
+			if err := validateIPv6Group(field); err != nil {
+				return err
+			}
+			groups++
+		}
+	}
+
+	switch {
+	case compressed && groups > 7:
+		return fmt.Errorf(`"::" must stand for at least one group`)
+	case !compressed && groups != 8:
+		return fmt.Errorf("IPv6 addresses have 8 groups, not %d", groups)
+	}
+	return nil
+}
+
+// validateIPv6Group checks a group of an IPv6 address is 1 to 4 hex digits
+func validateIPv6Group(group string) error {
+	if group == "" {
+		return fmt.Errorf("empty group")
+	}
+	if len(group) > 4 {
+		return fmt.Errorf("group %q has more than 4 hex digits", group)
+	}
+	for i := 0; i < len(group); i++ {
+		c := group[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return fmt.Errorf("invalid group %q", group)
+		}
+	}
+	return nil
+}
+
+// ValidateHostname checks str is a host name in the preferred name syntax
+// of RFC 1034, section 3.1, as relaxed by RFC 1123 to allow labels starting
+// with digits, which the "hostname" format requires: labels of letters,
+// digits and inner hyphens up to 63 characters long, making a name up to
+// 253 characters long. Labels with hyphens at their third and fourth
+// positions must be valid A-labels, the Punycode of RFC 5891
+// https://tools.ietf.org/html/rfc1034#section-3.1
+// https://tools.ietf.org/html/rfc1123#section-2.1
+// https://tools.ietf.org/html/rfc5891#section-4.4
+func ValidateHostname(str string) error {
+	return HostnameFormatOptions{}.check(str)
+}
+
+// HostnameFormatOptions configure the hostname format, which follows
+// RFC 1034 strictly by default
+type HostnameFormatOptions struct {
+	// TrailingDot accepts names ending in a dot, the fully qualified form
+	// DNS writes them in
+	TrailingDot bool
+	// Underscores accepts underscores in labels as letters, as the names
+	// of DNS service and TXT records use them, such as "_dmarc.example.com"
+	Underscores bool
+}
+
+// check checks str is a host name as ValidateHostname does, with the
+// relaxations o sets
+func (o HostnameFormatOptions) check(str string) error {
+	if o.TrailingDot && len(str) > 1 && strings.HasSuffix(str, ".") {
+		str = str[:len(str)-1]
+	}
+	if str == "" {
+		return fmt.Errorf("host names can't be empty")
+	}
+	if len(str) > 253 {
+		return fmt.Errorf("host names can't be longer than 253 characters")
+	}
+	if !isASCII(str) {
+		return fmt.Errorf("host names must be ASCII, as idn-hostname allows Unicode")
+	}
+	for _, label := range strings.Split(str, ".") {
+		if o.Underscores && strings.Contains(label, "_") {
+			if err := checkLDHLabel(strings.ReplaceAll(label, "_", "a")); err != nil {
+				return fmt.Errorf("invalid label %q", label)
+			}
+			continue
+		}
+		if _, err := idnLabelToASCII(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
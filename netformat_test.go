@@ -0,0 +1,102 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateIPv4(t *testing.T) {
+	cases := []struct {
+		addr, err string
+	}{
+		{"192.168.0.1", ""},
+		{"0.0.0.0", ""},
+		{"255.255.255.255", ""},
+		{"127.0.0.0.1", "IPv4 addresses have 4 parts, not 5"},
+		{"127.0", "IPv4 addresses have 4 parts, not 2"},
+		{"256.256.256.256", `part "256" is greater than 255`},
+		{"087.10.0.1", `part "087" has a leading zero`},
+		{"0x7f000001", "IPv4 addresses have 4 parts, not 1"},
+		{"1.2.3.", `invalid part ""`},
+		{"1.2.3.4 ", `invalid part "4 "`},
+		{"1.2.3.١", `invalid part "١"`},
+		{"::ffff:1.2.3.4", `invalid part "::ffff:1"`},
+	}
+	for _, c := range cases {
+		got := ""
+		if err := ValidateIPv4(c.addr); err != nil {
+			got = err.Error()
+		}
+		if got != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.addr, c.err, got)
+		}
+	}
+}
+
+func TestValidateIPv6(t *testing.T) {
+	cases := []struct {
+		addr, err string
+	}{
+		{"::1", ""},
+		{"::", ""},
+		{"1::", ""},
+		{"fe80::a:B:c", ""},
+		{"1:2:3:4:5:6:7:8", ""},
+		{"1:2:3:4:5:6:7::", ""},
+		{"::ffff:192.168.0.1", ""},
+		{"1:2:3:4:5:6:1.2.3.4", ""},
+		{"fe80::1%eth0", `zone ID "%eth0" isn't part of an IPv6 address`},
+		{"12345::", `group "12345" has more than 4 hex digits`},
+		{"::laptop", `group "laptop" has more than 4 hex digits`},
+		{"::lap", `invalid group "lap"`},
+		{"1:2:3:4:5:6:7:8:9", "IPv6 addresses have 8 groups, not 9"},
+		{"1:2:3:4:5:6:7", "IPv6 addresses have 8 groups, not 7"},
+		{"1:2:3:4:5:6:7:8::", `"::" must stand for at least one group`},
+		{"1::2::3", `"::" may only appear once`},
+		{":1:2:3:4:5:6:7", "empty group"},
+		{"1:::2", "empty group"},
+		{"1.2.3.4::1", "an IPv4 address may only end an IPv6 address"},
+		{"::1.2.3.4:1", "an IPv4 address may only end an IPv6 address"},
+		{"::ffff:1.2.3.04", `invalid IPv4 part: part "04" has a leading zero`},
+	}
+	for _, c := range cases {
+		got := ""
+		if err := ValidateIPv6(c.addr); err != nil {
+			got = err.Error()
+		}
+		if got != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.addr, c.err, got)
+		}
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	long := strings.Repeat("a", 63)
+	cases := []struct {
+		host, err string
+	}{
+		{"www.example.com", ""},
+		{"xn--4gbwdl.xn--wgbh1c", ""},
+		{"1password.com", ""},
+		{"localhost", ""},
+		{long + ".com", ""},
+		{"", "host names can't be empty"},
+		{long + "a.com", `label "` + long + `a" is longer than 63 characters`},
+		{long + "." + long + "." + long + "." + long + ".com", "host names can't be longer than 253 characters"},
+		{"-a-host-name-that-starts-with--", `label "-a-host-name-that-starts-with--" can't begin or end with a hyphen`},
+		{"not_a_valid_host_name", `label "not_a_valid_host_name" contains illegal character '_'`},
+		{"ab--cd.example", `label "ab--cd" can't have hyphens at its third and fourth positions`},
+		{"xn--x.example", `invalid A-label "xn--x": unexpected end of input`},
+		{"www..example", "labels can't be empty"},
+		{"bücher.example", "host names must be ASCII, as idn-hostname allows Unicode"},
+	}
+	for _, c := range cases {
+		got := ""
+		if err := ValidateHostname(c.host); err != nil {
+			got = err.Error()
+		}
+		if got != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.host, c.err, got)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package jsonschema
+
+import (
+	"strconv"
+)
+
+// ApplyTransforms gives a copy of doc with the "transform" keywords of the
+// schemas that apply to its strings applied, as validation sees them.
+// Schemas are found as SchemasAt finds them, so the transforms of every
+// branch of "anyOf", "oneOf" and "if" apply. doc is left as it is
+func (s *Schema) ApplyTransforms(doc interface{}) interface{} {
+	return applyTransforms(applicableSchemas(s, nil, map[*Schema]bool{}), cloneValue(doc))
+}
+
+// applyTransforms applies the transforms of schemas to val and those of
+// their children to its properties and elements, modifying val
+func applyTransforms(schemas []*Schema, val interface{}) interface{} {
+	if len(schemas) == 0 {
+		return val
+	}
+	switch t := val.(type) {
+	case string:
+		for _, sch := range schemas {
+			if tr, ok := sch.Validators["transform"].(*Transform); ok {
+				val = tr.apply(val)
+			}
+		}
+	case map[string]interface{}:
+		for key, v := range t {
+			t[key] = applyTransforms(nextSchemas(schemas, key), v)
+		}
+	case []interface{}:
+		for i, v := range t {
+			t[i] = applyTransforms(nextSchemas(schemas, strconv.Itoa(i)), v)
+		}
+	}
+	return val
+}
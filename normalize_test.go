@@ -0,0 +1,58 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	RegisterValidator("transform", NewTransform)
+	defer delete(DefaultValidators, "transform")
+
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "transform": ["trim", "toLowerCase"], "pattern": "^[a-z]+@[a-z]+\\.com$"},
+			"code": {"transform": ["trimEnd", "toUpperCase"], "enum": ["AB", " CD"]},
+			"tags": {"items": {"transform": ["trimStart"], "maxLength": 3}}
+		}
+	}`)
+
+	errs, err := rs.ValidateBytes([]byte(`{"email": "  Ada@Example.COM ", "code": " cd  ", "tags": ["  abc", "abcd"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].PropertyPath != "/tags/1" {
+		t.Errorf("expected a single error at /tags/1, got %v", errs)
+	}
+
+	var doc interface{}
+	json.Unmarshal([]byte(`{"email": "  Ada@Example.COM ", "code": " cd  ", "tags": ["  abc", 1], "other": " x "}`), &doc)
+	got := rs.ApplyTransforms(doc)
+	assertJSONEqual(t, "transformed", `{"email": "ada@example.com", "code": " CD", "tags": ["abc", 1], "other": " x "}`, got)
+	assertJSONEqual(t, "original", `{"email": "  Ada@Example.COM ", "code": " cd  ", "tags": ["  abc", 1], "other": " x "}`, doc)
+
+	data, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"transform":["trim","toLowerCase"]`) {
+		t.Errorf("expected transform to be encoded, got %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"transform": ["capitalize"]}`), &RootSchema{}); err == nil || !strings.Contains(err.Error(), `unknown transform "capitalize"`) {
+		t.Errorf("expected an unknown transform error, got %v", err)
+	}
+}
+
+func TestTransformOptIn(t *testing.T) {
+	rs := Must(`{"transform": ["trim"], "const": "a"}`)
+	errs, err := rs.ValidateBytes([]byte(`" a "`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected transform to be ignored unless registered, got %v", errs)
+	}
+}
@@ -0,0 +1,179 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// DecodeOptions configures how instances are decoded for validation.
+// Numeric keywords accept json.Number instances either way
+type DecodeOptions struct {
+	// UseNumber decodes numbers as json.Number, as json.Decoder.UseNumber
+	// does, rather than float64. Numbers keep their exact value, so
+	// integers beyond the precision of float64 are compared exactly
+	UseNumber bool
+	// InternKeys shares one string between the equal object keys of a
+	// document, so that large arrays of objects hold each of their keys
+	// once rather than once per object
+	InternKeys bool
+}
+
+// numberPrec is the precision of json.Numbers compared with keyword
+// values, exact for integers of up to 256 bits
+const numberPrec = 256
+
+// decodeJSON decodes the JSON document data as o sets
+func (o DecodeOptions) decodeJSON(data []byte) (interface{}, error) {
+	if o.InternKeys {
+		sc := &lazyScanner{data: data, keys: stringInterner{}, opts: o}
+		return sc.document(nil, true)
+	}
+	var doc interface{}
+	if !o.UseNumber {
+		err := json.Unmarshal(data, &doc)
+		return doc, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+	return doc, nil
+}
+
+// decodedInt gives the value of a decoded integer as o sets
+func (o DecodeOptions) decodedInt(i int64) interface{} {
+	if o.UseNumber {
+		return json.Number(strconv.FormatInt(i, 10))
+	}
+	return float64(i)
+}
+
+// decodedUint gives the value of a decoded unsigned integer as o sets
+func (o DecodeOptions) decodedUint(u uint64) interface{} {
+	if o.UseNumber {
+		return json.Number(strconv.FormatUint(u, 10))
+	}
+	return float64(u)
+}
+
+// decodedFloat gives the value of a decoded finite float as o sets
+func (o DecodeOptions) decodedFloat(f float64) interface{} {
+	if o.UseNumber {
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return f
+}
+
+// decodedNumberText gives the value of the number f parsed from text as o
+// sets, keeping the exact text when it's written as JSON
+func (o DecodeOptions) decodedNumberText(text string, f float64) interface{} {
+	if o.UseNumber && json.Valid([]byte(text)) {
+		return json.Number(text)
+	}
+	return o.decodedFloat(f)
+}
+
+// bigNumber gives the numeric instance data at numberPrec precision.
+// float64s are read as the shortest decimal giving them, as JSON writes
+// them, so that 1.1 is equal to json.Number("1.1")
+func bigNumber(data interface{}) (*big.Float, bool) {
+	switch n := data.(type) {
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, false
+		}
+		return bigNumber(json.Number(strconv.FormatFloat(n, 'g', -1, 64)))
+	case json.Number:
+		f, _, err := big.ParseFloat(string(n), 10, numberPrec, big.ToNearestEven)
+		return f, err == nil
+	}
+	return nil, false
+}
+
+// maxRatExp bounds the binary exponent of numbers ratNumber reads exactly,
+// as exact values of larger ones grow too big to work with
+const maxRatExp = 4096
+
+// ratNumber gives the exact value of the numeric instance data, read as
+// bigNumber reads it
+func ratNumber(data interface{}) (*big.Rat, bool) {
+	f, ok := bigNumber(data)
+	if !ok || f.MantExp(nil) > maxRatExp || f.MantExp(nil) < -maxRatExp {
+		return nil, false
+	}
+	if n, ok := data.(float64); ok {
+		data = json.Number(strconv.FormatFloat(n, 'g', -1, 64))
+	}
+	return new(big.Rat).SetString(string(data.(json.Number)))
+}
+
+// compareNumber compares the numeric instance data with a keyword value,
+// giving -1, 0 or 1 as data is less than, equal to or greater than limit
+func compareNumber(data interface{}, limit float64) (int, bool) {
+	if num, ok := data.(float64); ok {
+		switch {
+		case num < limit:
+			return -1, true
+		case num > limit:
+			return 1, true
+		}
+		return 0, true
+	}
+	n, ok := bigNumber(data)
+	if !ok {
+		return 0, false
+	}
+	lim, ok := bigNumber(limit)
+	if !ok {
+		return 0, false
+	}
+	return n.Cmp(lim), true
+}
+
+// valuesEqual reports whether the instances a and b are equal, comparing
+// numbers by value whether they're float64 or json.Number
+func valuesEqual(a, b interface{}) bool {
+	_, anum := a.(json.Number)
+	_, bnum := b.(json.Number)
+	if anum || bnum {
+		x, xok := bigNumber(a)
+		y, yok := bigNumber(b)
+		return xok && yok && x.Cmp(y) == 0
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, xv := range x {
+			yv, ok := y[key]
+			if !ok || !valuesEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !valuesEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,110 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNumberKeywords(t *testing.T) {
+	cases := []struct {
+		schema string
+		data   interface{}
+		valid  bool
+	}{
+		{`{"type": "integer"}`, json.Number("12345678901234567890"), true},
+		{`{"type": "integer"}`, json.Number("1.0"), true},
+		{`{"type": "integer"}`, json.Number("1.5"), false},
+		{`{"type": "number"}`, json.Number("1.5e3"), true},
+		{`{"type": "string"}`, json.Number("1"), false},
+		{`{"maximum": 9007199254740992}`, json.Number("9007199254740993"), false},
+		{`{"maximum": 9007199254740992}`, 9007199254740993.0, true},
+		{`{"minimum": 1.5}`, json.Number("1.5"), true},
+		{`{"exclusiveMinimum": 1.5}`, json.Number("1.5"), false},
+		{`{"exclusiveMaximum": 2}`, json.Number("1.999"), true},
+		{`{"multipleOf": 3}`, json.Number("12345678901234567890"), true},
+		{`{"multipleOf": 3}`, json.Number("12345678901234567891"), false},
+		{`{"multipleOf": 2.5}`, json.Number("7.5"), true},
+		{`{"minimum": 1.1}`, json.Number("1.1"), true},
+		{`{"minimum": 1.1}`, json.Number("1.0999999999999999999"), false},
+		{`{"maximum": 1.1}`, json.Number("1.1"), true},
+		{`{"maximum": 1.1}`, json.Number("1.1000000000000000001"), false},
+		{`{"exclusiveMaximum": 0.3}`, json.Number("0.3"), false},
+		{`{"exclusiveMinimum": 0.3}`, json.Number("0.30000000000000001"), true},
+		{`{"multipleOf": 0.0001}`, json.Number("0.0075"), true},
+		{`{"multipleOf": 0.0001}`, json.Number("0.00751"), false},
+		{`{"multipleOf": 0.1}`, json.Number("1e400"), true},
+		{`{"multipleOf": 0.01}`, json.Number("19.99"), true},
+		{`{"const": 0.1}`, json.Number("0.1"), true},
+		{`{"const": 1}`, json.Number("1.0"), true},
+		{`{"const": {"a": [1, 2]}}`, map[string]interface{}{"a": []interface{}{json.Number("1"), 2.0}}, true},
+		{`{"enum": [1, 2]}`, json.Number("3"), false},
+		{`{"uniqueItems": true}`, []interface{}{json.Number("1"), 1.0}, false},
+		{`{"uniqueItems": true}`, []interface{}{json.Number("1"), json.Number("2")}, true},
+	}
+	for i, c := range cases {
+		errs := []ValError{}
+		Must(c.schema).Validate("/", c.data, &errs)
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("case %d: %s with %v: expected valid %t, got errors %v", i, c.schema, c.data, c.valid, errs)
+		}
+	}
+}
+
+func TestDecodingUseNumber(t *testing.T) {
+	rs := Must(`{"properties": {"id": {"type": "integer", "maximum": 9007199254740992}}}`)
+	doc := []byte(`{"id": 9007199254740993}`)
+
+	errs, err := rs.ValidateBytes(doc)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected float64 decoding to lose the excess, got %v", errs)
+	}
+
+	opts := DecodeOptions{UseNumber: true}
+	rs.SetOptions(Options{Decoding: opts})
+	errs, err = rs.ValidateBytes(doc)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(errs) != 1 || errs[0].InvalidValue != json.Number("9007199254740993") {
+		t.Errorf("expected an exact maximum error, got %v", errs)
+	}
+	if _, err := rs.ValidateBytes([]byte(`{"id": 1} {}`)); err == nil {
+		t.Errorf("expected an error for data after the document")
+	}
+
+	decoders := []struct {
+		name   string
+		decode InstanceDecoder
+		data   []byte
+	}{
+		{"cbor", opts.DecodeCBOR, []byte{0x1b, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		{"msgpack", opts.DecodeMsgPack, []byte{0xcf, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		{"toml", func(data []byte) (interface{}, error) {
+			doc, err := opts.DecodeTOML(data)
+			if err != nil {
+				return nil, err
+			}
+			return doc.(map[string]interface{})["n"], nil
+		}, []byte("n = 9007199254740993")},
+	}
+	for _, d := range decoders {
+		v, err := d.decode(d.data)
+		if err != nil {
+			t.Errorf("%s: %s", d.name, err.Error())
+			continue
+		}
+		if v != json.Number("9007199254740993") {
+			t.Errorf("%s: expected an exact json.Number, got %#v", d.name, v)
+		}
+	}
+
+	envSchema := Must(`{"properties": {"N": {"type": "integer"}}}`)
+	envSchema.SetOptions(Options{Decoding: opts})
+	env, errs := envSchema.ValidateEnv("", []string{"N=9007199254740993"})
+	if len(errs) != 0 || env["N"] != json.Number("9007199254740993") {
+		t.Errorf("expected an exact environment variable, got %v %v", env, errs)
+	}
+}
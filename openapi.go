@@ -0,0 +1,297 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// JSONSchema202012 is the "$schema" URI of the 2020-12 dialect of JSON Schema
+const JSONSchema202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// FromOpenAPI30Schema converts a schema object in OpenAPI 3.0's restricted
+// dialect to a JSON schema in the 2020-12 dialect, which validates
+// instances as OpenAPI 3.0 does. "nullable: true" adds "null" to the type
+// and enum it accompanies, "example" becomes a single-valued "examples",
+// boolean exclusive bounds become numeric ones, and keywords beside a
+// "$ref" are left out, as OpenAPI 3.0 ignores them. Vendor extensions and
+// OpenAPI annotations such as "discriminator" are kept. Types other than a
+// single name, such as "null" or a list, aren't OpenAPI 3.0 and are errors.
+// References are kept as they are, so references to other parts of an
+// OpenAPI document, like "#/components/schemas/Pet", only resolve when the
+// schema object is the document's root
+func FromOpenAPI30Schema(doc map[string]interface{}) (*RootSchema, error) {
+	// normalize values such as the ints of YAML decoders
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var norm interface{}
+	if err := json.Unmarshal(data, &norm); err != nil {
+		return nil, err
+	}
+	converted, err := fromOpenAPI30(norm, "")
+	if err != nil {
+		return nil, err
+	}
+	converted.(map[string]interface{})["$schema"] = JSONSchema202012
+	if data, err = json.Marshal(converted); err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// fromOpenAPI30 converts a schema in OpenAPI 3.0's dialect
+func fromOpenAPI30(v interface{}, ptr string) (interface{}, error) {
+	t, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a schema object", ptr)
+	}
+	if ref, ok := t["$ref"]; ok {
+		return map[string]interface{}{"$ref": ref}, nil
+	}
+
+	out := map[string]interface{}{}
+	for _, key := range sortedMapKeys(t) {
+		val := t[key]
+		kptr := ptr + "/" + EscapePointerToken(key)
+		switch key {
+		case "type":
+			name, ok := val.(string)
+			if !ok || name == "null" {
+				return nil, fmt.Errorf("%s: OpenAPI 3.0 types are single names other than \"null\", use \"nullable\" instead", kptr)
+			}
+			out[key] = name
+		case "nullable":
+			if _, ok := val.(bool); !ok {
+				return nil, fmt.Errorf("%s: expected a boolean", kptr)
+			}
+		case "example":
+			out["examples"] = []interface{}{val}
+		case "exclusiveMinimum", "exclusiveMaximum":
+			bound := "minimum"
+			if key == "exclusiveMaximum" {
+				bound = "maximum"
+			}
+			exclusive, ok := val.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected a boolean", kptr)
+			}
+			if n, ok := t[bound]; ok && exclusive {
+				out[key] = n
+			}
+		case "minimum", "maximum":
+			exclusive := "exclusiveMinimum"
+			if key == "maximum" {
+				exclusive = "exclusiveMaximum"
+			}
+			if b, _ := t[exclusive].(bool); !b {
+				out[key] = val
+			}
+		default:
+			conv, err := mapSubschemas(key, val, kptr, fromOpenAPI30)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = conv
+		}
+	}
+
+	// nullable only has an effect alongside a type
+	if nullable, _ := t["nullable"].(bool); nullable && out["type"] != nil {
+		out["type"] = []interface{}{out["type"], "null"}
+		if enum, ok := out["enum"].([]interface{}); ok && !containsValue(enum, nil) {
+			out["enum"] = append(enum, nil)
+		}
+	}
+	return out, nil
+}
+
+// openAPISchemasPtr is the JSON pointer of the schemas of an OpenAPI
+// document's components
+const openAPISchemasPtr = "/components/schemas/"
+
+// OpenAPIComponents gives the "components" section of an OpenAPI 3.1
+// document with schemas, keyed by component name. References within each
+// schema are rewritten to point into its component, and references to
+// another schema of the set, by its "$id", to that schema's component.
+// Schemas lose their "$id" and "$schema", so references resolve against
+// the OpenAPI document, and other references are made absolute
+func OpenAPIComponents(schemas map[string]*RootSchema) (map[string]interface{}, error) {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bases := map[string]*url.URL{}
+	ids := map[string]string{}
+	for _, name := range names {
+		base, err := url.Parse(schemas[name].ID)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s has an invalid $id: %s", name, err.Error())
+		}
+		bases[name] = base
+		if schemas[name].ID != "" {
+			ids[bundleDocURL(base)] = name
+		}
+	}
+
+	components := map[string]interface{}{}
+	for _, name := range names {
+		data, err := json.Marshal(schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		base := bases[name]
+		err = mapRefs(doc, func(ref string) (string, error) {
+			u, err := url.Parse(ref)
+			if err != nil {
+				return "", fmt.Errorf("schema %s: invalid reference %q: %s", name, ref, err.Error())
+			}
+			if u.Fragment != "" && u.Fragment[0] != '/' {
+				// plain-name fragments don't depend on the document's location
+				return ref, nil
+			}
+			target := name
+			if !strings.HasPrefix(ref, "#") {
+				u = base.ResolveReference(u)
+				var ok bool
+				if target, ok = ids[bundleDocURL(u)]; !ok {
+					return u.String(), nil
+				}
+			}
+			return "#" + openAPISchemasPtr + EscapePointerToken(target) + u.Fragment, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if obj, ok := doc.(map[string]interface{}); ok {
+			delete(obj, "$id")
+			delete(obj, "$schema")
+		}
+		components[name] = doc
+	}
+	return map[string]interface{}{"schemas": components}, nil
+}
+
+// ExtractOpenAPISchemas gives a standalone schema for each schema component
+// of the OpenAPI document data, keyed by component name. The components a
+// schema refers to are embedded under its "$defs", with references
+// rewritten to match. Components of OpenAPI 3.0 documents are converted
+// from its dialect as FromOpenAPI30Schema does. References elsewhere in
+// the document, such as to parameters, are errors
+func ExtractOpenAPISchemas(data []byte) (map[string]*RootSchema, error) {
+	doc := struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI document: %s", err.Error())
+	}
+
+	components := map[string]interface{}{}
+	for _, name := range sortedMapKeys(doc.Components.Schemas) {
+		comp := doc.Components.Schemas[name]
+		if strings.HasPrefix(doc.OpenAPI, "3.0") {
+			conv, err := fromOpenAPI30(comp, openAPISchemasPtr+EscapePointerToken(name))
+			if err != nil {
+				return nil, err
+			}
+			comp = conv
+		}
+		components[name] = comp
+	}
+
+	schemas := map[string]*RootSchema{}
+	for _, name := range sortedMapKeys(components) {
+		rs, err := extractOpenAPISchema(name, components)
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = rs
+	}
+	return schemas, nil
+}
+
+// extractOpenAPISchema makes a standalone copy of the component name,
+// embedding the components it refers to
+func extractOpenAPISchema(name string, components map[string]interface{}) (*RootSchema, error) {
+	root := cloneValue(components[name])
+	obj, _ := root.(map[string]interface{})
+	embedded := map[string]string{}
+	defs := map[string]interface{}{}
+	var rewrite func(ref string) (string, error)
+	rewrite = func(ref string) (string, error) {
+		if !strings.HasPrefix(ref, "#"+openAPISchemasPtr) {
+			if strings.HasPrefix(ref, "#/") || ref == "#" {
+				return "", fmt.Errorf("schema %s: reference %q isn't to a schema component", name, ref)
+			}
+			return ref, nil
+		}
+		rest := ref[len("#"+openAPISchemasPtr):]
+		comp, frag := rest, ""
+		if i := strings.Index(rest, "/"); i != -1 {
+			comp, frag = rest[:i], rest[i:]
+		}
+		comp = strings.Replace(strings.Replace(comp, "~1", "/", -1), "~0", "~", -1)
+		if comp == name {
+			return "#" + frag, nil
+		}
+		key, ok := embedded[comp]
+		if !ok {
+			target, ok := components[comp]
+			if !ok {
+				return "", fmt.Errorf("schema %s: reference %q is to a missing component", name, ref)
+			}
+			key = uniqueBundleName(defs, obj, comp)
+			embedded[comp] = key
+			copied := cloneValue(target)
+			defs[key] = copied
+			if err := mapRefs(copied, rewrite); err != nil {
+				return "", err
+			}
+		}
+		return "#/$defs/" + EscapePointerToken(key) + frag, nil
+	}
+	if err := mapRefs(root, rewrite); err != nil {
+		return nil, err
+	}
+
+	if obj != nil {
+		if len(defs) > 0 {
+			existing, _ := obj["$defs"].(map[string]interface{})
+			if existing == nil {
+				existing = map[string]interface{}{}
+			}
+			for key, def := range defs {
+				existing[key] = def
+			}
+			obj["$defs"] = existing
+		}
+		if _, ok := obj["$schema"]; !ok {
+			obj["$schema"] = JSONSchema202012
+		}
+	}
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
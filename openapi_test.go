@@ -0,0 +1,169 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFromOpenAPI30Schema(t *testing.T) {
+	doc := map[string]interface{}{}
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "nullable": true, "example": "Rex"},
+			"age": {"type": "integer", "minimum": 0, "exclusiveMinimum": true, "maximum": 30, "exclusiveMaximum": false},
+			"status": {"type": "string", "enum": ["available", "sold"], "nullable": true},
+			"owner": {"$ref": "#/definitions/owner", "description": "ignored beside $ref"},
+			"tags": {"type": "array", "items": {"type": "string", "x-order": 1}},
+			"any": {"nullable": true}
+		},
+		"discriminator": {"propertyName": "kind"},
+		"definitions": {"owner": {"type": "object", "additionalProperties": {"type": "number", "nullable": true}}}
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := FromOpenAPI30Schema(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": ["string", "null"], "examples": ["Rex"]},
+			"age": {"type": "integer", "exclusiveMinimum": 0, "maximum": 30},
+			"status": {"type": ["string", "null"], "enum": ["available", "sold", null]},
+			"owner": {"$ref": "#/definitions/owner"},
+			"tags": {"type": "array", "items": {"type": "string", "x-order": 1}},
+			"any": {}
+		},
+		"discriminator": {"propertyName": "kind"},
+		"definitions": {"owner": {"type": "object", "additionalProperties": {"type": ["number", "null"]}}}
+	}`
+	assertJSONEqual(t, "FromOpenAPI30Schema", expect, rs)
+
+	cases := []struct {
+		data  string
+		valid bool
+	}{
+		{`{"name": null, "status": null, "owner": {"a": null}}`, true},
+		{`{"name": "Rex", "age": 1, "status": "sold", "owner": {"a": 1}}`, true},
+		{`{"name": "Rex", "age": 0}`, false},
+		{`{"name": "Rex", "owner": {"a": "1"}}`, false},
+		{`{"name": "Rex", "tags": [null]}`, false},
+	}
+	for _, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("%s: expected valid to be %t, got errors %v", c.data, c.valid, errs)
+		}
+	}
+
+	for _, bad := range []string{`{"type": ["string", "null"]}`, `{"properties": {"a": {"type": "null"}}}`} {
+		doc := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(bad), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := FromOpenAPI30Schema(doc); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	schemas := map[string]*RootSchema{
+		"Pet": Must(`{
+			"$id": "https://example.com/pet.json",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"properties": {
+				"owner": {"$ref": "owner.json"},
+				"tag": {"$ref": "#/definitions/tag"},
+				"toy": {"$ref": "https://example.com/toy.json#/definitions/ball"}
+			},
+			"definitions": {"tag": {"type": "string"}}
+		}`),
+		"Owner": Must(`{
+			"$id": "https://example.com/owner.json",
+			"properties": {"pets": {"items": {"$ref": "pet.json"}}, "self": {"$ref": "#"}}
+		}`),
+	}
+	got, err := OpenAPIComponents(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"schemas": {
+		"Pet": {
+			"properties": {
+				"owner": {"$ref": "#/components/schemas/Owner"},
+				"tag": {"$ref": "#/components/schemas/Pet/definitions/tag"},
+				"toy": {"$ref": "https://example.com/toy.json#/definitions/ball"}
+			},
+			"definitions": {"tag": {"type": "string"}}
+		},
+		"Owner": {
+			"properties": {"pets": {"items": {"$ref": "#/components/schemas/Pet"}}, "self": {"$ref": "#/components/schemas/Owner"}}
+		}
+	}}`
+	assertJSONEqual(t, "OpenAPIComponents", expect, got)
+
+	doc, err := json.Marshal(map[string]interface{}{"openapi": "3.1.0", "components": got})
+	if err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := ExtractOpenAPISchemas(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"properties": {
+			"owner": {"$ref": "#/$defs/Owner"},
+			"tag": {"$ref": "#/definitions/tag"},
+			"toy": {"$ref": "https://example.com/toy.json#/definitions/ball"}
+		},
+		"definitions": {"tag": {"type": "string"}},
+		"$defs": {"Owner": {"properties": {"pets": {"items": {"$ref": "#"}}, "self": {"$ref": "#/$defs/Owner"}}}}
+	}`
+	assertJSONEqual(t, "ExtractOpenAPISchemas", expect, extracted["Pet"])
+}
+
+func TestExtractOpenAPISchemas(t *testing.T) {
+	doc := []byte(`{
+		"openapi": "3.0.3",
+		"components": {"schemas": {
+			"Pet": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string", "nullable": true}, "tags": {"type": "array", "items": {"$ref": "#/components/schemas/Tag"}}}},
+			"Tag": {"type": "string", "example": "cute"}
+		}}
+	}`)
+	schemas, err := ExtractOpenAPISchemas(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("expected 2 schemas, got %d", len(schemas))
+	}
+	pet := schemas["Pet"]
+	for data, valid := range map[string]bool{
+		`{"name": null, "tags": ["cute"]}`: true,
+		`{"name": "Rex", "tags": [1]}`:     false,
+	} {
+		errs, err := pet.ValidateBytes([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(errs) == 0) != valid {
+			t.Errorf("%s: expected valid to be %t, got errors %v", data, valid, errs)
+		}
+	}
+
+	bad := []byte(`{"openapi": "3.1.0", "components": {"schemas": {"A": {"$ref": "#/components/parameters/id"}}}}`)
+	if _, err := ExtractOpenAPISchemas(bad); err == nil {
+		t.Errorf("expected an error for a reference to a parameter")
+	}
+}
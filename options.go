@@ -0,0 +1,55 @@
+package jsonschema
+
+// Options configure how a root schema is parsed and how it validates. The
+// zero value holds the default of every option
+type Options struct {
+	// Parsing are the options of parsing the schema
+	Parsing ParseOptions
+	// Decoding are the options of the built-in decode paths: ValidateBytes,
+	// ValidateReader, the middleware and the decoders of other encodings
+	Decoding DecodeOptions
+	// Formats are the options of the built-in formats
+	Formats FormatOptions
+	// Hooks are called around the evaluation of every keyword of every
+	// schema, in order
+	Hooks []KeywordHooks
+}
+
+// defaultOptions are the options of schemas no options were set for
+var defaultOptions = &Options{}
+
+// Options gives the options of rs
+func (rs *RootSchema) Options() Options {
+	return *rs.options()
+}
+
+// SetOptions sets the options of rs. Options set before rs is parsed from
+// JSON apply to parsing it:
+//
+//	rs := &RootSchema{}
+//	rs.SetOptions(Options{Parsing: ParseOptions{Strict: true}})
+//	err := json.Unmarshal(data, rs)
+//
+// Options mustn't be set while rs is validating
+func (rs *RootSchema) SetOptions(opts Options) {
+	opts.Formats = opts.Formats.clone()
+	opts.Hooks = append([]KeywordHooks(nil), opts.Hooks...)
+	rs.opts = &opts
+	rs.bindOptions()
+}
+
+// options gives the options of rs, the defaults if none were set
+func (rs *RootSchema) options() *Options {
+	if rs.opts == nil {
+		return defaultOptions
+	}
+	return rs.opts
+}
+
+// bindOptions points every schema of rs at its options
+func (rs *RootSchema) bindOptions() {
+	rs.Schema.Walk(func(_ string, s *Schema) error {
+		s.opts = rs.opts
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"fmt"
+)
+
+// ValidateAt validates data as the value at the instance JSON pointer
+// instancePtr, checking it against the subschemas that describe that
+// location without needing the rest of the instance, as when a PATCH
+// request replaces a single field. Both "" and "/" point at the whole
+// instance. Subschemas are found as SchemasAt finds them, except that
+// "anyOf" and "oneOf" are satisfied when data is valid for any one of
+// their branches and "if", "then" and "else" are skipped, since which of
+// them apply depends on the rest of the instance. Errors are reported at
+// their place within the whole instance
+func (rs *RootSchema) ValidateAt(instancePtr string, data interface{}) ([]ValError, error) {
+	if instancePtr == "/" {
+		instancePtr = ""
+	}
+	tokens, err := ParsePointer(instancePtr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance pointer %q: %s", instancePtr, err.Error())
+	}
+
+	errs := []ValError{}
+	validateAt(&rs.Schema, tokens, tokens, data, &errs, map[*Schema]bool{})
+	return errs, nil
+}
+
+// validateAt validates data against the schemas of s for the location
+// rest, the remaining tokens of ptr. seen holds the schemas already visited
+// for rest, guarding against reference cycles
+func validateAt(s *Schema, ptr, rest Pointer, data interface{}, errs *[]ValError, seen map[*Schema]bool) {
+	s, ok := resolveSchema(s)
+	if !ok || seen[s] {
+		return
+	}
+	seen[s] = true
+
+	propPath := ptr.String()
+	if propPath == "" {
+		propPath = "/"
+	}
+	if len(rest) == 0 {
+		s.Validate(propPath, data, errs)
+		return
+	}
+	if s.schemaType != schemaTypeObject {
+		return
+	}
+
+	for _, child := range childSchemas(s, rest[0]) {
+		validateAt(child, ptr, rest[1:], data, errs, map[*Schema]bool{})
+	}
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		for _, branch := range *allOf {
+			validateAt(branch, ptr, rest, data, errs, seen)
+		}
+	}
+	for _, kw := range []string{"anyOf", "oneOf"} {
+		var branches []*Schema
+		switch v := s.Validators[kw].(type) {
+		case *AnyOf:
+			branches = *v
+		case *OneOf:
+			branches = *v
+		}
+		if len(branches) == 0 {
+			continue
+		}
+		matched := false
+		for _, branch := range branches {
+			test := []ValError{}
+			validateAt(branch, ptr, rest, data, &test, copySeen(seen))
+			if len(test) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			AddError(errs, propPath, data, fmt.Sprintf("did not match any of the %s schemas", kw))
+		}
+	}
+}
+
+// copySeen copies a set of visited schemas, so that sibling branches are
+// each free to visit the same schemas
+func copySeen(seen map[*Schema]bool) map[*Schema]bool {
+	cp := make(map[*Schema]bool, len(seen))
+	for s := range seen {
+		cp[s] = true
+	}
+	return cp
+}
@@ -0,0 +1,88 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestValidateAt(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["name", "tags"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"tags": {"type": "array", "items": {"$ref": "#/definitions/tag"}},
+			"kind": {"type": "string"},
+			"size": {"anyOf": [{"type": "integer"}, {"enum": ["small", "large"]}]}
+		},
+		"allOf": [{"properties": {"name": {"maxLength": 5}}}],
+		"anyOf": [
+			{"properties": {"kind": {"const": "a"}}},
+			{"properties": {"kind": {"const": "b"}}}
+		],
+		"if": {"properties": {"kind": {"const": "a"}}},
+		"then": {"properties": {"name": {"const": "never"}}},
+		"additionalProperties": false,
+		"definitions": {
+			"tag": {"type": "object", "properties": {"label": {"type": "string"}}}
+		}
+	}`)
+
+	cases := []struct {
+		ptr    string
+		data   interface{}
+		expect []string
+	}{
+		{"/name", "ok", nil},
+		{"/name", "", []string{`/name: ""`}},
+		{"/name", "toolong", []string{`/name: "toolong"`}},
+		{"/tags/3/label", "x", nil},
+		{"/tags/3/label", 1.0, []string{"/tags/3/label: 1"}},
+		{"/tags/0", map[string]interface{}{"label": true}, []string{"/tags/0/label: true"}},
+		{"/size", 3.0, nil},
+		{"/size", "large", nil},
+		{"/size", "huge", []string{`/size: "huge"`}},
+		{"/kind", "b", nil},
+		{"/kind", "c", []string{`/kind: "c"`}},
+		{"/other", 1.0, []string{"/other: 1"}},
+		{"/", map[string]interface{}{}, []string{"/: {}"}},
+	}
+	for _, c := range cases {
+		errs, err := rs.ValidateAt(c.ptr, c.data)
+		if err != nil {
+			t.Errorf("%s: %s", c.ptr, err.Error())
+			continue
+		}
+		if len(errs) != len(c.expect) {
+			t.Errorf("%s %v: expected %d errors, got %v", c.ptr, c.data, len(c.expect), errs)
+			continue
+		}
+		for i, e := range errs {
+			if got := e.PropertyPath + ": " + InvalidValueString(e.InvalidValue); got != c.expect[i] {
+				t.Errorf("%s %v: expected error %q, got %q", c.ptr, c.data, c.expect[i], got)
+			}
+		}
+	}
+
+	if _, err := rs.ValidateAt("name", "x"); err == nil {
+		t.Error("expected an error for an invalid pointer")
+	}
+}
+
+func TestValidateAtRecursive(t *testing.T) {
+	rs := Must(`{
+		"$ref": "#/definitions/node",
+		"definitions": {
+			"node": {
+				"allOf": [{"$ref": "#/definitions/node"}],
+				"properties": {"children": {"items": {"$ref": "#/definitions/node"}}, "value": {"type": "number"}}
+			}
+		}
+	}`)
+	errs, err := rs.ValidateAt("/children/0/children/2/value", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].PropertyPath != "/children/0/children/2/value" {
+		t.Errorf("expected a single error at the value, got %v", errs)
+	}
+}
@@ -0,0 +1,324 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Patch is a JSON Patch (RFC 6902), a sequence of operations on a JSON
+// document
+type Patch []PatchOperation
+
+// PatchOperation is a single operation of a JSON Patch. Value is as
+// encoding/json decodes it
+type PatchOperation struct {
+	// Op is one of "add", "remove", "replace", "move", "copy" and "test"
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// ParsePatch decodes a JSON Patch document, checking its operations have
+// the members they need
+func ParsePatch(data []byte) (Patch, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing JSON patch: %s", err.Error())
+	}
+	patch := make(Patch, len(raw))
+	for i, members := range raw {
+		for _, key := range []string{"op", "path"} {
+			if _, ok := members[key]; !ok {
+				return nil, fmt.Errorf("operation %d: %q is required", i, key)
+			}
+		}
+		op := &patch[i]
+		if err := json.Unmarshal(members["op"], &op.Op); err != nil {
+			return nil, fmt.Errorf("operation %d: op must be a string", i)
+		}
+		if err := json.Unmarshal(members["path"], &op.Path); err != nil {
+			return nil, fmt.Errorf("operation %d: path must be a string", i)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			val, ok := members["value"]
+			if !ok {
+				return nil, fmt.Errorf("operation %d: %q is required for %s", i, "value", op.Op)
+			}
+			json.Unmarshal(val, &op.Value)
+		case "move", "copy":
+			from, ok := members["from"]
+			if !ok {
+				return nil, fmt.Errorf("operation %d: %q is required for %s", i, "from", op.Op)
+			}
+			if err := json.Unmarshal(from, &op.From); err != nil {
+				return nil, fmt.Errorf("operation %d: from must be a string", i)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+	}
+	return patch, nil
+}
+
+// Apply applies the patch to doc, a document as encoding/json decodes it,
+// giving the patched document. doc is left as it is. Patches apply
+// atomically, so any failing operation, including a failing "test", is an
+// error
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+	doc = cloneValue(doc)
+	for i, op := range p {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %s", i, op.Op, op.Path, err.Error())
+		}
+	}
+	return doc, nil
+}
+
+// PatchError is a validation error of a patched document, attributed to
+// the operation that introduced it
+type PatchError struct {
+	// Operation is the index within the patch of the operation after which
+	// the error appeared, or -1 if the document had it before patching
+	Operation int
+	ValError
+}
+
+// Error implements the error interface for PatchError
+func (e PatchError) Error() string {
+	if e.Operation < 0 {
+		return "before patching: " + e.ValError.Error()
+	}
+	return fmt.Sprintf("operation %d: %s", e.Operation, e.ValError.Error())
+}
+
+// ValidatePatch applies patch to doc and validates the result, giving the
+// patched document and its validation errors. Each error is attributed to
+// the operation after which it appeared and stayed until the end of the
+// patch, so a violation a later operation fixes isn't reported. Patches
+// that can't be applied are an error
+func (rs *RootSchema) ValidatePatch(doc interface{}, patch Patch) (interface{}, []PatchError, error) {
+	doc = cloneValue(doc)
+	introduced := map[string]int{}
+	errs := []ValError{}
+	rs.Validate("/", doc, &errs)
+	for _, e := range errs {
+		introduced[patchErrorKey(e)] = -1
+	}
+
+	for i, op := range patch {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, nil, fmt.Errorf("operation %d (%s %s): %s", i, op.Op, op.Path, err.Error())
+		}
+		errs = []ValError{}
+		rs.Validate("/", doc, &errs)
+		current := map[string]int{}
+		for _, e := range errs {
+			key := patchErrorKey(e)
+			if at, ok := introduced[key]; ok {
+				current[key] = at
+			} else {
+				current[key] = i
+			}
+		}
+		introduced = current
+	}
+
+	res := make([]PatchError, len(errs))
+	for i, e := range errs {
+		res[i] = PatchError{Operation: introduced[patchErrorKey(e)], ValError: e}
+	}
+	return doc, res, nil
+}
+
+// patchErrorKey identifies a validation error across the documents of
+// successive operations
+func patchErrorKey(e ValError) string {
+	return e.PropertyPath + "\x00" + e.Message + "\x00" + InvalidValueString(e.InvalidValue)
+}
+
+// apply performs the operation on doc, which it may modify, giving the
+// resulting document
+func (op PatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePatchPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAdd(doc, path, cloneValue(op.Value))
+	case "remove":
+		doc, _, err = patchRemove(doc, path)
+		return doc, err
+	case "replace":
+		if len(path) == 0 {
+			return cloneValue(op.Value), nil
+		}
+		return patchContainer(doc, path, func(container interface{}, tok string) (interface{}, error) {
+			switch t := container.(type) {
+			case map[string]interface{}:
+				if _, ok := t[tok]; !ok {
+					return nil, fmt.Errorf("no property %q", tok)
+				}
+				t[tok] = cloneValue(op.Value)
+				return t, nil
+			case []interface{}:
+				i, err := patchIndex(t, tok, false)
+				if err != nil {
+					return nil, err
+				}
+				t[i] = cloneValue(op.Value)
+				return t, nil
+			}
+			return nil, fmt.Errorf("%s has no children", DataType(container))
+		})
+	case "move", "copy":
+		from, err := parsePatchPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		val, err := from.Eval(doc)
+		if err != nil {
+			return nil, fmt.Errorf("from: %s", err.Error())
+		}
+		if op.Op == "copy" {
+			return patchAdd(doc, path, cloneValue(val))
+		}
+		if len(path) > len(from) && path.hasPrefix(from) {
+			return nil, fmt.Errorf("can't move %s into one of its children", op.From)
+		}
+		if doc, _, err = patchRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, val)
+	case "test":
+		val, err := path.Eval(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(val, op.Value) {
+			return nil, fmt.Errorf("test failed: %s isn't %s", InvalidValueString(val), InvalidValueString(op.Value))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePatchPointer parses the path or from of an operation, which aren't
+// URI fragments
+func parsePatchPointer(str string) (Pointer, error) {
+	if str != "" && str[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with /", str)
+	}
+	return ParsePointer(str)
+}
+
+// patchAdd adds val at path within doc, inserting it into arrays
+func patchAdd(doc interface{}, path Pointer, val interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+	return patchContainer(doc, path, func(container interface{}, tok string) (interface{}, error) {
+		switch t := container.(type) {
+		case map[string]interface{}:
+			t[tok] = val
+			return t, nil
+		case []interface{}:
+			i, err := patchIndex(t, tok, true)
+			if err != nil {
+				return nil, err
+			}
+			t = append(t, nil)
+			copy(t[i+1:], t[i:])
+			t[i] = val
+			return t, nil
+		}
+		return nil, fmt.Errorf("%s has no children", DataType(container))
+	})
+}
+
+// patchRemove removes the value at path within doc, giving the document
+// and the removed value
+func patchRemove(doc interface{}, path Pointer) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("can't remove the whole document")
+	}
+	var removed interface{}
+	doc, err := patchContainer(doc, path, func(container interface{}, tok string) (interface{}, error) {
+		switch t := container.(type) {
+		case map[string]interface{}:
+			val, ok := t[tok]
+			if !ok {
+				return nil, fmt.Errorf("no property %q", tok)
+			}
+			removed = val
+			delete(t, tok)
+			return t, nil
+		case []interface{}:
+			i, err := patchIndex(t, tok, false)
+			if err != nil {
+				return nil, err
+			}
+			removed = t[i]
+			return append(t[:i], t[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%s has no children", DataType(container))
+	})
+	return doc, removed, err
+}
+
+// patchContainer replaces the object or array holding the location path
+// within doc with the one fn gives for it and the last token of path
+func patchContainer(doc interface{}, path Pointer, fn func(container interface{}, tok string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch t := doc.(type) {
+	case map[string]interface{}:
+		child, ok := t[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("no property %q", path[0])
+		}
+		updated, err := patchContainer(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[path[0]] = updated
+		return t, nil
+	case []interface{}:
+		i, err := patchIndex(t, path[0], false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := patchContainer(t[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[i] = updated
+		return t, nil
+	}
+	return nil, fmt.Errorf("%s has no children", DataType(doc))
+}
+
+// patchIndex gives the array index tok refers to. Indexes just past the
+// end, including "-", are allowed when inserting
+func patchIndex(arr []interface{}, tok string, insert bool) (int, error) {
+	if tok == "-" && insert {
+		return len(arr), nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || tok[0] == '+' || tok[0] == '-' || (len(tok) > 1 && tok[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i > len(arr) || (i == len(arr) && !insert) {
+		return 0, fmt.Errorf("no element %q", tok)
+	}
+	return i, nil
+}
@@ -0,0 +1,131 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPatchApply(t *testing.T) {
+	// examples from RFC 6902 appendix A
+	cases := []struct {
+		doc, patch, expect, err string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz": "qux", "foo": "bar"}`, ""},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`, ""},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`, ""},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`, ""},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`, ""},
+		{`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`, `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`, `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`, ""},
+		{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`, ""},
+		{`{"baz": "qux", "foo": ["a", 2, "c"]}`, `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`, `{"baz": "qux", "foo": ["a", 2, "c"]}`, ""},
+		{`{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, "", `operation 0 (test /baz): test failed: "qux" isn't "bar"`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo": "bar", "child": {"grandchild": {}}}`, ""},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, "", `operation 0 (add /baz/bat): no property "baz"`},
+		{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/": 9, "~1": 10}`, ""},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar", ["abc", "def"]]}`, ""},
+		{`{"foo": "bar"}`, `[{"op": "copy", "from": "/foo", "path": "/baz"}]`, `{"foo": "bar", "baz": "bar"}`, ""},
+		{`{"foo": "bar"}`, `[{"op": "replace", "path": "", "value": [1]}]`, `[1]`, ""},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/2", "value": 1}]`, "", `operation 0 (add /foo/2): no element "2"`},
+		{`{"foo": ["bar"]}`, `[{"op": "remove", "path": "/foo/01"}]`, "", `operation 0 (remove /foo/01): invalid array index "01"`},
+		{`{"foo": {"a": 1}}`, `[{"op": "move", "from": "/foo", "path": "/foo/b"}]`, "", `operation 0 (move /foo/b): can't move /foo into one of its children`},
+		{`{"foo": "bar"}`, `[{"op": "remove", "path": "foo"}]`, "", `operation 0 (remove foo): invalid JSON pointer "foo": must be empty or start with /`},
+	}
+	for i, c := range cases {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(c.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		patch, err := ParsePatch([]byte(c.patch))
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		got, err := patch.Apply(doc)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("case %d: expected error %q, got %v", i, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		assertJSONEqual(t, c.patch, c.expect, got)
+
+		// the document patched is left as it was
+		assertJSONEqual(t, c.patch, c.doc, doc)
+	}
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	cases := []struct {
+		patch, err string
+	}{
+		{`{}`, "error parsing JSON patch"},
+		{`[{"path": "/a"}]`, `operation 0: "op" is required`},
+		{`[{"op": "add", "path": "/a"}]`, `operation 0: "value" is required for add`},
+		{`[{"op": "remove", "path": "/a"}, {"op": "move", "path": "/a"}]`, `operation 1: "from" is required for move`},
+		{`[{"op": "merge", "path": "/a"}]`, `operation 0: unknown op "merge"`},
+	}
+	for _, c := range cases {
+		_, err := ParsePatch([]byte(c.patch))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected error %q, got %v", c.patch, c.err, err)
+		}
+	}
+}
+
+func TestValidatePatch(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer", "minimum": 0},
+			"tags": {"type": "array", "maxItems": 2}
+		}
+	}`)
+	var doc interface{}
+	json.Unmarshal([]byte(`{"name": "ada", "age": -1, "tags": ["a"]}`), &doc)
+
+	patch, err := ParsePatch([]byte(`[
+		{"op": "add", "path": "/tags/-", "value": "b"},
+		{"op": "remove", "path": "/name"},
+		{"op": "add", "path": "/tags/-", "value": "c"},
+		{"op": "add", "path": "/name", "value": 1},
+		{"op": "replace", "path": "/name", "value": "grace"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, errs, err := rs.ValidatePatch(doc, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSONEqual(t, "patched", `{"name": "grace", "age": -1, "tags": ["a", "b", "c"]}`, patched)
+
+	got := map[string]int{}
+	for _, e := range errs {
+		got[e.PropertyPath] = e.Operation
+	}
+	expect := map[string]int{"/age": -1, "/tags": 2}
+	if len(got) != len(expect) {
+		t.Fatalf("expected errors at %v, got %v", expect, errs)
+	}
+	for path, op := range expect {
+		if got[path] != op {
+			t.Errorf("%s: expected the error to be attributed to operation %d, got %d", path, op, got[path])
+		}
+	}
+	for _, e := range errs {
+		if e.PropertyPath == "/tags" && !strings.HasPrefix(e.Error(), "operation 2: /tags: ") {
+			t.Errorf("unexpected error string %q", e.Error())
+		}
+	}
+
+	if _, _, err := rs.ValidatePatch(doc, Patch{{Op: "remove", Path: "/missing"}}); err == nil {
+		t.Error("expected an error for a patch that can't be applied")
+	}
+}
@@ -0,0 +1,175 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a JSON pointer (RFC 6901) held as its unescaped reference
+// tokens. The empty pointer refers to the whole document
+type Pointer []string
+
+// ParsePointer parses a JSON pointer in its string form, such as
+// "/definitions/a~1b", or in the URI fragment form references use, such
+// as "#/definitions/a%20b"
+func ParsePointer(str string) (Pointer, error) {
+	if strings.HasPrefix(str, "#") {
+		frag, err := url.PathUnescape(str[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON pointer %q: %s", str, err.Error())
+		}
+		str = frag
+	}
+	if str == "" {
+		return Pointer{}, nil
+	}
+	if str[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with /", str)
+	}
+	tokens := strings.Split(str[1:], "/")
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || (tok[j+1] != '0' && tok[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid JSON pointer %q: ~ must be followed by 0 or 1", str)
+			}
+		}
+		tokens[i] = UnescapePointerToken(tok)
+	}
+	return Pointer(tokens), nil
+}
+
+// EscapePointerToken escapes a single JSON pointer reference token
+// according to RFC 6901, section 3
+func EscapePointerToken(tok string) string {
+	return strings.Replace(strings.Replace(tok, "~", "~0", -1), "/", "~1", -1)
+}
+
+// UnescapePointerToken reverses EscapePointerToken
+func UnescapePointerToken(tok string) string {
+	return strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+}
+
+// String gives the pointer in its string form
+func (p Pointer) String() string {
+	buf := &strings.Builder{}
+	for _, tok := range p {
+		buf.WriteByte('/')
+		buf.WriteString(EscapePointerToken(tok))
+	}
+	return buf.String()
+}
+
+// Fragment gives the pointer in the URI fragment form references use
+func (p Pointer) Fragment() string {
+	return (&url.URL{Fragment: p.String()}).String()
+}
+
+// Descend gives the pointer to a location beneath p, leaving p as it is
+func (p Pointer) Descend(tokens ...string) Pointer {
+	return append(append(make(Pointer, 0, len(p)+len(tokens)), p...), tokens...)
+}
+
+// Eval gives the value the pointer refers to within an instance, as
+// encoding/json decodes them
+func (p Pointer) Eval(instance interface{}) (interface{}, error) {
+	for i, tok := range p {
+		switch t := instance.(type) {
+		case map[string]interface{}:
+			val, ok := t[tok]
+			if !ok {
+				return nil, fmt.Errorf("%s: no property %q", p[:i+1], tok)
+			}
+			instance = val
+		case []interface{}:
+			idx, err := strconv.Atoi(tok)
+			if err != nil || idx < 0 || idx >= len(t) || (len(tok) > 1 && tok[0] == '0') || tok[0] == '+' {
+				return nil, fmt.Errorf("%s: no element %q", p[:i+1], tok)
+			}
+			instance = t[idx]
+		default:
+			return nil, fmt.Errorf("%s: %s has no children", p[:i+1], DataType(instance))
+		}
+	}
+	return instance, nil
+}
+
+// EvalSchema gives the schema the pointer refers to within s, such as
+// "/properties/name" or "/definitions/address/items". References aren't
+// followed, and pointers to locations other than schemas are errors.
+// Schemas within unknown keywords are parsed anew on each call
+func (p Pointer) EvalSchema(s *Schema) (*Schema, error) {
+	for i := 0; i < len(p); {
+		if s.schemaType != schemaTypeObject {
+			return nil, fmt.Errorf("%s: boolean schemas have no keywords", p[:i+1])
+		}
+		kw := p[i]
+		i++
+
+		var children []subschema
+		switch kw {
+		case "definitions", "$defs":
+			defs := s.Definitions
+			if kw == "$defs" {
+				defs = s.Defs
+			}
+			for _, key := range sortedDefinitionKeys(defs) {
+				children = append(children, subschema{token: key, schema: defs[key]})
+			}
+		default:
+			if v, ok := s.Validators[kw]; ok {
+				children = subschemas(v)
+				break
+			}
+			raw, ok := s.extraKeywords[kw]
+			if !ok {
+				return nil, fmt.Errorf("%s: no keyword %q", p[:i], kw)
+			}
+			// unknown keywords may hold schemas too, parsed afresh
+			extra := &Schema{}
+			if err := json.Unmarshal(raw, extra); err != nil {
+				return nil, fmt.Errorf("%s: not a schema", p[:i])
+			}
+			children = []subschema{{schema: extra}}
+		}
+
+		var next *Schema
+		for _, ch := range children {
+			tokens := Pointer{}
+			if ch.token != "" {
+				tokens = append(tokens, ch.token)
+			}
+			if ch.sub != "" {
+				tokens = append(tokens, ch.sub)
+			}
+			if p[i:].hasPrefix(tokens) {
+				next = ch.schema
+				i += len(tokens)
+				break
+			}
+		}
+		if next == nil {
+			if i < len(p) {
+				return nil, fmt.Errorf("%s: no schema at %q", p[:i+1], p[i])
+			}
+			return nil, fmt.Errorf("%s: not a schema", p[:i])
+		}
+		s = next
+	}
+	return s, nil
+}
+
+// hasPrefix reports whether the tokens of p begin with those of prefix
+func (p Pointer) hasPrefix(prefix Pointer) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i, tok := range prefix {
+		if p[i] != tok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	cases := []struct {
+		ptr    string
+		tokens []string
+		str    string
+	}{
+		{"", []string{}, ""},
+		{"/", []string{""}, "/"},
+		{"/a/0", []string{"a", "0"}, "/a/0"},
+		{"/a~1b/c~0d", []string{"a/b", "c~d"}, "/a~1b/c~0d"},
+		{"/~01", []string{"~1"}, "/~01"},
+		{"#", []string{}, ""},
+		{"#/definitions/a%20b", []string{"definitions", "a b"}, "/definitions/a b"},
+	}
+	for _, c := range cases {
+		p, err := ParsePointer(c.ptr)
+		if err != nil {
+			t.Errorf("%q: %s", c.ptr, err.Error())
+			continue
+		}
+		if len(p) != len(c.tokens) {
+			t.Errorf("%q: expected tokens %q, got %q", c.ptr, c.tokens, []string(p))
+			continue
+		}
+		for i := range p {
+			if p[i] != c.tokens[i] {
+				t.Errorf("%q: expected tokens %q, got %q", c.ptr, c.tokens, []string(p))
+				break
+			}
+		}
+		if p.String() != c.str {
+			t.Errorf("%q: expected string %q, got %q", c.ptr, c.str, p.String())
+		}
+	}
+
+	for _, ptr := range []string{"a", "/a~", "/a~2", "#%zz"} {
+		if _, err := ParsePointer(ptr); err == nil {
+			t.Errorf("%q: expected an error", ptr)
+		}
+	}
+}
+
+func TestPointerFragment(t *testing.T) {
+	p := Pointer{"definitions", "a b", "c/d"}
+	if got := p.Fragment(); got != "#/definitions/a%20b/c~1d" {
+		t.Errorf("unexpected fragment %s", got)
+	}
+	back, err := ParsePointer(p.Fragment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.String() != p.String() {
+		t.Errorf("expected %s, got %s", p, back)
+	}
+}
+
+func TestPointerDescend(t *testing.T) {
+	p := make(Pointer, 1, 4)
+	p[0] = "a"
+	b := p.Descend("b")
+	c := p.Descend("c", "d/e")
+	if b.String() != "/a/b" || c.String() != "/a/c/d~1e" || p.String() != "/a" {
+		t.Errorf("unexpected pointers %s, %s, %s", b, c, p)
+	}
+}
+
+func TestPointerEval(t *testing.T) {
+	var doc interface{}
+	json.Unmarshal([]byte(`{"a": [{"b/c": 1}, 2], "": {"~": true}}`), &doc)
+
+	cases := []struct {
+		ptr    string
+		expect interface{}
+		err    string
+	}{
+		{"/a/0/b~1c", 1.0, ""},
+		{"/a/1", 2.0, ""},
+		{"//~0", true, ""},
+		{"/a/2", nil, `/a/2: no element "2"`},
+		{"/a/01", nil, `/a/01: no element "01"`},
+		{"/a/-", nil, `/a/-: no element "-"`},
+		{"/b", nil, `/b: no property "b"`},
+		{"/a/1/x", nil, "/a/1/x: integer has no children"},
+	}
+	for _, c := range cases {
+		p, err := ParsePointer(c.ptr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.Eval(doc)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: expected error %q, got %v", c.ptr, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.ptr, err.Error())
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("%s: expected %v, got %v", c.ptr, c.expect, got)
+		}
+	}
+}
+
+func TestPointerEvalSchema(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"a/b": {"type": "string"},
+			"list": {"items": [{"type": "integer"}, {"type": "null"}]}
+		},
+		"items": {"type": "boolean"},
+		"not": {"type": "object"},
+		"definitions": {"x": {"title": "x"}},
+		"$defs": {"y": {"title": "y"}},
+		"links": [{"rel": "self", "href": "a", "targetSchema": {"title": "target"}}],
+		"x-schema": {"properties": {"z": {"title": "z"}}},
+		"x-note": "text"
+	}`)
+
+	cases := []struct {
+		ptr, title, err string
+		typ             string
+	}{
+		{"", "", "", ""},
+		{"/properties/a~1b", "", "", "string"},
+		{"/properties/list/items/1", "", "", "null"},
+		{"/items", "", "", "boolean"},
+		{"/not", "", "", "object"},
+		{"/definitions/x", "x", "", ""},
+		{"/$defs/y", "y", "", ""},
+		{"/links/0/targetSchema", "target", "", ""},
+		{"/x-schema/properties/z", "z", "", ""},
+		{"/x-note", "", "/x-note: not a schema", ""},
+		{"/properties/c", "", `/properties/c: no schema at "c"`, ""},
+		{"/required", "", `/required: no keyword "required"`, ""},
+		{"/properties", "", "/properties: not a schema", ""},
+		{"/properties/list/items/2", "", `/properties/list/items/2: no schema at "2"`, ""},
+	}
+	for _, c := range cases {
+		p, err := ParsePointer(c.ptr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sch, err := p.EvalSchema(&rs.Schema)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%q: expected error %q, got %v", c.ptr, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", c.ptr, err.Error())
+			continue
+		}
+		if sch.Title != c.title {
+			t.Errorf("%q: expected title %q, got %q", c.ptr, c.title, sch.Title)
+		}
+		if c.typ != "" {
+			typ, ok := sch.Validators["type"].(*Type)
+			if !ok || len(typ.vals) != 1 || typ.vals[0] != c.typ {
+				t.Errorf("%q: expected type %s, got %v", c.ptr, c.typ, sch.Validators["type"])
+			}
+		}
+	}
+}
+
+func TestPointerFormats(t *testing.T) {
+	rs := Must(`{
+		"type": "array",
+		"items": {
+			"properties": {
+				"op": {"enum": ["add", "remove", "replace", "move", "copy", "test"]},
+				"path": {"format": "json-pointer"},
+				"from": {"format": "json-pointer"},
+				"rel": {"format": "relative-json-pointer"}
+			}
+		}
+	}`)
+
+	cases := []struct {
+		doc    string
+		expect []string
+	}{
+		{`[{"op": "add", "path": "", "rel": "0"}]`, []string{}},
+		{`[{"op": "move", "path": "/a~1b/-", "from": "/~0c/0", "rel": "2/0#"}]`, []string{}},
+		{`[{"op": "copy", "path": "a/b", "from": "/a~", "rel": "1+1/name"}]`, []string{
+			`/0/from: "/a~" invalid json-pointer: unescaped tilda at offset 2: ~ must be followed by 0 or 1`,
+			`/0/path: "a/b" invalid json-pointer: non-empty references must begin with a '/' character`,
+		}},
+		{`[{"op": "test", "path": "/~2", "rel": "-1/a"}]`, []string{
+			`/0/path: "/~2" invalid json-pointer: unescaped tilda at offset 1: ~ must be followed by 0 or 1`,
+			`/0/rel: "-1/a" invalid relative-json-pointer: relative JSON pointers must begin with a non-negative integer`,
+		}},
+	}
+	for i, c := range cases {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(c.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		errs := []ValError{}
+		rs.Validate("/", doc, &errs)
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.Error()
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expect, got)
+		}
+	}
+}
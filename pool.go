@@ -0,0 +1,33 @@
+package jsonschema
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity beyond which buffers aren't returned to
+// the pool, so that one large instance doesn't hold on to its memory
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds buffers for reading encoded instances. Buffers are only
+// needed until instances are decoded, as decoding copies what it keeps.
+// Decoded values aren't pooled, since the errors validation gives hold them
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer takes an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to the pool, unless it has grown too large
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
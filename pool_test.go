@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestValidateReader(t *testing.T) {
+	rs := Must(`{"properties": {"seq": {"type": "integer"}}}`)
+	for i := 0; i < 3; i++ {
+		errs, err := rs.ValidateReader(strings.NewReader(`{"seq": "x"}`))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(errs) != 1 || errs[0].PropertyPath != "/seq" || errs[0].InvalidValue != "x" {
+			t.Errorf("expected an error at /seq, got %v", errs)
+		}
+	}
+	if _, err := rs.ValidateReader(strings.NewReader(`{"seq": `)); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+	if _, err := rs.ValidateReader(failingReader{}); err == nil || err.Error() != "error reading JSON: connection reset" {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("instance")
+	putBuffer(buf)
+	if buf.Len() != 0 {
+		t.Errorf("expected pooled buffers to be reset")
+	}
+	if got := getBuffer(); got.Len() != 0 {
+		t.Errorf("expected an empty buffer from the pool, got %q", got.String())
+	}
+
+	large := bytes.NewBuffer(make([]byte, 0, maxPooledBuffer+1))
+	large.WriteString("instance")
+	putBuffer(large)
+	if large.Len() == 0 {
+		t.Errorf("expected large buffers to be left out of the pool")
+	}
+}
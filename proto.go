@@ -0,0 +1,406 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ProtoOptions configures protobuf generation
+type ProtoOptions struct {
+	// Package is the protobuf package of the generated file, "schema" if empty
+	Package string
+	// RootName is the name of the message generated for the root schema. If
+	// empty it's derived from the schema title, falling back to "Root"
+	RootName string
+}
+
+// GenerateProto emits a proto3 file declaring messages for the root schema
+// and each of its top-level definitions, as a starting point for moving a
+// JSON contract to gRPC. The mapping is best-effort:
+//
+//   - objects with properties become messages, with a field per property
+//     numbered in property name order. Optional scalar properties are
+//     "optional" fields, and fields are given a json_name where protojson
+//     would otherwise name them differently
+//   - objects with additionalProperties become maps, arrays repeated fields
+//   - string enums become enums with an UNSPECIFIED zero value and a value
+//     per string, prefixed with the enum name as protobuf requires.
+//     protojson encodes them by those names rather than the strings
+//   - oneOf and anyOf become messages with a oneof holding a field per branch
+//   - integers become int32 when bounds keep them within its range, and
+//     int64 otherwise, which protojson encodes as strings
+//   - date-time strings become google.protobuf.Timestamp
+//   - anything else, such as values of several types, lists of lists or
+//     objects without properties, becomes a google.protobuf well-known type
+//
+// Schemas other than objects, enums and unions are written inline where
+// they're used rather than declared. Keywords without a protobuf equivalent,
+// like patterns and bounds, are left for validation
+func GenerateProto(rs *RootSchema, opts ProtoOptions) ([]byte, error) {
+	g := &protoWriter{
+		typeDecls: newTypeDecls(),
+		imports:   map[string]bool{},
+		active:    map[*Schema]bool{},
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "schema"
+	}
+	root := opts.RootName
+	if root == "" {
+		root = goName(rs.Title)
+	}
+	if root == "" {
+		root = "Root"
+	}
+
+	if isProtoDeclared(&rs.Schema) {
+		if _, err := g.declare(&rs.Schema, root); err != nil {
+			return nil, err
+		}
+	}
+	for _, defs := range []Definitions{rs.Defs, rs.Definitions} {
+		for _, key := range sortedDefinitionKeys(defs) {
+			if !isProtoDeclared(defs[key]) {
+				continue
+			}
+			if _, err := g.declare(defs[key], goName(key)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(g.decls) == 0 {
+		return nil, fmt.Errorf("schema has no objects, enums or unions to declare messages for")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema. DO NOT EDIT.\n\n")
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(buf, "package %s;\n\n", pkg)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		for _, imp := range imports {
+			fmt.Fprintf(buf, "import %s;\n", strconv.Quote(imp))
+		}
+		buf.WriteString("\n")
+	}
+	for i, decl := range g.decls {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(decl)
+	}
+	return buf.Bytes(), nil
+}
+
+// protoWriter accumulates message and enum declarations. active holds the
+// references being written inline
+type protoWriter struct {
+	typeDecls
+	imports map[string]bool
+	active  map[*Schema]bool
+}
+
+// protoFieldType is the type of a field, and whether it's repeated. Map types
+// are written in full, as in "map<string, int64>"
+type protoFieldType struct {
+	typ      string
+	repeated bool
+}
+
+// scalar reports whether fields of this type lack presence unless they're
+// marked optional
+func (f protoFieldType) scalar() bool {
+	if f.repeated || strings.HasPrefix(f.typ, "map<") {
+		return false
+	}
+	switch f.typ {
+	case "string", "bool", "int32", "int64", "double", "bytes":
+		return true
+	}
+	return false
+}
+
+// isProtoDeclared reports whether s is given a message or enum of its own
+func isProtoDeclared(s *Schema) bool {
+	return isUnionSchema(s) || isStringEnum(s) || isStructSchema(s)
+}
+
+// declare gives s a message or enum, returning its name
+func (g *protoWriter) declare(s *Schema, hint string) (string, error) {
+	return g.typeDecls.declare(s, hint, func(name string) (string, error) {
+		switch {
+		case isUnionSchema(s):
+			return g.unionMessage(s, name)
+		case isStringEnum(s):
+			return g.enum(s, name), nil
+		}
+		return g.message(s, name)
+	}, func(_, description string) string {
+		return goComment(description, "")
+	})
+}
+
+// fieldType gives the type of a field holding values of s, declaring
+// messages and enums nested within it
+func (g *protoWriter) fieldType(s *Schema, hint string) (protoFieldType, error) {
+	if s == nil || s.schemaType != schemaTypeObject {
+		return g.wellKnown("Value"), nil
+	}
+	if s.Ref != "" {
+		target := refTarget(s)
+		if target == nil {
+			return protoFieldType{}, fmt.Errorf("unresolved reference: %s", s.Ref)
+		}
+		if isProtoDeclared(target) {
+			name, err := g.declare(target, refTypeName(s.Ref, hint))
+			return protoFieldType{typ: name}, err
+		}
+		if g.active[target] {
+			return g.wellKnown("Value"), nil
+		}
+		g.active[target] = true
+		defer delete(g.active, target)
+		return g.fieldType(target, hint)
+	}
+	if isProtoDeclared(s) {
+		name, err := g.declare(s, hint)
+		return protoFieldType{typ: name}, err
+	}
+
+	types, _ := nonNullTypes(s)
+	if isEnumSchema(s) {
+		// enums of integers
+		types = []string{"integer"}
+	}
+	if len(types) != 1 {
+		return g.wellKnown("Value"), nil
+	}
+	switch types[0] {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["google/protobuf/timestamp.proto"] = true
+			return protoFieldType{typ: "google.protobuf.Timestamp"}, nil
+		}
+		return protoFieldType{typ: "string"}, nil
+	case "integer":
+		if protoInt32(s) {
+			return protoFieldType{typ: "int32"}, nil
+		}
+		return protoFieldType{typ: "int64"}, nil
+	case "number":
+		return protoFieldType{typ: "double"}, nil
+	case "boolean":
+		return protoFieldType{typ: "bool"}, nil
+	case "array":
+		if items, ok := s.Validators["items"].(*Items); ok && items.single {
+			it, err := g.fieldType(items.Schemas[0], hint+"Item")
+			if err != nil {
+				return protoFieldType{}, err
+			}
+			// repeated fields can't hold repeated fields or maps
+			if !it.repeated && !strings.HasPrefix(it.typ, "map<") {
+				return protoFieldType{typ: it.typ, repeated: true}, nil
+			}
+		}
+		return g.wellKnown("ListValue"), nil
+	case "object":
+		if add, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && add.Schema != nil && add.Schema.schemaType != schemaTypeFalse {
+			vt, err := g.fieldType(add.Schema, hint+"Value")
+			if err != nil {
+				return protoFieldType{}, err
+			}
+			// nor can map values
+			if !vt.repeated && !strings.HasPrefix(vt.typ, "map<") {
+				return protoFieldType{typ: "map<string, " + vt.typ + ">"}, nil
+			}
+		}
+		return g.wellKnown("Struct"), nil
+	}
+	return g.wellKnown("Value"), nil
+}
+
+// wellKnown gives a type from google/protobuf/struct.proto, importing it
+func (g *protoWriter) wellKnown(name string) protoFieldType {
+	g.imports["google/protobuf/struct.proto"] = true
+	return protoFieldType{typ: "google.protobuf." + name}
+}
+
+// protoInt32 reports whether the bounds of integer schema s keep its values
+// within the range of an int32
+func protoInt32(s *Schema) bool {
+	min, ok := s.Validators["minimum"].(*Minimum)
+	if !ok {
+		return false
+	}
+	max, ok := s.Validators["maximum"].(*Maximum)
+	if !ok {
+		return false
+	}
+	return float64(*min) >= math.MinInt32 && float64(*max) <= math.MaxInt32
+}
+
+// message declares a message with a field for each property of s and of
+// its allOf branches
+func (g *protoWriter) message(s *Schema, name string) (string, error) {
+	props, required := collectProperties(s)
+
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "message %s {\n", name)
+	fields := map[string]bool{}
+	for i, key := range keys {
+		prop := props[key]
+		f, err := g.fieldType(prop, name+goName(key))
+		if err != nil {
+			return "", err
+		}
+		label := ""
+		switch {
+		case f.repeated:
+			label = "repeated "
+		case !required[key] && f.scalar():
+			label = "optional "
+		}
+		if prop.Description != "" {
+			buf.WriteString(goComment(prop.Description, "  "))
+		}
+		buf.WriteString("  " + label + protoFieldDecl(f.typ, protoSnakeName(key, false), key, i+1, fields) + "\n")
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// unionMessage declares a message with a oneof holding a field for each
+// branch of a oneOf or anyOf. Null branches are left out, since an unset
+// oneof stands for them
+func (g *protoWriter) unionMessage(s *Schema, name string) (string, error) {
+	var branches []*Schema
+	switch v := s.Validators["oneOf"].(type) {
+	case *OneOf:
+		branches = *v
+	default:
+		branches = *s.Validators["anyOf"].(*AnyOf)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "message %s {\n  oneof value {\n", name)
+	fields := map[string]bool{}
+	num := 0
+	for i, branch := range branches {
+		if types := schemaTypeNames(branch); len(types) == 1 && types[0] == "null" {
+			continue
+		}
+		f, err := g.fieldType(branch, fmt.Sprintf("%sOption%d", name, i+1))
+		if err != nil {
+			return "", err
+		}
+		// oneof fields can't be repeated or maps
+		if f.repeated || strings.HasPrefix(f.typ, "map<") {
+			f = g.wellKnown("Value")
+		}
+		num++
+		buf.WriteString("    " + protoFieldDecl(f.typ, protoVariantName(f.typ), "", num, fields) + "\n")
+	}
+	buf.WriteString("  }\n}\n")
+	return buf.String(), nil
+}
+
+// enum declares an enum with a value for each string of s
+func (g *protoWriter) enum(s *Schema, name string) string {
+	prefix := protoSnakeName(name, true)
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "enum %s {\n  %s_UNSPECIFIED = 0;\n", name, prefix)
+	used := map[string]bool{prefix + "_UNSPECIFIED": true}
+	for i, v := range enumValues(s) {
+		vname := prefix + "_" + protoSnakeName(v.(string), true)
+		if vname == prefix+"_" || used[vname] {
+			vname = fmt.Sprintf("%s_VALUE_%d", prefix, i+1)
+		}
+		used[vname] = true
+		fmt.Fprintf(buf, "  %s = %d;\n", vname, i+1)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// protoFieldDecl writes a field of type typ named after base, picking a
+// name not already in fields. key is the JSON property the field holds, if
+// any, which protojson is told to use when it would pick another
+func protoFieldDecl(typ, base, key string, num int, fields map[string]bool) string {
+	if base == "" {
+		base = "field"
+	}
+	field := base
+	for i := 2; fields[field]; i++ {
+		field = fmt.Sprintf("%s_%d", base, i)
+	}
+	fields[field] = true
+
+	decl := fmt.Sprintf("%s %s = %d", typ, field, num)
+	if key != "" && protoJSONName(field) != key {
+		decl += fmt.Sprintf(" [json_name = %s]", strconv.Quote(key))
+	}
+	return decl + ";"
+}
+
+// protoVariantName names a oneof field after the type it holds
+func protoVariantName(typ string) string {
+	switch typ {
+	case "string", "bool", "int32", "int64", "double", "bytes":
+		return typ + "_value"
+	}
+	return protoSnakeName(strings.TrimPrefix(typ, "google.protobuf."), false)
+}
+
+// protoSnakeName converts a JSON or message name into a snake_case
+// protobuf identifier, in upper case for enum values
+func protoSnakeName(str string, upper bool) string {
+	words := []string{}
+	word := []rune{}
+	runes := []rune(str)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && len(word) > 0:
+			// split "fooBar" and "HTTPServer" before the "B" and "S"
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	name := strings.Join(words, "_")
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "n" + name
+	}
+	if upper {
+		return strings.ToUpper(name)
+	}
+	return strings.ToLower(name)
+}
@@ -0,0 +1,55 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestGenerateProto(t *testing.T) {
+	rs := Must(`{
+		"title": "pet store",
+		"type": "object",
+		"properties": {
+			"id": { "type": "integer" },
+			"count": { "type": "integer", "minimum": 0, "maximum": 100 },
+			"name": { "type": "string", "description": "display name" },
+			"status": { "enum": ["available", "sold"] },
+			"pet": { "$ref": "#/definitions/pet" },
+			"tags": { "type": "array", "items": { "type": "string" } },
+			"updated_at": { "type": "string", "format": "date-time" },
+			"extra": { "additionalProperties": { "type": "number" }, "type": "object" },
+			"meta": { "type": "object" },
+			"grid": { "type": "array", "items": { "type": "array" } },
+			"any": { "type": ["string", "number"] },
+			"ownerID": { "type": "string" }
+		},
+		"required": ["id", "name"],
+		"definitions": {
+			"pet": { "oneOf": [ { "$ref": "#/definitions/cat" }, { "$ref": "#/definitions/dog" }, { "type": "string" }, { "type": "null" } ] },
+			"cat": { "type": "object", "properties": { "lives": { "type": "integer" } } },
+			"dog": { "allOf": [ { "$ref": "#/definitions/animal" }, { "properties": { "good": { "type": "boolean" } } } ] },
+			"animal": { "description": "anything with legs", "properties": { "legs": { "enum": [2, 4] } } }
+		}
+	}`)
+	got, err := GenerateProto(rs, ProtoOptions{Package: "store"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "// Code generated by jsonschema. DO NOT EDIT.\n\nsyntax = \"proto3\";\n\npackage store;\n\nimport \"google/protobuf/struct.proto\";\nimport \"google/protobuf/timestamp.proto\";\n\nmessage PetStore {\n  google.protobuf.Value any = 1;\n  optional int32 count = 2;\n  map<string, double> extra = 3;\n  repeated google.protobuf.ListValue grid = 4;\n  int64 id = 5;\n  google.protobuf.Struct meta = 6;\n  // display name\n  string name = 7;\n  optional string owner_id = 8 [json_name = \"ownerID\"];\n  Pet pet = 9;\n  PetStoreStatus status = 10;\n  repeated string tags = 11;\n  google.protobuf.Timestamp updated_at = 12 [json_name = \"updated_at\"];\n}\n\nmessage Pet {\n  oneof value {\n    Cat cat = 1;\n    Dog dog = 2;\n    string string_value = 3;\n  }\n}\n\nmessage Cat {\n  optional int64 lives = 1;\n}\n\nmessage Dog {\n  optional bool good = 1;\n  optional int64 legs = 2;\n}\n\nenum PetStoreStatus {\n  PET_STORE_STATUS_UNSPECIFIED = 0;\n  PET_STORE_STATUS_AVAILABLE = 1;\n  PET_STORE_STATUS_SOLD = 2;\n}\n\n// anything with legs\nmessage Animal {\n  optional int64 legs = 1;\n}\n"
+	if string(got) != expect {
+		t.Errorf("output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateProto(Must(`{"type": "array", "items": {"$ref": "#"}}`), ProtoOptions{})
+	if err == nil {
+		t.Errorf("expected an error for a schema without messages, got:\n%s", got)
+	}
+
+	got, err = GenerateProto(Must(`{"title": "node", "properties": {"children": {"type": "array", "items": {"$ref": "#"}}, "value": {"$ref": "#/definitions/value"}}, "definitions": {"value": {"type": "integer", "minimum": 0, "maximum": 10}}}`), ProtoOptions{Package: "tree"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "// Code generated by jsonschema. DO NOT EDIT.\n\nsyntax = \"proto3\";\n\npackage tree;\n\nmessage Node {\n  repeated Node children = 1;\n  optional int32 value = 2;\n}\n"
+	if string(got) != expect {
+		t.Errorf("recursive output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,368 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// protoFileSet mirrors the parts of google.protobuf.FileDescriptorSet
+// schemas are generated from, as protojson encodes them
+type protoFileSet struct {
+	File []protoFile `json:"file"`
+}
+
+type protoFile struct {
+	Package     string         `json:"package"`
+	MessageType []protoMessage `json:"messageType"`
+	EnumType    []protoEnum    `json:"enumType"`
+}
+
+type protoMessage struct {
+	Name       string         `json:"name"`
+	Field      []protoField   `json:"field"`
+	NestedType []protoMessage `json:"nestedType"`
+	EnumType   []protoEnum    `json:"enumType"`
+	OneofDecl  []struct {
+		Name string `json:"name"`
+	} `json:"oneofDecl"`
+	Options *struct {
+		MapEntry bool `json:"mapEntry"`
+	} `json:"options"`
+}
+
+type protoField struct {
+	Name           string `json:"name"`
+	Label          string `json:"label"`
+	Type           string `json:"type"`
+	TypeName       string `json:"typeName"`
+	JSONName       string `json:"jsonName"`
+	OneofIndex     *int   `json:"oneofIndex"`
+	Proto3Optional bool   `json:"proto3Optional"`
+}
+
+type protoEnum struct {
+	Name  string `json:"name"`
+	Value []struct {
+		Name   string `json:"name"`
+		Number int    `json:"number"`
+	} `json:"value"`
+}
+
+// protoInt64 describes the 64-bit integers protojson writes as strings of
+// digits and reads as strings or numbers
+func protoInt64(unsigned bool) map[string]interface{} {
+	pattern := "^-?[0-9]+$"
+	if unsigned {
+		pattern = "^[0-9]+$"
+	}
+	return map[string]interface{}{"type": []interface{}{"string", "integer"}, "pattern": pattern}
+}
+
+// protoFloat describes floating point numbers, which protojson writes as
+// strings when they aren't finite
+func protoFloat() map[string]interface{} {
+	return map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{"type": "number"},
+		map[string]interface{}{"enum": []interface{}{"NaN", "Infinity", "-Infinity"}},
+	}}
+}
+
+// protoScalars gives the schemas of the scalar field types
+var protoScalars = map[string]func() map[string]interface{}{
+	"TYPE_DOUBLE": protoFloat,
+	"TYPE_FLOAT":  protoFloat,
+	"TYPE_INT32": func() map[string]interface{} {
+		return map[string]interface{}{"type": "integer", "minimum": -2147483648, "maximum": 2147483647}
+	},
+	"TYPE_UINT32": func() map[string]interface{} {
+		return map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 4294967295}
+	},
+	"TYPE_INT64":  func() map[string]interface{} { return protoInt64(false) },
+	"TYPE_UINT64": func() map[string]interface{} { return protoInt64(true) },
+	"TYPE_BOOL":   func() map[string]interface{} { return map[string]interface{}{"type": "boolean"} },
+	"TYPE_STRING": func() map[string]interface{} { return map[string]interface{}{"type": "string"} },
+	"TYPE_BYTES": func() map[string]interface{} {
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	},
+}
+
+func init() {
+	for alias, of := range map[string]string{
+		"TYPE_SINT32": "TYPE_INT32", "TYPE_SFIXED32": "TYPE_INT32", "TYPE_FIXED32": "TYPE_UINT32",
+		"TYPE_SINT64": "TYPE_INT64", "TYPE_SFIXED64": "TYPE_INT64", "TYPE_FIXED64": "TYPE_UINT64",
+	} {
+		protoScalars[alias] = protoScalars[of]
+	}
+}
+
+// protoWellKnown gives the schemas of the well-known types protojson
+// encodes specially, keyed by full name
+var protoWellKnown = map[string]func() map[string]interface{}{
+	"google.protobuf.Timestamp": func() map[string]interface{} {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	},
+	"google.protobuf.Duration": func() map[string]interface{} {
+		return map[string]interface{}{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`}
+	},
+	"google.protobuf.FieldMask": func() map[string]interface{} { return map[string]interface{}{"type": "string"} },
+	"google.protobuf.Struct":    func() map[string]interface{} { return map[string]interface{}{"type": "object"} },
+	"google.protobuf.Value":     func() map[string]interface{} { return map[string]interface{}{} },
+	"google.protobuf.ListValue": func() map[string]interface{} { return map[string]interface{}{"type": "array"} },
+	"google.protobuf.NullValue": func() map[string]interface{} { return map[string]interface{}{"type": "null"} },
+	"google.protobuf.Empty": func() map[string]interface{} {
+		return map[string]interface{}{"type": "object", "maxProperties": 0}
+	},
+	"google.protobuf.Any": func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"@type": map[string]interface{}{"type": "string"}},
+			"required":   []interface{}{"@type"},
+		}
+	},
+	"google.protobuf.DoubleValue": protoFloat,
+	"google.protobuf.FloatValue":  protoFloat,
+	"google.protobuf.Int64Value":  func() map[string]interface{} { return protoInt64(false) },
+	"google.protobuf.UInt64Value": func() map[string]interface{} { return protoInt64(true) },
+	"google.protobuf.Int32Value":  protoScalars["TYPE_INT32"],
+	"google.protobuf.UInt32Value": protoScalars["TYPE_UINT32"],
+	"google.protobuf.BoolValue":   protoScalars["TYPE_BOOL"],
+	"google.protobuf.StringValue": protoScalars["TYPE_STRING"],
+	"google.protobuf.BytesValue":  protoScalars["TYPE_BYTES"],
+}
+
+// GenerateProtoSchemas generates a schema for each message of a protobuf
+// FileDescriptorSet, keyed by the message's full name, describing the JSON
+// protojson reads and writes for it. The descriptor set is given encoded
+// with protojson, as protojson.Marshal gives it or "buf build -o set.json"
+// writes it, and should include imports so referenced messages are known.
+// Each schema holds the messages it references under "definitions".
+// Fields are named as protojson writes them, with their original names
+// also accepted as protojson reads both, and unknown fields are rejected
+// as protojson does by default. Members of a oneof exclude each other.
+// 64-bit integers are strings of digits, enums their value names or
+// numbers, and well-known types such as Timestamp take their special JSON
+// forms
+func GenerateProtoSchemas(descriptorSet []byte) (map[string]*RootSchema, error) {
+	set := &protoFileSet{}
+	if err := json.Unmarshal(descriptorSet, set); err != nil {
+		return nil, fmt.Errorf("decoding descriptor set: %s", err.Error())
+	}
+	g := &protoGenerator{
+		messages: map[string]*protoMessage{},
+		enums:    map[string]*protoEnum{},
+		defs:     map[string]map[string]interface{}{},
+		refs:     map[string][]string{},
+	}
+	names := []string{}
+	for fi := range set.File {
+		f := &set.File[fi]
+		prefix := ""
+		if f.Package != "" {
+			prefix = f.Package + "."
+		}
+		for i := range f.EnumType {
+			g.enums[prefix+f.EnumType[i].Name] = &f.EnumType[i]
+		}
+		for i := range f.MessageType {
+			names = append(names, g.collect(prefix, &f.MessageType[i])...)
+		}
+	}
+	for _, name := range names {
+		def, err := g.message(name)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = def
+	}
+
+	schemas := map[string]*RootSchema{}
+	for _, name := range names {
+		if g.messages[name].Options != nil && g.messages[name].Options.MapEntry {
+			continue
+		}
+		defs := map[string]interface{}{}
+		g.reachable(name, defs)
+		doc := map[string]interface{}{
+			"$schema":     "http://json-schema.org/draft-07/schema#",
+			"title":       name,
+			"$ref":        "#/definitions/" + EscapePointerToken(name),
+			"definitions": defs,
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		rs := &RootSchema{}
+		if err := json.Unmarshal(data, rs); err != nil {
+			return nil, fmt.Errorf("schema of %s: %s", name, err.Error())
+		}
+		schemas[name] = rs
+	}
+	return schemas, nil
+}
+
+// protoGenerator builds a definition for each message. refs lists the
+// messages each message refers to
+type protoGenerator struct {
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+	defs     map[string]map[string]interface{}
+	refs     map[string][]string
+}
+
+// collect indexes m and the types nested within it by full name, giving
+// the names of the messages
+func (g *protoGenerator) collect(prefix string, m *protoMessage) []string {
+	name := prefix + m.Name
+	g.messages[name] = m
+	names := []string{name}
+	for i := range m.EnumType {
+		g.enums[name+"."+m.EnumType[i].Name] = &m.EnumType[i]
+	}
+	for i := range m.NestedType {
+		names = append(names, g.collect(name+".", &m.NestedType[i])...)
+	}
+	return names
+}
+
+// message builds the definition of the message named name
+func (g *protoGenerator) message(name string) (map[string]interface{}, error) {
+	m := g.messages[name]
+	props := map[string]interface{}{}
+	required := []interface{}{}
+	oneofs := make([][]string, len(m.OneofDecl))
+	for _, f := range m.Field {
+		fs, err := g.field(name, f)
+		if err != nil {
+			return nil, err
+		}
+		jsonName := f.JSONName
+		if jsonName == "" {
+			jsonName = protoJSONName(f.Name)
+		}
+		props[jsonName] = fs
+		if f.Name != jsonName {
+			props[f.Name] = fs
+		}
+		if f.Label == "LABEL_REQUIRED" {
+			required = append(required, jsonName)
+		}
+		if f.OneofIndex != nil && !f.Proto3Optional && *f.OneofIndex < len(oneofs) {
+			oneofs[*f.OneofIndex] = append(oneofs[*f.OneofIndex], jsonName)
+		}
+	}
+
+	def := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		def["required"] = required
+	}
+	exclusions := []interface{}{}
+	for _, members := range oneofs {
+		if len(members) < 2 {
+			continue
+		}
+		// exactly one of: each member alone, or none of them
+		alts := []interface{}{}
+		present := []interface{}{}
+		for _, member := range members {
+			alts = append(alts, map[string]interface{}{"required": []interface{}{member}})
+			present = append(present, map[string]interface{}{"required": []interface{}{member}})
+		}
+		alts = append(alts, map[string]interface{}{"not": map[string]interface{}{"anyOf": present}})
+		exclusions = append(exclusions, map[string]interface{}{"oneOf": alts})
+	}
+	if len(exclusions) > 0 {
+		def["allOf"] = exclusions
+	}
+	return def, nil
+}
+
+// field builds the schema of field f of the message named msg
+func (g *protoGenerator) field(msg string, f protoField) (map[string]interface{}, error) {
+	var value map[string]interface{}
+	typeName := strings.TrimPrefix(f.TypeName, ".")
+	switch {
+	case protoScalars[f.Type] != nil:
+		value = protoScalars[f.Type]()
+	case protoWellKnown[typeName] != nil:
+		value = protoWellKnown[typeName]()
+	case f.Type == "TYPE_ENUM":
+		e := g.enums[typeName]
+		if e == nil {
+			return nil, fmt.Errorf("%s.%s: unknown enum %s", msg, f.Name, typeName)
+		}
+		vals := []interface{}{}
+		for _, v := range e.Value {
+			vals = append(vals, v.Name)
+		}
+		for _, v := range e.Value {
+			vals = append(vals, v.Number)
+		}
+		value = map[string]interface{}{"enum": vals}
+	case f.Type == "TYPE_MESSAGE" || f.Type == "TYPE_GROUP":
+		m := g.messages[typeName]
+		if m == nil {
+			return nil, fmt.Errorf("%s.%s: unknown message %s, is the descriptor set missing imports?", msg, f.Name, typeName)
+		}
+		if m.Options != nil && m.Options.MapEntry {
+			// map fields are repeated entries of a key and a value
+			var val map[string]interface{}
+			for _, ef := range m.Field {
+				if ef.Name == "value" {
+					var err error
+					if val, err = g.field(typeName, ef); err != nil {
+						return nil, err
+					}
+				}
+			}
+			g.refs[msg] = append(g.refs[msg], g.refs[typeName]...)
+			return map[string]interface{}{"type": "object", "additionalProperties": val}, nil
+		}
+		g.refs[msg] = append(g.refs[msg], typeName)
+		value = map[string]interface{}{"$ref": "#/definitions/" + EscapePointerToken(typeName)}
+	default:
+		return nil, fmt.Errorf("%s.%s: unsupported field type %s", msg, f.Name, f.Type)
+	}
+	if f.Label == "LABEL_REPEATED" {
+		return map[string]interface{}{"type": "array", "items": value}, nil
+	}
+	return value, nil
+}
+
+// reachable adds the definitions of the message named name and those it
+// refers to to defs
+func (g *protoGenerator) reachable(name string, defs map[string]interface{}) {
+	if _, ok := defs[name]; ok {
+		return
+	}
+	defs[name] = g.defs[name]
+	refs := append([]string{}, g.refs[name]...)
+	sort.Strings(refs)
+	for _, ref := range refs {
+		g.reachable(ref, defs)
+	}
+}
+
+// protoJSONName gives the lowerCamelCase name protoc gives field name
+func protoJSONName(name string) string {
+	b := &strings.Builder{}
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
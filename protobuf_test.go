@@ -0,0 +1,99 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestGenerateProtoSchemas(t *testing.T) {
+	// a descriptor set for:
+	//
+	//	syntax = "proto3";
+	//	package pets.v1;
+	//	import "google/protobuf/timestamp.proto";
+	//	enum Kind { KIND_UNSPECIFIED = 0; KIND_DOG = 1; }
+	//	message Pet {
+	//	  string name = 1;
+	//	  int64 weight_grams = 2;
+	//	  Kind kind = 3;
+	//	  google.protobuf.Timestamp born_at = 4;
+	//	  repeated Pet friends = 5;
+	//	  map<string, Toy> toys = 6;
+	//	  oneof owner { string person = 7; string shelter = 8; }
+	//	  optional bool vaccinated = 9;
+	//	}
+	//	message Toy { bytes photo = 1; }
+	set := `{"file": [{
+		"name": "pets.proto",
+		"package": "pets.v1",
+		"enumType": [{"name": "Kind", "value": [{"name": "KIND_UNSPECIFIED", "number": 0}, {"name": "KIND_DOG", "number": 1}]}],
+		"messageType": [
+			{
+				"name": "Pet",
+				"field": [
+					{"name": "name", "number": 1, "label": "LABEL_OPTIONAL", "type": "TYPE_STRING", "jsonName": "name"},
+					{"name": "weight_grams", "number": 2, "label": "LABEL_OPTIONAL", "type": "TYPE_INT64", "jsonName": "weightGrams"},
+					{"name": "kind", "number": 3, "label": "LABEL_OPTIONAL", "type": "TYPE_ENUM", "typeName": ".pets.v1.Kind", "jsonName": "kind"},
+					{"name": "born_at", "number": 4, "label": "LABEL_OPTIONAL", "type": "TYPE_MESSAGE", "typeName": ".google.protobuf.Timestamp", "jsonName": "bornAt"},
+					{"name": "friends", "number": 5, "label": "LABEL_REPEATED", "type": "TYPE_MESSAGE", "typeName": ".pets.v1.Pet", "jsonName": "friends"},
+					{"name": "toys", "number": 6, "label": "LABEL_REPEATED", "type": "TYPE_MESSAGE", "typeName": ".pets.v1.Pet.ToysEntry", "jsonName": "toys"},
+					{"name": "person", "number": 7, "label": "LABEL_OPTIONAL", "type": "TYPE_STRING", "oneofIndex": 0, "jsonName": "person"},
+					{"name": "shelter", "number": 8, "label": "LABEL_OPTIONAL", "type": "TYPE_STRING", "oneofIndex": 0, "jsonName": "shelter"},
+					{"name": "vaccinated", "number": 9, "label": "LABEL_OPTIONAL", "type": "TYPE_BOOL", "oneofIndex": 1, "jsonName": "vaccinated", "proto3Optional": true}
+				],
+				"nestedType": [{
+					"name": "ToysEntry",
+					"field": [
+						{"name": "key", "number": 1, "label": "LABEL_OPTIONAL", "type": "TYPE_STRING", "jsonName": "key"},
+						{"name": "value", "number": 2, "label": "LABEL_OPTIONAL", "type": "TYPE_MESSAGE", "typeName": ".pets.v1.Toy", "jsonName": "value"}
+					],
+					"options": {"mapEntry": true}
+				}],
+				"oneofDecl": [{"name": "owner"}, {"name": "_vaccinated"}]
+			},
+			{"name": "Toy", "field": [{"name": "photo", "number": 1, "label": "LABEL_OPTIONAL", "type": "TYPE_BYTES", "jsonName": "photo"}]}
+		],
+		"syntax": "proto3"
+	}]}`
+
+	schemas, err := GenerateProtoSchemas([]byte(set))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 || schemas["pets.v1.Pet"] == nil || schemas["pets.v1.Toy"] == nil {
+		t.Fatalf("expected schemas for Pet and Toy, got %v", schemas)
+	}
+	if defs := schemas["pets.v1.Toy"].Definitions; len(defs) != 1 {
+		t.Errorf("expected Toy's schema to define only Toy, got %d definitions", len(defs))
+	}
+
+	pet := schemas["pets.v1.Pet"]
+	cases := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{}`, true},
+		{`{"name": "Rex", "weightGrams": "12000", "kind": "KIND_DOG", "bornAt": "2020-01-02T03:04:05Z", "vaccinated": true}`, true},
+		{`{"weight_grams": 12000, "kind": 1, "born_at": "2020-01-02T03:04:05Z"}`, true},
+		{`{"friends": [{"name": "Fido"}], "toys": {"ball": {"photo": "aGk="}}}`, true},
+		{`{"person": "Ann"}`, true},
+		{`{"person": "Ann", "shelter": "North"}`, false},
+		{`{"weightGrams": "heavy"}`, false},
+		{`{"kind": "KIND_CAT"}`, false},
+		{`{"friends": [{"nickname": "Fido"}]}`, false},
+		{`{"toys": {"ball": {"colour": "red"}}}`, false},
+		{`{"unknown": 1}`, false},
+	}
+	for _, c := range cases {
+		errs, err := pet.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(errs) == 0) != c.valid {
+			t.Errorf("%s: expected valid %t, got errors %v", c.doc, c.valid, errs)
+		}
+	}
+
+	if _, err := GenerateProtoSchemas([]byte(`{"file": [{"messageType": [{"name": "A", "field": [{"name": "b", "type": "TYPE_MESSAGE", "typeName": ".B"}]}]}]}`)); err == nil {
+		t.Errorf("expected an error for an unknown message")
+	}
+}
@@ -0,0 +1,122 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MessageValidator validates the payloads of message queues, such as Kafka
+// topics or the subjects of a schema registry, against schemas keyed by
+// topic or subject name. Producers validate payloads before sending them
+// with Validate, and consumers validate what they receive with Consume,
+// which diverts invalid payloads to a dead-letter callback. Counts of
+// the payloads seen are kept per topic. It's safe for concurrent use once
+// Schemas is populated
+type MessageValidator struct {
+	Schemas map[string]*RootSchema
+	// RequireSchema rejects the payloads of topics without a schema. By
+	// default they pass unvalidated
+	RequireSchema bool
+	// DeadLetter is given the payloads Consume rejects along with the reason,
+	// such as to publish them to a dead-letter topic. When nil Consume
+	// returns the reason instead
+	DeadLetter func(topic string, payload []byte, err error)
+
+	mu      sync.Mutex
+	metrics map[string]*MessageMetrics
+}
+
+// MessageMetrics counts the payloads of a topic a MessageValidator has seen
+type MessageMetrics struct {
+	Valid   uint64 `json:"valid"`
+	Invalid uint64 `json:"invalid"`
+	// Unvalidated counts the payloads of a topic without a schema
+	Unvalidated  uint64 `json:"unvalidated"`
+	DeadLettered uint64 `json:"deadLettered"`
+}
+
+// PayloadError is the error of a payload that failed validation
+type PayloadError struct {
+	Topic string
+	// Errors is empty when the payload isn't JSON or its topic has no schema
+	Errors []ValError
+	// Reason explains a payload rejected without validation errors
+	Reason string
+}
+
+// Error implements the error interface for PayloadError
+func (e *PayloadError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("invalid payload for %s: %s", e.Topic, e.Reason)
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid payload for %s: %s", e.Topic, strings.Join(msgs, "; "))
+}
+
+// Validate validates a payload of topic, giving a *PayloadError if it's
+// invalid
+func (v *MessageValidator) Validate(topic string, payload []byte) error {
+	rs := v.Schemas[topic]
+	if rs == nil {
+		if v.RequireSchema {
+			v.count(topic, func(m *MessageMetrics) { m.Invalid++ })
+			return &PayloadError{Topic: topic, Reason: "no schema for topic"}
+		}
+		v.count(topic, func(m *MessageMetrics) { m.Unvalidated++ })
+		return nil
+	}
+	errs, err := rs.ValidateBytes(payload)
+	if err != nil || len(errs) > 0 {
+		v.count(topic, func(m *MessageMetrics) { m.Invalid++ })
+		if err != nil {
+			return &PayloadError{Topic: topic, Reason: err.Error()}
+		}
+		return &PayloadError{Topic: topic, Errors: errs}
+	}
+	v.count(topic, func(m *MessageMetrics) { m.Valid++ })
+	return nil
+}
+
+// Consume validates a payload received from topic, passing it to handle if
+// it's valid and to DeadLetter otherwise. It returns the error of handle,
+// or the validation error when there's no DeadLetter
+func (v *MessageValidator) Consume(topic string, payload []byte, handle func(payload []byte) error) error {
+	if err := v.Validate(topic, payload); err != nil {
+		if v.DeadLetter == nil {
+			return err
+		}
+		v.count(topic, func(m *MessageMetrics) { m.DeadLettered++ })
+		v.DeadLetter(topic, payload, err)
+		return nil
+	}
+	return handle(payload)
+}
+
+// Metrics gives a snapshot of the counts of payloads seen, by topic
+func (v *MessageValidator) Metrics() map[string]MessageMetrics {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	snapshot := make(map[string]MessageMetrics, len(v.metrics))
+	for topic, m := range v.metrics {
+		snapshot[topic] = *m
+	}
+	return snapshot
+}
+
+func (v *MessageValidator) count(topic string, inc func(m *MessageMetrics)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.metrics == nil {
+		v.metrics = map[string]*MessageMetrics{}
+	}
+	m := v.metrics[topic]
+	if m == nil {
+		m = &MessageMetrics{}
+		v.metrics[topic] = m
+	}
+	inc(m)
+}
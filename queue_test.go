@@ -0,0 +1,72 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMessageValidator(t *testing.T) {
+	type letter struct {
+		topic   string
+		payload string
+	}
+	var dead []letter
+	v := &MessageValidator{
+		Schemas: map[string]*RootSchema{
+			"orders": Must(`{"required": ["id"], "properties": {"id": {"type": "integer"}}}`),
+		},
+		DeadLetter: func(topic string, payload []byte, err error) {
+			if _, ok := err.(*PayloadError); !ok {
+				t.Errorf("expected a *PayloadError, got %v", err)
+			}
+			dead = append(dead, letter{topic, string(payload)})
+		},
+	}
+
+	if err := v.Validate("orders", []byte(`{"id": 1}`)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := v.Validate("orders", []byte(`{"id": "1"}`))
+	if perr, ok := err.(*PayloadError); !ok || perr.Topic != "orders" || len(perr.Errors) != 1 {
+		t.Errorf("expected a payload error, got %v", err)
+	}
+	if err := v.Validate("clicks", []byte(`anything`)); err != nil {
+		t.Errorf("expected topics without a schema to pass, got %s", err)
+	}
+
+	handled := 0
+	handle := func(payload []byte) error {
+		handled++
+		return nil
+	}
+	for _, payload := range []string{`{"id": 2}`, `{}`, `not json`} {
+		if err := v.Consume("orders", []byte(payload), handle); err != nil {
+			t.Errorf("%s: unexpected error: %s", payload, err)
+		}
+	}
+	if handled != 1 {
+		t.Errorf("expected 1 payload handled, got %d", handled)
+	}
+	if !reflect.DeepEqual(dead, []letter{{"orders", `{}`}, {"orders", `not json`}}) {
+		t.Errorf("unexpected dead letters: %v", dead)
+	}
+
+	expect := map[string]MessageMetrics{
+		"orders": {Valid: 2, Invalid: 3, DeadLettered: 2},
+		"clicks": {Unvalidated: 1},
+	}
+	if got := v.Metrics(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected metrics %v, got %v", expect, got)
+	}
+
+	v.DeadLetter = nil
+	v.RequireSchema = true
+	if err := v.Consume("clicks", []byte(`{}`), handle); err == nil {
+		t.Errorf("expected an error for a topic without a schema")
+	}
+	boom := errors.New("boom")
+	if err := v.Consume("orders", []byte(`{"id": 3}`), func([]byte) error { return boom }); err != boom {
+		t.Errorf("expected the handler's error, got %v", err)
+	}
+}
@@ -0,0 +1,399 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Reflect builds a schema describing the JSON encoding of v's Go type, as
+// produced by the encoding/json package. Named struct types other than the
+// root are placed in "definitions" and referenced, so recursive types are
+// supported. Struct fields are required unless their json tag has
+// omitempty. Pointers, slices and maps below the root accept null, as nil
+// ones encode to it, and fields with the json tag's string option are
+// strings holding the encoding of their value.
+//
+// A "jsonschema" struct tag adds constraints Go types can't express, as a
+// comma-separated list of keyword=value pairs and flags:
+//
+//	Name string `json:"name" jsonschema:"minLength=1,pattern=^[a-z]+$,description=login name"`
+//	Role string `json:"role,omitempty" jsonschema:"enum=admin|user,default=user,required"`
+//
+// Supported keywords are title, description, format, pattern, comment,
+// minLength, maxLength, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, multipleOf, minItems, maxItems, minProperties,
+// maxProperties, enum (values separated by |), const, default and example,
+// plus the flags required, uniqueItems, readOnly and writeOnly. Values are
+// read as the field's JSON type. Commas in values are escaped as \,
+func Reflect(v interface{}) (*RootSchema, error) {
+	return ReflectType(reflect.TypeOf(v))
+}
+
+// ReflectType is like Reflect, but starts from a type
+func ReflectType(t reflect.Type) (*RootSchema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("can't reflect a schema from a nil type")
+	}
+	r := &reflector{refs: map[reflect.Type]string{}, defs: map[string]*SchemaBuilder{}}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		r.refs[t] = "#"
+	}
+
+	b, err := r.schemaFor(t, true)
+	if err != nil {
+		return nil, err
+	}
+	for name, def := range r.defs {
+		b.Definition(name, def)
+	}
+	return b.BuildRoot()
+}
+
+// reflector tracks the named types already given a definition
+type reflector struct {
+	refs map[reflect.Type]string
+	defs map[string]*SchemaBuilder
+}
+
+func (r *reflector) schemaFor(t reflect.Type, root bool) (*SchemaBuilder, error) {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	b, err := r.valueSchema(t, root)
+	if err != nil || root {
+		return b, err
+	}
+	if nullable || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		return withNull(b), nil
+	}
+	return b, nil
+}
+
+// withNull lets the schema b accept null as well
+func withNull(b *SchemaBuilder) *SchemaBuilder {
+	switch t := b.keywords["type"].(type) {
+	case string:
+		return b.Type(t, "null")
+	case []string:
+		return b.Type(append(t, "null")...)
+	}
+	if _, isRef := b.keywords["$ref"]; isRef {
+		return AnyOfSchema(b, Null())
+	}
+	// schemas without a type already accept null
+	return b
+}
+
+// quotedPatterns match the strings the json tag's string option encodes
+// values of each kind as
+var quotedPatterns = map[reflect.Kind]string{
+	reflect.Bool:    `^(true|false)$`,
+	reflect.Int:     `^-?(0|[1-9][0-9]*)$`,
+	reflect.Uint:    `^(0|[1-9][0-9]*)$`,
+	reflect.Float64: `^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`,
+	reflect.String:  `^"[\s\S]*"$`,
+}
+
+// quotedSchema describes values of t encoded with the json tag's string
+// option, which only applies to booleans, numbers and strings
+func quotedSchema(t reflect.Type) (*SchemaBuilder, bool) {
+	kind := t.Kind()
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		kind = reflect.Int
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		kind = reflect.Uint
+	case reflect.Float32:
+		kind = reflect.Float64
+	}
+	pattern, ok := quotedPatterns[kind]
+	if !ok || t == timeType {
+		return nil, false
+	}
+	return String().Pattern(pattern), true
+}
+
+// valueSchema describes the encoding of values of t, which isn't a pointer
+func (r *reflector) valueSchema(t reflect.Type, root bool) (*SchemaBuilder, error) {
+	switch {
+	case t == timeType:
+		return String().Format("date-time"), nil
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		// the encoding is up to the type
+		return NewSchema(), nil
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return String(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Integer().Minimum(0), nil
+	case reflect.Float32, reflect.Float64:
+		return Number(), nil
+	case reflect.String:
+		return String(), nil
+	case reflect.Interface:
+		return NewSchema(), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			// byte slices are encoded as base64 strings
+			return String(), nil
+		}
+		items, err := r.schemaFor(t.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		b := Array(items)
+		if t.Kind() == reflect.Array {
+			b.MinItems(t.Len()).MaxItems(t.Len())
+		}
+		return b, nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return nil, fmt.Errorf("unsupported map key type: %s", t.Key())
+			}
+		}
+		vals, err := r.schemaFor(t.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		return NewObject().AdditionalProperties(vals), nil
+	case reflect.Struct:
+		if t.Name() == "" || root {
+			return r.structSchema(t)
+		}
+		if ref, ok := r.refs[t]; ok {
+			return Ref(ref), nil
+		}
+		name := t.Name()
+		for i := 2; r.defs[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		ref := "#/definitions/" + EscapePointerToken(name)
+		r.refs[t] = ref
+		// reserve the name before reflecting fields, which may refer back to t
+		r.defs[name] = NewSchema()
+		def, err := r.structSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		r.defs[name] = def
+		return Ref(ref), nil
+	}
+	return nil, fmt.Errorf("unsupported type: %s", t)
+}
+
+// structSchema describes a struct's fields, including the fields of
+// embedded structs that encoding/json promotes
+func (r *reflector) structSchema(t reflect.Type) (*SchemaBuilder, error) {
+	b := NewObject()
+	seen := map[string]bool{}
+	if err := r.addFields(b, t, seen); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *reflector) addFields(b *SchemaBuilder, t reflect.Type, seen map[string]bool) error {
+	embedded := []reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		prop, err := r.schemaFor(f.Type, false)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", f.Name, err.Error())
+		}
+		required := true
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "omitempty":
+				required = false
+			case "string":
+				if quoted, ok := quotedSchema(ft); ok {
+					prop = quoted
+					if f.Type.Kind() == reflect.Ptr {
+						prop = withNull(prop)
+					}
+				}
+			}
+		}
+		if tag, ok := f.Tag.Lookup("jsonschema"); ok {
+			nullable := f.Type.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map
+			if prop, required, err = applySchemaTag(prop, ft, tag, required, nullable); err != nil {
+				return fmt.Errorf("field %s: %s", f.Name, err.Error())
+			}
+		}
+		b.Property(name, prop)
+		if required {
+			b.Required(name)
+		}
+	}
+
+	// fields of the outer struct take precedence over promoted fields
+	for _, et := range embedded {
+		if err := r.addFields(b, et, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applySchemaTag adds the constraints in a jsonschema struct tag to a
+// field's schema, and reports whether the field is required. Enums of
+// nullable fields include null
+func applySchemaTag(b *SchemaBuilder, t reflect.Type, tag string, required, nullable bool) (*SchemaBuilder, bool, error) {
+	if _, isRef := b.keywords["$ref"]; isRef {
+		// siblings of $ref are ignored, so constrain the reference in an allOf
+		b = NewSchema().AllOf(b)
+	}
+
+	for _, part := range splitTag(tag) {
+		key, val := part, ""
+		if idx := strings.Index(part, "="); idx != -1 {
+			key, val = part[:idx], part[idx+1:]
+		}
+
+		switch key {
+		case "required":
+			required = true
+		case "uniqueItems", "readOnly", "writeOnly":
+			b.Keyword(key, true)
+		case "title", "description", "format", "pattern":
+			b.Keyword(key, val)
+		case "comment":
+			b.Comment(val)
+		case "minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid %s: %q", key, val)
+			}
+			b.Keyword(key, n)
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid %s: %q", key, val)
+			}
+			b.Keyword(key, n)
+		case "enum":
+			vals := []interface{}{}
+			for _, str := range strings.Split(val, "|") {
+				v, err := tagValue(t, str)
+				if err != nil {
+					return nil, false, fmt.Errorf("invalid enum value: %s", err.Error())
+				}
+				vals = append(vals, v)
+			}
+			if nullable {
+				vals = append(vals, nil)
+			}
+			b.Enum(vals...)
+		case "const", "default", "example":
+			v, err := tagValue(t, val)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid %s: %s", key, err.Error())
+			}
+			switch key {
+			case "example":
+				exs, _ := b.keywords["examples"].([]interface{})
+				b.Examples(append(exs, v)...)
+			default:
+				b.Keyword(key, v)
+			}
+		default:
+			return nil, false, fmt.Errorf("unknown jsonschema tag key: %q", key)
+		}
+	}
+	return b, required, nil
+}
+
+// splitTag splits a jsonschema tag on commas that aren't escaped
+func splitTag(tag string) []string {
+	parts := []string{}
+	cur := strings.Builder{}
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			cur.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(tag[i])
+		}
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
+
+// tagValue reads a value from a struct tag as the JSON type of t
+func tagValue(t reflect.Type, str string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(str, 64)
+	case reflect.String:
+		return str, nil
+	}
+	// other types take JSON values
+	var v interface{}
+	if err := json.Unmarshal([]byte(str), &v); err != nil {
+		return nil, fmt.Errorf("%q isn't valid JSON", str)
+	}
+	return v, nil
+}
@@ -0,0 +1,137 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type reflectAudit struct {
+	Created time.Time `json:"created"`
+	By      string    `json:"by,omitempty"`
+}
+
+type reflectNode struct {
+	reflectAudit
+	Name     string            `json:"name" jsonschema:"minLength=1,pattern=^[a-z]+$,description=lower\\, no digits"`
+	Role     string            `json:"role,omitempty" jsonschema:"enum=admin|user,default=user,required"`
+	Score    *float64          `json:"score,omitempty" jsonschema:"minimum=0,exclusiveMaximum=10"`
+	Count    uint8             `json:"count"`
+	Tags     []string          `json:"tags,omitempty" jsonschema:"uniqueItems,maxItems=3"`
+	Data     []byte            `json:"data,omitempty"`
+	Pair     [2]int            `json:"pair,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []*reflectNode    `json:"children,omitempty"`
+	Parent   *reflectLink      `json:"parent,omitempty" jsonschema:"description=owning node"`
+	Extra    interface{}       `json:"extra,omitempty"`
+	Skipped  string            `json:"-"`
+	hidden   string
+}
+
+type reflectLink struct {
+	Node *reflectNode `json:"node"`
+}
+
+func TestReflect(t *testing.T) {
+	rs, err := Reflect(&reflectNode{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"definitions":{"reflectLink":{"properties":{"node":{"anyOf":[{"$ref":"#"},{"type":"null"}]}},"required":["node"],"type":"object"}},"properties":{"by":{"type":"string"},"children":{"items":{"anyOf":[{"$ref":"#"},{"type":"null"}]},"type":["array","null"]},"count":{"minimum":0,"type":"integer"},"created":{"format":"date-time","type":"string"},"data":{"type":["string","null"]},"extra":{},"labels":{"additionalProperties":{"type":"string"},"type":["object","null"]},"name":{"description":"lower, no digits","minLength":1,"pattern":"^[a-z]+$","type":"string"},"pair":{"items":{"type":"integer"},"maxItems":2,"minItems":2,"type":"array"},"parent":{"anyOf":[{"$ref":"#/definitions/reflectLink"},{"type":"null"}],"description":"owning node"},"role":{"default":"user","enum":["admin","user"],"type":"string"},"score":{"exclusiveMaximum":10,"minimum":0,"type":["number","null"]},"tags":{"items":{"type":"string"},"maxItems":3,"type":["array","null"],"uniqueItems":true}},"required":["name","role","count","created"],"type":"object"}`
+	if string(got) != expect {
+		t.Errorf("encoding mismatch.\nexpected: %s\ngot:      %s", expect, got)
+	}
+
+	score := 3.5
+	doc, err := json.Marshal(reflectNode{Name: "root", Role: "admin", Score: &score, Children: []*reflectNode{{Name: "leaf", Role: "user"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := rs.ValidateBytes(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected an encoded value to be valid, got errors: %v", errs)
+	}
+
+	errs, err = rs.ValidateBytes([]byte(`{ "name": "Root", "role": "guest", "count": 1, "created": "2020-01-01T00:00:00Z" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got: %v", errs)
+	}
+}
+
+type reflectZero struct {
+	P     *int              `json:"p"`
+	S     []string          `json:"s"`
+	M     map[string]int    `json:"m"`
+	N     int               `json:"n,string"`
+	U     *uint8            `json:"u,string"`
+	F     float64           `json:"f,string"`
+	B     bool              `json:"b,string"`
+	Q     string            `json:"q,string"`
+	Kind  *string           `json:"kind" jsonschema:"enum=a|b"`
+	Link  *reflectLink      `json:"link"`
+	Any   interface{}       `json:"any"`
+	Links []*reflectLink    `json:"links"`
+	Raw   json.RawMessage   `json:"raw,omitempty"`
+	Times map[string]string `json:"times"`
+}
+
+func TestReflectZeroValue(t *testing.T) {
+	rs, err := Reflect(reflectZero{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kind, u := "a", uint8(7)
+	for _, v := range []reflectZero{
+		{},
+		{N: -12, U: &u, F: 1.5e-7, B: true, Q: `say "hi"`, Kind: &kind, S: []string{}, Links: []*reflectLink{nil, {}}},
+	} {
+		doc, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, err := rs.ValidateBytes(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 0 {
+			t.Errorf("expected %s to be valid, got errors: %v", doc, errs)
+		}
+	}
+
+	errs, _ := rs.ValidateBytes([]byte(`{"p": null, "s": null, "m": null, "n": 0, "u": "x", "f": "1.", "b": "yes", "q": "q", "kind": "c", "link": null, "any": null, "links": null, "times": null}`))
+	if len(errs) != 6 {
+		t.Errorf("expected 6 errors, got: %v", errs)
+	}
+}
+
+func TestReflectTagErrors(t *testing.T) {
+	cases := []interface{}{
+		struct {
+			A string `jsonschema:"minLength=x"`
+		}{},
+		struct {
+			A int `jsonschema:"enum=1|a"`
+		}{},
+		struct {
+			A int `jsonschema:"bogus=1"`
+		}{},
+		struct {
+			A chan int
+		}{},
+	}
+	for i, c := range cases {
+		if _, err := Reflect(c); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
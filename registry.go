@@ -0,0 +1,225 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LatestVersion asks a schema registry for the latest version of a subject
+const LatestVersion = -1
+
+// RegistryClient loads JSON schemas from a schema registry compatible with
+// Confluent's REST API. Schemas that reference other subjects through the
+// registry's reference mechanism are bundled with the schemas they
+// reference, so they validate on their own. Responses for specific versions
+// and IDs never change, so they're cached. It's safe for concurrent use
+type RegistryClient struct {
+	// URL is the base URL of the registry
+	URL string
+	// Client makes requests to the registry, http.DefaultClient if nil
+	Client *http.Client
+	// Username and Password authenticate requests with basic auth when set
+	Username string
+	Password string
+
+	mu        sync.Mutex
+	responses map[string]*registryResponse
+	schemas   map[string]*RegistrySchema
+}
+
+// NewRegistryClient creates a client for the registry at baseURL
+func NewRegistryClient(baseURL string) *RegistryClient {
+	return &RegistryClient{URL: baseURL}
+}
+
+// RegistrySchema is a schema loaded from a registry. Schemas are cached and
+// shared between callers, so they shouldn't be modified
+type RegistrySchema struct {
+	// Subject and Version are unset for schemas loaded by ID
+	Subject string
+	Version int
+	// ID is the registry's global identifier of the schema
+	ID     int
+	Schema *RootSchema
+}
+
+// registryResponse is a schema as the registry gives it
+type registryResponse struct {
+	Subject    string              `json:"subject"`
+	Version    int                 `json:"version"`
+	ID         int                 `json:"id"`
+	SchemaType string              `json:"schemaType"`
+	Schema     string              `json:"schema"`
+	References []registryReference `json:"references"`
+}
+
+// registryReference names another subject's schema. Name is how the
+// referencing schema refers to it in "$ref"
+type registryReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Schema loads version version of subject, or its latest version for
+// LatestVersion
+func (c *RegistryClient) Schema(subject string, version int) (*RegistrySchema, error) {
+	return c.load(registryVersionPath(subject, version))
+}
+
+// SchemaByID loads the schema with the registry's global identifier id, as
+// given in messages serialized with the registry's wire format
+func (c *RegistryClient) SchemaByID(id int) (*RegistrySchema, error) {
+	rs, err := c.load("/schemas/ids/" + strconv.Itoa(id))
+	if err != nil {
+		return nil, err
+	}
+	rs.ID = id
+	return rs, nil
+}
+
+func registryVersionPath(subject string, version int) string {
+	v := "latest"
+	if version != LatestVersion {
+		v = strconv.Itoa(version)
+	}
+	return "/subjects/" + url.PathEscape(subject) + "/versions/" + v
+}
+
+// load loads and compiles the schema at path in the registry
+func (c *RegistryClient) load(path string) (*RegistrySchema, error) {
+	c.mu.Lock()
+	cached := c.schemas[path]
+	c.mu.Unlock()
+	if cached != nil {
+		loaded := *cached
+		return &loaded, nil
+	}
+
+	res, err := c.get(path)
+	if err != nil {
+		return nil, err
+	}
+	if res.SchemaType != "JSON" {
+		schemaType := res.SchemaType
+		if schemaType == "" {
+			// the registry leaves out the type of Avro schemas
+			schemaType = "AVRO"
+		}
+		return nil, fmt.Errorf("registry schema %s is %s, not JSON", path, schemaType)
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal([]byte(res.Schema), rs); err != nil {
+		return nil, fmt.Errorf("parsing registry schema %s: %s", path, err.Error())
+	}
+	if len(res.References) > 0 {
+		base, err := url.Parse(rs.ID)
+		if err != nil {
+			return nil, fmt.Errorf("registry schema %s has an invalid $id: %s", path, err.Error())
+		}
+		docs := map[string][]byte{}
+		if err := c.collectReferences(res.References, base, docs); err != nil {
+			return nil, err
+		}
+		if rs, err = Bundle(rs, BundleOptions{BaseURI: rs.ID, Documents: docs}); err != nil {
+			return nil, fmt.Errorf("bundling registry schema %s: %s", path, err.Error())
+		}
+	}
+
+	loaded := &RegistrySchema{Subject: res.Subject, Version: res.Version, ID: res.ID, Schema: rs}
+	if !strings.HasSuffix(path, "/latest") {
+		c.mu.Lock()
+		if c.schemas == nil {
+			c.schemas = map[string]*RegistrySchema{}
+		}
+		c.schemas[path] = loaded
+		c.mu.Unlock()
+	}
+	shared := *loaded
+	return &shared, nil
+}
+
+// collectReferences loads the schemas refs name, and those they reference
+// in turn, into docs keyed by their names resolved against base, the URL
+// of the schema referencing them
+func (c *RegistryClient) collectReferences(refs []registryReference, base *url.URL, docs map[string][]byte) error {
+	for _, ref := range refs {
+		u, err := url.Parse(ref.Name)
+		if err != nil {
+			return fmt.Errorf("invalid reference name %q: %s", ref.Name, err.Error())
+		}
+		u = base.ResolveReference(u)
+		key := bundleDocURL(u)
+		if _, ok := docs[key]; ok {
+			continue
+		}
+		res, err := c.get(registryVersionPath(ref.Subject, ref.Version))
+		if err != nil {
+			return err
+		}
+		docs[key] = []byte(res.Schema)
+		if err := c.collectReferences(res.References, u, docs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get requests path from the registry, caching responses other than those
+// for latest versions
+func (c *RegistryClient) get(path string) (*registryResponse, error) {
+	c.mu.Lock()
+	cached := c.responses[path]
+	c.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(c.URL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json, application/json")
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpRes, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+	if httpRes.StatusCode != http.StatusOK {
+		// errors come as {"error_code": 40401, "message": "Subject not found."}
+		body := struct {
+			Message string `json:"message"`
+		}{}
+		json.NewDecoder(httpRes.Body).Decode(&body)
+		if body.Message == "" {
+			body.Message = httpRes.Status
+		}
+		return nil, fmt.Errorf("registry request %s failed: %s", path, body.Message)
+	}
+	res := &registryResponse{}
+	if err := json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return nil, fmt.Errorf("decoding registry response %s: %s", path, err.Error())
+	}
+
+	if !strings.HasSuffix(path, "/latest") {
+		c.mu.Lock()
+		if c.responses == nil {
+			c.responses = map[string]*registryResponse{}
+		}
+		c.responses[path] = res
+		c.mu.Unlock()
+	}
+	return res, nil
+}
@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryClient(t *testing.T) {
+	responses := map[string]interface{}{
+		"/subjects/order/versions/1": map[string]interface{}{
+			"subject": "order", "version": 1, "id": 10, "schemaType": "JSON",
+			"schema":     `{"type": "object", "properties": {"customer": {"$ref": "customer.json"}}, "required": ["customer"]}`,
+			"references": []map[string]interface{}{{"name": "customer.json", "subject": "customer", "version": 2}},
+		},
+		"/subjects/customer/versions/2": map[string]interface{}{
+			"subject": "customer", "version": 2, "id": 11, "schemaType": "JSON",
+			"schema":     `{"type": "object", "properties": {"address": {"$ref": "address.json#/definitions/address"}}}`,
+			"references": []map[string]interface{}{{"name": "address.json", "subject": "address", "version": 1}},
+		},
+		"/subjects/address/versions/1": map[string]interface{}{
+			"subject": "address", "version": 1, "id": 12, "schemaType": "JSON",
+			"schema": `{"definitions": {"address": {"required": ["city"]}}}`,
+		},
+		"/subjects/order/versions/latest": map[string]interface{}{
+			"subject": "order", "version": 2, "id": 13, "schemaType": "JSON",
+			"schema": `{"type": "object"}`,
+		},
+		"/schemas/ids/12": map[string]interface{}{
+			"schemaType": "JSON",
+			"schema":     `{"type": "string"}`,
+		},
+		"/subjects/avro/versions/1": map[string]interface{}{
+			"subject": "avro", "version": 1, "id": 14,
+			"schema": `{"type": "record", "name": "x", "fields": []}`,
+		},
+	}
+	requests := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		if user, pass, _ := r.BasicAuth(); user != "key" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 40401, "message": "Subject not found."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	c := NewRegistryClient(srv.URL + "/")
+	c.Username, c.Password = "key", "secret"
+
+	for i := 0; i < 2; i++ {
+		order, err := c.Schema("order", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order.Subject != "order" || order.Version != 1 || order.ID != 10 {
+			t.Errorf("unexpected schema info: %+v", order)
+		}
+		errs, err := order.Schema.ValidateBytes([]byte(`{"customer": {"address": {}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Message, `"city" value is required`) {
+			t.Errorf("expected the referenced schemas to apply, got %v", errs)
+		}
+	}
+	for path, n := range requests {
+		if n != 1 {
+			t.Errorf("expected %s to be requested once, got %d", path, n)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if latest, err := c.Schema("order", LatestVersion); err != nil || latest.Version != 2 {
+			t.Errorf("unexpected latest version: %+v, %v", latest, err)
+		}
+	}
+	if requests["/subjects/order/versions/latest"] != 2 {
+		t.Errorf("expected latest versions not to be cached")
+	}
+
+	byID, err := c.SchemaByID(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byID.ID != 12 || byID.Schema.TopLevelType() != "string" {
+		t.Errorf("unexpected schema by ID: %+v", byID)
+	}
+
+	if _, err := c.Schema("missing", 1); err == nil || !strings.Contains(err.Error(), "Subject not found.") {
+		t.Errorf("expected the registry's error message, got %v", err)
+	}
+	if _, err := c.Schema("avro", 1); err == nil || !strings.Contains(err.Error(), "AVRO") {
+		t.Errorf("expected an error for a non-JSON schema, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RelativePointer is a Relative JSON Pointer, which locates a value
+// relative to another location within the same document
+type RelativePointer struct {
+	// Up is the number of levels to move up from the starting location
+	Up int
+	// IndexOffset moves the location, once up, between the elements of the
+	// array holding it
+	IndexOffset int
+	// Key gives the property name or array index of the location instead
+	// of its value, as a trailing "#" does
+	Key bool
+	// Pointer is followed from the location when Key isn't set
+	Pointer Pointer
+}
+
+// ParseRelativePointer parses a Relative JSON Pointer, such as "0/foo",
+// "2#" or "1+1/name". Index manipulation, the "+1" of the latter, is the
+// addition of draft-bhutton-relative-json-pointer-00
+func ParseRelativePointer(str string) (*RelativePointer, error) {
+	end := 0
+	for end < len(str) && str[end] >= '0' && str[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return nil, fmt.Errorf("relative JSON pointers must begin with a non-negative integer")
+	}
+	if end > 1 && str[0] == '0' {
+		return nil, fmt.Errorf("leading zeros aren't allowed")
+	}
+	up, err := strconv.Atoi(str[:end])
+	if err != nil {
+		return nil, fmt.Errorf("invalid level %q", str[:end])
+	}
+	p := &RelativePointer{Up: up}
+	rest := str[end:]
+
+	if rest != "" && (rest[0] == '+' || rest[0] == '-') {
+		n := 1
+		for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+			n++
+		}
+		if n == 1 || (n > 2 && rest[1] == '0') || rest[1:n] == "0" {
+			return nil, fmt.Errorf("invalid index manipulation %q", rest[:n])
+		}
+		offset, err := strconv.Atoi(rest[:n])
+		if err != nil {
+			return nil, fmt.Errorf("invalid index manipulation %q", rest[:n])
+		}
+		p.IndexOffset = offset
+		rest = rest[n:]
+	}
+
+	if rest == "#" {
+		p.Key = true
+		return p, nil
+	}
+	if err := isValidJSONPointer(rest); err != nil {
+		return nil, err
+	}
+	if p.Pointer, err = ParsePointer(rest); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// String gives the relative pointer in its string form
+func (p *RelativePointer) String() string {
+	str := strconv.Itoa(p.Up)
+	if p.IndexOffset > 0 {
+		str += "+"
+	}
+	if p.IndexOffset != 0 {
+		str += strconv.Itoa(p.IndexOffset)
+	}
+	if p.Key {
+		return str + "#"
+	}
+	return str + p.Pointer.String()
+}
+
+// Eval evaluates the relative pointer within doc, starting from the value
+// at the JSON pointer from. Key pointers give property names as strings and
+// array indexes as numbers
+func (p *RelativePointer) Eval(doc interface{}, from string) (interface{}, error) {
+	tokens, err := p.Location(from)
+	if err != nil {
+		return nil, err
+	}
+	if p.IndexOffset != 0 {
+		parent, err := tokens[:len(tokens)-1].Eval(doc)
+		if err != nil {
+			return nil, err
+		}
+		arr, ok := parent.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: %q isn't an array element", p, from)
+		}
+		if i, _ := strconv.Atoi(tokens[len(tokens)-1]); i >= len(arr) {
+			return nil, fmt.Errorf("%s moves past the end of the array", p)
+		}
+	}
+	if p.Key {
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("the document root has no name or index")
+		}
+		parent, err := tokens[:len(tokens)-1].Eval(doc)
+		if err != nil {
+			return nil, err
+		}
+		last := tokens[len(tokens)-1]
+		if _, ok := parent.([]interface{}); ok {
+			i, _ := strconv.Atoi(last)
+			return float64(i), nil
+		}
+		return last, nil
+	}
+	return append(tokens, p.Pointer...).Eval(doc)
+}
+
+// Location gives the JSON pointer the relative pointer moves up to from
+// the JSON pointer from, before any reference tokens are followed
+func (p *RelativePointer) Location(from string) (Pointer, error) {
+	if from == "/" {
+		from = ""
+	}
+	tokens, err := ParsePointer(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid starting pointer %q: %s", from, err.Error())
+	}
+	if p.Up > len(tokens) {
+		return nil, fmt.Errorf("%s moves above the document root from %q", p, from)
+	}
+	tokens = append(Pointer{}, tokens[:len(tokens)-p.Up]...)
+	if p.IndexOffset != 0 {
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("the document root isn't an array element")
+		}
+		i, err := strconv.Atoi(tokens[len(tokens)-1])
+		if err != nil || strings.HasPrefix(tokens[len(tokens)-1], "+") {
+			return nil, fmt.Errorf("%q isn't an array index", tokens[len(tokens)-1])
+		}
+		if i += p.IndexOffset; i < 0 {
+			return nil, fmt.Errorf("%s moves before the start of the array", p)
+		}
+		tokens[len(tokens)-1] = strconv.Itoa(i)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRelativePointerEval(t *testing.T) {
+	// examples from the Relative JSON Pointer draft, section 5.1
+	var doc interface{}
+	json.Unmarshal([]byte(`{
+		"foo": ["bar", "baz", "biz"],
+		"highly": {"nested": {"objects": true}}
+	}`), &doc)
+
+	cases := []struct {
+		from, ptr string
+		expect    interface{}
+	}{
+		{"/foo/1", "0", "baz"},
+		{"/foo/1", "1/0", "bar"},
+		{"/foo/1", "0-1", "bar"},
+		{"/foo/1", "0+1", "biz"},
+		{"/foo/1", "2/highly/nested/objects", true},
+		{"/foo/1", "0#", 1.0},
+		{"/foo/1", "0-1#", 0.0},
+		{"/foo/1", "1#", "foo"},
+		{"/highly/nested", "0/objects", true},
+		{"/highly/nested", "1/nested/objects", true},
+		{"/highly/nested", "2/foo/0", "bar"},
+		{"/highly/nested", "0#", "nested"},
+		{"/highly/nested", "1#", "highly"},
+	}
+	for _, c := range cases {
+		p, err := ParseRelativePointer(c.ptr)
+		if err != nil {
+			t.Errorf("%s: %s", c.ptr, err.Error())
+			continue
+		}
+		got, err := p.Eval(doc, c.from)
+		if err != nil {
+			t.Errorf("%s from %s: %s", c.ptr, c.from, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("%s from %s: expected %v, got %v", c.ptr, c.from, c.expect, got)
+		}
+		if p.String() != c.ptr {
+			t.Errorf("%s: expected String to give it back, got %s", c.ptr, p.String())
+		}
+	}
+
+	errCases := []struct {
+		from, ptr string
+	}{
+		{"/foo/1", "3"},
+		{"/foo/0", "0-1"},
+		{"/foo/2", "0+1"},
+		{"/highly/nested", "0+1"},
+		{"", "0#"},
+		{"/foo/1", "0/missing"},
+	}
+	for _, c := range errCases {
+		p, err := ParseRelativePointer(c.ptr)
+		if err != nil {
+			t.Errorf("%s: %s", c.ptr, err.Error())
+			continue
+		}
+		if _, err := p.Eval(doc, c.from); err == nil {
+			t.Errorf("%s from %s: expected an error", c.ptr, c.from)
+		}
+	}
+}
+
+func TestParseRelativePointerErrors(t *testing.T) {
+	for _, ptr := range []string{"", "/foo", "01", "-1", "0+", "0+01", "0-0", "0##", "0/a~2", "1foo", "0#/a"} {
+		if _, err := ParseRelativePointer(ptr); err == nil {
+			t.Errorf("%q: expected an error", ptr)
+		}
+	}
+}
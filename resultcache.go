@@ -0,0 +1,158 @@
+package jsonschema
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+)
+
+// ResultCache is a least-recently-used cache of validation results, keyed
+// by a hash of the instance and the fingerprint of the schema, so that hot,
+// identical payloads such as heartbeat messages skip validation. Equivalent
+// schemas share results as long as they share options too, as schemas
+// without options set do: results are keyed by the options of schemas as
+// well, so options set with SetOptions are only shared by the schema they
+// were set for. Fingerprints are worked out once per schema, so schemas
+// mustn't be changed once used with a cache. Validations by schemas with
+// keyword hooks aren't cached. It's safe for concurrent use
+type ResultCache struct {
+	size int
+
+	mu           sync.Mutex
+	order        *list.List
+	entries      map[resultKey]*list.Element
+	fingerprints map[*RootSchema][32]byte
+}
+
+// resultKey identifies the validation of an instance by a schema with its
+// options. Raw instances are hashed as they're encoded, so errors can hold
+// lines
+type resultKey struct {
+	schema   [32]byte
+	opts     *Options
+	instance [sha256.Size]byte
+	raw      bool
+}
+
+// resultEntry is a cached result
+type resultEntry struct {
+	key  resultKey
+	errs []ValError
+}
+
+// NewResultCache creates a cache holding the results of up to size
+// validations
+func NewResultCache(size int) *ResultCache {
+	return &ResultCache{
+		size:         size,
+		order:        list.New(),
+		entries:      map[resultKey]*list.Element{},
+		fingerprints: map[*RootSchema][32]byte{},
+	}
+}
+
+// Validate validates data with rs, as rs.Validate does from the root,
+// reusing the result of an identical instance
+func (c *ResultCache) Validate(rs *RootSchema, data interface{}) []ValError {
+	raw, err := json.Marshal(data)
+	if err != nil || len(rs.options().Hooks) > 0 {
+		errs := []ValError{}
+		rs.Validate("/", data, &errs)
+		return errs
+	}
+	key := resultKey{schema: c.fingerprint(rs), opts: rs.options(), instance: sha256.Sum256(raw)}
+	if errs, ok := c.get(key); ok {
+		return errs
+	}
+	errs := []ValError{}
+	rs.Validate("/", data, &errs)
+	c.add(key, errs)
+	return errs
+}
+
+// ValidateBytes validates the JSON document data with rs, as
+// rs.ValidateBytes does. Documents identical to one already validated
+// aren't decoded at all
+func (c *ResultCache) ValidateBytes(rs *RootSchema, data []byte) ([]ValError, error) {
+	if len(rs.options().Hooks) > 0 {
+		return rs.ValidateBytes(data)
+	}
+	key := resultKey{schema: c.fingerprint(rs), opts: rs.options(), instance: sha256.Sum256(data), raw: true}
+	if errs, ok := c.get(key); ok {
+		return errs, nil
+	}
+	errs, err := rs.ValidateBytes(data)
+	if err != nil {
+		return errs, err
+	}
+	c.add(key, errs)
+	return errs, nil
+}
+
+// Len gives the number of results cached
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Purge drops every cached result and fingerprint
+func (c *ResultCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = map[resultKey]*list.Element{}
+	c.fingerprints = map[*RootSchema][32]byte{}
+}
+
+// fingerprint gives the fingerprint of rs, working it out the first time
+func (c *ResultCache) fingerprint(rs *RootSchema) [32]byte {
+	c.mu.Lock()
+	fp, ok := c.fingerprints[rs]
+	c.mu.Unlock()
+	if ok {
+		return fp
+	}
+	fp = rs.Fingerprint()
+	c.mu.Lock()
+	c.fingerprints[rs] = fp
+	c.mu.Unlock()
+	return fp
+}
+
+// get gives a copy of the result for key, marking it recently used
+func (c *ResultCache) get(key resultKey) ([]ValError, bool) {
+	if key.schema == ([32]byte{}) {
+		// the schema couldn't be fingerprinted
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return append([]ValError{}, el.Value.(*resultEntry).errs...), true
+}
+
+// add caches a copy of errs for key, evicting the least recently used
+// result when the cache is full
+func (c *ResultCache) add(key resultKey, errs []ValError) {
+	if c.size <= 0 || key.schema == ([32]byte{}) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&resultEntry{key: key, errs: append([]ValError{}, errs...)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultEntry).key)
+	}
+}
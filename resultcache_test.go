@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestResultCache(t *testing.T) {
+	RegisterValidator("countCalls", func() Validator { return new(countCalls) })
+	defer delete(DefaultValidators, "countCalls")
+
+	rs := Must(`{"countCalls": 0, "properties": {"seq": {"type": "integer"}}}`)
+	same := Must(`{"properties": {"seq": {"type": "integer"}}, "countCalls": 0}`)
+	cache := NewResultCache(2)
+	countedCalls = 0
+
+	ok := map[string]interface{}{"seq": 1.0}
+	bad := map[string]interface{}{"seq": "x"}
+	if errs := cache.Validate(rs, ok); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	if errs := cache.Validate(rs, bad); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+	if errs := cache.Validate(rs, map[string]interface{}{"seq": "x"}); len(errs) != 1 || errs[0].PropertyPath != "/seq" {
+		t.Errorf("expected the cached error, got %v", errs)
+	}
+	if errs := cache.Validate(same, ok); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	if countedCalls != 2 {
+		t.Errorf("expected 2 validations, got %d", countedCalls)
+	}
+
+	// the least recently used result is evicted
+	cache.Validate(rs, map[string]interface{}{"seq": 2.0})
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 cached results, got %d", cache.Len())
+	}
+	cache.Validate(rs, bad)
+	if countedCalls != 4 {
+		t.Errorf("expected the evicted result to be validated again, got %d validations", countedCalls)
+	}
+
+	doc := []byte(`{"seq": "x"}`)
+	for i := 0; i < 2; i++ {
+		errs, err := cache.ValidateBytes(rs, doc)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(errs) != 1 {
+			t.Errorf("expected 1 error, got %v", errs)
+		}
+	}
+	if countedCalls != 5 {
+		t.Errorf("expected an identical document to be validated once, got %d validations", countedCalls)
+	}
+	if _, err := cache.ValidateBytes(rs, []byte(`{`)); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("expected an empty cache after purging, got %d results", cache.Len())
+	}
+}
+
+func TestResultCacheOptions(t *testing.T) {
+	strict := Must(`{"format": "date-time"}`)
+	lenient := Must(`{"format": "date-time"}`)
+	if err := lenient.UseFormatProfile("lenient"); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewResultCache(10)
+
+	if errs := cache.Validate(strict, "2020-01-01 10:00:00"); len(errs) != 1 {
+		t.Errorf("expected the strict schema to reject the date-time, got %v", errs)
+	}
+	if errs := cache.Validate(lenient, "2020-01-01 10:00:00"); len(errs) != 0 {
+		t.Errorf("expected the lenient schema to accept the date-time, got %v", errs)
+	}
+
+	exact := Must(`{"maximum": 9007199254740992}`)
+	exact.SetOptions(Options{Decoding: DecodeOptions{UseNumber: true}})
+	doc := []byte(`9007199254740993`)
+	if errs, _ := cache.ValidateBytes(Must(`{"maximum": 9007199254740992}`), doc); len(errs) != 0 {
+		t.Errorf("expected float64 decoding to lose the excess, got %v", errs)
+	}
+	if errs, _ := cache.ValidateBytes(exact, doc); len(errs) != 1 {
+		t.Errorf("expected exact decoding to exceed the maximum, got %v", errs)
+	}
+}
@@ -0,0 +1,85 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MethodSchemas validates the requests of RPC methods carrying JSON, as
+// grpc-gateway transcodes them, against schemas keyed by full method name
+// such as "/pets.v1.PetService/CreatePet". It has no gRPC dependency, so
+// it's plugged into a gRPC server with a unary interceptor:
+//
+//	grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+//		if err := methods.Validate(info.FullMethod, req); err != nil {
+//			return nil, status.Error(codes.InvalidArgument, err.Error())
+//		}
+//		return handler(ctx, req)
+//	})
+//
+// or into a gateway as HTTP middleware with Selector
+type MethodSchemas struct {
+	Schemas map[string]*RootSchema
+	// Marshal encodes request messages as JSON, json.Marshal if nil. Set it
+	// to protojson.Marshal for protobuf messages so field names match the
+	// JSON the schemas describe
+	Marshal func(req interface{}) ([]byte, error)
+}
+
+// MethodError is the error of a request that failed validation
+type MethodError struct {
+	Method string
+	Errors []ValError
+}
+
+// Error implements the error interface for MethodError
+func (e *MethodError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid request to %s: %s", e.Method, strings.Join(msgs, "; "))
+}
+
+// Validate validates req, a request to the method named by its full name,
+// giving a *MethodError if it's invalid. Methods without a schema accept
+// any request
+func (m *MethodSchemas) Validate(method string, req interface{}) error {
+	rs := m.Schemas[method]
+	if rs == nil {
+		return nil
+	}
+	marshal := m.Marshal
+	if marshal == nil {
+		marshal = json.Marshal
+	}
+	data, err := marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding request to %s: %s", method, err.Error())
+	}
+	errs, err := rs.ValidateBytes(data)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return &MethodError{Method: method, Errors: errs}
+	}
+	return nil
+}
+
+// Selector gives a SchemaSelector for RouteMiddleware validating request
+// bodies against the schema of the method methodOf names for a request,
+// such as runtime.RPCMethod of grpc-gateway in a middleware registered
+// with runtime.WithMiddlewares. Bodies are validated as received, before
+// transcoding, and requests without a method or schema aren't validated
+func (m *MethodSchemas) Selector(methodOf func(r *http.Request) string) SchemaSelector {
+	return func(r *http.Request) *RouteSchemas {
+		rs := m.Schemas[methodOf(r)]
+		if rs == nil {
+			return nil
+		}
+		return &RouteSchemas{Body: rs}
+	}
+}
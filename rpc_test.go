@@ -0,0 +1,70 @@
+package jsonschema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodSchemas(t *testing.T) {
+	type createPet struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	methods := &MethodSchemas{Schemas: map[string]*RootSchema{
+		"/pets.v1.PetService/CreatePet": Must(`{"properties": {"name": {"minLength": 1}, "age": {"minimum": 0}}}`),
+	}}
+
+	if err := methods.Validate("/pets.v1.PetService/CreatePet", createPet{Name: "Rex", Age: 3}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := methods.Validate("/pets.v1.PetService/ListPets", createPet{}); err != nil {
+		t.Errorf("expected methods without a schema to accept anything, got: %s", err)
+	}
+	err := methods.Validate("/pets.v1.PetService/CreatePet", createPet{Age: -1})
+	merr, ok := err.(*MethodError)
+	if !ok {
+		t.Fatalf("expected a *MethodError, got %v", err)
+	}
+	if merr.Method != "/pets.v1.PetService/CreatePet" || len(merr.Errors) != 2 {
+		t.Errorf("unexpected error: %s", merr)
+	}
+
+	methods.Marshal = func(req interface{}) ([]byte, error) {
+		return []byte(`{"name": "custom"}`), nil
+	}
+	if err := methods.Validate("/pets.v1.PetService/CreatePet", createPet{Age: -1}); err != nil {
+		t.Errorf("expected Marshal to encode requests, got: %s", err)
+	}
+}
+
+func TestMethodSchemasSelector(t *testing.T) {
+	methods := &MethodSchemas{Schemas: map[string]*RootSchema{
+		"/pets.v1.PetService/CreatePet": Must(`{"required": ["name"]}`),
+	}}
+	methodOf := func(r *http.Request) string {
+		return r.Header.Get("X-Method")
+	}
+	handler := RouteMiddleware(methods.Selector(methodOf), MiddlewareOptions{}, ResponseOptions{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		method, body string
+		status       int
+	}{
+		{"/pets.v1.PetService/CreatePet", `{"name": "Rex"}`, 200},
+		{"/pets.v1.PetService/CreatePet", `{}`, 400},
+		{"/pets.v1.PetService/ListPets", `{}`, 200},
+		{"", `{}`, 200},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/v1/pets", strings.NewReader(c.body))
+		req.Header.Set("X-Method", c.method)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != c.status {
+			t.Errorf("%q %s: expected status %d, got %d", c.method, c.body, c.status, rec.Code)
+		}
+	}
+}
@@ -10,10 +10,10 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-
-	"github.com/qri-io/jsonpointer"
+	"strings"
 )
 
 // Must turns a JSON string into a *RootSchema, panicing if parsing fails.
@@ -47,6 +47,13 @@ type RootSchema struct {
 	// for current and previous published drafts of JSON Schema
 	// vocabularies as deemed reasonable.
 	SchemaURI string `json:"$schema"`
+
+	// data is whether the schema uses $data
+	data bool
+	// memo holds the results of referenced schemas when memoized
+	memo *refMemo
+	// opts are the options of the schema, nil for the defaults
+	opts *Options
 }
 
 // TopLevelType returns a string representing the schema's top-level type.
@@ -64,9 +71,15 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, sch); err != nil {
 		return err
 	}
+	if rs.options().Parsing.Strict {
+		if err := checkKeywords(sch); err != nil {
+			return err
+		}
+	}
 
 	if sch.schemaType == schemaTypeFalse || sch.schemaType == schemaTypeTrue {
-		*rs = RootSchema{Schema: *sch}
+		*rs = RootSchema{Schema: *sch, opts: rs.opts}
+		rs.bindOptions()
 		return nil
 	}
 
@@ -77,10 +90,30 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	root := &RootSchema{
+	*rs = RootSchema{
 		Schema:    *sch,
 		SchemaURI: suri.SchemaURI,
+		opts:      rs.opts,
+	}
+	if err := rs.resolveRefs(); err != nil {
+		return err
 	}
+	rs.bindData()
+	rs.bindPaths()
+	rs.bindOptions()
+	return nil
+}
+
+// resolveRefs links every "$ref" in the document to the schema it identifies
+// within the document. references are resolved against rs itself, so
+// references to the root document point at the schema the caller holds.
+// References to other documents are left for FetchRemoteReferences, and
+// references that lead back to themselves without evaluating anything are
+// left unresolved, failing validation rather than recursing forever.
+// References to no schema in the document are an error
+func (rs *RootSchema) resolveRefs() error {
+	root := rs
+	sch := &rs.Schema
 
 	// collect IDs for internal referencing:
 	ids := map[string]*Schema{}
@@ -106,9 +139,17 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	// pass a pointer to the schema component in here (instead of the
-	// RootSchema struct) to ensure root is evaluated for references
-	if err := walkJSON(sch, func(elem JSONPather) error {
+	// schemas references lead to outside the tree, parsed from unknown
+	// keywords, have their own references resolved too
+	inTree := map[*Schema]bool{}
+	walkJSON(sch, func(elem JSONPather) error {
+		if sch, ok := elem.(*Schema); ok {
+			inTree[sch] = true
+		}
+		return nil
+	})
+	trees := []*Schema{sch}
+	resolve := func(elem JSONPather) error {
 		if sch, ok := elem.(*Schema); ok {
 			if sch.Ref != "" {
 				if ids[sch.Ref] != nil {
@@ -116,29 +157,79 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 					return nil
 				}
 
-				ptr, err := jsonpointer.Parse(sch.Ref)
+				from := &root.Schema
+				doc, frag := sch.Ref, ""
+				if i := strings.IndexByte(sch.Ref, '#'); i >= 0 {
+					doc, frag = sch.Ref[:i], sch.Ref[i:]
+				}
+				if doc != "" {
+					if ids[doc] == nil {
+						// a reference to another document
+						return nil
+					}
+					from = ids[doc]
+				}
+
+				ptr, err := ParsePointer(frag)
 				if err != nil {
 					return fmt.Errorf("error evaluating json pointer: %s: %s", err.Error(), sch.Ref)
 				}
-				res, err := root.evalJSONValidatorPointer(ptr)
+				target, err := ptr.EvalSchema(from)
 				if err != nil {
-					return err
+					return fmt.Errorf("error resolving reference %s: %s", sch.Ref, err.Error())
 				}
-				if val, ok := res.(Validator); ok {
-					sch.ref = val
-				} else {
-					return fmt.Errorf("%s : %s, %v is not a json pointer to a json schema", sch.Ref, ptr.String(), ptr)
+				sch.ref = target
+				if !inTree[target] {
+					inTree[target] = true
+					trees = append(trees, target)
 				}
 			}
 		}
 		return nil
-	}); err != nil {
-		return err
 	}
 
-	*rs = RootSchema{
-		Schema:    *sch,
-		SchemaURI: suri.SchemaURI,
+	// pass a pointer to the schema component in here (instead of the
+	// RootSchema struct) to ensure root is evaluated for references
+	for i := 0; i < len(trees); i++ {
+		if err := walkJSON(trees[i], resolve); err != nil {
+			return err
+		}
+	}
+
+	for _, tree := range trees {
+		walkJSON(tree, func(elem JSONPather) error {
+			if sch, ok := elem.(*Schema); ok && sch.ref != nil && refCycle(sch) {
+				sch.ref = nil
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// refCycle reports whether following the references of s, and those of the
+// schemas they lead to, comes back to s
+func refCycle(s *Schema) bool {
+	seen := map[*Schema]bool{}
+	for next := resolvedRef(s.ref); next != nil && !seen[next]; next = resolvedRef(next.ref) {
+		if next == s {
+			return true
+		}
+		if next.Ref == "" {
+			return false
+		}
+		seen[next] = true
+	}
+	return false
+}
+
+// resolvedRef gives the schema a resolved reference leads to
+func resolvedRef(v Validator) *Schema {
+	switch t := v.(type) {
+	case *Schema:
+		return t
+	case *RootSchema:
+		return &t.Schema
 	}
 	return nil
 }
@@ -183,26 +274,42 @@ func (rs *RootSchema) FetchRemoteReferences() error {
 // ValidateBytes performs schema validation against a slice of json
 // byte data
 func (rs *RootSchema) ValidateBytes(data []byte) ([]ValError, error) {
-	var doc interface{}
 	errs := []ValError{}
-	if err := json.Unmarshal(data, &doc); err != nil {
+	doc, err := rs.options().Decoding.decodeJSON(data)
+	if err != nil {
 		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
 	}
 	rs.Validate("/", doc, &errs)
 	return errs, nil
 }
 
-func (rs *RootSchema) evalJSONValidatorPointer(ptr jsonpointer.Pointer) (res interface{}, err error) {
-	res = rs
-	for _, token := range ptr {
-		if adr, ok := res.(JSONPather); ok {
-			res = adr.JSONProp(token)
-		} else if !ok {
-			err = fmt.Errorf("invalid pointer: %s", ptr)
-			return
-		}
+// ValidateReader performs schema validation against the json document
+// read from r, reading it into a pooled buffer
+func (rs *RootSchema) ValidateReader(r io.Reader) ([]ValError, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return []ValError{}, fmt.Errorf("error reading JSON: %s", err.Error())
 	}
-	return
+	return rs.ValidateBytes(buf.Bytes())
+}
+
+// InstanceDecoder decodes an instance in an encoding other than JSON into
+// the values validation works with, as encoding/json decodes JSON into an
+// interface{}: nil, bool, float64, string, []interface{} and
+// map[string]interface{}
+type InstanceDecoder func(data []byte) (interface{}, error)
+
+// ValidateEncoded performs schema validation against data, an instance
+// decoded by decode
+func (rs *RootSchema) ValidateEncoded(data []byte, decode InstanceDecoder) ([]ValError, error) {
+	errs := []ValError{}
+	doc, err := decode(data)
+	if err != nil {
+		return errs, err
+	}
+	rs.Validate("/", doc, &errs)
+	return errs, nil
 }
 
 type schemaType int
@@ -350,24 +457,49 @@ type Schema struct {
 	// to inline re-usable JSON Schemas into a more general schema. The
 	// keyword does not directly affect the validation result.
 	Definitions Definitions `json:"definitions,omitempty"`
+	// Defs is the "$defs" keyword, the name later drafts give to
+	// "definitions". It behaves exactly like Definitions
+	Defs Definitions `json:"$defs,omitempty"`
 
 	// TODO - currently a bit of a hack to handle arbitrary JSON data
 	// outside the spec
 	extraDefinitions Definitions
+	// extraKeywords holds the raw values of keywords no validator is
+	// registered for, so they survive a decode/encode round trip
+	extraKeywords map[string]json.RawMessage
+	// path is the JSON pointer to the schema within the root schema it was
+	// parsed with
+	path string
+	// memo caches the results of the referenced schema, when enabled
+	memo *refMemo
+	// opts are the options of the root schema the schema was parsed with
+	opts *Options
 
 	Validators map[string]Validator
 }
 
-// Path gives a jsonpointer path to the validator
+// Path gives a jsonpointer path to the schema within the root schema it was
+// parsed with, empty for the root and for schemas parsed on their own
 func (s *Schema) Path() string {
-	return ""
+	return s.path
 }
 
 // Validate uses the schema to check an instance, collecting validation
 // errors in a slice
 func (s *Schema) Validate(propPath string, data interface{}, errs *[]ValError) {
+	vd := newValidation(propPath, data)
+	vd.opts = s.opts
+	s.validateIn(vd, propPath, data, errs)
+}
+
+func (s *Schema) validateIn(vd *validation, propPath string, data interface{}, errs *[]ValError) {
+	hooked := len(vd.options().Hooks) > 0
 	if s.Ref != "" && s.ref != nil {
-		s.ref.Validate(propPath, data, errs)
+		if s.memo != nil && !hooked {
+			s.memo.validate(vd, s.ref, propPath, data, errs)
+			return
+		}
+		vd.validate(s.ref, propPath, data, errs)
 		return
 	} else if s.Ref != "" && s.ref == nil {
 		AddError(errs, propPath, data, fmt.Sprintf("%s reference is nil for data: %v", s.Ref, data))
@@ -378,11 +510,47 @@ func (s *Schema) Validate(propPath string, data interface{}, errs *[]ValError) {
 	// "default" is made.
 	// Is this correct?
 
-	for _, v := range s.Validators {
-		v.Validate(propPath, data, errs)
+	if t, ok := s.Validators["transform"].(*Transform); ok {
+		data = t.apply(data)
+	}
+
+	d, discriminated := s.Validators["discriminator"].(*Discriminator)
+	null := data == nil && s.nullable()
+	for key, v := range s.Validators {
+		if key == "oneOf" && discriminated && d.oneOf != nil {
+			// the discriminator validates against the branch it picks
+			continue
+		}
+		if null && (key == "type" || key == "enum") {
+			continue
+		}
+		n := len(*errs)
+		if hooked {
+			s.validateHooked(vd, key, v, propPath, data, errs)
+		} else {
+			vd.validate(v, propPath, data, errs)
+		}
+		s.setRulePaths(key, (*errs)[n:])
+	}
+}
+
+// setRulePaths points the errors of the keyword key of s that don't name
+// the rule they come from at the keyword. Subschemas set theirs first, so
+// errors keep the innermost keyword that reported them
+func (s *Schema) setRulePaths(key string, errs []ValError) {
+	for i := range errs {
+		if errs[i].RulePath == "" {
+			errs[i].RulePath = s.path + "/" + EscapePointerToken(key)
+		}
 	}
 }
 
+// nullable reports whether "nullable: true" adds null to the type of s
+func (s *Schema) nullable() bool {
+	n, ok := s.Validators["nullable"].(*Nullable)
+	return ok && bool(*n) && s.Validators["type"] != nil
+}
+
 // JSONProp implements the JSONPather for Schema
 func (s Schema) JSONProp(name string) interface{} {
 	switch name {
@@ -406,6 +574,8 @@ func (s Schema) JSONProp(name string) interface{} {
 		return s.Ref
 	case "definitions":
 		return s.Definitions
+	case "$defs":
+		return s.Defs
 	case "format":
 		return s.Format
 	default:
@@ -430,6 +600,9 @@ func (s Schema) JSONChildren() (ch map[string]JSONPather) {
 	if s.Definitions != nil {
 		ch["definitions"] = s.Definitions
 	}
+	if s.Defs != nil {
+		ch["$defs"] = s.Defs
+	}
 
 	if s.Validators != nil {
 		for key, val := range s.Validators {
@@ -454,6 +627,7 @@ type _schema struct {
 	Comment     string             `json:"$comment,omitempty"`
 	Ref         string             `json:"$ref,omitempty"`
 	Definitions map[string]*Schema `json:"definitions,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
 	Format      string             `json:"format,omitempty"`
 }
 
@@ -488,6 +662,7 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 		Comment:     _s.Comment,
 		Ref:         _s.Ref,
 		Definitions: _s.Definitions,
+		Defs:        _s.Defs,
 		Format:      _s.Format,
 		Validators:  map[string]Validator{},
 	}
@@ -515,7 +690,7 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 		} else {
 			switch prop {
 			// skip any already-parsed props
-			case "$schema", "$id", "title", "description", "default", "examples", "readOnly", "writeOnly", "$comment", "$ref", "definitions", "format":
+			case "$schema", "$id", "title", "description", "default", "examples", "readOnly", "writeOnly", "$comment", "$ref", "definitions", "$defs", "format":
 				continue
 			default:
 				// // assume non-specified props are "extra definitions"
@@ -527,39 +702,76 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 				// 	return fmt.Errorf("error unmarshaling %s from json: %s", prop, err.Error())
 				// }
 				// sch.extraDefinitions[prop] = s
+				if sch.extraKeywords == nil {
+					sch.extraKeywords = map[string]json.RawMessage{}
+				}
+				sch.extraKeywords[prop] = rawmsg
 				continue
 			}
 		}
+		if ref, ok, err := newDataRef(prop, rawmsg); ok {
+			if err != nil {
+				return err
+			}
+			sch.Validators[prop] = ref
+			continue
+		}
 		if err := json.Unmarshal(rawmsg, val); err != nil {
 			return fmt.Errorf("error unmarshaling %s from json: %s", prop, err.Error())
 		}
 		sch.Validators[prop] = val
 	}
 
-	if sch.Validators["if"] != nil {
-		if ite, ok := sch.Validators["if"].(*If); ok {
-			if s, ok := sch.Validators["then"].(*Then); ok {
-				ite.Then = s
+	sch.linkSiblings()
+
+	*s = Schema(*sch)
+	return nil
+}
+
+// linkSiblings connects keywords whose behaviour depends on sibling keywords
+func (s *Schema) linkSiblings() {
+	if s.Validators["if"] != nil {
+		if ite, ok := s.Validators["if"].(*If); ok {
+			if t, ok := s.Validators["then"].(*Then); ok {
+				ite.Then = t
 			}
-			if s, ok := sch.Validators["else"].(*Else); ok {
-				ite.Else = s
+			if e, ok := s.Validators["else"].(*Else); ok {
+				ite.Else = e
 			}
 		}
 	}
 
 	// TODO - replace all these assertions with methods on Schema that return proper types
-	if sch.Validators["items"] != nil && sch.Validators["additionalItems"] != nil && !sch.Validators["items"].(*Items).single {
-		sch.Validators["additionalItems"].(*AdditionalItems).startIndex = len(sch.Validators["items"].(*Items).Schemas)
+	if s.Validators["items"] != nil && s.Validators["additionalItems"] != nil && !s.Validators["items"].(*Items).single {
+		s.Validators["additionalItems"].(*AdditionalItems).startIndex = len(s.Validators["items"].(*Items).Schemas)
+	}
+	if s.Validators["properties"] != nil && s.Validators["additionalProperties"] != nil {
+		s.Validators["additionalProperties"].(*AdditionalProperties).Properties = s.Validators["properties"].(*Properties)
 	}
-	if sch.Validators["properties"] != nil && sch.Validators["additionalProperties"] != nil {
-		sch.Validators["additionalProperties"].(*AdditionalProperties).Properties = sch.Validators["properties"].(*Properties)
+	if s.Validators["patternProperties"] != nil && s.Validators["additionalProperties"] != nil {
+		s.Validators["additionalProperties"].(*AdditionalProperties).patterns = s.Validators["patternProperties"].(*PatternProperties)
 	}
-	if sch.Validators["patternProperties"] != nil && sch.Validators["additionalProperties"] != nil {
-		sch.Validators["additionalProperties"].(*AdditionalProperties).patterns = sch.Validators["patternProperties"].(*PatternProperties)
+	if d, ok := s.Validators["discriminator"].(*Discriminator); ok {
+		d.oneOf, _ = s.Validators["oneOf"].(*OneOf)
 	}
+	if a, ok := s.Validators["allRequired"].(*AllRequired); ok {
+		a.props, _ = s.Validators["properties"].(*Properties)
+	}
+	for _, key := range []string{"formatMinimum", "formatMaximum", "formatExclusiveMinimum", "formatExclusiveMaximum"} {
+		if f, ok := s.Validators[key].(*FormatLimit); ok {
+			f.format, _ = s.Validators["format"].(*Format)
+		}
+	}
+}
 
-	*s = Schema(*sch)
-	return nil
+// MarshalJSON implements the json.Marshaler interface for RootSchema
+func (rs RootSchema) MarshalJSON() ([]byte, error) {
+	if rs.schemaType != schemaTypeObject || rs.SchemaURI == "" {
+		return rs.Schema.MarshalJSON()
+	}
+	obj := rs.Schema.jsonObject()
+	obj["$schema"] = rs.SchemaURI
+	return json.Marshal(obj)
 }
 
 // MarshalJSON implements the json.Marshaler interface for Schema
@@ -570,53 +782,62 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 	case schemaTypeTrue:
 		return []byte("true"), nil
 	default:
-		obj := map[string]interface{}{}
+		return json.Marshal(s.jsonObject())
+	}
+}
 
-		if s.ID != "" {
-			obj["$id"] = s.ID
-		}
-		if s.Title != "" {
-			obj["title"] = s.Title
-		}
-		if s.Description != "" {
-			obj["description"] = s.Description
-		}
-		if s.Default != nil {
-			obj["default"] = s.Default
-		}
-		if s.Examples != nil {
-			obj["examples"] = s.Examples
-		}
-		if s.ReadOnly != nil {
-			obj["readOnly"] = s.ReadOnly
-		}
-		if s.WriteOnly != nil {
-			obj["writeOnly"] = s.WriteOnly
-		}
-		if s.Comment != "" {
-			obj["$comment"] = s.Comment
-		}
-		if s.Ref != "" {
-			obj["$ref"] = s.Ref
-		}
-		if s.Definitions != nil {
-			obj["definitions"] = s.Definitions
-		}
-		if s.Format != "" {
-			obj["format"] = s.Format
-		}
-		if s.Definitions != nil {
-			obj["definitions"] = s.Definitions
-		}
+// jsonObject collects all keywords of an object schema into a map for encoding
+func (s Schema) jsonObject() map[string]interface{} {
+	obj := map[string]interface{}{}
 
-		for k, v := range s.Validators {
-			obj[k] = v
-		}
-		for k, v := range s.extraDefinitions {
-			obj[k] = v
-		}
-		return json.Marshal(obj)
+	for k, v := range s.extraKeywords {
+		obj[k] = v
+	}
+
+	if s.ID != "" {
+		obj["$id"] = s.ID
+	}
+	if s.Title != "" {
+		obj["title"] = s.Title
+	}
+	if s.Description != "" {
+		obj["description"] = s.Description
+	}
+	if s.Default != nil {
+		obj["default"] = s.Default
+	}
+	if s.Examples != nil {
+		obj["examples"] = s.Examples
+	}
+	if s.ReadOnly != nil {
+		obj["readOnly"] = s.ReadOnly
+	}
+	if s.WriteOnly != nil {
+		obj["writeOnly"] = s.WriteOnly
+	}
+	if s.Comment != "" {
+		obj["$comment"] = s.Comment
+	}
+	if s.Ref != "" {
+		obj["$ref"] = s.Ref
+	}
+	if s.Definitions != nil {
+		obj["definitions"] = s.Definitions
+	}
+	if s.Defs != nil {
+		obj["$defs"] = s.Defs
+	}
+	if s.Format != "" {
+		obj["format"] = s.Format
+	}
+
+	for k, v := range s.Validators {
+		obj[k] = v
+	}
+	for k, v := range s.extraDefinitions {
+		obj[k] = v
 	}
+	return obj
 }
 
 // Definitions implements a map of schemas while also satsfying the JSON
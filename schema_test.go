@@ -11,6 +11,7 @@ import (
 	// "net/http"
 	// "net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -477,11 +478,13 @@ func TestJSONCoding(t *testing.T) {
 		"testdata/coding/false.json",
 		"testdata/coding/true.json",
 		"testdata/coding/std.json",
+		"testdata/coding/arrays.json",
 		"testdata/coding/booleans.json",
 		"testdata/coding/conditionals.json",
 		"testdata/coding/numeric.json",
 		"testdata/coding/objects.json",
 		"testdata/coding/strings.json",
+		"testdata/coding/root.json",
 	}
 
 	for i, c := range cases {
@@ -580,6 +583,292 @@ func TestOpaqueProperties(t *testing.T) {
 	}
 }
 
+func TestDiscriminator(t *testing.T) {
+	rs := Must(`{
+		"oneOf": [{"$ref": "#/definitions/cat"}, {"$ref": "#/definitions/dog"}],
+		"discriminator": {"propertyName": "kind", "mapping": {"puppy": "#/definitions/dog"}},
+		"definitions": {
+			"cat": {"required": ["kind", "lives"], "properties": {"lives": {"type": "integer"}}},
+			"dog": {"required": ["kind"], "properties": {"good": {"type": "boolean"}}}
+		}
+	}`)
+	cases := []struct {
+		data   string
+		errors []string
+	}{
+		{`{"kind": "cat", "lives": 9}`, nil},
+		{`{"kind": "puppy", "good": true}`, nil},
+		{`{"kind": "cat"}`, []string{`/: {"kind":"cat"} "lives" value is required (as cat variant)`}},
+		{`{"kind": "dog", "good": "very"}`, []string{`/good: "very" type should be boolean (as dog variant)`}},
+		{`{"kind": "bird"}`, []string{`/: {"kind":"bird"} discriminator "kind" value "bird" doesn't name a OneOf variant`}},
+		{`{"lives": 9}`, []string{`/: {"lives":9} discriminator property "kind" must be a string naming a OneOf variant`}},
+		{`"cat"`, []string{`/: "cat" matched more than one specified OneOf schemas`}},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		if len(got) != len(c.errors) {
+			t.Errorf("case %d: expected errors %v, got %v", i, c.errors, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != c.errors[j] {
+				t.Errorf("case %d: expected error %q, got %q", i, c.errors[j], got[j])
+			}
+		}
+	}
+
+	if errs, _ := rs.Clone().ValidateBytes([]byte(`{"kind": "bird"}`)); len(errs) != 1 {
+		t.Errorf("expected a clone to keep using the discriminator, got %v", errs)
+	}
+}
+
+func TestNullable(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "nullable": true, "minLength": 2},
+			"size": {"type": "string", "enum": ["s", "m"], "nullable": true},
+			"plain": {"type": "string", "nullable": false},
+			"untyped": {"enum": ["a"], "nullable": true}
+		}
+	}`)
+	cases := []struct {
+		data   string
+		errors []string
+	}{
+		{`{"name": null, "size": null}`, nil},
+		{`{"name": "ab", "size": "m"}`, nil},
+		{`{"name": 1}`, []string{`/name: 1 type should be string`}},
+		{`{"size": "l"}`, []string{`/size: "l" should be one of ["s", "m"]`}},
+		{`{"plain": null}`, []string{`/plain: type should be string`}},
+		{`{"untyped": null}`, []string{`/untyped: should be one of ["a"]`}},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(c.errors, "\n") {
+			t.Errorf("case %d: expected errors %q, got %q", i, c.errors, got)
+		}
+	}
+
+	if errs, _ := rs.Clone().ValidateBytes([]byte(`{"name": null}`)); len(errs) != 0 {
+		t.Errorf("expected a clone to keep nullable, got %v", errs)
+	}
+
+	ts, err := GenerateTypeScript(Must(`{"properties": {"a": {"type": "string", "nullable": true}}}`), TypeScriptOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ts), "a?: string | null;") {
+		t.Errorf("expected nullable to add null to the generated type, got %s", ts)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	rs := Must(`{"required": ["id", "name", "tags"]}`)
+	cases := []struct {
+		data   string
+		errors []string
+	}{
+		{`{"id": 1, "name": null, "tags": []}`, nil},
+		{`{"id": 1, "tags": []}`, []string{`/: {"id":1,"tags":[]} "name" value is required`}},
+		{`{"name": "a"}`, []string{`/: {"name":"a"} "id", "tags" values are required`}},
+		{`{}`, []string{`/: {} "id", "name", "tags" values are required`}},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+			if requiredMessage(e.MissingProperties) != e.Message {
+				t.Errorf("case %d: expected the missing properties of %q, got %v", i, e.Message, e.MissingProperties)
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(c.errors, "\n") {
+			t.Errorf("case %d: expected errors %q, got %q", i, c.errors, got)
+		}
+	}
+}
+
+func TestPatternPropertiesOrder(t *testing.T) {
+	rs := Must(`{"patternProperties": {"^z": {"type": "string"}, "^[a-z]": {"type": "integer"}}}`)
+	expect := []string{"/zb", "/zc", "/a", "/zb", "/zc"}
+	for i := 0; i < 10; i++ {
+		errs, err := rs.ValidateBytes([]byte(`{"zc": 1.5, "a": "x", "zb": true}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.PropertyPath)
+		}
+		if strings.Join(got, " ") != strings.Join(expect, " ") {
+			t.Fatalf("run %d: expected errors at %v, got %v", i, expect, errs)
+		}
+	}
+}
+
+func TestAdditionalPropertiesFalse(t *testing.T) {
+	cases := []struct {
+		schema, data string
+		errors       []string
+	}{
+		{`{"properties": {"b": {}, "a": {}}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, nil},
+		{`{"properties": {"b": {}, "a": {}}, "additionalProperties": false}`, `{"a": 1, "z": 2, "y": 3}`, []string{
+			`/y: 3 additional property "y" is not allowed, allowed: "a", "b"`,
+			`/z: 2 additional property "z" is not allowed, allowed: "a", "b"`,
+		}},
+		{`{"properties": {"a": {}}, "patternProperties": {"^x-": {}}, "additionalProperties": false}`, `{"x-a": 1, "b": true}`, []string{
+			`/b: true additional property "b" is not allowed, allowed: "a", names matching "^x-"`,
+		}},
+		{`{"additionalProperties": false}`, `{"a": null}`, []string{
+			`/a: additional property "a" is not allowed, no properties are allowed`,
+		}},
+	}
+	for i, c := range cases {
+		rs := Must(c.schema)
+		for _, sch := range []*RootSchema{rs, rs.Clone()} {
+			errs, err := sch.ValidateBytes([]byte(c.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(c.errors, "\n") {
+				t.Errorf("case %d: expected errors %q, got %q", i, c.errors, got)
+			}
+		}
+	}
+}
+
+func TestPropertyNamesErrors(t *testing.T) {
+	rs := Must(`{"properties": {"labels": {"propertyNames": {"maxLength": 3}}}}`)
+	errs, err := rs.ValidateBytes([]byte(`{"labels": {"a/b": 1, "long": 2, "longer": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"/labels/long long", "/labels/longer longer"}
+	if len(errs) != len(expect) {
+		t.Fatalf("expected %d errors, got %v", len(expect), errs)
+	}
+	for i, e := range errs {
+		if got := e.PropertyPath + " " + e.PropertyName; got != expect[i] {
+			t.Errorf("error %d: expected %q, got %q", i, expect[i], got)
+		}
+		if !strings.HasPrefix(e.Message, "invalid property name: ") {
+			t.Errorf("error %d: expected an invalid property name message, got %q", i, e.Message)
+		}
+	}
+
+	errs, _ = Must(`{"propertyNames": {"pattern": "^[a-z]+$"}}`).ValidateBytes([]byte(`{"a/b": 1}`))
+	if len(errs) != 1 || errs[0].PropertyName != "a/b" {
+		t.Errorf("expected the exact invalid name, got %v", errs)
+	}
+}
+
+func TestUnresolvedRefs(t *testing.T) {
+	cases := []struct {
+		schema, data string
+		valid        bool
+	}{
+		{`{"$ref": "a.json"}`, `1`, false},
+		{`{"$ref": "http://example.com/a.json"}`, `1`, false},
+		{`{"$ref": "http://example.com/a.json#/definitions/a", "definitions": {"a": true}}`, `1`, false},
+		{`{"$ref": "#"}`, `1`, false},
+		{`{"$ref": "#/definitions/a", "definitions": {"a": {"$ref": "#"}}}`, `1`, false},
+		{`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"$ref": "#/definitions/a"}}, "properties": {"x": {"$ref": "#/definitions/a"}}}`, `{"x": 1}`, false},
+		{`{"type": "object", "properties": {"child": {"$ref": "#"}}}`, `{"child": {"child": {}}}`, true},
+		{`{"type": "object", "properties": {"child": {"$ref": "#"}}}`, `{"child": {"child": 1}}`, false},
+		{`{"$id": "http://example.com/root.json", "definitions": {"a": {"type": "integer"}}, "properties": {"n": {"$ref": "http://example.com/root.json#/definitions/a"}}}`, `{"n": "x"}`, false},
+		{`{"$id": "http://example.com/root.json", "definitions": {"a": {"type": "integer"}}, "properties": {"n": {"$ref": "http://example.com/root.json#/definitions/a"}}}`, `{"n": 1}`, true},
+	}
+	for i, c := range cases {
+		rs := &RootSchema{}
+		if err := json.Unmarshal([]byte(c.schema), rs); err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		errs, err := rs.ValidateBytes([]byte(c.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("case %d: expected valid %t, got errors %v", i, c.valid, errs)
+		}
+	}
+}
+
+func TestMissingRefs(t *testing.T) {
+	for _, schema := range []string{
+		`{"properties": {"x": {"$ref": "#/definitions/nope"}}}`,
+		`{"$ref": "#/properties/x/items", "properties": {"x": {"type": "array"}}}`,
+		`{"$id": "http://example.com/root.json", "properties": {"n": {"$ref": "http://example.com/root.json#/definitions/a"}}}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/b"}}}`,
+	} {
+		if err := json.Unmarshal([]byte(schema), &RootSchema{}); err == nil {
+			t.Errorf("%s: expected an error for a reference to nothing", schema)
+		}
+	}
+}
+
+func TestEscapedRefs(t *testing.T) {
+	rs := Must(`{
+		"definitions": {"a~b": {"type": "integer"}, "c/d": {"type": "string"}, "e f": {"type": "boolean"}},
+		"x-types": {"n": {"$ref": "#/definitions/a~0b"}},
+		"properties": {
+			"tilda": {"$ref": "#/definitions/a~0b"},
+			"slash": {"$ref": "#/definitions/c~1d"},
+			"percent": {"$ref": "#/definitions/e%20f"},
+			"extra": {"$ref": "#/x-types/n"}
+		}
+	}`)
+	errs, err := rs.ValidateBytes([]byte(`{"tilda": 1, "slash": "s", "percent": true, "extra": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs, _ = rs.ValidateBytes([]byte(`{"tilda": "x", "slash": 1, "percent": 1, "extra": "x"}`))
+	if len(errs) != 4 {
+		t.Errorf("expected 4 errors, got %v", errs)
+	}
+	if err := json.Unmarshal([]byte(`{"$ref": "#/definitions/a~2"}`), &RootSchema{}); err == nil {
+		t.Errorf("expected an error for an invalid pointer")
+	}
+}
+
+func TestNotRef(t *testing.T) {
+	rs := Must(`{"properties": {"x": {"not": {"$ref": "#/definitions/s"}}}, "definitions": {"s": {"type": "string"}}}`)
+	for doc, expect := range map[string]int{`{"x": 1}`: 0, `{"x": "a"}`: 1} {
+		errs, err := rs.ValidateBytes([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != expect {
+			t.Errorf("%s: expected %d errors, got %v", doc, expect, errs)
+		}
+	}
+}
+
 // TODO - finish remoteRef.json tests by setting up a httptest server on localhost:1234
 // that uses an http.Dir to serve up testdata/remotes directory
 // func testServer() {
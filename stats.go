@@ -0,0 +1,88 @@
+package jsonschema
+
+// SchemaStats summarises the size and shape of a schema, for example to
+// reject overly expensive user-supplied schemas before using them
+type SchemaStats struct {
+	// Subschemas counts the schema and every schema nested within it,
+	// including definitions
+	Subschemas int `json:"subschemas"`
+	// Keywords counts keywords across all subschemas
+	Keywords int `json:"keywords"`
+	// MaxDepth is the deepest level of nesting, where the schema itself is
+	// at depth 1
+	MaxDepth int `json:"maxDepth"`
+	// Refs counts "$ref" keywords
+	Refs int `json:"refs"`
+	// Regexes counts regular expressions: "pattern" keywords and the keys
+	// of "patternProperties"
+	Regexes int `json:"regexes"`
+	// MaxBranching is the largest number of branches in a single "allOf",
+	// "anyOf" or "oneOf"
+	MaxBranching int `json:"maxBranching"`
+	// Complexity is an overall score, see Stats
+	Complexity int `json:"complexity"`
+}
+
+// Stats measures the schema. References aren't followed, so each schema is
+// counted once. The complexity score adds one for each keyword, two for
+// each reference and ten for each regular expression, plus the square of
+// the number of branches in each "anyOf" and "oneOf", whose cost grows
+// quickly when they nest. Scores are only meaningful relative to each other
+func (s *Schema) Stats() SchemaStats {
+	st := SchemaStats{}
+	collectStats(s, 1, &st)
+	st.Complexity += st.Keywords + 2*st.Refs + 10*st.Regexes
+	return st
+}
+
+func collectStats(s *Schema, depth int, st *SchemaStats) {
+	if s == nil {
+		return
+	}
+	st.Subschemas++
+	if depth > st.MaxDepth {
+		st.MaxDepth = depth
+	}
+	if s.schemaType != schemaTypeObject {
+		return
+	}
+
+	st.Keywords += len(s.jsonObject())
+	if s.Ref != "" {
+		st.Refs++
+	}
+	for _, defs := range []Definitions{s.Defs, s.Definitions} {
+		for _, key := range sortedDefinitionKeys(defs) {
+			collectStats(defs[key], depth+1, st)
+		}
+	}
+
+	for _, v := range s.Validators {
+		switch t := v.(type) {
+		case *Pattern:
+			st.Regexes++
+		case *PatternProperties:
+			st.Regexes += len(*t)
+		case *AllOf:
+			st.branches(len(*t), false)
+		case *AnyOf:
+			st.branches(len(*t), true)
+		case *OneOf:
+			st.branches(len(*t), true)
+		}
+		for _, ch := range subschemas(v) {
+			collectStats(ch.schema, depth+1, st)
+		}
+	}
+}
+
+// branches records a composition keyword with n branches, which adds to the
+// complexity when any one branch may be the one that matches
+func (st *SchemaStats) branches(n int, alternatives bool) {
+	if n > st.MaxBranching {
+		st.MaxBranching = n
+	}
+	if alternatives {
+		st.Complexity += n * n
+	}
+}
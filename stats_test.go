@@ -0,0 +1,37 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": { "type": "string", "pattern": "^[a-z]+$" },
+			"tags": { "items": { "anyOf": [{ "type": "string" }, { "type": "integer" }, false] } },
+			"self": { "$ref": "#" }
+		},
+		"patternProperties": { "^x-": {}, "^y-": true },
+		"definitions": { "unused": { "allOf": [{}, {}, {}, {}] } }
+	}`)
+
+	got := rs.Stats()
+	expect := SchemaStats{
+		Subschemas:   15,
+		Keywords:     12,
+		MaxDepth:     4,
+		Refs:         1,
+		Regexes:      3,
+		MaxBranching: 4,
+		// 12 keywords + 2 for the ref + 30 for regexes + 3*3 for the anyOf
+		Complexity: 53,
+	}
+	if got != expect {
+		t.Errorf("expected: %+v\ngot:      %+v", expect, got)
+	}
+
+	if got := (&Schema{schemaType: schemaTypeTrue}).Stats(); got != (SchemaStats{Subschemas: 1, MaxDepth: 1}) {
+		t.Errorf("unexpected stats for the true schema: %+v", got)
+	}
+}
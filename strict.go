@@ -0,0 +1,109 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseOptions configures how schemas are parsed
+type ParseOptions struct {
+	// Strict fails parsing schemas with an unknown keyword that closely
+	// matches a known one, like "minLenght" or "additonalProperties",
+	// suggesting the keyword likely meant. Other unknown keywords, such as
+	// extensions, are kept as they are otherwise
+	Strict bool
+}
+
+// checkKeywords fails for the first unknown keyword of s and its
+// subschemas, in walk order, that's likely a typo of a known keyword
+func checkKeywords(s *Schema) error {
+	return s.Walk(func(_ string, sch *Schema) error {
+		keys := make([]string, 0, len(sch.extraKeywords))
+		for key := range sch.extraKeywords {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if known, ok := suggestKeyword(key); ok {
+				return fmt.Errorf("unknown keyword %q, did you mean %q?", key, known)
+			}
+		}
+		return nil
+	})
+}
+
+// nonValidatorKeywords are the keywords schemas understand that no validator
+// evaluates
+var nonValidatorKeywords = map[string]bool{
+	"$schema": true, "$id": true, "id": true, "$ref": true, "$comment": true,
+	"$anchor": true, "$vocabulary": true, "definitions": true, "$defs": true,
+	"title": true, "description": true, "default": true, "examples": true, "example": true,
+	"readOnly": true, "writeOnly": true, "deprecated": true,
+	"contentMediaType": true, "contentEncoding": true, "contentSchema": true,
+}
+
+// KnownKeywords gives the keywords schemas understand in sorted order:
+// those of DefaultValidators, including registered ones, and the others
+func KnownKeywords() []string {
+	keywords := make([]string, 0, len(DefaultValidators)+len(nonValidatorKeywords))
+	for key := range DefaultValidators {
+		keywords = append(keywords, key)
+	}
+	for key := range nonValidatorKeywords {
+		if DefaultValidators[key] == nil {
+			keywords = append(keywords, key)
+		}
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+// suggestKeyword gives the known keyword closest to the unknown keyword,
+// when they're close enough for keyword to be a typo of it
+func suggestKeyword(keyword string) (string, bool) {
+	if strings.HasPrefix(keyword, "x-") || nonValidatorKeywords[keyword] {
+		return "", false
+	}
+	best, bestDist := "", -1
+	for _, known := range KnownKeywords() {
+		limit := 1
+		if len(known) > 6 {
+			limit = 2
+		}
+		dist := editDistance(strings.ToLower(keyword), strings.ToLower(known))
+		if dist <= limit && (bestDist < 0 || dist < bestDist) {
+			best, bestDist = known, dist
+		}
+	}
+	return best, bestDist >= 0
+}
+
+// editDistance gives the number of insertions, deletions, substitutions and
+// transpositions of adjacent characters that turn a into b
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	// rows of the distances between prefixes of x and y, two rows back to
+	// count transpositions
+	prev2 := make([]int, len(y)+1)
+	prev := make([]int, len(y)+1)
+	cur := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		cur[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(y)]
+}
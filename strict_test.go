@@ -0,0 +1,66 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		dist int
+	}{
+		{"", "", 0},
+		{"type", "type", 0},
+		{"minLenght", "minLength", 1},
+		{"additonalProperties", "additionalProperties", 1},
+		{"tpye", "type", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, c := range cases {
+		if got := editDistance(c.a, c.b); got != c.dist {
+			t.Errorf("%s, %s: expected %d, got %d", c.a, c.b, c.dist, got)
+		}
+	}
+}
+
+func TestParsingStrict(t *testing.T) {
+	cases := []struct {
+		schema string
+		err    string
+	}{
+		{`{"properties": {"a": {"minLenght": 1}}}`, `did you mean "minLength"?`},
+		{`{"additonalProperties": false}`, `did you mean "additionalProperties"?`},
+		{`{"maxlength": 1}`, `did you mean "maxLength"?`},
+		{`{"tpye": "string"}`, `did you mean "type"?`},
+		{`{"x-minLength": 1, "propertyOrder": 2, "deprecated": true, "example": 1}`, ``},
+	}
+	for i, c := range cases {
+		for _, strict := range []bool{false, true} {
+			rs := &RootSchema{}
+			rs.SetOptions(Options{Parsing: ParseOptions{Strict: strict}})
+			err := json.Unmarshal([]byte(c.schema), rs)
+			switch {
+			case !strict || c.err == "":
+				if err != nil {
+					t.Errorf("case %d: strict %t: unexpected error: %s", i, strict, err.Error())
+				}
+			case err == nil || !strings.Contains(err.Error(), c.err):
+				t.Errorf("case %d: expected an error suggesting %s, got %v", i, c.err, err)
+			}
+		}
+	}
+
+	keywords := KnownKeywords()
+	for _, key := range []string{"type", "minLength", "$ref", "title", "nullable"} {
+		found := false
+		for _, known := range keywords {
+			found = found || known == key
+		}
+		if !found {
+			t.Errorf("expected %s to be a known keyword", key)
+		}
+	}
+}
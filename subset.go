@@ -0,0 +1,622 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// maxSubsetDepth bounds how deeply IsSubschemaOf descends before giving up
+const maxSubsetDepth = 64
+
+// allTypes lists every instance type, used when a schema doesn't restrict type
+var allTypes = []string{"null", "boolean", "object", "array", "number", "string"}
+
+// IsSubschemaOf reports whether every instance that is valid against a is
+// also valid against b. The check is a sound approximation: a true result
+// is always correct, while false means the relationship couldn't be proven.
+// Keywords this package can't reason about, like differing patterns or
+// custom validators, make the check fail unless both schemas share them.
+// Recursive references are assumed to hold while they're being checked
+func IsSubschemaOf(a, b *Schema) bool {
+	c := &subsetChecker{assumed: map[[2]*Schema]bool{}}
+	return c.subset(a, b)
+}
+
+// subsetChecker tracks the schema pairs currently being compared so
+// recursive schemas terminate
+type subsetChecker struct {
+	assumed map[[2]*Schema]bool
+	depth   int
+}
+
+var trueSchema = &Schema{schemaType: schemaTypeTrue}
+
+func (c *subsetChecker) subset(a, b *Schema) bool {
+	a, ok := resolveSchema(a)
+	if !ok {
+		// an unresolved reference may accept anything
+		a = trueSchema
+	}
+	b, ok = resolveSchema(b)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case isTrueSchema(b), a.schemaType == schemaTypeFalse:
+		return true
+	case b.schemaType == schemaTypeFalse:
+		return c.disjoint(a, trueSchema)
+	case a.schemaType == schemaTypeTrue:
+		a = &Schema{Validators: map[string]Validator{}}
+	}
+
+	key := [2]*Schema{a, b}
+	if c.assumed[key] {
+		return true
+	}
+	if c.depth >= maxSubsetDepth {
+		return false
+	}
+	c.assumed[key] = true
+	c.depth++
+	defer func() {
+		delete(c.assumed, key)
+		c.depth--
+	}()
+
+	if Equal(a, b) {
+		return true
+	}
+	// a finite set of instances can be checked one by one
+	if vals, ok := enumerable(a); ok {
+		for _, v := range vals {
+			if !isValid(a, v) {
+				continue
+			}
+			if !isValid(b, v) {
+				return false
+			}
+		}
+		return true
+	}
+	if _, ok := a.Validators["allOf"]; ok {
+		a, _ = a.Merge()
+	}
+	return c.composedSubset(a, b)
+}
+
+// composedSubset handles the boolean and conditional keywords of a and b
+// before comparing their remaining keywords
+func (c *subsetChecker) composedSubset(a, b *Schema) bool {
+	switch {
+	case b.Validators["allOf"] != nil:
+		for _, branch := range *b.Validators["allOf"].(*AllOf) {
+			if !c.subset(a, branch) {
+				return false
+			}
+		}
+		return c.subset(a, without(b, "allOf"))
+	case b.Validators["anyOf"] != nil:
+		if !c.subset(a, without(b, "anyOf")) {
+			return false
+		}
+		for _, branch := range *b.Validators["anyOf"].(*AnyOf) {
+			if c.subset(a, branch) {
+				return true
+			}
+		}
+		return false
+	case b.Validators["oneOf"] != nil:
+		// proving exactly one branch matches is out of reach unless there's only one
+		branches := *b.Validators["oneOf"].(*OneOf)
+		return len(branches) == 1 && c.subset(a, branches[0]) && c.subset(a, without(b, "oneOf"))
+	case b.Validators["not"] != nil:
+		return c.disjoint(a, (*Schema)(b.Validators["not"].(*Not))) && c.subset(a, without(b, "not"))
+	case b.Validators["if"] != nil:
+		cond := b.Validators["if"].(*If)
+		then, els := trueSchema, trueSchema
+		if cond.Then != nil {
+			then = (*Schema)(cond.Then)
+		}
+		if cond.Else != nil {
+			els = (*Schema)(cond.Else)
+		}
+		ok := (c.subset(a, then) && c.subset(a, els)) ||
+			(c.subset(a, &cond.Schema) && c.subset(a, then)) ||
+			(c.disjoint(a, &cond.Schema) && c.subset(a, els))
+		return ok && c.subset(a, without(b, "if", "then", "else"))
+	}
+
+	rest := without(a, "anyOf", "oneOf", "not", "if", "then", "else")
+	if v, ok := a.Validators["anyOf"].(*AnyOf); ok {
+		return c.allBranches(rest, *v, b)
+	}
+	if v, ok := a.Validators["oneOf"].(*OneOf); ok {
+		return c.allBranches(rest, *v, b)
+	}
+	if cond, ok := a.Validators["if"].(*If); ok {
+		branches := []*Schema{trueSchema, trueSchema}
+		if cond.Then != nil {
+			branches[0] = mergeSubschemas(cond.Schema.Clone(), (*Schema)(cond.Then).Clone())
+		}
+		if cond.Else != nil {
+			branches[1] = (*Schema)(cond.Else)
+		}
+		return c.allBranches(rest, branches, b)
+	}
+	// dropping "not" from a only grows the set of valid instances
+	return c.keywordsSubset(rest, b)
+}
+
+// allBranches checks that rest combined with each branch is a subset of b
+func (c *subsetChecker) allBranches(rest *Schema, branches []*Schema, b *Schema) bool {
+	if c.subset(rest, b) {
+		return true
+	}
+	for _, branch := range branches {
+		if !c.subset(mergeSubschemas(rest.Clone(), branch.Clone()), b) {
+			return false
+		}
+	}
+	return true
+}
+
+// keywordsSubset checks that a implies each assertion of b. Neither schema
+// has boolean or conditional keywords left at this point
+func (c *subsetChecker) keywordsSubset(a, b *Schema) bool {
+	types := schemaTypes(a)
+	applies := func(t string) bool { return containsType(types, t) }
+
+	for key, bv := range b.Validators {
+		ok := true
+		switch v := bv.(type) {
+		case *Type:
+			ok = typesCovered(types, v.vals)
+		case *Maximum:
+			ok = !applies("number") || upperBound(a, float64(*v), false)
+		case *ExclusiveMaximum:
+			ok = !applies("number") || upperBound(a, float64(*v), true)
+		case *Minimum:
+			ok = !applies("number") || lowerBound(a, float64(*v), false)
+		case *ExclusiveMinimum:
+			ok = !applies("number") || lowerBound(a, float64(*v), true)
+		case *MultipleOf:
+			m, has := a.Validators["multipleOf"].(*MultipleOf)
+			// integers are multiples of one
+			ok = !applies("number") || (has && math.Mod(float64(*m), float64(*v)) == 0) ||
+				(float64(*v) == 1 && !containsStrings(types, []string{"number"}))
+		case *MaxLength:
+			m, has := a.Validators["maxLength"].(*MaxLength)
+			ok = !applies("string") || (has && *m <= *v)
+		case *MinLength:
+			m, has := a.Validators["minLength"].(*MinLength)
+			ok = !applies("string") || (has && *m >= *v) || *v == 0
+		case *Pattern, *Format:
+			ok = !applies("string") || sameKeyword(a, b, key)
+		case *MaxItems:
+			m, has := a.Validators["maxItems"].(*MaxItems)
+			ok = !applies("array") || (has && *m <= *v)
+		case *MinItems:
+			m, has := a.Validators["minItems"].(*MinItems)
+			ok = !applies("array") || (has && *m >= *v) || *v == 0
+		case *UniqueItems:
+			u, has := a.Validators["uniqueItems"].(*UniqueItems)
+			m, bounded := a.Validators["maxItems"].(*MaxItems)
+			ok = !applies("array") || !bool(*v) || (has && bool(*u)) || (bounded && *m <= 1)
+		case *Items:
+			ok = !applies("array") || c.itemsSubset(a, b)
+		case *AdditionalItems:
+			// compared alongside items
+		case *Contains:
+			con, has := a.Validators["contains"].(*Contains)
+			ok = !applies("array") || (has && c.subset((*Schema)(con), (*Schema)(v)))
+		case *MaxProperties:
+			m, has := a.Validators["maxProperties"].(*MaxProperties)
+			ok = !applies("object") || (has && *m <= *v)
+		case *minProperties:
+			m, has := a.Validators["minProperties"].(*minProperties)
+			ok = !applies("object") || (has && *m >= *v) || *v == 0
+		case *Required:
+			ok = !applies("object") || requires(a, *v...)
+		case *Properties, *PatternProperties, *AdditionalProperties:
+			ok = !applies("object") || c.propertySubset(a, key, bv)
+		case *Dependencies:
+			ok = !applies("object") || c.dependenciesSubset(a, *v)
+		case *PropertyNames:
+			ok = !applies("object") || c.propertyNamesSubset(a, (*Schema)(v))
+		case *Then, *Else:
+			// only meaningful alongside "if"
+		default:
+			// const, enum and custom keywords need a matching keyword in a
+			ok = sameKeyword(a, b, key)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// itemsSubset compares every array position constrained by either schema
+func (c *subsetChecker) itemsSubset(a, b *Schema) bool {
+	n := 1
+	for _, s := range []*Schema{a, b} {
+		if items, ok := s.Validators["items"].(*Items); ok && !items.single && len(items.Schemas)+1 > n {
+			n = len(items.Schemas) + 1
+		}
+	}
+	if m, ok := a.Validators["maxItems"].(*MaxItems); ok && int(*m) < n {
+		n = int(*m)
+	}
+	for i := 0; i < n; i++ {
+		if !c.subset(itemSchema(a, i), itemSchema(b, i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemSchema gives the schema constraining position i of an array, where
+// the last tuple position stands for every position after it
+func itemSchema(s *Schema, i int) *Schema {
+	items, ok := s.Validators["items"].(*Items)
+	switch {
+	case !ok:
+		return trueSchema
+	case items.single:
+		return items.Schemas[0]
+	case i < len(items.Schemas):
+		return items.Schemas[i]
+	}
+	if add, ok := s.Validators["additionalItems"].(*AdditionalItems); ok && add.Schema != nil {
+		return add.Schema
+	}
+	return trueSchema
+}
+
+// propertySubset checks one of b's properties, patternProperties or
+// additionalProperties keywords against the property constraints of a
+func (c *subsetChecker) propertySubset(a *Schema, key string, bv Validator) bool {
+	aprops, _ := a.Validators["properties"].(*Properties)
+	apatterns, _ := a.Validators["patternProperties"].(*PatternProperties)
+	aadd := trueSchema
+	if v, ok := a.Validators["additionalProperties"].(*AdditionalProperties); ok && v.Schema != nil {
+		aadd = v.Schema
+	}
+	patterns := PatternProperties{}
+	if apatterns != nil {
+		patterns = *apatterns
+	}
+
+	switch v := bv.(type) {
+	case *Properties:
+		for name, sch := range *v {
+			if !c.subset(propertySchema(a, name), sch) {
+				return false
+			}
+		}
+		return true
+	case *PatternProperties:
+		for _, ptn := range *v {
+			if aprops != nil {
+				for name := range *aprops {
+					if ptn.re.MatchString(name) && !c.subset(propertySchema(a, name), ptn.schema) {
+						return false
+					}
+				}
+			}
+			if same := findPattern(patterns, ptn.key); same != nil {
+				if !c.subset(same.schema, ptn.schema) {
+					return false
+				}
+				continue
+			}
+			for _, q := range patterns {
+				if !c.subset(q.schema, ptn.schema) {
+					return false
+				}
+			}
+			if !c.subset(aadd, ptn.schema) {
+				return false
+			}
+		}
+		return true
+	case *AdditionalProperties:
+		if v.Schema == nil {
+			return true
+		}
+		bprops := Properties{}
+		if v.Properties != nil {
+			bprops = *v.Properties
+		}
+		bpatterns := PatternProperties{}
+		if v.patterns != nil {
+			bpatterns = *v.patterns
+		}
+		additional := func(name string) bool {
+			if _, ok := bprops[name]; ok {
+				return false
+			}
+			for _, ptn := range bpatterns {
+				if ptn.re.MatchString(name) {
+					return false
+				}
+			}
+			return true
+		}
+		if aprops != nil {
+			for name := range *aprops {
+				if additional(name) && !c.subset(propertySchema(a, name), v.Schema) {
+					return false
+				}
+			}
+		}
+		for _, q := range patterns {
+			if findPattern(bpatterns, q.key) == nil && !c.subset(q.schema, v.Schema) {
+				return false
+			}
+		}
+		return c.subset(aadd, v.Schema)
+	}
+	return false
+}
+
+// propertySchema gives a schema that every value of the named property
+// satisfies under s
+func propertySchema(s *Schema, name string) *Schema {
+	if props, ok := s.Validators["properties"].(*Properties); ok && (*props)[name] != nil {
+		return (*props)[name]
+	}
+	if patterns, ok := s.Validators["patternProperties"].(*PatternProperties); ok {
+		for _, ptn := range *patterns {
+			if ptn.re.MatchString(name) {
+				return ptn.schema
+			}
+		}
+	}
+	if v, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && v.Schema != nil {
+		return v.Schema
+	}
+	return trueSchema
+}
+
+func findPattern(patterns PatternProperties, key string) *patternSchema {
+	for i := range patterns {
+		if patterns[i].key == key {
+			return &patterns[i]
+		}
+	}
+	return nil
+}
+
+func (c *subsetChecker) dependenciesSubset(a *Schema, deps Dependencies) bool {
+	adeps, _ := a.Validators["dependencies"].(*Dependencies)
+	for name, dep := range deps {
+		var ad Dependency
+		if adeps != nil {
+			ad = (*adeps)[name]
+		}
+		if isFalseSchema(propertySchema(a, name)) {
+			// a never has the property, so the dependency never applies
+			continue
+		}
+		if dep.schema == nil {
+			if !requires(a, dep.props...) && !(ad.schema == nil && containsStrings(ad.props, dep.props)) {
+				return false
+			}
+			continue
+		}
+		if !c.subset(a, dep.schema) && !(ad.schema != nil && c.subset(ad.schema, dep.schema)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *subsetChecker) propertyNamesSubset(a, names *Schema) bool {
+	if pn, ok := a.Validators["propertyNames"].(*PropertyNames); ok && c.subset((*Schema)(pn), names) {
+		return true
+	}
+	// a closed schema can only have the properties it lists
+	add, ok := a.Validators["additionalProperties"].(*AdditionalProperties)
+	if !ok || !isFalseSchema(add.Schema) || a.Validators["patternProperties"] != nil {
+		return false
+	}
+	if props, ok := a.Validators["properties"].(*Properties); ok {
+		for name := range *props {
+			if !isValid(names, name) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// disjoint reports whether no instance can be valid against both a and b,
+// again erring towards false
+func (c *subsetChecker) disjoint(a, b *Schema) bool {
+	a, ok := resolveSchema(a)
+	if !ok {
+		return false
+	}
+	if a.schemaType == schemaTypeFalse {
+		return true
+	}
+	if vals, ok := enumerable(a); ok {
+		for _, v := range vals {
+			if isValid(a, v) && isValid(b, v) {
+				return false
+			}
+		}
+		return true
+	}
+	b, ok = resolveSchema(b)
+	if !ok {
+		return false
+	}
+	if b.schemaType == schemaTypeFalse {
+		return true
+	}
+	if a.schemaType != schemaTypeObject || b.schemaType != schemaTypeObject ||
+		a.Validators["type"] == nil || b.Validators["type"] == nil {
+		return false
+	}
+	return len(intersectTypes(schemaTypes(a), schemaTypes(b))) == 0
+}
+
+// resolveSchema follows references, reporting false for unresolved ones
+func resolveSchema(s *Schema) (*Schema, bool) {
+	for i := 0; s != nil && s.Ref != ""; i++ {
+		if i > maxSubsetDepth {
+			return nil, false
+		}
+		switch ref := s.ref.(type) {
+		case *Schema:
+			s = ref
+		case *RootSchema:
+			s = &ref.Schema
+		default:
+			return nil, false
+		}
+	}
+	if s == nil {
+		return trueSchema, true
+	}
+	return s, true
+}
+
+// isTrueSchema reports whether s accepts every instance
+func isTrueSchema(s *Schema) bool {
+	return s.schemaType == schemaTypeTrue ||
+		(s.schemaType == schemaTypeObject && s.Ref == "" && len(s.Validators) == 0)
+}
+
+func isFalseSchema(s *Schema) bool {
+	return s != nil && s.schemaType == schemaTypeFalse
+}
+
+// without returns a shallow copy of s lacking the given keywords
+func without(s *Schema, keys ...string) *Schema {
+	cp := *s
+	cp.Validators = map[string]Validator{}
+	for key, v := range s.Validators {
+		cp.Validators[key] = v
+	}
+	for _, key := range keys {
+		delete(cp.Validators, key)
+	}
+	return &cp
+}
+
+// enumerable lists every instance s could accept when that set is finite.
+// Listed values may still be invalid against other keywords of s
+func enumerable(s *Schema) ([]interface{}, bool) {
+	if con, ok := s.Validators["const"].(*Const); ok {
+		var v interface{}
+		if err := json.Unmarshal(*con, &v); err != nil {
+			return nil, false
+		}
+		return []interface{}{v}, true
+	}
+	if enum, ok := s.Validators["enum"].(*Enum); ok {
+		vals := make([]interface{}, 0, len(*enum))
+		for _, con := range *enum {
+			var v interface{}
+			if err := json.Unmarshal(con, &v); err != nil {
+				return nil, false
+			}
+			vals = append(vals, v)
+		}
+		return vals, true
+	}
+	if _, ok := s.Validators["type"]; !ok {
+		return nil, false
+	}
+	vals := []interface{}{}
+	for _, t := range schemaTypes(s) {
+		switch t {
+		case "null":
+			vals = append(vals, nil)
+		case "boolean":
+			vals = append(vals, true, false)
+		default:
+			return nil, false
+		}
+	}
+	return vals, true
+}
+
+func isValid(s *Schema, v interface{}) bool {
+	errs := []ValError{}
+	s.Validate("/", v, &errs)
+	return len(errs) == 0
+}
+
+// schemaTypes gives the instance types s allows by its type keyword
+func schemaTypes(s *Schema) []string {
+	if t, ok := s.Validators["type"].(*Type); ok {
+		return t.vals
+	}
+	return allTypes
+}
+
+// upperBound reports whether a caps numbers at max, exclusively if excl is set
+func upperBound(a *Schema, max float64, excl bool) bool {
+	if m, ok := a.Validators["maximum"].(*Maximum); ok {
+		if float64(*m) < max || (!excl && float64(*m) == max) {
+			return true
+		}
+	}
+	if m, ok := a.Validators["exclusiveMaximum"].(*ExclusiveMaximum); ok && float64(*m) <= max {
+		return true
+	}
+	return false
+}
+
+// lowerBound reports whether a floors numbers at min, exclusively if excl is set
+func lowerBound(a *Schema, min float64, excl bool) bool {
+	if m, ok := a.Validators["minimum"].(*Minimum); ok {
+		if float64(*m) > min || (!excl && float64(*m) == min) {
+			return true
+		}
+	}
+	if m, ok := a.Validators["exclusiveMinimum"].(*ExclusiveMinimum); ok && float64(*m) >= min {
+		return true
+	}
+	return false
+}
+
+// requires reports whether a requires every named property
+func requires(a *Schema, names ...string) bool {
+	req, _ := a.Validators["required"].(*Required)
+	if req == nil {
+		return len(names) == 0
+	}
+	return containsStrings(*req, names)
+}
+
+func containsStrings(set, strs []string) bool {
+	for _, str := range strs {
+		found := false
+		for _, s := range set {
+			if s == str {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sameKeyword reports whether a and b share an equal value for key
+func sameKeyword(a, b *Schema, key string) bool {
+	av, ok := a.Validators[key]
+	if !ok {
+		return false
+	}
+	return Equal(keywordSchema(av), keywordSchema(b.Validators[key]))
+}
@@ -0,0 +1,54 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestIsSubschemaOf(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect bool
+	}{
+		{`false`, `{ "type": "string" }`, true},
+		{`{ "type": "string" }`, `true`, true},
+		{`true`, `{ "type": "string" }`, false},
+		{`{ "type": "integer" }`, `{ "type": "number" }`, true},
+		{`{ "type": "number" }`, `{ "type": "integer" }`, false},
+		{`{ "type": "integer" }`, `{ "multipleOf": 1 }`, true},
+		{`{ "type": "string", "maxLength": 3 }`, `{ "type": "string", "maxLength": 5 }`, true},
+		{`{ "type": "string", "maxLength": 5 }`, `{ "type": "string", "maxLength": 3 }`, false},
+		{`{ "type": "string" }`, `{ "maximum": 3 }`, true},
+		{`{ "exclusiveMaximum": 3 }`, `{ "maximum": 3 }`, true},
+		{`{ "maximum": 3 }`, `{ "exclusiveMaximum": 3 }`, false},
+		{`{ "enum": ["a", "b"] }`, `{ "type": "string", "maxLength": 1 }`, true},
+		{`{ "enum": ["a", "bb"] }`, `{ "type": "string", "maxLength": 1 }`, false},
+		{`{ "type": "boolean" }`, `{ "enum": [true, false, null] }`, true},
+		{`{ "required": ["a", "b"] }`, `{ "required": ["a"] }`, true},
+		{`{ "required": ["a"] }`, `{ "required": ["a", "b"] }`, false},
+		{`{ "properties": { "a": { "type": "integer" } }, "additionalProperties": false }`,
+			`{ "properties": { "a": { "type": "number" } }, "additionalProperties": { "type": "string" } }`, true},
+		{`{ "properties": { "a": { "type": "integer" } } }`,
+			`{ "additionalProperties": { "type": "string" } }`, false},
+		{`{ "patternProperties": { "^x-": { "type": "string" } }, "additionalProperties": false }`,
+			`{ "patternProperties": { "^x-": { "type": ["string", "null"] } } }`, true},
+		{`{ "items": { "type": "integer" } }`, `{ "items": [{ "type": "number" }], "additionalItems": { "type": "integer" } }`, true},
+		{`{ "items": [{ "type": "string" }] }`, `{ "items": { "type": "string" } }`, false},
+		{`{ "items": [{ "type": "string" }], "additionalItems": false }`, `{ "items": { "type": "string" } }`, true},
+		{`{ "anyOf": [{ "type": "string" }, { "type": "integer" }] }`, `{ "type": ["string", "number"] }`, true},
+		{`{ "type": "string" }`, `{ "anyOf": [{ "type": "integer" }, { "type": "string" }] }`, true},
+		{`{ "allOf": [{ "type": "string" }, { "maxLength": 2 }] }`, `{ "type": "string", "maxLength": 4 }`, true},
+		{`{ "type": "string" }`, `{ "not": { "type": "null" } }`, true},
+		{`{ "type": "string" }`, `{ "not": { "maxLength": 3 } }`, false},
+		{`{ "type": "string", "pattern": "^a" }`, `{ "pattern": "^a" }`, true},
+		{`{ "type": "string", "pattern": "^ab" }`, `{ "pattern": "^a" }`, false},
+		{`{ "definitions": { "n": { "type": "object", "properties": { "next": { "$ref": "#/definitions/n" } } } }, "$ref": "#/definitions/n" }`,
+			`{ "definitions": { "t": { "type": ["object", "null"], "properties": { "next": { "$ref": "#/definitions/t" } } } }, "$ref": "#/definitions/t" }`, true},
+	}
+
+	for i, c := range cases {
+		a, b := Must(c.a), Must(c.b)
+		if got := IsSubschemaOf(&a.Schema, &b.Schema); got != c.expect {
+			t.Errorf("case %d: expected %t, got: %t", i, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package jsonschema
+
+import (
+	"strings"
+	"time"
+)
+
+// TimeFormatOptions configures the "date-time", "date" and "time" formats,
+// which otherwise only accept RFC 3339
+type TimeFormatOptions struct {
+	// Lenient accepts common variations of RFC 3339: a space between the
+	// date and time, lowercase "t" and "z", date-times and times without a
+	// time zone, and times without seconds
+	Lenient bool
+	// Layouts are the layouts of the time package each format accepts in
+	// addition, keyed by format name, such as
+	// {"date": {"02/01/2006"}}
+	Layouts map[string][]string
+}
+
+// RegisterLayout adds layout to those o accepts for format, one of
+// "date-time", "date" and "time"
+func (o *TimeFormatOptions) RegisterLayout(format, layout string) {
+	if o.Layouts == nil {
+		o.Layouts = map[string][]string{}
+	}
+	o.Layouts[format] = append(o.Layouts[format], layout)
+}
+
+// lenientTimeLayouts are the layouts lenient parsing accepts beyond RFC 3339
+var lenientTimeLayouts = map[string][]string{
+	"date-time": {time.RFC3339, "2006-01-02 15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02 15:04:05"},
+	"time":      {"15:04:05Z07:00", "15:04:05", "15:04"},
+}
+
+// accepts reports whether str, which isn't RFC 3339, is a valid value of
+// format according to the options
+func (o TimeFormatOptions) accepts(format, str string) bool {
+	_, ok := o.parse(format, str)
+	return ok
+}
+
+// parse reads str, which isn't RFC 3339, as a value of format according to
+// the options
+func (o TimeFormatOptions) parse(format, str string) (time.Time, bool) {
+	if o.Lenient {
+		// RFC 3339 layouts only match an uppercase "T" and "Z"
+		for _, layout := range lenientTimeLayouts[format] {
+			if t, err := time.Parse(layout, strings.ToUpper(str)); err == nil {
+				return t, true
+			}
+		}
+	}
+	for _, layout := range o.Layouts[format] {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,87 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestTimeFormats(t *testing.T) {
+	cases := []struct {
+		format, value            string
+		strict, lenient, layouts bool
+	}{
+		{"date-time", "2020-01-02T15:04:05Z", true, true, true},
+		{"date-time", "2020-01-02T15:04:05.123+02:00", true, true, true},
+		{"date-time", "2020-01-02 15:04:05Z", false, true, true},
+		{"date-time", "2020-01-02t15:04:05z", false, true, true},
+		{"date-time", "2020-01-02T15:04:05", false, true, true},
+		{"date-time", "2020-01-02 15:04:05.5", false, true, true},
+		{"date-time", "2020-01-02T25:04:05", false, false, false},
+		{"date-time", "Jan 2, 2020 at 3:04pm", false, false, true},
+		{"date", "2020-01-02", true, true, true},
+		{"date", "02/01/2020", false, false, true},
+		{"date", "2020-13-02", false, false, false},
+		{"time", "15:04:05Z", true, true, true},
+		{"time", "15:04:05", false, true, true},
+		{"time", "15:04", false, true, true},
+		{"time", "3:04pm", false, false, true},
+		{"time", "15:60", false, false, false},
+	}
+	opts := TimeFormatOptions{}
+	check := func(mode string, want func(strict, lenient, layouts bool) bool) {
+		for _, c := range cases {
+			rs := Must(`{"format": "` + c.format + `"}`)
+			rs.SetOptions(Options{Formats: FormatOptions{Time: opts}})
+			errs := []ValError{}
+			rs.Validate("/", c.value, &errs)
+			if valid := len(errs) == 0; valid != want(c.strict, c.lenient, c.layouts) {
+				t.Errorf("%s %s %q: expected valid to be %t, got %v", mode, c.format, c.value, !valid, errs)
+			}
+		}
+	}
+
+	check("strict", func(strict, _, _ bool) bool { return strict })
+
+	opts.Lenient = true
+	check("lenient", func(_, lenient, _ bool) bool { return lenient })
+
+	opts.RegisterLayout("date-time", "Jan 2, 2006 at 3:04pm")
+	opts.RegisterLayout("date", "02/01/2006")
+	opts.RegisterLayout("time", "3:04pm")
+	check("layouts", func(_, _, layouts bool) bool { return layouts })
+
+	opts.Lenient = false
+	rs := Must(`{"format": "date-time"}`)
+	rs.SetOptions(Options{Formats: FormatOptions{Time: opts}})
+	errs := []ValError{}
+	rs.Validate("/", "2020-01-02 15:04:05Z", &errs)
+	if len(errs) != 1 {
+		t.Errorf("expected registered layouts alone not to make parsing lenient, got %v", errs)
+	}
+}
+
+func TestDurationFormat(t *testing.T) {
+	valid := []string{
+		"P4Y", "P1Y2M3DT4H5M6S", "PT0S", "P0D", "P1M", "PT1M", "PT36H", "P1DT12H", "P4W",
+		"P1Y1D", "PT1H1S", "PT1.5S", "P0,5D", "P1YT0.25H", "P1.5W",
+	}
+	invalid := []string{
+		"", "1D", "P", "PT", "P1YT", "PT1D", "P2D1Y", "P1D2H", "P2S", "P1", "P1W2D", "P1Y2W", "P1WT1H",
+		"P1.5Y2M", "PT1.S", "P.5D", "P1DT", "P1DD", "PT1H2H", "P1TT1H", "P١D", "p1d",
+	}
+	for _, dur := range valid {
+		if err := isValidDuration(dur); err != nil {
+			t.Errorf("%q: %s", dur, err.Error())
+		}
+	}
+	for _, dur := range invalid {
+		if err := isValidDuration(dur); err == nil {
+			t.Errorf("%q: expected an error", dur)
+		}
+	}
+
+	errs := []ValError{}
+	Format("duration").Validate("/", "P1W2D", &errs)
+	if len(errs) != 1 || errs[0].Message != "invalid duration: weeks can't be combined with other components" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,679 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidateTOML performs schema validation against a TOML document, decoded
+// by DecodeTOML with the decoding options of rs
+func (rs *RootSchema) ValidateTOML(data []byte) ([]ValError, error) {
+	return rs.ValidateEncoded(data, rs.options().Decoding.DecodeTOML)
+}
+
+// DecodeTOML decodes a TOML document into the values validation works
+// with. Tables, including inline tables, become objects, arrays and arrays
+// of tables become arrays, and integers and floats become numbers. Offset
+// date-times become RFC 3339 date-time strings, so they satisfy the
+// "date-time" format, and local dates and times become strings as they're
+// written, with a "T" between date and time. Redefined keys and tables are
+// errors, as are infinities and NaN, which JSON can't represent. Inline
+// tables may span lines and end with a comma, as TOML 1.1 allows. It's an
+// InstanceDecoder
+func DecodeTOML(data []byte) (interface{}, error) {
+	return DecodeOptions{}.DecodeTOML(data)
+}
+
+// DecodeTOML decodes a TOML document as the DecodeTOML function does, with
+// the options o
+func (o DecodeOptions) DecodeTOML(data []byte) (interface{}, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("toml: document isn't valid UTF-8")
+	}
+	p := &tomlParser{src: data, root: newTOMLTable(), opts: o}
+	p.root.explicit = true
+	p.current = p.root
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root.value(), nil
+}
+
+// tomlTable is a table being decoded. explicit tables have been given a
+// header, dotted ones were created by dotted keys and inline ones can't be
+// changed
+type tomlTable struct {
+	vals     map[string]interface{}
+	explicit bool
+	dotted   bool
+	inline   bool
+}
+
+func newTOMLTable() *tomlTable {
+	return &tomlTable{vals: map[string]interface{}{}}
+}
+
+// tomlTableArray is an array of tables, to which [[headers]] add tables
+type tomlTableArray struct {
+	tables []*tomlTable
+}
+
+// value converts t to a map of plain values
+func (t *tomlTable) value() map[string]interface{} {
+	obj := make(map[string]interface{}, len(t.vals))
+	for key, v := range t.vals {
+		obj[key] = tomlValue(v)
+	}
+	return obj
+}
+
+// tomlValue converts a decoded value to a plain value
+func tomlValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *tomlTable:
+		return t.value()
+	case *tomlTableArray:
+		arr := make([]interface{}, len(t.tables))
+		for i, table := range t.tables {
+			arr[i] = table.value()
+		}
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, item := range t {
+			arr[i] = tomlValue(item)
+		}
+		return arr
+	}
+	return v
+}
+
+// freeze marks t and the tables within it as inline
+func (t *tomlTable) freeze() {
+	t.inline = true
+	for _, v := range t.vals {
+		if sub, ok := v.(*tomlTable); ok {
+			sub.freeze()
+		}
+	}
+}
+
+// tomlParser reads a TOML document from src. current is the table key/value
+// pairs are added to
+type tomlParser struct {
+	src     []byte
+	pos     int
+	root    *tomlTable
+	current *tomlTable
+	opts    DecodeOptions
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := bytes.Count(p.src[:p.pos], []byte{'\n'}) + 1
+	return fmt.Errorf("toml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.src[p.pos:], []byte(prefix))
+}
+
+func (p *tomlParser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// skipComment moves past a comment, if one is next
+func (p *tomlParser) skipComment() {
+	if p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank moves past whitespace, comments and line breaks
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpaces()
+		p.skipComment()
+		switch {
+		case p.peek() == '\n':
+			p.pos++
+		case p.hasPrefix("\r\n"):
+			p.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+// endLine expects the end of a line, allowing a comment
+func (p *tomlParser) endLine() error {
+	p.skipSpaces()
+	p.skipComment()
+	switch {
+	case p.eof():
+	case p.peek() == '\n':
+		p.pos++
+	case p.hasPrefix("\r\n"):
+		p.pos += 2
+	default:
+		return p.errorf("expected the end of the line, got %q", p.peek())
+	}
+	return nil
+}
+
+// parse reads the document's expressions
+func (p *tomlParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+		var err error
+		switch {
+		case p.hasPrefix("[["):
+			err = p.tableArrayHeader()
+		case p.peek() == '[':
+			err = p.tableHeader()
+		default:
+			err = p.keyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// header reads the key of a table header ending with closing
+func (p *tomlParser) header(closing string) ([]string, error) {
+	p.pos += len(closing)
+	p.skipSpaces()
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.hasPrefix(closing) {
+		return nil, p.errorf("expected %q to close the table header", closing)
+	}
+	p.pos += len(closing)
+	return keys, nil
+}
+
+// descend walks keys from the root for a header, creating tables as needed
+// and entering the last table of arrays of tables
+func (p *tomlParser) descend(keys []string) (*tomlTable, error) {
+	t := p.root
+	for _, key := range keys {
+		switch v := t.vals[key].(type) {
+		case nil:
+			sub := newTOMLTable()
+			t.vals[key] = sub
+			t = sub
+		case *tomlTable:
+			if v.inline {
+				return nil, p.errorf("inline table %q can't be extended", key)
+			}
+			t = v
+		case *tomlTableArray:
+			t = v.tables[len(v.tables)-1]
+		default:
+			return nil, p.errorf("key %q is already defined as a value", key)
+		}
+	}
+	return t, nil
+}
+
+// tableHeader reads a [table] header, making it the current table
+func (p *tomlParser) tableHeader() error {
+	keys, err := p.header("]")
+	if err != nil {
+		return err
+	}
+	parent, err := p.descend(keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	switch v := parent.vals[last].(type) {
+	case nil:
+		t := newTOMLTable()
+		t.explicit = true
+		parent.vals[last] = t
+		p.current = t
+	case *tomlTable:
+		if v.explicit || v.dotted || v.inline {
+			return p.errorf("table %q is already defined", strings.Join(keys, "."))
+		}
+		v.explicit = true
+		p.current = v
+	default:
+		return p.errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	return nil
+}
+
+// tableArrayHeader reads a [[table]] header, adding a table to its array
+func (p *tomlParser) tableArrayHeader() error {
+	keys, err := p.header("]]")
+	if err != nil {
+		return err
+	}
+	parent, err := p.descend(keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	t := newTOMLTable()
+	t.explicit = true
+	switch v := parent.vals[last].(type) {
+	case nil:
+		parent.vals[last] = &tomlTableArray{tables: []*tomlTable{t}}
+	case *tomlTableArray:
+		v.tables = append(v.tables, t)
+	default:
+		return p.errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	p.current = t
+	return nil
+}
+
+// keyValue reads a key/value pair into t
+func (p *tomlParser) keyValue(t *tomlTable) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return p.errorf("expected \"=\" after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpaces()
+	val, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys[:len(keys)-1] {
+		switch v := t.vals[key].(type) {
+		case nil:
+			sub := newTOMLTable()
+			sub.dotted = true
+			t.vals[key] = sub
+			t = sub
+		case *tomlTable:
+			if v.inline || (v.explicit && !v.dotted) {
+				return p.errorf("table %q can't be extended with dotted keys", key)
+			}
+			t = v
+		default:
+			return p.errorf("key %q is already defined", strings.Join(keys, "."))
+		}
+	}
+	last := keys[len(keys)-1]
+	if _, ok := t.vals[last]; ok {
+		return p.errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	t.vals[last] = val
+	return nil
+}
+
+// key reads a key, which may be dotted
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		var key string
+		switch c := p.peek(); {
+		case c == '"':
+			if p.hasPrefix(`"""`) {
+				return nil, p.errorf("keys can't be multi-line strings")
+			}
+			str, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			key = str
+		case c == '\'':
+			if p.hasPrefix("'''") {
+				return nil, p.errorf("keys can't be multi-line strings")
+			}
+			str, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			key = str
+		default:
+			start := p.pos
+			for isTOMLBareKey(p.peek()) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorf("expected a key, got %q", p.peek())
+			}
+			key = string(p.src[start:p.pos])
+		}
+		keys = append(keys, key)
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+		p.skipSpaces()
+	}
+}
+
+func isTOMLBareKey(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// value reads a value
+func (p *tomlParser) value() (interface{}, error) {
+	switch c := p.peek(); {
+	case p.hasPrefix(`"""`):
+		return p.multilineString(true)
+	case p.hasPrefix("'''"):
+		return p.multilineString(false)
+	case c == '"':
+		return p.basicString()
+	case c == '\'':
+		return p.literalString()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	case c == 0:
+		return nil, p.errorf("expected a value")
+	}
+
+	start := p.pos
+	for !p.eof() && (isTOMLBareKey(p.peek()) || strings.IndexByte("+.:", p.peek()) != -1) {
+		p.pos++
+	}
+	// dates and times may be separated by a space
+	if tomlDate.Match(p.src[start:p.pos]) && p.peek() == ' ' && p.pos+1 < len(p.src) && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9' {
+		p.pos++
+		for !p.eof() && (isTOMLBareKey(p.peek()) || strings.IndexByte("+.:", p.peek()) != -1) {
+			p.pos++
+		}
+	}
+	token := string(p.src[start:p.pos])
+	if token == "" {
+		return nil, p.errorf("expected a value, got %q", p.peek())
+	}
+	p.pos = start
+	v, err := tomlScalar(p.opts, token)
+	if err != nil {
+		return nil, p.errorf("%s", err.Error())
+	}
+	p.pos += len(token)
+	return v, nil
+}
+
+var (
+	tomlDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlDateTime = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})[Tt ](\d{2}:\d{2}:\d{2}(?:\.\d+)?)([Zz]|[+-]\d{2}:\d{2})?$`)
+	tomlTime     = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(?:\.\d+)?$`)
+	tomlInteger  = regexp.MustCompile(`^[+-]?(?:0|[1-9](?:_?\d)*)$`)
+	tomlRadix    = regexp.MustCompile(`^0(?:x[0-9A-Fa-f](?:_?[0-9A-Fa-f])*|o[0-7](?:_?[0-7])*|b[01](?:_?[01])*)$`)
+	tomlFloat    = regexp.MustCompile(`^[+-]?(?:0|[1-9](?:_?\d)*)(?:\.\d(?:_?\d)*)?(?:[eE][+-]?\d(?:_?\d)*)?$`)
+)
+
+// tomlScalar types a boolean, number, date or time, decoding numbers as o
+// sets
+func tomlScalar(o DecodeOptions, token string) (interface{}, error) {
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return nil, fmt.Errorf("%s can't be represented in JSON", token)
+	}
+	if m := tomlDateTime.FindStringSubmatch(token); m != nil {
+		str := m[1] + "T" + m[2] + strings.ToUpper(m[3])
+		layout := "2006-01-02T15:04:05.999999999"
+		if m[3] != "" {
+			layout = time.RFC3339Nano
+		}
+		if _, err := time.Parse(layout, str); err != nil {
+			return nil, fmt.Errorf("invalid date-time %s", token)
+		}
+		return str, nil
+	}
+	if tomlDate.MatchString(token) {
+		if _, err := time.Parse("2006-01-02", token); err != nil {
+			return nil, fmt.Errorf("invalid date %s", token)
+		}
+		return token, nil
+	}
+	if tomlTime.MatchString(token) {
+		if _, err := time.Parse("15:04:05.999999999", token); err != nil {
+			return nil, fmt.Errorf("invalid time %s", token)
+		}
+		return token, nil
+	}
+
+	digits := strings.Replace(token, "_", "", -1)
+	switch {
+	case tomlInteger.MatchString(token):
+		i, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s is out of range", token)
+		}
+		return o.decodedInt(i), nil
+	case tomlRadix.MatchString(token):
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[digits[1]]
+		i, err := strconv.ParseInt(digits[2:], base, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s is out of range", token)
+		}
+		return o.decodedInt(i), nil
+	case tomlFloat.MatchString(token):
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("float %s is out of range", token)
+		}
+		return o.decodedFloat(f), nil
+	}
+	return nil, fmt.Errorf("invalid value %s", token)
+}
+
+// tomlEscapes maps the single-character escapes of basic strings
+var tomlEscapes = map[byte]string{
+	'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", 'e': "\x1b",
+	'"': "\"", '\\': "\\",
+}
+
+// escape reads an escape sequence of a basic string
+func (p *tomlParser) escape(buf *strings.Builder) error {
+	c := byte(0)
+	if p.pos+1 < len(p.src) {
+		c = p.src[p.pos+1]
+	}
+	if s, ok := tomlEscapes[c]; ok {
+		buf.WriteString(s)
+		p.pos += 2
+		return nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if digits == 0 || p.pos+2+digits > len(p.src) {
+		return p.errorf("invalid escape \\%c", c)
+	}
+	code, err := strconv.ParseUint(string(p.src[p.pos+2:p.pos+2+digits]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid escape \\%s", p.src[p.pos+1:p.pos+2+digits])
+	}
+	buf.WriteRune(rune(code))
+	p.pos += 2 + digits
+	return nil
+}
+
+// basicString reads a single-line "basic string"
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	buf := &strings.Builder{}
+	for {
+		switch c := p.peek(); {
+		case p.eof() || c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '"':
+			p.pos++
+			return buf.String(), nil
+		case c == '\\':
+			if err := p.escape(buf); err != nil {
+				return "", err
+			}
+		default:
+			buf.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// literalString reads a single-line 'literal string'
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	start := p.pos
+	for p.peek() != '\'' {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		p.pos++
+	}
+	p.pos++
+	return string(p.src[start : p.pos-1]), nil
+}
+
+// multilineString reads a multi-line basic or literal string, quoted by
+// three double or single quotes. A line break right after the opening
+// quotes is trimmed, as are line breaks and whitespace after a backslash
+// ending a line of a basic string
+func (p *tomlParser) multilineString(basic bool) (string, error) {
+	quote := p.peek()
+	p.pos += 3
+	if p.peek() == '\n' {
+		p.pos++
+	} else if p.hasPrefix("\r\n") {
+		p.pos += 2
+	}
+	buf := &strings.Builder{}
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		c := p.peek()
+		switch {
+		case c == quote && p.hasPrefix(strings.Repeat(string(quote), 3)):
+			// up to two quotes may precede the closing ones
+			n := 3
+			for n < 5 && p.pos+n < len(p.src) && p.src[p.pos+n] == quote {
+				n++
+			}
+			buf.WriteString(strings.Repeat(string(quote), n-3))
+			p.pos += n
+			return buf.String(), nil
+		case basic && c == '\\':
+			rest := p.pos + 1
+			for rest < len(p.src) && (p.src[rest] == ' ' || p.src[rest] == '\t' || p.src[rest] == '\r') {
+				rest++
+			}
+			if rest < len(p.src) && p.src[rest] == '\n' {
+				p.pos = rest
+				for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) != -1 {
+					p.pos++
+				}
+				continue
+			}
+			if err := p.escape(buf); err != nil {
+				return "", err
+			}
+		case p.hasPrefix("\r\n"):
+			buf.WriteByte('\n')
+			p.pos += 2
+		default:
+			buf.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// array reads an array, which may span lines and end with a comma
+func (p *tomlParser) array() (interface{}, error) {
+	p.pos++
+	arr := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			if p.eof() {
+				return nil, p.errorf("unterminated array")
+			}
+			return nil, p.errorf("expected \",\" or \"]\" in array, got %q", p.peek())
+		}
+	}
+}
+
+// inlineTable reads an { inline = "table" }
+func (p *tomlParser) inlineTable() (interface{}, error) {
+	p.pos++
+	t := newTOMLTable()
+	for {
+		p.skipBlank()
+		if p.peek() == '}' {
+			p.pos++
+			t.freeze()
+			return t, nil
+		}
+		if err := p.keyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			if p.eof() {
+				return nil, p.errorf("unterminated inline table")
+			}
+			return nil, p.errorf("expected \",\" or \"}\" in inline table, got %q", p.peek())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	cases := []struct {
+		toml, json string
+	}{
+		{`title = "TOML" # comment`, `{"title":"TOML"}`},
+		{"a = 1\nb = -2_000\nc = 0xff\nd = 0o17\ne = 0b101\nf = 1.5e3\ng = -0.25\nh = true\ni = false", `{"a":1,"b":-2000,"c":255,"d":15,"e":5,"f":1500,"g":-0.25,"h":true,"i":false}`},
+		{`s = "tab\tquote\" \u00e9 \U0001F600"` + "\n" + `l = 'C:\path'`, `{"l":"C:\\path","s":"tab\tquote\" é 😀"}`},
+		{"m = \"\"\"\nline one\nline two\\\n    joined\"\"\"\nr = '''\nraw \\n ''\n'''", `{"m":"line one\nline twojoined","r":"raw \\n ''\n"}`},
+		{`q = """quotes at the end"""""`, `{"q":"quotes at the end\"\""}`},
+		{"odt = 1979-05-27T07:32:00Z\nodt2 = 1979-05-27 00:32:00.5-07:00\nldt = 1979-05-27T07:32:00\nld = 1979-05-27\nlt = 07:32:00.999", `{"ld":"1979-05-27","ldt":"1979-05-27T07:32:00","lt":"07:32:00.999","odt":"1979-05-27T07:32:00Z","odt2":"1979-05-27T00:32:00.5-07:00"}`},
+		{"arr = [\n  1,\n  'two', # comment\n  [3],\n]\nempty = []", `{"arr":[1,"two",[3]],"empty":[]}`},
+		{"point = { x = 1, y.z = 2 }\n\"quoted key\" = 1\n'lit' = 2\na.b . c = 3", `{"a":{"b":{"c":3}},"lit":2,"point":{"x":1,"y":{"z":2}},"quoted key":1}`},
+		{"[server]\nhost = \"a\"\n[server.tls]\nenabled = true\n[client]\nport = 80", `{"client":{"port":80},"server":{"host":"a","tls":{"enabled":true}}}`},
+		{"[x.y.z]\nv = 1\n[x]\nw = 2", `{"x":{"w":2,"y":{"z":{"v":1}}}}`},
+		{"[[fruit]]\nname = \"apple\"\n[fruit.physical]\ncolor = \"red\"\n[[fruit.variety]]\nname = \"fuji\"\n[[fruit]]\nname = \"banana\"", `{"fruit":[{"name":"apple","physical":{"color":"red"},"variety":[{"name":"fuji"}]},{"name":"banana"}]}`},
+		{"[fruit]\napple.color = \"red\"\n[fruit.apple.texture]\nsmooth = true", `{"fruit":{"apple":{"color":"red","texture":{"smooth":true}}}}`},
+		{"a = 1\r\nb = 2\r\n", `{"a":1,"b":2}`},
+		{"", `{}`},
+	}
+	for i, c := range cases {
+		v, err := DecodeTOML([]byte(c.toml))
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		got, _ := json.Marshal(v)
+		if string(got) != c.json {
+			t.Errorf("case %d: expected %s, got %s", i, c.json, got)
+		}
+	}
+}
+
+func TestDecodeTOMLErrors(t *testing.T) {
+	cases := []struct {
+		toml, err string
+	}{
+		{"a = 1\na = 2", `toml: line 2: key "a" is already defined`},
+		{"[a]\n[a]", `toml: line 2: table "a" is already defined`},
+		{"[a]\nb.c = 1\n[a.b]", `toml: line 3: table "a.b" is already defined`},
+		{"a = {b = 1}\n[a]", `toml: line 2: table "a" is already defined`},
+		{"a = {b = 1}\n[a.c]", `toml: line 2: inline table "a" can't be extended`},
+		{"a = [1]\n[[a]]", `toml: line 2: key "a" is already defined`},
+		{"a = 1\nb = \"open", `toml: line 2: unterminated string`},
+		{"a = [1, 2", `toml: line 1: unterminated array`},
+		{"a = 1 b = 2", `toml: line 1: expected the end of the line, got 'b'`},
+		{"a = 01", `toml: line 1: invalid value 01`},
+		{"a = inf", `toml: line 1: inf can't be represented in JSON`},
+		{"a = 1979-02-30", `toml: line 1: invalid date 1979-02-30`},
+		{"a = 9223372036854775808", `toml: line 1: integer 9223372036854775808 is out of range`},
+		{"a", `toml: line 1: expected "=" after key "a"`},
+		{"= 1", `toml: line 1: expected a key, got '='`},
+		{`a = "\q"`, `toml: line 1: invalid escape \q`},
+	}
+	for _, c := range cases {
+		_, err := DecodeTOML([]byte(c.toml))
+		if err == nil {
+			t.Errorf("%q: expected error %q", c.toml, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.toml, c.err, err.Error())
+		}
+	}
+}
+
+func TestValidateTOML(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["server"],
+		"properties": {
+			"server": {
+				"type": "object",
+				"required": ["port"],
+				"properties": {
+					"port": {"type": "integer", "maximum": 65535},
+					"started": {"type": "string", "format": "date-time"}
+				}
+			},
+			"users": {"type": "array", "items": {"type": "object", "required": ["name"]}}
+		}
+	}`)
+	cases := []struct {
+		toml string
+		errs int
+	}{
+		{"[server]\nport = 8080\nstarted = 2020-09-13T12:26:40Z\n[[users]]\nname = \"a\"", 0},
+		{"[server]\nport = 70000\n[[users]]\nrole = \"admin\"", 2},
+		{"[client]\nport = 1", 1},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateTOML([]byte(c.toml))
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != c.errs {
+			t.Errorf("case %d: expected %d errors, got %v", i, c.errs, errs)
+		}
+	}
+
+	if _, err := rs.ValidateTOML([]byte("port = ")); err == nil {
+		t.Error("expected an error for invalid TOML")
+	}
+}
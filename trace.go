@@ -0,0 +1,294 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// Trace is a node of an evaluation trace, recording how a schema or one of
+// its keywords judged a value. Schema nodes have no Keyword and hold a node
+// for each keyword evaluated, while keyword nodes hold the schemas their
+// keyword applied, so the branches of "anyOf" show which of them matched
+// and "if" shows whether "then" or "else" followed
+type Trace struct {
+	// Keyword is the keyword evaluated, empty for schema nodes
+	Keyword string `json:"keyword,omitempty"`
+	// SchemaPath is the JSON pointer to the schema or keyword, relative to
+	// the root schema, through any references followed
+	SchemaPath string `json:"schemaPath"`
+	// InstancePath is the JSON pointer to the value evaluated
+	InstancePath string `json:"instancePath"`
+	// Valid is the outcome of the evaluation
+	Valid bool `json:"valid"`
+	// Errors are those the keyword reported, including the errors of the
+	// schemas it applied. Schema nodes only hold errors of boolean schemas
+	Errors   []ValError `json:"errors,omitempty"`
+	Children []*Trace   `json:"children,omitempty"`
+	// member is the property name or array index of the value within the
+	// one the parent keyword evaluated, empty when they're the same
+	member string
+}
+
+// ValidateTrace validates data as Validate does, recording every keyword
+// evaluated in a trace to diagnose why a document is rejected. Keywords are
+// evaluated in sorted order. Tracing evaluates subschemas again for each
+// keyword that applies them, so it's meant for debugging rather than
+// everyday validation
+func (rs *RootSchema) ValidateTrace(data interface{}) *Trace {
+	vd := newValidation("/", data)
+	vd.opts = rs.opts
+	return traceSchema(vd, &rs.Schema, "", "/", data)
+}
+
+// Failures lists the keyword nodes of the trace that failed without any
+// of their applied schemas failing, which are where documents are rejected
+func (t *Trace) Failures() []*Trace {
+	var res []*Trace
+	var collect func(t *Trace)
+	collect = func(t *Trace) {
+		if t.Valid {
+			return
+		}
+		if t.Keyword != "" && len(t.Children) == 0 {
+			res = append(res, t)
+			return
+		}
+		before := len(res)
+		for _, ch := range t.Children {
+			collect(ch)
+		}
+		if len(res) == before && t.Keyword != "" {
+			res = append(res, t)
+		}
+	}
+	collect(t)
+	return res
+}
+
+// String renders the trace as an indented tree, a line per node
+func (t *Trace) String() string {
+	buf := &strings.Builder{}
+	t.write(buf, 0)
+	return buf.String()
+}
+
+func (t *Trace) write(buf *strings.Builder, depth int) {
+	outcome := "valid"
+	if !t.Valid {
+		outcome = "invalid"
+	}
+	name := t.Keyword
+	if name == "" {
+		name = fmt.Sprintf("schema %q", t.SchemaPath)
+	}
+	fmt.Fprintf(buf, "%s%s at %s: %s", strings.Repeat("  ", depth), name, t.InstancePath, outcome)
+	if len(t.Children) == 0 {
+		for i, err := range t.Errors {
+			sep := "; "
+			if i == 0 {
+				sep = ": "
+			}
+			buf.WriteString(sep + err.Message)
+		}
+	}
+	buf.WriteString("\n")
+	for _, ch := range t.Children {
+		ch.write(buf, depth+1)
+	}
+}
+
+// traceSchema evaluates s against data at instPath as part of vd, as
+// Schema.Validate does
+func traceSchema(vd *validation, s *Schema, schemaPath, instPath string, data interface{}) *Trace {
+	node := &Trace{SchemaPath: schemaPath, InstancePath: instPath, Valid: true}
+	if s == nil {
+		return node
+	}
+	if s.schemaType != schemaTypeObject {
+		s.validateIn(vd, instPath, data, &node.Errors)
+		node.Valid = len(node.Errors) == 0
+		return node
+	}
+
+	if s.Ref != "" {
+		ref := &Trace{Keyword: "$ref", SchemaPath: schemaPath + "/$ref", InstancePath: instPath}
+		s.validateIn(vd, instPath, data, &ref.Errors)
+		ref.Valid = len(ref.Errors) == 0
+		switch t := s.ref.(type) {
+		case *Schema:
+			ref.Children = []*Trace{traceSchema(vd, t, ref.SchemaPath, instPath, data)}
+		case *RootSchema:
+			ref.Children = []*Trace{traceSchema(vd, &t.Schema, ref.SchemaPath, instPath, data)}
+		}
+		node.Children = []*Trace{ref}
+		node.Valid = ref.Valid
+		return node
+	}
+
+	if t, ok := s.Validators["transform"].(*Transform); ok {
+		data = t.apply(data)
+	}
+
+	keys := make([]string, 0, len(s.Validators))
+	for key := range s.Validators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	d, discriminated := s.Validators["discriminator"].(*Discriminator)
+	null := data == nil && s.nullable()
+	for _, key := range keys {
+		switch {
+		case key == "oneOf" && discriminated && d.oneOf != nil:
+			continue
+		case null && (key == "type" || key == "enum"):
+			continue
+		case key == "then" || key == "else":
+			// evaluated as part of "if"
+			continue
+		}
+		v := s.Validators[key]
+		kw := &Trace{Keyword: key, SchemaPath: schemaPath + "/" + EscapePointerToken(key), InstancePath: instPath}
+		vd.validate(v, instPath, data, &kw.Errors)
+		kw.Valid = len(kw.Errors) == 0
+		kw.Children = traceApplied(vd, v, schemaPath, kw.SchemaPath, instPath, data)
+		node.Children = append(node.Children, kw)
+		node.Valid = node.Valid && kw.Valid
+	}
+	return node
+}
+
+// traceApplied traces the schemas the keyword v applies to data.
+// schemaPath is the path to the schema holding v and kwPath the path to v
+func traceApplied(vd *validation, v Validator, schemaPath, kwPath, instPath string, data interface{}) []*Trace {
+	var res []*Trace
+	branches := func(schemas []*Schema) {
+		for i, sch := range schemas {
+			res = append(res, traceSchema(vd, sch, kwPath+"/"+strconv.Itoa(i), instPath, data))
+		}
+	}
+	obj, _ := data.(map[string]interface{})
+	arr, _ := data.([]interface{})
+
+	switch t := v.(type) {
+	case *AllOf:
+		branches(*t)
+	case *AnyOf:
+		branches(*t)
+	case *OneOf:
+		branches(*t)
+	case *Not:
+		res = append(res, traceSchema(vd, (*Schema)(t), kwPath, instPath, data))
+	case *If:
+		cond := traceSchema(vd, &t.Schema, kwPath, instPath, data)
+		res = append(res, cond)
+		if cond.Valid && t.Then != nil {
+			res = append(res, traceSchema(vd, (*Schema)(t.Then), schemaPath+"/then", instPath, data))
+		} else if !cond.Valid && t.Else != nil {
+			res = append(res, traceSchema(vd, (*Schema)(t.Else), schemaPath+"/else", instPath, data))
+		}
+	case *Discriminator:
+		name, ok := obj[t.PropertyName].(string)
+		if t.oneOf == nil || !ok {
+			break
+		}
+		for i, sch := range *t.oneOf {
+			if sch == t.variant(name) {
+				res = append(res, traceSchema(vd, sch, schemaPath+"/oneOf/"+strconv.Itoa(i), instPath, data))
+			}
+		}
+	case *Properties:
+		for _, key := range sortedMapKeys(obj) {
+			if sch := (*t)[key]; sch != nil {
+				res = append(res, traceMember(vd, sch, kwPath+"/"+EscapePointerToken(key), instPath, key, obj[key]))
+			}
+		}
+	case *PatternProperties:
+		for _, key := range sortedMapKeys(obj) {
+			for _, ptn := range *t {
+				if ptn.re.MatchString(key) {
+					res = append(res, traceMember(vd, ptn.schema, kwPath+"/"+EscapePointerToken(ptn.key), instPath, key, obj[key]))
+				}
+			}
+		}
+	case *AdditionalProperties:
+		if t.Schema == nil {
+			break
+		}
+	KEYS:
+		for _, key := range sortedMapKeys(obj) {
+			if t.Properties != nil {
+				if _, ok := (*t.Properties)[key]; ok {
+					continue
+				}
+			}
+			if t.patterns != nil {
+				for _, ptn := range *t.patterns {
+					if ptn.re.MatchString(key) {
+						continue KEYS
+					}
+				}
+			}
+			res = append(res, traceMember(vd, t.Schema, kwPath, instPath, key, obj[key]))
+		}
+	case *Dependencies:
+		for _, key := range sortedDependencyKeys(*t) {
+			if dep := (*t)[key]; dep.schema != nil && obj[key] != nil {
+				res = append(res, traceSchema(vd, dep.schema, kwPath+"/"+EscapePointerToken(key), instPath, data))
+			}
+		}
+	case *PropertyNames:
+		for _, key := range sortedMapKeys(obj) {
+			res = append(res, traceMember(vd, (*Schema)(t), kwPath, instPath, key, key))
+		}
+	case *Items:
+		for i, elem := range arr {
+			switch {
+			case t.single:
+				res = append(res, traceMember(vd, t.Schemas[0], kwPath, instPath, strconv.Itoa(i), elem))
+			case i < len(t.Schemas):
+				res = append(res, traceMember(vd, t.Schemas[i], kwPath+"/"+strconv.Itoa(i), instPath, strconv.Itoa(i), elem))
+			}
+		}
+	case *AdditionalItems:
+		if t.startIndex < 0 || t.Schema == nil {
+			break
+		}
+		for i := t.startIndex; i < len(arr); i++ {
+			res = append(res, traceMember(vd, t.Schema, kwPath, instPath, strconv.Itoa(i), arr[i]))
+		}
+	case *Contains:
+		// elements are tried until one matches
+		for i, elem := range arr {
+			el := traceMember(vd, (*Schema)(t), kwPath, instPath, strconv.Itoa(i), elem)
+			res = append(res, el)
+			if el.Valid {
+				break
+			}
+		}
+	}
+	return res
+}
+
+// traceMember traces s against the property or element member of the
+// value at instPath
+func traceMember(vd *validation, s *Schema, schemaPath, instPath, member string, data interface{}) *Trace {
+	t := traceSchema(vd, s, schemaPath, traceInstancePath(instPath, member), data)
+	t.member = member
+	return t
+}
+
+// traceInstancePath gives the path to the property or element tok of the
+// value at instPath, written as validators write it
+func traceInstancePath(instPath, tok string) string {
+	jp, err := jsonpointer.Parse(instPath)
+	if err != nil {
+		return instPath
+	}
+	d, _ := jp.Descendant(tok)
+	return d.String()
+}
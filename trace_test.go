@@ -0,0 +1,85 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateTrace(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"id": {"anyOf": [{"type": "integer"}, {"type": "string", "minLength": 3}]},
+			"tags": {"items": {"$ref": "#/definitions/tag"}}
+		},
+		"if": {"required": ["id"]},
+		"then": {"required": ["tags"]},
+		"definitions": {
+			"tag": {"type": "string"}
+		}
+	}`)
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"id": "ab", "tags": ["x", 2]}`), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	trace := rs.ValidateTrace(doc)
+	expect := `schema "" at /: invalid
+  if at /: valid
+    schema "/if" at /: valid
+      required at /: valid
+    schema "/then" at /: valid
+      required at /: valid
+  properties at /: invalid
+    schema "/properties/id" at /id: invalid
+      anyOf at /id: invalid
+        schema "/properties/id/anyOf/0" at /id: invalid
+          type at /id: invalid: type should be integer
+        schema "/properties/id/anyOf/1" at /id: invalid
+          minLength at /id: invalid: min length of 3 characters required: ab
+          type at /id: valid
+    schema "/properties/tags" at /tags: invalid
+      items at /tags: invalid
+        schema "/properties/tags/items" at /tags/0: valid
+          $ref at /tags/0: valid
+            schema "/properties/tags/items/$ref" at /tags/0: valid
+              type at /tags/0: valid
+        schema "/properties/tags/items" at /tags/1: invalid
+          $ref at /tags/1: invalid
+            schema "/properties/tags/items/$ref" at /tags/1: invalid
+              type at /tags/1: invalid: type should be string
+  type at /: valid
+`
+	if got := trace.String(); got != expect {
+		t.Errorf("expected trace:\n%s\ngot:\n%s", expect, got)
+	}
+
+	errs := []ValError{}
+	rs.Validate("/", doc, &errs)
+	if trace.Valid != (len(errs) == 0) {
+		t.Errorf("trace validity %t disagrees with errors %v", trace.Valid, errs)
+	}
+
+	failures := []string{}
+	for _, f := range trace.Failures() {
+		failures = append(failures, f.SchemaPath+" at "+f.InstancePath)
+	}
+	expectFailures := []string{
+		"/properties/id/anyOf/0/type at /id",
+		"/properties/id/anyOf/1/minLength at /id",
+		"/properties/tags/items/$ref/type at /tags/1",
+	}
+	if len(failures) != len(expectFailures) {
+		t.Fatalf("expected failures %v, got %v", expectFailures, failures)
+	}
+	for i, f := range failures {
+		if f != expectFailures[i] {
+			t.Errorf("failure %d: expected %s, got %s", i, expectFailures[i], f)
+		}
+	}
+
+	valid := rs.ValidateTrace(map[string]interface{}{"id": 1.0, "tags": []interface{}{}})
+	if !valid.Valid || len(valid.Failures()) != 0 {
+		t.Errorf("expected a valid trace, got:\n%s", valid)
+	}
+}
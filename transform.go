@@ -0,0 +1,218 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RewriteRefs replaces the value of every "$ref" in the document with the
+// result of calling fn on it, then re-resolves references within the
+// document. Use it to point references at a different host, or to map
+// remote URLs onto identifiers defined locally. References to remote
+// documents must be fetched again with FetchRemoteReferences
+func (rs *RootSchema) RewriteRefs(fn func(ref string) string) error {
+	rs.Walk(func(_ string, sch *Schema) error {
+		if sch.Ref != "" {
+			sch.Ref = fn(sch.Ref)
+			sch.ref = nil
+		}
+		return nil
+	})
+	return rs.resolveRefs()
+}
+
+// RenameDefinition renames a top-level entry in "definitions", rewriting
+// every reference to the definition (or to a location within it) so the
+// document stays consistent
+func (rs *RootSchema) RenameDefinition(from, to string) error {
+	if rs.Definitions[from] == nil {
+		return fmt.Errorf("definition %q not found", from)
+	}
+	if rs.Definitions[to] != nil {
+		return fmt.Errorf("definition %q already exists", to)
+	}
+
+	rs.Definitions[to] = rs.Definitions[from]
+	delete(rs.Definitions, from)
+
+	oldPtr := "/definitions/" + EscapePointerToken(from)
+	newPtr := "/definitions/" + EscapePointerToken(to)
+	return rs.RewriteRefs(func(ref string) string {
+		idx := strings.Index(ref, "#")
+		if idx == -1 {
+			return ref
+		}
+		// only rewrite references into this document
+		if base := ref[:idx]; base != "" && base != rs.ID {
+			return ref
+		}
+		frag := ref[idx+1:]
+		if frag == oldPtr || strings.HasPrefix(frag, oldPtr+"/") {
+			return ref[:idx+1] + newPtr + frag[len(oldPtr):]
+		}
+		return ref
+	})
+}
+
+// ExtractRepeated factors identical inline subschemas out into named "$defs"
+// entries, replacing each occurrence with a "$ref". Larger schemas are
+// extracted first, and occurrences identical to an existing definition are
+// pointed at that definition instead. Subschemas with fewer than minKeywords
+// keywords (see Stats) stay inline, as do schemas that declare an "$id" or
+// are the target of a reference. Definition names come from the nearest
+// property or definition name in each schema's location. ExtractRepeated
+// returns the names of the "$defs" entries it added
+func (rs *RootSchema) ExtractRepeated(minKeywords int) ([]string, error) {
+	targets := []string{}
+	rs.Walk(func(_ string, sch *Schema) error {
+		if idx := strings.Index(sch.Ref, "#"); idx != -1 && (idx == 0 || sch.Ref[:idx] == rs.ID) {
+			targets = append(targets, sch.Ref[idx+1:])
+		}
+		return nil
+	})
+
+	added := []string{}
+	// each pass extracts the repeats found in one walk of the document.
+	// Copies placed in "$defs" may repeat schemas found elsewhere, so passes
+	// continue until nothing is extracted
+	for extracted := true; extracted; {
+		extracted = false
+		replaced := []string{}
+		for _, occs := range repeatedSchemas(rs, targets, minKeywords) {
+			// occurrences within a schema replaced by a larger repeat are gone
+			live := []repeat{}
+			for _, occ := range occs {
+				if !withinAny(occ.ptr, replaced) {
+					live = append(live, occ)
+				}
+			}
+			if len(live) < 2 {
+				continue
+			}
+
+			ref := ""
+			for _, occ := range live {
+				if occ.isDef {
+					ref = "#" + occ.ptr
+					break
+				}
+			}
+			if ref == "" {
+				name := uniqueDefName(rs.Defs, definitionName(live[0].ptr))
+				if rs.Defs == nil {
+					rs.Defs = Definitions{}
+				}
+				rs.Defs[name] = live[0].sch.Clone()
+				added = append(added, name)
+				ref = "#/$defs/" + EscapePointerToken(name)
+			}
+
+			for _, occ := range live {
+				if "#"+occ.ptr != ref {
+					*occ.sch = Schema{Ref: ref, Validators: map[string]Validator{}}
+					replaced = append(replaced, occ.ptr)
+				}
+			}
+			extracted = true
+		}
+	}
+	if err := rs.resolveRefs(); err != nil {
+		return added, err
+	}
+	rs.bindPaths()
+	rs.bindOptions()
+	return added, nil
+}
+
+// repeat is a candidate subschema for ExtractRepeated
+type repeat struct {
+	ptr   string
+	sch   *Schema
+	isDef bool
+	size  int
+}
+
+// repeatedSchemas finds the occurrences of each subschema that appears more
+// than once in rs and may be extracted, largest first
+func repeatedSchemas(rs *RootSchema, targets []string, minKeywords int) [][]repeat {
+	defs := map[*Schema]bool{}
+	groups := map[[32]byte][]repeat{}
+	order := [][32]byte{}
+
+	rs.Walk(func(ptr string, sch *Schema) error {
+		for _, d := range []Definitions{sch.Defs, sch.Definitions} {
+			for _, def := range d {
+				defs[def] = true
+			}
+		}
+		if ptr == "" || sch.schemaType != schemaTypeObject || sch.Ref != "" {
+			return nil
+		}
+		for _, t := range targets {
+			if t == ptr || strings.HasPrefix(t, ptr+"/") {
+				return nil
+			}
+		}
+		hasID := false
+		walkSchemas("", sch, func(_ string, s *Schema) error {
+			hasID = hasID || s.ID != ""
+			return nil
+		})
+		size := sch.Stats().Keywords
+		if hasID || size < minKeywords || size == 0 {
+			return nil
+		}
+
+		fp := sch.Fingerprint()
+		if groups[fp] == nil {
+			order = append(order, fp)
+		}
+		groups[fp] = append(groups[fp], repeat{ptr: ptr, sch: sch, isDef: defs[sch], size: size})
+		return nil
+	})
+
+	repeats := [][]repeat{}
+	for _, fp := range order {
+		if g := groups[fp]; len(g) > 1 {
+			repeats = append(repeats, g)
+		}
+	}
+	sort.SliceStable(repeats, func(i, j int) bool { return repeats[i][0].size > repeats[j][0].size })
+	return repeats
+}
+
+// withinAny reports whether ptr is one of ptrs or a location within one
+func withinAny(ptr string, ptrs []string) bool {
+	for _, p := range ptrs {
+		if ptr == p || strings.HasPrefix(ptr, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// definitionName picks a name for a schema from its location
+func definitionName(ptr string) string {
+	tokens := strings.Split(ptr, "/")
+	for i := len(tokens) - 1; i > 0; i-- {
+		switch tokens[i-1] {
+		case "properties", "definitions", "$defs", "dependencies":
+			return strings.Replace(strings.Replace(tokens[i], "~1", "/", -1), "~0", "~", -1)
+		}
+	}
+	return "schema"
+}
+
+// uniqueDefName adds a numeric suffix to name if defs already has an entry
+// by that name
+func uniqueDefName(defs Definitions, name string) string {
+	if defs[name] == nil {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := fmt.Sprintf("%s%d", name, i); defs[n] == nil {
+			return n
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRenameDefinition(t *testing.T) {
+	rs := Must(`{
+		"$id": "http://example.com/root.json",
+		"definitions": {
+			"name": { "type": "string" },
+			"names": { "type": "array" },
+			"pair": { "items": [{ "$ref": "#/definitions/name" }, { "type": "integer" }] }
+		},
+		"properties": {
+			"a": { "$ref": "#/definitions/name" },
+			"b": { "$ref": "http://example.com/root.json#/definitions/pair/items/1" },
+			"c": { "$ref": "#/definitions/names" }
+		}
+	}`)
+
+	if err := rs.RenameDefinition("name", "label"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.RenameDefinition("pair", "tuple"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{
+		`"#/definitions/label"`,
+		`"http://example.com/root.json#/definitions/tuple/items/1"`,
+		`"#/definitions/names"`,
+	} {
+		if !strings.Contains(string(data), ref) {
+			t.Errorf("expected rewritten schema to contain reference %s. got: %s", ref, data)
+		}
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "a": 1, "b": "x", "c": [] }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors validating with renamed definitions, got: %v", errs)
+	}
+
+	if err := rs.RenameDefinition("missing", "other"); err == nil {
+		t.Errorf("expected renaming a missing definition to error")
+	}
+	if err := rs.RenameDefinition("label", "tuple"); err == nil {
+		t.Errorf("expected renaming onto an existing definition to error")
+	}
+}
+
+func TestRewriteRefs(t *testing.T) {
+	rs := Must(`{
+		"definitions": { "local": { "type": "string" } },
+		"properties": { "a": { "$ref": "http://old.example.com/thing.json" } }
+	}`)
+
+	if err := rs.RewriteRefs(func(ref string) string {
+		if ref == "http://old.example.com/thing.json" {
+			return "#/definitions/local"
+		}
+		return ref
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "a": 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected rewritten reference to validate against the local definition, got: %v", errs)
+	}
+}
+
+func TestExtractRepeated(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"home": { "type": "object", "properties": { "street": { "type": "string", "minLength": 1 }, "zip": { "type": "string", "pattern": "^[0-9]{5}$" } } },
+			"work": { "type": "object", "properties": { "street": { "type": "string", "minLength": 1 }, "zip": { "type": "string", "pattern": "^[0-9]{5}$" } } },
+			"name": { "type": "string", "minLength": 1 },
+			"id": { "type": "integer", "minimum": 1 },
+			"parent": { "$ref": "#/properties/id" },
+			"count": { "type": "integer", "minimum": 1 },
+			"tag": { "type": "string" },
+			"label": { "type": "string" }
+		},
+		"definitions": {
+			"positive": { "type": "number", "exclusiveMinimum": 0 }
+		},
+		"items": { "type": "number", "exclusiveMinimum": 0 }
+	}`)
+
+	added, err := rs.ExtractRepeated(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 || added[0] != "home" || added[1] != "street" {
+		t.Errorf("expected home and street definitions to be added, got: %v", added)
+	}
+
+	got, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"$defs":{"home":{"properties":{"street":{"$ref":"#/$defs/street"},"zip":{"pattern":"^[0-9]{5}$","type":"string"}},"type":"object"},"street":{"minLength":1,"type":"string"}},"definitions":{"positive":{"exclusiveMinimum":0,"type":"number"}},"items":{"$ref":"#/definitions/positive"},"properties":{"count":{"minimum":1,"type":"integer"},"home":{"$ref":"#/$defs/home"},"id":{"minimum":1,"type":"integer"},"label":{"type":"string"},"name":{"$ref":"#/$defs/street"},"parent":{"$ref":"#/properties/id"},"tag":{"type":"string"},"work":{"$ref":"#/$defs/home"}}}`
+	if string(got) != expect {
+		t.Errorf("encoding mismatch.\nexpected: %s\ngot:      %s", expect, got)
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "home": { "street": "" }, "work": { "zip": "1" }, "items": [], "name": "" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 3 {
+		t.Errorf("expected extracted schemas to still validate, got errors: %v", errs)
+	}
+}
+
+func TestExtractRepeatedRebinds(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"home": { "properties": { "zip": { "type": "string", "pattern": "^[0-9]{5}$" } } },
+			"work": { "properties": { "zip": { "type": "string", "pattern": "^[0-9]{5}$" } } }
+		}
+	}`)
+	rs.SetOptions(Options{})
+	if _, err := rs.ExtractRepeated(2); err != nil {
+		t.Fatal(err)
+	}
+
+	rs.Walk(func(ptr string, s *Schema) error {
+		if s.Path() != ptr {
+			t.Errorf("expected the schema at %q to have its path, got %q", ptr, s.Path())
+		}
+		if s.opts != rs.opts {
+			t.Errorf("expected the schema at %q to have the options of the document", ptr)
+		}
+		return nil
+	})
+
+	errs, err := rs.ValidateBytes([]byte(`{ "work": { "zip": "1" } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].RulePath != "/$defs/home/properties/zip/pattern" {
+		t.Errorf("expected an error from the extracted pattern, got: %v", errs)
+	}
+}
@@ -1,5 +1,10 @@
 package jsonschema
 
+import (
+	"sort"
+	"strconv"
+)
+
 // JSONPather makes validators traversible by JSON-pointers,
 // which is required to support references in JSON schemas.
 type JSONPather interface {
@@ -33,3 +38,153 @@ func walkJSON(elem JSONPather, fn func(elem JSONPather) error) error {
 
 	return nil
 }
+
+// Walk calls fn for the schema and every subschema nested within it, in a
+// deterministic depth-first order. ptr is the JSON pointer to each schema
+// relative to s, the empty string for s itself. References aren't followed.
+// Returning an error from fn stops the walk, and Walk returns that error
+func (s *Schema) Walk(fn func(ptr string, s *Schema) error) error {
+	return walkSchemas("", s, fn)
+}
+
+// walkSchemas calls fn for s and every subschema defined beneath it, passing
+// the JSON pointer to each schema relative to s. References aren't followed.
+// Sibling keywords are visited in sorted order, so walks are deterministic
+func walkSchemas(ptr string, s *Schema, fn func(ptr string, s *Schema) error) error {
+	if s == nil {
+		return nil
+	}
+	if err := fn(ptr, s); err != nil {
+		return err
+	}
+	// boolean schemas have no subschemas, false is implemented as a "not"
+	// keyword internally
+	if s.schemaType != schemaTypeObject {
+		return nil
+	}
+
+	for _, key := range sortedDefinitionKeys(s.Defs) {
+		if err := walkSchemas(ptr+"/$defs/"+EscapePointerToken(key), s.Defs[key], fn); err != nil {
+			return err
+		}
+	}
+	for _, key := range sortedDefinitionKeys(s.Definitions) {
+		if err := walkSchemas(ptr+"/definitions/"+EscapePointerToken(key), s.Definitions[key], fn); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(s.Validators))
+	for key := range s.Validators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kptr := ptr + "/" + EscapePointerToken(key)
+		for _, ch := range subschemas(s.Validators[key]) {
+			p := kptr
+			if ch.token != "" {
+				p += "/" + EscapePointerToken(ch.token)
+			}
+			if ch.sub != "" {
+				p += "/" + EscapePointerToken(ch.sub)
+			}
+			if err := walkSchemas(p, ch.schema, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// subschema is a schema nested directly within a keyword. token is the
+// (unescaped) pointer token to the schema within the keyword value, empty
+// when the keyword value is itself a schema. sub is the token of schemas
+// nested a level deeper, as those of links are
+type subschema struct {
+	token  string
+	schema *Schema
+	sub    string
+}
+
+// subschemas lists the schemas nested directly within a keyword value
+func subschemas(v Validator) (res []subschema) {
+	switch t := v.(type) {
+	case *AllOf:
+		for i, sch := range *t {
+			res = append(res, subschema{token: strconv.Itoa(i), schema: sch})
+		}
+	case *AnyOf:
+		for i, sch := range *t {
+			res = append(res, subschema{token: strconv.Itoa(i), schema: sch})
+		}
+	case *OneOf:
+		for i, sch := range *t {
+			res = append(res, subschema{token: strconv.Itoa(i), schema: sch})
+		}
+	case *Not:
+		res = append(res, subschema{schema: (*Schema)(t)})
+	case *Items:
+		if t.single && len(t.Schemas) == 1 {
+			return []subschema{{schema: t.Schemas[0]}}
+		}
+		for i, sch := range t.Schemas {
+			res = append(res, subschema{token: strconv.Itoa(i), schema: sch})
+		}
+	case *AdditionalItems:
+		if t.Schema != nil {
+			res = append(res, subschema{schema: t.Schema})
+		}
+	case *Contains:
+		res = append(res, subschema{schema: (*Schema)(t)})
+	case *Properties:
+		for _, key := range sortedDefinitionKeys(Definitions(*t)) {
+			res = append(res, subschema{token: key, schema: (*t)[key]})
+		}
+	case *PatternProperties:
+		for _, ptn := range *t {
+			res = append(res, subschema{token: ptn.key, schema: ptn.schema})
+		}
+	case *AdditionalProperties:
+		if t.Schema != nil {
+			res = append(res, subschema{schema: t.Schema})
+		}
+	case *Dependencies:
+		keys := make([]string, 0, len(*t))
+		for key := range *t {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if dep := (*t)[key]; dep.schema != nil {
+				res = append(res, subschema{token: key, schema: dep.schema})
+			}
+		}
+	case *PropertyNames:
+		res = append(res, subschema{schema: (*Schema)(t)})
+	case *If:
+		res = append(res, subschema{schema: &t.Schema})
+	case *Then:
+		res = append(res, subschema{schema: (*Schema)(t)})
+	case *Else:
+		res = append(res, subschema{schema: (*Schema)(t)})
+	case *Links:
+		for i, link := range *t {
+			schemas := link.schemas()
+			for _, key := range sortedDefinitionKeys(Definitions(schemas)) {
+				res = append(res, subschema{token: strconv.Itoa(i), sub: key, schema: schemas[key]})
+			}
+		}
+	}
+	return
+}
+
+func sortedDefinitionKeys(defs Definitions) []string {
+	keys := make([]string, 0, len(defs))
+	for key := range defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
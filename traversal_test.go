@@ -1,6 +1,7 @@
 package jsonschema
 
 import (
+	"fmt"
 	"io/ioutil"
 	"testing"
 )
@@ -102,3 +103,74 @@ func TestReferenceTraversal(t *testing.T) {
 	}
 
 }
+
+func TestWalk(t *testing.T) {
+	rs := Must(`{
+		"definitions": { "a/b": { "type": "string" } },
+		"properties": {
+			"foo": { "items": [{ "$ref": "#/definitions/a~1b" }, true] },
+			"bar": { "not": { "maxLength": 2 } }
+		},
+		"patternProperties": { "^x~": { "anyOf": [{}, {}] } },
+		"additionalProperties": false,
+		"dependencies": { "foo": ["bar"], "bar": { "required": ["baz"] } },
+		"if": { "minProperties": 1 },
+		"then": {},
+		"else": true,
+		"contains": {},
+		"propertyNames": { "pattern": "^[a-z]" }
+	}`)
+
+	expect := []string{
+		"",
+		"/definitions/a~1b",
+		"/additionalProperties",
+		"/contains",
+		"/dependencies/bar",
+		"/else",
+		"/if",
+		"/patternProperties/^x~0",
+		"/patternProperties/^x~0/anyOf/0",
+		"/patternProperties/^x~0/anyOf/1",
+		"/properties/bar",
+		"/properties/bar/not",
+		"/properties/foo",
+		"/properties/foo/items/0",
+		"/properties/foo/items/1",
+		"/propertyNames",
+		"/then",
+	}
+
+	got := []string{}
+	if err := rs.Walk(func(ptr string, s *Schema) error {
+		got = append(got, ptr)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(expect) {
+		t.Fatalf("expected %d schemas, got %d: %v", len(expect), len(got), got)
+	}
+	for i, ptr := range expect {
+		if got[i] != ptr {
+			t.Errorf("walk %d mismatch. expected: %q, got: %q", i, ptr, got[i])
+		}
+	}
+
+	stop := fmt.Errorf("stop")
+	visits := 0
+	err := rs.Walk(func(ptr string, s *Schema) error {
+		visits++
+		if ptr == "/contains" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("expected walk to return the callback error, got: %v", err)
+	}
+	if visits != 4 {
+		t.Errorf("expected walk to stop after 4 visits, got: %d", visits)
+	}
+}
@@ -0,0 +1,357 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TypeScriptOptions configures TypeScript generation
+type TypeScriptOptions struct {
+	// RootName is the name of the type generated for the root schema. If
+	// empty it's derived from the schema title, falling back to "Root"
+	RootName string
+}
+
+// tsIdentifier matches property names TypeScript accepts without quotes
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTypeScript emits a TypeScript declaration file (.d.ts) exporting
+// types for the root schema and each of its top-level definitions:
+//
+//   - objects with properties become interfaces. Optional properties are
+//     marked "?", and allOf branches referring to interfaces are extended
+//     while other branches contribute their properties
+//   - other objects become records, arrays arrays, and tuple items tuples
+//   - enums and consts become unions of literal types
+//   - oneOf and anyOf become unions, as do values of several types
+//   - integers and numbers become number, and types including null a union
+//     with null
+//   - references become the type of their target
+//
+// Keywords without a TypeScript equivalent, like patterns and bounds, are
+// left for validation
+func GenerateTypeScript(rs *RootSchema, opts TypeScriptOptions) ([]byte, error) {
+	g := &tsGenerator{
+		typeDecls: newTypeDecls(),
+	}
+	root := opts.RootName
+	if root == "" {
+		root = goName(rs.Title)
+	}
+	if root == "" {
+		root = "Root"
+	}
+
+	if _, err := g.declare(&rs.Schema, root); err != nil {
+		return nil, err
+	}
+	for _, defs := range []Definitions{rs.Defs, rs.Definitions} {
+		for _, key := range sortedDefinitionKeys(defs) {
+			if _, err := g.declare(defs[key], goName(key)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema. DO NOT EDIT.\n")
+	for _, decl := range g.decls {
+		buf.WriteString("\n")
+		buf.WriteString(decl)
+	}
+	return buf.Bytes(), nil
+}
+
+// tsGenerator accumulates type declarations
+type tsGenerator struct {
+	typeDecls
+}
+
+// declare gives s a named type, returning the name
+func (g *tsGenerator) declare(s *Schema, hint string) (string, error) {
+	return g.typeDecls.declare(s, hint, func(name string) (string, error) {
+		if s.Ref == "" && isStructSchema(s) && !isUnionSchema(s) && tsLiterals(s) == nil {
+			return g.interfaceBody(s, name)
+		}
+		t, err := g.typeExpr(s, name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("export type %s = %s;\n", name, t), nil
+	}, func(_, description string) string {
+		return tsComment(description, "")
+	})
+}
+
+// typeExpr gives a TypeScript type expression for s, declaring interfaces
+// for objects with properties nested within it
+func (g *tsGenerator) typeExpr(s *Schema, hint string) (string, error) {
+	if s == nil {
+		return "unknown", nil
+	}
+	switch s.schemaType {
+	case schemaTypeTrue:
+		return "unknown", nil
+	case schemaTypeFalse:
+		return "never", nil
+	}
+	if s.Ref != "" {
+		target := refTarget(s)
+		if target == nil {
+			return "", fmt.Errorf("unresolved reference: %s", s.Ref)
+		}
+		return g.declare(target, refTypeName(s.Ref, hint))
+	}
+
+	if lits := tsLiterals(s); lits != nil {
+		return strings.Join(lits, " | "), nil
+	}
+	types, nullable := nonNullTypes(s)
+	if isUnionSchema(s) {
+		var branches []*Schema
+		switch v := s.Validators["oneOf"].(type) {
+		case *OneOf:
+			branches = *v
+		default:
+			branches = *s.Validators["anyOf"].(*AnyOf)
+		}
+		alts := []string{}
+		for i, branch := range branches {
+			t, err := g.typeExpr(branch, fmt.Sprintf("%sOption%d", hint, i+1))
+			if err != nil {
+				return "", err
+			}
+			alts = tsAppendAlt(alts, t)
+		}
+		if nullable {
+			alts = tsAppendAlt(alts, "null")
+		}
+		return strings.Join(alts, " | "), nil
+	}
+	if isStructSchema(s) {
+		name, err := g.declare(s, hint)
+		if nullable {
+			name += " | null"
+		}
+		return name, err
+	}
+
+	if len(types) == 0 && !nullable {
+		return "unknown", nil
+	}
+	alts := []string{}
+	for _, typ := range types {
+		switch typ {
+		case "string":
+			alts = tsAppendAlt(alts, "string")
+		case "integer", "number":
+			alts = tsAppendAlt(alts, "number")
+		case "boolean":
+			alts = tsAppendAlt(alts, "boolean")
+		case "array":
+			t, err := g.arrayType(s, hint)
+			if err != nil {
+				return "", err
+			}
+			alts = tsAppendAlt(alts, t)
+		case "object":
+			vt := "unknown"
+			if add, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && add.Schema != nil {
+				t, err := g.typeExpr(add.Schema, hint+"Value")
+				if err != nil {
+					return "", err
+				}
+				vt = t
+			}
+			alts = tsAppendAlt(alts, "Record<string, "+vt+">")
+		}
+	}
+	if nullable {
+		alts = tsAppendAlt(alts, "null")
+	}
+	return strings.Join(alts, " | "), nil
+}
+
+// arrayType gives the array or tuple type of array schema s
+func (g *tsGenerator) arrayType(s *Schema, hint string) (string, error) {
+	items, ok := s.Validators["items"].(*Items)
+	if !ok {
+		return "unknown[]", nil
+	}
+	if items.single {
+		t, err := g.typeExpr(items.Schemas[0], hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(t, " ") {
+			t = "(" + t + ")"
+		}
+		return t + "[]", nil
+	}
+	elems := []string{}
+	for i, item := range items.Schemas {
+		t, err := g.typeExpr(item, fmt.Sprintf("%sItem%d", hint, i+1))
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(t, " ") {
+			t = "(" + t + ")"
+		}
+		// arrays may be shorter than their tuple
+		elems = append(elems, t+"?")
+	}
+	rest := "unknown"
+	if add, ok := s.Validators["additionalItems"].(*AdditionalItems); ok && add.Schema != nil {
+		t, err := g.typeExpr(add.Schema, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		rest = t
+	}
+	if rest != "never" {
+		if strings.Contains(rest, " ") {
+			rest = "(" + rest + ")"
+		}
+		elems = append(elems, "..."+rest+"[]")
+	}
+	return "[" + strings.Join(elems, ", ") + "]", nil
+}
+
+// interfaceBody declares an interface for an object with properties,
+// extending the interfaces of allOf branches that refer to one
+func (g *tsGenerator) interfaceBody(s *Schema, name string) (string, error) {
+	props := Properties{}
+	required := map[string]bool{}
+	extends := []string{}
+	collect := func(sch *Schema) {
+		p, req := collectProperties(sch)
+		for key, prop := range p {
+			if props[key] == nil {
+				props[key] = prop
+			}
+		}
+		for key := range req {
+			required[key] = true
+		}
+	}
+	if p, ok := s.Validators["properties"].(*Properties); ok {
+		for key, prop := range *p {
+			props[key] = prop
+		}
+	}
+	if req, ok := s.Validators["required"].(*Required); ok {
+		for _, key := range *req {
+			required[key] = true
+		}
+	}
+	if allOf, ok := s.Validators["allOf"].(*AllOf); ok {
+		for i, branch := range *allOf {
+			if target := refTarget(branch); target != nil && branch.Ref != "" && isStructSchema(target) {
+				t, err := g.typeExpr(branch, fmt.Sprintf("%sPart%d", name, i+1))
+				if err != nil {
+					return "", err
+				}
+				extends = append(extends, t)
+				continue
+			}
+			collect(branch)
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "export interface %s ", name)
+	if len(extends) > 0 {
+		fmt.Fprintf(buf, "extends %s ", strings.Join(extends, ", "))
+	}
+	buf.WriteString("{\n")
+	for _, key := range keys {
+		prop := props[key]
+		t, err := g.typeExpr(prop, name+goName(key))
+		if err != nil {
+			return "", err
+		}
+		label := key
+		if !tsIdentifier.MatchString(key) {
+			label = tsLiteral(key)
+		}
+		if !required[key] {
+			label += "?"
+		}
+		if prop.Description != "" {
+			buf.WriteString(tsComment(prop.Description, "  "))
+		}
+		fmt.Fprintf(buf, "  %s: %s;\n", label, t)
+	}
+	if add, ok := s.Validators["additionalProperties"].(*AdditionalProperties); ok && add.Schema != nil && add.Schema.schemaType != schemaTypeFalse {
+		// index signatures must admit the types of every property
+		buf.WriteString("  [key: string]: unknown;\n")
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// tsLiterals gives the literal types of the const or enum values of s, nil
+// if it has neither or some value is an object or array
+func tsLiterals(s *Schema) []string {
+	vals := enumValues(s)
+	if c, ok := s.Validators["const"].(*Const); ok {
+		var v interface{}
+		if err := json.Unmarshal(*c, &v); err != nil {
+			return nil
+		}
+		vals = []interface{}{v}
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+	lits := []string{}
+	for _, v := range vals {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil
+		}
+		lits = tsAppendAlt(lits, tsLiteral(v))
+	}
+	return lits
+}
+
+// tsAppendAlt adds the alternative t to a union, unless it's already there
+func tsAppendAlt(alts []string, t string) []string {
+	for _, alt := range alts {
+		if alt == t {
+			return alts
+		}
+	}
+	return append(alts, t)
+}
+
+// tsLiteral writes a JSON scalar as a TypeScript literal
+func tsLiteral(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// tsComment formats text as a JSDoc comment at the given indent
+func tsComment(text, indent string) string {
+	lines := strings.Split(strings.Replace(strings.TrimSpace(text), "*/", "*\\/", -1), "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+	buf := &strings.Builder{}
+	buf.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		buf.WriteString(strings.TrimRight(indent+" * "+strings.TrimSpace(line), " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+	return buf.String()
+}
@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestGenerateTypeScript(t *testing.T) {
+	rs := Must(`{
+		"title": "pet store",
+		"type": "object",
+		"properties": {
+			"id": { "type": "integer" },
+			"name": { "type": "string", "description": "display name" },
+			"nickname": { "type": ["string", "null"] },
+			"status": { "enum": ["available", "sold", null] },
+			"pet": { "$ref": "#/definitions/pet" },
+			"tags": { "type": "array", "items": { "type": ["string", "number"] } },
+			"point": { "type": "array", "items": [{ "type": "number" }, { "type": "number" }], "additionalItems": false },
+			"extra": { "type": "object", "additionalProperties": { "type": "number" } },
+			"owner": { "type": ["object", "null"], "properties": { "email": { "type": "string" } }, "required": ["email"] },
+			"x-trace": { "const": 1 }
+		},
+		"required": ["id", "name"],
+		"definitions": {
+			"id": { "type": "string", "description": "an identifier\nof a pet" },
+			"pet": { "oneOf": [ { "$ref": "#/definitions/cat" }, { "$ref": "#/definitions/dog" } ] },
+			"cat": { "type": "object", "properties": { "lives": { "type": "integer" } }, "additionalProperties": { "type": "string" } },
+			"dog": { "allOf": [ { "$ref": "#/definitions/animal" }, { "properties": { "good": { "type": "boolean" } }, "required": ["good"] } ] },
+			"animal": { "description": "anything with legs", "properties": { "legs": { "enum": [2, 4] } } }
+		}
+	}`)
+
+	got, err := GenerateTypeScript(rs, TypeScriptOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "// Code generated by jsonschema. DO NOT EDIT.\n\nexport interface PetStore {\n  extra?: Record<string, number>;\n  id: number;\n  /** display name */\n  name: string;\n  nickname?: string | null;\n  owner?: PetStoreOwner | null;\n  pet?: Pet;\n  point?: [number?, number?];\n  status?: \"available\" | \"sold\" | null;\n  tags?: (string | number)[];\n  \"x-trace\"?: 1;\n}\n\nexport interface PetStoreOwner {\n  email: string;\n}\n\nexport type Pet = Cat | Dog;\n\nexport interface Cat {\n  lives?: number;\n  [key: string]: unknown;\n}\n\nexport interface Dog extends Animal {\n  good: boolean;\n}\n\n/** anything with legs */\nexport interface Animal {\n  legs?: 2 | 4;\n}\n\n/**\n * an identifier\n * of a pet\n */\nexport type ID = string;\n"
+	if string(got) != expect {
+		t.Errorf("output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateTypeScript(Must(`{"type": "array", "items": {"$ref": "#"}}`), TypeScriptOptions{RootName: "List"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "// Code generated by jsonschema. DO NOT EDIT.\n\nexport type List = List[];\n"
+	if string(got) != expect {
+		t.Errorf("recursive output mismatch.\nexpected:\n%s\ngot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,214 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// UIElement is an element of a UI schema in the style JSON Forms renderers
+// read: a layout of elements, a group of them, or a control rendering the
+// part of the instance the schema at Scope describes
+type UIElement struct {
+	// Type is "VerticalLayout", "Group" or "Control"
+	Type string `json:"type"`
+	// Scope is a JSON pointer fragment to the schema a control renders,
+	// relative to the schema the UI schema was generated from
+	Scope string `json:"scope,omitempty"`
+	// Label is the title of the schema, or a name derived from its
+	// property's when it has none
+	Label    string                 `json:"label,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+	Elements []*UIElement           `json:"elements,omitempty"`
+}
+
+// GenerateUISchema derives a UI schema for rendering forms from rs. Object
+// properties become controls within a vertical layout, and objects nested
+// within them groups of their own. Properties are ordered by any numeric
+// "propertyOrder" keyword they have, then required properties in the order
+// they're required, then the rest by name. Controls get a "widget" option
+// picked from the type, format and enum of their schema, along with the
+// options JSON Forms uses for the same widgets. Arrays of objects get a
+// "detail" option laying out their items
+func GenerateUISchema(rs *RootSchema) *UIElement {
+	g := &uiGenerator{seen: map[*Schema]bool{}}
+	if s, ok := resolveSchema(&rs.Schema); ok && hasUIProperties(s) {
+		return &UIElement{Type: "VerticalLayout", Elements: g.elements(s, "#")}
+	}
+	return g.control(&rs.Schema, "#", rs.Title)
+}
+
+// uiGenerator tracks the schemas being laid out, so recursive schemas end
+// in a control rather than nesting forever
+type uiGenerator struct {
+	seen map[*Schema]bool
+}
+
+// elements lays out the properties of s, which scope points at
+func (g *uiGenerator) elements(s *Schema, scope string) []*UIElement {
+	g.seen[s] = true
+	defer delete(g.seen, s)
+
+	props := s.Validators["properties"].(*Properties)
+	elems := []*UIElement{}
+	for _, name := range uiPropertyOrder(s) {
+		sch := (*props)[name]
+		pscope := scope + "/properties/" + EscapePointerToken(name)
+		label := uiLabel(sch, name)
+		if target, ok := resolveSchema(sch); ok && hasUIProperties(target) && !g.seen[target] {
+			elems = append(elems, &UIElement{Type: "Group", Label: label, Elements: g.elements(target, pscope)})
+			continue
+		}
+		elems = append(elems, g.control(sch, pscope, label))
+	}
+	return elems
+}
+
+// control makes a control for the value s describes
+func (g *uiGenerator) control(s *Schema, scope, label string) *UIElement {
+	c := &UIElement{Type: "Control", Scope: scope, Label: label}
+	target, ok := resolveSchema(s)
+	if !ok || target.schemaType != schemaTypeObject {
+		return c
+	}
+	opts := map[string]interface{}{}
+	if widget := uiWidget(target); widget != "" {
+		opts["widget"] = widget
+		switch widget {
+		case "radio":
+			opts["format"] = "radio"
+		case "textarea":
+			opts["multi"] = true
+		}
+	}
+	if target.ReadOnly != nil && *target.ReadOnly {
+		opts["readonly"] = true
+	}
+	if items, ok := target.Validators["items"].(*Items); ok && items.single && len(items.Schemas) == 1 {
+		if item, ok := resolveSchema(items.Schemas[0]); ok && hasUIProperties(item) && !g.seen[item] {
+			opts["detail"] = &UIElement{Type: "VerticalLayout", Elements: g.elements(item, "#")}
+		}
+	}
+	if len(opts) > 0 {
+		c.Options = opts
+	}
+	return c
+}
+
+// uiWidget picks the input a form should render for values of s, the empty
+// string if it has no preference
+func uiWidget(s *Schema) string {
+	if vals := enumValues(s); len(vals) > 0 {
+		if len(vals) <= 3 {
+			return "radio"
+		}
+		return "select"
+	}
+	switch s.Format {
+	case "date", "date-time", "time", "email", "uri", "iri":
+		return s.Format
+	case "idn-email":
+		return "email"
+	}
+	if s.WriteOnly != nil && *s.WriteOnly {
+		return "password"
+	}
+	types, _ := nonNullTypes(s)
+	if len(types) != 1 {
+		return ""
+	}
+	switch types[0] {
+	case "boolean":
+		return "checkbox"
+	case "integer", "number":
+		return "number"
+	case "string":
+		if max, ok := s.Validators["maxLength"].(*MaxLength); ok && *max > 255 {
+			return "textarea"
+		}
+		return "text"
+	}
+	return ""
+}
+
+func hasUIProperties(s *Schema) bool {
+	props, ok := s.Validators["properties"].(*Properties)
+	return ok && len(*props) > 0
+}
+
+// uiPropertyOrder orders the properties of s for display
+func uiPropertyOrder(s *Schema) []string {
+	props := s.Validators["properties"].(*Properties)
+	rank := map[string]int{}
+	if req, ok := s.Validators["required"].(*Required); ok {
+		for i, name := range *req {
+			if _, ok := rank[name]; !ok {
+				rank[name] = i
+			}
+		}
+	}
+	order := map[string]float64{}
+	for name, sch := range *props {
+		if sch == nil {
+			continue
+		}
+		var n float64
+		if raw, ok := sch.extraKeywords["propertyOrder"]; ok && json.Unmarshal(raw, &n) == nil {
+			order[name] = n
+		}
+	}
+
+	names := sortedPropertyKeys(*props)
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		oa, aok := order[a]
+		ob, bok := order[b]
+		if aok || bok {
+			return aok && (!bok || oa < ob)
+		}
+		ra, aok := rank[a]
+		rb, bok := rank[b]
+		return aok && (!bok || ra < rb)
+	})
+	return names
+}
+
+// uiLabel labels the property name described by s with its title, or with
+// name split into capitalized words
+func uiLabel(s *Schema, name string) string {
+	if s != nil && s.Title != "" {
+		return s.Title
+	}
+	words := []string{}
+	word := []rune{}
+	prev := rune(0)
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			r = 0
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+		default:
+			word = append(word, r)
+			prev = r
+			continue
+		}
+		if len(word) > 0 {
+			words = append(words, string(word))
+		}
+		word = word[:0]
+		if r != 0 {
+			word = append(word, r)
+		}
+		prev = r
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	for i, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,63 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateUISchema(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"firstName": {"type": "string", "maxLength": 50},
+			"last_name": {"type": "string", "title": "Surname"},
+			"bio": {"type": "string", "maxLength": 1000},
+			"born": {"type": "string", "format": "date"},
+			"size": {"enum": ["s", "m", "l"]},
+			"color": {"enum": ["red", "green", "blue", "cyan"]},
+			"active": {"type": "boolean", "readOnly": true},
+			"password": {"type": "string", "writeOnly": true},
+			"id": {"type": "integer", "propertyOrder": 1},
+			"address": {"$ref": "#/definitions/address"},
+			"pets": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}}
+		},
+		"required": ["last_name", "firstName"],
+		"definitions": {
+			"address": {
+				"type": "object",
+				"properties": {
+					"city": {"type": "string"},
+					"next": {"$ref": "#/definitions/address"}
+				}
+			}
+		}
+	}`)
+
+	data, err := json.Marshal(GenerateUISchema(rs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"type":"VerticalLayout","elements":[` +
+		`{"type":"Control","scope":"#/properties/id","label":"Id","options":{"widget":"number"}},` +
+		`{"type":"Control","scope":"#/properties/last_name","label":"Surname","options":{"widget":"text"}},` +
+		`{"type":"Control","scope":"#/properties/firstName","label":"First Name","options":{"widget":"text"}},` +
+		`{"type":"Control","scope":"#/properties/active","label":"Active","options":{"readonly":true,"widget":"checkbox"}},` +
+		`{"type":"Group","label":"Address","elements":[` +
+		`{"type":"Control","scope":"#/properties/address/properties/city","label":"City","options":{"widget":"text"}},` +
+		`{"type":"Control","scope":"#/properties/address/properties/next","label":"Next"}]},` +
+		`{"type":"Control","scope":"#/properties/bio","label":"Bio","options":{"multi":true,"widget":"textarea"}},` +
+		`{"type":"Control","scope":"#/properties/born","label":"Born","options":{"widget":"date"}},` +
+		`{"type":"Control","scope":"#/properties/color","label":"Color","options":{"widget":"select"}},` +
+		`{"type":"Control","scope":"#/properties/password","label":"Password","options":{"widget":"password"}},` +
+		`{"type":"Control","scope":"#/properties/pets","label":"Pets","options":{"detail":{"type":"VerticalLayout","elements":[` +
+		`{"type":"Control","scope":"#/properties/name","label":"Name","options":{"widget":"text"}}]}}},` +
+		`{"type":"Control","scope":"#/properties/size","label":"Size","options":{"format":"radio","widget":"radio"}}]}`
+	if string(data) != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, data)
+	}
+
+	data, _ = json.Marshal(GenerateUISchema(Must(`{"type": "string", "format": "email", "title": "Email"}`)))
+	if expect := `{"type":"Control","scope":"#","label":"Email","options":{"widget":"email"}}`; string(data) != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, data)
+	}
+}
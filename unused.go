@@ -0,0 +1,98 @@
+package jsonschema
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// UnusedDefinitions lists JSON pointers to the entries of "definitions" and
+// "$defs", at any depth, that validation can never reach, ordered by
+// pointer. A definition is used when a reference reachable from the root
+// schema, or from the root of any sibling document, points at it or at a
+// schema nested inside it.
+// Definitions only referenced by other unused definitions are unused too,
+// so every listed entry can be removed without breaking a reference.
+// Sibling references are matched by the resolved schema, or by URI when rs
+// declares an $id
+func (rs *RootSchema) UnusedDefinitions(siblings ...*RootSchema) []string {
+	reached := map[*Schema]bool{}
+	reachSchemas(&rs.Schema, reached)
+	for _, sib := range siblings {
+		reachSchemas(&sib.Schema, reached)
+		walkSchemas("", &sib.Schema, func(_ string, sch *Schema) error {
+			if target := rs.resolveExternalRef(sch.Ref); target != nil {
+				reachSchemas(target, reached)
+			}
+			return nil
+		})
+	}
+
+	unused := []string{}
+	walkSchemas("", &rs.Schema, func(ptr string, sch *Schema) error {
+		for _, defs := range []struct {
+			keyword string
+			defs    Definitions
+		}{{"$defs", sch.Defs}, {"definitions", sch.Definitions}} {
+			for _, key := range sortedDefinitionKeys(defs.defs) {
+				used := false
+				walkSchemas("", defs.defs[key], func(_ string, s *Schema) error {
+					used = used || reached[s]
+					return nil
+				})
+				if !used {
+					unused = append(unused, ptr+"/"+defs.keyword+"/"+EscapePointerToken(key))
+				}
+			}
+		}
+		return nil
+	})
+	sort.Strings(unused)
+	return unused
+}
+
+// reachSchemas marks every schema validation of s can evaluate, following
+// references but not descending into definitions
+func reachSchemas(s *Schema, reached map[*Schema]bool) {
+	if s == nil || reached[s] {
+		return
+	}
+	reached[s] = true
+	switch target := s.ref.(type) {
+	case *Schema:
+		reachSchemas(target, reached)
+	case *RootSchema:
+		reachSchemas(&target.Schema, reached)
+	}
+	if s.schemaType != schemaTypeObject {
+		return
+	}
+	for _, v := range s.Validators {
+		for _, ch := range subschemas(v) {
+			reachSchemas(ch.schema, reached)
+		}
+	}
+}
+
+// resolveExternalRef finds the schema within rs that a reference from
+// another document identifies by URI, or nil if there isn't one
+func (rs *RootSchema) resolveExternalRef(ref string) *Schema {
+	if ref == "" || rs.ID == "" {
+		return nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil
+	}
+	frag := u.Fragment
+	u.Fragment = ""
+	if strings.TrimSuffix(u.String(), "#") != strings.TrimSuffix(rs.ID, "#") {
+		return nil
+	}
+	ptr, err := ParsePointer(frag)
+	if err != nil {
+		return nil
+	}
+	sch, _ := ptr.EvalSchema(&rs.Schema)
+	return sch
+}
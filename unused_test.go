@@ -0,0 +1,38 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnusedDefinitions(t *testing.T) {
+	rs := Must(`{
+		"$id": "http://example.com/root.json",
+		"properties": {
+			"a": { "$ref": "#/definitions/a" },
+			"c": { "$ref": "#/$defs/c/properties/inner" }
+		},
+		"definitions": {
+			"a": { "items": { "$ref": "#/definitions/b" } },
+			"b": { "type": "string" },
+			"orphan": { "$ref": "#/definitions/orphaned" },
+			"orphaned": { "type": "null" },
+			"shared": { "type": "integer" }
+		},
+		"$defs": {
+			"c": { "properties": { "inner": {} } },
+			"d": { "definitions": { "nested": {} } }
+		}
+	}`)
+
+	expect := []string{"/$defs/d", "/$defs/d/definitions/nested", "/definitions/orphan", "/definitions/orphaned", "/definitions/shared"}
+	if got := rs.UnusedDefinitions(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected: %v\ngot:      %v", expect, got)
+	}
+
+	sibling := Must(`{ "$ref": "http://example.com/root.json#/definitions/shared" }`)
+	expect = []string{"/$defs/d", "/$defs/d/definitions/nested", "/definitions/orphan", "/definitions/orphaned"}
+	if got := rs.UnusedDefinitions(sibling); !reflect.DeepEqual(expect, got) {
+		t.Errorf("with sibling, expected: %v\ngot:      %v", expect, got)
+	}
+}
@@ -0,0 +1,210 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateURI checks str is an absolute URI with the syntax of RFC 3986,
+// as the "uri" format requires: a scheme followed by a hierarchical part
+// and an optional query and fragment, with any character outside the
+// allowed sets percent-encoded
+// https://tools.ietf.org/html/rfc3986#section-3
+func ValidateURI(str string) error {
+	return checkURI(str, true)
+}
+
+// ValidateURIReference checks str is a URI or a relative reference with
+// the syntax of RFC 3986, as the "uri-reference" format requires
+// https://tools.ietf.org/html/rfc3986#section-4.1
+func ValidateURIReference(str string) error {
+	return checkURI(str, false)
+}
+
+// URIFormatOptions configure the uri, uri-reference, iri and iri-reference
+// formats, which follow RFC 3986 and RFC 3987 strictly by default
+type URIFormatOptions struct {
+	// Lenient accepts the characters browsers percent-encode in the URLs
+	// people type, spaces and `"<>\^{|}` among them, as if they were
+	// encoded
+	Lenient bool
+}
+
+// check checks str is a URI, or a URI reference unless absolute, with the
+// relaxations o sets
+func (o URIFormatOptions) check(str string, absolute bool) error {
+	return checkURI(o.escape(str), absolute)
+}
+
+// escape percent-encodes the characters of str lenient parsing accepts,
+// leaving str as it is otherwise
+func (o URIFormatOptions) escape(str string) string {
+	if !o.Lenient {
+		return str
+	}
+	buf := &strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if c := str[i]; c == ' ' || strings.IndexByte(`"<>\^`+"`"+`{|}`, c) != -1 {
+			fmt.Fprintf(buf, "%%%02X", c)
+			continue
+		}
+		buf.WriteByte(str[i])
+	}
+	return buf.String()
+}
+
+// checkURI checks str is a URI reference, requiring a scheme if absolute
+func checkURI(str string, absolute bool) error {
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c == '%' {
+			if i+2 >= len(str) || !isHex(str[i+1]) || !isHex(str[i+2]) {
+				return fmt.Errorf("invalid percent-encoding at offset %d", i)
+			}
+			continue
+		}
+		if !isURIUnreserved(c) && !isURISubDelim(c) && strings.IndexByte(":/?#[]@", c) == -1 {
+			return fmt.Errorf("invalid character %q at offset %d", c, i)
+		}
+	}
+
+	rest := str
+	if i := strings.IndexByte(rest, '#'); i != -1 {
+		if err := checkURIChars("fragment", rest[i+1:], ":@/?"); err != nil {
+			return err
+		}
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '?'); i != -1 {
+		if err := checkURIChars("query", rest[i+1:], ":@/?"); err != nil {
+			return err
+		}
+		rest = rest[:i]
+	}
+
+	scheme := false
+	if i := strings.IndexByte(rest, ':'); i != -1 && isURIScheme(rest[:i]) {
+		rest, scheme = rest[i+1:], true
+	}
+	if absolute && !scheme {
+		return fmt.Errorf("missing scheme")
+	}
+
+	path := rest
+	if strings.HasPrefix(rest, "//") {
+		authority := rest[2:]
+		path = ""
+		if i := strings.IndexByte(authority, '/'); i != -1 {
+			authority, path = authority[:i], authority[i:]
+		}
+		if err := checkURIAuthority(authority); err != nil {
+			return err
+		}
+	} else if !scheme {
+		// path-noscheme: a colon in the first segment would read as a scheme
+		if first := strings.SplitN(path, "/", 2)[0]; strings.Contains(first, ":") {
+			return fmt.Errorf("the first segment of a relative path can't contain \":\"")
+		}
+	}
+	return checkURIChars("path", path, ":@/")
+}
+
+// checkURIAuthority checks an authority: optional user information, a
+// host and an optional port
+func checkURIAuthority(authority string) error {
+	host := authority
+	if i := strings.IndexByte(authority, '@'); i != -1 {
+		if err := checkURIChars("user information", authority[:i], ":"); err != nil {
+			return err
+		}
+		host = authority[i+1:]
+	}
+
+	port := ""
+	if strings.HasPrefix(host, "[") {
+		end := strings.IndexByte(host, ']')
+		if end == -1 {
+			return fmt.Errorf("unterminated IP literal")
+		}
+		if err := checkURIIPLiteral(host[1:end]); err != nil {
+			return err
+		}
+		port = host[end+1:]
+		if port != "" && port[0] != ':' {
+			return fmt.Errorf("unexpected %q after IP literal", port)
+		}
+	} else {
+		if i := strings.IndexByte(host, ':'); i != -1 {
+			host, port = host[:i], host[i:]
+		}
+		if err := checkURIChars("host", host, ""); err != nil {
+			return err
+		}
+	}
+
+	for i := 1; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return fmt.Errorf("invalid port %q", port[1:])
+		}
+	}
+	return nil
+}
+
+// checkURIIPLiteral checks the address within the brackets of an IP
+// literal, an IPv6 address or an "IPvFuture"
+func checkURIIPLiteral(addr string) error {
+	if !strings.HasPrefix(addr, "v") && !strings.HasPrefix(addr, "V") {
+		if err := ValidateIPv6(addr); err != nil {
+			return fmt.Errorf("invalid IP literal: %s", err.Error())
+		}
+		return nil
+	}
+	dot := strings.IndexByte(addr, '.')
+	if dot < 2 || dot == len(addr)-1 {
+		return fmt.Errorf("invalid IP literal %q", addr)
+	}
+	for i := 1; i < dot; i++ {
+		if !isHex(addr[i]) {
+			return fmt.Errorf("invalid IP literal %q", addr)
+		}
+	}
+	return checkURIChars("IP literal", addr[dot+1:], ":")
+}
+
+// checkURIChars checks part of a URI only holds unreserved characters,
+// percent-encodings, sub-delimiters and the characters of extra
+func checkURIChars(part, str, extra string) error {
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c != '%' && !isURIUnreserved(c) && !isURISubDelim(c) && strings.IndexByte(extra, c) == -1 {
+			return fmt.Errorf("invalid character %q in %s", c, part)
+		}
+	}
+	return nil
+}
+
+// isURIScheme reports whether str is a scheme: a letter followed by
+// letters, digits, "+", "-" and "."
+func isURIScheme(str string) bool {
+	if str == "" {
+		return false
+	}
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || !(c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.')) {
+			return false
+		}
+	}
+	return true
+}
+
+// isURIUnreserved reports whether c is an unreserved character of RFC 3986
+func isURIUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-._~", c) != -1
+}
+
+// isURISubDelim reports whether c is a "sub-delims" character of RFC 3986
+func isURISubDelim(c byte) bool {
+	return strings.IndexByte("!$&'()*+,;=", c) != -1
+}
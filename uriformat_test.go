@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestValidateURI(t *testing.T) {
+	cases := []struct {
+		str, uriErr, refErr string
+	}{
+		{"http://foo.bar/?baz=qux#quux", "", ""},
+		{"http://-.~_!$&'()*+,;=:%40:80%2f::::::@example.com", "", ""},
+		{"ldap://[2001:db8::7]/c=GB?objectClass?one", "", ""},
+		{"http://[v1.fe80::a+en1]:8080/", "", ""},
+		{"mailto:John.Doe@example.com", "", ""},
+		{"urn:oasis:names:specification:docbook:dtd:xml:4.1.2", "", ""},
+		{"file:///etc/hosts", "", ""},
+		{"a:b", "", ""},
+		{"//foo.bar/?baz=qux#quux", "missing scheme", ""},
+		{"/abc", "missing scheme", ""},
+		{"abc", "missing scheme", ""},
+		{"#fragment", "missing scheme", ""},
+		{"", "missing scheme", ""},
+		{"./a:b", "missing scheme", ""},
+		{"1a:b", "missing scheme", `the first segment of a relative path can't contain ":"`},
+		{":// should fail", "invalid character ' ' at offset 3", "invalid character ' ' at offset 3"},
+		{`\\WINDOWS\fileshare`, `invalid character '\\' at offset 0`, `invalid character '\\' at offset 0`},
+		{"http://foo.bar/a%2", "invalid percent-encoding at offset 16", "invalid percent-encoding at offset 16"},
+		{"http://foo.bar/%zz", "invalid percent-encoding at offset 15", "invalid percent-encoding at offset 15"},
+		{"http://foo.bar/#a#b", "invalid character '#' in fragment", "invalid character '#' in fragment"},
+		{"http://foo.bar/a[1]", "invalid character '[' in path", "invalid character '[' in path"},
+		{"http://foo.bar:80a/", `invalid port "80a"`, `invalid port "80a"`},
+		{"http://a@b@c/", "invalid character '@' in host", "invalid character '@' in host"},
+		{"http://[::1/", "unterminated IP literal", "unterminated IP literal"},
+		{"http://[::1]x/", `unexpected "x" after IP literal`, `unexpected "x" after IP literal`},
+		{"http://[fe80::1%25eth0]/", `invalid IP literal: zone ID "%25eth0" isn't part of an IPv6 address`, `invalid IP literal: zone ID "%25eth0" isn't part of an IPv6 address`},
+	}
+	errString := func(err error) string {
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+	for _, c := range cases {
+		if got := errString(ValidateURI(c.str)); got != c.uriErr {
+			t.Errorf("uri %q: expected error %q, got %q", c.str, c.uriErr, got)
+		}
+		if got := errString(ValidateURIReference(c.str)); got != c.refErr {
+			t.Errorf("uri-reference %q: expected error %q, got %q", c.str, c.refErr, got)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// URITemplate is a URI template of any level of RFC 6570
+type URITemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+// templatePart is a literal or, when op is set, an expression of a
+// template. Literals are kept encoded
+type templatePart struct {
+	literal string
+	op      *templateOp
+	vars    []templateVar
+}
+
+// templateVar is a variable of an expression with its modifier. prefix is
+// 0 when the variable has no prefix modifier
+type templateVar struct {
+	name    string
+	explode bool
+	prefix  int
+}
+
+// templateOp describes the expansion of an operator, as in the table of
+// RFC 6570 appendix A
+type templateOp struct {
+	first, sep string
+	named      bool
+	ifEmpty    string
+	reserved   bool
+}
+
+var templateOps = map[byte]*templateOp{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// ParseURITemplate parses a URI template, reporting templates that aren't
+// valid according to RFC 6570
+func ParseURITemplate(tmpl string) (*URITemplate, error) {
+	t := &URITemplate{raw: tmpl}
+	for i := 0; i < len(tmpl); {
+		switch c := tmpl[i]; {
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated expression at offset %d", i)
+			}
+			part, err := parseTemplateExpression(tmpl[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression {%s}: %s", tmpl[i+1:i+end], err.Error())
+			}
+			t.parts = append(t.parts, part)
+			i += end + 1
+		case c == '}':
+			return nil, fmt.Errorf("unmatched } at offset %d", i)
+		case c == '%':
+			if i+2 >= len(tmpl) || !isHex(tmpl[i+1]) || !isHex(tmpl[i+2]) {
+				return nil, fmt.Errorf("invalid percent-encoding at offset %d", i)
+			}
+			t.appendLiteral(tmpl[i : i+3])
+			i += 3
+		case c <= ' ' || c == 0x7f || strings.IndexByte("\"'<>\\^`|", c) != -1:
+			return nil, fmt.Errorf("invalid character %q at offset %d", c, i)
+		default:
+			t.appendLiteral(encodeTemplateValue(tmpl[i:i+1], true))
+			i++
+		}
+	}
+	return t, nil
+}
+
+func (t *URITemplate) appendLiteral(lit string) {
+	if n := len(t.parts); n > 0 && t.parts[n-1].op == nil {
+		t.parts[n-1].literal += lit
+		return
+	}
+	t.parts = append(t.parts, templatePart{literal: lit})
+}
+
+// parseTemplateExpression parses the contents of an expression
+func parseTemplateExpression(expr string) (templatePart, error) {
+	part := templatePart{op: templateOps[0]}
+	if expr == "" {
+		return part, fmt.Errorf("no variables")
+	}
+	if op, ok := templateOps[expr[0]]; ok && expr[0] != 0 {
+		part.op = op
+		expr = expr[1:]
+	} else if strings.IndexByte("=,!@|", expr[0]) != -1 {
+		return part, fmt.Errorf("reserved operator %q", expr[0])
+	}
+	for _, spec := range strings.Split(expr, ",") {
+		v := templateVar{name: spec}
+		if strings.HasSuffix(spec, "*") {
+			v.name, v.explode = spec[:len(spec)-1], true
+		} else if i := strings.IndexByte(spec, ':'); i != -1 {
+			n, err := strconv.Atoi(spec[i+1:])
+			if err != nil || n < 1 || n > 9999 || spec[i+1] == '0' {
+				return part, fmt.Errorf("invalid prefix %q", spec[i+1:])
+			}
+			v.name, v.prefix = spec[:i], n
+		}
+		if !isTemplateVarName(v.name) {
+			return part, fmt.Errorf("invalid variable name %q", v.name)
+		}
+		part.vars = append(part.vars, v)
+	}
+	return part, nil
+}
+
+// isTemplateVarName reports whether name is a varname of RFC 6570
+func isTemplateVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' || strings.Contains(name, "..") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(name) || !isHex(name[i+1]) || !isHex(name[i+2]) {
+				return false
+			}
+			i += 2
+		case c != '_' && c != '.' && !isAlphaNum(c):
+			return false
+		}
+	}
+	return true
+}
+
+// String gives the template as it was parsed
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Variables lists the names of the template's variables in order of
+// appearance, without duplicates
+func (t *URITemplate) Variables() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, part := range t.parts {
+		for _, v := range part.vars {
+			if !seen[v.name] {
+				seen[v.name] = true
+				names = append(names, v.name)
+			}
+		}
+	}
+	return names
+}
+
+// Expand fills in the template from vars. Values are strings, numbers or
+// booleans, lists as []interface{} or associative arrays as
+// map[string]interface{}, whose keys are expanded in sorted order, as
+// encoding/json decodes them. Variables that are missing, null or empty
+// lists or associative arrays are undefined, and left out. Prefix
+// modifiers don't apply to lists and associative arrays
+func (t *URITemplate) Expand(vars map[string]interface{}) string {
+	buf := &strings.Builder{}
+	for _, part := range t.parts {
+		if part.op == nil {
+			buf.WriteString(part.literal)
+			continue
+		}
+		first := true
+		for _, v := range part.vars {
+			expanded, ok := expandTemplateVar(part.op, v, vars[v.name])
+			if !ok {
+				continue
+			}
+			if first {
+				buf.WriteString(part.op.first)
+				first = false
+			} else {
+				buf.WriteString(part.op.sep)
+			}
+			buf.WriteString(expanded)
+		}
+	}
+	return buf.String()
+}
+
+// ExpandURITemplate fills in the URI template tmpl from the properties of
+// data, an object instance, as Expand does
+func ExpandURITemplate(tmpl string, data interface{}) (string, error) {
+	t, err := ParseURITemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+	vars, _ := data.(map[string]interface{})
+	return t.Expand(vars), nil
+}
+
+// expandTemplateVar expands a variable of an expression, reporting false
+// when it's undefined
+func expandTemplateVar(op *templateOp, v templateVar, val interface{}) (string, bool) {
+	named := func(name, value string) string {
+		if value == "" {
+			return name + op.ifEmpty
+		}
+		return name + "=" + value
+	}
+
+	switch t := val.(type) {
+	case []interface{}:
+		items := []string{}
+		for _, item := range t {
+			if s, ok := templateScalar(item); ok {
+				items = append(items, encodeTemplateValue(s, op.reserved))
+			}
+		}
+		if len(items) == 0 {
+			return "", false
+		}
+		if !v.explode {
+			if op.named {
+				return named(v.name, strings.Join(items, ",")), true
+			}
+			return strings.Join(items, ","), true
+		}
+		if op.named {
+			for i, item := range items {
+				items[i] = named(v.name, item)
+			}
+		}
+		return strings.Join(items, op.sep), true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			if _, ok := templateScalar(t[key]); ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return "", false
+		}
+		sort.Strings(keys)
+		pairs := []string{}
+		for _, key := range keys {
+			s, _ := templateScalar(t[key])
+			k, val := encodeTemplateValue(key, op.reserved), encodeTemplateValue(s, op.reserved)
+			if !v.explode {
+				pairs = append(pairs, k, val)
+			} else if op.named {
+				pairs = append(pairs, named(k, val))
+			} else {
+				pairs = append(pairs, k+"="+val)
+			}
+		}
+		if !v.explode {
+			if op.named {
+				return named(v.name, strings.Join(pairs, ",")), true
+			}
+			return strings.Join(pairs, ","), true
+		}
+		return strings.Join(pairs, op.sep), true
+	}
+
+	s, ok := templateScalar(val)
+	if !ok {
+		return "", false
+	}
+	if runes := []rune(s); v.prefix > 0 && v.prefix < len(runes) {
+		s = string(runes[:v.prefix])
+	}
+	s = encodeTemplateValue(s, op.reserved)
+	if op.named {
+		return named(v.name, s), true
+	}
+	return s, true
+}
+
+// templateScalar formats a string, number or boolean value
+func templateScalar(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(t), true
+	}
+	return "", false
+}
+
+// encodeTemplateValue percent-encodes the characters of s other than the
+// unreserved characters of RFC 3986, also allowing reserved characters and
+// percent-encoded triplets if reserved is set
+func encodeTemplateValue(s string, reserved bool) string {
+	buf := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlphaNum(c) || c == '-' || c == '.' || c == '_' || c == '~':
+			buf.WriteByte(c)
+		case reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) != -1:
+			buf.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			buf.WriteString(s[i : i+3])
+			i += 2
+		default:
+			fmt.Fprintf(buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func isAlphaNum(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,158 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestURITemplateExpand(t *testing.T) {
+	// examples from RFC 6570 section 3.2
+	vars := map[string]interface{}{
+		"count":      []interface{}{"one", "two", "three"},
+		"dom":        []interface{}{"example", "com"},
+		"dub":        "me/too",
+		"hello":      "Hello World!",
+		"half":       "50%",
+		"var":        "value",
+		"who":        "fred",
+		"base":       "http://example.com/home/",
+		"path":       "/foo/bar",
+		"list":       []interface{}{"red", "green", "blue"},
+		"keys":       map[string]interface{}{"comma": ",", "dot": ".", "semi": ";"},
+		"v":          "6",
+		"x":          "1024",
+		"y":          "768",
+		"empty":      "",
+		"empty_keys": map[string]interface{}{},
+		"undef":      nil,
+		"n":          2.5,
+	}
+	cases := []struct {
+		tmpl, expect string
+	}{
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{half}", "50%25"},
+		{"O{empty}X", "OX"},
+		{"O{undef}X", "OX"},
+		{"{x,y}", "1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"?{x,empty}", "?1024,"},
+		{"?{x,undef}", "?1024"},
+		{"{var:3}", "val"},
+		{"{var:30}", "value"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+		{"{+var}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+half}", "50%25"},
+		{"{base}index", "http%3A%2F%2Fexample.com%2Fhome%2Findex"},
+		{"{+base}index", "http://example.com/home/index"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"{+path:6}/here", "/foo/b/here"},
+		{"{+keys*}", "comma=,,dot=.,semi=;"},
+		{"{#var}", "#value"},
+		{"{#hello}", "#Hello%20World!"},
+		{"{#path:6}/here", "#/foo/b/here"},
+		{"{#list*}", "#red,green,blue"},
+		{"X{.var}", "X.value"},
+		{"X{.x,y}", "X.1024.768"},
+		{"{.dom*}", ".example.com"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"X{.empty_keys}", "X"},
+		{"{/who}", "/fred"},
+		{"{/who,who}", "/fred/fred"},
+		{"{/half,who}", "/50%25/fred"},
+		{"{/who}{/dub}", "/fred/me%2Ftoo"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/var:1,var}", "/v/value"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{/keys*}", "/comma=%2C/dot=./semi=%3B"},
+		{"{;who}", ";who=fred"},
+		{"{;v,empty,who}", ";v=6;empty;who=fred"},
+		{"{;v,bar,who}", ";v=6;who=fred"},
+		{"{;list}", ";list=red,green,blue"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{;keys*}", ";comma=%2C;dot=.;semi=%3B"},
+		{"{?who}", "?who=fred"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?var:3}", "?var=val"},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?list*}", "?list=red&list=green&list=blue"},
+		{"{?keys}", "?keys=comma,%2C,dot,.,semi,%3B"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{&var:3}", "&var=val"},
+		{"{?count*}", "?count=one&count=two&count=three"},
+		{"{n}", "2.5"},
+		{"café/{var}", "caf%C3%A9/value"},
+	}
+	for _, c := range cases {
+		tmpl, err := ParseURITemplate(c.tmpl)
+		if err != nil {
+			t.Errorf("%s: %s", c.tmpl, err.Error())
+			continue
+		}
+		if got := tmpl.Expand(vars); got != c.expect {
+			t.Errorf("%s: expected %s, got %s", c.tmpl, c.expect, got)
+		}
+	}
+}
+
+func TestParseURITemplateErrors(t *testing.T) {
+	cases := []struct {
+		tmpl, err string
+	}{
+		{"/a/{id", "unterminated expression at offset 3"},
+		{"/a/}", "unmatched } at offset 3"},
+		{"{}", "invalid expression {}: no variables"},
+		{"{=x}", `invalid expression {=x}: reserved operator '='`},
+		{"{x:0}", `invalid expression {x:0}: invalid prefix "0"`},
+		{"{x:10000}", `invalid expression {x:10000}: invalid prefix "10000"`},
+		{"{a b}", `invalid expression {a b}: invalid variable name "a b"`},
+		{"{a..b}", `invalid expression {a..b}: invalid variable name "a..b"`},
+		{"{?a,}", `invalid expression {?a,}: invalid variable name ""`},
+		{"/a b", "invalid character ' ' at offset 2"},
+		{"/%zz", "invalid percent-encoding at offset 1"},
+	}
+	for _, c := range cases {
+		_, err := ParseURITemplate(c.tmpl)
+		if err == nil {
+			t.Errorf("%s: expected error %q", c.tmpl, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%s: expected error %q, got %q", c.tmpl, c.err, err.Error())
+		}
+	}
+}
+
+func TestURITemplateVariables(t *testing.T) {
+	tmpl, err := ParseURITemplate("/users{/id}{?fields*,id,page:2}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(tmpl.Variables(), ","); got != "id,fields,page" {
+		t.Errorf("expected variables id,fields,page, got %s", got)
+	}
+	if tmpl.String() != "/users{/id}{?fields*,id,page:2}" {
+		t.Errorf("expected the template as parsed, got %s", tmpl.String())
+	}
+}
+
+func TestExpandURITemplate(t *testing.T) {
+	data := map[string]interface{}{"id": 7.0, "tags": []interface{}{"a", "b c"}}
+	got, err := ExpandURITemplate("/things/{id}{?tags*}", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "/things/7?tags=a&tags=b%20c" {
+		t.Errorf("unexpected expansion %s", got)
+	}
+	if _, err := ExpandURITemplate("/things/{id", data); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,64 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UUIDFormatOptions configures the "uuid" format, which otherwise accepts
+// the hyphenated form of RFC 4122 of any version, in either case
+type UUIDFormatOptions struct {
+	// Versions are the UUID versions accepted, such as {4} for random
+	// UUIDs. All versions are accepted if it's empty
+	Versions []int
+	// ForbidUppercase only accepts lowercase hex digits, the form RFC 4122
+	// outputs
+	ForbidUppercase bool
+	// AllowBraces also accepts UUIDs wrapped in braces, as Microsoft
+	// writes GUIDs: "{123e4567-e89b-12d3-a456-426614174000}"
+	AllowBraces bool
+}
+
+// A string instance is valid against "uuid" if it is a UUID as RFC 4122,
+// section 3 writes it: 32 hex digits in groups of 8, 4, 4, 4 and 12
+// separated by hyphens, as far as the options allow
+// https://tools.ietf.org/html/rfc4122#section-3
+func (o UUIDFormatOptions) check(uuid string) error {
+	if strings.HasPrefix(uuid, "{") && strings.HasSuffix(uuid, "}") {
+		if !o.AllowBraces {
+			return fmt.Errorf("braces aren't allowed")
+		}
+		uuid = uuid[1 : len(uuid)-1]
+	}
+	if len(uuid) != 36 {
+		return fmt.Errorf("must be 36 characters long, not %d", len(uuid))
+	}
+	for i := 0; i < len(uuid); i++ {
+		c := uuid[i]
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return fmt.Errorf("expected a hyphen at offset %d", i)
+			}
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'f':
+		case c >= 'A' && c <= 'F':
+			if o.ForbidUppercase {
+				return fmt.Errorf("uppercase hex digits aren't allowed")
+			}
+		default:
+			return fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+
+	if len(o.Versions) == 0 {
+		return nil
+	}
+	// the version is the first hex digit of the third group
+	version := strings.IndexByte("0123456789abcdef", uuid[14]|0x20)
+	for _, v := range o.Versions {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("version %d isn't allowed", version)
+}
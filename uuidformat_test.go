@@ -0,0 +1,41 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestUUIDFormat(t *testing.T) {
+	cases := []struct {
+		opts  UUIDFormatOptions
+		value string
+		err   string
+	}{
+		{UUIDFormatOptions{}, "123e4567-e89b-12d3-a456-426614174000", ""},
+		{UUIDFormatOptions{}, "123E4567-E89B-12D3-A456-426614174000", ""},
+		{UUIDFormatOptions{}, "00000000-0000-0000-0000-000000000000", ""},
+		{UUIDFormatOptions{}, "123e4567e89b12d3a456426614174000", "invalid uuid: must be 36 characters long, not 32"},
+		{UUIDFormatOptions{}, "123e4567-e89b-12d3-a456_426614174000", "invalid uuid: expected a hyphen at offset 23"},
+		{UUIDFormatOptions{}, "123e4567-e89b-12d3-a456-42661417400g", "invalid uuid: unexpected character 'g' at offset 35"},
+		{UUIDFormatOptions{}, "{123e4567-e89b-12d3-a456-426614174000}", "invalid uuid: braces aren't allowed"},
+		{UUIDFormatOptions{AllowBraces: true}, "{123e4567-e89b-12d3-a456-426614174000}", ""},
+		{UUIDFormatOptions{AllowBraces: true}, "{123e4567-e89b-12d3-a456-426614174000", "invalid uuid: must be 36 characters long, not 37"},
+		{UUIDFormatOptions{ForbidUppercase: true}, "123E4567-e89b-12d3-a456-426614174000", "invalid uuid: uppercase hex digits aren't allowed"},
+		{UUIDFormatOptions{Versions: []int{4}}, "f47ac10b-58cc-4372-a567-0e02b2c3d479", ""},
+		{UUIDFormatOptions{Versions: []int{4}}, "123e4567-e89b-12d3-a456-426614174000", "invalid uuid: version 1 isn't allowed"},
+		{UUIDFormatOptions{Versions: []int{1, 4}}, "123E4567-E89B-12D3-A456-426614174000", ""},
+		{UUIDFormatOptions{Versions: []int{10}}, "123e4567-e89b-a2d3-a456-426614174000", ""},
+	}
+	for i, c := range cases {
+		rs := Must(`{"format": "uuid"}`)
+		rs.SetOptions(Options{Formats: FormatOptions{UUID: c.opts}})
+		errs := []ValError{}
+		rs.Validate("/", c.value, &errs)
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].Message
+		}
+		if got != c.err {
+			t.Errorf("case %d %q: expected error %q, got %q", i, c.value, c.err, got)
+		}
+	}
+}
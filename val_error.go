@@ -18,17 +18,30 @@ type ValError struct {
 	RulePath string `json:"rulePath,omitempty"`
 	// Message is a human-readable description of the error
 	Message string `json:"message"`
+	// Line is the line of the document holding the invalid value, when
+	// known, counting from 1
+	Line int `json:"line,omitempty"`
+	// PropertyName is the name of the property at PropertyPath when it's
+	// the name itself that's invalid, as with "propertyNames"
+	PropertyName string `json:"propertyName,omitempty"`
+	// MissingProperties are the names of the required properties missing
+	// from the object at PropertyPath, as "required" reports them
+	MissingProperties []string `json:"missingProperties,omitempty"`
 }
 
 // Error implements the error interface for ValError
 func (v ValError) Error() string {
-	// [propPath]: [value] [message]
+	// [line N: ][propPath]: [value] [message]
+	prefix := ""
+	if v.Line > 0 {
+		prefix = fmt.Sprintf("line %d: ", v.Line)
+	}
 	if v.PropertyPath != "" && v.InvalidValue != nil {
-		return fmt.Sprintf("%s: %s %s", v.PropertyPath, InvalidValueString(v.InvalidValue), v.Message)
+		return fmt.Sprintf("%s%s: %s %s", prefix, v.PropertyPath, InvalidValueString(v.InvalidValue), v.Message)
 	} else if v.PropertyPath != "" {
-		return fmt.Sprintf("%s: %s", v.PropertyPath, v.Message)
+		return fmt.Sprintf("%s%s: %s", prefix, v.PropertyPath, v.Message)
 	}
-	return v.Message
+	return prefix + v.Message
 }
 
 // InvalidValueString returns the errored value as a string
@@ -104,4 +104,11 @@ var DefaultValidators = map[string]ValMaker{
 
 	//optional formats
 	"format": NewFormat,
+
+	// OpenAPI keywords
+	"discriminator": NewDiscriminator,
+	"nullable":      NewNullable,
+
+	// hyper-schema keywords
+	"links": NewLinks,
 }
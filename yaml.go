@@ -0,0 +1,1100 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseYAML decodes a schema written as a YAML document. YAML is read as
+// the JSON it's a superset of: plain scalars are typed by YAML 1.2's core
+// schema, so "true", "~" and "0x1f" are a boolean, null and number while
+// quoted scalars are always strings, anchors and aliases are expanded,
+// including "<<" merge keys, and mapping keys must be unique. Keys keep
+// their document order where the package preserves it, as for
+// patternProperties. Tags other than the core schema's, complex keys and
+// streams of several documents aren't supported
+func ParseYAML(data []byte) (*RootSchema, error) {
+	doc, err := decodeYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	js, err := doc.json()
+	if err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := json.Unmarshal(js, rs); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %s", err.Error())
+	}
+	return rs, nil
+}
+
+// ValidateYAML validates a YAML document, decoded as ParseYAML decodes
+// schemas, as ValidateBytes validates JSON. Documents that aren't valid
+// YAML, have duplicate keys or hold values JSON can't represent are
+// errors. Each ValError gives the line of the value it's about, or of the
+// key holding it
+func (rs *RootSchema) ValidateYAML(data []byte) ([]ValError, error) {
+	errs := []ValError{}
+	root, err := decodeYAML(data)
+	if err != nil {
+		return errs, fmt.Errorf("error parsing YAML: %s", err.Error())
+	}
+	js, err := root.json()
+	if err != nil {
+		return errs, fmt.Errorf("error parsing YAML: %s", err.Error())
+	}
+	doc, err := rs.options().Decoding.decodeJSON(js)
+	if err != nil {
+		return errs, fmt.Errorf("error parsing YAML: %s", err.Error())
+	}
+	rs.Validate("/", doc, &errs)
+	for i := range errs {
+		errs[i].Line = root.lineOf(errs[i].PropertyPath)
+	}
+	return errs, nil
+}
+
+// lineOf gives the line of the node at the JSON pointer ptr within the
+// document rooted at n, or of the key of the mapping entry holding it. It's
+// 0 if there's no such node
+func (n *yamlNode) lineOf(ptr string) int {
+	if ptr == "/" {
+		ptr = ""
+	}
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return 0
+	}
+	line := n.line
+	for _, tok := range tokens {
+		n = n.resolve()
+		switch n.kind {
+		case yamlMapping:
+			entries, err := n.entries()
+			if err != nil {
+				return 0
+			}
+			found := false
+			for _, e := range entries {
+				if e.key == tok {
+					n, line, found = e.val, e.line, true
+					break
+				}
+			}
+			if !found {
+				return 0
+			}
+		case yamlSequence:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(n.children) {
+				return 0
+			}
+			n = n.children[i]
+			line = n.line
+		default:
+			return 0
+		}
+	}
+	return line
+}
+
+// yamlKind is the kind of a YAML node
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMapping
+	yamlSequence
+	yamlAlias
+)
+
+// yamlNode is a node of a YAML document. Mappings hold alternating keys and
+// values in children, aliases the node they refer to in target. line and
+// col locate the node's start, counting from 1
+type yamlNode struct {
+	kind     yamlKind
+	tag      string
+	value    string
+	plain    bool
+	children []*yamlNode
+	target   *yamlNode
+	line     int
+	col      int
+}
+
+// decodeYAML parses data as a single YAML document
+func decodeYAML(data []byte) (*yamlNode, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	p := &yamlParser{src: data, line: 1, anchors: map[string]*yamlNode{}}
+
+	var doc *yamlNode
+	for {
+		p.skipToContent()
+		for p.col() == 0 && p.peek() == '%' {
+			// directives such as %YAML don't change how documents are read
+			p.skipLine()
+			p.skipToContent()
+		}
+		if p.eof() {
+			break
+		}
+		if doc != nil {
+			return nil, p.errorf("expected a single document")
+		}
+		if p.atMarker("---") {
+			p.advance(3)
+			p.skipToContent()
+		}
+		if p.eof() || p.atMarker("---") || p.atMarker("...") {
+			doc = &yamlNode{kind: yamlScalar, plain: true, line: p.line, col: p.col() + 1}
+		} else {
+			node, err := p.parseBlock(-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node
+		}
+		p.skipToContent()
+		if p.atMarker("...") {
+			p.advance(3)
+			continue
+		}
+		if !p.eof() && !p.atMarker("---") {
+			return nil, p.errorf("unexpected %q", p.peekRune())
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("yaml: empty document")
+	}
+	return doc, nil
+}
+
+// yamlParser reads YAML from src. Columns count bytes from the start of the
+// line at lineStart, from 0
+type yamlParser struct {
+	src       []byte
+	pos       int
+	line      int
+	lineStart int
+	anchors   map[string]*yamlNode
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *yamlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *yamlParser) col() int {
+	return p.pos - p.lineStart
+}
+
+// at gives the byte off bytes ahead, 0 past the end
+func (p *yamlParser) at(off int) byte {
+	if p.pos+off >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+off]
+}
+
+func (p *yamlParser) peek() byte {
+	return p.at(0)
+}
+
+func (p *yamlParser) peekRune() rune {
+	r, _ := utf8.DecodeRune(p.src[p.pos:])
+	return r
+}
+
+// advance moves n bytes ahead, keeping track of lines
+func (p *yamlParser) advance(n int) {
+	for ; n > 0 && !p.eof(); n-- {
+		if p.src[p.pos] == '\n' {
+			p.line++
+			p.lineStart = p.pos + 1
+		}
+		p.pos++
+	}
+}
+
+// blankAt reports whether the byte off bytes ahead ends a token
+func (p *yamlParser) blankAt(off int) bool {
+	switch p.at(off) {
+	case ' ', '\t', '\n', 0:
+		return true
+	}
+	return false
+}
+
+// atMarker reports whether a document marker starts at the current position
+func (p *yamlParser) atMarker(marker string) bool {
+	return p.col() == 0 && bytes.HasPrefix(p.src[p.pos:], []byte(marker)) && p.blankAt(3)
+}
+
+func (p *yamlParser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func (p *yamlParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+	p.advance(1)
+}
+
+// atLineEnd reports whether only a comment, if anything, remains on the line
+func (p *yamlParser) atLineEnd() bool {
+	return p.eof() || p.peek() == '\n' || p.peek() == '#'
+}
+
+// skipToContent moves past spaces, comments and line breaks
+func (p *yamlParser) skipToContent() {
+	for !p.eof() {
+		p.skipSpaces()
+		switch p.peek() {
+		case '#':
+			p.skipLine()
+		case '\n':
+			p.advance(1)
+		default:
+			return
+		}
+	}
+}
+
+// properties reads a node's anchor and tag
+func (p *yamlParser) properties() (anchor, tag string) {
+	for {
+		switch p.peek() {
+		case '&':
+			p.pos++
+			anchor = p.name()
+		case '!':
+			start := p.pos
+			for !p.blankAt(0) && !strings.ContainsRune(",[]{}", rune(p.peek())) {
+				p.pos++
+			}
+			tag = string(p.src[start:p.pos])
+		default:
+			return anchor, tag
+		}
+		p.skipSpaces()
+	}
+}
+
+// name reads an anchor or alias name
+func (p *yamlParser) name() string {
+	start := p.pos
+	for !p.blankAt(0) && !strings.ContainsRune(",[]{}", rune(p.peek())) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// finish attaches properties to a parsed node
+func (p *yamlParser) finish(n *yamlNode, anchor, tag string) *yamlNode {
+	if tag != "" {
+		n.tag = tag
+	}
+	if anchor != "" {
+		p.anchors[anchor] = n
+	}
+	return n
+}
+
+// parseBlock parses the node at the current position, which must be
+// indented further than its parent's indent
+func (p *yamlParser) parseBlock(indent int) (*yamlNode, error) {
+	line, col := p.line, p.col()
+	if p.isMappingKey() {
+		n, err := p.parseMapping(col)
+		if err != nil {
+			return nil, err
+		}
+		n.line, n.col = line, col+1
+		return n, nil
+	}
+	anchor, tag := p.properties()
+	if anchor != "" || tag != "" {
+		if p.atLineEnd() {
+			p.skipToContent()
+			if p.eof() || p.atMarker("---") || p.atMarker("...") || p.col() <= indent {
+				return p.finish(&yamlNode{kind: yamlScalar, plain: true, line: line, col: col + 1}, anchor, tag), nil
+			}
+		}
+		line, col = p.line, p.col()
+	}
+
+	var n *yamlNode
+	var err error
+	switch c := p.peek(); {
+	case c == '*':
+		if anchor != "" || tag != "" {
+			return nil, p.errorf("aliases can't have properties")
+		}
+		return p.alias()
+	case c == '-' && p.blankAt(1):
+		n, err = p.parseSequence(col)
+	case c == '|' || c == '>':
+		n, err = p.parseBlockScalar(indent)
+	case c == '?' && p.blankAt(1):
+		return nil, p.errorf("complex mapping keys aren't supported")
+	case p.isMappingKey():
+		n, err = p.parseMapping(col)
+	case c == '[' || c == '{':
+		n, err = p.parseFlow()
+	case c == '"' || c == '\'':
+		n, err = p.parseQuoted()
+	default:
+		n, err = p.parsePlain(indent, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.line, n.col = line, col+1
+	return p.finish(n, anchor, tag), nil
+}
+
+// isMappingKey reports whether a mapping key starts at the current position
+func (p *yamlParser) isMappingKey() bool {
+	save := *p
+	defer func() { *p = save }()
+	p.anchors = map[string]*yamlNode{}
+	if _, err := p.parseKey(); err != nil {
+		return false
+	}
+	return p.peek() == ':' && p.blankAt(1)
+}
+
+// parseKey parses a mapping key, which must fit on one line
+func (p *yamlParser) parseKey() (*yamlNode, error) {
+	line, col := p.line, p.col()
+	anchor, tag := p.properties()
+	var n *yamlNode
+	var err error
+	switch p.peek() {
+	case '"', '\'':
+		n, err = p.parseQuoted()
+	case '[', '{', '*', '&', '!', '|', '>', '#', '\n', 0:
+		return nil, p.errorf("expected a mapping key")
+	default:
+		if p.peek() == '-' && p.blankAt(1) {
+			return nil, p.errorf("expected a mapping key")
+		}
+		n = &yamlNode{kind: yamlScalar, plain: true, value: p.plainLine(false)}
+		if n.value == "" {
+			return nil, p.errorf("expected a mapping key")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.line != line {
+		return nil, p.errorf("mapping keys must fit on one line")
+	}
+	p.skipSpaces()
+	n.line, n.col = line, col+1
+	return p.finish(n, anchor, tag), nil
+}
+
+// parseMapping parses a block mapping whose keys are at col
+func (p *yamlParser) parseMapping(col int) (*yamlNode, error) {
+	n := &yamlNode{kind: yamlMapping}
+	for {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ':' || !p.blankAt(1) {
+			return nil, p.errorf("expected \":\" after mapping key")
+		}
+		p.pos++
+		p.skipSpaces()
+
+		var val *yamlNode
+		if p.atLineEnd() {
+			line, vcol := p.line, p.col()
+			p.skipToContent()
+			switch {
+			case p.eof() || p.atMarker("---") || p.atMarker("..."):
+			case p.col() > col:
+				val, err = p.parseBlock(col)
+			case p.col() == col && p.peek() == '-' && p.blankAt(1):
+				// sequences may sit at their key's indent
+				val, err = p.parseSequence(col)
+			}
+			if val == nil && err == nil {
+				val = &yamlNode{kind: yamlScalar, plain: true, line: line, col: vcol + 1}
+			}
+		} else {
+			val, err = p.parseBlock(col)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, key, val)
+
+		p.skipToContent()
+		if p.eof() || p.atMarker("---") || p.atMarker("...") || p.col() < col {
+			return n, nil
+		}
+		if p.col() > col {
+			return nil, p.errorf("bad indentation of a mapping entry")
+		}
+	}
+}
+
+// parseSequence parses a block sequence whose entries are at col
+func (p *yamlParser) parseSequence(col int) (*yamlNode, error) {
+	n := &yamlNode{kind: yamlSequence}
+	for {
+		line, icol := p.line, p.col()
+		p.pos++
+		p.skipSpaces()
+		var item *yamlNode
+		var err error
+		if p.atLineEnd() {
+			p.skipToContent()
+			if !p.eof() && !p.atMarker("---") && !p.atMarker("...") && p.col() > col {
+				item, err = p.parseBlock(col)
+			} else {
+				item = &yamlNode{kind: yamlScalar, plain: true, line: line, col: icol + 1}
+			}
+		} else {
+			item, err = p.parseBlock(col)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, item)
+
+		p.skipToContent()
+		if p.eof() || p.atMarker("---") || p.atMarker("...") || p.col() < col {
+			return n, nil
+		}
+		if p.col() > col {
+			return nil, p.errorf("bad indentation of a sequence entry")
+		}
+		if p.peek() != '-' || !p.blankAt(1) {
+			// the end of a sequence at its key's indent
+			return n, nil
+		}
+	}
+}
+
+// alias parses an alias to a previously anchored node
+func (p *yamlParser) alias() (*yamlNode, error) {
+	line, col := p.line, p.col()
+	p.pos++
+	name := p.name()
+	target, ok := p.anchors[name]
+	if !ok {
+		return nil, p.errorf("unknown anchor %q", name)
+	}
+	return &yamlNode{kind: yamlAlias, target: target, line: line, col: col + 1}, nil
+}
+
+// plainLine reads the rest of a plain scalar on the current line
+func (p *yamlParser) plainLine(flow bool) string {
+	start := p.pos
+	end := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == '\n' || (c == '#' && p.pos > start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t')) {
+			break
+		}
+		if c == ':' && (p.blankAt(1) || (flow && strings.ContainsRune(",[]{}", rune(p.at(1))))) {
+			break
+		}
+		if flow && strings.ContainsRune(",[]{}", rune(c)) {
+			break
+		}
+		p.pos++
+		if c != ' ' && c != '\t' {
+			end = p.pos
+		}
+	}
+	p.pos = end
+	return string(p.src[start:end])
+}
+
+// parsePlain parses a plain scalar, which may continue on lines indented
+// further than indent. In flow collections lines may have any indent
+func (p *yamlParser) parsePlain(indent int, flow bool) (*yamlNode, error) {
+	text := p.plainLine(flow)
+	for {
+		save := *p
+		p.skipSpaces()
+		if p.peek() != '\n' {
+			*p = save
+			break
+		}
+		breaks := 0
+		for p.peek() == '\n' {
+			p.advance(1)
+			p.skipSpaces()
+			breaks++
+		}
+		if p.eof() || p.peek() == '#' || p.atMarker("---") || p.atMarker("...") ||
+			(!flow && p.col() <= indent) || (p.peek() == ':' && p.blankAt(1)) ||
+			(flow && strings.ContainsRune(",[]{}", rune(p.peek()))) {
+			*p = save
+			break
+		}
+		line := p.plainLine(flow)
+		if line == "" {
+			*p = save
+			break
+		}
+		if breaks == 1 {
+			text += " "
+		} else {
+			text += strings.Repeat("\n", breaks-1)
+		}
+		text += line
+	}
+	return &yamlNode{kind: yamlScalar, plain: true, value: text}, nil
+}
+
+// parseQuoted parses a single or double-quoted scalar
+func (p *yamlParser) parseQuoted() (*yamlNode, error) {
+	quote := p.peek()
+	p.pos++
+	buf := &strings.Builder{}
+	for {
+		if p.eof() {
+			return nil, p.errorf("unterminated quoted scalar")
+		}
+		c := p.peek()
+		switch {
+		case c == quote && quote == '\'' && p.at(1) == '\'':
+			buf.WriteByte('\'')
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return &yamlNode{kind: yamlScalar, value: buf.String()}, nil
+		case c == '\\' && quote == '"' && p.at(1) == '\n':
+			// escaped line breaks join lines without a space
+			p.advance(2)
+			p.skipSpaces()
+		case c == '\\' && quote == '"':
+			if err := p.escape(buf); err != nil {
+				return nil, err
+			}
+		case c == '\n':
+			str := strings.TrimRight(buf.String(), " \t")
+			buf.Reset()
+			buf.WriteString(str)
+			breaks := 0
+			for p.peek() == '\n' {
+				p.advance(1)
+				p.skipSpaces()
+				breaks++
+			}
+			if p.atMarker("---") || p.atMarker("...") {
+				return nil, p.errorf("unterminated quoted scalar")
+			}
+			if breaks == 1 {
+				buf.WriteByte(' ')
+			} else {
+				buf.WriteString(strings.Repeat("\n", breaks-1))
+			}
+		default:
+			buf.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// yamlEscapes maps the single-character escapes of double-quoted scalars
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028",
+	'P': "\u2029",
+}
+
+// escape reads an escape sequence of a double-quoted scalar
+func (p *yamlParser) escape(buf *strings.Builder) error {
+	c := p.at(1)
+	if s, ok := yamlEscapes[c]; ok {
+		buf.WriteString(s)
+		p.pos += 2
+		return nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if digits == 0 || p.pos+2+digits > len(p.src) {
+		return p.errorf("invalid escape \\%c", c)
+	}
+	code, err := strconv.ParseUint(string(p.src[p.pos+2:p.pos+2+digits]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid escape \\%s", p.src[p.pos+1:p.pos+2+digits])
+	}
+	buf.WriteRune(rune(code))
+	p.pos += 2 + digits
+	return nil
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) block scalar
+func (p *yamlParser) parseBlockScalar(indent int) (*yamlNode, error) {
+	folded := p.peek() == '>'
+	p.pos++
+	chomp := byte(0)
+	explicit := 0
+	for i := 0; i < 2; i++ {
+		switch c := p.peek(); {
+		case (c == '+' || c == '-') && chomp == 0:
+			chomp = c
+			p.pos++
+		case c >= '1' && c <= '9' && explicit == 0:
+			explicit = int(c - '0')
+			p.pos++
+		}
+	}
+	p.skipSpaces()
+	if !p.atLineEnd() {
+		return nil, p.errorf("unexpected %q after block scalar indicator", p.peekRune())
+	}
+	p.skipLine()
+
+	// content is indented as its first line unless given
+	contentIndent := -1
+	if explicit > 0 {
+		contentIndent = indent + explicit
+		if indent < 0 {
+			contentIndent = explicit
+		}
+	}
+	var lines []string
+	for !p.eof() {
+		end := bytes.IndexByte(p.src[p.pos:], '\n')
+		if end == -1 {
+			end = len(p.src) - p.pos
+		}
+		raw := string(p.src[p.pos : p.pos+end])
+		spaces := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.TrimSpace(raw) == "" {
+			if contentIndent != -1 && spaces > contentIndent {
+				lines = append(lines, raw[contentIndent:])
+			} else {
+				lines = append(lines, "")
+			}
+			p.advance(end + 1)
+			continue
+		}
+		if contentIndent == -1 {
+			contentIndent = spaces
+			if contentIndent <= indent {
+				break
+			}
+		}
+		if spaces < contentIndent || p.atMarker("---") || p.atMarker("...") {
+			break
+		}
+		lines = append(lines, raw[contentIndent:])
+		p.advance(end + 1)
+	}
+
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	body := lines[:len(lines)-trailing]
+	text := strings.Join(body, "\n")
+	if folded {
+		text = foldYAMLLines(body)
+	}
+	switch {
+	case chomp == '-':
+	case chomp == '+':
+		if len(body) > 0 {
+			text += "\n"
+		}
+		text += strings.Repeat("\n", trailing)
+	case len(body) > 0:
+		text += "\n"
+	}
+	return &yamlNode{kind: yamlScalar, value: text}, nil
+}
+
+// foldYAMLLines joins the lines of a folded block scalar. Line breaks
+// between lines of text become spaces while those around more indented
+// lines are kept, and each empty line is a line break
+func foldYAMLLines(lines []string) string {
+	more := func(line string) bool {
+		return line != "" && (line[0] == ' ' || line[0] == '\t')
+	}
+	buf := &strings.Builder{}
+	lastText := ""
+	for i, line := range lines {
+		switch {
+		case i == 0:
+		case line == "":
+			buf.WriteByte('\n')
+		case lines[i-1] == "":
+			if more(line) || more(lastText) {
+				buf.WriteByte('\n')
+			}
+		case more(line) || more(lines[i-1]):
+			buf.WriteByte('\n')
+		default:
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(line)
+		if line != "" {
+			lastText = line
+		}
+	}
+	return buf.String()
+}
+
+// parseFlow parses a flow sequence or mapping
+func (p *yamlParser) parseFlow() (*yamlNode, error) {
+	open := p.peek()
+	closing := byte(']')
+	n := &yamlNode{kind: yamlSequence}
+	if open == '{' {
+		closing = '}'
+		n.kind = yamlMapping
+	}
+	p.pos++
+	for {
+		p.skipToContent()
+		if p.eof() {
+			return nil, p.errorf("unterminated flow collection")
+		}
+		if p.peek() == closing {
+			p.pos++
+			return n, nil
+		}
+		line, col := p.line, p.col()
+		key, err := p.parseFlowNode()
+		if err != nil {
+			return nil, err
+		}
+		p.skipToContent()
+		var val *yamlNode
+		if p.peek() == ':' {
+			p.pos++
+			p.skipToContent()
+			if p.peek() == ',' || p.peek() == closing {
+				val = &yamlNode{kind: yamlScalar, plain: true, line: p.line, col: p.col() + 1}
+			} else if val, err = p.parseFlowNode(); err != nil {
+				return nil, err
+			}
+			p.skipToContent()
+		}
+		switch {
+		case n.kind == yamlMapping:
+			if val == nil {
+				val = &yamlNode{kind: yamlScalar, plain: true, line: key.line, col: key.col}
+			}
+			n.children = append(n.children, key, val)
+		case val != nil:
+			// a single pair within a sequence is a mapping
+			pair := &yamlNode{kind: yamlMapping, children: []*yamlNode{key, val}, line: line, col: col + 1}
+			n.children = append(n.children, pair)
+		default:
+			n.children = append(n.children, key)
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case closing:
+		default:
+			if p.eof() {
+				return nil, p.errorf("unterminated flow collection")
+			}
+			return nil, p.errorf("expected \",\" or %q in flow collection", closing)
+		}
+	}
+}
+
+// parseFlowNode parses a node within a flow collection
+func (p *yamlParser) parseFlowNode() (*yamlNode, error) {
+	line, col := p.line, p.col()
+	anchor, tag := p.properties()
+	if anchor != "" || tag != "" {
+		p.skipToContent()
+	}
+	var n *yamlNode
+	var err error
+	switch p.peek() {
+	case '*':
+		return p.alias()
+	case '[', '{':
+		n, err = p.parseFlow()
+	case '"', '\'':
+		n, err = p.parseQuoted()
+	case ',', ']', '}', ':':
+		n = &yamlNode{kind: yamlScalar, plain: true}
+	default:
+		n, err = p.parsePlain(-1, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.line, n.col = line, col+1
+	return p.finish(n, anchor, tag), nil
+}
+
+// yamlMaxNodes bounds the nodes aliases may expand a document to
+const yamlMaxNodes = 1 << 20
+
+// json encodes the document rooted at n as JSON, keeping the order of
+// mapping keys
+func (n *yamlNode) json() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	count := 0
+	if err := n.writeJSON(buf, &count); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (n *yamlNode) writeJSON(buf *bytes.Buffer, count *int) error {
+	if *count++; *count > yamlMaxNodes {
+		return fmt.Errorf("yaml: line %d: document expands to too many nodes", n.line)
+	}
+	switch n.kind {
+	case yamlAlias:
+		return n.target.writeJSON(buf, count)
+	case yamlSequence:
+		buf.WriteByte('[')
+		for i, item := range n.children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := item.writeJSON(buf, count); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case yamlMapping:
+		entries, err := n.entries()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for i, e := range entries {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			data, _ := json.Marshal(e.key)
+			buf.Write(data)
+			buf.WriteByte(':')
+			if err := e.val.writeJSON(buf, count); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	v, err := n.scalar()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("yaml: line %d: %s can't be represented in JSON", n.line, n.value)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// resolve follows aliases to the node they refer to
+func (n *yamlNode) resolve() *yamlNode {
+	for n.kind == yamlAlias {
+		n = n.target
+	}
+	return n
+}
+
+// yamlEntry is an entry of a mapping, with the line of its key
+type yamlEntry struct {
+	key  string
+	line int
+	val  *yamlNode
+}
+
+// entries gives the entries of mapping n in order, merging in the entries
+// of "<<" merge keys that aren't given explicitly. Keys must be scalars,
+// and unique
+func (n *yamlNode) entries() ([]yamlEntry, error) {
+	var entries []yamlEntry
+	index := map[string]int{}
+	merged := map[string]bool{}
+	for i := 0; i < len(n.children); i += 2 {
+		keyNode, val := n.children[i].resolve(), n.children[i+1]
+		if keyNode.kind != yamlScalar {
+			return nil, fmt.Errorf("yaml: line %d: mapping keys must be scalars", n.children[i].line)
+		}
+		key := keyNode.value
+		if key == "<<" && keyNode.plain && keyNode.tag == "" {
+			sources := []*yamlNode{val.resolve()}
+			if sources[0].kind == yamlSequence {
+				sources = sources[0].children
+			}
+			for _, src := range sources {
+				src = src.resolve()
+				if src.kind != yamlMapping {
+					return nil, fmt.Errorf("yaml: line %d: merge keys must refer to mappings", val.line)
+				}
+				sentries, err := src.entries()
+				if err != nil {
+					return nil, err
+				}
+				for _, e := range sentries {
+					if _, ok := index[e.key]; ok {
+						continue
+					}
+					index[e.key] = len(entries)
+					merged[e.key] = true
+					entries = append(entries, e)
+				}
+			}
+			continue
+		}
+		if j, ok := index[key]; ok {
+			if !merged[key] {
+				return nil, fmt.Errorf("yaml: line %d: duplicate key %q", n.children[i].line, key)
+			}
+			// explicit keys override merged ones
+			entries[j] = yamlEntry{key, n.children[i].line, val}
+			delete(merged, key)
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, yamlEntry{key, n.children[i].line, val})
+	}
+	return entries, nil
+}
+
+// scalar gives the value of scalar node n. Plain scalars are typed by the
+// YAML 1.2 core schema, and others are strings unless tagged
+func (n *yamlNode) scalar() (interface{}, error) {
+	switch n.tag {
+	case "":
+		if !n.plain {
+			return n.value, nil
+		}
+		return resolveYAMLScalar(n.value), nil
+	case "!", "!!str", "!!binary", "!!timestamp":
+		return n.value, nil
+	case "!!null", "!!bool", "!!int", "!!float":
+		v := resolveYAMLScalar(n.value)
+		ok := false
+		switch v.(type) {
+		case nil:
+			ok = n.tag == "!!null"
+		case bool:
+			ok = n.tag == "!!bool"
+		case json.Number:
+			ok = n.tag == "!!float" || !yamlNumber(n.value) || !strings.ContainsAny(n.value, ".eE")
+		case float64:
+			ok = n.tag == "!!float"
+		}
+		if !ok {
+			return nil, fmt.Errorf("yaml: line %d: %q isn't a valid %s", n.line, n.value, n.tag)
+		}
+		return v, nil
+	}
+	if strings.HasPrefix(n.tag, "!!") {
+		return nil, fmt.Errorf("yaml: line %d: unsupported tag %s", n.line, n.tag)
+	}
+	// local tags don't change values
+	if n.plain {
+		return resolveYAMLScalar(n.value), nil
+	}
+	return n.value, nil
+}
+
+// resolveYAMLScalar types a plain scalar by the YAML 1.2 core schema. Numbers
+// are json.Numbers, except infinities and NaN
+func resolveYAMLScalar(str string) interface{} {
+	switch str {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0o") {
+		base := 16
+		if str[1] == 'o' {
+			base = 8
+		}
+		if i, err := strconv.ParseUint(str[2:], base, 64); err == nil {
+			return json.Number(strconv.FormatUint(i, 10))
+		}
+		return str
+	}
+	if !yamlNumber(str) {
+		return str
+	}
+	digits := strings.TrimLeft(str, "+-")
+	if !strings.ContainsAny(digits, ".eE") {
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(i, 10))
+		}
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return str
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// yamlNumber reports whether str is a decimal number of the core schema:
+// [-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?
+func yamlNumber(str string) bool {
+	i := 0
+	if i < len(str) && (str[i] == '-' || str[i] == '+') {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(str) && str[i] >= '0' && str[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	whole := digits()
+	frac := 0
+	if i < len(str) && str[i] == '.' {
+		i++
+		frac = digits()
+	}
+	if whole == 0 && frac == 0 {
+		return false
+	}
+	if i < len(str) && (str[i] == 'e' || str[i] == 'E') {
+		i++
+		if i < len(str) && (str[i] == '-' || str[i] == '+') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(str)
+}
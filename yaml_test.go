@@ -0,0 +1,222 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	cases := []struct {
+		yaml, json string
+	}{
+		{"a: 1\nb: two\nc: [x, 'y z', \"\\u00e9\"]\n", `{"a":1,"b":"two","c":["x","y z","é"]}`},
+		{"z: 1\na: 2\nm: 3", `{"z":1,"a":2,"m":3}`},
+		{"- 1\n- -2.50\n- 0x1f\n- 0o17\n- 1e3\n- 007\n- .5", `[1,-2.5,31,15,1000,7,0.5]`},
+		{"[true, False, ~, null, '', 'true', \"1\", yes, 1.2.3]", `[true,false,null,null,"","true","1","yes","1.2.3"]`},
+		{"a:\nb: ~\n", `{"a":null,"b":null}`},
+		{"url: http://example.com/a#b # comment\ntime: 12:30", `{"url":"http://example.com/a#b","time":"12:30"}`},
+		{"items:\n- a\n- b: 1\n  c: 2\n-\n  - nested\nnext: x", `{"items":["a",{"b":1,"c":2},["nested"]],"next":"x"}`},
+		{"text: |\n  line one\n    indented\n\n  last\nafter: 1", `{"text":"line one\n  indented\n\nlast\n","after":1}`},
+		{"text: >\n  folded\n  lines\n\n  para\n", `{"text":"folded lines\npara\n"}`},
+		{"a: |-\n  strip\n\nb: |+\n  keep\n\nc: >2\n    more\n", `{"a":"strip","b":"keep\n\n","c":"  more\n"}`},
+		{"plain: multi\n  line\n\n  scalar\nquoted: \"a\n  b\"\nsingle: 'it''s'", `{"plain":"multi line\nscalar","quoted":"a b","single":"it's"}`},
+		{"{a: [1, {b: c}], 'd': e, \"f\": }", `{"a":[1,{"b":"c"}],"d":"e","f":null}`},
+		{"[a: 1, b]", `[{"a":1},"b"]`},
+		{"base: &base\n  x: 1\n  y: 2\nderived:\n  <<: *base\n  y: 3\nlist: [*base]", `{"base":{"x":1,"y":2},"derived":{"x":1,"y":3},"list":[{"x":1,"y":2}]}`},
+		{"%YAML 1.2\n---\n!!str 1: !!int \"2\"\nb: !!str true\nc: !local 3\n...\n", `{"1":2,"b":"true","c":3}`},
+		{"\"a b\": 'c'\n? ", ``},
+		{"scalar", `"scalar"`},
+		{"---\n", `null`},
+	}
+	for i, c := range cases {
+		doc, err := decodeYAML([]byte(c.yaml))
+		if c.json == "" {
+			if err == nil {
+				t.Errorf("case %d: expected an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		got, err := doc.json()
+		if err != nil {
+			t.Errorf("case %d: %s", i, err.Error())
+			continue
+		}
+		if string(got) != c.json {
+			t.Errorf("case %d: expected %s, got %s", i, c.json, got)
+		}
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	cases := []struct {
+		yaml, err string
+	}{
+		{"a: 1\nb: 2\na: 3\n", "yaml: line 3: duplicate key \"a\""},
+		{"a: *missing", "yaml: line 1: unknown anchor \"missing\""},
+		{"a: 1\n  b: 2", "yaml: line 2: bad indentation of a mapping entry"},
+		{"a: [1, 2", "yaml: line 1: unterminated flow collection"},
+		{"a: 'open", "yaml: line 1: unterminated quoted scalar"},
+		{"a: 1\n---\nb: 2", "yaml: line 2: expected a single document"},
+		{"a: .inf", "yaml: line 1: .inf can't be represented in JSON"},
+		{"a: !!int x", "yaml: line 1: \"x\" isn't a valid !!int"},
+		{"", "yaml: empty document"},
+	}
+	for _, c := range cases {
+		doc, err := decodeYAML([]byte(c.yaml))
+		if err == nil {
+			_, err = doc.json()
+		}
+		if err == nil {
+			t.Errorf("%q: expected error %q", c.yaml, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%q: expected error %q, got %q", c.yaml, c.err, err.Error())
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	rs, err := ParseYAML([]byte(`
+# a person
+title: Person
+type: object
+required: [name]
+properties:
+  name: &str
+    type: string
+    minLength: 1
+  nickname: *str
+  age:
+    type: integer
+    minimum: 0
+patternProperties:
+  "^x-": {}
+  "^[a-z]+$": {type: string}
+additionalProperties: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{
+		"title": "Person",
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"nickname": {"type": "string", "minLength": 1},
+			"age": {"type": "integer", "minimum": 0}
+		},
+		"patternProperties": {"^x-": {}, "^[a-z]+$": {"type": "string"}},
+		"additionalProperties": false
+	}`
+	assertJSONEqual(t, "ParseYAML", expect, rs)
+
+	pp := rs.Validators["patternProperties"].(*PatternProperties)
+	if (*pp)[0].key != "^x-" || (*pp)[1].key != "^[a-z]+$" {
+		t.Errorf("expected patternProperties in document order")
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{"name": "", "x-1": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].PropertyPath, "name") {
+		t.Errorf("expected an error for name, got %v", errs)
+	}
+
+	if _, err := ParseYAML([]byte("type: [string\n")); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+	if _, err := ParseYAML([]byte("type: 3\n")); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["kind", "spec"],
+		"properties": {
+			"kind": {"const": "Deployment"},
+			"spec": {
+				"type": "object",
+				"properties": {
+					"replicas": {"type": "integer"},
+					"paused": {"type": "boolean"},
+					"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+					"ports": {"type": "array", "items": {"type": "integer", "maximum": 65535}}
+				}
+			}
+		}
+	}`)
+
+	errs, err := rs.ValidateYAML([]byte(`kind: Deployment
+spec:
+  replicas: 3
+  paused: false
+  labels:
+    version: "1.0"
+  ports: [80, 443]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	errs, err = rs.ValidateYAML([]byte(`# deployment
+kind: Deployment
+spec:
+  replicas: "3"
+  paused: yes
+  labels:
+    version: 1.0
+  ports:
+    - 80
+    - 70000
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := map[string]int{}
+	for _, e := range errs {
+		lines[e.PropertyPath] = e.Line
+	}
+	expect := map[string]int{
+		"/spec/replicas":       4,
+		"/spec/paused":         5,
+		"/spec/labels/version": 7,
+		"/spec/ports/1":        10,
+	}
+	for path, line := range expect {
+		if lines[path] != line {
+			t.Errorf("%s: expected an error on line %d, got errors %v", path, line, errs)
+		}
+	}
+	if len(errs) != len(expect) {
+		t.Errorf("expected %d errors, got %v", len(expect), errs)
+	}
+	for _, e := range errs {
+		if e.PropertyPath == "/spec/replicas" && !strings.HasPrefix(e.Error(), "line 4: /spec/replicas: ") {
+			t.Errorf("expected the error to give its line, got %q", e.Error())
+		}
+	}
+
+	errs, err = rs.ValidateYAML([]byte("spec: {}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Line != 1 {
+		t.Errorf("expected a missing kind on line 1, got %v", errs)
+	}
+
+	if _, err := rs.ValidateYAML([]byte("kind: a\nkind: b\n")); err == nil || !strings.Contains(err.Error(), "line 2: duplicate key") {
+		t.Errorf("expected a duplicate key error, got %v", err)
+	}
+}